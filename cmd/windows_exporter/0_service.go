@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 	"unsafe"
 
 	"golang.org/x/sys/windows"
@@ -29,7 +30,12 @@ import (
 	"golang.org/x/sys/windows/svc/eventlog"
 )
 
-const serviceName = "windows_exporter"
+const (
+	serviceName = "windows_exporter"
+
+	// shutdownWaitHint is the time the service manager is told to wait for the service to stop on pre-shutdown.
+	shutdownWaitHint = 30 * time.Second
+)
 
 //nolint:gochecknoglobals
 var (
@@ -41,8 +47,16 @@ var (
 	// stopCh is a channel to send a signal to the service manager that the service is stopping.
 	stopCh = make(chan struct{})
 
+	// shutdownCh is a channel to send a signal to the main function that Windows is shutting down,
+	// so the final metrics are sent before the service stops.
+	shutdownCh = make(chan struct{})
+
 	// serviceManagerFinishedCh is a channel to send a signal to the main function that the service manager has stopped the service.
 	serviceManagerFinishedCh = make(chan struct{}, 1)
+
+	// reloadCh is a channel to send a signal to the main function to reload the configuration,
+	// e.g. after "sc.exe control windows_exporter paramchange".
+	reloadCh = make(chan struct{}, 1)
 )
 
 // IsService variable declaration allows initiating time-sensitive components like registering the Windows service
@@ -103,7 +117,7 @@ type windowsExporterService struct{}
 func (s *windowsExporterService) Execute(_ []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
 	changes <- svc.Status{State: svc.StartPending}
 	// Send a signal to the main function that the service is running.
-	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown | svc.AcceptPreShutdown | svc.AcceptParamChange}
 
 	for {
 		select {
@@ -117,6 +131,25 @@ func (s *windowsExporterService) Execute(_ []string, r <-chan svc.ChangeRequest,
 			switch c.Cmd {
 			case svc.Interrogate:
 				changes <- c.CurrentStatus
+			case svc.ParamChange:
+				// Send a signal to the main function to reload the configuration. A pending reload is not queued twice.
+				select {
+				case reloadCh <- struct{}{}:
+				default:
+				}
+
+				changes <- c.CurrentStatus
+			case svc.PreShutdown:
+				// Windows notifies services that accept pre-shutdown before the shutdown, with more time to stop than on shutdown.
+				_ = logToEventToLog(windows.EVENTLOG_INFORMATION_TYPE, "service pre-shutdown received")
+
+				changes <- svc.Status{State: svc.StopPending, WaitHint: uint32(shutdownWaitHint.Milliseconds())}
+
+				// Send a signal to the main function to send the final metrics and to stop the service.
+				shutdownCh <- struct{}{}
+
+				// Wait for the main function to stop the service.
+				return false, uint32(<-exitCodeCh)
 			case svc.Stop, svc.Shutdown:
 				// Stop the service if a stop or shutdown request is received.
 				_ = logToEventToLog(windows.EVENTLOG_INFORMATION_TYPE, "service stop received")
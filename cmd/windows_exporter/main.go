@@ -20,7 +20,9 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"debug/pe"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -29,10 +31,14 @@ import (
 	"os"
 	"os/signal"
 	"os/user"
+	"path"
+	"regexp"
 	"runtime"
 	"runtime/debug"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
@@ -40,8 +46,18 @@ import (
 	"github.com/prometheus-community/windows_exporter/internal/httphandler"
 	"github.com/prometheus-community/windows_exporter/internal/log"
 	"github.com/prometheus-community/windows_exporter/internal/log/flag"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/otlp"
+	"github.com/prometheus-community/windows_exporter/internal/relabel"
+	"github.com/prometheus-community/windows_exporter/internal/remotewrite"
+	"github.com/prometheus-community/windows_exporter/internal/shutdown"
+	"github.com/prometheus-community/windows_exporter/internal/snapshot"
+	"github.com/prometheus-community/windows_exporter/internal/snmp"
+	"github.com/prometheus-community/windows_exporter/internal/updatecheck"
 	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus-community/windows_exporter/internal/zabbix"
 	"github.com/prometheus-community/windows_exporter/pkg/collector"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/version"
 	"github.com/prometheus/exporter-toolkit/web"
 	webflag "github.com/prometheus/exporter-toolkit/web/kingpinflag"
@@ -66,51 +82,90 @@ func main() {
 	<-serviceManagerFinishedCh
 }
 
-func run(ctx context.Context, args []string) int {
-	startTime := time.Now()
+// exporterFlags are the global flags of windows_exporter.
+type exporterFlags struct {
+	configFile               *string
+	profile                  *string
+	webConfig                *web.FlagConfig
+	metricsPath              *string
+	cacheDuration            *time.Duration
+	cacheCollectors          *string
+	disableExporterMetrics   *bool
+	enableReload             *bool
+	enableDelta              *bool
+	adminAPITokenFile        *string
+	allowedNetworks          *string
+	allowedClientCNs         *string
+	enabledCollectors        *string
+	disabledCollectors       *string
+	pluginsFile              *string
+	relabelConfigFile        *string
+	relabelTenantLabels      *string
+	profilesFile             *string
+	maxConcurrency           *int
+	selfTest                 *bool
+	selfTestScrapeInterval   *time.Duration
+	maintenance              *bool
+	maintenanceSuppress      *string
+	timeoutMargin            *float64
+	collectorTimeouts        *string
+	counterRates             *string
+	debugEnabled             *bool
+	processPriority          *string
+	compatMetricNames        *string
+	compatMetricNamesExclude *string
+	memoryLimit              *int64
+	probeEnabled             *bool
+	probeCollectors          *string
+	probeProtocol            *string
+	probeAuthentication      *string
+	probeUsername            *string
+	probePasswordFile        *string
+	probeTargets             *string
+	otlpEndpoint             *string
+	otlpInterval             *time.Duration
+	otlpTimeout              *time.Duration
+	otlpHeaders              *string
+	otlpAlign                *bool
+	otlpJitter               *time.Duration
+	otlpTracesEndpoint       *string
+	otlpTracesTimeout        *time.Duration
+	snmpListenAddress        *string
+	snmpCommunity            *string
+	snmpBaseOID              *string
+	snmpRefreshInterval      *time.Duration
+	zabbixServer             *string
+	zabbixHost               *string
+	zabbixInterval           *time.Duration
+	zabbixTimeout            *time.Duration
+	zabbixKeysFile           *string
+	snapshotPath             *string
+	snapshotInterval         *time.Duration
+	snapshotTimeout          *time.Duration
+	remoteWriteURL           *string
+	remoteWriteInterval      *time.Duration
+	remoteWriteTimeout       *time.Duration
+	remoteWriteHeaders       *string
+	remoteWriteLabels        *string
+	remoteWriteWALDir        *string
+	remoteWriteMaxSize       *int64
+	updateCheckFeedURL       *string
+	updateCheckInterval      *time.Duration
+	updateCheckTimeout       *time.Duration
+}
 
-	app := kingpin.New("windows_exporter", "A metrics collector for Windows.")
+// finalPushTimeout limits the final pushes before Windows shuts down. It is below shutdownWaitHint,
+// so the service stops before the service manager gives up on it.
+const finalPushTimeout = 20 * time.Second
 
-	var (
-		configFile = app.Flag(
-			"config.file",
-			"YAML configuration file to use. Values set in this file will be overridden by CLI flags.",
-		).String()
-		webConfig   = webflag.AddFlags(app, ":9182")
-		metricsPath = app.Flag(
-			"telemetry.path",
-			"URL path for surfacing collected metrics.",
-		).Default("/metrics").String()
-		disableExporterMetrics = app.Flag(
-			"web.disable-exporter-metrics",
-			"Exclude metrics about the exporter itself (promhttp_*, process_*, go_*).",
-		).Bool()
-		enabledCollectors = app.Flag(
-			"collectors.enabled",
-			"Comma-separated list of collectors to use. Use '[defaults]' as a placeholder for all the collectors enabled by default.").
-			Default(collector.DefaultCollectors).String()
-		disabledCollectors = app.Flag(
-			"collectors.disabled",
-			"Comma-separated list of collectors to exclude. Can be used to disable collector from the defaults.").
-			Default("").String()
-		timeoutMargin = app.Flag(
-			"scrape.timeout-margin",
-			"Seconds to subtract from the timeout allowed by the client. Tune to allow for overhead or high loads.",
-		).Default("0.5").Float64()
-		debugEnabled = app.Flag(
-			"debug.enabled",
-			"If true, windows_exporter will expose debug endpoints under /debug/pprof.",
-		).Default("false").Bool()
-		processPriority = app.Flag(
-			"process.priority",
-			"Priority of the exporter process. Higher priorities may improve exporter responsiveness during periods of system load. Can be one of [\"realtime\", \"high\", \"abovenormal\", \"normal\", \"belownormal\", \"low\"]",
-		).Default("normal").String()
-		memoryLimit = app.Flag(
-			"process.memory-limit",
-			"Limit memory usage in bytes. This is a soft-limit and not guaranteed. 0 means no limit. Read more at https://pkg.go.dev/runtime/debug#SetMemoryLimit .",
-		).Default("200000000").Int64()
-	)
+const (
+	runCommand            = "run"
+	configDefaultsCommand = "config defaults"
+	configSchemaCommand   = "config schema"
+)
 
+// newLogConfig returns the logging configuration with the default log file, which is the event log for services.
+func newLogConfig() *log.Config {
 	logFile := &log.AllowedFile{}
 
 	_ = logFile.Set("stdout")
@@ -118,16 +173,343 @@ func run(ctx context.Context, args []string) int {
 		_ = logFile.Set("eventlog")
 	}
 
-	logConfig := &log.Config{File: logFile}
+	return &log.Config{File: logFile}
+}
+
+// newApplication registers the flags of windows_exporter and all collectors.
+// It is called again on reload, to parse the configuration into new collectors.
+func newApplication(logConfig *log.Config) (*kingpin.Application, *exporterFlags, *collector.Collection) {
+	app := kingpin.New("windows_exporter", "A metrics collector for Windows.")
+
+	f := &exporterFlags{}
+
+	f.configFile = app.Flag(
+		"config.file",
+		"YAML configuration file to use. Values set in this file will be overridden by CLI flags.",
+	).String()
+	f.profile = app.Flag(
+		"profile",
+		"Profile of the configuration file whose collectors and collector settings are used. Overrides the profile key of the configuration file.",
+	).Default("").String()
+	f.webConfig = webflag.AddFlags(app, ":9182")
+	f.metricsPath = app.Flag(
+		"telemetry.path",
+		"URL path for surfacing collected metrics.",
+	).Default("/metrics").String()
+	f.cacheDuration = app.Flag(
+		"web.cache-duration",
+		"Duration for which the results of a collector are served from a cache instead of collecting them again. 0 disables the cache.",
+	).Default("0s").Duration()
+	f.cacheCollectors = app.Flag(
+		"web.cache-collectors",
+		"Comma-separated list of collectors whose results are cached with --web.cache-duration. If empty, all collectors are cached.",
+	).Default("").String()
+	f.disableExporterMetrics = app.Flag(
+		"web.disable-exporter-metrics",
+		"Exclude metrics about the exporter itself (promhttp_*, process_*, go_*).",
+	).Bool()
+	f.enableReload = app.Flag(
+		"web.enable-reload",
+		"If true, the configuration can be reloaded with POST /-/reload.",
+	).Default("false").Bool()
+	f.enableDelta = app.Flag(
+		"web.enable-delta",
+		"If true, the experimental endpoint GET /api/v1/metrics/delta returns only the metric families that changed since a previous response.",
+	).Default("false").Bool()
+	f.adminAPITokenFile = app.Flag(
+		"web.admin-api.token-file",
+		"File containing the bearer token of the admin API to enable and disable collectors at runtime. If empty, the admin API is disabled.",
+	).Default("").String()
+	f.profilesFile = app.Flag(
+		"web.profiles-file",
+		"YAML file of scrape profiles, which are named sets of collectors, filters and collector settings selected with ?profile=<name> or the path <telemetry.path>/<name>.",
+	).Default("").String()
+	f.allowedNetworks = app.Flag(
+		"web.allowed-networks",
+		"Comma-separated list of networks in CIDR notation or IP addresses that may connect, e.g. '10.0.0.0/24,192.168.1.10'. Other clients receive 403 Forbidden. If empty, all clients are allowed.",
+	).Default("").String()
+	f.allowedClientCNs = app.Flag(
+		"web.allowed-client-cns",
+		"Regexp of the common names or subject alternative names of client certificates that may connect. Requires client certificates verified by the client_ca_file of --web.config.file. If empty, all clients are allowed.",
+	).Default("").String()
+	f.enabledCollectors = app.Flag(
+		"collectors.enabled",
+		"Comma-separated list of collectors to use. Use '[defaults]' as a placeholder for all the collectors enabled by default.").
+		Default(collector.DefaultCollectors).String()
+	f.disabledCollectors = app.Flag(
+		"collectors.disabled",
+		"Comma-separated list of collectors to exclude. Can be used to disable collector from the defaults.").
+		Default("").String()
+	f.pluginsFile = app.Flag(
+		"collectors.plugins-file",
+		"YAML file of external collectors, which are run as exec or gRPC plugins. The plugins are always enabled.",
+	).Default("").String()
+	f.relabelConfigFile = app.Flag(
+		"relabel.config-file",
+		"YAML file of relabeling rules, which add static labels, drop series and rename labels of scrapes and pushes.",
+	).Default("").String()
+	f.relabelTenantLabels = app.Flag(
+		"relabel.tenant-labels",
+		"Comma-separated list of labels stamped on all series of scrapes and pushes, e.g. 'tenant=contoso,environment=prod'. They replace labels of the series with the same name.",
+	).Default("").String()
+	f.maxConcurrency = app.Flag(
+		"collectors.max-concurrency",
+		"Number of collectors that collect at the same time. Concurrent scrapes share the running collections of their collectors.",
+	).Default(strconv.Itoa(collector.DefaultMaxConcurrency())).Int()
+	f.selfTest = app.Flag(
+		"collectors.self-test",
+		"Collect each enabled collector once at startup and export its duration as windows_exporter_collector_startup_cost_seconds.",
+	).Default("false").Bool()
+	f.selfTestScrapeInterval = app.Flag(
+		"collectors.self-test.scrape-interval",
+		"Scrape interval of the Prometheus servers. The self-test warns about collectors that take longer.",
+	).Default("15s").Duration()
+	f.maintenance = app.Flag(
+		"collectors.maintenance",
+		"Start in maintenance mode, exported as windows_host_maintenance_mode. The mode is changed at runtime with the admin API.",
+	).Default("false").Bool()
+	f.maintenanceSuppress = app.Flag(
+		"collectors.maintenance.suppress",
+		"Comma-separated list of collectors that are skipped while the host is in maintenance mode.",
+	).Default("").String()
+	f.timeoutMargin = app.Flag(
+		"scrape.timeout-margin",
+		"Seconds to subtract from the timeout allowed by the client. Tune to allow for overhead or high loads.",
+	).Default("0.5").Float64()
+	f.collectorTimeouts = app.Flag(
+		"scrape.collector-timeouts",
+		"Comma-separated list of timeouts of individual collectors, e.g. 'service:5s,mssql:3s'. Slow collectors are cancelled after their timeout, while the other collectors still return their metrics.",
+	).Default("").String()
+	f.counterRates = app.Flag(
+		"scrape.counter-rates",
+		"Regexp of counters whose per-second rate since the previous scrape is exported as <name>_per_second, e.g. 'windows_net_.+' for long scrape intervals.",
+	).Default("").String()
+	f.debugEnabled = app.Flag(
+		"debug.enabled",
+		"If true, windows_exporter will expose debug endpoints under /debug/pprof, /debug/collectors and /debug/dump.",
+	).Default("false").Bool()
+	f.processPriority = app.Flag(
+		"process.priority",
+		"Priority of the exporter process. Higher priorities may improve exporter responsiveness during periods of system load. Can be one of [\"realtime\", \"high\", \"abovenormal\", \"normal\", \"belownormal\", \"low\"]",
+	).Default("normal").String()
+	f.compatMetricNames = app.Flag(
+		"compat.metric-names",
+		"Emit the metric names of a previous release next to the current names, to migrate dashboards and recording rules gradually. Can be one of [\""+strings.Join(httphandler.CompatMetricNamesVersions(), "\", \"")+"\"]",
+	).Default("").Enum(append([]string{""}, httphandler.CompatMetricNamesVersions()...)...)
+	f.compatMetricNamesExclude = app.Flag(
+		"compat.metric-names.exclude",
+		"Regexp of legacy metric names to not emit with --compat.metric-names, e.g. if they are already migrated.",
+	).Default("").String()
+	f.memoryLimit = app.Flag(
+		"process.memory-limit",
+		"Limit memory usage in bytes. This is a soft-limit and not guaranteed. 0 means no limit. Read more at https://pkg.go.dev/runtime/debug#SetMemoryLimit .",
+	).Default("200000000").Int64()
+	f.probeEnabled = app.Flag(
+		"probe.enabled",
+		"If true, windows_exporter will collect metrics from remote hosts under /probe?target=<host>.",
+	).Default("false").Bool()
+	f.probeCollectors = app.Flag(
+		"probe.collectors",
+		"Comma-separated list of collectors to use for remote hosts if the request doesn't contain collect[] parameters. Supported collectors: "+strings.Join(collector.RemoteCollectors(), ", "),
+	).Default("diskdrive").String()
+	f.probeProtocol = app.Flag(
+		"probe.protocol",
+		"Protocol used to connect to remote hosts. Can be one of [\"winrm\", \"dcom\"]",
+	).Default("winrm").Enum("winrm", "dcom")
+	f.probeAuthentication = app.Flag(
+		"probe.authentication",
+		"Authentication used with --probe.username. Can be one of [\"default\", \"negotiate\", \"kerberos\", \"ntlm\"]",
+	).Default("default").Enum("default", "negotiate", "kerberos", "ntlm")
+	f.probeUsername = app.Flag(
+		"probe.username",
+		"User to authenticate to remote hosts, as DOMAIN\\user. If empty, the account of windows_exporter is used.",
+	).Default("").String()
+	f.probePasswordFile = app.Flag(
+		"probe.password-file",
+		"File containing the password of --probe.username.",
+	).Default("").String()
+	f.probeTargets = app.Flag(
+		"probe.targets",
+		"Regexp of remote hosts that may be probed. If empty, all probes are rejected.",
+	).Default("").String()
+	f.otlpEndpoint = app.Flag(
+		"otlp.endpoint",
+		"URL of an OTLP/HTTP receiver, e.g. of an OpenTelemetry Collector, to push the metrics to. If the URL has no path, /v1/metrics is used. If empty, metrics are not pushed.",
+	).Default("").String()
+	f.otlpInterval = app.Flag(
+		"otlp.interval",
+		"Interval between two pushes to --otlp.endpoint.",
+	).Default("1m").Duration()
+	f.otlpTimeout = app.Flag(
+		"otlp.timeout",
+		"Timeout of collecting and pushing the metrics to --otlp.endpoint. Limited to --otlp.interval.",
+	).Default("10s").Duration()
+	f.otlpHeaders = app.Flag(
+		"otlp.headers",
+		"Comma-separated list of HTTP headers sent to --otlp.endpoint and --otlp.traces-endpoint, e.g. 'Authorization=Bearer token'.",
+	).Default("").String()
+	f.otlpAlign = app.Flag(
+		"otlp.align",
+		"If true, pushes run at multiples of --otlp.interval on the UTC wall clock, e.g. at :00 and :30 with an interval of 30m.",
+	).Default("false").Bool()
+	f.otlpJitter = app.Flag(
+		"otlp.jitter",
+		"Maximum offset of the pushes, so many hosts don't collect at the same time. The offset is derived from the hostname and limited to --otlp.interval.",
+	).Default("0s").Duration()
+	f.otlpTracesEndpoint = app.Flag(
+		"otlp.traces-endpoint",
+		"URL of an OTLP/HTTP receiver that a trace of each scrape is sent to, with a span per collector, e.g. 'http://otel-collector:4318'. If the URL has no path, '/v1/traces' is used. If empty, scrapes are not traced.",
+	).Default("").String()
+	f.otlpTracesTimeout = app.Flag(
+		"otlp.traces-timeout",
+		"Timeout of sending a trace to --otlp.traces-endpoint.",
+	).Default("10s").Duration()
+	f.snmpListenAddress = app.Flag(
+		"snmp.listen-address",
+		"UDP address of the SNMP agent, e.g. ':161'. If empty, the SNMP agent is disabled.",
+	).Default("").String()
+	f.snmpCommunity = app.Flag(
+		"snmp.community",
+		"SNMPv1 and SNMPv2c community that requests must send.",
+	).Default("public").String()
+	f.snmpBaseOID = app.Flag(
+		"snmp.base-oid",
+		"OID of WINDOWS-EXPORTER-MIB. The default is below netSnmpPlaypen, which is reserved for local use.",
+	).Default(snmp.DefaultBaseOID).String()
+	f.snmpRefreshInterval = app.Flag(
+		"snmp.refresh-interval",
+		"Interval between two collections of the metrics exposed via SNMP.",
+	).Default("30s").Duration()
+	f.zabbixServer = app.Flag(
+		"zabbix.server",
+		"Address of a Zabbix server or proxy to send the metrics of --zabbix.keys-file to, e.g. 'zabbix.example.com:10051'. If empty, metrics are not sent.",
+	).Default("").String()
+	f.zabbixHost = app.Flag(
+		"zabbix.host",
+		"Name of the host in Zabbix. If empty, the hostname is used.",
+	).Default("").String()
+	f.zabbixInterval = app.Flag(
+		"zabbix.interval",
+		"Interval between two sends to --zabbix.server.",
+	).Default("1m").Duration()
+	f.zabbixTimeout = app.Flag(
+		"zabbix.timeout",
+		"Timeout of collecting and sending the metrics to --zabbix.server. Limited to --zabbix.interval.",
+	).Default("10s").Duration()
+	f.zabbixKeysFile = app.Flag(
+		"zabbix.keys-file",
+		"YAML file that maps metrics to the keys of Zabbix trapper items.",
+	).Default("").String()
+	f.snapshotPath = app.Flag(
+		"snapshot.path",
+		"File to periodically write all metrics to in the text exposition format. If empty, no snapshots are written.",
+	).Default("").String()
+	f.snapshotInterval = app.Flag(
+		"snapshot.interval",
+		"Interval between two writes of --snapshot.path.",
+	).Default("1m").Duration()
+	f.snapshotTimeout = app.Flag(
+		"snapshot.timeout",
+		"Timeout of collecting the metrics of a snapshot. Limited to --snapshot.interval.",
+	).Default("10s").Duration()
+	f.remoteWriteURL = app.Flag(
+		"remote-write.url",
+		"Comma-separated list of Prometheus remote write URLs to push the metrics to, e.g. 'https://prometheus:9090/api/v1/write'. If empty, metrics are not pushed.",
+	).Default("").String()
+	f.remoteWriteInterval = app.Flag(
+		"remote-write.interval",
+		"Interval between two collections of the metrics pushed to --remote-write.url.",
+	).Default("1m").Duration()
+	f.remoteWriteTimeout = app.Flag(
+		"remote-write.timeout",
+		"Timeout of collecting the metrics and of each request to --remote-write.url. Limited to --remote-write.interval.",
+	).Default("10s").Duration()
+	f.remoteWriteHeaders = app.Flag(
+		"remote-write.headers",
+		"Comma-separated list of HTTP headers sent to --remote-write.url, e.g. 'Authorization=Bearer token'.",
+	).Default("").String()
+	f.remoteWriteLabels = app.Flag(
+		"remote-write.labels",
+		"Comma-separated list of labels added to all pushed series, e.g. 'site=branch01'. The instance label defaults to the hostname and the job label to windows_exporter.",
+	).Default("").String()
+	f.remoteWriteWALDir = app.Flag(
+		"remote-write.wal-dir",
+		"Directory of the write-ahead log, which buffers the metrics while --remote-write.url is not reachable. If empty, the metrics are buffered in memory and lost on restart.",
+	).Default("").String()
+	f.remoteWriteMaxSize = app.Flag(
+		"remote-write.max-size",
+		"Maximum size in bytes of the buffered metrics of each remote write URL. If exceeded, the oldest metrics are dropped.",
+	).Default("268435456").Int64()
+	f.updateCheckFeedURL = app.Flag(
+		"update-check.feed-url",
+		"URL of a release feed in the JSON format of the GitHub releases API, e.g. 'https://api.github.com/repos/prometheus-community/windows_exporter/releases/latest'. If empty, no update checks are done.",
+	).Default("").String()
+	f.updateCheckInterval = app.Flag(
+		"update-check.interval",
+		"Interval between two reads of --update-check.feed-url.",
+	).Default("24h").Duration()
+	f.updateCheckTimeout = app.Flag(
+		"update-check.timeout",
+		"Timeout of reading --update-check.feed-url. Limited to --update-check.interval.",
+	).Default("30s").Duration()
+
 	flag.AddFlags(app, logConfig)
 
+	app.Command(runCommand, "Run windows_exporter. This is the default command.").Default()
+
+	configCommand := app.Command("config", "Print information about the configuration file.")
+	configCommand.Command("defaults", "Print a configuration file with the default values of all settings.")
+	configCommand.Command("schema", "Print the JSON schema of the configuration file.")
+
 	app.Version(version.Print("windows_exporter"))
 	app.HelpFlag.Short('h')
 
 	// Initialize collectors before loading and parsing CLI arguments
 	collectors := collector.NewWithFlags(app)
 
-	if err := config.Parse(app, args); err != nil {
+	return app, f, collectors
+}
+
+// printConfig prints the configuration file defaults or schema. They are generated from a new application,
+// so values of the configuration file passed with --config.file are not printed as defaults.
+func printConfig(ctx context.Context, command string) int {
+	app, _, _ := newApplication(newLogConfig())
+
+	var (
+		content []byte
+		err     error
+	)
+
+	if command == configSchemaCommand {
+		content, err = config.Schema(app)
+	} else {
+		content, err = config.Defaults(app)
+	}
+
+	if err != nil {
+		//nolint:sloglint // we do not have an logger yet
+		slog.LogAttrs(ctx, slog.LevelError, "Failed to generate "+command,
+			slog.Any("err", err),
+		)
+
+		return 1
+	}
+
+	if _, err = os.Stdout.Write(content); err != nil {
+		return 1
+	}
+
+	return 0
+}
+
+func run(ctx context.Context, args []string) int {
+	startTime := time.Now()
+
+	logConfig := newLogConfig()
+	app, flags, collectors := newApplication(logConfig)
+
+	command, err := config.Parse(app, args)
+	if err != nil {
 		//nolint:sloglint // we do not have an logger yet
 		slog.LogAttrs(ctx, slog.LevelError, "Failed to load configuration",
 			slog.Any("err", err),
@@ -136,7 +518,11 @@ func run(ctx context.Context, args []string) int {
 		return 1
 	}
 
-	debug.SetMemoryLimit(*memoryLimit)
+	if command == configDefaultsCommand || command == configSchemaCommand {
+		return printConfig(ctx, command)
+	}
+
+	debug.SetMemoryLimit(*flags.memoryLimit)
 
 	logger, err := log.New(logConfig)
 	if err != nil {
@@ -149,11 +535,15 @@ func run(ctx context.Context, args []string) int {
 
 	logger.LogAttrs(ctx, slog.LevelDebug, "logging has Started")
 
-	if configFile != nil && *configFile != "" {
-		logger.LogAttrs(ctx, slog.LevelInfo, "using configuration file: "+*configFile)
+	if flags.configFile != nil && *flags.configFile != "" {
+		logger.LogAttrs(ctx, slog.LevelInfo, "using configuration file: "+*flags.configFile)
+	}
+
+	if *flags.profile != "" {
+		logger.LogAttrs(ctx, slog.LevelInfo, "using profile: "+*flags.profile)
 	}
 
-	if err = setPriorityWindows(ctx, logger, os.Getpid(), *processPriority); err != nil {
+	if err = setPriorityWindows(ctx, logger, os.Getpid(), *flags.processPriority); err != nil {
 		logger.LogAttrs(ctx, slog.LevelError, "failed to set process priority",
 			slog.Any("err", err),
 		)
@@ -161,48 +551,295 @@ func run(ctx context.Context, args []string) int {
 		return 1
 	}
 
-	enabledCollectorList := expandEnabledCollectors(*enabledCollectors)
-	if err := collectors.Enable(enabledCollectorList); err != nil {
-		logger.LogAttrs(ctx, slog.LevelError, "couldn't enable collectors",
+	if err = setupCollection(ctx, logger, collectors, flags); err != nil {
+		for _, err := range utils.SplitError(err) {
+			logger.LogAttrs(ctx, slog.LevelError, "couldn't initialize collectors",
+				slog.Any("err", err),
+			)
+		}
+
+		return 1
+	}
+
+	if *flags.selfTest {
+		go collectors.SelfTest(ctx, logger, *flags.selfTestScrapeInterval)
+	}
+
+	logCurrentUser(ctx, logger)
+	logEmulation(ctx, logger)
+
+	logger.InfoContext(ctx, "Enabled collectors: "+strings.Join(expandEnabledCollectors(*flags.enabledCollectors), ", "))
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /health", httphandler.NewHealthHandler())
+	mux.Handle("GET /version", httphandler.NewVersionHandler())
+	var compatExclude *regexp.Regexp
+	if *flags.compatMetricNamesExclude != "" {
+		compatExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", *flags.compatMetricNamesExclude))
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "failed to compile --compat.metric-names.exclude",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+	}
+
+	var counterRates *regexp.Regexp
+	if *flags.counterRates != "" {
+		counterRates, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", *flags.counterRates))
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "failed to compile --scrape.counter-rates",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+	}
+
+	if *flags.compatMetricNames != "" {
+		logger.LogAttrs(ctx, slog.LevelInfo, "emitting legacy metric names of windows_exporter "+*flags.compatMetricNames)
+	}
+
+	relabelRules, err := loadRelabelRules(flags)
+	if err != nil {
+		logger.LogAttrs(ctx, slog.LevelError, "failed to load relabeling rules",
 			slog.Any("err", err),
 		)
 
 		return 1
 	}
 
-	if *disabledCollectors != "" {
-		collectors.Disable(slices.Compact(strings.Split(*disabledCollectors, ",")))
+	profiles, err := loadScrapeProfiles(ctx, logger, args, flags)
+	if err != nil {
+		logger.LogAttrs(ctx, slog.LevelError, "failed to load scrape profiles",
+			slog.Any("err", err),
+		)
+
+		return 1
 	}
 
-	// Initialize collectors before loading
-	if err = collectors.Build(ctx, logger); err != nil {
-		for _, err := range utils.SplitError(err) {
-			logger.LogAttrs(ctx, slog.LevelError, "couldn't initialize collector",
+	pushCtx, stopPush := context.WithCancel(ctx)
+	defer stopPush()
+
+	// shutdownCollector exports the shutdown of Windows, once the service was notified about it.
+	shutdownCollector := shutdown.NewCollector()
+
+	// finalPushes send the metrics once more before Windows shuts down.
+	var finalPushes []finalPush
+
+	var updateChecker *updatecheck.Checker
+
+	if *flags.updateCheckFeedURL != "" {
+		checker, err := updatecheck.New(logger, version.Version, updatecheck.Options{
+			FeedURL:  *flags.updateCheckFeedURL,
+			Interval: *flags.updateCheckInterval,
+			Timeout:  *flags.updateCheckTimeout,
+		})
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "failed to configure update checks",
 				slog.Any("err", err),
 			)
 
 			return 1
 		}
+
+		updateChecker = checker
+
+		go checker.Run(pushCtx)
 	}
 
-	logCurrentUser(ctx, logger)
+	var tracer *otlp.Tracer
+
+	if *flags.otlpTracesEndpoint != "" {
+		tracer, err = newOTLPTracer(logger, flags)
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "failed to configure OTLP tracing",
+				slog.Any("err", err),
+			)
 
-	logger.InfoContext(ctx, "Enabled collectors: "+strings.Join(enabledCollectorList, ", "))
+			return 1
+		}
 
-	mux := http.NewServeMux()
-	mux.Handle("GET /health", httphandler.NewHealthHandler())
-	mux.Handle("GET /version", httphandler.NewVersionHandler())
-	mux.Handle("GET "+*metricsPath, httphandler.New(logger, collectors, &httphandler.Options{
-		DisableExporterMetrics: *disableExporterMetrics,
-		TimeoutMargin:          *timeoutMargin,
-	}))
+		go tracer.Run(pushCtx)
+	}
+
+	metricsHandler := httphandler.New(logger, collectors, &httphandler.Options{
+		DisableExporterMetrics:   *flags.disableExporterMetrics,
+		TimeoutMargin:            *flags.timeoutMargin,
+		CompatMetricNames:        *flags.compatMetricNames,
+		CompatMetricNamesExclude: compatExclude,
+		RelabelRules:             relabelRules,
+		Profiles:                 profiles,
+		CounterRates:             counterRates,
+		UpdateChecker:            updateChecker,
+		Tracer:                   tracer,
+		Shutdown:                 shutdownCollector,
+	})
+
+	mux.Handle("GET "+*flags.metricsPath, metricsHandler)
+	mux.Handle("POST "+*flags.metricsPath, metricsHandler)
+	mux.Handle("GET "+path.Join(*flags.metricsPath, "{profile}"), metricsHandler)
+	mux.Handle("POST "+path.Join(*flags.metricsPath, "{profile}"), metricsHandler)
+	mux.Handle("GET /-/healthy", httphandler.NewHealthHandler())
+	mux.Handle("GET /-/ready", httphandler.NewReadyHandler(metricsHandler))
+
+	if *flags.enableDelta {
+		mux.Handle("GET /api/v1/metrics/delta", httphandler.NewDeltaHandler(logger, metricsHandler))
+	}
+
+	configReloader := &reloader{args: args, logger: logger, handler: metricsHandler}
+	if *flags.enableReload {
+		mux.Handle("POST /-/reload", httphandler.NewReloadHandler(logger, configReloader.Reload))
+	}
+
+	if *flags.adminAPITokenFile != "" {
+		token, err := readAdminAPIToken(*flags.adminAPITokenFile)
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "failed to read admin API token",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+
+		var persist func(ctx context.Context, name string, enabled bool) error
+		if *flags.configFile != "" {
+			persist = configReloader.SetCollectorEnabled
+		}
+
+		adminHandler := httphandler.NewAdminHandler(logger, metricsHandler, token, persist)
+
+		mux.Handle("GET /api/v1/collectors", adminHandler)
+		mux.Handle("POST /api/v1/collectors/{name}/{action}", adminHandler)
+		mux.Handle("POST /api/v1/capture", httphandler.NewCaptureHandler(logger, metricsHandler, token))
+
+		maintenanceHandler := httphandler.NewMaintenanceHandler(logger, metricsHandler, token)
+
+		mux.Handle("GET /api/v1/maintenance", maintenanceHandler)
+		mux.Handle("POST /api/v1/maintenance/{action}", maintenanceHandler)
+	}
+
+	if *flags.probeEnabled {
+		probeOptions, err := newProbeOptions(*flags.probeCollectors, *flags.probeProtocol, *flags.probeAuthentication, *flags.probeUsername, *flags.probePasswordFile, *flags.probeTargets)
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "failed to configure remote probing",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+
+		if *flags.probeTargets == "" {
+			logger.LogAttrs(ctx, slog.LevelWarn, "--probe.targets is empty, all probes are rejected until the hosts that may be probed are listed")
+		}
+
+		probeOptions.TimeoutMargin = *flags.timeoutMargin
+
+		mux.Handle("GET /probe", httphandler.NewProbeHandler(logger, probeOptions))
+	}
+
+	if *flags.otlpEndpoint != "" {
+		pusher, err := newOTLPPusher(logger, metricsHandler, startTime, flags)
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "failed to configure OTLP push",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+
+		go pusher.Run(pushCtx)
 
-	if *debugEnabled {
+		finalPushes = append(finalPushes, finalPush{name: "OTLP", push: pusher.Push})
+	}
+
+	if *flags.snmpListenAddress != "" {
+		agent, err := newSNMPAgent(logger, metricsHandler, startTime, flags)
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "failed to start SNMP agent",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+
+		go agent.Run(pushCtx)
+	}
+
+	if *flags.zabbixServer != "" {
+		sender, err := newZabbixSender(logger, metricsHandler, flags)
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "failed to configure Zabbix sender",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+
+		go sender.Run(pushCtx)
+
+		finalPushes = append(finalPushes, finalPush{name: "Zabbix", push: sender.Send})
+	}
+
+	if *flags.snapshotPath != "" {
+		writer, err := snapshot.New(logger, metricsHandler.Gather, snapshot.Options{
+			Path:     *flags.snapshotPath,
+			Interval: *flags.snapshotInterval,
+			Timeout:  *flags.snapshotTimeout,
+		})
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "failed to configure metrics snapshots",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+
+		go writer.Run(pushCtx)
+
+		finalPushes = append(finalPushes, finalPush{name: "snapshot", push: writer.Write})
+	}
+
+	if *flags.remoteWriteURL != "" {
+		sender, err := newRemoteWriteSender(logger, metricsHandler, flags)
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "failed to configure remote write",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+
+		go sender.Run(pushCtx)
+
+		finalPushes = append(finalPushes, finalPush{name: "remote write", push: sender.Flush})
+	}
+
+	if *flags.debugEnabled {
 		mux.HandleFunc("GET /debug/pprof/", pprof.Index)
 		mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
 		mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
 		mux.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
 		mux.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+
+		debugHandler := httphandler.NewDebugHandler(logger, metricsHandler)
+		mux.Handle("GET /debug/collectors", debugHandler)
+		mux.Handle("GET /debug/dump", debugHandler)
+	}
+
+	allowListOptions, err := newAllowListOptions(*flags.allowedNetworks, *flags.allowedClientCNs)
+	if err != nil {
+		logger.LogAttrs(ctx, slog.LevelError, "failed to configure allowed clients",
+			slog.Any("err", err),
+		)
+
+		return 1
+	}
+
+	var handler http.Handler = mux
+	if len(allowListOptions.Networks) > 0 || allowListOptions.ClientNames != nil {
+		handler = httphandler.NewAllowListHandler(logger, mux, allowListOptions)
 	}
 
 	logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("starting windows_exporter in %s", time.Since(startTime)),
@@ -219,31 +856,56 @@ func run(ctx context.Context, args []string) int {
 		IdleTimeout:       60 * time.Second,
 		ReadTimeout:       5 * time.Second,
 		WriteTimeout:      5 * time.Minute,
-		Handler:           mux,
+		Handler:           handler,
 	}
 
 	errCh := make(chan error, 1)
 
 	go func() {
-		if err := web.ListenAndServe(server, webConfig, logger); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := web.ListenAndServe(server, flags.webConfig, logger); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errCh <- err
 		}
 
 		close(errCh)
 	}()
 
-	select {
-	case <-ctx.Done():
-		logger.LogAttrs(ctx, slog.LevelInfo, "Shutting down windows_exporter via kill signal")
-	case <-stopCh:
-		logger.LogAttrs(ctx, slog.LevelInfo, "Shutting down windows_exporter via service control")
-	case err := <-errCh:
-		if err != nil {
-			logger.LogAttrs(ctx, slog.LevelError, "Failed to start windows_exporter",
-				slog.Any("err", err),
-			)
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			logger.LogAttrs(ctx, slog.LevelInfo, "Shutting down windows_exporter via kill signal")
 
-			return 1
+			break loop
+		case <-stopCh:
+			logger.LogAttrs(ctx, slog.LevelInfo, "Shutting down windows_exporter via service control")
+
+			break loop
+		case <-shutdownCh:
+			logger.LogAttrs(ctx, slog.LevelInfo, "Shutting down windows_exporter via system shutdown")
+
+			// The periodic pushes are stopped, so the final pushes don't run concurrently with them.
+			stopPush()
+
+			//nolint:contextcheck // the final pushes run after ctx of the periodic pushes was cancelled
+			pushFinalMetrics(logger, shutdownCollector, finalPushes)
+
+			break loop
+		case <-reloadCh:
+			if err := configReloader.Reload(ctx); err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "Failed to reload configuration via service control",
+					slog.Any("err", err),
+				)
+			}
+		case err := <-errCh:
+			if err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "Failed to start windows_exporter",
+					slog.Any("err", err),
+				)
+
+				return 1
+			}
+
+			break loop
 		}
 	}
 
@@ -264,6 +926,48 @@ func run(ctx context.Context, args []string) int {
 	return 0
 }
 
+// finalPush sends the metrics once more before Windows shuts down, see pushFinalMetrics.
+type finalPush struct {
+	name string
+	push func(ctx context.Context) error
+}
+
+// pushFinalMetrics records the shutdown of Windows, which adds its metrics to the final pushes,
+// and runs the final pushes concurrently within finalPushTimeout.
+func pushFinalMetrics(logger *slog.Logger, shutdownCollector *shutdown.Collector, pushes []finalPush) {
+	ctx, cancel := context.WithTimeout(context.Background(), finalPushTimeout)
+	defer cancel()
+
+	reason, err := shutdownCollector.Begin(time.Now())
+	if err != nil {
+		logger.LogAttrs(ctx, slog.LevelWarn, "failed to read the reason of the shutdown",
+			slog.Any("err", err),
+		)
+	}
+
+	logger.LogAttrs(ctx, slog.LevelInfo, "Windows is shutting down, sending final metrics",
+		slog.String("type", reason.Type),
+		slog.String("process", reason.Process),
+		slog.String("user", reason.User),
+		slog.String("reason", reason.Reason),
+		slog.String("reason_code", reason.Code),
+	)
+
+	var wg sync.WaitGroup
+
+	for _, p := range pushes {
+		wg.Go(func() {
+			if err := p.push(ctx); err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "failed to send final metrics via "+p.name,
+					slog.Any("err", err),
+				)
+			}
+		})
+	}
+
+	wg.Wait()
+}
+
 func logCurrentUser(ctx context.Context, logger *slog.Logger) {
 	u, err := user.Current()
 	if err != nil {
@@ -281,6 +985,23 @@ func logCurrentUser(ctx context.Context, logger *slog.Logger) {
 	}
 }
 
+// logEmulation warns if an x86 or amd64 build runs emulated on an ARM64 host, which adds CPU overhead to every scrape.
+func logEmulation(ctx context.Context, logger *slog.Logger) {
+	var processMachine, nativeMachine uint16
+
+	if err := windows.IsWow64Process2(windows.CurrentProcess(), &processMachine, &nativeMachine); err != nil {
+		logger.LogAttrs(ctx, slog.LevelDebug, "Unable to determine the native machine architecture",
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	if nativeMachine == pe.IMAGE_FILE_MACHINE_ARM64 && runtime.GOARCH != "arm64" {
+		logger.LogAttrs(ctx, slog.LevelWarn, "Running the "+runtime.GOARCH+" build of windows_exporter on an ARM64 host. Use the arm64 build to avoid emulation.")
+	}
+}
+
 // setPriorityWindows sets the priority of the current process to the specified value.
 func setPriorityWindows(ctx context.Context, logger *slog.Logger, pid int, priority string) error {
 	// Mapping of priority names to uin32 values required by windows.SetPriorityClass.
@@ -322,8 +1043,360 @@ func setPriorityWindows(ctx context.Context, logger *slog.Logger, pid int, prior
 	return nil
 }
 
+// setupCollection enables, builds and configures the collectors selected by the flags.
+// Errors of collectors that couldn't be built are joined and returned unwrapped.
+func setupCollection(ctx context.Context, logger *slog.Logger, collectors *collector.Collection, flags *exporterFlags) error {
+	if err := collectors.Enable(expandEnabledCollectors(*flags.enabledCollectors)); err != nil {
+		return fmt.Errorf("couldn't enable collectors: %w", err)
+	}
+
+	if *flags.disabledCollectors != "" {
+		collectors.Disable(slices.Compact(strings.Split(*flags.disabledCollectors, ",")))
+	}
+
+	if *flags.pluginsFile != "" {
+		plugins, err := collector.LoadPlugins(*flags.pluginsFile)
+		if err != nil {
+			return err
+		}
+
+		if err := collectors.AddPlugins(plugins); err != nil {
+			return fmt.Errorf("couldn't add plugins: %w", err)
+		}
+	}
+
+	if err := collectors.Build(ctx, logger); err != nil {
+		return err
+	}
+
+	if *flags.maintenanceSuppress != "" {
+		if err := collectors.SetMaintenanceSuppressed(slices.Compact(strings.Split(*flags.maintenanceSuppress, ","))); err != nil {
+			return fmt.Errorf("couldn't set collectors suppressed in maintenance mode: %w", err)
+		}
+	}
+
+	collectors.SetMaintenance(*flags.maintenance, "")
+
+	if err := collectors.SetMaxConcurrency(*flags.maxConcurrency); err != nil {
+		return fmt.Errorf("couldn't set max concurrency: %w", err)
+	}
+
+	if *flags.collectorTimeouts != "" {
+		timeouts, err := parseCollectorTimeouts(*flags.collectorTimeouts)
+		if err == nil {
+			err = collectors.SetTimeouts(timeouts)
+		}
+
+		if err != nil {
+			return fmt.Errorf("couldn't set collector timeouts: %w", err)
+		}
+	}
+
+	if *flags.cacheDuration > 0 {
+		var cachedCollectorList []string
+		if *flags.cacheCollectors != "" {
+			cachedCollectorList = slices.Compact(strings.Split(*flags.cacheCollectors, ","))
+		}
+
+		if err := collectors.SetCache(*flags.cacheDuration, cachedCollectorList); err != nil {
+			return fmt.Errorf("couldn't enable cache: %w", err)
+		}
+
+		logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("caching collector results for %s", *flags.cacheDuration))
+	}
+
+	return nil
+}
+
 func expandEnabledCollectors(enabled string) []string {
 	expanded := strings.ReplaceAll(enabled, "[defaults]", collector.DefaultCollectors)
 
 	return slices.Compact(strings.Split(expanded, ","))
 }
+
+// readAdminAPIToken reads the bearer token of the admin API from the file of --web.admin-api.token-file.
+func readAdminAPIToken(tokenFile string) ([]byte, error) {
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	token = bytes.TrimSpace(token)
+	if len(token) == 0 {
+		return nil, fmt.Errorf("token file %s is empty", tokenFile)
+	}
+
+	return token, nil
+}
+
+// parseCollectorTimeouts parses the --scrape.collector-timeouts flag, e.g. "service:5s,mssql:3s".
+func parseCollectorTimeouts(value string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+
+	for entry := range strings.SplitSeq(value, ",") {
+		name, timeout, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid collector timeout %q, expected <collector>:<duration>", entry)
+		}
+
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout of collector %s: %w", name, err)
+		}
+
+		timeouts[name] = duration
+	}
+
+	return timeouts, nil
+}
+
+// newOTLPPusher converts the --otlp.* flags to a pusher, which sends the metrics of handler.
+func newOTLPPusher(logger *slog.Logger, handler *httphandler.MetricsHTTPHandler, startTime time.Time, flags *exporterFlags) (*otlp.Pusher, error) {
+	headers, err := parseKeyValues(*flags.otlpHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP header: %w", err)
+	}
+
+	return otlp.New(logger, handler.Gather, startTime, otlp.Options{
+		Endpoint: *flags.otlpEndpoint,
+		Interval: *flags.otlpInterval,
+		Timeout:  *flags.otlpTimeout,
+		Headers:  headers,
+		Align:    *flags.otlpAlign,
+		Jitter:   *flags.otlpJitter,
+	})
+}
+
+// newOTLPTracer converts the --otlp.traces-* flags to a tracer, which sends a trace of each scrape.
+func newOTLPTracer(logger *slog.Logger, flags *exporterFlags) (*otlp.Tracer, error) {
+	headers, err := parseKeyValues(*flags.otlpHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP header: %w", err)
+	}
+
+	return otlp.NewTracer(logger, otlp.TracerOptions{
+		Endpoint: *flags.otlpTracesEndpoint,
+		Timeout:  *flags.otlpTracesTimeout,
+		Headers:  headers,
+	})
+}
+
+// newRemoteWriteSender converts the --remote-write.* flags to a sender, which pushes the metrics of handler.
+func newRemoteWriteSender(logger *slog.Logger, handler *httphandler.MetricsHTTPHandler, flags *exporterFlags) (*remotewrite.Sender, error) {
+	headers, err := parseKeyValues(*flags.remoteWriteHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote write header: %w", err)
+	}
+
+	labels, err := parseKeyValues(*flags.remoteWriteLabels)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote write label: %w", err)
+	}
+
+	urls := make([]string, 0)
+
+	for url := range strings.SplitSeq(*flags.remoteWriteURL, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+
+	return remotewrite.New(logger, handler.Gather, remotewrite.Options{
+		URLs:     urls,
+		Interval: *flags.remoteWriteInterval,
+		Timeout:  *flags.remoteWriteTimeout,
+		Headers:  headers,
+		Labels:   labels,
+		WALDir:   *flags.remoteWriteWALDir,
+		MaxSize:  *flags.remoteWriteMaxSize,
+	})
+}
+
+// loadRelabelRules loads the rules of --relabel.config-file and adds the --relabel.tenant-labels.
+func loadRelabelRules(flags *exporterFlags) (*relabel.Rules, error) {
+	rules, err := relabel.Load(*flags.relabelConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("--relabel.config-file: %w", err)
+	}
+
+	tenantLabels, err := parseKeyValues(*flags.relabelTenantLabels)
+	if err != nil {
+		return nil, fmt.Errorf("--relabel.tenant-labels: %w", err)
+	}
+
+	rules, err = rules.WithTenantLabels(tenantLabels)
+	if err != nil {
+		return nil, fmt.Errorf("--relabel.tenant-labels: %w", err)
+	}
+
+	return rules, nil
+}
+
+// loadScrapeProfiles returns the profiles of --web.profiles-file and the profiles of the configuration file,
+// which can be selected per scrape. The profiles with collector settings collect with their own collectors,
+// except the profile selected at startup, whose settings are those of the collectors of the exporter.
+func loadScrapeProfiles(ctx context.Context, logger *slog.Logger, args []string, flags *exporterFlags) (httphandler.Profiles, error) {
+	var configProfiles httphandler.ProfilesConfig
+
+	if *flags.configFile != "" {
+		var err error
+
+		configProfiles.Profiles, err = config.ReadProfiles(*flags.configFile)
+		if err != nil {
+			return nil, fmt.Errorf("--config.file: %w", err)
+		}
+	}
+
+	newCollection := func(name string, profile httphandler.ProfileConfig) (*collector.Collection, error) {
+		if _, ok := configProfiles.Profiles[name]; ok && name == *flags.profile {
+			return nil, nil
+		}
+
+		app, profileFlags, collectors := newApplication(newLogConfig())
+
+		if _, err := config.ParseWithProfile(app, args, profile); err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		if err := setupCollection(ctx, logger, collectors, profileFlags); err != nil {
+			return nil, errors.Join(err, collectors.Close())
+		}
+
+		return collectors, nil
+	}
+
+	profiles, err := httphandler.LoadProfiles(*flags.profilesFile, configProfiles, newCollection)
+	if err != nil {
+		return nil, fmt.Errorf("--web.profiles-file: %w", err)
+	}
+
+	return profiles, nil
+}
+
+// parseKeyValues parses a comma-separated list of <name>=<value> pairs, e.g. of HTTP headers.
+func parseKeyValues(list string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	if list == "" {
+		return values, nil
+	}
+
+	for entry := range strings.SplitSeq(list, ",") {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("%q, expected <name>=<value>", entry)
+		}
+
+		values[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	return values, nil
+}
+
+// newAllowListOptions converts the --web.allowed-* flags to the options of the allow list.
+func newAllowListOptions(networks, clientCNs string) (httphandler.AllowListOptions, error) {
+	var (
+		options httphandler.AllowListOptions
+		err     error
+	)
+
+	options.Networks, err = httphandler.ParseNetworks(networks)
+	if err != nil {
+		return options, fmt.Errorf("--web.allowed-networks: %w", err)
+	}
+
+	if clientCNs != "" {
+		options.ClientNames, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", clientCNs))
+		if err != nil {
+			return options, fmt.Errorf("--web.allowed-client-cns: %w", err)
+		}
+	}
+
+	return options, nil
+}
+
+// newSNMPAgent converts the --snmp.* flags to an agent, which exposes the metrics of snmp.Collectors of handler.
+func newSNMPAgent(logger *slog.Logger, handler *httphandler.MetricsHTTPHandler, startTime time.Time, flags *exporterFlags) (*snmp.Agent, error) {
+	gather := func(timeout time.Duration) ([]*dto.MetricFamily, error) {
+		return handler.GatherCollectors(timeout, snmp.Collectors)
+	}
+
+	return snmp.New(logger, gather, startTime, snmp.Options{
+		ListenAddress:   *flags.snmpListenAddress,
+		Community:       *flags.snmpCommunity,
+		BaseOID:         *flags.snmpBaseOID,
+		RefreshInterval: *flags.snmpRefreshInterval,
+	})
+}
+
+// newZabbixSender converts the --zabbix.* flags to a sender, which sends the metrics of handler.
+func newZabbixSender(logger *slog.Logger, handler *httphandler.MetricsHTTPHandler, flags *exporterFlags) (*zabbix.Sender, error) {
+	if *flags.zabbixKeysFile == "" {
+		return nil, errors.New("--zabbix.keys-file is required")
+	}
+
+	keys, err := zabbix.LoadKeys(*flags.zabbixKeysFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return zabbix.New(logger, handler.Gather, zabbix.Options{
+		Server:   *flags.zabbixServer,
+		Host:     *flags.zabbixHost,
+		Interval: *flags.zabbixInterval,
+		Timeout:  *flags.zabbixTimeout,
+		Keys:     keys,
+	})
+}
+
+// newProbeOptions converts the --probe.* flags to the options of the /probe handler.
+func newProbeOptions(collectors, protocol, authentication, username, passwordFile, targets string) (httphandler.ProbeOptions, error) {
+	options := httphandler.ProbeOptions{
+		DefaultCollectors: slices.Compact(strings.Split(collectors, ",")),
+		Remote: collector.RemoteOptions{
+			Protocol: mi.ProtocolWinRM,
+			AuthenticationType: map[string]string{
+				"default":   mi.AuthenticationTypeDefault,
+				"negotiate": mi.AuthenticationTypeNegotiate,
+				"kerberos":  mi.AuthenticationTypeKerberos,
+				"ntlm":      mi.AuthenticationTypeNTLM,
+			}[authentication],
+		},
+	}
+
+	if protocol == "dcom" {
+		options.Remote.Protocol = mi.ProtocolWMIDCOM
+	}
+
+	for _, name := range options.DefaultCollectors {
+		if !slices.Contains(collector.RemoteCollectors(), name) {
+			return options, fmt.Errorf("collector %s in --probe.collectors doesn't support remote hosts", name)
+		}
+	}
+
+	if username != "" {
+		if domain, user, ok := strings.Cut(username, `\`); ok {
+			options.Remote.Domain, options.Remote.Username = domain, user
+		} else {
+			options.Remote.Username = username
+		}
+
+		if passwordFile != "" {
+			password, err := os.ReadFile(passwordFile)
+			if err != nil {
+				return options, fmt.Errorf("failed to read --probe.password-file: %w", err)
+			}
+
+			options.Remote.Password = strings.TrimRight(string(password), "\r\n")
+		}
+	}
+
+	var err error
+
+	options.Targets, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", targets))
+	if err != nil {
+		return options, fmt.Errorf("failed to compile --probe.targets: %w", err)
+	}
+
+	return options, nil
+}
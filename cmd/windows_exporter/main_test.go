@@ -26,10 +26,13 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus-community/windows_exporter/internal/config"
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sys/windows"
 )
@@ -43,6 +46,7 @@ func TestRun(t *testing.T) {
 		args            []string
 		config          string
 		metricsEndpoint string
+		reloadEndpoint  string
 		exitCode        int
 	}{
 		{
@@ -72,6 +76,23 @@ func TestRun(t *testing.T) {
 			config:          `{"web":{"listen-address":"127.0.0.1:8083"}}`,
 			metricsEndpoint: "http://127.0.0.1:8084/metrics",
 		},
+		{
+			name:            "web.enable-reload",
+			args:            []string{"--config.file=config.yaml", "--web.enable-reload"},
+			config:          `{"web":{"listen-address":"127.0.0.1:8087"}}`,
+			metricsEndpoint: "http://127.0.0.1:8087/metrics",
+			reloadEndpoint:  "http://127.0.0.1:8087/-/reload",
+		},
+		{
+			name:            "scrape.collector-timeouts",
+			args:            []string{"--web.listen-address=127.0.0.1:8085", "--scrape.collector-timeouts=service:5s,cpu:1s"},
+			metricsEndpoint: "http://127.0.0.1:8085/metrics",
+		},
+		{
+			name:     "scrape.collector-timeouts invalid",
+			args:     []string{"--web.listen-address=127.0.0.1:8086", "--scrape.collector-timeouts=service"},
+			exitCode: 1,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx, cancel := context.WithCancel(t.Context())
@@ -140,11 +161,64 @@ func TestRun(t *testing.T) {
 			require.NotEmpty(t, body)
 			require.Contains(t, string(body), "# HELP windows_exporter_build_info")
 
+			if tc.reloadEndpoint != "" {
+				req, err = http.NewRequestWithContext(ctx, http.MethodPost, tc.reloadEndpoint, nil)
+				require.NoError(t, err)
+
+				resp, err = http.DefaultClient.Do(req)
+				require.NoError(t, err, "LOGS:\n%s", stdout)
+				require.NoError(t, resp.Body.Close())
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+
+				req, err = http.NewRequestWithContext(ctx, http.MethodGet, tc.metricsEndpoint, nil)
+				require.NoError(t, err)
+
+				resp, err = http.DefaultClient.Do(req)
+				require.NoError(t, err, "LOGS:\n%s", stdout)
+				require.NoError(t, resp.Body.Close())
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+			}
+
 			cancel()
 		})
 	}
 }
 
+func TestConfigDefaults(t *testing.T) {
+	t.Parallel()
+
+	app, _, _ := newApplication(newLogConfig())
+
+	_, err := config.Schema(app)
+	require.NoError(t, err)
+
+	defaults, err := config.Defaults(app)
+	require.NoError(t, err)
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, defaults, 0o600))
+
+	// The defaults must be accepted by the strict validation of the configuration file.
+	app, flags, _ := newApplication(newLogConfig())
+
+	command, err := config.Parse(app, []string{"--config.file=" + configFile})
+	require.NoError(t, err)
+	require.Equal(t, runCommand, command)
+	require.Equal(t, collector.DefaultCollectors, *flags.enabledCollectors)
+}
+
+func TestConfigUnknownField(t *testing.T) {
+	t.Parallel()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("collector:\n  update:\n    scrape_interval: 1h\n"), 0o600))
+
+	app, _, _ := newApplication(newLogConfig())
+
+	_, err := config.Parse(app, []string{"--config.file=" + configFile})
+	require.ErrorContains(t, err, "field scrape_interval not found")
+}
+
 func captureOutput(tb testing.TB, f func()) string {
 	tb.Helper()
 
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/prometheus-community/windows_exporter/internal/config"
+	"github.com/prometheus-community/windows_exporter/internal/httphandler"
+)
+
+// reloader rebuilds the collectors from the command line arguments and the configuration file,
+// without restarting windows_exporter. Only the collector selection, the collector flags and
+// the --scrape.collector-timeouts, --web.cache-*, --web.profiles-file, --relabel.config-file and --relabel.tenant-labels flags are applied; other flags require a restart.
+type reloader struct {
+	// mu serializes reloads.
+	mu      sync.Mutex
+	args    []string
+	logger  *slog.Logger
+	handler *httphandler.MetricsHTTPHandler
+}
+
+func (r *reloader) Reload(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.reload(ctx)
+}
+
+// SetCollectorEnabled adds or removes a collector from collectors.enabled in the configuration file
+// and reloads the configuration, so the change survives a restart.
+func (r *reloader) SetCollectorEnabled(ctx context.Context, name string, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	configFile := config.ParseConfigFile(r.args)
+	if configFile == "" {
+		return errors.New("persisting requires a configuration file, see --config.file")
+	}
+
+	// CLI flags override the configuration file, so the change would have no effect.
+	if slices.ContainsFunc(r.args, func(arg string) bool { return strings.HasPrefix(strings.TrimLeft(arg, "-"), "collectors.enabled") }) {
+		return errors.New("--collectors.enabled is set on the command line and overrides the configuration file")
+	}
+
+	app, flags, _ := newApplication(newLogConfig())
+
+	if _, err := config.Parse(app, r.args); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// The collectors of the profile override the configuration file, so the change would have no effect.
+	if *flags.profile != "" {
+		profiles, err := config.ReadProfiles(configFile)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		if len(profiles[*flags.profile].Collectors) > 0 {
+			return fmt.Errorf("collectors.enabled is set by the profile %s and overrides the configuration file", *flags.profile)
+		}
+	}
+
+	if enabled && slices.Contains(expandEnabledCollectors(*flags.disabledCollectors), name) {
+		return fmt.Errorf("collector %s is disabled by --collectors.disabled", name)
+	}
+
+	enabledCollectors := slices.DeleteFunc(expandEnabledCollectors(*flags.enabledCollectors), func(collectorName string) bool {
+		return collectorName == name || collectorName == ""
+	})
+
+	if enabled {
+		enabledCollectors = append(enabledCollectors, name)
+	}
+
+	if len(enabledCollectors) == 0 {
+		return errors.New("at least one collector must be enabled")
+	}
+
+	if err := config.SetEnabledCollectors(configFile, enabledCollectors); err != nil {
+		return err
+	}
+
+	return r.reload(ctx)
+}
+
+// reload must be called with mu held.
+func (r *reloader) reload(ctx context.Context) error {
+	r.logger.LogAttrs(ctx, slog.LevelInfo, "reloading configuration")
+
+	app, flags, collectors := newApplication(newLogConfig())
+
+	if _, err := config.Parse(app, r.args); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	relabelRules, err := loadRelabelRules(flags)
+	if err != nil {
+		return fmt.Errorf("failed to load relabeling rules: %w", err)
+	}
+
+	profiles, err := loadScrapeProfiles(ctx, r.logger, r.args, flags)
+	if err != nil {
+		return fmt.Errorf("failed to load scrape profiles: %w", err)
+	}
+
+	if err := setupCollection(ctx, r.logger, collectors, flags); err != nil {
+		return errors.Join(fmt.Errorf("couldn't initialize collectors: %w", err), collectors.Close(), profiles.Close())
+	}
+
+	r.handler.SetRelabelRules(relabelRules)
+
+	if err := r.handler.SetProfiles(profiles).Close(); err != nil {
+		r.logger.LogAttrs(ctx, slog.LevelWarn, "couldn't close the collectors of the previous profiles",
+			slog.Any("err", err),
+		)
+	}
+
+	previous := r.handler.SetCollection(collectors)
+	if err := previous.Close(); err != nil {
+		r.logger.LogAttrs(ctx, slog.LevelWarn, "couldn't close previous collectors",
+			slog.Any("err", err),
+		)
+	}
+
+	r.logger.LogAttrs(ctx, slog.LevelInfo, "reloaded configuration. Enabled collectors: "+strings.Join(expandEnabledCollectors(*flags.enabledCollectors), ", "))
+
+	return nil
+}
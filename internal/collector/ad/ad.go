@@ -36,6 +36,8 @@ type Config struct{}
 var ConfigDefaults = Config{}
 
 type Collector struct {
+	collectorDatabase
+
 	config Config
 
 	perfDataCollector *pdh.Collector
@@ -48,6 +50,8 @@ type Collector struct {
 	atqCurrentThreads                                   *prometheus.Desc
 	atqEstimatedDelaySeconds                            *prometheus.Desc
 	atqOutstandingRequests                              *prometheus.Desc
+	atqQueueLatencySeconds                              *prometheus.Desc
+	atqThreads                                          *prometheus.Desc
 	bindsTotal                                          *prometheus.Desc
 	changeMonitorUpdatesPending                         *prometheus.Desc
 	changeMonitorsRegistered                            *prometheus.Desc
@@ -127,6 +131,7 @@ func (c *Collector) GetName() string {
 
 func (c *Collector) Close() error {
 	c.perfDataCollector.Close()
+	c.closeDatabase()
 
 	return nil
 }
@@ -174,6 +179,18 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		[]string{"service"},
 		nil,
 	)
+	c.atqQueueLatencySeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "atq_queue_latency_seconds"),
+		"Average time requests spent in the ATQ queue before they were processed",
+		nil,
+		nil,
+	)
+	c.atqThreads = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "atq_threads"),
+		"Number of threads allocated by the ATQ, including idle threads",
+		nil,
+		nil,
+	)
 	c.searchesTotal = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, Name, "searches_total"),
 		"",
@@ -513,6 +530,10 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		return fmt.Errorf("failed to create DirectoryServices collector: %w", err)
 	}
 
+	if err := c.buildDatabase(); err != nil {
+		return fmt.Errorf("failed to build database metrics: %w", err)
+	}
+
 	return nil
 }
 
@@ -612,6 +633,18 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		"other",
 	)
 
+	ch <- prometheus.MustNewConstMetric(
+		c.atqQueueLatencySeconds,
+		prometheus.GaugeValue,
+		c.perfDataObject[0].AtqQueueLatency/1000,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.atqThreads,
+		prometheus.GaugeValue,
+		c.perfDataObject[0].AtqThreadsTotal,
+	)
+
 	ch <- prometheus.MustNewConstMetric(
 		c.searchesTotal,
 		prometheus.CounterValue,
@@ -1288,5 +1321,5 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		c.perfDataObject[0].TombstonesVisitedPerSec,
 	)
 
-	return nil
+	return c.collectDatabase(ch)
 }
@@ -0,0 +1,233 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package ad
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	ntdsParametersKey = `SYSTEM\CurrentControlSet\Services\NTDS\Parameters`
+
+	directoryServiceChannel = "Directory Service"
+	// Event 1646 reports the free space within the database after the online defragmentation
+	// of the garbage collection. It is only logged if the diagnostic logging level of
+	// "6 Garbage Collection" is at least 1.
+	databaseWhitespaceQuery = "*[System[EventID=1646]]"
+)
+
+// Values of event 1646 rendered by the user render context.
+const (
+	databaseWhitespaceValueFreeMB = iota
+	databaseWhitespaceValueAllocatedMB
+)
+
+type collectorDatabase struct {
+	databasePath          string
+	logPath               string
+	databaseRenderContext wevtapi.EVT_HANDLE
+
+	databaseSizeBytes          *prometheus.Desc
+	databaseWhitespaceBytes    *prometheus.Desc
+	databaseVolumeFreeBytes    *prometheus.Desc
+	databaseLogFilesSizeBytes  *prometheus.Desc
+	databaseLogVolumeFreeBytes *prometheus.Desc
+}
+
+func (c *Collector) buildDatabase() error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, ntdsParametersKey, registry.QUERY_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open registry key %s: %w", ntdsParametersKey, err)
+	}
+
+	defer func() {
+		_ = key.Close()
+	}()
+
+	c.databasePath, _, err = key.GetStringValue("DSA Database file")
+	if err != nil {
+		return fmt.Errorf("failed to read database path: %w", err)
+	}
+
+	c.logPath, _, err = key.GetStringValue("Database log files path")
+	if err != nil {
+		return fmt.Errorf("failed to read database log path: %w", err)
+	}
+
+	c.databaseSizeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "database_size_bytes"),
+		"Size of the directory database file (ntds.dit)",
+		nil,
+		nil,
+	)
+	c.databaseWhitespaceBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "database_whitespace_bytes"),
+		"Free space within the directory database file, which an offline defragmentation would reclaim, as logged by the latest event 1646",
+		nil,
+		nil,
+	)
+	c.databaseVolumeFreeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "database_volume_free_bytes"),
+		"Free space of the volume of the directory database file",
+		[]string{"volume"},
+		nil,
+	)
+	c.databaseLogFilesSizeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "database_log_files_size_bytes"),
+		"Size of the transaction log files of the directory database",
+		nil,
+		nil,
+	)
+	c.databaseLogVolumeFreeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "database_log_volume_free_bytes"),
+		"Free space of the volume of the transaction log files of the directory database",
+		[]string{"volume"},
+		nil,
+	)
+
+	c.databaseRenderContext, err = wevtapi.EvtCreateUserRenderContext()
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Collector) closeDatabase() {
+	if c.databaseRenderContext != 0 {
+		_ = wevtapi.EvtClose(c.databaseRenderContext)
+	}
+}
+
+func (c *Collector) collectDatabase(ch chan<- prometheus.Metric) error {
+	info, err := os.Stat(c.databasePath)
+	if err != nil {
+		return fmt.Errorf("failed to get size of %s: %w", c.databasePath, err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.databaseSizeBytes,
+		prometheus.GaugeValue,
+		float64(info.Size()),
+	)
+
+	logFiles, err := filepath.Glob(filepath.Join(c.logPath, "*.log"))
+	if err != nil {
+		return fmt.Errorf("failed to list log files of %s: %w", c.logPath, err)
+	}
+
+	var logFilesSize int64
+
+	for _, logFile := range logFiles {
+		// Log files are rotated by the database engine, a file removed after listing is skipped.
+		if info, err := os.Stat(logFile); err == nil {
+			logFilesSize += info.Size()
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.databaseLogFilesSizeBytes,
+		prometheus.GaugeValue,
+		float64(logFilesSize),
+	)
+
+	for desc, path := range map[*prometheus.Desc]string{
+		c.databaseVolumeFreeBytes:    c.databasePath,
+		c.databaseLogVolumeFreeBytes: c.logPath,
+	} {
+		volume, freeBytes, err := volumeFreeSpace(path)
+		if err != nil {
+			return fmt.Errorf("failed to get free space of the volume of %s: %w", path, err)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			desc,
+			prometheus.GaugeValue,
+			float64(freeBytes),
+			volume,
+		)
+	}
+
+	values, err := wevtapi.LatestEventValues(directoryServiceChannel, databaseWhitespaceQuery, c.databaseRenderContext)
+	if err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", directoryServiceChannel, err)
+	}
+
+	// Without an event, the diagnostic logging of the garbage collection is disabled.
+	if len(values) <= databaseWhitespaceValueAllocatedMB {
+		return nil
+	}
+
+	freeMB, err := eventValueFloat(values[databaseWhitespaceValueFreeMB])
+	if err != nil {
+		return fmt.Errorf("failed to parse free space of event 1646: %w", err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.databaseWhitespaceBytes,
+		prometheus.GaugeValue,
+		freeMB*1024*1024,
+	)
+
+	return nil
+}
+
+// volumeFreeSpace returns the mount point of the volume of path, e.g. C:, and the free space of the volume.
+func volumeFreeSpace(path string) (string, uint64, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return "", 0, err
+	}
+
+	volumePath := make([]uint16, windows.MAX_PATH+1)
+	if err := windows.GetVolumePathName(pathPtr, &volumePath[0], uint32(len(volumePath))); err != nil {
+		return "", 0, fmt.Errorf("GetVolumePathName: %w", err)
+	}
+
+	var freeBytes uint64
+	if err := windows.GetDiskFreeSpaceEx(&volumePath[0], nil, nil, &freeBytes); err != nil {
+		return "", 0, fmt.Errorf("GetDiskFreeSpaceEx: %w", err)
+	}
+
+	return strings.TrimSuffix(windows.UTF16ToString(volumePath), `\`), freeBytes, nil
+}
+
+// eventValueFloat converts an event value rendered by the user render context to a float.
+// Classic event providers log their insertion strings as strings.
+func eventValueFloat(value any) (float64, error) {
+	switch v := value.(type) {
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	case uint64:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	default:
+		return 0, fmt.Errorf("unexpected value type %T", value)
+	}
+}
@@ -28,7 +28,7 @@ type perfDataCounterValues struct {
 	ApproximateHighestDNT                                            float64 `perfdata:"Approximate highest DNT"`
 	AtqEstimatedQueueDelay                                           float64 `perfdata:"ATQ Estimated Queue Delay"`
 	AtqOutstandingQueuedRequests                                     float64 `perfdata:"ATQ Outstanding Queued Requests"`
-	_                                                                float64 `perfdata:"ATQ Queue Latency"`
+	AtqQueueLatency                                                  float64 `perfdata:"ATQ Queue Latency"`
 	AtqRequestLatency                                                float64 `perfdata:"ATQ Request Latency"`
 	AtqThreadsLDAP                                                   float64 `perfdata:"ATQ Threads LDAP"`
 	AtqThreadsOther                                                  float64 `perfdata:"ATQ Threads Other"`
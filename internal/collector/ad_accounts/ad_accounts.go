@@ -0,0 +1,446 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package ad_accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/sysinfoapi"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wldap32"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "ad_accounts"
+
+	ntdsParametersKey = `SYSTEM\CurrentControlSet\Services\NTDS\Parameters`
+)
+
+type Config struct {
+	ScanInterval     time.Duration `yaml:"scan-interval"`
+	StaleComputerAge time.Duration `yaml:"stale-computer-age"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	ScanInterval:     time.Hour,
+	StaleComputerAge: 90 * 24 * time.Hour,
+}
+
+// LDAP filters of the counted accounts. Bit 2 of userAccountControl is ACCOUNTDISABLE and bit 65536
+// is DONT_EXPIRE_PASSWORD, matched with the LDAP_MATCHING_RULE_BIT_AND rule.
+// 📑 https://learn.microsoft.com/en-us/troubleshoot/windows-server/active-directory/useraccountcontrol-manipulate-account-properties
+const (
+	filterUsers     = "(&(objectCategory=person)(objectClass=user))"
+	filterComputers = "(objectCategory=computer)"
+	filterEnabled   = "(!(userAccountControl:1.2.840.113556.1.4.803:=2))"
+	filterDisabled  = "(userAccountControl:1.2.840.113556.1.4.803:=2)"
+
+	filterPasswordNeverExpires = "(userAccountControl:1.2.840.113556.1.4.803:=65536)"
+
+	// filterKrbtgt matches the account of the KDC of the domain, but not the krbtgt_<number> accounts
+	// of read-only domain controllers.
+	filterKrbtgt = "(&(objectClass=user)(sAMAccountName=krbtgt))"
+)
+
+// A Collector is a Prometheus Collector for the hygiene of the accounts of the Active Directory domain of
+// a domain controller: the number of enabled and disabled users and computers, of expired users, of users
+// whose password never expires, of computers that didn't log on for StaleComputerAge and the age of the
+// password of the krbtgt account.
+// The directory is searched in the background every ScanInterval, since counting the accounts of large
+// domains takes a while.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	hostname string
+
+	ctxCancelFn context.CancelFunc
+
+	// mu protects the result of the last scan.
+	mu           sync.RWMutex
+	counts       accountCounts
+	scanDuration float64
+	scanTime     time.Time
+
+	users                     *prometheus.Desc
+	computers                 *prometheus.Desc
+	expiredUsers              *prometheus.Desc
+	passwordNeverExpiresUsers *prometheus.Desc
+	staleComputers            *prometheus.Desc
+	krbtgtPasswordLastSet     *prometheus.Desc
+	krbtgtPasswordAgeSeconds  *prometheus.Desc
+	scanDurationSeconds       *prometheus.Desc
+	scanTimestampSeconds      *prometheus.Desc
+}
+
+type accountCounts struct {
+	enabledUsers              int
+	disabledUsers             int
+	expiredUsers              int
+	passwordNeverExpiresUsers int
+	enabledComputers          int
+	disabledComputers         int
+	staleComputers            int
+
+	// krbtgtPasswordLastSet is zero if the krbtgt account wasn't found.
+	krbtgtPasswordLastSet time.Time
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.ScanInterval == 0 {
+		config.ScanInterval = ConfigDefaults.ScanInterval
+	}
+
+	if config.StaleComputerAge == 0 {
+		config.StaleComputerAge = ConfigDefaults.StaleComputerAge
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	app.Flag(
+		"collector.ad_accounts.scan-interval",
+		"Interval of counting the accounts of the domain.",
+	).Default(ConfigDefaults.ScanInterval.String()).DurationVar(&c.config.ScanInterval)
+
+	app.Flag(
+		"collector.ad_accounts.stale-computer-age",
+		"Time since the last logon after which an enabled computer account is counted as stale.",
+	).Default(ConfigDefaults.StaleComputerAge.String()).DurationVar(&c.config.StaleComputerAge)
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+// LastSuccess returns the time of the last successful scan, see collector.BackgroundCollector.
+func (c *Collector) LastSuccess() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.scanTime
+}
+
+func (c *Collector) Close() error {
+	if c.ctxCancelFn != nil {
+		c.ctxCancelFn()
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	if c.config.ScanInterval <= 0 {
+		return fmt.Errorf("invalid scan interval %s, expected a positive duration", c.config.ScanInterval)
+	}
+
+	if c.config.StaleComputerAge <= 0 {
+		return fmt.Errorf("invalid stale computer age %s, expected a positive duration", c.config.StaleComputerAge)
+	}
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, ntdsParametersKey, registry.QUERY_VALUE)
+	if err != nil {
+		return fmt.Errorf("host is not a domain controller: failed to open registry key %s: %w", ntdsParametersKey, err)
+	}
+
+	_ = key.Close()
+
+	// The directory of the local domain controller is searched. Binding to the DNS name instead of
+	// localhost allows Kerberos authentication.
+	c.hostname, err = sysinfoapi.GetComputerName(sysinfoapi.ComputerNameDNSFullyQualified)
+	if err != nil {
+		return fmt.Errorf("failed to get DNS name of the computer: %w", err)
+	}
+
+	c.users = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "users"),
+		"Number of user accounts of the domain by state at the last scan",
+		[]string{"state"},
+		nil,
+	)
+	c.computers = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "computers"),
+		"Number of computer accounts of the domain by state at the last scan",
+		[]string{"state"},
+		nil,
+	)
+	c.expiredUsers = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "expired_users"),
+		"Number of enabled user accounts whose account expiration date passed at the last scan",
+		nil,
+		nil,
+	)
+	c.passwordNeverExpiresUsers = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "password_never_expires_users"),
+		"Number of enabled user accounts whose password never expires at the last scan",
+		nil,
+		nil,
+	)
+	c.staleComputers = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "stale_computers"),
+		"Number of enabled computer accounts without a logon within the stale computer age at the last scan",
+		nil,
+		nil,
+	)
+	c.krbtgtPasswordLastSet = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "krbtgt_password_last_set_timestamp_seconds"),
+		"Timestamp of the last password change of the krbtgt account",
+		nil,
+		nil,
+	)
+	c.krbtgtPasswordAgeSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "krbtgt_password_age_seconds"),
+		"Time since the last password change of the krbtgt account",
+		nil,
+		nil,
+	)
+	c.scanDurationSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "scan_duration_seconds"),
+		"Duration of the last scan of the accounts of the domain",
+		nil,
+		nil,
+	)
+	c.scanTimestampSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "scan_timestamp_seconds"),
+		"Timestamp of the end of the last scan of the accounts of the domain",
+		nil,
+		nil,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.ctxCancelFn = cancel
+
+	go c.scheduleScan(ctx)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// The metrics are missing until the first scan finished.
+	if c.scanTime.IsZero() {
+		return nil
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.users, prometheus.GaugeValue, float64(c.counts.enabledUsers), "enabled")
+	ch <- prometheus.MustNewConstMetric(c.users, prometheus.GaugeValue, float64(c.counts.disabledUsers), "disabled")
+	ch <- prometheus.MustNewConstMetric(c.computers, prometheus.GaugeValue, float64(c.counts.enabledComputers), "enabled")
+	ch <- prometheus.MustNewConstMetric(c.computers, prometheus.GaugeValue, float64(c.counts.disabledComputers), "disabled")
+
+	ch <- prometheus.MustNewConstMetric(
+		c.expiredUsers,
+		prometheus.GaugeValue,
+		float64(c.counts.expiredUsers),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.passwordNeverExpiresUsers,
+		prometheus.GaugeValue,
+		float64(c.counts.passwordNeverExpiresUsers),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.staleComputers,
+		prometheus.GaugeValue,
+		float64(c.counts.staleComputers),
+	)
+
+	if !c.counts.krbtgtPasswordLastSet.IsZero() {
+		ch <- prometheus.MustNewConstMetric(
+			c.krbtgtPasswordLastSet,
+			prometheus.GaugeValue,
+			float64(c.counts.krbtgtPasswordLastSet.Unix()),
+		)
+		// The age is computed at the scrape, so that it grows between the scans.
+		ch <- prometheus.MustNewConstMetric(
+			c.krbtgtPasswordAgeSeconds,
+			prometheus.GaugeValue,
+			time.Since(c.counts.krbtgtPasswordLastSet).Seconds(),
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.scanDurationSeconds,
+		prometheus.GaugeValue,
+		c.scanDuration,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.scanTimestampSeconds,
+		prometheus.GaugeValue,
+		float64(c.scanTime.Unix()),
+	)
+
+	return nil
+}
+
+func (c *Collector) scheduleScan(ctx context.Context) {
+	for {
+		if err := c.scan(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			c.logger.LogAttrs(ctx, slog.LevelWarn, "failed to scan the accounts of the domain",
+				slog.Any("err", err),
+			)
+		}
+
+		select {
+		case <-time.After(c.config.ScanInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Collector) scan(ctx context.Context) error {
+	start := time.Now()
+
+	conn, err := wldap32.Connect(c.hostname)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the directory of %s: %w", c.hostname, err)
+	}
+
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	namingContexts, err := conn.Values("", wldap32.LDAP_SCOPE_BASE, "(objectClass=*)", "defaultNamingContext")
+	if err != nil {
+		return fmt.Errorf("failed to read defaultNamingContext of the RootDSE: %w", err)
+	}
+
+	if len(namingContexts) == 0 {
+		return errors.New("RootDSE has no defaultNamingContext")
+	}
+
+	baseDN := namingContexts[0]
+
+	var counts accountCounts
+
+	for filter, count := range accountFilters(start, c.config.StaleComputerAge, &counts) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if *count, err = conn.Count(baseDN, filter); err != nil {
+			return fmt.Errorf("failed to count the accounts matching %s: %w", filter, err)
+		}
+	}
+
+	pwdLastSet, err := conn.Values(baseDN, wldap32.LDAP_SCOPE_SUBTREE, filterKrbtgt, "pwdLastSet")
+	if err != nil {
+		return fmt.Errorf("failed to read pwdLastSet of the krbtgt account: %w", err)
+	}
+
+	if len(pwdLastSet) > 0 {
+		if counts.krbtgtPasswordLastSet, err = parseFileTime(pwdLastSet[0]); err != nil {
+			return fmt.Errorf("failed to parse pwdLastSet of the krbtgt account: %w", err)
+		}
+	} else {
+		c.logger.Debug("krbtgt account not found",
+			slog.String("base_dn", baseDN),
+		)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts = counts
+	c.scanTime = time.Now()
+	c.scanDuration = c.scanTime.Sub(start).Seconds()
+
+	return nil
+}
+
+// accountFilters returns the LDAP filters of the counted accounts with the count they are stored in.
+// Accounts expire and computers become stale relative to now.
+func accountFilters(now time.Time, staleComputerAge time.Duration, counts *accountCounts) map[string]*int {
+	staleSince := now.Add(-staleComputerAge)
+
+	// accountExpires is 0 or 0x7FFFFFFFFFFFFFFF for accounts that never expire.
+	expired := fmt.Sprintf("(accountExpires>=1)(accountExpires<=%d)", fileTime(now))
+
+	// lastLogonTimestamp is replicated with a delay of up to 14 days, see msDS-LogonTimeSyncInterval.
+	// Computers that never logged on are stale if they were created before the stale computer age.
+	stale := fmt.Sprintf("(|(lastLogonTimestamp<=%d)(&(!(lastLogonTimestamp=*))(whenCreated<=%s)))",
+		fileTime(staleSince), generalizedTime(staleSince))
+
+	return map[string]*int{
+		"(&" + filterUsers + filterEnabled + ")":                              &counts.enabledUsers,
+		"(&" + filterUsers + filterDisabled + ")":                             &counts.disabledUsers,
+		"(&" + filterUsers + filterEnabled + expired + ")":                    &counts.expiredUsers,
+		"(&" + filterUsers + filterEnabled + filterPasswordNeverExpires + ")": &counts.passwordNeverExpiresUsers,
+		"(&" + filterComputers + filterEnabled + ")":                          &counts.enabledComputers,
+		"(&" + filterComputers + filterDisabled + ")":                         &counts.disabledComputers,
+		"(&" + filterComputers + filterEnabled + stale + ")":                  &counts.staleComputers,
+	}
+}
+
+// fileTimeEpochOffset is the number of 100-nanosecond intervals between 1601-01-01 and 1970-01-01.
+const fileTimeEpochOffset = 116444736000000000
+
+// fileTime returns the time in the format of the Integer8 time attributes, 100-nanosecond intervals since 1601.
+func fileTime(t time.Time) int64 {
+	return t.UnixNano()/100 + fileTimeEpochOffset
+}
+
+// parseFileTime parses an Integer8 time attribute like pwdLastSet. 0 means the time is not set.
+func parseFileTime(value string) (time.Time, error) {
+	intervals, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if intervals <= 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(0, (intervals-fileTimeEpochOffset)*100), nil
+}
+
+// generalizedTime returns the time in the format of the Generalized-Time attributes like whenCreated.
+func generalizedTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.0Z")
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package ad_accounts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileTime(t *testing.T) {
+	t.Parallel()
+
+	// 2024-01-01T00:00:00Z in 100-nanosecond intervals since 1601.
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, int64(133485408000000000), fileTime(ts))
+
+	parsed, err := parseFileTime("133485408000000000")
+	require.NoError(t, err)
+	require.True(t, ts.Equal(parsed))
+
+	parsed, err = parseFileTime("0")
+	require.NoError(t, err)
+	require.True(t, parsed.IsZero())
+
+	_, err = parseFileTime("never")
+	require.Error(t, err)
+}
+
+func TestAccountFilters(t *testing.T) {
+	t.Parallel()
+
+	var counts accountCounts
+
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	filters := accountFilters(now, 90*24*time.Hour, &counts)
+	require.Len(t, filters, 7)
+
+	for filter, count := range filters {
+		if count == &counts.staleComputers {
+			require.Equal(t,
+				"(&(objectCategory=computer)(!(userAccountControl:1.2.840.113556.1.4.803:=2))"+
+					"(|(lastLogonTimestamp<=133485840000000000)(&(!(lastLogonTimestamp=*))(whenCreated<=20240101120000.0Z))))",
+				filter,
+			)
+		}
+
+		if count == &counts.expiredUsers {
+			require.Contains(t, filter, "(accountExpires>=1)(accountExpires<=133563600000000000)")
+		}
+	}
+}
@@ -0,0 +1,317 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package ad_replication
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const Name = "ad_replication"
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+// A Collector is a Prometheus Collector for the inbound replication partners of a domain controller,
+// read from the WMI replication provider like repadmin /showrepl.
+type Collector struct {
+	config    Config
+	miSession *mi.Session
+
+	neighborMIQuery  mi.Query
+	pendingOpMIQuery mi.Query
+
+	lastSyncSuccess         *prometheus.Desc
+	lastSyncAttempt         *prometheus.Desc
+	lastSyncResult          *prometheus.Desc
+	consecutiveSyncFailures *prometheus.Desc
+	usnLastObjChangeSynced  *prometheus.Desc
+	largestDelta            *prometheus.Desc
+	pendingOperations       *prometheus.Desc
+}
+
+// msadReplNeighbor represents the MSAD_ReplNeighbor WMI class
+// - https://learn.microsoft.com/en-us/previous-versions/windows/desktop/adrepl/msad-replneighbor
+type msadReplNeighbor struct {
+	NamingContextDN            string    `mi:"NamingContextDN"`
+	SourceDsaCN                string    `mi:"SourceDsaCN"`
+	SourceDsaSite              string    `mi:"SourceDsaSite"`
+	IsDeletedSourceDsa         bool      `mi:"IsDeletedSourceDsa"`
+	LastSyncResult             uint32    `mi:"LastSyncResult"`
+	NumConsecutiveSyncFailures uint32    `mi:"NumConsecutiveSyncFailures"`
+	TimeOfLastSyncAttempt      time.Time `mi:"TimeOfLastSyncAttempt"`
+	TimeOfLastSyncSuccess      time.Time `mi:"TimeOfLastSyncSuccess"`
+	USNLastObjChangeSynced     int64     `mi:"USNLastObjChangeSynced"`
+}
+
+// msadReplPendingOp represents the MSAD_ReplPendingOp WMI class
+// - https://learn.microsoft.com/en-us/previous-versions/windows/desktop/adrepl/msad-replpendingop
+type msadReplPendingOp struct {
+	DsaDN string `mi:"DsaDN"`
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(_ *slog.Logger, miSession *mi.Session) error {
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.miSession = miSession
+
+	neighborMIQuery, err := mi.NewQuery("SELECT NamingContextDN,SourceDsaCN,SourceDsaSite,IsDeletedSourceDsa,LastSyncResult,NumConsecutiveSyncFailures,TimeOfLastSyncAttempt,TimeOfLastSyncSuccess,USNLastObjChangeSynced FROM MSAD_ReplNeighbor")
+	if err != nil {
+		return fmt.Errorf("failed to create WMI query: %w", err)
+	}
+
+	c.neighborMIQuery = neighborMIQuery
+
+	pendingOpMIQuery, err := mi.NewQuery("SELECT DsaDN FROM MSAD_ReplPendingOp")
+	if err != nil {
+		return fmt.Errorf("failed to create WMI query: %w", err)
+	}
+
+	c.pendingOpMIQuery = pendingOpMIQuery
+
+	c.lastSyncSuccess = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "last_sync_success_timestamp_seconds"),
+		"Time of the last successful inbound replication from the partner. 0 if the partner never replicated successfully.",
+		[]string{"partner", "partner_site", "naming_context"},
+		nil,
+	)
+	c.lastSyncAttempt = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "last_sync_attempt_timestamp_seconds"),
+		"Time of the last inbound replication attempt from the partner.",
+		[]string{"partner", "partner_site", "naming_context"},
+		nil,
+	)
+	c.lastSyncResult = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "last_sync_result"),
+		"Win32 error code of the last inbound replication attempt from the partner. 0 if it was successful.",
+		[]string{"partner", "partner_site", "naming_context"},
+		nil,
+	)
+	c.consecutiveSyncFailures = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "consecutive_sync_failures"),
+		"Number of consecutive failed inbound replication attempts from the partner.",
+		[]string{"partner", "partner_site", "naming_context"},
+		nil,
+	)
+	c.usnLastObjChangeSynced = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "partner_usn_synced"),
+		"USN of the partner up to which its changes have been replicated to this domain controller.",
+		[]string{"partner", "partner_site", "naming_context"},
+		nil,
+	)
+	c.largestDelta = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "largest_delta_seconds"),
+		"Longest time since the last successful inbound replication of any partner and naming context, like the largest delta of repadmin /replsummary.",
+		nil,
+		nil,
+	)
+	c.pendingOperations = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "pending_operations"),
+		"Number of replication operations in the queue of this domain controller by partner.",
+		[]string{"partner"},
+		nil,
+	)
+
+	var dst []msadReplNeighbor
+
+	if err := c.miSession.Query(&dst, mi.NamespaceRootMicrosoftAD, c.neighborMIQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if err := c.collectNeighbors(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed to collect replication partners: %w", err))
+	}
+
+	if err := c.collectPendingOperations(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed to collect pending replication operations: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectNeighbors(ch chan<- prometheus.Metric) error {
+	var dst []msadReplNeighbor
+
+	if err := c.miSession.Query(&dst, mi.NamespaceRootMicrosoftAD, c.neighborMIQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	now := time.Now()
+
+	var (
+		largestDelta time.Duration
+		neighbors    int
+	)
+
+	for _, neighbor := range dst {
+		// Partners that have been demoted stay in the list until the KCC removes them.
+		if neighbor.IsDeletedSourceDsa {
+			continue
+		}
+
+		neighbors++
+
+		ch <- prometheus.MustNewConstMetric(
+			c.lastSyncSuccess,
+			prometheus.GaugeValue,
+			timestamp(neighbor.TimeOfLastSyncSuccess),
+			neighbor.SourceDsaCN, neighbor.SourceDsaSite, neighbor.NamingContextDN,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.lastSyncAttempt,
+			prometheus.GaugeValue,
+			timestamp(neighbor.TimeOfLastSyncAttempt),
+			neighbor.SourceDsaCN, neighbor.SourceDsaSite, neighbor.NamingContextDN,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.lastSyncResult,
+			prometheus.GaugeValue,
+			float64(neighbor.LastSyncResult),
+			neighbor.SourceDsaCN, neighbor.SourceDsaSite, neighbor.NamingContextDN,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.consecutiveSyncFailures,
+			prometheus.GaugeValue,
+			float64(neighbor.NumConsecutiveSyncFailures),
+			neighbor.SourceDsaCN, neighbor.SourceDsaSite, neighbor.NamingContextDN,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.usnLastObjChangeSynced,
+			prometheus.GaugeValue,
+			float64(neighbor.USNLastObjChangeSynced),
+			neighbor.SourceDsaCN, neighbor.SourceDsaSite, neighbor.NamingContextDN,
+		)
+
+		if !never(neighbor.TimeOfLastSyncSuccess) {
+			largestDelta = max(largestDelta, now.Sub(neighbor.TimeOfLastSyncSuccess))
+		}
+	}
+
+	// A domain controller without replication partners, e.g. the only one of its domain, has no delta.
+	if neighbors > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			c.largestDelta,
+			prometheus.GaugeValue,
+			largestDelta.Seconds(),
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectPendingOperations(ch chan<- prometheus.Metric) error {
+	var dst []msadReplPendingOp
+
+	if err := c.miSession.Query(&dst, mi.NamespaceRootMicrosoftAD, c.pendingOpMIQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	pendingOperations := make(map[string]int)
+
+	for _, operation := range dst {
+		pendingOperations[partnerName(operation.DsaDN)]++
+	}
+
+	for partner, count := range pendingOperations {
+		ch <- prometheus.MustNewConstMetric(
+			c.pendingOperations,
+			prometheus.GaugeValue,
+			float64(count),
+			partner,
+		)
+	}
+
+	return nil
+}
+
+// partnerName returns the name of the domain controller of the DN of its NTDS Settings object,
+// e.g. DC2 for CN=NTDS Settings,CN=DC2,CN=Servers,CN=Default-First-Site-Name,CN=Sites,CN=Configuration,DC=example,DC=com.
+func partnerName(dsaDN string) string {
+	rdns := strings.SplitN(dsaDN, ",", 3)
+	if len(rdns) < 2 {
+		return dsaDN
+	}
+
+	name, ok := strings.CutPrefix(rdns[1], "CN=")
+	if !ok {
+		return dsaDN
+	}
+
+	return name
+}
+
+// never reports whether t is unset. The replication provider reports unset times as 1601-01-01, the zero FILETIME.
+func never(t time.Time) bool {
+	return t.Unix() <= 0
+}
+
+func timestamp(t time.Time) float64 {
+	if never(t) {
+		return 0
+	}
+
+	return float64(t.Unix())
+}
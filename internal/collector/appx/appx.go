@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package appx
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "appx"
+
+	deploymentChannel = "Microsoft-Windows-AppXDeploymentServer/Operational"
+
+	// Events logged at the end of each package deployment operation (add, register, stage, remove, ...).
+	eventIDDeploymentSucceeded = 400
+	eventIDDeploymentFailed    = 401
+
+	// packageRepositoryKey contains a subkey for each package known to the system, regardless of the users it's registered for.
+	packageRepositoryKey = `SOFTWARE\Classes\Local Settings\Software\Microsoft\Windows\CurrentVersion\AppModel\PackageRepository\Packages`
+	// stagedPackagesKey contains a subkey for each package that is staged but not registered for any user.
+	stagedPackagesKey = `SOFTWARE\Microsoft\Windows\CurrentVersion\Appx\AppxAllUserStore\Staged`
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+// renderValuePaths are the event properties rendered for each deployment event.
+// The order must match the value* indices.
+//
+//nolint:gochecknoglobals
+var renderValuePaths = []string{
+	"Event/System/EventRecordID",
+	"Event/System/EventID",
+}
+
+const (
+	valueEventRecordID = iota
+	valueEventID
+)
+
+// A Collector is a Prometheus Collector for AppX and MSIX package deployments.
+// Deployment operations are read from the AppXDeploymentServer operational event log. Counters only include
+// events logged since windows_exporter started.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	renderContext wevtapi.EVT_HANDLE
+
+	// mu protects the deployment counters and lastRecordID against concurrent scrapes.
+	mu                   sync.Mutex
+	lastRecordID         uint64
+	deploymentOperations map[string]float64
+
+	deploymentOperationsTotal *prometheus.Desc
+	packages                  *prometheus.Desc
+	stagedPackages            *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if c.renderContext != 0 {
+		return wevtapi.EvtClose(c.renderContext)
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.deploymentOperationsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "deployment_operations_total"),
+		"Number of AppX/MSIX package deployment operations by result (success, failure)",
+		[]string{"result"},
+		nil,
+	)
+	c.packages = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "packages"),
+		"Number of AppX/MSIX packages in the package repository of the system",
+		nil,
+		nil,
+	)
+	c.stagedPackages = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "staged_packages"),
+		"Number of AppX/MSIX packages that are staged but not registered for any user",
+		nil,
+		nil,
+	)
+
+	c.deploymentOperations = map[string]float64{
+		"success": 0,
+		"failure": 0,
+	}
+
+	lastRecordID, err := wevtapi.LatestEventRecordID(deploymentChannel)
+	if err != nil {
+		return fmt.Errorf("failed to read latest event of %s: %w", deploymentChannel, err)
+	}
+
+	c.lastRecordID = lastRecordID
+
+	c.renderContext, err = wevtapi.EvtCreateRenderContext(renderValuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if err := c.collectDeploymentOperations(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting deployment operation metrics: %w", err))
+	}
+
+	if err := c.collectPackages(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting package metrics: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectDeploymentOperations(ch chan<- prometheus.Metric) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	query := fmt.Sprintf("*[System[(EventID=%d or EventID=%d) and EventRecordID > %d]]",
+		eventIDDeploymentSucceeded, eventIDDeploymentFailed, c.lastRecordID,
+	)
+
+	if err := wevtapi.QueryValues(deploymentChannel, query, c.renderContext, c.handleEvent); err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", deploymentChannel, err)
+	}
+
+	for result, count := range c.deploymentOperations {
+		ch <- prometheus.MustNewConstMetric(
+			c.deploymentOperationsTotal,
+			prometheus.CounterValue,
+			count,
+			result,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) handleEvent(values []any) {
+	if len(values) != len(renderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[valueEventRecordID].(uint64)
+	c.lastRecordID = max(c.lastRecordID, recordID)
+
+	eventID, _ := values[valueEventID].(uint64)
+
+	switch eventID {
+	case eventIDDeploymentSucceeded:
+		c.deploymentOperations["success"]++
+	case eventIDDeploymentFailed:
+		c.deploymentOperations["failure"]++
+	}
+}
+
+func (c *Collector) collectPackages(ch chan<- prometheus.Metric) error {
+	packages, err := subKeyCount(packageRepositoryKey)
+	if err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.packages,
+		prometheus.GaugeValue,
+		float64(packages),
+	)
+
+	stagedPackages, err := subKeyCount(stagedPackagesKey)
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.stagedPackages,
+		prometheus.GaugeValue,
+		float64(stagedPackages),
+	)
+
+	return nil
+}
+
+func subKeyCount(path string) (uint32, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, path, registry.QUERY_VALUE)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open registry key %s: %w", path, err)
+	}
+
+	defer key.Close()
+
+	info, err := key.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read registry key %s: %w", path, err)
+	}
+
+	return info.SubKeyCount, nil
+}
@@ -0,0 +1,370 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package autorun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wintrust"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const Name = "autorun"
+
+type Config struct {
+	ScanInterval time.Duration `yaml:"scan-interval"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	ScanInterval: 15 * time.Minute,
+}
+
+// Signature states of the executables of the entries, the signature_status label.
+const (
+	signatureValid    = "valid"
+	signatureInvalid  = "invalid"
+	signatureUnsigned = "unsigned"
+	signatureNotFound = "not_found"
+	signatureUnknown  = "unknown"
+)
+
+//nolint:gochecknoglobals
+var (
+	sources           = []string{sourceRun, sourceRunOnce, sourceStartupFolder, sourceScheduledTask}
+	signatureStatuses = []string{signatureValid, signatureInvalid, signatureUnsigned, signatureNotFound, signatureUnknown}
+)
+
+// A Collector is a Prometheus Collector for the autorun entries of the machine: the Run and RunOnce keys,
+// the startup folders and the scheduled tasks started at logon or boot, with the Authenticode signature
+// of the started executables.
+// The entries are scanned in the background every ScanInterval, since verifying the signatures requires
+// hashing the executables.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	ctxCancelFn context.CancelFunc
+
+	// signatures are the verified signatures of the executables by path. They are only accessed by the scan.
+	signatures map[string]signature
+
+	// mu protects the result of the last scan.
+	mu           sync.RWMutex
+	entries      []verifiedEntry
+	scanDuration float64
+	scanTime     time.Time
+
+	entriesDesc          *prometheus.Desc
+	entryInfo            *prometheus.Desc
+	scanDurationSeconds  *prometheus.Desc
+	scanTimestampSeconds *prometheus.Desc
+}
+
+// signature is the result of the signature verification of an executable, which is verified again if
+// the size or the modification time of the file changes.
+type signature struct {
+	size      int64
+	modTime   time.Time
+	status    string
+	publisher string
+}
+
+type verifiedEntry struct {
+	entry
+
+	signatureStatus string
+	publisher       string
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.ScanInterval == 0 {
+		config.ScanInterval = ConfigDefaults.ScanInterval
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	app.Flag(
+		"collector.autorun.scan-interval",
+		"Interval of scanning the autorun entries and verifying the signatures of their executables.",
+	).Default(ConfigDefaults.ScanInterval.String()).DurationVar(&c.config.ScanInterval)
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+// LastSuccess returns the time of the last successful scan, see collector.BackgroundCollector.
+func (c *Collector) LastSuccess() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.scanTime
+}
+
+func (c *Collector) Close() error {
+	if c.ctxCancelFn != nil {
+		c.ctxCancelFn()
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	if c.config.ScanInterval <= 0 {
+		return fmt.Errorf("invalid scan interval %s, expected a positive duration", c.config.ScanInterval)
+	}
+
+	c.signatures = make(map[string]signature)
+
+	c.entriesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "entries"),
+		"Number of autorun entries by source and signature status of the executable at the last scan",
+		[]string{"source", "signature_status"},
+		nil,
+	)
+	c.entryInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "entry_info"),
+		"Autorun entry at the last scan, with the executable it starts, the signature status and the publisher of the signature",
+		[]string{"source", "location", "name", "path", "publisher", "signature_status"},
+		nil,
+	)
+	c.scanDurationSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "scan_duration_seconds"),
+		"Duration of the last scan of the autorun entries",
+		nil,
+		nil,
+	)
+	c.scanTimestampSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "scan_timestamp_seconds"),
+		"Timestamp of the end of the last scan of the autorun entries",
+		nil,
+		nil,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.ctxCancelFn = cancel
+
+	go c.scheduleScan(ctx)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// The metrics are missing until the first scan finished.
+	if c.scanTime.IsZero() {
+		return nil
+	}
+
+	type entryKey struct {
+		source, location, name, path, publisher, signatureStatus string
+	}
+
+	counts := make(map[[2]string]int)
+	seen := make(map[entryKey]struct{}, len(c.entries))
+
+	for _, e := range c.entries {
+		counts[[2]string{e.source, e.signatureStatus}]++
+
+		key := entryKey{e.source, e.location, e.name, e.path, e.publisher, e.signatureStatus}
+		// e.g. a task with two identical actions
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.entryInfo,
+			prometheus.GaugeValue,
+			1,
+			e.source,
+			e.location,
+			e.name,
+			e.path,
+			e.publisher,
+			e.signatureStatus,
+		)
+	}
+
+	for _, source := range sources {
+		for _, status := range signatureStatuses {
+			ch <- prometheus.MustNewConstMetric(
+				c.entriesDesc,
+				prometheus.GaugeValue,
+				float64(counts[[2]string{source, status}]),
+				source,
+				status,
+			)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.scanDurationSeconds,
+		prometheus.GaugeValue,
+		c.scanDuration,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.scanTimestampSeconds,
+		prometheus.GaugeValue,
+		float64(c.scanTime.Unix()),
+	)
+
+	return nil
+}
+
+func (c *Collector) scheduleScan(ctx context.Context) {
+	for {
+		if err := c.scan(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			c.logger.LogAttrs(ctx, slog.LevelWarn, "failed to scan the autorun entries",
+				slog.Any("err", err),
+			)
+		}
+
+		select {
+		case <-time.After(c.config.ScanInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Collector) scan(ctx context.Context) error {
+	start := time.Now()
+
+	entries := make([]entry, 0)
+
+	for _, read := range []func() ([]entry, error){readRunKeyEntries, readStartupFolderEntries, readScheduledTaskEntries} {
+		sourceEntries, err := read()
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, sourceEntries...)
+	}
+
+	verified := make([]verifiedEntry, 0, len(entries))
+	signatures := make(map[string]signature, len(c.signatures))
+
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		status, publisher := signatureNotFound, ""
+
+		if e.found {
+			sig := c.verifySignature(e.path)
+			// Executables that couldn't be verified are verified again at the next scan.
+			if sig.status != signatureUnknown {
+				signatures[e.path] = sig
+			}
+
+			status, publisher = sig.status, sig.publisher
+		}
+
+		verified = append(verified, verifiedEntry{entry: e, signatureStatus: status, publisher: publisher})
+	}
+
+	// Executables of removed entries are not kept.
+	c.signatures = signatures
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = verified
+	c.scanTime = time.Now()
+	c.scanDuration = c.scanTime.Sub(start).Seconds()
+
+	return nil
+}
+
+// verifySignature returns the signature of the executable, which is only verified again if the file changed.
+func (c *Collector) verifySignature(path string) signature {
+	info, err := os.Stat(path)
+	if err != nil {
+		return signature{status: signatureNotFound}
+	}
+
+	if sig, ok := c.signatures[path]; ok && sig.size == info.Size() && sig.modTime.Equal(info.ModTime()) {
+		return sig
+	}
+
+	sig := signature{size: info.Size(), modTime: info.ModTime()}
+
+	publisher, err := wintrust.VerifyFile(path)
+
+	var errno windows.Errno
+
+	switch {
+	case err == nil:
+		sig.status = signatureValid
+		sig.publisher = publisher
+	case errors.Is(err, windows.Errno(windows.TRUST_E_NOSIGNATURE)),
+		errors.Is(err, windows.Errno(windows.TRUST_E_SUBJECT_FORM_UNKNOWN)),
+		errors.Is(err, windows.Errno(windows.TRUST_E_PROVIDER_UNKNOWN)):
+		sig.status = signatureUnsigned
+	case errors.As(err, &errno) && errno&0x80000000 != 0:
+		// HRESULTs of the verification, e.g. an expired certificate, an untrusted root or a modified file.
+		sig.status = signatureInvalid
+		sig.publisher = publisher
+	default:
+		// e.g. the file is locked or can't be read by the exporter.
+		c.logger.Debug("failed to verify signature",
+			slog.String("path", path),
+			slog.Any("err", err),
+		)
+
+		sig.status = signatureUnknown
+	}
+
+	return sig
+}
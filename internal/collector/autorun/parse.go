@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package autorun
+
+import (
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+var (
+	errInvalidShortcut  = errors.New("invalid shell link")
+	errNoShortcutTarget = errors.New("shell link has no local target")
+)
+
+// commandLinePath returns the path of the executable of a command line, e.g. of a Run value.
+// Unquoted paths with spaces are resolved like by CreateProcess: the prefixes of the command line
+// ending at a space are looked up from the shortest to the longest one.
+// lookup returns the path of an existing file for a name. If no prefix exists, the first word is returned
+// with false.
+func commandLinePath(commandLine string, lookup func(name string) (string, bool)) (string, bool) {
+	commandLine = strings.TrimSpace(commandLine)
+
+	if rest, ok := strings.CutPrefix(commandLine, `"`); ok {
+		name, _, _ := strings.Cut(rest, `"`)
+		if path, ok := lookup(name); ok {
+			return path, true
+		}
+
+		return name, false
+	}
+
+	words := strings.Fields(commandLine)
+	for i := range words {
+		if path, ok := lookup(strings.Join(words[:i+1], " ")); ok {
+			return path, true
+		}
+	}
+
+	if len(words) == 0 {
+		return "", false
+	}
+
+	return words[0], false
+}
+
+// Flags of the shell link header and offsets of the LinkInfo, see MS-SHLLINK.
+// 📑 https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-shllink/16cb4ca1-9339-4d0c-a68d-bf1d6cc0f943
+const (
+	shortcutHeaderSize = 0x4c
+
+	shortcutHasLinkTargetIDList = 0x01
+	shortcutHasLinkInfo         = 0x02
+
+	linkInfoVolumeIDAndLocalBasePath = 0x01
+	linkInfoUnicodeHeaderSize        = 0x24
+)
+
+// parseShortcut returns the local path of the target of a shell link (.lnk) file.
+// Links to network shares and advertised links of Windows Installer packages have no local target.
+func parseShortcut(data []byte) (string, error) {
+	if len(data) < shortcutHeaderSize || binary.LittleEndian.Uint32(data) != shortcutHeaderSize {
+		return "", errInvalidShortcut
+	}
+
+	flags := binary.LittleEndian.Uint32(data[0x14:])
+	offset := shortcutHeaderSize
+
+	if flags&shortcutHasLinkTargetIDList != 0 {
+		if len(data) < offset+2 {
+			return "", errInvalidShortcut
+		}
+
+		offset += 2 + int(binary.LittleEndian.Uint16(data[offset:]))
+	}
+
+	if flags&shortcutHasLinkInfo == 0 {
+		return "", errNoShortcutTarget
+	}
+
+	if len(data) < offset+0x1c {
+		return "", errInvalidShortcut
+	}
+
+	linkInfoSize := int(binary.LittleEndian.Uint32(data[offset:]))
+	if linkInfoSize < 0x1c || len(data) < offset+linkInfoSize {
+		return "", errInvalidShortcut
+	}
+
+	linkInfo := data[offset : offset+linkInfoSize]
+	headerSize := binary.LittleEndian.Uint32(linkInfo[0x04:])
+
+	if binary.LittleEndian.Uint32(linkInfo[0x08:])&linkInfoVolumeIDAndLocalBasePath == 0 {
+		return "", errNoShortcutTarget
+	}
+
+	if headerSize >= linkInfoUnicodeHeaderSize && linkInfoSize >= linkInfoUnicodeHeaderSize {
+		basePath, err := utf16StringAt(linkInfo, binary.LittleEndian.Uint32(linkInfo[0x1c:]))
+		if err != nil {
+			return "", err
+		}
+
+		suffix, err := utf16StringAt(linkInfo, binary.LittleEndian.Uint32(linkInfo[0x20:]))
+		if err != nil {
+			return "", err
+		}
+
+		return joinShortcutPath(basePath, suffix), nil
+	}
+
+	basePath, err := ansiStringAt(linkInfo, binary.LittleEndian.Uint32(linkInfo[0x10:]))
+	if err != nil {
+		return "", err
+	}
+
+	suffix, err := ansiStringAt(linkInfo, binary.LittleEndian.Uint32(linkInfo[0x18:]))
+	if err != nil {
+		return "", err
+	}
+
+	return joinShortcutPath(basePath, suffix), nil
+}
+
+func joinShortcutPath(basePath, suffix string) string {
+	if suffix == "" || strings.HasSuffix(basePath, `\`) {
+		return basePath + suffix
+	}
+
+	return basePath + `\` + suffix
+}
+
+// ansiStringAt returns the NULL-terminated string at the offset. Characters beyond ASCII
+// are decoded as Latin-1, since the code page of the system that created the link is unknown.
+func ansiStringAt(data []byte, offset uint32) (string, error) {
+	if int(offset) >= len(data) {
+		return "", errInvalidShortcut
+	}
+
+	var builder strings.Builder
+
+	for _, b := range data[offset:] {
+		if b == 0 {
+			return builder.String(), nil
+		}
+
+		builder.WriteRune(rune(b))
+	}
+
+	return "", errInvalidShortcut
+}
+
+// utf16StringAt returns the NULL-terminated UTF-16 string at the offset.
+func utf16StringAt(data []byte, offset uint32) (string, error) {
+	if int(offset) >= len(data) {
+		return "", errInvalidShortcut
+	}
+
+	var chars []uint16
+
+	for i := int(offset); i+1 < len(data); i += 2 {
+		char := binary.LittleEndian.Uint16(data[i:])
+		if char == 0 {
+			return string(utf16.Decode(chars)), nil
+		}
+
+		chars = append(chars, char)
+	}
+
+	return "", errInvalidShortcut
+}
+
+// taskDefinition is the part of the XML definition of a scheduled task that determines whether it is an autorun entry.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/taskschd/task-scheduler-schema
+type taskDefinition struct {
+	Triggers struct {
+		Logon []taskTrigger `xml:"LogonTrigger"`
+		Boot  []taskTrigger `xml:"BootTrigger"`
+	} `xml:"Triggers"`
+	Actions struct {
+		Exec []struct {
+			Command string `xml:"Command"`
+		} `xml:"Exec"`
+	} `xml:"Actions"`
+}
+
+type taskTrigger struct {
+	// Enabled defaults to true if the element is missing.
+	Enabled string `xml:"Enabled"`
+}
+
+// parseTaskCommands returns the programs of the actions of a scheduled task if the task has
+// an enabled logon or boot trigger.
+func parseTaskCommands(definition string) ([]string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(definition))
+	// The definition returned by the Task Scheduler declares UTF-16, but is already decoded.
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	var task taskDefinition
+	if err := decoder.Decode(&task); err != nil {
+		return nil, err
+	}
+
+	triggered := false
+
+	for _, trigger := range append(task.Triggers.Logon, task.Triggers.Boot...) {
+		if strings.TrimSpace(trigger.Enabled) != "false" {
+			triggered = true
+
+			break
+		}
+	}
+
+	if !triggered {
+		return nil, nil
+	}
+
+	commands := make([]string, 0, len(task.Actions.Exec))
+
+	for _, action := range task.Actions.Exec {
+		if command := strings.TrimSpace(action.Command); command != "" {
+			commands = append(commands, command)
+		}
+	}
+
+	return commands, nil
+}
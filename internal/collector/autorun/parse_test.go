@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package autorun
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandLinePath(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]struct{}{
+		`C:\Program Files\Vendor\agent.exe`: {},
+		`C:\Windows\System32\rundll32.exe`:  {},
+	}
+
+	lookup := func(name string) (string, bool) {
+		if name == "rundll32.exe" {
+			return `C:\Windows\System32\rundll32.exe`, true
+		}
+
+		for _, path := range []string{name, name + ".exe"} {
+			if _, ok := files[path]; ok {
+				return path, true
+			}
+		}
+
+		return "", false
+	}
+
+	for commandLine, expected := range map[string]string{
+		`"C:\Program Files\Vendor\agent.exe" /background`: `C:\Program Files\Vendor\agent.exe`,
+		`C:\Program Files\Vendor\agent.exe --tray`:        `C:\Program Files\Vendor\agent.exe`,
+		`C:\Program Files\Vendor\agent --tray`:            `C:\Program Files\Vendor\agent.exe`,
+		`rundll32.exe C:\Vendor\plugin.dll,Start`:         `C:\Windows\System32\rundll32.exe`,
+	} {
+		path, found := commandLinePath(commandLine, lookup)
+		require.True(t, found, commandLine)
+		require.Equal(t, expected, path, commandLine)
+	}
+
+	path, found := commandLinePath(`"C:\Removed\app.exe" -minimized`, lookup)
+	require.False(t, found)
+	require.Equal(t, `C:\Removed\app.exe`, path)
+
+	path, found = commandLinePath(`C:\Removed\app.exe -minimized`, lookup)
+	require.False(t, found)
+	require.Equal(t, `C:\Removed\app.exe`, path)
+}
+
+// shortcut returns a shell link with a LinkInfo of the local base path and an empty common path suffix.
+func shortcut(basePath string, unicode bool) []byte {
+	header := make([]byte, shortcutHeaderSize)
+	binary.LittleEndian.PutUint32(header, shortcutHeaderSize)
+	binary.LittleEndian.PutUint32(header[0x14:], shortcutHasLinkTargetIDList|shortcutHasLinkInfo)
+
+	// An empty IDList.
+	idList := []byte{2, 0, 0, 0}
+
+	headerSize := uint32(0x1c)
+	if unicode {
+		headerSize = linkInfoUnicodeHeaderSize
+	}
+
+	var text []byte
+	if unicode {
+		for _, char := range utf16.Encode([]rune(basePath)) {
+			text = binary.LittleEndian.AppendUint16(text, char)
+		}
+
+		text = append(text, 0, 0, 0, 0)
+	} else {
+		text = append([]byte(basePath), 0, 0)
+	}
+
+	linkInfo := make([]byte, headerSize)
+	binary.LittleEndian.PutUint32(linkInfo, headerSize+uint32(len(text)))
+	binary.LittleEndian.PutUint32(linkInfo[0x04:], headerSize)
+	binary.LittleEndian.PutUint32(linkInfo[0x08:], linkInfoVolumeIDAndLocalBasePath)
+
+	suffixOffset := headerSize + uint32(len(text)) - 1
+	if unicode {
+		suffixOffset--
+		binary.LittleEndian.PutUint32(linkInfo[0x1c:], headerSize)
+		binary.LittleEndian.PutUint32(linkInfo[0x20:], suffixOffset)
+	} else {
+		binary.LittleEndian.PutUint32(linkInfo[0x10:], headerSize)
+		binary.LittleEndian.PutUint32(linkInfo[0x18:], suffixOffset)
+	}
+
+	data := append(header, idList...)
+	data = append(data, linkInfo...)
+
+	return append(data, text...)
+}
+
+func TestParseShortcut(t *testing.T) {
+	t.Parallel()
+
+	target, err := parseShortcut(shortcut(`C:\Program Files\Vendor\agent.exe`, false))
+	require.NoError(t, err)
+	require.Equal(t, `C:\Program Files\Vendor\agent.exe`, target)
+
+	target, err = parseShortcut(shortcut(`C:\Programme\Händler\agent.exe`, true))
+	require.NoError(t, err)
+	require.Equal(t, `C:\Programme\Händler\agent.exe`, target)
+
+	data := shortcut(`C:\agent.exe`, false)
+	binary.LittleEndian.PutUint32(data[0x14:], shortcutHasLinkTargetIDList)
+	_, err = parseShortcut(data)
+	require.ErrorIs(t, err, errNoShortcutTarget)
+
+	_, err = parseShortcut(data[:0x20])
+	require.ErrorIs(t, err, errInvalidShortcut)
+}
+
+func TestParseTaskCommands(t *testing.T) {
+	t.Parallel()
+
+	commands, err := parseTaskCommands(`<?xml version="1.0" encoding="UTF-16"?>
+<Task version="1.2" xmlns="http://schemas.microsoft.com/windows/2004/02/mit/task">
+  <Triggers>
+    <LogonTrigger>
+      <Enabled>true</Enabled>
+    </LogonTrigger>
+  </Triggers>
+  <Actions Context="Author">
+    <Exec>
+      <Command>"%ProgramFiles%\Vendor\updater.exe"</Command>
+      <Arguments>/silent</Arguments>
+    </Exec>
+  </Actions>
+</Task>`)
+	require.NoError(t, err)
+	require.Equal(t, []string{`"%ProgramFiles%\Vendor\updater.exe"`}, commands)
+
+	commands, err = parseTaskCommands(`<?xml version="1.0" encoding="UTF-16"?>
+<Task version="1.2" xmlns="http://schemas.microsoft.com/windows/2004/02/mit/task">
+  <Triggers>
+    <BootTrigger>
+      <Enabled>false</Enabled>
+    </BootTrigger>
+    <CalendarTrigger />
+  </Triggers>
+  <Actions Context="Author">
+    <Exec>
+      <Command>C:\Vendor\cleanup.exe</Command>
+    </Exec>
+  </Actions>
+</Task>`)
+	require.NoError(t, err)
+	require.Empty(t, commands)
+}
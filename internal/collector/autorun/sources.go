@@ -0,0 +1,461 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package autorun
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// Sources of the autorun entries, the source label.
+const (
+	sourceRun           = "run"
+	sourceRunOnce       = "run_once"
+	sourceStartupFolder = "startup_folder"
+	sourceScheduledTask = "scheduled_task"
+)
+
+const (
+	// profileListKey contains a subkey for each local user profile, named by the SID of the user.
+	profileListKey = `SOFTWARE\Microsoft\Windows NT\CurrentVersion\ProfileList`
+
+	// userStartupFolder is the startup folder below the profile directory, if the AppData folder is not redirected.
+	userStartupFolder = `AppData\Roaming\Microsoft\Windows\Start Menu\Programs\Startup`
+
+	// S_FALSE is returned by CoInitialize if it was already called on this thread.
+	S_FALSE = 0x00000001
+)
+
+type runKey struct {
+	path   string
+	source string
+}
+
+// machineRunKeys are the Run keys of HKEY_LOCAL_MACHINE, including the keys of 32-bit applications.
+//
+//nolint:gochecknoglobals
+var machineRunKeys = []runKey{
+	{path: `SOFTWARE\Microsoft\Windows\CurrentVersion\Run`, source: sourceRun},
+	{path: `SOFTWARE\Microsoft\Windows\CurrentVersion\RunOnce`, source: sourceRunOnce},
+	{path: `SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Run`, source: sourceRun},
+	{path: `SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\RunOnce`, source: sourceRunOnce},
+}
+
+// userRunKeys are the Run keys of the user hives, which are shared by 64-bit and 32-bit applications.
+//
+//nolint:gochecknoglobals
+var userRunKeys = machineRunKeys[:2]
+
+// entry is an autorun entry. location is the registry key, the startup folder or the task folder of the entry.
+// path is the executable that is started, found reports whether it exists.
+type entry struct {
+	source   string
+	location string
+	name     string
+	path     string
+	found    bool
+}
+
+// readRunKeyEntries returns the entries of the Run keys of the machine and of the loaded user hives.
+// Hives of users that are not logged on are not loaded.
+func readRunKeyEntries() ([]entry, error) {
+	entries := make([]entry, 0)
+
+	for _, key := range machineRunKeys {
+		keyEntries, err := readRunKey(registry.LOCAL_MACHINE, key.path, `HKLM\`+key.path, key.source)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, keyEntries...)
+	}
+
+	users, err := registry.OpenKey(registry.USERS, "", registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open registry key HKEY_USERS: %w", err)
+	}
+
+	defer users.Close()
+
+	sids, err := users.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry key HKEY_USERS: %w", err)
+	}
+
+	for _, sid := range sids {
+		// .DEFAULT is the hive of LocalSystem, <SID>_Classes are the file associations of the user.
+		if sid == ".DEFAULT" || strings.HasSuffix(sid, "_Classes") {
+			continue
+		}
+
+		for _, key := range userRunKeys {
+			path := sid + `\` + key.path
+
+			keyEntries, err := readRunKey(users, path, `HKU\`+path, key.source)
+			if err != nil {
+				return nil, err
+			}
+
+			entries = append(entries, keyEntries...)
+		}
+	}
+
+	return entries, nil
+}
+
+func readRunKey(root registry.Key, path, location, source string) ([]entry, error) {
+	key, err := registry.OpenKey(root, path, registry.QUERY_VALUE)
+	if errors.Is(err, registry.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open registry key %s: %w", location, err)
+	}
+
+	defer key.Close()
+
+	names, err := key.ReadValueNames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry key %s: %w", location, err)
+	}
+
+	entries := make([]entry, 0, len(names))
+
+	for _, name := range names {
+		commandLine, _, err := key.GetStringValue(name)
+		if err != nil {
+			// Values that are not strings are ignored by Windows as well.
+			continue
+		}
+
+		executable, found := resolveCommandLine(commandLine)
+
+		entries = append(entries, entry{
+			source:   source,
+			location: location,
+			name:     name,
+			path:     executable,
+			found:    found,
+		})
+	}
+
+	return entries, nil
+}
+
+// readStartupFolderEntries returns the files of the startup folder of all users and of the startup folders
+// of the local user profiles.
+func readStartupFolderEntries() ([]entry, error) {
+	commonStartup, err := windows.KnownFolderPath(windows.FOLDERID_CommonStartup, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the startup folder of all users: %w", err)
+	}
+
+	folders := []string{commonStartup}
+
+	profileDirectories, err := readProfileDirectories()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, directory := range profileDirectories {
+		folders = append(folders, filepath.Join(directory, userStartupFolder))
+	}
+
+	entries := make([]entry, 0)
+
+	for _, folder := range folders {
+		files, err := os.ReadDir(folder)
+		if err != nil {
+			// The folder doesn't exist for profiles that were never logged on interactively.
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to read startup folder %s: %w", folder, err)
+		}
+
+		for _, file := range files {
+			if file.IsDir() || strings.EqualFold(file.Name(), "desktop.ini") {
+				continue
+			}
+
+			path := filepath.Join(folder, file.Name())
+
+			if strings.EqualFold(filepath.Ext(path), ".lnk") {
+				path = readShortcutTarget(path)
+			}
+
+			entries = append(entries, entry{
+				source:   sourceStartupFolder,
+				location: folder,
+				name:     file.Name(),
+				path:     path,
+				found:    fileExists(path),
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// readShortcutTarget returns the target of the shell link, or an empty string if it has no local target.
+func readShortcutTarget(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	target, err := parseShortcut(data)
+	if err != nil {
+		return ""
+	}
+
+	return target
+}
+
+// readProfileDirectories returns the directories of the local user profiles of the ProfileList.
+func readProfileDirectories() ([]string, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, profileListKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open registry key %s: %w", profileListKey, err)
+	}
+
+	defer key.Close()
+
+	sids, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry key %s: %w", profileListKey, err)
+	}
+
+	directories := make([]string, 0, len(sids))
+
+	for _, sid := range sids {
+		profileKey, err := registry.OpenKey(key, sid, registry.QUERY_VALUE)
+		if err != nil {
+			// The profile may have been deleted since the subkeys were read.
+			continue
+		}
+
+		directory, _, err := profileKey.GetStringValue("ProfileImagePath")
+		profileKey.Close()
+
+		if err != nil {
+			continue
+		}
+
+		if expanded, err := registry.ExpandString(directory); err == nil {
+			directory = expanded
+		}
+
+		directories = append(directories, directory)
+	}
+
+	return directories, nil
+}
+
+// readScheduledTaskEntries returns the programs of the enabled scheduled tasks with a logon or boot trigger.
+func readScheduledTaskEntries() ([]entry, error) {
+	// The only way to run WMI queries in parallel while being thread-safe is to
+	// ensure the CoInitialize[Ex]() call is bound to its current OS thread.
+	// Otherwise, attempting to initialize and run parallel queries across
+	// goroutines will result in protected memory errors.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := ole.CoInitializeEx(0, ole.COINIT_APARTMENTTHREADED|ole.COINIT_DISABLE_OLE1DDE); err != nil {
+		var oleCode *ole.OleError
+		if errors.As(err, &oleCode) && oleCode.Code() != ole.S_OK && oleCode.Code() != S_FALSE {
+			return nil, err
+		}
+	}
+	defer ole.CoUninitialize()
+
+	scheduleClassID, err := ole.ClassIDFrom("Schedule.Service.1")
+	if err != nil {
+		return nil, err
+	}
+
+	taskSchedulerObj, err := ole.CreateInstance(scheduleClassID, nil)
+	if err != nil || taskSchedulerObj == nil {
+		return nil, fmt.Errorf("failed to create task scheduler: %w", err)
+	}
+	defer taskSchedulerObj.Release()
+
+	taskServiceObj, err := taskSchedulerObj.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return nil, err
+	}
+	defer taskServiceObj.Release()
+
+	if _, err = oleutil.CallMethod(taskServiceObj, "Connect"); err != nil {
+		return nil, fmt.Errorf("failed to connect to task service: %w", err)
+	}
+
+	res, err := oleutil.CallMethod(taskServiceObj, "GetFolder", `\`)
+	if err != nil {
+		return nil, err
+	}
+
+	rootFolderObj := res.ToIDispatch()
+	defer rootFolderObj.Release()
+
+	entries := make([]entry, 0)
+
+	if err := readTaskFolder(rootFolderObj, &entries); err != nil {
+		return nil, fmt.Errorf("failed to read scheduled tasks: %w", err)
+	}
+
+	return entries, nil
+}
+
+func readTaskFolder(folder *ole.IDispatch, entries *[]entry) error {
+	// 1 is TASK_ENUM_HIDDEN, which includes hidden tasks.
+	res, err := oleutil.CallMethod(folder, "GetTasks", 1)
+	if err != nil {
+		return err
+	}
+
+	tasks := res.ToIDispatch()
+	defer tasks.Release()
+
+	err = oleutil.ForEach(tasks, func(v *ole.VARIANT) error {
+		task := v.ToIDispatch()
+		defer task.Release()
+
+		return readTask(task, entries)
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err = oleutil.CallMethod(folder, "GetFolders", 1)
+	if err != nil {
+		return err
+	}
+
+	subFolders := res.ToIDispatch()
+	defer subFolders.Release()
+
+	return oleutil.ForEach(subFolders, func(v *ole.VARIANT) error {
+		subFolder := v.ToIDispatch()
+		defer subFolder.Release()
+
+		return readTaskFolder(subFolder, entries)
+	})
+}
+
+func readTask(task *ole.IDispatch, entries *[]entry) error {
+	properties := make(map[string]any, 3)
+
+	for _, name := range []string{"Path", "Enabled", "Xml"} {
+		property, err := oleutil.GetProperty(task, name)
+		if err != nil {
+			return err
+		}
+
+		properties[name] = property.Value()
+		_ = property.Clear()
+	}
+
+	if enabled, ok := properties["Enabled"].(bool); !ok || !enabled {
+		return nil
+	}
+
+	taskPath, _ := properties["Path"].(string)
+	definition, _ := properties["Xml"].(string)
+
+	commands, err := parseTaskCommands(definition)
+	if err != nil {
+		return fmt.Errorf("failed to parse definition of task %s: %w", taskPath, err)
+	}
+
+	// The task folder is separated by slashes like the task label of the scheduled_task collector.
+	taskPath = strings.ReplaceAll(taskPath, `\`, "/")
+	folder, name := "/", taskPath
+
+	if i := strings.LastIndex(taskPath, "/"); i >= 0 {
+		name = taskPath[i+1:]
+
+		if i > 0 {
+			folder = taskPath[:i]
+		}
+	}
+
+	for _, command := range commands {
+		path, found := resolveCommandLine(command)
+
+		*entries = append(*entries, entry{
+			source:   sourceScheduledTask,
+			location: folder,
+			name:     name,
+			path:     path,
+			found:    found,
+		})
+	}
+
+	return nil
+}
+
+// resolveCommandLine returns the executable of the command line after expanding the environment variables.
+func resolveCommandLine(commandLine string) (string, bool) {
+	if expanded, err := registry.ExpandString(commandLine); err == nil {
+		commandLine = expanded
+	}
+
+	return commandLinePath(commandLine, lookupExecutable)
+}
+
+// lookupExecutable returns the path of the executable, which is searched in PATH if it is a bare file name.
+// The .exe extension is optional like for CreateProcess.
+func lookupExecutable(name string) (string, bool) {
+	if !strings.ContainsAny(name, `\/:`) {
+		path, err := exec.LookPath(name)
+
+		return path, err == nil
+	}
+
+	for _, path := range []string{name, name + ".exe"} {
+		if fileExists(path) {
+			return path, true
+		}
+	}
+
+	return "", false
+}
+
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+
+	info, err := os.Stat(path)
+
+	return err == nil && !info.IsDir()
+}
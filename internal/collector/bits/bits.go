@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package bits
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/bits"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const Name = "bits"
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var jobStates = []struct {
+	state bits.JobState
+	label string
+}{
+	{bits.JobStateQueued, "queued"},
+	{bits.JobStateConnecting, "connecting"},
+	{bits.JobStateTransferring, "transferring"},
+	{bits.JobStateSuspended, "suspended"},
+	{bits.JobStateError, "error"},
+	{bits.JobStateTransientError, "transient_error"},
+	{bits.JobStateTransferred, "transferred"},
+	{bits.JobStateAcknowledged, "acknowledged"},
+	{bits.JobStateCancelled, "cancelled"},
+}
+
+// A Collector is a Prometheus Collector for Background Intelligent Transfer Service (BITS) jobs.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	// ownerCache caches the account names of the job owner SIDs.
+	ownerCache sync.Map
+
+	jobs                *prometheus.Desc
+	jobBytes            *prometheus.Desc
+	jobBytesTransferred *prometheus.Desc
+}
+
+type ownerJobs struct {
+	states           map[bits.JobState]int
+	bytes            uint64
+	bytesTransferred uint64
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.jobs = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "jobs"),
+		"Number of BITS jobs by owner and state",
+		[]string{"owner", "state"},
+		nil,
+	)
+	c.jobBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "job_bytes"),
+		"Total size of the files of the BITS jobs of the owner. Jobs with a size that isn't determined yet are not included",
+		[]string{"owner"},
+		nil,
+	)
+	c.jobBytesTransferred = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "job_bytes_transferred"),
+		"Bytes transferred by the BITS jobs of the owner",
+		[]string{"owner"},
+		nil,
+	)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	jobs, err := bits.EnumJobs()
+	if err != nil {
+		return fmt.Errorf("failed to enumerate BITS jobs: %w", err)
+	}
+
+	owners := make(map[string]*ownerJobs)
+
+	for _, job := range jobs {
+		owner := c.lookupOwner(job.Owner)
+
+		if _, ok := owners[owner]; !ok {
+			owners[owner] = &ownerJobs{states: make(map[bits.JobState]int, len(jobStates))}
+		}
+
+		owners[owner].states[job.State]++
+		owners[owner].bytesTransferred += job.Progress.BytesTransferred
+
+		if job.Progress.BytesTotal != bits.SizeUnknown {
+			owners[owner].bytes += job.Progress.BytesTotal
+		}
+	}
+
+	for owner, ownerJobs := range owners {
+		for _, jobState := range jobStates {
+			ch <- prometheus.MustNewConstMetric(
+				c.jobs,
+				prometheus.GaugeValue,
+				float64(ownerJobs.states[jobState.state]),
+				owner,
+				jobState.label,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.jobBytes,
+			prometheus.GaugeValue,
+			float64(ownerJobs.bytes),
+			owner,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.jobBytesTransferred,
+			prometheus.GaugeValue,
+			float64(ownerJobs.bytesTransferred),
+			owner,
+		)
+	}
+
+	return nil
+}
+
+// lookupOwner returns the account name of the SID as DOMAIN\user, or the SID if the account can't be resolved.
+func (c *Collector) lookupOwner(sid string) string {
+	if owner, ok := c.ownerCache.Load(sid); ok {
+		if owner, ok := owner.(string); ok {
+			return owner
+		}
+	}
+
+	owner := sid
+
+	if ownerSID, err := windows.StringToSid(sid); err == nil {
+		if account, domain, _, err := ownerSID.LookupAccount(""); err == nil {
+			owner = domain + `\` + account
+		}
+	}
+
+	c.ownerCache.Store(sid, owner)
+
+	return owner
+}
@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package cert_enrollment
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	Name = "cert_enrollment"
+
+	applicationChannel = "Application"
+
+	providerCertEnroll     = "Microsoft-Windows-CertificateServicesClient-CertEnroll"
+	providerAutoEnrollment = "Microsoft-Windows-CertificateServicesClient-AutoEnrollment"
+
+	// eventIDEnrollmentSucceeded is logged by CertEnroll if a certificate of a template was received.
+	eventIDEnrollmentSucceeded = 19
+	// eventIDEnrollmentFailed is logged by CertEnroll if the enrollment for a certificate of a template failed.
+	eventIDEnrollmentFailed = 13
+	// eventIDAutoEnrollmentFailed is logged by AutoEnrollment if the auto-enrollment run failed as a whole,
+	// e.g. because the CA or the certificate templates couldn't be reached.
+	eventIDAutoEnrollmentFailed = 6
+
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+// renderValuePaths are the event properties rendered for each certificate enrollment event.
+// The order must match the value* indices.
+//
+//nolint:gochecknoglobals
+var renderValuePaths = []string{
+	"Event/System/EventRecordID",
+	"Event/System/EventID",
+	"Event/System/Provider/@Name",
+	"Event/EventData/Data[@Name='TemplateName']",
+}
+
+const (
+	valueEventRecordID = iota
+	valueEventID
+	valueProvider
+	valueTemplateName
+)
+
+type enrollmentKey struct {
+	template string
+	result   string
+}
+
+// A Collector is a Prometheus Collector for certificate auto-enrollment results.
+// Enrollments are counted from the CertificateServicesClient events in the Application event log
+// since the start of windows_exporter.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	renderContext wevtapi.EVT_HANDLE
+
+	// mu protects the counters and lastRecordID against concurrent scrapes.
+	mu                     sync.Mutex
+	lastRecordID           uint64
+	enrollments            map[enrollmentKey]float64
+	autoEnrollmentFailures float64
+
+	enrollmentsTotal            *prometheus.Desc
+	autoEnrollmentFailuresTotal *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if c.renderContext != 0 {
+		return wevtapi.EvtClose(c.renderContext)
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.enrollmentsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "enrollments_total"),
+		"Number of certificate enrollments by certificate template and result (success, failure)",
+		[]string{"template", "result"},
+		nil,
+	)
+	c.autoEnrollmentFailuresTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "autoenrollment_failures_total"),
+		"Number of failed certificate auto-enrollment runs, e.g. because the CA couldn't be reached",
+		nil,
+		nil,
+	)
+
+	c.enrollments = make(map[enrollmentKey]float64)
+
+	lastRecordID, err := wevtapi.LatestEventRecordID(applicationChannel)
+	if err != nil {
+		return fmt.Errorf("failed to read latest event of %s: %w", applicationChannel, err)
+	}
+
+	c.lastRecordID = lastRecordID
+
+	c.renderContext, err = wevtapi.EvtCreateRenderContext(renderValuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	query := fmt.Sprintf(
+		"*[System[(Provider[@Name='%s'] and (EventID=%d or EventID=%d)) or (Provider[@Name='%s'] and EventID=%d)] and System[EventRecordID > %d]]",
+		providerCertEnroll, eventIDEnrollmentSucceeded, eventIDEnrollmentFailed,
+		providerAutoEnrollment, eventIDAutoEnrollmentFailed,
+		c.lastRecordID,
+	)
+
+	if err := wevtapi.QueryValues(applicationChannel, query, c.renderContext, c.handleEvent); err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", applicationChannel, err)
+	}
+
+	for key, count := range c.enrollments {
+		ch <- prometheus.MustNewConstMetric(
+			c.enrollmentsTotal,
+			prometheus.CounterValue,
+			count,
+			key.template,
+			key.result,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.autoEnrollmentFailuresTotal,
+		prometheus.CounterValue,
+		c.autoEnrollmentFailures,
+	)
+
+	return nil
+}
+
+func (c *Collector) handleEvent(values []any) {
+	if len(values) != len(renderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[valueEventRecordID].(uint64)
+	c.lastRecordID = max(c.lastRecordID, recordID)
+
+	eventID, _ := values[valueEventID].(uint64)
+	provider, _ := values[valueProvider].(string)
+	template, _ := values[valueTemplateName].(string)
+
+	switch {
+	case provider == providerCertEnroll && eventID == eventIDEnrollmentSucceeded:
+		c.enrollments[enrollmentKey{template: template, result: resultSuccess}]++
+	case provider == providerCertEnroll && eventID == eventIDEnrollmentFailed:
+		c.enrollments[enrollmentKey{template: template, result: resultFailure}]++
+	case provider == providerAutoEnrollment && eventID == eventIDAutoEnrollmentFailed:
+		c.autoEnrollmentFailures++
+	}
+}
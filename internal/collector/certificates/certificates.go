@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package certificates
+
+import (
+	"crypto/sha1" //nolint:gosec // the thumbprint of a certificate is its SHA-1 hash
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"unsafe"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	Name = "certificates"
+
+	// subsystem is the metric prefix, which names a single certificate like the metrics.
+	subsystem = "certificate"
+)
+
+type Config struct {
+	Stores []string `yaml:"stores"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	Stores: []string{
+		"My",
+		"WebHosting",
+		"Remote Desktop",
+	},
+}
+
+// A Collector is a Prometheus Collector for the certificates of the LocalMachine certificate stores.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	notAfter  *prometheus.Desc
+	notBefore *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.Stores == nil {
+		config.Stores = ConfigDefaults.Stores
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+	c.config.Stores = make([]string, 0)
+
+	var stores string
+
+	app.Flag(
+		"collector.certificates.stores",
+		"Comma-separated list of LocalMachine certificate stores to enumerate.",
+	).Default(strings.Join(ConfigDefaults.Stores, ",")).StringVar(&stores)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config.Stores = strings.Split(stores, ",")
+
+		return nil
+	})
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	labels := []string{"store", "subject", "issuer", "thumbprint"}
+
+	c.notAfter = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, subsystem, "not_after_timestamp_seconds"),
+		"End of the validity period of the certificate as unix timestamp",
+		labels,
+		nil,
+	)
+	c.notBefore = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, subsystem, "not_before_timestamp_seconds"),
+		"Start of the validity period of the certificate as unix timestamp",
+		labels,
+		nil,
+	)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	for _, store := range c.config.Stores {
+		if err := c.collectStore(ch, store); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting certificates of store %s: %w", store, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectStore(ch chan<- prometheus.Metric, storeName string) error {
+	storeNamePtr, err := windows.UTF16PtrFromString(storeName)
+	if err != nil {
+		return err
+	}
+
+	store, err := windows.CertOpenStore(
+		windows.CERT_STORE_PROV_SYSTEM,
+		0,
+		0,
+		windows.CERT_SYSTEM_STORE_LOCAL_MACHINE|windows.CERT_STORE_READONLY_FLAG|windows.CERT_STORE_OPEN_EXISTING_FLAG,
+		uintptr(unsafe.Pointer(storeNamePtr)),
+	)
+	if err != nil {
+		// Stores like WebHosting or Remote Desktop only exist if the role is installed.
+		if errors.Is(err, windows.ERROR_FILE_NOT_FOUND) {
+			c.logger.Debug("certificate store does not exist",
+				slog.String("store", storeName),
+			)
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to open certificate store: %w", err)
+	}
+
+	defer windows.CertCloseStore(store, 0) //nolint:errcheck
+
+	var certContext *windows.CertContext
+
+	for {
+		// CertEnumCertificatesInStore frees the previous context.
+		certContext, err = windows.CertEnumCertificatesInStore(store, certContext)
+		if certContext == nil {
+			if errors.Is(err, windows.Errno(windows.CRYPT_E_NOT_FOUND)) {
+				return nil
+			}
+
+			return fmt.Errorf("failed to enumerate certificates: %w", err)
+		}
+
+		// The context is freed by the next call, so the certificate must not reference its memory.
+		encoded := slices.Clone(unsafe.Slice(certContext.EncodedCert, certContext.Length))
+
+		certificate, err := x509.ParseCertificate(encoded)
+		if err != nil {
+			c.logger.Debug("failed to parse certificate",
+				slog.String("store", storeName),
+				slog.Any("err", err),
+			)
+
+			continue
+		}
+
+		thumbprint := sha1.Sum(encoded) //nolint:gosec
+
+		labels := []string{
+			storeName,
+			certificate.Subject.String(),
+			certificate.Issuer.String(),
+			strings.ToUpper(hex.EncodeToString(thumbprint[:])),
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.notAfter,
+			prometheus.GaugeValue,
+			float64(certificate.NotAfter.Unix()),
+			labels...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.notBefore,
+			prometheus.GaugeValue,
+			float64(certificate.NotBefore.Unix()),
+			labels...,
+		)
+	}
+}
@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package citrix_vda
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const Name = "citrix_vda"
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+// A Collector is a Prometheus Collector for Citrix Virtual Delivery Agent ICA session metrics.
+// The ICA Session performance object is registered by the VDA; on hosts without the VDA the collector
+// is skipped at startup.
+type Collector struct {
+	config Config
+
+	perfDataCollector *pdh.Collector
+	perfDataObject    []perfDataCounterValuesICASession
+
+	inputBandwidth          *prometheus.Desc
+	outputBandwidth         *prometheus.Desc
+	inputLineSpeed          *prometheus.Desc
+	outputLineSpeed         *prometheus.Desc
+	outputThinWireBandwidth *prometheus.Desc
+	latencyLast             *prometheus.Desc
+	latencyAverage          *prometheus.Desc
+	latencyDeviation        *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	c.perfDataCollector.Close()
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	var err error
+
+	c.perfDataCollector, err = pdh.NewCollector[perfDataCounterValuesICASession](logger.With(slog.String("collector", Name)), pdh.CounterTypeRaw, "ICA Session", pdh.InstancesAll)
+	if err != nil {
+		return fmt.Errorf("failed to create ICA Session collector: %w", err)
+	}
+
+	c.inputBandwidth = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "session_input_bandwidth_bytes"),
+		"Bandwidth used from the client to the VDA by the ICA session in bytes per second",
+		[]string{"session"},
+		nil,
+	)
+	c.outputBandwidth = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "session_output_bandwidth_bytes"),
+		"Bandwidth used from the VDA to the client by the ICA session in bytes per second",
+		[]string{"session"},
+		nil,
+	)
+	c.inputLineSpeed = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "session_input_line_speed_bytes"),
+		"Line speed from the client to the VDA of the ICA session in bytes per second",
+		[]string{"session"},
+		nil,
+	)
+	c.outputLineSpeed = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "session_output_line_speed_bytes"),
+		"Line speed from the VDA to the client of the ICA session in bytes per second",
+		[]string{"session"},
+		nil,
+	)
+	c.outputThinWireBandwidth = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "session_output_thinwire_bandwidth_bytes"),
+		"Bandwidth used from the VDA to the client by the ThinWire graphics channel of the ICA session in bytes per second",
+		[]string{"session"},
+		nil,
+	)
+	c.latencyLast = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "session_latency_last_seconds"),
+		"Last recorded round-trip latency of the ICA session in seconds",
+		[]string{"session"},
+		nil,
+	)
+	c.latencyAverage = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "session_latency_average_seconds"),
+		"Average round-trip latency over the lifetime of the ICA session in seconds",
+		[]string{"session"},
+		nil,
+	)
+	c.latencyDeviation = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "session_latency_deviation_seconds"),
+		"Deviation of the round-trip latency over the lifetime of the ICA session in seconds",
+		[]string{"session"},
+		nil,
+	)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	err := c.perfDataCollector.Collect(&c.perfDataObject)
+	if err != nil {
+		return fmt.Errorf("failed to collect ICA Session metrics: %w", err)
+	}
+
+	// The ICA Session object has no instances while no user is connected.
+	for _, data := range c.perfDataObject {
+		ch <- prometheus.MustNewConstMetric(
+			c.inputBandwidth,
+			prometheus.GaugeValue,
+			data.InputSessionBandwidth/8,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.outputBandwidth,
+			prometheus.GaugeValue,
+			data.OutputSessionBandwidth/8,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.inputLineSpeed,
+			prometheus.GaugeValue,
+			data.InputSessionLineSpeed/8,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.outputLineSpeed,
+			prometheus.GaugeValue,
+			data.OutputSessionLineSpeed/8,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.outputThinWireBandwidth,
+			prometheus.GaugeValue,
+			data.OutputThinWireBandwidth/8,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.latencyLast,
+			prometheus.GaugeValue,
+			utils.MilliSecToSec(data.LatencyLastRecorded),
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.latencyAverage,
+			prometheus.GaugeValue,
+			utils.MilliSecToSec(data.LatencySessionAverage),
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.latencyDeviation,
+			prometheus.GaugeValue,
+			utils.MilliSecToSec(data.LatencySessionDeviation),
+			data.Name,
+		)
+	}
+
+	return nil
+}
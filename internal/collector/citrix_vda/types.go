@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package citrix_vda
+
+type perfDataCounterValuesICASession struct {
+	Name string
+
+	// ICA Session
+	InputSessionBandwidth   float64 `perfdata:"Input Session Bandwidth"`
+	OutputSessionBandwidth  float64 `perfdata:"Output Session Bandwidth"`
+	InputSessionLineSpeed   float64 `perfdata:"Input Session Line Speed"`
+	OutputSessionLineSpeed  float64 `perfdata:"Output Session Line Speed"`
+	OutputThinWireBandwidth float64 `perfdata:"Output ThinWire Bandwidth"`
+	LatencyLastRecorded     float64 `perfdata:"Latency - Last Recorded"`
+	LatencySessionAverage   float64 `perfdata:"Latency - Session Average"`
+	LatencySessionDeviation float64 `perfdata:"Latency - Session Deviation"`
+}
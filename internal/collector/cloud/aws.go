@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	providerAWS = "aws"
+
+	// awsTokenTTL is the lifetime of the IMDSv2 session tokens. Tokens are renewed a minute before they expire.
+	awsTokenTTL = 6 * time.Hour
+)
+
+// aws reads the EC2 instance metadata service with IMDSv2 session tokens.
+// 📑 https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/instancedata-data-retrieval.html
+type aws struct {
+	client   *http.Client
+	endpoint string
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+type awsIdentityDocument struct {
+	InstanceID       string `json:"instanceId"`
+	InstanceType     string `json:"instanceType"`
+	Region           string `json:"region"`
+	AvailabilityZone string `json:"availabilityZone"`
+}
+
+// awsInstanceAction is the interruption notice of a spot instance, which is issued two minutes before the interruption.
+// 📑 https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/spot-instance-termination-notices.html
+type awsInstanceAction struct {
+	Action string    `json:"action"`
+	Time   time.Time `json:"time"`
+}
+
+func (p *aws) name() string {
+	return providerAWS
+}
+
+func (p *aws) sessionToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Now().Before(p.tokenExpiry) {
+		return p.token, nil
+	}
+
+	body, err := request(ctx, p.client, http.MethodPut, p.endpoint+"/latest/api/token", http.Header{
+		"X-Aws-Ec2-Metadata-Token-Ttl-Seconds": {strconv.Itoa(int(awsTokenTTL.Seconds()))},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to request session token: %w", err)
+	}
+
+	p.token = strings.TrimSpace(string(body))
+	p.tokenExpiry = time.Now().Add(awsTokenTTL - time.Minute)
+
+	return p.token, nil
+}
+
+func (p *aws) get(ctx context.Context, path string) ([]byte, error) {
+	token, err := p.sessionToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return request(ctx, p.client, http.MethodGet, p.endpoint+path, http.Header{"X-Aws-Ec2-Metadata-Token": {token}})
+}
+
+func (p *aws) instance(ctx context.Context) (instance, error) {
+	body, err := p.get(ctx, "/latest/dynamic/instance-identity/document")
+	if err != nil {
+		return instance{}, err
+	}
+
+	var document awsIdentityDocument
+	if err := json.Unmarshal(body, &document); err != nil {
+		return instance{}, err
+	}
+
+	if document.InstanceID == "" {
+		return instance{}, errors.New("missing instanceId in instance identity document")
+	}
+
+	lifeCycle, err := p.get(ctx, "/latest/meta-data/instance-life-cycle")
+	if err != nil {
+		return instance{}, fmt.Errorf("failed to read instance life cycle: %w", err)
+	}
+
+	return instance{
+		id:           document.InstanceID,
+		instanceType: document.InstanceType,
+		region:       document.Region,
+		zone:         document.AvailabilityZone,
+		spot:         strings.TrimSpace(string(lifeCycle)) == "spot",
+	}, nil
+}
+
+func (p *aws) eviction(ctx context.Context) (eviction, error) {
+	body, err := p.get(ctx, "/latest/meta-data/spot/instance-action")
+	if errors.Is(err, errNotFound) {
+		return eviction{}, nil
+	}
+
+	if err != nil {
+		return eviction{}, err
+	}
+
+	var action awsInstanceAction
+	if err := json.Unmarshal(body, &action); err != nil {
+		return eviction{}, err
+	}
+
+	return eviction{pending: true, time: action.Time}, nil
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const providerAzure = "azure"
+
+// azure reads the Azure Instance Metadata Service.
+// 📑 https://learn.microsoft.com/en-us/azure/virtual-machines/instance-metadata-service
+type azure struct {
+	client   *http.Client
+	endpoint string
+}
+
+type azureCompute struct {
+	VMID     string `json:"vmId"`
+	VMSize   string `json:"vmSize"`
+	Location string `json:"location"`
+	Zone     string `json:"zone"`
+	// Priority is Regular, Spot or Low for the deprecated low-priority instances.
+	Priority string `json:"priority"`
+}
+
+// azureScheduledEvents are the scheduled events of the instance. Spot instances get a Preempt event at least
+// 30 seconds before the eviction.
+// 📑 https://learn.microsoft.com/en-us/azure/virtual-machines/windows/scheduled-events
+type azureScheduledEvents struct {
+	Events []struct {
+		EventType string `json:"EventType"`
+		NotBefore string `json:"NotBefore"`
+	} `json:"Events"`
+}
+
+func (p *azure) name() string {
+	return providerAzure
+}
+
+func (p *azure) get(ctx context.Context, path string, v any) error {
+	body, err := request(ctx, p.client, http.MethodGet, p.endpoint+path, http.Header{"Metadata": {"true"}})
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func (p *azure) instance(ctx context.Context) (instance, error) {
+	var compute azureCompute
+	if err := p.get(ctx, "/metadata/instance/compute?api-version=2021-02-01", &compute); err != nil {
+		return instance{}, err
+	}
+
+	if compute.VMID == "" {
+		return instance{}, errors.New("missing vmId in instance metadata")
+	}
+
+	return instance{
+		id:           compute.VMID,
+		instanceType: compute.VMSize,
+		region:       compute.Location,
+		zone:         compute.Zone,
+		spot:         compute.Priority != "" && compute.Priority != "Regular",
+	}, nil
+}
+
+func (p *azure) eviction(ctx context.Context) (eviction, error) {
+	var events azureScheduledEvents
+	if err := p.get(ctx, "/metadata/scheduledevents?api-version=2020-07-01", &events); err != nil {
+		return eviction{}, err
+	}
+
+	var ev eviction
+
+	for _, event := range events.Events {
+		if event.EventType != "Preempt" && event.EventType != "Terminate" {
+			continue
+		}
+
+		ev.pending = true
+
+		// NotBefore is empty once the event started.
+		notBefore, err := time.Parse(time.RFC1123, event.NotBefore)
+		if err != nil {
+			continue
+		}
+
+		if ev.time.IsZero() || notBefore.Before(ev.time) {
+			ev.time = notBefore
+		}
+	}
+
+	return ev, nil
+}
@@ -0,0 +1,320 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	Name = "cloud"
+
+	providerAuto = "auto"
+
+	// metadataEndpoint is the link-local address of the instance metadata services of Azure, AWS and GCP.
+	metadataEndpoint = "http://169.254.169.254"
+)
+
+type Config struct {
+	Provider string        `yaml:"provider"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	Provider: providerAuto,
+	Timeout:  2 * time.Second,
+}
+
+// A Collector is a Prometheus Collector for the instance metadata services of Azure, AWS and GCP.
+// The provider and the identity of the instance are read once, since resizing an instance requires a restart.
+// Eviction notices are read on each collection.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	endpoint   string
+	httpClient *http.Client
+	provider   provider
+	instance   instance
+
+	instanceInfo      *prometheus.Desc
+	instanceSpot      *prometheus.Desc
+	evictionPending   *prometheus.Desc
+	evictionTimestamp *prometheus.Desc
+}
+
+// provider reads the instance metadata service of a cloud provider.
+type provider interface {
+	name() string
+	// instance returns the identity of the instance. It fails if the host is not an instance of the provider.
+	instance(ctx context.Context) (instance, error)
+	// eviction returns the pending eviction of the instance, if any.
+	eviction(ctx context.Context) (eviction, error)
+}
+
+type instance struct {
+	id           string
+	instanceType string
+	region       string
+	zone         string
+	spot         bool
+}
+
+type eviction struct {
+	pending bool
+	// time is the earliest time of the eviction. It's zero if the provider doesn't announce it.
+	time time.Time
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.Provider == "" {
+		config.Provider = ConfigDefaults.Provider
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = ConfigDefaults.Timeout
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	app.Flag(
+		"collector.cloud.provider",
+		"Cloud provider of the instance. One of auto, azure, aws or gcp. auto detects the provider on startup.",
+	).Default(ConfigDefaults.Provider).EnumVar(&c.config.Provider, providerAuto, providerAzure, providerAWS, providerGCP)
+
+	app.Flag(
+		"collector.cloud.timeout",
+		"Timeout of each request to the instance metadata service.",
+	).Default(ConfigDefaults.Timeout.String()).DurationVar(&c.config.Timeout)
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if c.httpClient != nil {
+		c.httpClient.CloseIdleConnections()
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.instanceInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "instance_info"),
+		"Labelled identity of the cloud instance as provided by the instance metadata service",
+		[]string{"provider", "instance_id", "instance_type", "region", "zone"},
+		nil,
+	)
+	c.instanceSpot = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "instance_spot"),
+		"1 if the instance is a spot, low-priority or preemptible instance, 0 otherwise",
+		[]string{"provider"},
+		nil,
+	)
+	c.evictionPending = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "eviction_pending"),
+		"1 if the provider announced the eviction, preemption or termination of the instance, 0 otherwise",
+		[]string{"provider"},
+		nil,
+	)
+	c.evictionTimestamp = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "eviction_timestamp_seconds"),
+		"Unix timestamp of the earliest time of the announced eviction, if the provider announced it",
+		[]string{"provider"},
+		nil,
+	)
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return errors.New("unexpected type of http.DefaultTransport")
+	}
+
+	transport = transport.Clone()
+	// The instance metadata services are only reachable from the instance, they must not be requested through a proxy.
+	transport.Proxy = nil
+
+	c.httpClient = &http.Client{
+		Transport: transport,
+		Timeout:   c.config.Timeout,
+	}
+
+	if c.endpoint == "" {
+		c.endpoint = metadataEndpoint
+	}
+
+	providers := c.providers()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*c.config.Timeout)
+	defer cancel()
+
+	if c.config.Provider != providerAuto {
+		index := slices.IndexFunc(providers, func(p provider) bool { return p.name() == c.config.Provider })
+		if index == -1 {
+			return fmt.Errorf("unknown cloud provider %q", c.config.Provider)
+		}
+
+		ins, err := providers[index].instance(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read instance metadata of %s: %w", c.config.Provider, err)
+		}
+
+		c.provider, c.instance = providers[index], ins
+
+		return nil
+	}
+
+	c.provider, c.instance = detect(ctx, providers)
+	if c.provider == nil {
+		c.logger.Info("no instance metadata service detected, the host is not a cloud instance")
+
+		return nil
+	}
+
+	c.logger.Info("detected cloud instance",
+		slog.String("provider", c.provider.name()),
+		slog.String("instance_id", c.instance.id),
+	)
+
+	return nil
+}
+
+func (c *Collector) providers() []provider {
+	return []provider{
+		&azure{client: c.httpClient, endpoint: c.endpoint},
+		&aws{client: c.httpClient, endpoint: c.endpoint},
+		&gcp{client: c.httpClient, endpoint: c.endpoint},
+	}
+}
+
+// detect requests the instance metadata of all providers concurrently, since requests on hosts outside
+// a cloud run into the timeout. The providers share the endpoint, but each one requires its own header,
+// so at most one of them responds.
+func detect(ctx context.Context, providers []provider) (provider, instance) {
+	instances := make([]instance, len(providers))
+	errs := make([]error, len(providers))
+
+	var wg sync.WaitGroup
+
+	for i, p := range providers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			instances[i], errs[i] = p.instance(ctx)
+		}()
+	}
+
+	wg.Wait()
+
+	for i, p := range providers {
+		if errs[i] == nil {
+			return p, instances[i]
+		}
+	}
+
+	return nil, instance{}
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	if c.provider == nil {
+		return nil
+	}
+
+	providerName := c.provider.name()
+
+	ch <- prometheus.MustNewConstMetric(
+		c.instanceInfo,
+		prometheus.GaugeValue,
+		1.0,
+		providerName,
+		c.instance.id,
+		c.instance.instanceType,
+		c.instance.region,
+		c.instance.zone,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.instanceSpot,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(c.instance.spot),
+		providerName,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	ev, err := c.provider.eviction(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read eviction notices of %s: %w", providerName, err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.evictionPending,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(ev.pending),
+		providerName,
+	)
+
+	if ev.pending && !ev.time.IsZero() {
+		ch <- prometheus.MustNewConstMetric(
+			c.evictionTimestamp,
+			prometheus.GaugeValue,
+			float64(ev.time.Unix()),
+			providerName,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const providerGCP = "gcp"
+
+// gcp reads the metadata server of Compute Engine.
+// 📑 https://cloud.google.com/compute/docs/metadata/predefined-metadata-keys
+type gcp struct {
+	client   *http.Client
+	endpoint string
+}
+
+type gcpInstance struct {
+	ID json.Number `json:"id"`
+	// MachineType and Zone are resource paths, e.g. projects/123456789/zones/europe-west1-b.
+	MachineType string `json:"machineType"`
+	Zone        string `json:"zone"`
+	Scheduling  struct {
+		Preemptible string `json:"preemptible"`
+	} `json:"scheduling"`
+}
+
+func (p *gcp) name() string {
+	return providerGCP
+}
+
+func (p *gcp) get(ctx context.Context, path string) ([]byte, error) {
+	return request(ctx, p.client, http.MethodGet, p.endpoint+"/computeMetadata/v1/instance/"+path, http.Header{"Metadata-Flavor": {"Google"}})
+}
+
+func (p *gcp) instance(ctx context.Context) (instance, error) {
+	body, err := p.get(ctx, "?recursive=true")
+	if err != nil {
+		return instance{}, err
+	}
+
+	var metadata gcpInstance
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		return instance{}, err
+	}
+
+	if metadata.ID == "" {
+		return instance{}, errors.New("missing id in instance metadata")
+	}
+
+	zone := path.Base(metadata.Zone)
+	region := zone
+
+	if i := strings.LastIndexByte(zone, '-'); i > 0 {
+		region = zone[:i]
+	}
+
+	return instance{
+		id:           metadata.ID.String(),
+		instanceType: path.Base(metadata.MachineType),
+		region:       region,
+		zone:         zone,
+		// Spot VMs are preemptible as well.
+		spot: metadata.Scheduling.Preemptible == "TRUE",
+	}, nil
+}
+
+// eviction reports preempted instances and instances that will be stopped for a host maintenance.
+// Compute Engine doesn't announce the time, preempted instances are stopped after 30 seconds.
+func (p *gcp) eviction(ctx context.Context) (eviction, error) {
+	preempted, err := p.get(ctx, "preempted")
+	if err != nil {
+		return eviction{}, err
+	}
+
+	maintenance, err := p.get(ctx, "maintenance-event")
+	if err != nil {
+		return eviction{}, err
+	}
+
+	return eviction{
+		pending: strings.TrimSpace(string(preempted)) == "TRUE" ||
+			strings.TrimSpace(string(maintenance)) == "TERMINATE_ON_HOST_MAINTENANCE",
+	}, nil
+}
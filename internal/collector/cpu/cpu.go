@@ -20,7 +20,9 @@ package cpu
 import (
 	"fmt"
 	"log/slog"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
@@ -32,10 +34,16 @@ import (
 
 const Name = "cpu"
 
-type Config struct{}
+type Config struct {
+	InterruptStormThreshold float64       `yaml:"interrupt-storm.threshold"`
+	InterruptStormDuration  time.Duration `yaml:"interrupt-storm.duration"`
+}
 
 //nolint:gochecknoglobals
-var ConfigDefaults = Config{}
+var ConfigDefaults = Config{
+	InterruptStormThreshold: 0.5,
+	InterruptStormDuration:  time.Minute,
+}
 
 type Collector struct {
 	config Config
@@ -47,6 +55,7 @@ type Collector struct {
 
 	processorRTCValues   map[string]utils.Counter
 	processorMPerfValues map[string]utils.Counter
+	interruptStorms      map[string]*interruptStorm
 
 	logicalProcessors          *prometheus.Desc
 	cStateSecondsTotal         *prometheus.Desc
@@ -62,6 +71,9 @@ type Collector struct {
 	processorRTC               *prometheus.Desc
 	processorUtility           *prometheus.Desc
 	processorPrivilegedUtility *prometheus.Desc
+	dpcRate                    *prometheus.Desc
+	interruptStormActive       *prometheus.Desc
+	interruptStormDetections   *prometheus.Desc
 }
 
 func New(config *Config) *Collector {
@@ -69,6 +81,10 @@ func New(config *Config) *Collector {
 		config = &ConfigDefaults
 	}
 
+	if config.InterruptStormThreshold <= 0 {
+		config.InterruptStormThreshold = ConfigDefaults.InterruptStormThreshold
+	}
+
 	c := &Collector{
 		config: *config,
 	}
@@ -76,8 +92,22 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(_ *kingpin.Application) *Collector {
-	return &Collector{}
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	app.Flag(
+		"collector.cpu.interrupt-storm.threshold",
+		"Share of the time a processor spends servicing interrupts and DPCs above which it is considered to be in an interrupt storm.",
+	).Default(strconv.FormatFloat(c.config.InterruptStormThreshold, 'f', -1, 64)).Float64Var(&c.config.InterruptStormThreshold)
+
+	app.Flag(
+		"collector.cpu.interrupt-storm.duration",
+		"Time the interrupt and DPC share of a processor must stay above the threshold until an interrupt storm is detected.",
+	).Default(c.config.InterruptStormDuration.String()).DurationVar(&c.config.InterruptStormDuration)
+
+	return c
 }
 
 func (c *Collector) GetName() string {
@@ -180,6 +210,26 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 
 	c.processorRTCValues = map[string]utils.Counter{}
 	c.processorMPerfValues = map[string]utils.Counter{}
+	c.interruptStorms = map[string]*interruptStorm{}
+
+	c.dpcRate = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "dpc_rate"),
+		"Average rate at which DPCs were added to the DPC queue of the processor between the timer ticks of the processor clock",
+		[]string{"core"},
+		nil,
+	)
+	c.interruptStormActive = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "interrupt_storm"),
+		"1 if the processor spent more than the configured share of the time servicing interrupts and DPCs for at least the configured duration, 0 otherwise",
+		[]string{"core"},
+		nil,
+	)
+	c.interruptStormDetections = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "interrupt_storm_detections_total"),
+		"Number of detected interrupt storms of the processor since windows_exporter started",
+		[]string{"core"},
+		nil,
+	)
 
 	var err error
 
@@ -202,6 +252,8 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 
 	var coreCount float64
 
+	now := time.Now()
+
 	for _, coreData := range c.perfDataObject {
 		core := coreData.Name
 		coreCount++
@@ -360,6 +412,35 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 			coreData.PrivilegedUtilitySeconds,
 			core,
 		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.dpcRate,
+			prometheus.GaugeValue,
+			coreData.DpcRate,
+			core,
+		)
+
+		storm, ok := c.interruptStorms[core]
+		if !ok {
+			storm = &interruptStorm{}
+			c.interruptStorms[core] = storm
+		}
+
+		storm.observe(coreData.InterruptTimeSeconds+coreData.DpcTimeSeconds, now, c.config.InterruptStormThreshold, c.config.InterruptStormDuration)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.interruptStormActive,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(storm.active),
+			core,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.interruptStormDetections,
+			prometheus.CounterValue,
+			storm.detections,
+			core,
+		)
 	}
 
 	ch <- prometheus.MustNewConstMetric(
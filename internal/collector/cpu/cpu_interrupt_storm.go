@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package cpu
+
+import "time"
+
+// interruptStorm tracks the share of the time a processor spends servicing interrupts and DPCs between scrapes.
+// A storm is detected once the share stays above the threshold for the configured duration.
+type interruptStorm struct {
+	lastBusySeconds float64
+	lastSeen        time.Time
+
+	// aboveSince is the start of the current period above the threshold, zero if the processor is below.
+	aboveSince time.Time
+	active     bool
+	detections float64
+}
+
+// observe updates the state with the cumulative interrupt and DPC time of the processor.
+func (s *interruptStorm) observe(busySeconds float64, now time.Time, threshold float64, duration time.Duration) {
+	defer func() {
+		s.lastBusySeconds = busySeconds
+		s.lastSeen = now
+	}()
+
+	if s.lastSeen.IsZero() {
+		return
+	}
+
+	elapsed := now.Sub(s.lastSeen).Seconds()
+	busy := busySeconds - s.lastBusySeconds
+
+	// A negative delta means the counter was reset, e.g. if the processor was removed and added again.
+	if elapsed <= 0 || busy < 0 || busy/elapsed < threshold {
+		s.aboveSince = time.Time{}
+		s.active = false
+
+		return
+	}
+
+	if s.aboveSince.IsZero() {
+		s.aboveSince = s.lastSeen
+	}
+
+	if !s.active && now.Sub(s.aboveSince) >= duration {
+		s.active = true
+		s.detections++
+	}
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package cpu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterruptStorm(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1700000000, 0)
+	storm := &interruptStorm{}
+
+	// The first observation has no previous value to compare against.
+	storm.observe(10, start, 0.5, time.Minute)
+	require.False(t, storm.active)
+
+	// 80% interrupt and DPC time, but not yet for the configured duration.
+	storm.observe(34, start.Add(30*time.Second), 0.5, time.Minute)
+	require.False(t, storm.active)
+
+	storm.observe(58, start.Add(60*time.Second), 0.5, time.Minute)
+	require.True(t, storm.active)
+	require.InDelta(t, 1, storm.detections, 0)
+
+	// A sustained storm is only counted once.
+	storm.observe(82, start.Add(90*time.Second), 0.5, time.Minute)
+	require.True(t, storm.active)
+	require.InDelta(t, 1, storm.detections, 0)
+
+	storm.observe(83, start.Add(120*time.Second), 0.5, time.Minute)
+	require.False(t, storm.active)
+
+	// A counter reset ends the storm without detecting a new one.
+	storm.observe(1, start.Add(150*time.Second), 0.5, time.Minute)
+	require.False(t, storm.active)
+	require.InDelta(t, 1, storm.detections, 0)
+}
@@ -29,6 +29,7 @@ type perfDataCounterValues struct {
 	C3TransitionsTotal              float64 `perfdata:"C3 Transitions/sec"`
 	ClockInterruptsTotal            float64 `perfdata:"Clock Interrupts/sec"`
 	DpcQueuedPerSecond              float64 `perfdata:"DPCs Queued/sec"`
+	DpcRate                         float64 `perfdata:"DPC Rate"`
 	DpcTimeSeconds                  float64 `perfdata:"% DPC Time"`
 	IdleBreakEventsTotal            float64 `perfdata:"Idle Break Events/sec"`
 	IdleTimeSeconds                 float64 `perfdata:"% Idle Time"`
@@ -25,6 +25,8 @@ import (
 	"strings"
 
 	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/kernel32"
+	"github.com/prometheus-community/windows_exporter/internal/headers/powrprof"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
 	"github.com/prometheus-community/windows_exporter/internal/types"
 	"github.com/prometheus/client_golang/prometheus"
@@ -38,11 +40,16 @@ type Config struct{}
 var ConfigDefaults = Config{}
 
 // A Collector is a Prometheus Collector for a few WMI metrics in Win32_Processor.
+// The core topology is read from GetLogicalProcessorInformationEx, because Win32_Processor
+// doesn't describe hybrid cores, e.g. on ARM64 devices.
 type Collector struct {
 	config    Config
+	logger    *slog.Logger
 	miSession *mi.Session
 	miQuery   mi.Query
 
+	logicalProcessors []logicalProcessor
+
 	cpuInfo                   *prometheus.Desc
 	cpuCoreCount              *prometheus.Desc
 	cpuEnabledCoreCount       *prometheus.Desc
@@ -50,6 +57,15 @@ type Collector struct {
 	cpuThreadCount            *prometheus.Desc
 	cpuL2CacheSize            *prometheus.Desc
 	cpuL3CacheSize            *prometheus.Desc
+	coreEfficiencyClass       *prometheus.Desc
+	coreMaxFrequency          *prometheus.Desc
+}
+
+type logicalProcessor struct {
+	// core is formatted like the instance names of the Processor Information performance counters.
+	core            string
+	efficiencyClass uint8
+	maxFrequencyMHz uint32
 }
 
 func New(config *Config) *Collector {
@@ -76,7 +92,9 @@ func (c *Collector) Close() error {
 	return nil
 }
 
-func (c *Collector) Build(_ *slog.Logger, miSession *mi.Session) error {
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
 	c.cpuInfo = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, "", Name),
 		"Labelled CPU information as provided by Win32_Processor",
@@ -138,6 +156,23 @@ func (c *Collector) Build(_ *slog.Logger, miSession *mi.Session) error {
 		nil,
 	)
 
+	c.coreEfficiencyClass = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "core_efficiency_class"),
+		"Efficiency class of the physical core of the logical processor. On hybrid processors, e.g. ARM big.LITTLE, higher classes are faster cores",
+		[]string{
+			"core",
+		},
+		nil,
+	)
+	c.coreMaxFrequency = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "core_max_frequency_mhz"),
+		"Maximum frequency of the logical processor in MHz. Logical processors with the same maximum frequency usually share a frequency domain",
+		[]string{
+			"core",
+		},
+		nil,
+	)
+
 	if miSession == nil {
 		return errors.New("miSession is nil")
 	}
@@ -155,9 +190,64 @@ func (c *Collector) Build(_ *slog.Logger, miSession *mi.Session) error {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
+	c.logicalProcessors = c.lookupLogicalProcessors()
+
 	return nil
 }
 
+// lookupLogicalProcessors reads the processor topology once, since it doesn't change at runtime.
+// Errors are not fatal, because the topology only adds details to Win32_Processor.
+func (c *Collector) lookupLogicalProcessors() []logicalProcessor {
+	cores, err := kernel32.GetProcessorCores()
+	if err != nil {
+		c.logger.Debug("failed to get processor cores",
+			slog.Any("err", err),
+		)
+
+		return nil
+	}
+
+	logicalProcessors := make([]logicalProcessor, 0, len(cores))
+	singleGroup := true
+
+	for _, core := range cores {
+		for _, processor := range core.LogicalProcessors {
+			logicalProcessors = append(logicalProcessors, logicalProcessor{
+				core:            fmt.Sprintf("%d,%d", processor.Group, processor.Number),
+				efficiencyClass: core.EfficiencyClass,
+			})
+
+			singleGroup = singleGroup && processor.Group == 0
+		}
+	}
+
+	// CallNtPowerInformation only reports the processor group of the calling thread,
+	// so the maximum frequency is only known on systems with a single group.
+	if !singleGroup {
+		return logicalProcessors
+	}
+
+	powerInformation, err := powrprof.GetProcessorPowerInformation(len(logicalProcessors))
+	if err != nil {
+		c.logger.Debug("failed to get processor power information",
+			slog.Any("err", err),
+		)
+
+		return logicalProcessors
+	}
+
+	maxFrequencies := make(map[string]uint32, len(powerInformation))
+	for _, info := range powerInformation {
+		maxFrequencies[fmt.Sprintf("0,%d", info.Number)] = info.MaxMhz
+	}
+
+	for i, processor := range logicalProcessors {
+		logicalProcessors[i].maxFrequencyMHz = maxFrequencies[processor.core]
+	}
+
+	return logicalProcessors
+}
+
 type miProcessor struct {
 	Architecture              uint32 `mi:"Architecture"`
 	DeviceID                  string `mi:"DeviceID"`
@@ -238,5 +328,23 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		)
 	}
 
+	for _, processor := range c.logicalProcessors {
+		ch <- prometheus.MustNewConstMetric(
+			c.coreEfficiencyClass,
+			prometheus.GaugeValue,
+			float64(processor.efficiencyClass),
+			processor.core,
+		)
+
+		if processor.maxFrequencyMHz != 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.coreMaxFrequency,
+				prometheus.GaugeValue,
+				float64(processor.maxFrequencyMHz),
+				processor.core,
+			)
+		}
+	}
+
 	return nil
 }
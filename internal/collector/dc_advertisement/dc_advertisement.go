@@ -0,0 +1,369 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package dc_advertisement
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/netapi32"
+	"github.com/prometheus-community/windows_exporter/internal/headers/sysinfoapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "dc_advertisement"
+
+	ntdsParametersKey     = `SYSTEM\CurrentControlSet\Services\NTDS\Parameters`
+	netlogonParametersKey = `SYSTEM\CurrentControlSet\Services\Netlogon\Parameters`
+
+	// netlogonDNSPath lists the DNS records the Netlogon service registers for the domain controller.
+	netlogonDNSPath = `%SystemRoot%\System32\config\netlogon.dns`
+
+	// dnsTimeout limits the lookups of all SRV records of a scrape.
+	dnsTimeout = 5 * time.Second
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var (
+	// advertisementFlags are the services the domain controller advertises to the DC locator.
+	advertisementFlags = []struct {
+		name string
+		flag uint32
+	}{
+		{"pdc", netapi32.DS_PDC_FLAG},
+		{"gc", netapi32.DS_GC_FLAG},
+		{"ldap", netapi32.DS_LDAP_FLAG},
+		{"ds", netapi32.DS_DS_FLAG},
+		{"kdc", netapi32.DS_KDC_FLAG},
+		{"timeserv", netapi32.DS_TIMESERV_FLAG},
+		{"good_timeserv", netapi32.DS_GOOD_TIMESERV_FLAG},
+		{"writable", netapi32.DS_WRITABLE_FLAG},
+		{"closest", netapi32.DS_CLOSEST_FLAG},
+		{"ws", netapi32.DS_WS_FLAG},
+	}
+
+	shares = []string{"SYSVOL", "NETLOGON"}
+)
+
+// srvTarget is a target of a SRV record, which the domain controller registers.
+type srvTarget struct {
+	target string
+	port   uint16
+}
+
+// A Collector is a Prometheus Collector for the advertisement of domain controllers.
+// It reports whether the DC locator returns the local domain controller and which services it advertises,
+// whether the SRV records of netlogon.dns resolve to the domain controller, and whether SYSVOL and NETLOGON are shared.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	hostname string
+
+	advertising    *prometheus.Desc
+	advertisedFlag *prometheus.Desc
+	srvRegistered  *prometheus.Desc
+	shareAvailable *prometheus.Desc
+	sysvolReady    *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, ntdsParametersKey, registry.QUERY_VALUE)
+	if err != nil {
+		return fmt.Errorf("host is not a domain controller: failed to open registry key %s: %w", ntdsParametersKey, err)
+	}
+
+	_ = key.Close()
+
+	c.hostname, err = sysinfoapi.GetComputerName(sysinfoapi.ComputerNameDNSFullyQualified)
+	if err != nil {
+		return fmt.Errorf("failed to get DNS name of the host: %w", err)
+	}
+
+	c.advertising = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "advertising"),
+		"Whether the DC locator returns this domain controller (1) or another or no domain controller (0)",
+		nil,
+		nil,
+	)
+	c.advertisedFlag = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "flag"),
+		"Services advertised by this domain controller to the DC locator (1 = advertised)",
+		[]string{"flag"},
+		nil,
+	)
+	c.srvRegistered = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "srv_record_registered"),
+		"Whether the SRV record of netlogon.dns resolves to this domain controller (1) or not (0)",
+		[]string{"record"},
+		nil,
+	)
+	c.shareAvailable = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "share_available"),
+		"Whether the share is available on this domain controller (1) or not (0)",
+		[]string{"share"},
+		nil,
+	)
+	c.sysvolReady = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "sysvol_ready"),
+		"Whether the Netlogon service reports SYSVOL as ready (1) or not (0)",
+		nil,
+		nil,
+	)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if err := c.collectAdvertisement(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting DC locator metrics: %w", err))
+	}
+
+	if err := c.collectSRVRecords(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting SRV record metrics: %w", err))
+	}
+
+	if err := c.collectShares(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting share metrics: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// collectAdvertisement locates a domain controller on the local host. A domain controller that advertises itself
+// is returned by the DC locator, so any other result means that clients can't use it.
+func (c *Collector) collectAdvertisement(ch chan<- prometheus.Metric) error {
+	var (
+		advertising bool
+		flags       uint32
+	)
+
+	info, err := netapi32.DsGetDcName(netapi32.DS_FORCE_REDISCOVERY | netapi32.DS_DIRECTORY_SERVICE_REQUIRED | netapi32.DS_RETURN_DNS_NAME)
+
+	switch {
+	case errors.Is(err, windows.ERROR_NO_SUCH_DOMAIN):
+		c.logger.Debug("DC locator found no domain controller",
+			slog.Any("err", err),
+		)
+	case err != nil:
+		return err
+	case strings.EqualFold(info.DomainControllerName, c.hostname):
+		advertising = true
+		flags = info.Flags
+	default:
+		c.logger.Debug("DC locator returned another domain controller",
+			slog.String("domain_controller", info.DomainControllerName),
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.advertising,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(advertising),
+	)
+
+	for _, advertisementFlag := range advertisementFlags {
+		ch <- prometheus.MustNewConstMetric(
+			c.advertisedFlag,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(flags&advertisementFlag.flag != 0),
+			advertisementFlag.name,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectSRVRecords(ch chan<- prometheus.Metric) error {
+	path, err := registry.ExpandString(netlogonDNSPath)
+	if err != nil {
+		return fmt.Errorf("failed to expand path %s: %w", netlogonDNSPath, err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		// Netlogon writes the file after the first registration.
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	records := parseNetlogonDNS(content)
+
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+
+	for record, targets := range records {
+		ch <- prometheus.MustNewConstMetric(
+			c.srvRegistered,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(c.lookupSRV(ctx, record, targets)),
+			record,
+		)
+	}
+
+	return nil
+}
+
+// lookupSRV reports whether the SRV record resolves to all targets, which the domain controller registers for it.
+func (c *Collector) lookupSRV(ctx context.Context, record string, targets []srvTarget) bool {
+	_, addrs, err := net.DefaultResolver.LookupSRV(ctx, "", "", record)
+	if err != nil {
+		c.logger.Debug("failed to look up SRV record",
+			slog.String("record", record),
+			slog.Any("err", err),
+		)
+
+		return false
+	}
+
+	for _, target := range targets {
+		found := false
+
+		for _, addr := range addrs {
+			if addr.Port == target.port && strings.EqualFold(strings.TrimSuffix(addr.Target, "."), target.target) {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *Collector) collectShares(ch chan<- prometheus.Metric) error {
+	for _, share := range shares {
+		available, err := netapi32.ShareExists(share)
+		if err != nil {
+			return fmt.Errorf("failed to get share %s: %w", share, err)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.shareAvailable,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(available),
+			share,
+		)
+	}
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, netlogonParametersKey, registry.QUERY_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open registry key %s: %w", netlogonParametersKey, err)
+	}
+
+	defer key.Close()
+
+	sysvolReady, _, err := key.GetIntegerValue("SysvolReady")
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return fmt.Errorf("failed to read SysvolReady: %w", err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.sysvolReady,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(sysvolReady == 1),
+	)
+
+	return nil
+}
+
+// parseNetlogonDNS returns the SRV records of netlogon.dns by name. Lines have the format
+// "_ldap._tcp.example.com. 600 IN SRV 0 100 389 dc01.example.com.", other record types are skipped.
+func parseNetlogonDNS(content []byte) map[string][]srvTarget {
+	records := make(map[string][]srvTarget)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 || !strings.EqualFold(fields[3], "SRV") {
+			continue
+		}
+
+		port, err := strconv.ParseUint(fields[6], 10, 16)
+		if err != nil {
+			continue
+		}
+
+		record := strings.ToLower(strings.TrimSuffix(fields[0], "."))
+
+		records[record] = append(records[record], srvTarget{
+			target: strings.TrimSuffix(fields[7], "."),
+			port:   uint16(port),
+		})
+	}
+
+	return records
+}
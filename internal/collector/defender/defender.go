@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package defender
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	Name = "defender"
+
+	subCollectorASR               = "asr"
+	subCollectorNetworkProtection = "network_protection"
+
+	defenderChannel = "Microsoft-Windows-Windows Defender/Operational"
+)
+
+type Config struct {
+	CollectorsEnabled []string `yaml:"enabled"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	CollectorsEnabled: []string{
+		subCollectorASR,
+		subCollectorNetworkProtection,
+	},
+}
+
+// renderValuePaths are the event properties rendered for each Defender event.
+// The order must match the value* indices.
+//
+//nolint:gochecknoglobals
+var renderValuePaths = []string{
+	"Event/System/EventRecordID",
+	"Event/System/EventID",
+	"Event/EventData/Data[@Name='ID']",
+}
+
+const (
+	valueEventRecordID = iota
+	valueEventID
+	valueRuleID
+)
+
+// A Collector is a Prometheus Collector for Microsoft Defender events.
+// Events are read from the Defender operational event log. Counters only include
+// events logged since windows_exporter started.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	renderContext wevtapi.EVT_HANDLE
+	eventIDs      []uint64
+
+	// mu protects the event counters and lastRecordID against concurrent scrapes.
+	mu           sync.Mutex
+	lastRecordID uint64
+
+	asrRuleEvents           map[asrRuleEvent]float64
+	networkProtectionEvents map[string]float64
+
+	asrRuleEventsTotal           *prometheus.Desc
+	networkProtectionEventsTotal *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.CollectorsEnabled == nil {
+		config.CollectorsEnabled = ConfigDefaults.CollectorsEnabled
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+	c.config.CollectorsEnabled = make([]string, 0)
+
+	var collectorsEnabled string
+
+	app.Flag(
+		"collector.defender.enabled",
+		"Comma-separated list of collectors to use. Defaults to all, if not specified.",
+	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+
+		return nil
+	})
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if c.renderContext != 0 {
+		return wevtapi.EvtClose(c.renderContext)
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.asrRuleEventsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "asr_rule_events_total"),
+		"Number of Attack Surface Reduction rule events by rule and mode (audit, block)",
+		[]string{"rule_id", "rule", "mode"},
+		nil,
+	)
+
+	c.networkProtectionEventsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "network_protection_events_total"),
+		"Number of Network Protection events for connections to dangerous domains by mode (audit, block)",
+		[]string{"mode"},
+		nil,
+	)
+
+	c.asrRuleEvents = make(map[asrRuleEvent]float64)
+	c.networkProtectionEvents = make(map[string]float64)
+	c.eventIDs = make([]uint64, 0)
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorASR) {
+		c.eventIDs = append(c.eventIDs, eventIDASRBlock, eventIDASRAudit)
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorNetworkProtection) {
+		c.eventIDs = append(c.eventIDs, eventIDNetworkProtectionAudit, eventIDNetworkProtectionBlock)
+	}
+
+	if len(c.eventIDs) == 0 {
+		return nil
+	}
+
+	lastRecordID, err := wevtapi.LatestEventRecordID(defenderChannel)
+	if err != nil {
+		if errors.Is(err, wevtapi.ERROR_EVT_CHANNEL_NOT_FOUND) {
+			c.logger.Warn("Microsoft Defender event log not found, Defender may not be installed")
+
+			c.eventIDs = nil
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to read latest event of %s: %w", defenderChannel, err)
+	}
+
+	c.lastRecordID = lastRecordID
+
+	c.renderContext, err = wevtapi.EvtCreateRenderContext(renderValuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	if len(c.eventIDs) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.readEvents(); err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", defenderChannel, err)
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorASR) {
+		c.collectASR(ch)
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorNetworkProtection) {
+		c.collectNetworkProtection(ch)
+	}
+
+	return nil
+}
+
+// readEvents reads all Defender events logged since the last scrape and updates the event counters.
+func (c *Collector) readEvents() error {
+	eventIDFilter := make([]string, len(c.eventIDs))
+	for i, eventID := range c.eventIDs {
+		eventIDFilter[i] = "EventID=" + strconv.FormatUint(eventID, 10)
+	}
+
+	query := fmt.Sprintf("*[System[(%s) and EventRecordID > %d]]", strings.Join(eventIDFilter, " or "), c.lastRecordID)
+
+	return wevtapi.QueryValues(defenderChannel, query, c.renderContext, c.handleEvent)
+}
+
+func (c *Collector) handleEvent(values []any) {
+	if len(values) != len(renderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[valueEventRecordID].(uint64)
+	c.lastRecordID = max(c.lastRecordID, recordID)
+
+	eventID, _ := values[valueEventID].(uint64)
+
+	switch eventID {
+	case eventIDASRBlock, eventIDASRAudit:
+		ruleID, _ := values[valueRuleID].(string)
+
+		c.asrRuleEvents[asrRuleEvent{
+			ruleID: strings.ToLower(strings.Trim(ruleID, "{}")),
+			mode:   asrEventModes[eventID],
+		}]++
+	case eventIDNetworkProtectionAudit, eventIDNetworkProtectionBlock:
+		c.networkProtectionEvents[networkProtectionEventModes[eventID]]++
+	}
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package defender
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// eventIDNetworkProtectionAudit is logged when Network Protection in audit mode would have blocked a connection.
+	eventIDNetworkProtectionAudit = 1125
+	// eventIDNetworkProtectionBlock is logged when Network Protection blocked a connection.
+	eventIDNetworkProtectionBlock = 1126
+)
+
+//nolint:gochecknoglobals
+var networkProtectionEventModes = map[uint64]string{
+	eventIDNetworkProtectionAudit: "audit",
+	eventIDNetworkProtectionBlock: "block",
+}
+
+// collectNetworkProtection sends the Network Protection events by mode. The events don't contain the category of the
+// blocked domain, so there is no breakdown by category.
+func (c *Collector) collectNetworkProtection(ch chan<- prometheus.Metric) {
+	for _, mode := range networkProtectionEventModes {
+		ch <- prometheus.MustNewConstMetric(
+			c.networkProtectionEventsTotal,
+			prometheus.CounterValue,
+			c.networkProtectionEvents[mode],
+			mode,
+		)
+	}
+}
@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package delivery_optimization
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const Name = "delivery_optimization"
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var statusQuery = utils.Must(mi.NewQuery("SELECT FileSize, TotalBytesDownloaded, BytesFromHttp, BytesFromCacheServer, BytesFromLanPeers, BytesFromGroupPeers, BytesFromInternetPeers, BytesToLanPeers, BytesToGroupPeers, BytesToInternetPeers FROM MSFT_DeliveryOptimizationStatus"))
+
+// A Collector is a Prometheus Collector for Delivery Optimization metrics.
+// The metrics are aggregated from the files in the Delivery Optimization cache, as reported by Get-DeliveryOptimizationStatus.
+type Collector struct {
+	config    Config
+	logger    *slog.Logger
+	miSession *mi.Session
+
+	cacheFiles          *prometheus.Desc
+	cacheSize           *prometheus.Desc
+	downloadedBytes     *prometheus.Desc
+	uploadedBytes       *prometheus.Desc
+	incompleteDownloads *prometheus.Desc
+}
+
+// msftDeliveryOptimizationStatus represents a file in the Delivery Optimization cache or a file being downloaded.
+type msftDeliveryOptimizationStatus struct {
+	FileSize               uint64 `mi:"FileSize"`
+	TotalBytesDownloaded   uint64 `mi:"TotalBytesDownloaded"`
+	BytesFromHTTP          uint64 `mi:"BytesFromHttp"`
+	BytesFromCacheServer   uint64 `mi:"BytesFromCacheServer"`
+	BytesFromLanPeers      uint64 `mi:"BytesFromLanPeers"`
+	BytesFromGroupPeers    uint64 `mi:"BytesFromGroupPeers"`
+	BytesFromInternetPeers uint64 `mi:"BytesFromInternetPeers"`
+	BytesToLanPeers        uint64 `mi:"BytesToLanPeers"`
+	BytesToGroupPeers      uint64 `mi:"BytesToGroupPeers"`
+	BytesToInternetPeers   uint64 `mi:"BytesToInternetPeers"`
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.cacheFiles = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cache_files"),
+		"Number of files in the Delivery Optimization cache",
+		nil,
+		nil,
+	)
+	c.cacheSize = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cache_size_bytes"),
+		"Size of the files in the Delivery Optimization cache",
+		nil,
+		nil,
+	)
+	c.downloadedBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "downloaded_bytes"),
+		"Bytes downloaded for the files in the Delivery Optimization cache by source (http, cache_server, lan_peers, group_peers, internet_peers)",
+		[]string{"source"},
+		nil,
+	)
+	c.uploadedBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "uploaded_bytes"),
+		"Bytes uploaded to peers from the files in the Delivery Optimization cache by destination (lan_peers, group_peers, internet_peers)",
+		[]string{"destination"},
+		nil,
+	)
+	c.incompleteDownloads = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "incomplete_downloads"),
+		"Number of files in the Delivery Optimization cache that are not completely downloaded",
+		nil,
+		nil,
+	)
+
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.miSession = miSession
+
+	var dst []msftDeliveryOptimizationStatus
+	if err := c.miSession.Query(&dst, mi.NamespaceRootDeliveryOptimization, statusQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	var dst []msftDeliveryOptimizationStatus
+	if err := c.miSession.Query(&dst, mi.NamespaceRootDeliveryOptimization, statusQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	var (
+		cacheSize           uint64
+		incompleteDownloads int
+		downloaded          = map[string]uint64{}
+		uploaded            = map[string]uint64{}
+	)
+
+	for _, file := range dst {
+		cacheSize += file.FileSize
+
+		if file.TotalBytesDownloaded < file.FileSize {
+			incompleteDownloads++
+		}
+
+		downloaded["http"] += file.BytesFromHTTP
+		downloaded["cache_server"] += file.BytesFromCacheServer
+		downloaded["lan_peers"] += file.BytesFromLanPeers
+		downloaded["group_peers"] += file.BytesFromGroupPeers
+		downloaded["internet_peers"] += file.BytesFromInternetPeers
+
+		uploaded["lan_peers"] += file.BytesToLanPeers
+		uploaded["group_peers"] += file.BytesToGroupPeers
+		uploaded["internet_peers"] += file.BytesToInternetPeers
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.cacheFiles,
+		prometheus.GaugeValue,
+		float64(len(dst)),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.cacheSize,
+		prometheus.GaugeValue,
+		float64(cacheSize),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.incompleteDownloads,
+		prometheus.GaugeValue,
+		float64(incompleteDownloads),
+	)
+
+	for _, source := range []string{"http", "cache_server", "lan_peers", "group_peers", "internet_peers"} {
+		ch <- prometheus.MustNewConstMetric(
+			c.downloadedBytes,
+			prometheus.GaugeValue,
+			float64(downloaded[source]),
+			source,
+		)
+	}
+
+	for _, destination := range []string{"lan_peers", "group_peers", "internet_peers"} {
+		ch <- prometheus.MustNewConstMetric(
+			c.uploadedBytes,
+			prometheus.GaugeValue,
+			float64(uploaded[destination]),
+			destination,
+		)
+	}
+
+	return nil
+}
@@ -38,6 +38,8 @@ const (
 
 	subCollectorServerMetrics = "server_metrics"
 	subCollectorScopeMetrics  = "scope_metrics"
+	subCollectorV6Metrics     = "v6_metrics"
+	subCollectorFailover      = "failover_metrics"
 )
 
 type Config struct {
@@ -49,6 +51,8 @@ var ConfigDefaults = Config{
 	CollectorsEnabled: []string{
 		subCollectorServerMetrics,
 		subCollectorScopeMetrics,
+		subCollectorV6Metrics,
+		subCollectorFailover,
 	},
 }
 
@@ -89,6 +93,8 @@ type Collector struct {
 
 	scopeInfo                               *prometheus.Desc
 	scopeState                              *prometheus.Desc
+	scopeAddresses                          *prometheus.Desc
+	scopeUtilizationRatio                   *prometheus.Desc
 	scopeAddressesFreeTotal                 *prometheus.Desc
 	scopeAddressesFreeOnPartnerServerTotal  *prometheus.Desc
 	scopeAddressesFreeOnThisServerTotal     *prometheus.Desc
@@ -97,6 +103,15 @@ type Collector struct {
 	scopeAddressesInUseOnThisServerTotal    *prometheus.Desc
 	scopePendingOffersTotal                 *prometheus.Desc
 	scopeReservedAddressTotal               *prometheus.Desc
+
+	failoverRelationshipInfo  *prometheus.Desc
+	failoverRelationshipState *prometheus.Desc
+	failoverRelationshipScope *prometheus.Desc
+
+	v6MessagesTotal          *prometheus.Desc
+	v6ScopeAddressesFree     *prometheus.Desc
+	v6ScopeAddressesInUse    *prometheus.Desc
+	v6ScopePendingAdvertises *prometheus.Desc
 }
 
 func New(config *Config) *Collector {
@@ -169,6 +184,20 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 			nil,
 		)
 
+		c.scopeAddresses = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "scope_addresses"),
+			"DHCP Scope size of the address pool, i.e. the free addresses and the addresses in use",
+			[]string{"scope"},
+			nil,
+		)
+
+		c.scopeUtilizationRatio = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "scope_utilization_ratio"),
+			"DHCP Scope ratio of the addresses in use to the size of the address pool",
+			[]string{"scope"},
+			nil,
+		)
+
 		c.scopeAddressesFreeTotal = prometheus.NewDesc(
 			prometheus.BuildFQName(types.Namespace, Name, "scope_addresses_free"),
 			"DHCP Scope free addresses",
@@ -384,6 +413,14 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		}
 	}
 
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorV6Metrics) {
+		c.buildV6Metrics()
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorFailover) {
+		c.buildFailoverMetrics()
+	}
+
 	return nil
 }
 
@@ -402,6 +439,18 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		}
 	}
 
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorV6Metrics) {
+		if err := c.collectV6Metrics(ch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorFailover) {
+		if err := c.collectFailoverMetrics(ch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -600,6 +649,25 @@ func (c *Collector) collectScopeMetrics(ch chan<- prometheus.Metric) error {
 			)
 		}
 
+		if scope.Addresses != -1 {
+			ch <- prometheus.MustNewConstMetric(
+				c.scopeAddresses,
+				prometheus.GaugeValue,
+				scope.Addresses,
+				scopeID,
+			)
+
+			// A scope without addresses, e.g. because all addresses are excluded, has no utilization.
+			if scope.Addresses > 0 {
+				ch <- prometheus.MustNewConstMetric(
+					c.scopeUtilizationRatio,
+					prometheus.GaugeValue,
+					scope.AddressesInUse/scope.Addresses,
+					scopeID,
+				)
+			}
+		}
+
 		if scope.AddressesFree != -1 {
 			ch <- prometheus.MustNewConstMetric(
 				c.scopeAddressesFreeTotal,
@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package dhcp
+
+import (
+	"fmt"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/dhcpsapi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func (c *Collector) buildV6Metrics() {
+	c.v6MessagesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "v6_messages_total"),
+		"Total DHCPv6 messages received or sent by the DHCP server by message type",
+		[]string{"message_type"},
+		nil,
+	)
+	c.v6ScopeAddressesFree = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "v6_scope_addresses_free"),
+		"DHCPv6 Scope free addresses",
+		[]string{"scope"},
+		nil,
+	)
+	c.v6ScopeAddressesInUse = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "v6_scope_addresses_in_use"),
+		"DHCPv6 Scope addresses in use",
+		[]string{"scope"},
+		nil,
+	)
+	c.v6ScopePendingAdvertises = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "v6_scope_pending_advertises"),
+		"DHCPv6 Scope pending advertises",
+		[]string{"scope"},
+		nil,
+	)
+}
+
+func (c *Collector) collectV6Metrics(ch chan<- prometheus.Metric) error {
+	statistics, err := dhcpsapi.GetDHCPV6Statistics()
+	if err != nil {
+		return fmt.Errorf("failed to get DHCPv6 statistics: %w", err)
+	}
+
+	for messageType, value := range map[string]float64{
+		"solicit":             statistics.Solicits,
+		"advertise":           statistics.Advertises,
+		"request":             statistics.Requests,
+		"renew":               statistics.Renews,
+		"rebind":              statistics.Rebinds,
+		"reply":               statistics.Replies,
+		"confirm":             statistics.Confirms,
+		"decline":             statistics.Declines,
+		"release":             statistics.Releases,
+		"information_request": statistics.Informs,
+	} {
+		ch <- prometheus.MustNewConstMetric(
+			c.v6MessagesTotal,
+			prometheus.CounterValue,
+			value,
+			messageType,
+		)
+	}
+
+	for _, scope := range statistics.Scopes {
+		scopeID := scope.SubnetAddress.String()
+
+		ch <- prometheus.MustNewConstMetric(
+			c.v6ScopeAddressesFree,
+			prometheus.GaugeValue,
+			scope.AddressesFree,
+			scopeID,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.v6ScopeAddressesInUse,
+			prometheus.GaugeValue,
+			scope.AddressesInUse,
+			scopeID,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.v6ScopePendingAdvertises,
+			prometheus.GaugeValue,
+			scope.PendingAdvertises,
+			scopeID,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package dhcp
+
+import (
+	"fmt"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/dhcpsapi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func (c *Collector) buildFailoverMetrics() {
+	c.failoverRelationshipInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "failover_relationship_info"),
+		"DHCP failover relationship information",
+		[]string{"relationship", "mode", "server_type", "partner_server"},
+		nil,
+	)
+	c.failoverRelationshipState = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "failover_relationship_state"),
+		"DHCP failover relationship state",
+		[]string{"relationship", "state"},
+		nil,
+	)
+	c.failoverRelationshipScope = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "failover_relationship_scope_info"),
+		"DHCP Scopes of a failover relationship",
+		[]string{"relationship", "scope"},
+		nil,
+	)
+}
+
+func (c *Collector) collectFailoverMetrics(ch chan<- prometheus.Metric) error {
+	relationships, err := dhcpsapi.GetDHCPV4FailoverRelationships()
+	if err != nil {
+		return fmt.Errorf("failed to get DHCP failover relationships: %w", err)
+	}
+
+	for _, relationship := range relationships {
+		ch <- prometheus.MustNewConstMetric(
+			c.failoverRelationshipInfo,
+			prometheus.GaugeValue,
+			1,
+			relationship.Name,
+			dhcpsapi.DHCP_FAILOVER_MODE_NAMES[relationship.Mode],
+			dhcpsapi.DHCP_FAILOVER_SERVER_NAMES[relationship.ServerType],
+			relationship.PartnerServer,
+		)
+
+		for state, name := range dhcpsapi.FSM_STATE_NAMES {
+			metric := 0.0
+			if state == relationship.State {
+				metric = 1.0
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.failoverRelationshipState,
+				prometheus.GaugeValue,
+				metric,
+				relationship.Name,
+				name,
+			)
+		}
+
+		for _, scope := range relationship.Scopes {
+			ch <- prometheus.MustNewConstMetric(
+				c.failoverRelationshipScope,
+				prometheus.GaugeValue,
+				1,
+				relationship.Name,
+				scope.String(),
+			)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,446 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package disk_cleanup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "disk_cleanup"
+
+	// profileListKey contains the ProfilesDirectory value, the parent directory of the user profiles.
+	profileListKey = `SOFTWARE\Microsoft\Windows NT\CurrentVersion\ProfileList`
+
+	locationWindows = "windows"
+	locationUsers   = "users"
+)
+
+type Config struct {
+	ScanInterval time.Duration `yaml:"scan-interval"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	ScanInterval: time.Hour,
+}
+
+// A Collector is a Prometheus Collector for the space used by the Recycle Bins of the fixed volumes and by the
+// temporary directories, i.e. the space that Disk Cleanup and Storage Sense usually free.
+// The directories are scanned in the background every ScanInterval, since walking them can take minutes.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	ctxCancelFn context.CancelFunc
+
+	// mu protects the result of the last scan.
+	mu           sync.RWMutex
+	result       scanResult
+	scanDuration float64
+	scanTime     time.Time
+
+	recycleBinSizeBytes  *prometheus.Desc
+	recycleBinItems      *prometheus.Desc
+	tempSizeBytes        *prometheus.Desc
+	tempFiles            *prometheus.Desc
+	scanDurationSeconds  *prometheus.Desc
+	scanTimestampSeconds *prometheus.Desc
+}
+
+type scanResult struct {
+	recycleBins map[string]usage
+	temp        map[string]usage
+}
+
+// usage is the size and the number of entries of a directory tree.
+type usage struct {
+	bytes int64
+	files int64
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.ScanInterval == 0 {
+		config.ScanInterval = ConfigDefaults.ScanInterval
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	app.Flag(
+		"collector.disk_cleanup.scan-interval",
+		"Interval of scanning the Recycle Bins and temporary directories.",
+	).Default(ConfigDefaults.ScanInterval.String()).DurationVar(&c.config.ScanInterval)
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+// LastSuccess returns the time of the last successful scan, see collector.BackgroundCollector.
+func (c *Collector) LastSuccess() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.scanTime
+}
+
+func (c *Collector) Close() error {
+	if c.ctxCancelFn != nil {
+		c.ctxCancelFn()
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	if c.config.ScanInterval <= 0 {
+		return fmt.Errorf("invalid scan interval %s, expected a positive duration", c.config.ScanInterval)
+	}
+
+	c.recycleBinSizeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "recycle_bin_size_bytes"),
+		"Size of the files in the Recycle Bin of the volume, of all users",
+		[]string{"volume"},
+		nil,
+	)
+	c.recycleBinItems = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "recycle_bin_items"),
+		"Number of deleted files and directories in the Recycle Bin of the volume, of all users",
+		[]string{"volume"},
+		nil,
+	)
+	c.tempSizeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "temp_size_bytes"),
+		"Size of the files in the temporary directories (windows: Windows\\Temp, users: AppData\\Local\\Temp of all profiles)",
+		[]string{"location"},
+		nil,
+	)
+	c.tempFiles = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "temp_files"),
+		"Number of files in the temporary directories (windows: Windows\\Temp, users: AppData\\Local\\Temp of all profiles)",
+		[]string{"location"},
+		nil,
+	)
+	c.scanDurationSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "scan_duration_seconds"),
+		"Duration of the last scan of the Recycle Bins and temporary directories",
+		nil,
+		nil,
+	)
+	c.scanTimestampSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "scan_timestamp_seconds"),
+		"Timestamp of the end of the last scan of the Recycle Bins and temporary directories",
+		nil,
+		nil,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.ctxCancelFn = cancel
+
+	go c.scheduleScan(ctx)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// The metrics are missing until the first scan finished.
+	if c.scanTime.IsZero() {
+		return nil
+	}
+
+	for volume, recycleBin := range c.result.recycleBins {
+		ch <- prometheus.MustNewConstMetric(
+			c.recycleBinSizeBytes,
+			prometheus.GaugeValue,
+			float64(recycleBin.bytes),
+			volume,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.recycleBinItems,
+			prometheus.GaugeValue,
+			float64(recycleBin.files),
+			volume,
+		)
+	}
+
+	for location, temp := range c.result.temp {
+		ch <- prometheus.MustNewConstMetric(
+			c.tempSizeBytes,
+			prometheus.GaugeValue,
+			float64(temp.bytes),
+			location,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.tempFiles,
+			prometheus.GaugeValue,
+			float64(temp.files),
+			location,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.scanDurationSeconds,
+		prometheus.GaugeValue,
+		c.scanDuration,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.scanTimestampSeconds,
+		prometheus.GaugeValue,
+		float64(c.scanTime.Unix()),
+	)
+
+	return nil
+}
+
+func (c *Collector) scheduleScan(ctx context.Context) {
+	for {
+		if err := c.scan(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			c.logger.LogAttrs(ctx, slog.LevelWarn, "failed to scan the Recycle Bins and temporary directories",
+				slog.Any("err", err),
+			)
+		}
+
+		select {
+		case <-time.After(c.config.ScanInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Collector) scan(ctx context.Context) error {
+	start := time.Now()
+
+	result := scanResult{
+		recycleBins: make(map[string]usage),
+		temp:        make(map[string]usage),
+	}
+
+	for _, volume := range fixedVolumes() {
+		recycleBin, err := recycleBinUsage(ctx, volume+`\$Recycle.Bin`)
+		if err != nil {
+			return err
+		}
+
+		result.recycleBins[volume] = recycleBin
+	}
+
+	windowsTemp, err := directoryUsage(ctx, filepath.Join(os.Getenv("SystemRoot"), "Temp"))
+	if err != nil {
+		return err
+	}
+
+	result.temp[locationWindows] = windowsTemp
+
+	userTemp, err := userTempUsage(ctx)
+	if err != nil {
+		return err
+	}
+
+	result.temp[locationUsers] = userTemp
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.result = result
+	c.scanTime = time.Now()
+	c.scanDuration = c.scanTime.Sub(start).Seconds()
+
+	return nil
+}
+
+// fixedVolumes returns the drive letters of the fixed volumes, e.g. "C:".
+func fixedVolumes() []string {
+	drives, err := windows.GetLogicalDrives()
+	if err != nil {
+		return nil
+	}
+
+	volumes := make([]string, 0)
+
+	for i := range 26 {
+		if drives&(1<<i) == 0 {
+			continue
+		}
+
+		volume := string(rune('A'+i)) + ":"
+
+		root, err := windows.UTF16PtrFromString(volume + `\`)
+		if err != nil {
+			continue
+		}
+
+		if windows.GetDriveType(root) == windows.DRIVE_FIXED {
+			volumes = append(volumes, volume)
+		}
+	}
+
+	return volumes
+}
+
+// recycleBinUsage returns the usage of the Recycle Bin of a volume, which has a directory per user SID.
+// Each deleted file or directory is stored as a $R file or directory, with a $I file holding its original path.
+func recycleBinUsage(ctx context.Context, path string) (usage, error) {
+	total, err := directoryUsage(ctx, path)
+	if err != nil {
+		return usage{}, err
+	}
+
+	userDirectories, err := os.ReadDir(path)
+	if err != nil {
+		return total, nil //nolint:nilerr // e.g. a volume without Recycle Bin
+	}
+
+	total.files = 0
+
+	for _, userDirectory := range userDirectories {
+		entries, err := os.ReadDir(filepath.Join(path, userDirectory.Name()))
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), "$R") {
+				total.files++
+			}
+		}
+	}
+
+	return total, nil
+}
+
+// userTempUsage returns the usage of the temporary directories of all user profiles.
+func userTempUsage(ctx context.Context) (usage, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, profileListKey, registry.QUERY_VALUE)
+	if err != nil {
+		return usage{}, fmt.Errorf("failed to open registry key %s: %w", profileListKey, err)
+	}
+
+	defer key.Close()
+
+	profilesDirectory, _, err := key.GetStringValue("ProfilesDirectory")
+	if err != nil {
+		return usage{}, fmt.Errorf("failed to read ProfilesDirectory: %w", err)
+	}
+
+	profilesDirectory, err = registry.ExpandString(profilesDirectory)
+	if err != nil {
+		return usage{}, fmt.Errorf("failed to expand ProfilesDirectory: %w", err)
+	}
+
+	profiles, err := os.ReadDir(profilesDirectory)
+	if err != nil {
+		return usage{}, fmt.Errorf("failed to read %s: %w", profilesDirectory, err)
+	}
+
+	var total usage
+
+	for _, profile := range profiles {
+		if !profile.IsDir() {
+			continue
+		}
+
+		temp, err := directoryUsage(ctx, filepath.Join(profilesDirectory, profile.Name(), "AppData", "Local", "Temp"))
+		if err != nil {
+			return usage{}, err
+		}
+
+		total.bytes += temp.bytes
+		total.files += temp.files
+	}
+
+	return total, nil
+}
+
+// directoryUsage returns the size and the number of the files below path. Junctions are not followed, so no file
+// is counted twice. Files that can't be read, e.g. because they are deleted during the scan, are skipped.
+func directoryUsage(ctx context.Context, path string) (usage, error) {
+	var total usage
+
+	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if err != nil {
+			if entry != nil && entry.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil //nolint:nilerr // e.g. a file deleted during the scan
+		}
+
+		total.bytes += info.Size()
+		total.files++
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return usage{}, err
+	}
+
+	return total, nil
+}
@@ -32,9 +32,11 @@ import (
 )
 
 const (
-	Name                 = "dns"
-	subCollectorMetrics  = "metrics"
-	subCollectorWMIStats = "wmi_stats"
+	Name                   = "dns"
+	subCollectorMetrics    = "metrics"
+	subCollectorWMIStats   = "wmi_stats"
+	subCollectorQueryStats = "query_stats"
+	subCollectorDNSSEC     = "dnssec"
 )
 
 type Config struct {
@@ -46,6 +48,7 @@ var ConfigDefaults = Config{
 	CollectorsEnabled: []string{
 		subCollectorMetrics,
 		subCollectorWMIStats,
+		subCollectorQueryStats,
 	},
 }
 
@@ -56,8 +59,10 @@ type Collector struct {
 	perfDataCollector *pdh.Collector
 	perfDataObject    []perfDataCounterValues
 
-	miSession *mi.Session
-	miQuery   mi.Query
+	miSession    *mi.Session
+	miQuery      mi.Query
+	miQueryStats mi.Query
+	logger       *slog.Logger
 
 	dynamicUpdatesFailures        *prometheus.Desc
 	dynamicUpdatesQueued          *prometheus.Desc
@@ -82,6 +87,14 @@ type Collector struct {
 	zoneTransferSuccessReceived   *prometheus.Desc
 	zoneTransferSuccessSent       *prometheus.Desc
 	dnsWMIStats                   *prometheus.Desc
+	queriesByType                 *prometheus.Desc
+	responsesByRcode              *prometheus.Desc
+
+	// dnssec source
+	zoneSigned                  *prometheus.Desc
+	signingKeyLastRollover      *prometheus.Desc
+	signingKeyNextRollover      *prometheus.Desc
+	signingKeySignatureValidity *prometheus.Desc
 }
 
 func New(config *Config) *Collector {
@@ -134,13 +147,15 @@ func (c *Collector) Close() error {
 
 func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
 	for _, collector := range c.config.CollectorsEnabled {
-		if !slices.Contains([]string{subCollectorMetrics, subCollectorWMIStats}, collector) {
+		if !slices.Contains([]string{subCollectorMetrics, subCollectorWMIStats, subCollectorQueryStats, subCollectorDNSSEC}, collector) {
 			return fmt.Errorf("unknown sub collector: %s. Possible values: %s", collector,
-				strings.Join([]string{subCollectorMetrics, subCollectorWMIStats}, ", "),
+				strings.Join([]string{subCollectorMetrics, subCollectorWMIStats, subCollectorQueryStats, subCollectorDNSSEC}, ", "),
 			)
 		}
 	}
 
+	c.logger = logger.With(slog.String("collector", Name))
+
 	if slices.Contains(c.config.CollectorsEnabled, subCollectorMetrics) {
 		if err := c.buildMetricsCollector(logger); err != nil {
 			return err
@@ -153,6 +168,18 @@ func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
 		}
 	}
 
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorQueryStats) {
+		if err := c.buildQueryStatsCollector(miSession); err != nil {
+			return err
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorDNSSEC) {
+		if err := c.buildDNSSECCollector(miSession); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -323,6 +350,35 @@ func (c *Collector) buildErrorStatsCollector(miSession *mi.Session) error {
 	return nil
 }
 
+func (c *Collector) buildQueryStatsCollector(miSession *mi.Session) error {
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.queriesByType = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "queries_by_type_total"),
+		"Number of queries received by DNS server by query type",
+		[]string{"qtype"},
+		nil,
+	)
+	c.responsesByRcode = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "responses_by_rcode_total"),
+		"Number of responses sent by DNS server by response code",
+		[]string{"rcode"},
+		nil,
+	)
+
+	query, err := mi.NewQuery("SELECT Name, CollectionName, Value, DnsServerName FROM MicrosoftDNS_Statistic WHERE CollectionName = 'Query2 Stats' OR CollectionName = 'Error Stats'")
+	if err != nil {
+		return fmt.Errorf("failed to create query: %w", err)
+	}
+
+	c.miSession = miSession
+	c.miQueryStats = query
+
+	return nil
+}
+
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
 func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
@@ -340,6 +396,18 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		}
 	}
 
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorQueryStats) {
+		if err := c.collectQueryStats(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting query statistics: %w", err))
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorDNSSEC) {
+		if err := c.collectDNSSEC(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting DNSSEC metrics: %w", err))
+		}
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -647,3 +715,44 @@ func (c *Collector) collectErrorStats(ch chan<- prometheus.Metric) error {
 
 	return nil
 }
+
+// collectQueryStats exposes the query type and response code statistics of the DNS server.
+// Query types are reported as TypeA, TypeAAAA, TypeSRV, ... in the "Query2 Stats" collection,
+// response codes as NoError, NxDomain, ServFail, ... in the "Error Stats" collection.
+func (c *Collector) collectQueryStats(ch chan<- prometheus.Metric) error {
+	var stats []Statistic
+	if err := c.miSession.Query(&stats, mi.NamespaceRootMicrosoftDNS, c.miQueryStats); err != nil {
+		return fmt.Errorf("failed to query DNS statistics: %w", err)
+	}
+
+	for _, stat := range stats {
+		switch stat.CollectionName {
+		case "Query2 Stats":
+			qtype, ok := strings.CutPrefix(stat.Name, "Type")
+			if !ok {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.queriesByType,
+				prometheus.CounterValue,
+				float64(stat.Value),
+				strings.ToUpper(qtype),
+			)
+		case "Error Stats":
+			// Max is the upper bound marker of the response code table, not a response code.
+			if stat.Name == "Max" {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.responsesByRcode,
+				prometheus.CounterValue,
+				float64(stat.Value),
+				strings.ToUpper(stat.Name),
+			)
+		}
+	}
+
+	return nil
+}
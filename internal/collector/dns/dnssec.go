@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+//nolint:gochecknoglobals
+var dnsServerZoneQuery = utils.Must(mi.NewQuery("SELECT ZoneName, IsSigned FROM DnsServerZone"))
+
+// dnsServerZone is a zone of the DNS server as exposed by the DnsServer PowerShell module provider.
+type dnsServerZone struct {
+	ZoneName string `mi:"ZoneName"`
+	IsSigned bool   `mi:"IsSigned"`
+}
+
+// dnsServerSigningKey is a DNSSEC key signing key or zone signing key of a signed zone.
+type dnsServerSigningKey struct {
+	KeyID                         string        `mi:"KeyId"`
+	LastRolloverTime              time.Time     `mi:"LastRolloverTime"`
+	NextRolloverTime              time.Time     `mi:"NextRolloverTime"`
+	DnsKeySignatureValidityPeriod time.Duration `mi:"DnsKeySignatureValidityPeriod"`
+	DsSignatureValidityPeriod     time.Duration `mi:"DsSignatureValidityPeriod"`
+	ZoneSignatureValidityPeriod   time.Duration `mi:"ZoneSignatureValidityPeriod"`
+}
+
+func (c *Collector) buildDNSSECCollector(miSession *mi.Session) error {
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.zoneSigned = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "zone_dnssec_signed"),
+		"Whether the zone is signed with DNSSEC",
+		[]string{"zone"},
+		nil,
+	)
+	c.signingKeyLastRollover = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "dnssec_signing_key_last_rollover_timestamp_seconds"),
+		"Time of the last rollover of the DNSSEC signing key as unix timestamp",
+		[]string{"zone", "key_id"},
+		nil,
+	)
+	c.signingKeyNextRollover = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "dnssec_signing_key_next_rollover_timestamp_seconds"),
+		"Time when the next rollover of the DNSSEC signing key is due as unix timestamp",
+		[]string{"zone", "key_id"},
+		nil,
+	)
+	c.signingKeySignatureValidity = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "dnssec_signing_key_signature_validity_seconds"),
+		"Validity period of the signatures created with the DNSSEC signing key by record set (dnskey, ds, zone)",
+		[]string{"zone", "key_id", "record_set"},
+		nil,
+	)
+
+	c.miSession = miSession
+
+	return nil
+}
+
+func (c *Collector) collectDNSSEC(ch chan<- prometheus.Metric) error {
+	var zones []dnsServerZone
+	if err := c.miSession.Query(&zones, mi.NamespaceRootWindowsDNS, dnsServerZoneQuery); err != nil {
+		return fmt.Errorf("failed to query DNS server zones: %w", err)
+	}
+
+	errs := make([]error, 0)
+
+	for _, zone := range zones {
+		ch <- prometheus.MustNewConstMetric(
+			c.zoneSigned,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(zone.IsSigned),
+			zone.ZoneName,
+		)
+
+		if !zone.IsSigned {
+			continue
+		}
+
+		if err := c.collectSigningKeys(ch, zone.ZoneName); err != nil {
+			errs = append(errs, fmt.Errorf("failed to collect signing keys of zone %s: %w", zone.ZoneName, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectSigningKeys(ch chan<- prometheus.Metric, zoneName string) error {
+	query, err := mi.NewQuery(fmt.Sprintf(
+		"SELECT KeyId, LastRolloverTime, NextRolloverTime, DnsKeySignatureValidityPeriod, DsSignatureValidityPeriod, ZoneSignatureValidityPeriod FROM DnsServerSigningKey WHERE ZoneName = '%s'",
+		strings.ReplaceAll(zoneName, "'", `\'`),
+	))
+	if err != nil {
+		return fmt.Errorf("failed to create query: %w", err)
+	}
+
+	var keys []dnsServerSigningKey
+	if err := c.miSession.Query(&keys, mi.NamespaceRootWindowsDNS, query); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	for _, key := range keys {
+		if !key.LastRolloverTime.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				c.signingKeyLastRollover,
+				prometheus.GaugeValue,
+				float64(key.LastRolloverTime.Unix()),
+				zoneName,
+				key.KeyID,
+			)
+		}
+
+		if !key.NextRolloverTime.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				c.signingKeyNextRollover,
+				prometheus.GaugeValue,
+				float64(key.NextRolloverTime.Unix()),
+				zoneName,
+				key.KeyID,
+			)
+		} else {
+			c.logger.Debug("DNSSEC signing key has no scheduled rollover",
+				slog.String("zone", zoneName),
+				slog.String("key_id", key.KeyID),
+			)
+		}
+
+		for recordSet, validity := range map[string]time.Duration{
+			"dnskey": key.DnsKeySignatureValidityPeriod,
+			"ds":     key.DsSignatureValidityPeriod,
+			"zone":   key.ZoneSignatureValidityPeriod,
+		} {
+			ch <- prometheus.MustNewConstMetric(
+				c.signingKeySignatureValidity,
+				prometheus.GaugeValue,
+				validity.Seconds(),
+				zoneName,
+				key.KeyID,
+				recordSet,
+			)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,299 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package dns_client
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	Name = "dns_client"
+
+	// Status of negative cache entries. The positive entries have status 0.
+	// 📑 https://learn.microsoft.com/en-us/windows/win32/debug/system-error-codes--9000-11999-
+	dnsErrorRcodeNameError = 9003
+	dnsInfoNoRecords       = 9501
+
+	// ttlTolerance allows for the rounding of the remaining TTL of the entries between two scrapes.
+	ttlTolerance = 5 * time.Second
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var (
+	cacheQuery = utils.Must(mi.NewQuery("SELECT Entry, Type, Status, TimeToLive FROM MSFT_DNSClientCache"))
+
+	// recordTypes are the names of the record types of the type label. Other types are reported as "other".
+	recordTypes = map[uint16]string{
+		1:  "A",
+		2:  "NS",
+		5:  "CNAME",
+		6:  "SOA",
+		12: "PTR",
+		15: "MX",
+		16: "TXT",
+		28: "AAAA",
+		33: "SRV",
+		65: "HTTPS",
+	}
+
+	negativeStatuses = map[uint32]string{
+		dnsErrorRcodeNameError: "name_error",
+		dnsInfoNoRecords:       "no_records",
+	}
+)
+
+// A Collector is a Prometheus Collector for the cache of the DNS client service, read from the
+// MSFT_DNSClientCache class like Get-DnsClientCache.
+// Flushes of the cache, e.g. ipconfig /flushdns or a restart of the DNS client service, aren't logged,
+// so they are detected by comparing the entries with the entries of the previous scrape.
+type Collector struct {
+	config    Config
+	logger    *slog.Logger
+	miSession *mi.Session
+
+	// mu protects the entries of the previous scrape and the flush counter against concurrent scrapes.
+	mu      sync.Mutex
+	expiry  map[cacheKey]time.Time
+	flushes float64
+
+	cacheNames           *prometheus.Desc
+	cacheRecords         *prometheus.Desc
+	cacheNegativeEntries *prometheus.Desc
+	cacheFlushesTotal    *prometheus.Desc
+}
+
+type dnsClientCache struct {
+	Entry      string `mi:"Entry"`
+	Type       uint16 `mi:"Type"`
+	Status     uint32 `mi:"Status"`
+	TimeToLive uint32 `mi:"TimeToLive"`
+}
+
+// cacheKey identifies an entry of the cache. The records of an entry share the TTL.
+type cacheKey struct {
+	entry      string
+	recordType uint16
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.miSession = miSession
+
+	c.cacheNames = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cache_names"),
+		"Number of distinct names in the DNS client cache, including negative entries",
+		nil,
+		nil,
+	)
+	c.cacheRecords = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cache_records"),
+		"Number of resource records in the DNS client cache by record type",
+		[]string{"type"},
+		nil,
+	)
+	c.cacheNegativeEntries = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cache_negative_entries"),
+		"Number of negative entries in the DNS client cache, caching that a name or a record type doesn't exist, by status",
+		[]string{"status"},
+		nil,
+	)
+	c.cacheFlushesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cache_flushes_total"),
+		"Number of flushes of the DNS client cache detected since windows_exporter started",
+		nil,
+		nil,
+	)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	var dst []dnsClientCache
+	if err := c.miSession.Query(&dst, mi.NamespaceRootStandardCimv2, cacheQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	now := time.Now()
+
+	names := make(map[string]struct{})
+	records := make(map[string]int, len(recordTypes)+1)
+	negativeEntries := make(map[string]int, len(negativeStatuses)+1)
+	expiry := make(map[cacheKey]time.Time, len(dst))
+
+	for _, recordType := range recordTypes {
+		records[recordType] = 0
+	}
+
+	records["other"] = 0
+
+	for _, status := range negativeStatuses {
+		negativeEntries[status] = 0
+	}
+
+	negativeEntries["other"] = 0
+
+	for _, record := range dst {
+		names[record.Entry] = struct{}{}
+
+		key := cacheKey{entry: record.Entry, recordType: record.Type}
+		_, seen := expiry[key]
+		expiry[key] = now.Add(time.Duration(record.TimeToLive) * time.Second)
+
+		if record.Status == 0 {
+			records[recordTypeName(record.Type)]++
+
+			continue
+		}
+
+		// A negative entry is returned once per entry and type.
+		if !seen {
+			negativeEntries[negativeStatusName(record.Status)]++
+		}
+	}
+
+	c.mu.Lock()
+
+	if c.expiry != nil && flushed(c.expiry, expiry, now) {
+		c.flushes++
+	}
+
+	c.expiry = expiry
+	flushes := c.flushes
+
+	c.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(
+		c.cacheNames,
+		prometheus.GaugeValue,
+		float64(len(names)),
+	)
+
+	for recordType, count := range records {
+		ch <- prometheus.MustNewConstMetric(
+			c.cacheRecords,
+			prometheus.GaugeValue,
+			float64(count),
+			recordType,
+		)
+	}
+
+	for status, count := range negativeEntries {
+		ch <- prometheus.MustNewConstMetric(
+			c.cacheNegativeEntries,
+			prometheus.GaugeValue,
+			float64(count),
+			status,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.cacheFlushesTotal,
+		prometheus.CounterValue,
+		flushes,
+	)
+
+	return nil
+}
+
+// flushed reports whether the cache was flushed between two scrapes. It is flushed if none of the entries
+// of the previous scrape whose TTL didn't elapse yet is still cached. An entry that is cached again with
+// a new TTL, e.g. the entries of the hosts file that are loaded after a flush, doesn't count as still cached.
+// Without entries that should still be cached, a flush can't be detected.
+func flushed(previous, current map[cacheKey]time.Time, now time.Time) bool {
+	alive := 0
+
+	for key, expiry := range previous {
+		if !expiry.After(now.Add(ttlTolerance)) {
+			continue
+		}
+
+		alive++
+
+		if currentExpiry, ok := current[key]; ok && !currentExpiry.After(expiry.Add(ttlTolerance)) {
+			return false
+		}
+	}
+
+	return alive > 0
+}
+
+func recordTypeName(recordType uint16) string {
+	if name, ok := recordTypes[recordType]; ok {
+		return name
+	}
+
+	return "other"
+}
+
+func negativeStatusName(status uint32) string {
+	if name, ok := negativeStatuses[status]; ok {
+		return name
+	}
+
+	return "other"
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package dns_client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlushed(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	example := cacheKey{entry: "example.com", recordType: 1}
+	expired := cacheKey{entry: "expired.example.com", recordType: 1}
+	hosts := cacheKey{entry: "intranet", recordType: 1}
+
+	previous := map[cacheKey]time.Time{
+		example: now.Add(time.Hour),
+		expired: now.Add(-time.Minute),
+		hosts:   now.Add(24 * time.Hour),
+	}
+
+	for name, tc := range map[string]struct {
+		current  map[cacheKey]time.Time
+		expected bool
+	}{
+		"unchanged": {
+			current:  previous,
+			expected: false,
+		},
+		"expired entry evicted": {
+			current: map[cacheKey]time.Time{
+				example: now.Add(time.Hour),
+				hosts:   now.Add(24 * time.Hour),
+			},
+			expected: false,
+		},
+		"empty": {
+			current:  map[cacheKey]time.Time{},
+			expected: true,
+		},
+		"hosts file reloaded": {
+			current: map[cacheKey]time.Time{
+				hosts: now.Add(24*time.Hour + time.Minute),
+			},
+			expected: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.expected, flushed(previous, tc.current, now))
+		})
+	}
+
+	require.False(t, flushed(map[cacheKey]time.Time{expired: now.Add(-time.Minute)}, map[cacheKey]time.Time{}, now))
+}
@@ -0,0 +1,331 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package ephemeral_ports
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/iphlpapi"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	Name = "ephemeral_ports"
+
+	ipAddressFamilyIPv4 = "ipv4"
+	ipAddressFamilyIPv6 = "ipv6"
+
+	protocolTCP = "tcp"
+	protocolUDP = "udp"
+
+	systemChannel = "System"
+
+	// Tcpip events logged when no ephemeral port could be allocated because all ports are in use.
+	eventIDTCPPortExhaustion = 4231
+	eventIDUDPPortExhaustion = 4266
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var (
+	tcpSettingQuery = utils.Must(mi.NewQuery("SELECT DynamicPortRangeStartPort, DynamicPortRangeNumberOfPorts FROM MSFT_NetTCPSetting"))
+	udpSettingQuery = utils.Must(mi.NewQuery("SELECT DynamicPortRangeStartPort, DynamicPortRangeNumberOfPorts FROM MSFT_NetUDPSetting"))
+
+	portExhaustionQuery = fmt.Sprintf(
+		"*[System[Provider[@Name='Tcpip'] and (EventID=%d or EventID=%d) and EventRecordID > %%d]]",
+		eventIDTCPPortExhaustion, eventIDUDPPortExhaustion,
+	)
+
+	// renderValuePaths are the event properties rendered for each Tcpip event.
+	// The order must match the value* indices.
+	renderValuePaths = []string{
+		"Event/System/EventRecordID",
+		"Event/System/EventID",
+	}
+)
+
+const (
+	valueEventRecordID = iota
+	valueEventID
+)
+
+// A Collector is a Prometheus Collector for ephemeral port usage.
+// Port usage is read from the TCP and UDP endpoint tables, the dynamic port range
+// from the MSFT_NetTCPSetting and MSFT_NetUDPSetting classes. Allocation failures
+// are read from the System event log and only include events logged since
+// windows_exporter started.
+type Collector struct {
+	config    Config
+	logger    *slog.Logger
+	miSession *mi.Session
+
+	renderContext wevtapi.EVT_HANDLE
+
+	// mu protects the allocation failure counters and lastRecordID against concurrent scrapes.
+	mu                 sync.Mutex
+	lastRecordID       uint64
+	allocationFailures map[string]float64
+
+	portsInUse              *prometheus.Desc
+	dynamicPortRangeStart   *prometheus.Desc
+	dynamicPortRangeSize    *prometheus.Desc
+	allocationFailuresTotal *prometheus.Desc
+}
+
+type dynamicPortRange struct {
+	StartPort     uint16 `mi:"DynamicPortRangeStartPort"`
+	NumberOfPorts uint16 `mi:"DynamicPortRangeNumberOfPorts"`
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if c.renderContext != 0 {
+		return wevtapi.EvtClose(c.renderContext)
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.miSession = miSession
+
+	c.portsInUse = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "in_use"),
+		"Number of distinct local ports within the dynamic port range that are in use",
+		[]string{"protocol", "af"},
+		nil,
+	)
+	c.dynamicPortRangeStart = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "dynamic_port_range_start"),
+		"First port of the dynamic port range",
+		[]string{"protocol"},
+		nil,
+	)
+	c.dynamicPortRangeSize = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "dynamic_port_range_size"),
+		"Number of ports in the dynamic port range",
+		[]string{"protocol"},
+		nil,
+	)
+	c.allocationFailuresTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "allocation_failures_total"),
+		"Number of failed ephemeral port allocations because all ports were in use (Tcpip events 4231 and 4266)",
+		[]string{"protocol"},
+		nil,
+	)
+
+	c.allocationFailures = map[string]float64{
+		protocolTCP: 0,
+		protocolUDP: 0,
+	}
+
+	lastRecordID, err := wevtapi.LatestEventRecordID(systemChannel)
+	if err != nil {
+		return fmt.Errorf("failed to read latest event of %s: %w", systemChannel, err)
+	}
+
+	c.lastRecordID = lastRecordID
+
+	c.renderContext, err = wevtapi.EvtCreateRenderContext(renderValuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if err := c.collectProtocol(ch, protocolTCP, tcpSettingQuery, iphlpapi.GetTCPLocalPorts); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting TCP port metrics: %w", err))
+	}
+
+	if err := c.collectProtocol(ch, protocolUDP, udpSettingQuery, iphlpapi.GetUDPLocalPorts); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting UDP port metrics: %w", err))
+	}
+
+	if err := c.collectAllocationFailures(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting port allocation failures: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectProtocol(
+	ch chan<- prometheus.Metric,
+	protocol string,
+	settingQuery mi.Query,
+	localPorts func(family uint32) ([]uint16, error),
+) error {
+	portRange, err := c.queryDynamicPortRange(settingQuery)
+	if err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.dynamicPortRangeStart,
+		prometheus.GaugeValue,
+		float64(portRange.StartPort),
+		protocol,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.dynamicPortRangeSize,
+		prometheus.GaugeValue,
+		float64(portRange.NumberOfPorts),
+		protocol,
+	)
+
+	// The range end is computed as int, since start port + number of ports may exceed 65535.
+	start := int(portRange.StartPort)
+	end := start + int(portRange.NumberOfPorts)
+
+	for family, af := range map[uint32]string{
+		windows.AF_INET:  ipAddressFamilyIPv4,
+		windows.AF_INET6: ipAddressFamilyIPv6,
+	} {
+		ports, err := localPorts(family)
+		if err != nil {
+			return err
+		}
+
+		inUse := make(map[uint16]struct{})
+
+		for _, port := range ports {
+			if int(port) >= start && int(port) < end {
+				inUse[port] = struct{}{}
+			}
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.portsInUse,
+			prometheus.GaugeValue,
+			float64(len(inUse)),
+			protocol,
+			af,
+		)
+	}
+
+	return nil
+}
+
+// queryDynamicPortRange returns the dynamic port range of the protocol.
+// MSFT_NetTCPSetting returns one instance per setting template; the dynamic port range
+// is global, so the first instance with a configured range is used.
+func (c *Collector) queryDynamicPortRange(settingQuery mi.Query) (dynamicPortRange, error) {
+	var dst []dynamicPortRange
+	if err := c.miSession.Query(&dst, mi.NamespaceRootStandardCimv2, settingQuery); err != nil {
+		return dynamicPortRange{}, fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	for _, portRange := range dst {
+		if portRange.NumberOfPorts > 0 {
+			return portRange, nil
+		}
+	}
+
+	return dynamicPortRange{}, errors.New("WMI query returned no dynamic port range")
+}
+
+func (c *Collector) collectAllocationFailures(ch chan<- prometheus.Metric) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	query := fmt.Sprintf(portExhaustionQuery, c.lastRecordID)
+
+	if err := wevtapi.QueryValues(systemChannel, query, c.renderContext, c.handleEvent); err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", systemChannel, err)
+	}
+
+	for protocol, count := range c.allocationFailures {
+		ch <- prometheus.MustNewConstMetric(
+			c.allocationFailuresTotal,
+			prometheus.CounterValue,
+			count,
+			protocol,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) handleEvent(values []any) {
+	if len(values) != len(renderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[valueEventRecordID].(uint64)
+	c.lastRecordID = max(c.lastRecordID, recordID)
+
+	eventID, _ := values[valueEventID].(uint64)
+
+	switch eventID {
+	case eventIDTCPPortExhaustion:
+		c.allocationFailures[protocolTCP]++
+	case eventIDUDPPortExhaustion:
+		c.allocationFailures[protocolUDP]++
+	default:
+		c.logger.Debug("unexpected event ID", slog.String("event_id", strconv.FormatUint(eventID, 10)))
+	}
+}
@@ -0,0 +1,407 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package eventlog
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const Name = "eventlog"
+
+type Config struct {
+	Channels        []string       `yaml:"channels"`
+	Levels          []string       `yaml:"levels"`
+	ProviderInclude *regexp.Regexp `yaml:"provider-include"`
+	ProviderExclude *regexp.Regexp `yaml:"provider-exclude"`
+	EventIDInclude  *regexp.Regexp `yaml:"event-id-include"`
+	EventIDExclude  *regexp.Regexp `yaml:"event-id-exclude"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	Channels:        []string{"System", "Application"},
+	Levels:          []string{"critical", "error", "warning"},
+	ProviderInclude: types.RegExpAny,
+	ProviderExclude: types.RegExpEmpty,
+	EventIDInclude:  types.RegExpAny,
+	EventIDExclude:  types.RegExpEmpty,
+}
+
+//nolint:gochecknoglobals
+var (
+	// levelValues are the values of Event/System/Level by level name.
+	// Event Viewer shows events with level 0 (LogAlways), e.g. audit events of the Security channel, as information.
+	// 📑 https://learn.microsoft.com/en-us/windows/win32/wes/eventmanifestschema-leveltype-complextype
+	levelValues = map[string][]uint64{
+		"critical":    {1},
+		"error":       {2},
+		"warning":     {3},
+		"information": {0, 4},
+		"verbose":     {5},
+	}
+
+	levelNames = map[uint64]string{
+		0: "information",
+		1: "critical",
+		2: "error",
+		3: "warning",
+		4: "information",
+		5: "verbose",
+	}
+
+	// renderValuePaths are the event properties rendered for each event.
+	// The order must match the value* indices.
+	renderValuePaths = []string{
+		"Event/System/EventRecordID",
+		"Event/System/Provider/@Name",
+		"Event/System/EventID",
+		"Event/System/Level",
+	}
+)
+
+const (
+	valueEventRecordID = iota
+	valueProvider
+	valueEventID
+	valueLevel
+)
+
+// A Collector is a Prometheus Collector for the events of Windows Event Log channels.
+// The events written since windows_exporter started are counted by channel, provider, level and event ID.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	renderContext wevtapi.EVT_HANDLE
+	// queryFormat is the XPath query of the new events of a channel. It contains a %d verb for the last record ID.
+	queryFormat string
+
+	// mu protects channels and eventCounts against concurrent scrapes.
+	mu sync.Mutex
+	// channels are the channels that exist by name.
+	channels    map[string]*channel
+	eventCounts map[eventKey]float64
+
+	eventsTotal *prometheus.Desc
+}
+
+type channel struct {
+	lastRecordID uint64
+}
+
+type eventKey struct {
+	channel  string
+	provider string
+	level    string
+	eventID  string
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.Channels == nil {
+		config.Channels = ConfigDefaults.Channels
+	}
+
+	if config.Levels == nil {
+		config.Levels = ConfigDefaults.Levels
+	}
+
+	if config.ProviderInclude == nil {
+		config.ProviderInclude = ConfigDefaults.ProviderInclude
+	}
+
+	if config.ProviderExclude == nil {
+		config.ProviderExclude = ConfigDefaults.ProviderExclude
+	}
+
+	if config.EventIDInclude == nil {
+		config.EventIDInclude = ConfigDefaults.EventIDInclude
+	}
+
+	if config.EventIDExclude == nil {
+		config.EventIDExclude = ConfigDefaults.EventIDExclude
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+	c.config.Channels = make([]string, 0)
+	c.config.Levels = make([]string, 0)
+
+	var channels, levels, providerInclude, providerExclude, eventIDInclude, eventIDExclude string
+
+	app.Flag(
+		"collector.eventlog.channels",
+		"Comma-separated list of event log channels to count the events of, e.g. 'System,Application,Microsoft-Windows-PowerShell/Operational'.",
+	).Default(strings.Join(ConfigDefaults.Channels, ",")).StringVar(&channels)
+
+	app.Flag(
+		"collector.eventlog.levels",
+		"Comma-separated list of event levels to count. Can contain [\"critical\", \"error\", \"warning\", \"information\", \"verbose\"]. If empty, all levels are counted.",
+	).Default(strings.Join(ConfigDefaults.Levels, ",")).StringVar(&levels)
+
+	app.Flag(
+		"collector.eventlog.provider-include",
+		"Regexp of event providers to include. Provider name must both match include and not match exclude to be included.",
+	).Default(".+").StringVar(&providerInclude)
+
+	app.Flag(
+		"collector.eventlog.provider-exclude",
+		"Regexp of event providers to exclude. Provider name must both match include and not match exclude to be included.",
+	).Default("").StringVar(&providerExclude)
+
+	app.Flag(
+		"collector.eventlog.event-id-include",
+		"Regexp of event IDs to include, e.g. '7|153|1000'. Event ID must both match include and not match exclude to be included.",
+	).Default(".+").StringVar(&eventIDInclude)
+
+	app.Flag(
+		"collector.eventlog.event-id-exclude",
+		"Regexp of event IDs to exclude. Event ID must both match include and not match exclude to be included.",
+	).Default("").StringVar(&eventIDExclude)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config.Channels = strings.Split(channels, ",")
+
+		if levels != "" {
+			c.config.Levels = strings.Split(levels, ",")
+		}
+
+		var err error
+
+		c.config.ProviderInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", providerInclude))
+		if err != nil {
+			return fmt.Errorf("collector.eventlog.provider-include: %w", err)
+		}
+
+		c.config.ProviderExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", providerExclude))
+		if err != nil {
+			return fmt.Errorf("collector.eventlog.provider-exclude: %w", err)
+		}
+
+		c.config.EventIDInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", eventIDInclude))
+		if err != nil {
+			return fmt.Errorf("collector.eventlog.event-id-include: %w", err)
+		}
+
+		c.config.EventIDExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", eventIDExclude))
+		if err != nil {
+			return fmt.Errorf("collector.eventlog.event-id-exclude: %w", err)
+		}
+
+		return nil
+	})
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if c.renderContext != 0 {
+		return wevtapi.EvtClose(c.renderContext)
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.eventsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "events_total"),
+		"Number of events written to the event log channel since windows_exporter started",
+		[]string{"channel", "provider", "level", "event_id"},
+		nil,
+	)
+
+	levelConditions := make([]string, 0, len(c.config.Levels))
+
+	for _, level := range c.config.Levels {
+		values, ok := levelValues[level]
+		if !ok {
+			return fmt.Errorf("unknown event level %q", level)
+		}
+
+		for _, value := range values {
+			levelConditions = append(levelConditions, fmt.Sprintf("Level=%d", value))
+		}
+	}
+
+	c.queryFormat = "*[System[EventRecordID > %d]]"
+	if len(levelConditions) > 0 {
+		c.queryFormat = "*[System[(" + strings.Join(levelConditions, " or ") + ") and EventRecordID > %d]]"
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.channels = make(map[string]*channel, len(c.config.Channels))
+	c.eventCounts = make(map[eventKey]float64)
+
+	errs := make([]error, 0)
+
+	c.config.Channels = slices.DeleteFunc(slices.Compact(slices.Sorted(slices.Values(c.config.Channels))), func(name string) bool {
+		return name == ""
+	})
+
+	for _, name := range c.config.Channels {
+		if err := c.openChannel(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	if c.renderContext == 0 {
+		renderContext, err := wevtapi.EvtCreateRenderContext(renderValuePaths)
+		if err != nil {
+			return fmt.Errorf("failed to create event render context: %w", err)
+		}
+
+		c.renderContext = renderContext
+	}
+
+	return nil
+}
+
+// openChannel starts counting the events of the channel after its newest event.
+// Channels that don't exist yet, e.g. because the application is not installed, are opened on later scrapes.
+// c.mu must be held.
+func (c *Collector) openChannel(name string) error {
+	lastRecordID, err := wevtapi.LatestEventRecordID(name)
+	if err != nil {
+		if errors.Is(err, wevtapi.ERROR_EVT_CHANNEL_NOT_FOUND) {
+			c.logger.Debug("event log channel not found, counting starts once it exists",
+				slog.String("channel", name),
+			)
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to read latest event of %s: %w", name, err)
+	}
+
+	c.channels[name] = &channel{lastRecordID: lastRecordID}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	errs := make([]error, 0)
+
+	for _, name := range c.config.Channels {
+		state, ok := c.channels[name]
+		if !ok {
+			if err := c.openChannel(name); err != nil {
+				errs = append(errs, err)
+			}
+
+			continue
+		}
+
+		err := wevtapi.QueryValues(name, fmt.Sprintf(c.queryFormat, state.lastRecordID), c.renderContext, func(values []any) {
+			c.handleEvent(name, state, values)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read events of %s: %w", name, err))
+		}
+	}
+
+	for key, count := range c.eventCounts {
+		ch <- prometheus.MustNewConstMetric(
+			c.eventsTotal,
+			prometheus.CounterValue,
+			count,
+			key.channel,
+			key.provider,
+			key.level,
+			key.eventID,
+		)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) handleEvent(name string, state *channel, values []any) {
+	if len(values) != len(renderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[valueEventRecordID].(uint64)
+	state.lastRecordID = max(state.lastRecordID, recordID)
+
+	provider, _ := values[valueProvider].(string)
+	if c.config.ProviderExclude.MatchString(provider) || !c.config.ProviderInclude.MatchString(provider) {
+		return
+	}
+
+	eventID, _ := values[valueEventID].(uint64)
+
+	eventIDString := strconv.FormatUint(eventID, 10)
+	if c.config.EventIDExclude.MatchString(eventIDString) || !c.config.EventIDInclude.MatchString(eventIDString) {
+		return
+	}
+
+	level, _ := values[valueLevel].(uint64)
+
+	levelName, ok := levelNames[level]
+	if !ok {
+		levelName = strconv.FormatUint(level, 10)
+	}
+
+	c.eventCounts[eventKey{
+		channel:  name,
+		provider: provider,
+		level:    levelName,
+		eventID:  eventIDString,
+	}]++
+}
@@ -43,6 +43,11 @@ const (
 	subCollectorWorkloadManagement  = "WorkloadManagement"
 	subCollectorRpcClientAccess     = "RpcClientAccess"
 	subCollectorMapiHTTPEmsmdb      = "MapiHttpEmsmdb"
+	subCollectorTransportAgents     = "TransportAgents"
+	subCollectorTransportRules      = "TransportRules"
+	subCollectorProtocolLatency     = "ProtocolLatency"
+	subCollectorBackPressure        = "BackPressure"
+	subCollectorSearchIndexes       = "SearchIndexes"
 )
 
 type Config struct {
@@ -62,19 +67,29 @@ var ConfigDefaults = Config{
 		subCollectorWorkloadManagement,
 		subCollectorRpcClientAccess,
 		subCollectorMapiHTTPEmsmdb,
+		subCollectorTransportAgents,
+		subCollectorTransportRules,
+		subCollectorProtocolLatency,
+		subCollectorBackPressure,
+		subCollectorSearchIndexes,
 	},
 }
 
 type Collector struct {
 	collectorADAccessProcesses
 	collectorActiveSync
+	collectorBackPressure
 	collectorAutoDiscover
 	collectorAvailabilityService
 	collectorHTTPProxy
 	collectorMapiHTTPEmsMDB
 	collectorOWA
+	collectorProtocolLatency
 	collectorRpcClientAccess
+	collectorSearchIndexes
+	collectorTransportAgents
 	collectorTransportQueues
+	collectorTransportRules
 	collectorWorkloadManagementWorkloads
 
 	config Config
@@ -133,6 +148,11 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 				subCollectorWorkloadManagement:  "[19430] MSExchange WorkloadManagement Workloads",
 				subCollectorRpcClientAccess:     "[29336] MSExchange RpcClientAccess",
 				subCollectorMapiHTTPEmsmdb:      "[26463] MSExchange MapiHttp Emsmdb",
+				subCollectorTransportAgents:     "MSExchange Extensibility Agents",
+				subCollectorTransportRules:      "MSExchangeTransport Rules",
+				subCollectorProtocolLatency:     "MSExchange OWA, MSExchange ActiveSync, MSExchange MapiHttp Emsmdb, MSExchangeWS",
+				subCollectorBackPressure:        "MSExchangeTransport events 15004 and 15005 (Application log)",
+				subCollectorSearchIndexes:       "MSExchange Search Indexes",
 			}
 
 			sb := strings.Builder{}
@@ -229,6 +249,31 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 			collect: c.collectMapiHTTPEmsMDB,
 			close:   c.perfDataCollectorMapiHTTPEmsMDB.Close,
 		},
+		subCollectorTransportAgents: {
+			build:   c.buildTransportAgents,
+			collect: c.collectTransportAgents,
+			close:   c.perfDataCollectorTransportAgents.Close,
+		},
+		subCollectorTransportRules: {
+			build:   c.buildTransportRules,
+			collect: c.collectTransportRules,
+			close:   c.perfDataCollectorTransportRules.Close,
+		},
+		subCollectorProtocolLatency: {
+			build:   c.buildProtocolLatency,
+			collect: c.collectProtocolLatency,
+			close:   c.closeProtocolLatency,
+		},
+		subCollectorBackPressure: {
+			build:   c.buildBackPressure,
+			collect: c.collectBackPressure,
+			close:   c.closeBackPressure,
+		},
+		subCollectorSearchIndexes: {
+			build:   c.buildSearchIndexes,
+			collect: c.collectSearchIndexes,
+			close:   c.perfDataCollectorSearchIndexes.Close,
+		},
 	}
 
 	errs := make([]error, 0, len(c.config.CollectorsEnabled))
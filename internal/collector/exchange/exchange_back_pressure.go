@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package exchange
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	backPressureChannel = "Application"
+
+	// MSExchangeTransport logs these events when the back pressure state of the transport service changes.
+	// 📑 https://learn.microsoft.com/en-us/exchange/mail-flow/back-pressure
+	eventIDBackPressureIncreased = 15004
+	eventIDBackPressureDecreased = 15005
+)
+
+const (
+	backPressureValueOldState = iota
+	backPressureValueNewState
+	backPressureValueResources
+)
+
+//nolint:gochecknoglobals
+var (
+	backPressureQuery = fmt.Sprintf(
+		"*[System[Provider[@Name='MSExchangeTransport'] and (EventID=%d or EventID=%d)]]",
+		eventIDBackPressureIncreased, eventIDBackPressureDecreased,
+	)
+
+	backPressureStates = []string{"normal", "medium", "high"}
+
+	// backPressureResourceLine matches the resource lines of the event message, e.g.
+	// Queue database and disk space ("C:\Queue\mail.que") = 76% [Normal] [Normal=95% Medium=97% High=99%].
+	backPressureResourceLine = regexp.MustCompile(`^(.+?)(?: \("(.*)"\))? = \S+ \[(Normal|Medium|High)\]`)
+)
+
+type backPressureResource struct {
+	resource string
+	path     string
+	state    string
+}
+
+type collectorBackPressure struct {
+	backPressureRenderContext wevtapi.EVT_HANDLE
+
+	backPressureState         *prometheus.Desc
+	backPressureResourceState *prometheus.Desc
+}
+
+func (c *Collector) buildBackPressure() error {
+	c.backPressureState = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "transport_back_pressure_state"),
+		"Back pressure state of the transport service (normal, medium, high) as logged by the latest MSExchangeTransport event 15004 or 15005",
+		[]string{"state"},
+		nil,
+	)
+	c.backPressureResourceState = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "transport_back_pressure_resource_state"),
+		"Back pressure state of the resource monitored by the transport service (normal, medium, high) as logged by the latest MSExchangeTransport event 15004 or 15005",
+		[]string{"resource", "path", "state"},
+		nil,
+	)
+
+	var err error
+
+	c.backPressureRenderContext, err = wevtapi.EvtCreateUserRenderContext()
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Collector) closeBackPressure() {
+	if c.backPressureRenderContext != 0 {
+		_ = wevtapi.EvtClose(c.backPressureRenderContext)
+	}
+}
+
+func (c *Collector) collectBackPressure(ch chan<- prometheus.Metric) error {
+	values, err := wevtapi.LatestEventValues(backPressureChannel, backPressureQuery, c.backPressureRenderContext)
+	if err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", backPressureChannel, err)
+	}
+
+	// Without an event, the transport service didn't report back pressure since the log was cleared.
+	if len(values) <= backPressureValueResources {
+		return nil
+	}
+
+	state, _ := values[backPressureValueNewState].(string)
+	state = strings.ToLower(state)
+
+	for _, s := range backPressureStates {
+		ch <- prometheus.MustNewConstMetric(
+			c.backPressureState,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(s == state),
+			s,
+		)
+	}
+
+	resources, _ := values[backPressureValueResources].(string)
+
+	for _, resource := range c.parseBackPressureResources(resources) {
+		for _, s := range backPressureStates {
+			ch <- prometheus.MustNewConstMetric(
+				c.backPressureResourceState,
+				prometheus.GaugeValue,
+				utils.BoolToFloat(s == resource.state),
+				resource.resource,
+				resource.path,
+				s,
+			)
+		}
+	}
+
+	return nil
+}
+
+// parseBackPressureResources returns the resources listed in the message of a back pressure event.
+// The message lists each resource once, grouped by resources under pressure and resources in normal state.
+func (c *Collector) parseBackPressureResources(message string) []backPressureResource {
+	resources := make([]backPressureResource, 0)
+
+	for line := range strings.Lines(message) {
+		match := backPressureResourceLine.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+
+		resources = append(resources, backPressureResource{
+			resource: c.toLabelName(match[1]),
+			path:     match[2],
+			state:    strings.ToLower(match[3]),
+		})
+	}
+
+	return resources
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBackPressureResources(t *testing.T) {
+	t.Parallel()
+
+	message := "\r\nResource utilization of the following resources exceed the normal level:\r\n" +
+		"Version buckets = 155 [Medium] [Normal=80 Medium=120 High=200]\r\n" +
+		"\r\nThe following components are disabled due to back pressure:\r\nInbound mail submission from Hub Transport servers\r\n" +
+		"\r\nThe following resources are in normal state:\r\n" +
+		`Queue database and disk space ("C:\Program Files\Microsoft\Exchange Server\V15\TransportRoles\data\Queue\mail.que") = 76% [Normal] [Normal=95% Medium=97% High=99%]` + "\r\n" +
+		"Private bytes = 4% [Normal] [Normal=71% Medium=73% High=75%]\r\n" +
+		"Physical memory load = 67% [limit is 94% to start dehydrating messages.]\r\n"
+
+	c := &Collector{}
+
+	require.Equal(t, []backPressureResource{
+		{resource: "version_buckets", state: "medium"},
+		{
+			resource: "queue_database_and_disk_space",
+			path:     `C:\Program Files\Microsoft\Exchange Server\V15\TransportRoles\data\Queue\mail.que`,
+			state:    "normal",
+		},
+		{resource: "private_bytes", state: "normal"},
+	}, c.parseBackPressureResources(message))
+}
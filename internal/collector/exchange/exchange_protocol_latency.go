@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// protocolLatencySource reads the request rate and average latency of one client protocol.
+type protocolLatencySource struct {
+	protocol          string
+	perfDataCollector *pdh.Collector
+	collect           func() (requests float64, averageLatency float64, err error)
+}
+
+type collectorProtocolLatency struct {
+	protocolLatencySources []protocolLatencySource
+
+	protocolRequestsTotal     *prometheus.Desc
+	protocolAverageLatencySec *prometheus.Desc
+}
+
+// protocolLatencyCounterValues is implemented by the counter values of all client protocol objects.
+type protocolLatencyCounterValues interface {
+	requests() float64
+	averageLatency() float64
+}
+
+type perfDataCounterValuesProtocolOWA struct {
+	RequestsPerSec      float64 `perfdata:"Requests/sec"`
+	AverageResponseTime float64 `perfdata:"Average Response Time"`
+}
+
+func (v perfDataCounterValuesProtocolOWA) requests() float64       { return v.RequestsPerSec }
+func (v perfDataCounterValuesProtocolOWA) averageLatency() float64 { return v.AverageResponseTime }
+
+type perfDataCounterValuesProtocolEAS struct {
+	RequestsPerSec     float64 `perfdata:"Requests/sec"`
+	AverageRequestTime float64 `perfdata:"Average Request Time"`
+}
+
+func (v perfDataCounterValuesProtocolEAS) requests() float64       { return v.RequestsPerSec }
+func (v perfDataCounterValuesProtocolEAS) averageLatency() float64 { return v.AverageRequestTime }
+
+type perfDataCounterValuesProtocolMapiHTTP struct {
+	RequestsPerSec  float64 `perfdata:"Requests/sec"`
+	AveragedLatency float64 `perfdata:"Averaged Latency"`
+}
+
+func (v perfDataCounterValuesProtocolMapiHTTP) requests() float64       { return v.RequestsPerSec }
+func (v perfDataCounterValuesProtocolMapiHTTP) averageLatency() float64 { return v.AveragedLatency }
+
+type perfDataCounterValuesProtocolEWS struct {
+	RequestsPerSec      float64 `perfdata:"Requests/sec"`
+	AverageResponseTime float64 `perfdata:"Average Response Time"`
+}
+
+func (v perfDataCounterValuesProtocolEWS) requests() float64       { return v.RequestsPerSec }
+func (v perfDataCounterValuesProtocolEWS) averageLatency() float64 { return v.AverageResponseTime }
+
+func newProtocolLatencySource[T protocolLatencyCounterValues](logger *slog.Logger, protocol string, object string) (protocolLatencySource, error) {
+	perfDataCollector, err := pdh.NewCollector[T](logger, pdh.CounterTypeRaw, object, nil)
+	if err != nil {
+		return protocolLatencySource{}, fmt.Errorf("failed to create %s collector: %w", object, err)
+	}
+
+	var perfDataObject []T
+
+	return protocolLatencySource{
+		protocol:          protocol,
+		perfDataCollector: perfDataCollector,
+		collect: func() (float64, float64, error) {
+			if err := perfDataCollector.Collect(&perfDataObject); err != nil {
+				return 0, 0, fmt.Errorf("failed to collect %s metrics: %w", object, err)
+			}
+
+			if len(perfDataObject) == 0 {
+				return 0, 0, fmt.Errorf("failed to collect %s metrics: %w", object, types.ErrNoDataUnexpected)
+			}
+
+			return perfDataObject[0].requests(), perfDataObject[0].averageLatency(), nil
+		},
+	}, nil
+}
+
+func (c *Collector) buildProtocolLatency() error {
+	c.protocolRequestsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "protocol_requests_total"),
+		"Number of client requests handled by the protocol (owa, eas, mapihttp, ews)",
+		[]string{"protocol"},
+		nil,
+	)
+	c.protocolAverageLatencySec = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "protocol_avg_latency_sec"),
+		"Average latency (sec) of client requests handled by the protocol (owa, eas, mapihttp, ews)",
+		[]string{"protocol"},
+		nil,
+	)
+
+	builders := []func() (protocolLatencySource, error){
+		func() (protocolLatencySource, error) {
+			return newProtocolLatencySource[perfDataCounterValuesProtocolOWA](c.logger, "owa", "MSExchange OWA")
+		},
+		func() (protocolLatencySource, error) {
+			return newProtocolLatencySource[perfDataCounterValuesProtocolEAS](c.logger, "eas", "MSExchange ActiveSync")
+		},
+		func() (protocolLatencySource, error) {
+			return newProtocolLatencySource[perfDataCounterValuesProtocolMapiHTTP](c.logger, "mapihttp", "MSExchange MapiHttp Emsmdb")
+		},
+		func() (protocolLatencySource, error) {
+			return newProtocolLatencySource[perfDataCounterValuesProtocolEWS](c.logger, "ews", "MSExchangeWS")
+		},
+	}
+
+	errs := make([]error, 0, len(builders))
+
+	c.protocolLatencySources = make([]protocolLatencySource, 0, len(builders))
+
+	// Depending on the installed roles not all protocols are available.
+	// Protocols without performance counters are skipped.
+	for _, build := range builders {
+		source, err := build()
+		if err != nil {
+			c.logger.Debug("skipping protocol latency source",
+				slog.Any("err", err),
+			)
+
+			errs = append(errs, err)
+
+			continue
+		}
+
+		c.protocolLatencySources = append(c.protocolLatencySources, source)
+	}
+
+	if len(c.protocolLatencySources) == 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectProtocolLatency(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	for _, source := range c.protocolLatencySources {
+		requests, averageLatency, err := source.collect()
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.protocolRequestsTotal,
+			prometheus.CounterValue,
+			requests,
+			source.protocol,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.protocolAverageLatencySec,
+			prometheus.GaugeValue,
+			utils.MilliSecToSec(averageLatency),
+			source.protocol,
+		)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) closeProtocolLatency() {
+	for _, source := range c.protocolLatencySources {
+		source.perfDataCollector.Close()
+	}
+}
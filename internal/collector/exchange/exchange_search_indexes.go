@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package exchange
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type collectorSearchIndexes struct {
+	perfDataCollectorSearchIndexes *pdh.Collector
+	perfDataObjectSearchIndexes    []perfDataCounterValuesSearchIndexes
+
+	searchIndexFullCrawl                  *prometheus.Desc
+	searchIndexAvgDocumentIndexingTimeSec *prometheus.Desc
+}
+
+type perfDataCounterValuesSearchIndexes struct {
+	Name string
+
+	FullCrawlModeStatus         float64 `perfdata:"Full Crawl Mode Status"`
+	AverageDocumentIndexingTime float64 `perfdata:"Average Document Indexing Time"`
+}
+
+func (c *Collector) buildSearchIndexes() error {
+	var err error
+
+	c.perfDataCollectorSearchIndexes, err = pdh.NewCollector[perfDataCounterValuesSearchIndexes](c.logger, pdh.CounterTypeRaw, "MSExchange Search Indexes", pdh.InstancesAll)
+	if err != nil {
+		return fmt.Errorf("failed to create MSExchange Search Indexes collector: %w", err)
+	}
+
+	c.searchIndexFullCrawl = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "search_index_full_crawl"),
+		"Whether the content index of the database is crawled (1), e.g. after it was reseeded or failed, or not (0)",
+		[]string{"database"},
+		nil,
+	)
+	c.searchIndexAvgDocumentIndexingTimeSec = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "search_index_avg_document_indexing_time_sec"),
+		"Average time (sec) to index a document of the database",
+		[]string{"database"},
+		nil,
+	)
+
+	return nil
+}
+
+func (c *Collector) collectSearchIndexes(ch chan<- prometheus.Metric) error {
+	err := c.perfDataCollectorSearchIndexes.Collect(&c.perfDataObjectSearchIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to collect MSExchange Search Indexes metrics: %w", err)
+	}
+
+	for _, data := range c.perfDataObjectSearchIndexes {
+		if strings.EqualFold(data.Name, pdh.InstanceTotal) {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.searchIndexFullCrawl,
+			prometheus.GaugeValue,
+			data.FullCrawlModeStatus,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.searchIndexAvgDocumentIndexingTimeSec,
+			prometheus.GaugeValue,
+			utils.MilliSecToSec(data.AverageDocumentIndexingTime),
+			data.Name,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package exchange
+
+import (
+	"fmt"
+
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type collectorTransportAgents struct {
+	perfDataCollectorTransportAgents *pdh.Collector
+	perfDataObjectTransportAgents    []perfDataCounterValuesTransportAgents
+
+	transportAgentAvgProcessingTime *prometheus.Desc
+	transportAgentInvocationsTotal  *prometheus.Desc
+}
+
+type perfDataCounterValuesTransportAgents struct {
+	Name string
+
+	AverageAgentProcessingTime float64 `perfdata:"Average Agent Processing Time (sec)"`
+	TotalAgentInvocations      float64 `perfdata:"Total Agent Invocations"`
+}
+
+func (c *Collector) buildTransportAgents() error {
+	var err error
+
+	c.perfDataCollectorTransportAgents, err = pdh.NewCollector[perfDataCounterValuesTransportAgents](c.logger, pdh.CounterTypeRaw, "MSExchange Extensibility Agents", pdh.InstancesAll)
+	if err != nil {
+		return fmt.Errorf("failed to create MSExchange Extensibility Agents collector: %w", err)
+	}
+
+	c.transportAgentAvgProcessingTime = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "transport_agent_avg_processing_time_sec"),
+		"Average time (sec) the transport agent spent processing a message",
+		[]string{"name"},
+		nil,
+	)
+	c.transportAgentInvocationsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "transport_agent_invocations_total"),
+		"Number of times the transport agent has been invoked",
+		[]string{"name"},
+		nil,
+	)
+
+	return nil
+}
+
+func (c *Collector) collectTransportAgents(ch chan<- prometheus.Metric) error {
+	err := c.perfDataCollectorTransportAgents.Collect(&c.perfDataObjectTransportAgents)
+	if err != nil {
+		return fmt.Errorf("failed to collect MSExchange Extensibility Agents metrics: %w", err)
+	}
+
+	for _, data := range c.perfDataObjectTransportAgents {
+		labelName := c.toLabelName(data.Name)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.transportAgentAvgProcessingTime,
+			prometheus.GaugeValue,
+			data.AverageAgentProcessingTime,
+			labelName,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.transportAgentInvocationsTotal,
+			prometheus.CounterValue,
+			data.TotalAgentInvocations,
+			labelName,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package exchange
+
+import (
+	"fmt"
+
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type collectorTransportRules struct {
+	perfDataCollectorTransportRules *pdh.Collector
+	perfDataObjectTransportRules    []perfDataCounterValuesTransportRules
+
+	transportRulesMessagesEvaluatedTotal *prometheus.Desc
+	transportRulesMessagesProcessedTotal *prometheus.Desc
+}
+
+type perfDataCounterValuesTransportRules struct {
+	Name string
+
+	MessagesEvaluated float64 `perfdata:"Messages Evaluated"`
+	MessagesProcessed float64 `perfdata:"Messages Processed"`
+}
+
+func (c *Collector) buildTransportRules() error {
+	var err error
+
+	c.perfDataCollectorTransportRules, err = pdh.NewCollector[perfDataCounterValuesTransportRules](c.logger, pdh.CounterTypeRaw, "MSExchangeTransport Rules", pdh.InstancesAll)
+	if err != nil {
+		return fmt.Errorf("failed to create MSExchangeTransport Rules collector: %w", err)
+	}
+
+	c.transportRulesMessagesEvaluatedTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "transport_rules_messages_evaluated_total"),
+		"Number of messages evaluated by the transport rules agent",
+		[]string{"name"},
+		nil,
+	)
+	c.transportRulesMessagesProcessedTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "transport_rules_messages_processed_total"),
+		"Number of messages that matched a transport rule and had its actions applied",
+		[]string{"name"},
+		nil,
+	)
+
+	return nil
+}
+
+func (c *Collector) collectTransportRules(ch chan<- prometheus.Metric) error {
+	err := c.perfDataCollectorTransportRules.Collect(&c.perfDataObjectTransportRules)
+	if err != nil {
+		return fmt.Errorf("failed to collect MSExchangeTransport Rules metrics: %w", err)
+	}
+
+	for _, data := range c.perfDataObjectTransportRules {
+		labelName := c.toLabelName(data.Name)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.transportRulesMessagesEvaluatedTotal,
+			prometheus.CounterValue,
+			data.MessagesEvaluated,
+			labelName,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.transportRulesMessagesProcessedTotal,
+			prometheus.CounterValue,
+			data.MessagesProcessed,
+			labelName,
+		)
+	}
+
+	return nil
+}
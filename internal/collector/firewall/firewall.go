@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package firewall
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+const Name = "firewall"
+
+type Config struct {
+	LogPath string `yaml:"log-path"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	LogPath: `%SystemRoot%\System32\LogFiles\Firewall\pfirewall.log`,
+}
+
+// A Collector is a Prometheus Collector for the Windows Firewall log.
+// The log is tailed on each scrape; counters only include packets logged since windows_exporter started.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	logPath string
+
+	// mu protects the log state and the counters against concurrent scrapes.
+	mu     sync.Mutex
+	offset int64
+	fields logFields
+
+	droppedPackets map[droppedPacket]float64
+
+	droppedPacketsTotal *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.LogPath == "" {
+		config.LogPath = ConfigDefaults.LogPath
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	app.Flag(
+		"collector.firewall.log-path",
+		"Path of the Windows Firewall log file. Logging of dropped packets must be enabled in the firewall profiles.",
+	).Default(ConfigDefaults.LogPath).StringVar(&c.config.LogPath)
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.droppedPacketsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "dropped_packets_total"),
+		"Number of packets dropped by the Windows Firewall by direction, protocol and destination port class",
+		[]string{"direction", "protocol", "port_class"},
+		nil,
+	)
+
+	logPath, err := registry.ExpandString(c.config.LogPath)
+	if err != nil {
+		return fmt.Errorf("failed to expand log path %s: %w", c.config.LogPath, err)
+	}
+
+	c.logPath = logPath
+	c.fields = defaultLogFields
+	c.droppedPackets = make(map[droppedPacket]float64)
+
+	// Skip the existing log entries, only packets dropped after the start are counted.
+	fileInfo, err := os.Stat(c.logPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to stat firewall log %s: %w", c.logPath, err)
+		}
+
+		c.logger.Warn("firewall log does not exist, logging of dropped packets may be disabled",
+			slog.String("path", c.logPath),
+		)
+	} else {
+		c.offset = fileInfo.Size()
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.readLog(); err != nil {
+		return fmt.Errorf("failed to read firewall log %s: %w", c.logPath, err)
+	}
+
+	for packet, count := range c.droppedPackets {
+		ch <- prometheus.MustNewConstMetric(
+			c.droppedPacketsTotal,
+			prometheus.CounterValue,
+			count,
+			packet.direction,
+			packet.protocol,
+			packet.portClass,
+		)
+	}
+
+	return nil
+}
+
+// readLog reads the log entries appended since the last scrape.
+// The firewall renames the log to pfirewall.log.old once it reaches the size limit
+// and starts a new file; a file smaller than the last offset is read from the start.
+func (c *Collector) readLog() error {
+	file, err := os.Open(c.logPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			c.offset = 0
+
+			return nil
+		}
+
+		return err
+	}
+
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if fileInfo.Size() < c.offset {
+		c.offset = 0
+	}
+
+	if fileInfo.Size() == c.offset {
+		return nil
+	}
+
+	data := make([]byte, fileInfo.Size()-c.offset)
+
+	n, err := file.ReadAt(data, c.offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	// Only consume complete lines, a partially written line is read on the next scrape.
+	end := bytes.LastIndexByte(data[:n], '\n')
+	if end == -1 {
+		return nil
+	}
+
+	for line := range bytes.Lines(data[:end+1]) {
+		c.parseLine(line)
+	}
+
+	c.offset += int64(end + 1)
+
+	return nil
+}
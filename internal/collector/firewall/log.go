@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package firewall
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+const fieldsHeaderPrefix = "#Fields:"
+
+// logFields contains the column indices of the fields used by the collector.
+type logFields struct {
+	action   int
+	protocol int
+	dstPort  int
+	path     int
+}
+
+type droppedPacket struct {
+	direction string
+	protocol  string
+	portClass string
+}
+
+//nolint:gochecknoglobals
+var (
+	// defaultLogFields is the column layout of the log format version 1.5:
+	// date time action protocol src-ip dst-ip src-port dst-port size tcpflags tcpsyn tcpack tcpwin icmptype icmpcode info path [pid]
+	defaultLogFields = logFields{
+		action:   2,
+		protocol: 3,
+		dstPort:  7,
+		path:     16,
+	}
+
+	directions = map[string]string{
+		"RECEIVE": "inbound",
+		"SEND":    "outbound",
+		"FORWARD": "forward",
+	}
+
+	// protocolNumbers maps the protocol numbers logged for protocols without a name to their names.
+	protocolNumbers = map[string]string{
+		"1":  "icmp",
+		"6":  "tcp",
+		"17": "udp",
+		"58": "icmpv6",
+	}
+)
+
+// parseLine parses a single log entry or header line and counts dropped packets.
+func (c *Collector) parseLine(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+
+	if line[0] == '#' {
+		if fields, ok := parseFieldsHeader(string(line)); ok {
+			c.fields = fields
+		}
+
+		return
+	}
+
+	columns := strings.Fields(string(line))
+	if len(columns) <= max(c.fields.action, c.fields.protocol, c.fields.dstPort, c.fields.path) {
+		return
+	}
+
+	if columns[c.fields.action] != "DROP" {
+		return
+	}
+
+	direction, ok := directions[columns[c.fields.path]]
+	if !ok {
+		direction = "unknown"
+	}
+
+	protocol := strings.ToLower(columns[c.fields.protocol])
+	if name, ok := protocolNumbers[protocol]; ok {
+		protocol = name
+	} else if _, err := strconv.Atoi(protocol); err == nil {
+		protocol = "other"
+	}
+
+	c.droppedPackets[droppedPacket{
+		direction: direction,
+		protocol:  protocol,
+		portClass: portClass(columns[c.fields.dstPort]),
+	}]++
+}
+
+// parseFieldsHeader parses the "#Fields:" header line of the log.
+func parseFieldsHeader(line string) (logFields, bool) {
+	names, ok := strings.CutPrefix(line, fieldsHeaderPrefix)
+	if !ok {
+		return logFields{}, false
+	}
+
+	fields := logFields{action: -1, protocol: -1, dstPort: -1, path: -1}
+
+	for i, name := range strings.Fields(names) {
+		switch name {
+		case "action":
+			fields.action = i
+		case "protocol":
+			fields.protocol = i
+		case "dst-port":
+			fields.dstPort = i
+		case "path":
+			fields.path = i
+		}
+	}
+
+	if fields.action == -1 || fields.protocol == -1 || fields.dstPort == -1 || fields.path == -1 {
+		return logFields{}, false
+	}
+
+	return fields, true
+}
+
+// portClass classifies a destination port by the IANA port ranges.
+func portClass(port string) string {
+	number, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		// ICMP and other protocols without ports are logged with "-".
+		return "none"
+	}
+
+	switch {
+	case number < 1024:
+		return "system"
+	case number < 49152:
+		return "user"
+	default:
+		return "dynamic"
+	}
+}
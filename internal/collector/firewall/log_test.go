@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package firewall
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const logHeader = `#Version: 1.5
+#Software: Microsoft Windows Firewall
+#Time Format: Local
+#Fields: date time action protocol src-ip dst-ip src-port dst-port size tcpflags tcpsyn tcpack tcpwin icmptype icmpcode info path pid
+`
+
+func TestParseLine(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		lines    []string
+		expected map[droppedPacket]float64
+	}{
+		{
+			name: "dropped packets",
+			lines: []string{
+				"2026-10-14 10:23:45 DROP TCP 10.0.0.10 10.0.0.1 52345 445 52 S 1234567 0 8192 - - - RECEIVE 4",
+				"2026-10-14 10:23:46 DROP TCP 10.0.0.11 10.0.0.1 52346 445 52 S 1234568 0 8192 - - - RECEIVE 4",
+				"2026-10-14 10:23:47 DROP UDP 10.0.0.1 10.0.0.53 61000 5353 60 - - - - - - - SEND 1234",
+				"2026-10-14 10:23:48 DROP ICMP 10.0.0.12 10.0.0.1 - - 60 - - - - 8 0 - RECEIVE 0",
+				"2026-10-14 10:23:49 DROP TCP 10.0.0.13 10.0.0.1 52347 60000 52 S 1234569 0 8192 - - - FORWARD 0",
+			},
+			expected: map[droppedPacket]float64{
+				{direction: "inbound", protocol: "tcp", portClass: "system"}:  2,
+				{direction: "outbound", protocol: "udp", portClass: "user"}:   1,
+				{direction: "inbound", protocol: "icmp", portClass: "none"}:   1,
+				{direction: "forward", protocol: "tcp", portClass: "dynamic"}: 1,
+			},
+		},
+		{
+			name: "allowed packets and protocol numbers",
+			lines: []string{
+				"2026-10-14 10:23:45 ALLOW TCP 10.0.0.10 10.0.0.1 52345 443 52 S 1234567 0 8192 - - - RECEIVE 4",
+				"2026-10-14 10:23:46 DROP 58 fe80::1 fe80::2 - - 72 - - - - 135 0 - RECEIVE 0",
+				"2026-10-14 10:23:47 DROP 47 10.0.0.10 10.0.0.1 - - 72 - - - - - - - RECEIVE 0",
+				"2026-10-14 10:23:48 DROP TCP 10.0.0.10 10.0.0.1 52345 443 52 S 1234567 0 8192 - - - INFO-EVENTS-LOST 0",
+			},
+			expected: map[droppedPacket]float64{
+				{direction: "inbound", protocol: "icmpv6", portClass: "none"}: 1,
+				{direction: "inbound", protocol: "other", portClass: "none"}:  1,
+				{direction: "unknown", protocol: "tcp", portClass: "system"}:  1,
+			},
+		},
+		{
+			name: "fields header with other field order",
+			lines: []string{
+				"#Fields: date time path action dst-port protocol",
+				"2026-10-14 10:23:45 SEND DROP 3389 TCP",
+				"2026-10-14 10:23:46 RECEIVE DROP - ICMP",
+			},
+			expected: map[droppedPacket]float64{
+				{direction: "outbound", protocol: "tcp", portClass: "user"}: 1,
+				{direction: "inbound", protocol: "icmp", portClass: "none"}: 1,
+			},
+		},
+		{
+			name: "fields header without required fields",
+			lines: []string{
+				"#Fields: date time action protocol",
+				"2026-10-14 10:23:45 DROP TCP 10.0.0.10 10.0.0.1 52345 445 52 S 1234567 0 8192 - - - RECEIVE 4",
+			},
+			expected: map[droppedPacket]float64{
+				{direction: "inbound", protocol: "tcp", portClass: "system"}: 1,
+			},
+		},
+		{
+			name: "malformed lines",
+			lines: []string{
+				"",
+				"   ",
+				"2026-10-14 10:23:45 DROP TCP",
+				"# comment",
+			},
+			expected: map[droppedPacket]float64{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := &Collector{fields: defaultLogFields, droppedPackets: make(map[droppedPacket]float64)}
+
+			for _, line := range tc.lines {
+				c.parseLine([]byte(line + "\r\n"))
+			}
+
+			require.Equal(t, tc.expected, c.droppedPackets)
+		})
+	}
+}
+
+func TestPortClass(t *testing.T) {
+	t.Parallel()
+
+	for port, expected := range map[string]string{
+		"0":     "system",
+		"1023":  "system",
+		"1024":  "user",
+		"49151": "user",
+		"49152": "dynamic",
+		"65535": "dynamic",
+		"65536": "none",
+		"-":     "none",
+	} {
+		require.Equal(t, expected, portClass(port), port)
+	}
+}
+
+func TestReadLog(t *testing.T) {
+	t.Parallel()
+
+	logPath := filepath.Join(t.TempDir(), "pfirewall.log")
+	c := &Collector{logPath: logPath, fields: defaultLogFields, droppedPackets: make(map[droppedPacket]float64)}
+	inbound := droppedPacket{direction: "inbound", protocol: "tcp", portClass: "system"}
+
+	// A missing log, e.g. while logging is disabled, is not an error.
+	require.NoError(t, c.readLog())
+	require.Empty(t, c.droppedPackets)
+
+	drop := "2026-10-14 10:23:45 DROP TCP 10.0.0.10 10.0.0.1 52345 445 52 S 1234567 0 8192 - - - RECEIVE 4\r\n"
+
+	// The partially written last line is read on the next scrape.
+	require.NoError(t, os.WriteFile(logPath, []byte(logHeader+drop+drop[:20]), 0o600))
+	require.NoError(t, c.readLog())
+	require.Equal(t, map[droppedPacket]float64{inbound: 1}, c.droppedPackets)
+	require.Equal(t, int64(len(logHeader+drop)), c.offset)
+
+	require.NoError(t, os.WriteFile(logPath, []byte(logHeader+drop+drop+drop), 0o600))
+	require.NoError(t, c.readLog())
+	require.Equal(t, map[droppedPacket]float64{inbound: 3}, c.droppedPackets)
+
+	// The firewall starts a new log once the log reached its size limit, which is read from the start.
+	require.NoError(t, os.WriteFile(logPath, []byte(logHeader+drop), 0o600))
+	require.NoError(t, c.readLog())
+	require.Equal(t, map[droppedPacket]float64{inbound: 4}, c.droppedPackets)
+	require.Equal(t, int64(len(logHeader+drop)), c.offset)
+}
@@ -21,10 +21,12 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus-community/windows_exporter/internal/headers/cfgmgr32"
 	"github.com/prometheus-community/windows_exporter/internal/headers/gdi32"
+	"github.com/prometheus-community/windows_exporter/internal/headers/nvml"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
 	"github.com/prometheus-community/windows_exporter/internal/pdh"
 	"github.com/prometheus-community/windows_exporter/internal/types"
@@ -33,13 +35,18 @@ import (
 
 const Name = "gpu"
 
-type Config struct{}
+type Config struct {
+	EnableNVML bool `yaml:"nvml"`
+}
 
 //nolint:gochecknoglobals
-var ConfigDefaults = Config{}
+var ConfigDefaults = Config{
+	EnableNVML: true,
+}
 
 type Collector struct {
 	config Config
+	logger *slog.Logger
 
 	gpuDeviceCache map[string]gpuDevice
 
@@ -83,6 +90,20 @@ type Collector struct {
 	gpuProcessMemoryNonLocalUsage  *prometheus.Desc
 	gpuProcessMemorySharedUsage    *prometheus.Desc
 	gpuProcessMemoryTotalCommitted *prometheus.Desc
+
+	// NVIDIA Management Library, see gpu_nvml.go
+	nvmlInitialized bool
+	nvmlDevices     []nvmlDevice
+
+	nvmlInfo               *prometheus.Desc
+	nvmlUtilization        *prometheus.Desc
+	nvmlMemoryUtilization  *prometheus.Desc
+	nvmlEncoderUtilization *prometheus.Desc
+	nvmlDecoderUtilization *prometheus.Desc
+	nvmlMemoryTotal        *prometheus.Desc
+	nvmlMemoryUsed         *prometheus.Desc
+	nvmlTemperature        *prometheus.Desc
+	nvmlPowerUsage         *prometheus.Desc
 }
 
 type gpuDevice struct {
@@ -103,8 +124,17 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(_ *kingpin.Application) *Collector {
-	return &Collector{}
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	app.Flag(
+		"collector.gpu.nvml",
+		"If enabled, windows_gpu_nvml_* metrics are read from the NVIDIA Management Library, if the NVIDIA driver is installed.",
+	).Default(strconv.FormatBool(c.config.EnableNVML)).BoolVar(&c.config.EnableNVML)
+
+	return c
 }
 
 func (c *Collector) GetName() string {
@@ -118,12 +148,22 @@ func (c *Collector) Close() error {
 	c.gpuNonLocalAdapterMemoryPerfDataCollector.Close()
 	c.gpuProcessMemoryPerfDataCollector.Close()
 
+	if c.nvmlInitialized {
+		c.nvmlInitialized = false
+
+		if err := nvml.Shutdown(); err != nil {
+			return fmt.Errorf("failed to shutdown NVML: %w", err)
+		}
+	}
+
 	return nil
 }
 
 func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 	var err error
 
+	c.logger = logger.With(slog.String("collector", Name))
+
 	c.gpuInfo = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, Name, "info"),
 		"A metric with a constant '1' value labeled with gpu device information.",
@@ -294,6 +334,11 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		}
 	}
 
+	// Build is retried after errors, NVML is only initialized once.
+	if c.config.EnableNVML && !c.nvmlInitialized {
+		c.buildNVML()
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -322,6 +367,12 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		errs = append(errs, err)
 	}
 
+	if c.nvmlInitialized {
+		if err := c.collectNVML(ch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -0,0 +1,271 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package gpu
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/nvml"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type nvmlDevice struct {
+	device nvml.Device
+	uuid   string
+}
+
+// buildNVML initializes the NVIDIA Management Library. NVML is optional, hosts without
+// the NVIDIA driver or with a failing driver only expose the performance counter metrics.
+func (c *Collector) buildNVML() {
+	c.nvmlInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "nvml_info"),
+		"A metric with a constant '1' value labeled with information about the NVIDIA GPU.",
+		[]string{"uuid", "name", "index", "pci_bus_id", "bus_number", "driver_version"},
+		nil,
+	)
+	c.nvmlUtilization = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "nvml_utilization_ratio"),
+		"Fraction of the last sample period during which kernels were executing on the GPU.",
+		[]string{"uuid"},
+		nil,
+	)
+	c.nvmlMemoryUtilization = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "nvml_memory_utilization_ratio"),
+		"Fraction of the last sample period during which GPU memory was being read or written.",
+		[]string{"uuid"},
+		nil,
+	)
+	c.nvmlEncoderUtilization = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "nvml_encoder_utilization_ratio"),
+		"Utilization of the video encoder of the GPU.",
+		[]string{"uuid"},
+		nil,
+	)
+	c.nvmlDecoderUtilization = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "nvml_decoder_utilization_ratio"),
+		"Utilization of the video decoder of the GPU.",
+		[]string{"uuid"},
+		nil,
+	)
+	c.nvmlMemoryTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "nvml_memory_total_bytes"),
+		"Total memory of the GPU in bytes.",
+		[]string{"uuid"},
+		nil,
+	)
+	c.nvmlMemoryUsed = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "nvml_memory_used_bytes"),
+		"Allocated memory of the GPU in bytes.",
+		[]string{"uuid"},
+		nil,
+	)
+	c.nvmlTemperature = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "nvml_temperature_celsius"),
+		"Temperature of the GPU die in degrees Celsius.",
+		[]string{"uuid"},
+		nil,
+	)
+	c.nvmlPowerUsage = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "nvml_power_usage_watts"),
+		"Power draw of the GPU in watts.",
+		[]string{"uuid"},
+		nil,
+	)
+
+	if err := nvml.Load(); err != nil {
+		c.logger.Debug("NVML is not available, skipping NVIDIA GPU metrics",
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	if err := nvml.Init(); err != nil {
+		c.logger.Warn("failed to initialize NVML, skipping NVIDIA GPU metrics",
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	c.nvmlInitialized = true
+	c.nvmlDevices = make([]nvmlDevice, 0)
+
+	count, err := nvml.DeviceGetCount()
+	if err != nil {
+		c.logger.Warn("failed to get NVIDIA GPU count",
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	for index := range count {
+		device, err := nvml.DeviceGetHandleByIndex(index)
+		if err != nil {
+			c.logger.Warn("failed to get NVIDIA GPU",
+				slog.Uint64("index", uint64(index)),
+				slog.Any("err", err),
+			)
+
+			continue
+		}
+
+		uuid, err := device.UUID()
+		if err != nil {
+			c.logger.Warn("failed to get UUID of NVIDIA GPU",
+				slog.Uint64("index", uint64(index)),
+				slog.Any("err", err),
+			)
+
+			continue
+		}
+
+		c.nvmlDevices = append(c.nvmlDevices, nvmlDevice{device: device, uuid: uuid})
+	}
+}
+
+func (c *Collector) collectNVML(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	// The driver version is read on each collection, since the driver may be updated without a restart.
+	driverVersion, err := nvml.SystemGetDriverVersion()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to get NVIDIA driver version: %w", err))
+	}
+
+	for index, device := range c.nvmlDevices {
+		if err := c.collectNVMLDevice(ch, index, device, driverVersion); err != nil {
+			errs = append(errs, fmt.Errorf("failed to collect NVIDIA GPU %s: %w", device.uuid, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectNVMLDevice(ch chan<- prometheus.Metric, index int, device nvmlDevice, driverVersion string) error {
+	name, err := device.device.Name()
+	if err != nil {
+		return err
+	}
+
+	pciInfo, err := device.device.PciInfo()
+	if err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.nvmlInfo,
+		prometheus.GaugeValue,
+		1.0,
+		device.uuid,
+		name,
+		strconv.Itoa(index),
+		pciInfo.BusID(),
+		strconv.FormatUint(uint64(pciInfo.Bus), 10),
+		driverVersion,
+	)
+
+	errs := make([]error, 0)
+
+	if utilization, err := device.device.UtilizationRates(); err == nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.nvmlUtilization,
+			prometheus.GaugeValue,
+			float64(utilization.GPU)/100,
+			device.uuid,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.nvmlMemoryUtilization,
+			prometheus.GaugeValue,
+			float64(utilization.Memory)/100,
+			device.uuid,
+		)
+	} else if !errors.Is(err, nvml.ErrNotSupported) {
+		errs = append(errs, err)
+	}
+
+	if utilization, err := device.device.EncoderUtilization(); err == nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.nvmlEncoderUtilization,
+			prometheus.GaugeValue,
+			float64(utilization)/100,
+			device.uuid,
+		)
+	} else if !errors.Is(err, nvml.ErrNotSupported) {
+		errs = append(errs, err)
+	}
+
+	if utilization, err := device.device.DecoderUtilization(); err == nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.nvmlDecoderUtilization,
+			prometheus.GaugeValue,
+			float64(utilization)/100,
+			device.uuid,
+		)
+	} else if !errors.Is(err, nvml.ErrNotSupported) {
+		errs = append(errs, err)
+	}
+
+	if memory, err := device.device.MemoryInfo(); err == nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.nvmlMemoryTotal,
+			prometheus.GaugeValue,
+			float64(memory.Total),
+			device.uuid,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.nvmlMemoryUsed,
+			prometheus.GaugeValue,
+			float64(memory.Used),
+			device.uuid,
+		)
+	} else if !errors.Is(err, nvml.ErrNotSupported) {
+		errs = append(errs, err)
+	}
+
+	if temperature, err := device.device.Temperature(); err == nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.nvmlTemperature,
+			prometheus.GaugeValue,
+			float64(temperature),
+			device.uuid,
+		)
+	} else if !errors.Is(err, nvml.ErrNotSupported) {
+		errs = append(errs, err)
+	}
+
+	if power, err := device.device.PowerUsage(); err == nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.nvmlPowerUsage,
+			prometheus.GaugeValue,
+			float64(power)/1000,
+			device.uuid,
+		)
+	} else if !errors.Is(err, nvml.ErrNotSupported) {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,412 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package hello_for_business
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "hello_for_business"
+
+	deviceRegistrationChannel = "Microsoft-Windows-User Device Registration/Admin"
+
+	// Events logged by the device registration service for each provisioning attempt and NGC key registration.
+	eventIDKeyRegistered           = 300
+	eventIDKeyRegistrationFailed   = 301
+	eventIDProvisioningLaunched    = 358
+	eventIDProvisioningNotLaunched = 360
+
+	// ngcCredentialProviderKey contains a subkey per user SID that has a Windows Hello for Business container.
+	ngcCredentialProviderKey = `SOFTWARE\Microsoft\Windows\CurrentVersion\Authentication\Credential Providers\{D6886603-9D2F-4EB2-B667-1971041FA96B}`
+
+	groupPolicyKey = `SOFTWARE\Policies\Microsoft\PassportForWork`
+	// mdmPolicyKey contains a subkey per Entra ID tenant with the policies configured by MDM.
+	mdmPolicyKey = `SOFTWARE\Microsoft\Policies\PassportForWork`
+
+	trustTypeKey           = "key"
+	trustTypeCertificate   = "certificate"
+	trustTypeCloudKerberos = "cloud_kerberos"
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+// renderValuePaths are the event properties rendered for each device registration event.
+// The order must match the value* indices.
+//
+//nolint:gochecknoglobals
+var renderValuePaths = []string{
+	"Event/System/EventRecordID",
+	"Event/System/EventID",
+}
+
+const (
+	valueEventRecordID = iota
+	valueEventID
+)
+
+//nolint:gochecknoglobals
+var provisioningEvents = map[uint64]string{
+	eventIDKeyRegistered:           "key_registered",
+	eventIDKeyRegistrationFailed:   "key_registration_failed",
+	eventIDProvisioningLaunched:    "provisioning_launched",
+	eventIDProvisioningNotLaunched: "provisioning_not_launched",
+}
+
+// A Collector is a Prometheus Collector for Windows Hello for Business.
+// The provisioning state of each user and the policy are read from the registry, provisioning attempts from the
+// User Device Registration event log. Counters only include events logged since windows_exporter started.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	eventsEnabled bool
+	renderContext wevtapi.EVT_HANDLE
+
+	// mu protects the event counters and lastRecordID against concurrent scrapes.
+	mu                 sync.Mutex
+	lastRecordID       uint64
+	provisioningEvents map[string]float64
+
+	policyEnabled          *prometheus.Desc
+	trustType              *prometheus.Desc
+	userProvisioned        *prometheus.Desc
+	provisioningEventTotal *prometheus.Desc
+}
+
+type policy struct {
+	source    string
+	enabled   bool
+	trustType string
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if c.renderContext != 0 {
+		return wevtapi.EvtClose(c.renderContext)
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.policyEnabled = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "policy_enabled"),
+		"1 if Windows Hello for Business is enabled by policy, 0 if it is disabled. Only reported if the policy is configured",
+		[]string{"source"},
+		nil,
+	)
+	c.trustType = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "trust_type_info"),
+		"Trust type used for on-premises authentication (key, certificate, cloud_kerberos). Only reported if the policy is configured",
+		[]string{"source", "trust_type"},
+		nil,
+	)
+	c.userProvisioned = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "user_provisioned"),
+		"1 if the user has provisioned Windows Hello for Business credentials, e.g. a PIN, 0 otherwise",
+		[]string{"user"},
+		nil,
+	)
+	c.provisioningEventTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "provisioning_events_total"),
+		"Number of Windows Hello for Business provisioning and key registration events",
+		[]string{"event"},
+		nil,
+	)
+
+	c.provisioningEvents = make(map[string]float64, len(provisioningEvents))
+	for _, event := range provisioningEvents {
+		c.provisioningEvents[event] = 0
+	}
+
+	lastRecordID, err := wevtapi.LatestEventRecordID(deviceRegistrationChannel)
+	if err != nil {
+		if errors.Is(err, wevtapi.ERROR_EVT_CHANNEL_NOT_FOUND) {
+			c.logger.Debug("User Device Registration event log not found, skipping provisioning event metrics")
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to read latest event of %s: %w", deviceRegistrationChannel, err)
+	}
+
+	c.lastRecordID = lastRecordID
+
+	c.renderContext, err = wevtapi.EvtCreateRenderContext(renderValuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	c.eventsEnabled = true
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if err := c.collectPolicy(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting policy metrics: %w", err))
+	}
+
+	if err := c.collectUsers(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting user metrics: %w", err))
+	}
+
+	if c.eventsEnabled {
+		if err := c.collectProvisioningEvents(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting provisioning event metrics: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectPolicy(ch chan<- prometheus.Metric) error {
+	p, err := readPolicy()
+	if err != nil {
+		return err
+	}
+
+	if p == nil {
+		return nil
+	}
+
+	enabled := 0.0
+	if p.enabled {
+		enabled = 1.0
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.policyEnabled,
+		prometheus.GaugeValue,
+		enabled,
+		p.source,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.trustType,
+		prometheus.GaugeValue,
+		1.0,
+		p.source,
+		p.trustType,
+	)
+
+	return nil
+}
+
+// readPolicy reads the Windows Hello for Business policy. Group policy takes precedence over MDM.
+// It returns nil if no policy is configured.
+func readPolicy() (*policy, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, groupPolicyKey, registry.QUERY_VALUE)
+	if err == nil {
+		defer key.Close()
+
+		return readPolicyValues(key, "group_policy", "Enabled"), nil
+	}
+
+	if !errors.Is(err, registry.ErrNotExist) {
+		return nil, fmt.Errorf("failed to open registry key %s: %w", groupPolicyKey, err)
+	}
+
+	tenantsKey, err := registry.OpenKey(registry.LOCAL_MACHINE, mdmPolicyKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to open registry key %s: %w", mdmPolicyKey, err)
+	}
+
+	defer tenantsKey.Close()
+
+	tenants, err := tenantsKey.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry key %s: %w", mdmPolicyKey, err)
+	}
+
+	for _, tenant := range tenants {
+		key, err := registry.OpenKey(tenantsKey, tenant+`\Policies`, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+
+		defer key.Close()
+
+		return readPolicyValues(key, "mdm", "UsePassportForWork"), nil
+	}
+
+	return nil, nil
+}
+
+func readPolicyValues(key registry.Key, source string, enabledValueName string) *policy {
+	p := &policy{
+		source:    source,
+		trustType: trustTypeKey,
+	}
+
+	if enabled, _, err := key.GetIntegerValue(enabledValueName); err == nil {
+		p.enabled = enabled != 0
+	}
+
+	if value, _, err := key.GetIntegerValue("UseCertificateForOnPremAuth"); err == nil && value != 0 {
+		p.trustType = trustTypeCertificate
+	}
+
+	if value, _, err := key.GetIntegerValue("UseCloudTrustForOnPremAuth"); err == nil && value != 0 {
+		p.trustType = trustTypeCloudKerberos
+	}
+
+	return p
+}
+
+func (c *Collector) collectUsers(ch chan<- prometheus.Metric) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, ngcCredentialProviderKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to open registry key %s: %w", ngcCredentialProviderKey, err)
+	}
+
+	defer key.Close()
+
+	sids, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return fmt.Errorf("failed to read registry key %s: %w", ngcCredentialProviderKey, err)
+	}
+
+	for _, sid := range sids {
+		userKey, err := registry.OpenKey(key, sid, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+
+		// LogonCredsAvailable is 1 once the user has completed provisioning.
+		logonCredsAvailable, _, err := userKey.GetIntegerValue("LogonCredsAvailable")
+
+		userKey.Close()
+
+		provisioned := 0.0
+		if err == nil && logonCredsAvailable != 0 {
+			provisioned = 1.0
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.userProvisioned,
+			prometheus.GaugeValue,
+			provisioned,
+			lookupUser(sid),
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectProvisioningEvents(ch chan<- prometheus.Metric) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	query := fmt.Sprintf("*[System[(EventID=%d or EventID=%d or EventID=%d or EventID=%d) and EventRecordID > %d]]",
+		eventIDKeyRegistered, eventIDKeyRegistrationFailed, eventIDProvisioningLaunched, eventIDProvisioningNotLaunched, c.lastRecordID,
+	)
+
+	if err := wevtapi.QueryValues(deviceRegistrationChannel, query, c.renderContext, c.handleEvent); err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", deviceRegistrationChannel, err)
+	}
+
+	for event, count := range c.provisioningEvents {
+		ch <- prometheus.MustNewConstMetric(
+			c.provisioningEventTotal,
+			prometheus.CounterValue,
+			count,
+			event,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) handleEvent(values []any) {
+	if len(values) != len(renderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[valueEventRecordID].(uint64)
+	c.lastRecordID = max(c.lastRecordID, recordID)
+
+	eventID, _ := values[valueEventID].(uint64)
+
+	if event, ok := provisioningEvents[eventID]; ok {
+		c.provisioningEvents[event]++
+	}
+}
+
+// lookupUser returns the account name of the SID as DOMAIN\user, or the SID if the account can't be resolved.
+func lookupUser(sid string) string {
+	userSID, err := windows.StringToSid(sid)
+	if err != nil {
+		return sid
+	}
+
+	account, domain, _, err := userSID.LookupAccount("")
+	if err != nil {
+		return sid
+	}
+
+	return domain + `\` + account
+}
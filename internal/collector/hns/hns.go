@@ -0,0 +1,425 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package hns
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/hcn"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	Name = "hns"
+
+	adminChannel = "Microsoft-Windows-Host-Network-Service-Admin"
+
+	operationNetworks    = "networks"
+	operationEndpoints   = "endpoints"
+	operationPolicyLists = "policylists"
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+// renderValuePaths are the event properties rendered for each event.
+// The order must match the value* indices.
+//
+//nolint:gochecknoglobals
+var renderValuePaths = []string{
+	"Event/System/EventRecordID",
+	"Event/System/EventID",
+}
+
+const (
+	valueEventRecordID = iota
+	valueEventID
+)
+
+// A Collector is a Prometheus Collector for the Host Networking Service (HNS) of container hosts.
+// The networks, endpoints and policy lists are read with the HNS API, whose failures and latency are exported too,
+// since a corrupted HNS state usually shows up as failing or slow HNS calls first.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	renderContext wevtapi.EVT_HANDLE
+
+	// mu protects the counters, channelExists and lastRecordID against concurrent scrapes.
+	mu            sync.Mutex
+	apiErrors     map[string]float64
+	channelExists bool
+	lastRecordID  uint64
+	errorEvents   map[uint64]float64
+
+	networks             *prometheus.Desc
+	endpoints            *prometheus.Desc
+	endpointsUnattached  *prometheus.Desc
+	endpointPolicies     *prometheus.Desc
+	policyLists          *prometheus.Desc
+	policyListPolicies   *prometheus.Desc
+	apiErrorsTotal       *prometheus.Desc
+	apiDurationSeconds   *prometheus.Desc
+	adminErrorEventTotal *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if c.renderContext != 0 {
+		return wevtapi.EvtClose(c.renderContext)
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.networks = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "networks"),
+		"Number of HNS networks by type, e.g. NAT, L2Bridge or Overlay",
+		[]string{"type"},
+		nil,
+	)
+	c.endpoints = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "endpoints"),
+		"Number of HNS endpoints by network. Remote endpoints are the endpoints of other nodes of an overlay network",
+		[]string{"network", "remote"},
+		nil,
+	)
+	c.endpointsUnattached = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "endpoints_unattached"),
+		"Number of local HNS endpoints by network that aren't attached to a container, e.g. leaked endpoints of removed pods",
+		[]string{"network"},
+		nil,
+	)
+	c.endpointPolicies = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "endpoint_policies"),
+		"Number of policies of the HNS endpoints by type, e.g. OutBoundNAT or ACL",
+		[]string{"type"},
+		nil,
+	)
+	c.policyLists = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "policy_lists"),
+		"Number of HNS policy lists, e.g. the load balancers of the Kubernetes services created by kube-proxy",
+		nil,
+		nil,
+	)
+	c.policyListPolicies = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "policy_list_policies"),
+		"Number of policies of the HNS policy lists by type, e.g. ELB",
+		[]string{"type"},
+		nil,
+	)
+	c.apiErrorsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "api_errors_total"),
+		"Number of failed HNS API calls of windows_exporter by operation (networks, endpoints, policylists)",
+		[]string{"operation"},
+		nil,
+	)
+	c.apiDurationSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "api_duration_seconds"),
+		"Duration of the last HNS API call of windows_exporter by operation (networks, endpoints, policylists)",
+		[]string{"operation"},
+		nil,
+	)
+	c.adminErrorEventTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "admin_error_events_total"),
+		"Number of critical and error events in the Admin event log of HNS by event ID",
+		[]string{"event_id"},
+		nil,
+	)
+
+	c.apiErrors = map[string]float64{
+		operationNetworks:    0,
+		operationEndpoints:   0,
+		operationPolicyLists: 0,
+	}
+	c.errorEvents = make(map[uint64]float64)
+
+	if err := c.openChannel(); err != nil {
+		return err
+	}
+
+	var err error
+
+	c.renderContext, err = wevtapi.EvtCreateRenderContext(renderValuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	return nil
+}
+
+// openChannel reads the latest record ID of the Admin channel of HNS, which only exists if the Containers feature is installed.
+func (c *Collector) openChannel() error {
+	lastRecordID, err := wevtapi.LatestEventRecordID(adminChannel)
+	if err != nil {
+		if errors.Is(err, wevtapi.ERROR_EVT_CHANNEL_NOT_FOUND) {
+			c.logger.Debug("HNS event log not found, skipping the HNS error events")
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to read latest event of %s: %w", adminChannel, err)
+	}
+
+	c.channelExists = true
+	c.lastRecordID = lastRecordID
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	errs := make([]error, 0)
+
+	if err := c.collectNetworks(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting HNS networks: %w", err))
+	}
+
+	if err := c.collectEndpoints(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting HNS endpoints: %w", err))
+	}
+
+	if err := c.collectPolicyLists(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting HNS policy lists: %w", err))
+	}
+
+	for operation, count := range c.apiErrors {
+		ch <- prometheus.MustNewConstMetric(
+			c.apiErrorsTotal,
+			prometheus.CounterValue,
+			count,
+			operation,
+		)
+	}
+
+	if err := c.collectErrorEvents(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting HNS events: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectNetworks(ch chan<- prometheus.Metric) error {
+	networks, err := call(c, ch, operationNetworks, hcn.ListNetworks)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]float64)
+	for _, network := range networks {
+		counts[network.Type]++
+	}
+
+	for networkType, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.networks,
+			prometheus.GaugeValue,
+			count,
+			networkType,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectEndpoints(ch chan<- prometheus.Metric) error {
+	endpoints, err := call(c, ch, operationEndpoints, hcn.ListEndpoints)
+	if err != nil {
+		return err
+	}
+
+	type endpointKey struct {
+		network string
+		remote  bool
+	}
+
+	counts := make(map[endpointKey]float64)
+	unattached := make(map[string]float64)
+	policies := make(map[string]float64)
+
+	for _, endpoint := range endpoints {
+		counts[endpointKey{network: endpoint.VirtualNetworkName, remote: endpoint.IsRemoteEndpoint}]++
+
+		// Export 0 for each network with local endpoints, so alerts can tell a clean network from a missing one.
+		if !endpoint.IsRemoteEndpoint {
+			unattached[endpoint.VirtualNetworkName] += utils.BoolToFloat(len(endpoint.SharedContainers) == 0)
+		}
+
+		for _, policy := range endpoint.Policies {
+			policies[policy.Type]++
+		}
+	}
+
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.endpoints,
+			prometheus.GaugeValue,
+			count,
+			key.network, strconv.FormatBool(key.remote),
+		)
+	}
+
+	for network, count := range unattached {
+		ch <- prometheus.MustNewConstMetric(
+			c.endpointsUnattached,
+			prometheus.GaugeValue,
+			count,
+			network,
+		)
+	}
+
+	for policyType, count := range policies {
+		ch <- prometheus.MustNewConstMetric(
+			c.endpointPolicies,
+			prometheus.GaugeValue,
+			count,
+			policyType,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectPolicyLists(ch chan<- prometheus.Metric) error {
+	policyLists, err := call(c, ch, operationPolicyLists, hcn.ListPolicyLists)
+	if err != nil {
+		return err
+	}
+
+	policies := make(map[string]float64)
+
+	for _, policyList := range policyLists {
+		for _, policy := range policyList.Policies {
+			policies[policy.Type]++
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.policyLists,
+		prometheus.GaugeValue,
+		float64(len(policyLists)),
+	)
+
+	for policyType, count := range policies {
+		ch <- prometheus.MustNewConstMetric(
+			c.policyListPolicies,
+			prometheus.GaugeValue,
+			count,
+			policyType,
+		)
+	}
+
+	return nil
+}
+
+// call runs the HNS API call, exports its duration and counts its failures. Must be called with mu held.
+func call[T any](c *Collector, ch chan<- prometheus.Metric, operation string, fn func() ([]T, error)) ([]T, error) {
+	start := time.Now()
+	result, err := fn()
+
+	ch <- prometheus.MustNewConstMetric(
+		c.apiDurationSeconds,
+		prometheus.GaugeValue,
+		time.Since(start).Seconds(),
+		operation,
+	)
+
+	if err != nil {
+		c.apiErrors[operation]++
+
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Collector) collectErrorEvents(ch chan<- prometheus.Metric) error {
+	if !c.channelExists {
+		if err := c.openChannel(); err != nil || !c.channelExists {
+			return err
+		}
+	}
+
+	// Level 1 is critical, 2 is error.
+	query := fmt.Sprintf("*[System[(Level=1 or Level=2) and EventRecordID > %d]]", c.lastRecordID)
+
+	if err := wevtapi.QueryValues(adminChannel, query, c.renderContext, c.handleEvent); err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", adminChannel, err)
+	}
+
+	for eventID, count := range c.errorEvents {
+		ch <- prometheus.MustNewConstMetric(
+			c.adminErrorEventTotal,
+			prometheus.CounterValue,
+			count,
+			strconv.FormatUint(eventID, 10),
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) handleEvent(values []any) {
+	if len(values) != len(renderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[valueEventRecordID].(uint64)
+	c.lastRecordID = max(c.lastRecordID, recordID)
+
+	eventID, _ := values[valueEventID].(uint64)
+	c.errorEvents[eventID]++
+}
@@ -0,0 +1,304 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package host
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+const Name = "host"
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+// A Collector is a Prometheus Collector for the identity and the hardware of the host,
+// e.g. for inventory queries. The hardware and the domain membership are read once, since they
+// don't change without a reboot, while the OU of the computer account is read on each collection.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	domain       string
+	workgroup    string
+	chassisType  string
+	manufacturer string
+	model        string
+	serialNumber string
+
+	hostInfo *prometheus.Desc
+}
+
+type miComputerSystem struct {
+	Domain       string `mi:"Domain"`
+	PartOfDomain bool   `mi:"PartOfDomain"`
+	Workgroup    string `mi:"Workgroup"`
+	Manufacturer string `mi:"Manufacturer"`
+	Model        string `mi:"Model"`
+}
+
+type miSystemEnclosure struct {
+	ChassisTypes []uint16 `mi:"ChassisTypes"`
+}
+
+type miBIOS struct {
+	SerialNumber string `mi:"SerialNumber"`
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.hostInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "info"),
+		"Labelled identity and hardware information of the host as provided by Win32_ComputerSystem, Win32_SystemEnclosure, Win32_BIOS and Group Policy",
+		[]string{
+			"domain",
+			"workgroup",
+			"ou",
+			"chassis_type",
+			"manufacturer",
+			"model",
+			"serial_number",
+			"image_build",
+		},
+		nil,
+	)
+
+	var computerSystems []miComputerSystem
+	if err := query(miSession, &computerSystems, "SELECT Domain, PartOfDomain, Workgroup, Manufacturer, Model FROM Win32_ComputerSystem"); err != nil {
+		return err
+	}
+
+	if len(computerSystems) > 0 {
+		if computerSystems[0].PartOfDomain {
+			c.domain = computerSystems[0].Domain
+		} else {
+			c.workgroup = computerSystems[0].Workgroup
+		}
+
+		c.manufacturer = strings.TrimSpace(computerSystems[0].Manufacturer)
+		c.model = strings.TrimSpace(computerSystems[0].Model)
+	}
+
+	var enclosures []miSystemEnclosure
+	if err := query(miSession, &enclosures, "SELECT ChassisTypes FROM Win32_SystemEnclosure"); err != nil {
+		return err
+	}
+
+	if len(enclosures) > 0 && len(enclosures[0].ChassisTypes) > 0 {
+		c.chassisType = chassisTypeName(enclosures[0].ChassisTypes[0])
+	}
+
+	var bios []miBIOS
+	if err := query(miSession, &bios, "SELECT SerialNumber FROM Win32_BIOS"); err != nil {
+		return err
+	}
+
+	if len(bios) > 0 {
+		c.serialNumber = strings.TrimSpace(bios[0].SerialNumber)
+	}
+
+	return nil
+}
+
+func query(miSession *mi.Session, dst any, queryString string) error {
+	miQuery, err := mi.NewQuery(queryString)
+	if err != nil {
+		return fmt.Errorf("failed to create WMI query: %w", err)
+	}
+
+	if err := miSession.Query(dst, mi.NamespaceRootCIMv2, miQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	imageBuild, err := getImageBuild()
+	if err != nil {
+		return fmt.Errorf("failed to get image build: %w", err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.hostInfo,
+		prometheus.GaugeValue,
+		1.0,
+		c.domain,
+		c.workgroup,
+		c.getOU(),
+		c.chassisType,
+		c.manufacturer,
+		c.model,
+		c.serialNumber,
+		imageBuild,
+	)
+
+	return nil
+}
+
+// getOU returns the OU of the computer account, as recorded by the last Group Policy refresh.
+// Hosts that are not joined to a domain or haven't applied a Group Policy yet have no OU.
+func (c *Collector) getOU() string {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\CurrentVersion\Group Policy\State\Machine`, registry.QUERY_VALUE)
+	if err != nil {
+		return ""
+	}
+
+	defer func(key registry.Key) {
+		_ = key.Close()
+	}(key)
+
+	distinguishedName, _, err := key.GetStringValue("Distinguished-Name")
+	if err != nil {
+		c.logger.Debug("failed to read distinguished name of the computer account",
+			slog.Any("err", err),
+		)
+
+		return ""
+	}
+
+	return parentDN(distinguishedName)
+}
+
+// parentDN removes the first RDN of a distinguished name, e.g. the CN of the computer account in
+// CN=HOST01,OU=Servers,DC=example,DC=com. Commas in the RDN are escaped with a backslash.
+func parentDN(distinguishedName string) string {
+	for i := 0; i < len(distinguishedName); i++ {
+		switch distinguishedName[i] {
+		case '\\':
+			i++
+		case ',':
+			return distinguishedName[i+1:]
+		}
+	}
+
+	return ""
+}
+
+// getImageBuild returns the build lab string of the installed image, e.g. 20348.1.amd64fre.fe_release.210507-1500.
+func getImageBuild() (string, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows NT\CurrentVersion`, registry.QUERY_VALUE)
+	if err != nil {
+		return "", fmt.Errorf("failed to open registry key: %w", err)
+	}
+
+	defer func(key registry.Key) {
+		_ = key.Close()
+	}(key)
+
+	buildLab, _, err := key.GetStringValue("BuildLabEx")
+	if errors.Is(err, registry.ErrNotExist) {
+		buildLab, _, err = key.GetStringValue("BuildLab")
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(buildLab), nil
+}
+
+// chassisTypeName returns the name of an SMBIOS chassis type of Win32_SystemEnclosure.
+func chassisTypeName(chassisType uint16) string {
+	if name, ok := chassisTypes[chassisType]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+//nolint:gochecknoglobals
+var chassisTypes = map[uint16]string{
+	1:  "other",
+	2:  "unknown",
+	3:  "desktop",
+	4:  "low_profile_desktop",
+	5:  "pizza_box",
+	6:  "mini_tower",
+	7:  "tower",
+	8:  "portable",
+	9:  "laptop",
+	10: "notebook",
+	11: "hand_held",
+	12: "docking_station",
+	13: "all_in_one",
+	14: "sub_notebook",
+	15: "space_saving",
+	16: "lunch_box",
+	17: "main_system_chassis",
+	18: "expansion_chassis",
+	19: "sub_chassis",
+	20: "bus_expansion_chassis",
+	21: "peripheral_chassis",
+	22: "storage_chassis",
+	23: "rack_mount_chassis",
+	24: "sealed_case_pc",
+	25: "multi_system_chassis",
+	26: "compact_pci",
+	27: "advanced_tca",
+	28: "blade",
+	29: "blade_enclosure",
+	30: "tablet",
+	31: "convertible",
+	32: "detachable",
+	33: "iot_gateway",
+	34: "embedded_pc",
+	35: "mini_pc",
+	36: "stick_pc",
+}
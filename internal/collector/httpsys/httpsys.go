@@ -0,0 +1,389 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httpsys
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	Name = "httpsys"
+
+	subCollectorURICache      = "uri_cache"
+	subCollectorRequestQueues = "request_queues"
+)
+
+type Config struct {
+	QueueExclude      *regexp.Regexp `yaml:"queue-exclude"`
+	QueueInclude      *regexp.Regexp `yaml:"queue-include"`
+	CollectorsEnabled []string       `yaml:"enabled"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	QueueExclude: types.RegExpEmpty,
+	QueueInclude: types.RegExpAny,
+	CollectorsEnabled: []string{
+		subCollectorURICache,
+		subCollectorRequestQueues,
+	},
+}
+
+// A Collector is a Prometheus Collector for the HTTP Server API (HTTP.sys) kernel driver.
+// The metrics cover all applications using HTTP.sys, including IIS and self-hosted applications.
+type Collector struct {
+	config Config
+
+	uriCachePerfDataCollector *pdh.Collector
+	uriCachePerfDataObject    []perfDataCounterValuesURICache
+
+	requestQueuesPerfDataCollector *pdh.Collector
+	requestQueuesPerfDataObject    []perfDataCounterValuesRequestQueues
+
+	uriCacheEntries      *prometheus.Desc
+	uriCacheEntriesAdded *prometheus.Desc
+	uriCacheHitsTotal    *prometheus.Desc
+	uriCacheMissesTotal  *prometheus.Desc
+	uriCacheFlushesTotal *prometheus.Desc
+	uriCacheFlushedURIs  *prometheus.Desc
+
+	requestQueueSize           *prometheus.Desc
+	requestQueueMaxItemAge     *prometheus.Desc
+	requestQueueArrivalsTotal  *prometheus.Desc
+	requestQueueRejectedTotal  *prometheus.Desc
+	requestQueueCacheHitsTotal *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.QueueExclude == nil {
+		config.QueueExclude = ConfigDefaults.QueueExclude
+	}
+
+	if config.QueueInclude == nil {
+		config.QueueInclude = ConfigDefaults.QueueInclude
+	}
+
+	if config.CollectorsEnabled == nil {
+		config.CollectorsEnabled = ConfigDefaults.CollectorsEnabled
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+	c.config.CollectorsEnabled = make([]string, 0)
+
+	var queueExclude, queueInclude string
+
+	var collectorsEnabled string
+
+	app.Flag(
+		"collector.httpsys.queue-exclude",
+		"Regexp of request queues to exclude. Queue name must both match include and not match exclude to be included.",
+	).Default("").StringVar(&queueExclude)
+
+	app.Flag(
+		"collector.httpsys.queue-include",
+		"Regexp of request queues to include. Queue name must both match include and not match exclude to be included.",
+	).Default(".+").StringVar(&queueInclude)
+
+	app.Flag(
+		"collector.httpsys.enabled",
+		"Comma-separated list of collectors to use. Defaults to all, if not specified.",
+	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+
+		var err error
+
+		c.config.QueueExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", queueExclude))
+		if err != nil {
+			return fmt.Errorf("collector.httpsys.queue-exclude: %w", err)
+		}
+
+		c.config.QueueInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", queueInclude))
+		if err != nil {
+			return fmt.Errorf("collector.httpsys.queue-include: %w", err)
+		}
+
+		return nil
+	})
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorURICache) {
+		c.uriCachePerfDataCollector.Close()
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorRequestQueues) {
+		c.requestQueuesPerfDataCollector.Close()
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	for _, collector := range c.config.CollectorsEnabled {
+		if !slices.Contains([]string{subCollectorURICache, subCollectorRequestQueues}, collector) {
+			return fmt.Errorf("unknown sub collector: %s. Possible values: %s", collector,
+				strings.Join([]string{subCollectorURICache, subCollectorRequestQueues}, ", "),
+			)
+		}
+	}
+
+	logger = logger.With(slog.String("collector", Name))
+
+	var err error
+
+	errs := make([]error, 0)
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorURICache) {
+		c.uriCacheEntries = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "uri_cache_entries"),
+			"Number of URIs currently cached by the kernel",
+			nil,
+			nil,
+		)
+		c.uriCacheEntriesAdded = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "uri_cache_entries_added_total"),
+			"Number of URIs added to the kernel cache",
+			nil,
+			nil,
+		)
+		c.uriCacheHitsTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "uri_cache_hits_total"),
+			"Number of successful lookups in the kernel URI cache",
+			nil,
+			nil,
+		)
+		c.uriCacheMissesTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "uri_cache_misses_total"),
+			"Number of unsuccessful lookups in the kernel URI cache",
+			nil,
+			nil,
+		)
+		c.uriCacheFlushesTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "uri_cache_flushes_total"),
+			"Number of kernel URI cache flushes",
+			nil,
+			nil,
+		)
+		c.uriCacheFlushedURIs = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "uri_cache_flushed_uris_total"),
+			"Number of URIs removed from the kernel cache",
+			nil,
+			nil,
+		)
+
+		c.uriCachePerfDataCollector, err = pdh.NewCollector[perfDataCounterValuesURICache](logger, pdh.CounterTypeRaw, "HTTP Service", nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create HTTP Service collector: %w", err))
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorRequestQueues) {
+		c.requestQueueSize = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "request_queue_size"),
+			"Number of requests in the request queue",
+			[]string{"queue"},
+			nil,
+		)
+		c.requestQueueMaxItemAge = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "request_queue_max_item_age_seconds"),
+			"Age of the oldest request in the request queue. The value might be bogus if the queue is empty",
+			[]string{"queue"},
+			nil,
+		)
+		c.requestQueueArrivalsTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "request_queue_arrivals_total"),
+			"Number of requests that arrived in the request queue",
+			[]string{"queue"},
+			nil,
+		)
+		c.requestQueueRejectedTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "request_queue_rejected_total"),
+			"Number of requests rejected with HTTP 503 by the kernel because the request queue was full or the application was not available",
+			[]string{"queue"},
+			nil,
+		)
+		c.requestQueueCacheHitsTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "request_queue_cache_hits_total"),
+			"Number of requests of the request queue served from the kernel URI cache",
+			[]string{"queue"},
+			nil,
+		)
+
+		c.requestQueuesPerfDataCollector, err = pdh.NewCollector[perfDataCounterValuesRequestQueues](logger, pdh.CounterTypeRaw, "HTTP Service Request Queues", pdh.InstancesAll)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create HTTP Service Request Queues collector: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorURICache) {
+		if err := c.collectURICache(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting HTTP Service metrics: %w", err))
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorRequestQueues) {
+		if err := c.collectRequestQueues(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting HTTP Service Request Queues metrics: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectURICache(ch chan<- prometheus.Metric) error {
+	err := c.uriCachePerfDataCollector.Collect(&c.uriCachePerfDataObject)
+	if err != nil {
+		return fmt.Errorf("failed to collect HTTP Service metrics: %w", err)
+	}
+
+	if len(c.uriCachePerfDataObject) == 0 {
+		return fmt.Errorf("failed to collect HTTP Service metrics: %w", types.ErrNoDataUnexpected)
+	}
+
+	data := c.uriCachePerfDataObject[0]
+
+	ch <- prometheus.MustNewConstMetric(
+		c.uriCacheEntries,
+		prometheus.GaugeValue,
+		data.CurrentUrisCached,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.uriCacheEntriesAdded,
+		prometheus.CounterValue,
+		data.TotalUrisCached,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.uriCacheHitsTotal,
+		prometheus.CounterValue,
+		data.UriCacheHits,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.uriCacheMissesTotal,
+		prometheus.CounterValue,
+		data.UriCacheMisses,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.uriCacheFlushesTotal,
+		prometheus.CounterValue,
+		data.UriCacheFlushes,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.uriCacheFlushedURIs,
+		prometheus.CounterValue,
+		data.TotalFlushedUris,
+	)
+
+	return nil
+}
+
+func (c *Collector) collectRequestQueues(ch chan<- prometheus.Metric) error {
+	err := c.requestQueuesPerfDataCollector.Collect(&c.requestQueuesPerfDataObject)
+	if err != nil {
+		return fmt.Errorf("failed to collect HTTP Service Request Queues metrics: %w", err)
+	}
+
+	for _, data := range c.requestQueuesPerfDataObject {
+		if c.config.QueueExclude.MatchString(data.Name) || !c.config.QueueInclude.MatchString(data.Name) {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.requestQueueSize,
+			prometheus.GaugeValue,
+			data.CurrentQueueSize,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.requestQueueMaxItemAge,
+			prometheus.GaugeValue,
+			utils.MilliSecToSec(data.MaxQueueItemAge),
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.requestQueueArrivalsTotal,
+			prometheus.CounterValue,
+			data.ArrivalRate,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.requestQueueRejectedTotal,
+			prometheus.CounterValue,
+			data.RejectedRequests,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.requestQueueCacheHitsTotal,
+			prometheus.CounterValue,
+			data.CacheHitRate,
+			data.Name,
+		)
+	}
+
+	return nil
+}
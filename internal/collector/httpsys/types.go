@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httpsys
+
+type perfDataCounterValuesURICache struct {
+	// HTTP Service
+	CurrentUrisCached float64 `perfdata:"CurrentUrisCached"`
+	TotalUrisCached   float64 `perfdata:"TotalUrisCached"`
+	UriCacheHits      float64 `perfdata:"UriCacheHits"`
+	UriCacheMisses    float64 `perfdata:"UriCacheMisses"`
+	UriCacheFlushes   float64 `perfdata:"UriCacheFlushes"`
+	TotalFlushedUris  float64 `perfdata:"TotalFlushedUris"`
+}
+
+type perfDataCounterValuesRequestQueues struct {
+	Name string
+
+	// HTTP Service Request Queues
+	CurrentQueueSize float64 `perfdata:"CurrentQueueSize"`
+	MaxQueueItemAge  float64 `perfdata:"MaxQueueItemAge"`
+	ArrivalRate      float64 `perfdata:"ArrivalRate"`
+	RejectedRequests float64 `perfdata:"RejectedRequests"`
+	CacheHitRate     float64 `perfdata:"CacheHitRate"`
+}
@@ -34,6 +34,7 @@ import (
 const (
 	Name = "hyperv"
 
+	subCollectorCheckpoints                      = "checkpoints"
 	subCollectorDataStore                        = "datastore"
 	subCollectorDynamicMemoryBalancer            = "dynamic_memory_balancer"
 	subCollectorDynamicMemoryVM                  = "dynamic_memory_vm"
@@ -41,7 +42,10 @@ const (
 	subCollectorHypervisorRootPartition          = "hypervisor_root_partition"
 	subCollectorHypervisorRootVirtualProcessor   = "hypervisor_root_virtual_processor"
 	subCollectorHypervisorVirtualProcessor       = "hypervisor_virtual_processor"
+	subCollectorIntegrationServices              = "integration_services"
 	subCollectorLegacyNetworkAdapter             = "legacy_network_adapter"
+	subCollectorStorageQoS                       = "storage_qos"
+	subCollectorVirtualMachine                   = "virtual_machine"
 	subCollectorVirtualMachineHealthSummary      = "virtual_machine_health_summary"
 	subCollectorVirtualMachineVidPartition       = "virtual_machine_vid_partition"
 	subCollectorVirtualNetworkAdapter            = "virtual_network_adapter"
@@ -65,7 +69,9 @@ var ConfigDefaults = Config{
 		subCollectorHypervisorRootPartition,
 		subCollectorHypervisorRootVirtualProcessor,
 		subCollectorHypervisorVirtualProcessor,
+		subCollectorIntegrationServices,
 		subCollectorLegacyNetworkAdapter,
+		subCollectorVirtualMachine,
 		subCollectorVirtualMachineHealthSummary,
 		subCollectorVirtualMachineVidPartition,
 		subCollectorVirtualNetworkAdapter,
@@ -78,6 +84,7 @@ var ConfigDefaults = Config{
 
 // Collector is a Prometheus Collector for hyper-v.
 type Collector struct {
+	collectorCheckpoints
 	collectorDataStore
 	collectorDynamicMemoryBalancer
 	collectorDynamicMemoryVM
@@ -85,7 +92,10 @@ type Collector struct {
 	collectorHypervisorRootPartition
 	collectorHypervisorRootVirtualProcessor
 	collectorHypervisorVirtualProcessor
+	collectorIntegrationServices
 	collectorLegacyNetworkAdapter
+	collectorStorageQoS
+	collectorVirtualMachine
 	collectorVirtualMachineHealthSummary
 	collectorVirtualMachineVidPartition
 	collectorVirtualNetworkAdapter
@@ -94,8 +104,9 @@ type Collector struct {
 	collectorVirtualStorageDevice
 	collectorVirtualSwitch
 
-	config Config
-	logger *slog.Logger
+	config    Config
+	logger    *slog.Logger
+	miSession *mi.Session
 
 	collectorFns []func(ch chan<- prometheus.Metric) error
 	closeFns     []func()
@@ -151,8 +162,9 @@ func (c *Collector) Close() error {
 	return nil
 }
 
-func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
 	c.logger = logger.With(slog.String("collector", Name))
+	c.miSession = miSession
 	c.collectorFns = make([]func(ch chan<- prometheus.Metric) error, 0, len(c.config.CollectorsEnabled))
 	c.closeFns = make([]func(), 0, len(c.config.CollectorsEnabled))
 
@@ -166,6 +178,11 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		close          func()
 		minBuildNumber uint16
 	}{
+		subCollectorCheckpoints: {
+			build:   c.buildCheckpoints,
+			collect: c.collectCheckpoints,
+			close:   func() {},
+		},
 		subCollectorDataStore: {
 			build:          c.buildDataStore,
 			collect:        c.collectDataStore,
@@ -202,11 +219,26 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 			collect: c.collectHypervisorVirtualProcessor,
 			close:   c.perfDataCollectorHypervisorVirtualProcessor.Close,
 		},
+		subCollectorIntegrationServices: {
+			build:   c.buildIntegrationServices,
+			collect: c.collectIntegrationServices,
+			close:   func() {},
+		},
 		subCollectorLegacyNetworkAdapter: {
 			build:   c.buildLegacyNetworkAdapter,
 			collect: c.collectLegacyNetworkAdapter,
 			close:   c.perfDataCollectorLegacyNetworkAdapter.Close,
 		},
+		subCollectorStorageQoS: {
+			build:   c.buildStorageQoS,
+			collect: c.collectStorageQoS,
+			close:   func() {},
+		},
+		subCollectorVirtualMachine: {
+			build:   c.buildVirtualMachine,
+			collect: c.collectVirtualMachine,
+			close:   func() {},
+		},
 		subCollectorVirtualMachineHealthSummary: {
 			build:   c.buildVirtualMachineHealthSummary,
 			collect: c.collectVirtualMachineHealthSummary,
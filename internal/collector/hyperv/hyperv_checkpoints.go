@@ -0,0 +1,286 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package hyperv
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/virtdisk"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// operationalStatusMergingDisks is reported in the second element of the OperationalStatus
+	// of a virtual machine while the differencing disks of a deleted checkpoint are merged.
+	operationalStatusMergingDisks = 32772
+
+	// resourceTypeLogicalDisk is the ResourceType of virtual hard disk files attached to a virtual machine.
+	resourceTypeLogicalDisk = 31
+
+	// maxDiskChainLength guards against loops in the parent locations of differencing disks.
+	maxDiskChainLength = 64
+)
+
+//nolint:gochecknoglobals
+var (
+	virtualMachineQuery = utils.Must(mi.NewQuery("SELECT Name, ElementName, OperationalStatus FROM Msvm_ComputerSystem"))
+	checkpointQuery     = utils.Must(mi.NewQuery("SELECT VirtualSystemIdentifier, VirtualSystemType, CreationTime FROM Msvm_VirtualSystemSettingData WHERE VirtualSystemType = 'Microsoft:Hyper-V:Snapshot:Realized' OR VirtualSystemType = 'Microsoft:Hyper-V:Snapshot:Recovery'"))
+	virtualDiskQuery    = utils.Must(mi.NewQuery(fmt.Sprintf("SELECT InstanceID, HostResource FROM Msvm_StorageAllocationSettingData WHERE ResourceType = %d", resourceTypeLogicalDisk)))
+
+	// checkpointTypes maps the VirtualSystemType of a checkpoint to label values.
+	// Recovery checkpoints are created by backup applications and are expected to be removed after the backup.
+	checkpointTypes = map[string]string{
+		"Microsoft:Hyper-V:Snapshot:Realized": "standard",
+		"Microsoft:Hyper-V:Snapshot:Recovery": "recovery",
+	}
+
+	checkpointTypeLabels = []string{"standard", "recovery"}
+)
+
+// collectorCheckpoints Hyper-V checkpoint and differencing disk metrics
+type collectorCheckpoints struct {
+	vmCheckpoints                *prometheus.Desc
+	vmOldestCheckpointAgeSeconds *prometheus.Desc
+	vmDiskChainLength            *prometheus.Desc
+	vmDiskMergeInProgress        *prometheus.Desc
+}
+
+type msvmComputerSystem struct {
+	Name              string   `mi:"Name"`
+	ElementName       string   `mi:"ElementName"`
+	OperationalStatus []uint16 `mi:"OperationalStatus"`
+}
+
+type msvmVirtualSystemSettingData struct {
+	VirtualSystemIdentifier string    `mi:"VirtualSystemIdentifier"`
+	VirtualSystemType       string    `mi:"VirtualSystemType"`
+	CreationTime            time.Time `mi:"CreationTime"`
+}
+
+type msvmStorageAllocationSettingData struct {
+	InstanceID   string   `mi:"InstanceID"`
+	HostResource []string `mi:"HostResource"`
+}
+
+func (c *Collector) buildCheckpoints() error {
+	if c.miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.vmCheckpoints = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "vm_checkpoints"),
+		"Number of checkpoints of the virtual machine",
+		[]string{"vm", "type"},
+		nil,
+	)
+	c.vmOldestCheckpointAgeSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "vm_oldest_checkpoint_age_seconds"),
+		"Age of the oldest checkpoint of the virtual machine",
+		[]string{"vm"},
+		nil,
+	)
+	c.vmDiskChainLength = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "vm_disk_chain_length"),
+		"Number of differencing disks (AVHDX) between the active disk of the virtual machine and its base disk",
+		[]string{"vm", "disk"},
+		nil,
+	)
+	c.vmDiskMergeInProgress = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "vm_disk_merge_in_progress"),
+		"1 if the differencing disks of the virtual machine are being merged, 0 otherwise",
+		[]string{"vm"},
+		nil,
+	)
+
+	var virtualMachines []msvmComputerSystem
+	if err := c.miSession.Query(&virtualMachines, mi.NamespaceRootVirtualizationV2, virtualMachineQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectCheckpoints(ch chan<- prometheus.Metric) error {
+	var virtualMachines []msvmComputerSystem
+	if err := c.miSession.Query(&virtualMachines, mi.NamespaceRootVirtualizationV2, virtualMachineQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	var checkpoints []msvmVirtualSystemSettingData
+	if err := c.miSession.Query(&checkpoints, mi.NamespaceRootVirtualizationV2, checkpointQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	var virtualDisks []msvmStorageAllocationSettingData
+	if err := c.miSession.Query(&virtualDisks, mi.NamespaceRootVirtualizationV2, virtualDiskQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	for _, vm := range virtualMachines {
+		// The host is also a Msvm_ComputerSystem. Caption and Description are localized,
+		// but only virtual machines are named by their GUID.
+		if _, err := windows.GUIDFromString("{" + vm.Name + "}"); err != nil {
+			continue
+		}
+
+		checkpointCount := make(map[string]int, len(checkpointTypeLabels))
+
+		var oldestCheckpoint time.Time
+
+		for _, checkpoint := range checkpoints {
+			if !strings.EqualFold(checkpoint.VirtualSystemIdentifier, vm.Name) {
+				continue
+			}
+
+			checkpointCount[checkpointTypes[checkpoint.VirtualSystemType]]++
+
+			if !checkpoint.CreationTime.IsZero() && (oldestCheckpoint.IsZero() || checkpoint.CreationTime.Before(oldestCheckpoint)) {
+				oldestCheckpoint = checkpoint.CreationTime
+			}
+		}
+
+		for _, checkpointType := range checkpointTypeLabels {
+			ch <- prometheus.MustNewConstMetric(
+				c.vmCheckpoints,
+				prometheus.GaugeValue,
+				float64(checkpointCount[checkpointType]),
+				vm.ElementName,
+				checkpointType,
+			)
+		}
+
+		if !oldestCheckpoint.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				c.vmOldestCheckpointAgeSeconds,
+				prometheus.GaugeValue,
+				time.Since(oldestCheckpoint).Seconds(),
+				vm.ElementName,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.vmDiskMergeInProgress,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(slices.Contains(vm.OperationalStatus, operationalStatusMergingDisks)),
+			vm.ElementName,
+		)
+
+		// The settings of the running configuration are prefixed with the ID of the virtual machine,
+		// the settings of checkpoints with the ID of the checkpoint.
+		instanceIDPrefix := "Microsoft:" + strings.ToUpper(vm.Name) + `\`
+
+		for _, virtualDisk := range virtualDisks {
+			if len(virtualDisk.HostResource) == 0 || !strings.HasPrefix(strings.ToUpper(virtualDisk.InstanceID), instanceIDPrefix) {
+				continue
+			}
+
+			diskPath := virtualDisk.HostResource[0]
+
+			chainLength, err := diskChainLength(diskPath)
+			if err != nil {
+				c.logger.Debug("failed to read the differencing disk chain",
+					slog.String("vm", vm.ElementName),
+					slog.String("disk", diskPath),
+					slog.Any("err", err),
+				)
+
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.vmDiskChainLength,
+				prometheus.GaugeValue,
+				float64(chainLength),
+				vm.ElementName,
+				diskPath,
+			)
+		}
+	}
+
+	return nil
+}
+
+// diskChainLength returns the number of differencing disks from the given virtual hard disk to its base disk.
+// Only the metadata of the disks is read; the disks are opened without their parents.
+func diskChainLength(diskPath string) (int, error) {
+	chainLength := 0
+
+	for range maxDiskChainLength {
+		parentPath, err := differencingDiskParent(diskPath)
+		if err != nil {
+			return 0, err
+		}
+
+		if parentPath == "" {
+			return chainLength, nil
+		}
+
+		chainLength++
+		diskPath = parentPath
+	}
+
+	return 0, fmt.Errorf("differencing disk chain of %s exceeds %d disks", diskPath, maxDiskChainLength)
+}
+
+// differencingDiskParent returns the path of the parent disk, or an empty string if the disk is not a differencing disk.
+func differencingDiskParent(diskPath string) (string, error) {
+	handle, err := virtdisk.OpenVirtualDisk(diskPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open virtual disk %s: %w", diskPath, err)
+	}
+
+	defer windows.CloseHandle(handle)
+
+	subtype, err := virtdisk.GetProviderSubtype(handle)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the type of virtual disk %s: %w", diskPath, err)
+	}
+
+	if subtype != virtdisk.ProviderSubtypeDifferencing {
+		return "", nil
+	}
+
+	parentLocations, err := virtdisk.GetParentLocations(handle)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the parent of virtual disk %s: %w", diskPath, err)
+	}
+
+	// The locations contain a path relative to the differencing disk and the absolute path of the parent.
+	for _, parentLocation := range parentLocations {
+		if !filepath.IsAbs(parentLocation) {
+			parentLocation = filepath.Join(filepath.Dir(diskPath), parentLocation)
+		}
+
+		if _, err := os.Stat(parentLocation); err == nil {
+			return parentLocation, nil
+		}
+	}
+
+	return "", fmt.Errorf("parent of virtual disk %s not found in %v", diskPath, parentLocations)
+}
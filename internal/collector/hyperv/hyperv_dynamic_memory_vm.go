@@ -42,6 +42,7 @@ type collectorDynamicMemoryVM struct {
 	vmMemoryPhysicalMemory             *prometheus.Desc // \Hyper-V Dynamic Memory VM(*)\Physical Memory
 	vmMemoryRemovedMemory              *prometheus.Desc // \Hyper-V Dynamic Memory VM(*)\Removed Memory
 	vmMemoryGuestAvailableMemory       *prometheus.Desc // \Hyper-V Dynamic Memory VM(*)\Guest Available Memory
+	vmMemoryDemand                     *prometheus.Desc // Current Pressure * Physical Memory
 }
 
 type perfDataCounterValuesDynamicMemoryVM struct {
@@ -122,6 +123,12 @@ func (c *Collector) buildDynamicMemoryVM() error {
 		[]string{"vm"},
 		nil,
 	)
+	c.vmMemoryDemand = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "dynamic_memory_vm_demand_bytes"),
+		"Represents the memory demand of the VM, i.e. the current pressure multiplied by the current amount of memory.",
+		[]string{"vm"},
+		nil,
+	)
 	c.vmMemoryRemovedMemory = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, Name, "dynamic_memory_vm_removed_bytes_total"),
 		"Represents the cumulative amount of memory removed from the VM.",
@@ -204,6 +211,14 @@ func (c *Collector) collectDynamicMemoryVM(ch chan<- prometheus.Metric) error {
 			data.Name,
 		)
 
+		// The pressure is the ratio of the memory demand to the memory assigned to the VM.
+		ch <- prometheus.MustNewConstMetric(
+			c.vmMemoryDemand,
+			prometheus.GaugeValue,
+			utils.PercentageToRatio(data.VmMemoryCurrentPressure)*utils.MBToBytes(data.VmMemoryPhysicalMemory),
+			data.Name,
+		)
+
 		ch <- prometheus.MustNewConstMetric(
 			c.vmMemoryRemovedMemory,
 			prometheus.CounterValue,
@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package hyperv
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// enabledStateDisabled is the EnabledState of an integration service that is turned off for the VM.
+	enabledStateDisabled = 3
+
+	operationalStatusOK = 2
+
+	integrationServicesVersionStateMismatch = 2
+)
+
+//nolint:gochecknoglobals
+var (
+	summaryInformationQuery   = utils.Must(mi.NewQuery("SELECT Name, ElementName, Heartbeat, IntegrationServicesVersionState FROM Msvm_SummaryInformation"))
+	kvpExchangeComponentQuery = utils.Must(mi.NewQuery("SELECT SystemName, EnabledState, OperationalStatus, GuestIntrinsicExchangeItems FROM Msvm_KvpExchangeComponent"))
+
+	// heartbeatStatuses maps the operational status of the heartbeat integration service to label values.
+	heartbeatStatuses = map[uint16]string{
+		2:  "ok",
+		3:  "degraded",
+		7:  "error",
+		12: "no_contact",
+		13: "lost_communication",
+		15: "paused",
+	}
+
+	heartbeatStatusLabels = []string{"ok", "degraded", "error", "no_contact", "lost_communication", "paused", "unknown"}
+)
+
+// collectorIntegrationServices Hyper-V guest integration services metrics
+type collectorIntegrationServices struct {
+	vmHeartbeatStatus                    *prometheus.Desc
+	vmIntegrationServicesInfo            *prometheus.Desc
+	vmIntegrationServicesVersionMismatch *prometheus.Desc
+	vmKvpExchangeAvailable               *prometheus.Desc
+}
+
+type msvmSummaryInformation struct {
+	Name                            string `mi:"Name"`
+	ElementName                     string `mi:"ElementName"`
+	Heartbeat                       uint16 `mi:"Heartbeat"`
+	IntegrationServicesVersionState uint16 `mi:"IntegrationServicesVersionState"`
+}
+
+type msvmKvpExchangeComponent struct {
+	SystemName                  string   `mi:"SystemName"`
+	EnabledState                uint16   `mi:"EnabledState"`
+	OperationalStatus           []uint16 `mi:"OperationalStatus"`
+	GuestIntrinsicExchangeItems []string `mi:"GuestIntrinsicExchangeItems"`
+}
+
+// kvpExchangeDataItem is an embedded Msvm_KvpExchangeDataItem instance in CIM-XML format.
+type kvpExchangeDataItem struct {
+	Properties []struct {
+		Name  string `xml:"NAME,attr"`
+		Value string `xml:"VALUE"`
+	} `xml:"PROPERTY"`
+}
+
+func (c *Collector) buildIntegrationServices() error {
+	if c.miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.vmHeartbeatStatus = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "vm_heartbeat_status"),
+		"Status of the heartbeat integration service of the virtual machine",
+		[]string{"vm", "status"},
+		nil,
+	)
+	c.vmIntegrationServicesInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "vm_integration_services_info"),
+		"A metric with a constant '1' value labeled with the integration services version reported by the guest",
+		[]string{"vm", "version"},
+		nil,
+	)
+	c.vmIntegrationServicesVersionMismatch = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "vm_integration_services_version_mismatch"),
+		"1 if the integration services version of the guest doesn't match the version of the host, 0 otherwise",
+		[]string{"vm"},
+		nil,
+	)
+	c.vmKvpExchangeAvailable = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "vm_kvp_exchange_available"),
+		"1 if the key-value pair exchange integration service of the virtual machine is enabled and communicating, 0 otherwise",
+		[]string{"vm"},
+		nil,
+	)
+
+	var summaries []msvmSummaryInformation
+	if err := c.miSession.Query(&summaries, mi.NamespaceRootVirtualizationV2, summaryInformationQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectIntegrationServices(ch chan<- prometheus.Metric) error {
+	var summaries []msvmSummaryInformation
+	if err := c.miSession.Query(&summaries, mi.NamespaceRootVirtualizationV2, summaryInformationQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	vmNames := make(map[string]string, len(summaries))
+
+	for _, summary := range summaries {
+		vmNames[summary.Name] = summary.ElementName
+
+		status, ok := heartbeatStatuses[summary.Heartbeat]
+		if !ok {
+			status = "unknown"
+		}
+
+		for _, label := range heartbeatStatusLabels {
+			ch <- prometheus.MustNewConstMetric(
+				c.vmHeartbeatStatus,
+				prometheus.GaugeValue,
+				utils.BoolToFloat(label == status),
+				summary.ElementName,
+				label,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.vmIntegrationServicesVersionMismatch,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(summary.IntegrationServicesVersionState == integrationServicesVersionStateMismatch),
+			summary.ElementName,
+		)
+	}
+
+	var components []msvmKvpExchangeComponent
+	if err := c.miSession.Query(&components, mi.NamespaceRootVirtualizationV2, kvpExchangeComponentQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	for _, component := range components {
+		vmName, ok := vmNames[component.SystemName]
+		if !ok {
+			continue
+		}
+
+		available := component.EnabledState != enabledStateDisabled &&
+			len(component.OperationalStatus) > 0 && component.OperationalStatus[0] == operationalStatusOK
+
+		ch <- prometheus.MustNewConstMetric(
+			c.vmKvpExchangeAvailable,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(available),
+			vmName,
+		)
+
+		if version := integrationServicesVersion(component.GuestIntrinsicExchangeItems); version != "" {
+			ch <- prometheus.MustNewConstMetric(
+				c.vmIntegrationServicesInfo,
+				prometheus.GaugeValue,
+				1,
+				vmName,
+				version,
+			)
+		}
+	}
+
+	return nil
+}
+
+// integrationServicesVersion returns the IntegrationServicesVersion item of the intrinsic key-value pairs
+// published by the guest. The guest only publishes the items while the KVP exchange service is running.
+func integrationServicesVersion(items []string) string {
+	for _, item := range items {
+		var dataItem kvpExchangeDataItem
+		if err := xml.Unmarshal([]byte(item), &dataItem); err != nil {
+			continue
+		}
+
+		var name, data string
+
+		for _, property := range dataItem.Properties {
+			switch property.Name {
+			case "Name":
+				name = property.Value
+			case "Data":
+				data = property.Value
+			}
+		}
+
+		if name == "IntegrationServicesVersion" {
+			return data
+		}
+	}
+
+	return ""
+}
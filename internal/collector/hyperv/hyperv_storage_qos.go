@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package hyperv
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+//nolint:gochecknoglobals
+var (
+	storageQoSPolicyQuery = utils.Must(mi.NewQuery("SELECT PolicyId, Name FROM MSFT_StorageQoSPolicy"))
+	storageQoSFlowQuery   = utils.Must(mi.NewQuery("SELECT InitiatorName, InitiatorNodeName, FilePath, PolicyId, MinimumIops, MaximumIops, InitiatorIOPS, InitiatorLatency, StorageNodeLatency FROM MSFT_StorageQoSFlow"))
+)
+
+// collectorStorageQoS Hyper-V Storage QoS flow metrics
+type collectorStorageQoS struct {
+	storageQoSFlowIOPS               *prometheus.Desc
+	storageQoSFlowLatency            *prometheus.Desc
+	storageQoSFlowStorageNodeLatency *prometheus.Desc
+	storageQoSFlowMinimumIOPS        *prometheus.Desc
+	storageQoSFlowMaximumIOPS        *prometheus.Desc
+	storageQoSFlowBelowMinimum       *prometheus.Desc
+	storageQoSFlowAtMaximum          *prometheus.Desc
+}
+
+type msftStorageQoSPolicy struct {
+	PolicyID string `mi:"PolicyId"`
+	Name     string `mi:"Name"`
+}
+
+// msftStorageQoSFlow is a flow between a virtual hard disk of a virtual machine and the storage cluster.
+// IOPS are normalized to 8 KB, latencies are averages in milliseconds.
+type msftStorageQoSFlow struct {
+	InitiatorName      string  `mi:"InitiatorName"`
+	InitiatorNodeName  string  `mi:"InitiatorNodeName"`
+	FilePath           string  `mi:"FilePath"`
+	PolicyID           string  `mi:"PolicyId"`
+	MinimumIops        uint64  `mi:"MinimumIops"`
+	MaximumIops        uint64  `mi:"MaximumIops"`
+	InitiatorIOPS      uint64  `mi:"InitiatorIOPS"`
+	InitiatorLatency   float64 `mi:"InitiatorLatency"`
+	StorageNodeLatency float64 `mi:"StorageNodeLatency"`
+}
+
+func (c *Collector) buildStorageQoS() error {
+	if c.miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	labels := []string{"vm", "node", "vhd", "policy"}
+
+	c.storageQoSFlowIOPS = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "storage_qos_flow_iops"),
+		"Normalized IOPS (8 KB) of the flow as seen by the Hyper-V host",
+		labels,
+		nil,
+	)
+	c.storageQoSFlowLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "storage_qos_flow_latency_seconds"),
+		"Average latency of the flow as seen by the Hyper-V host",
+		labels,
+		nil,
+	)
+	c.storageQoSFlowStorageNodeLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "storage_qos_flow_storage_node_latency_seconds"),
+		"Average latency of the flow as seen by the storage node",
+		labels,
+		nil,
+	)
+	c.storageQoSFlowMinimumIOPS = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "storage_qos_flow_minimum_iops"),
+		"Minimum normalized IOPS reserved for the flow by the Storage QoS policy. 0 if no minimum is configured",
+		labels,
+		nil,
+	)
+	c.storageQoSFlowMaximumIOPS = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "storage_qos_flow_maximum_iops"),
+		"Maximum normalized IOPS allowed for the flow by the Storage QoS policy. 0 if no maximum is configured",
+		labels,
+		nil,
+	)
+	c.storageQoSFlowBelowMinimum = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "storage_qos_flow_below_minimum"),
+		"1 if the normalized IOPS of the flow are below the minimum of the Storage QoS policy, 0 otherwise",
+		labels,
+		nil,
+	)
+	c.storageQoSFlowAtMaximum = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "storage_qos_flow_at_maximum"),
+		"1 if the normalized IOPS of the flow reached the maximum of the Storage QoS policy and the flow is throttled, 0 otherwise",
+		labels,
+		nil,
+	)
+
+	var policies []msftStorageQoSPolicy
+	if err := c.miSession.Query(&policies, mi.NamespaceRootWindowsStorage, storageQoSPolicyQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectStorageQoS(ch chan<- prometheus.Metric) error {
+	var policies []msftStorageQoSPolicy
+	if err := c.miSession.Query(&policies, mi.NamespaceRootWindowsStorage, storageQoSPolicyQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	policyNames := make(map[string]string, len(policies))
+	for _, policy := range policies {
+		policyNames[policy.PolicyID] = policy.Name
+	}
+
+	var flows []msftStorageQoSFlow
+	if err := c.miSession.Query(&flows, mi.NamespaceRootWindowsStorage, storageQoSFlowQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	// A virtual hard disk has multiple flows for a short time while the virtual machine is migrated.
+	seen := make(map[[3]string]struct{}, len(flows))
+
+	for _, flow := range flows {
+		key := [3]string{flow.InitiatorName, flow.InitiatorNodeName, flow.FilePath}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+
+		labelValues := []string{flow.InitiatorName, flow.InitiatorNodeName, flow.FilePath, policyNames[flow.PolicyID]}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.storageQoSFlowIOPS,
+			prometheus.GaugeValue,
+			float64(flow.InitiatorIOPS),
+			labelValues...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.storageQoSFlowLatency,
+			prometheus.GaugeValue,
+			utils.MilliSecToSec(flow.InitiatorLatency),
+			labelValues...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.storageQoSFlowStorageNodeLatency,
+			prometheus.GaugeValue,
+			utils.MilliSecToSec(flow.StorageNodeLatency),
+			labelValues...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.storageQoSFlowMinimumIOPS,
+			prometheus.GaugeValue,
+			float64(flow.MinimumIops),
+			labelValues...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.storageQoSFlowMaximumIOPS,
+			prometheus.GaugeValue,
+			float64(flow.MaximumIops),
+			labelValues...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.storageQoSFlowBelowMinimum,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(flow.MinimumIops > 0 && flow.InitiatorIOPS < flow.MinimumIops),
+			labelValues...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.storageQoSFlowAtMaximum,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(flow.MaximumIops > 0 && flow.InitiatorIOPS >= flow.MaximumIops),
+			labelValues...,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package hyperv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+// replicationModeNone is the ReplicationMode of virtual machines that are not replicated.
+const replicationModeNone = 0
+
+//nolint:gochecknoglobals
+var (
+	virtualMachineSummaryQuery = utils.Must(mi.NewQuery("SELECT Name, ElementName, ReplicationMode, ReplicationState, ReplicationHealth FROM Msvm_SummaryInformation"))
+
+	// replicationModes maps the ReplicationMode of Msvm_SummaryInformation to label values.
+	replicationModes = map[uint16]string{
+		1: "primary",
+		2: "replica",
+		3: "test_replica",
+		4: "extended_replica",
+	}
+
+	// replicationHealths maps the ReplicationHealth of Msvm_SummaryInformation to label values.
+	replicationHealths = map[uint16]string{
+		1: "ok",
+		2: "warning",
+		3: "critical",
+	}
+
+	replicationHealthLabels = []string{"ok", "warning", "critical", "unknown"}
+
+	// replicationStates maps the ReplicationState of Msvm_SummaryInformation to label values.
+	replicationStates = map[uint16]string{
+		0:  "disabled",
+		1:  "ready_for_replication",
+		2:  "waiting_to_complete_initial_replication",
+		3:  "replicating",
+		4:  "synced_replication_complete",
+		5:  "recovered",
+		6:  "committed",
+		7:  "suspended",
+		8:  "critical",
+		9:  "waiting_to_start_resynchronization",
+		10: "resynchronizing",
+		11: "resynchronization_suspended",
+		12: "failover_in_progress",
+		13: "failback_in_progress",
+		14: "failback_complete",
+	}
+)
+
+// collectorVirtualMachine Hyper-V virtual machine metrics, labeled by the ID of the virtual machine,
+// which doesn't change if the virtual machine is renamed.
+type collectorVirtualMachine struct {
+	vmInfo              *prometheus.Desc
+	vmReplicationHealth *prometheus.Desc
+	vmReplicationState  *prometheus.Desc
+	vmVirtualDiskInfo   *prometheus.Desc
+}
+
+type msvmVirtualMachineSummary struct {
+	Name              string `mi:"Name"`
+	ElementName       string `mi:"ElementName"`
+	ReplicationMode   uint16 `mi:"ReplicationMode"`
+	ReplicationState  uint16 `mi:"ReplicationState"`
+	ReplicationHealth uint16 `mi:"ReplicationHealth"`
+}
+
+func (c *Collector) buildVirtualMachine() error {
+	if c.miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.vmInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "vm_info"),
+		"A metric with a constant '1' value labeled with the ID and the name of the virtual machine",
+		[]string{"vm_id", "vm"},
+		nil,
+	)
+	c.vmReplicationHealth = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "vm_replication_health"),
+		"Health of the Hyper-V Replica replication of the virtual machine",
+		[]string{"vm_id", "mode", "health"},
+		nil,
+	)
+	c.vmReplicationState = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "vm_replication_state"),
+		"A metric with a constant '1' value labeled with the state of the Hyper-V Replica replication of the virtual machine",
+		[]string{"vm_id", "state"},
+		nil,
+	)
+	c.vmVirtualDiskInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "vm_virtual_disk_info"),
+		"A metric with a constant '1' value labeled with the ID of the virtual machine and the device of its virtual hard disks in the virtual_storage_device metrics",
+		[]string{"vm_id", "device"},
+		nil,
+	)
+
+	var summaries []msvmVirtualMachineSummary
+	if err := c.miSession.Query(&summaries, mi.NamespaceRootVirtualizationV2, virtualMachineSummaryQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectVirtualMachine(ch chan<- prometheus.Metric) error {
+	var summaries []msvmVirtualMachineSummary
+	if err := c.miSession.Query(&summaries, mi.NamespaceRootVirtualizationV2, virtualMachineSummaryQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	var virtualDisks []msvmStorageAllocationSettingData
+	if err := c.miSession.Query(&virtualDisks, mi.NamespaceRootVirtualizationV2, virtualDiskQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	for _, summary := range summaries {
+		// Only virtual machines are named by their GUID, see collectCheckpoints.
+		if _, err := windows.GUIDFromString("{" + summary.Name + "}"); err != nil {
+			continue
+		}
+
+		vmID := strings.ToLower(summary.Name)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.vmInfo,
+			prometheus.GaugeValue,
+			1,
+			vmID,
+			summary.ElementName,
+		)
+
+		if mode, ok := replicationModes[summary.ReplicationMode]; ok && summary.ReplicationMode != replicationModeNone {
+			health, ok := replicationHealths[summary.ReplicationHealth]
+			if !ok {
+				health = "unknown"
+			}
+
+			for _, label := range replicationHealthLabels {
+				ch <- prometheus.MustNewConstMetric(
+					c.vmReplicationHealth,
+					prometheus.GaugeValue,
+					utils.BoolToFloat(label == health),
+					vmID,
+					mode,
+					label,
+				)
+			}
+
+			state, ok := replicationStates[summary.ReplicationState]
+			if !ok {
+				state = "unknown"
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.vmReplicationState,
+				prometheus.GaugeValue,
+				1,
+				vmID,
+				state,
+			)
+		}
+
+		// The settings of the running configuration are prefixed with the ID of the virtual machine.
+		instanceIDPrefix := "Microsoft:" + strings.ToUpper(summary.Name) + `\`
+
+		for _, virtualDisk := range virtualDisks {
+			if len(virtualDisk.HostResource) == 0 || !strings.HasPrefix(strings.ToUpper(virtualDisk.InstanceID), instanceIDPrefix) {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.vmVirtualDiskInfo,
+				prometheus.GaugeValue,
+				1,
+				vmID,
+				virtualStorageDeviceName(virtualDisk.HostResource[0]),
+			)
+		}
+	}
+
+	return nil
+}
+
+// virtualStorageDeviceName returns the instance name of the Hyper-V Virtual Storage Device performance counters
+// of a virtual hard disk, which is its path with dashes instead of backslashes.
+func virtualStorageDeviceName(diskPath string) string {
+	return strings.ReplaceAll(diskPath, `\`, "-")
+}
@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package icmp
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/iphlpapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	Name = "icmp"
+
+	ipAddressFamilyIPv4 = "ipv4"
+	ipAddressFamilyIPv6 = "ipv6"
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var (
+	// icmpTypes maps the ICMP message types to their names.
+	// https://www.iana.org/assignments/icmp-parameters/icmp-parameters.xhtml
+	icmpTypes = map[int]string{
+		0:  "echo_reply",
+		3:  "destination_unreachable",
+		4:  "source_quench",
+		5:  "redirect",
+		8:  "echo_request",
+		9:  "router_advertisement",
+		10: "router_solicitation",
+		11: "time_exceeded",
+		12: "parameter_problem",
+		13: "timestamp_request",
+		14: "timestamp_reply",
+		17: "address_mask_request",
+		18: "address_mask_reply",
+	}
+
+	// icmpv6Types maps the ICMPv6 message types to their names.
+	// https://www.iana.org/assignments/icmpv6-parameters/icmpv6-parameters.xhtml
+	icmpv6Types = map[int]string{
+		1:   "destination_unreachable",
+		2:   "packet_too_big",
+		3:   "time_exceeded",
+		4:   "parameter_problem",
+		128: "echo_request",
+		129: "echo_reply",
+		130: "multicast_listener_query",
+		131: "multicast_listener_report",
+		132: "multicast_listener_done",
+		133: "router_solicitation",
+		134: "router_advertisement",
+		135: "neighbor_solicitation",
+		136: "neighbor_advertisement",
+		137: "redirect",
+		143: "multicast_listener_report_v2",
+	}
+)
+
+// A Collector is a Prometheus Collector for ICMP and ICMPv6 statistics.
+type Collector struct {
+	config Config
+
+	messagesTotal       *prometheus.Desc
+	errorsTotal         *prometheus.Desc
+	messagesByTypeTotal *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
+	c.messagesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "messages_total"),
+		"Number of ICMP messages received or sent, including errors",
+		[]string{"af", "direction"},
+		nil,
+	)
+	c.errorsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "errors_total"),
+		"Number of ICMP messages received with errors or not sent due to errors",
+		[]string{"af", "direction"},
+		nil,
+	)
+	c.messagesByTypeTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "messages_by_type_total"),
+		"Number of ICMP messages received or sent by message type",
+		[]string{"af", "direction", "type"},
+		nil,
+	)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if err := c.collect(ch, windows.AF_INET, ipAddressFamilyIPv4, icmpTypes); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting ICMP metrics: %w", err))
+	}
+
+	if err := c.collect(ch, windows.AF_INET6, ipAddressFamilyIPv6, icmpv6Types); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting ICMPv6 metrics: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collect(ch chan<- prometheus.Metric, family uint32, af string, typeNames map[int]string) error {
+	stats, err := iphlpapi.GetIcmpStatisticsEx(family)
+	if err != nil {
+		return err
+	}
+
+	for direction, directionStats := range map[string]iphlpapi.MIBICMPSTATS_EX{
+		"received": stats.InStats,
+		"sent":     stats.OutStats,
+	} {
+		ch <- prometheus.MustNewConstMetric(
+			c.messagesTotal,
+			prometheus.CounterValue,
+			float64(directionStats.Msgs),
+			af,
+			direction,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.errorsTotal,
+			prometheus.CounterValue,
+			float64(directionStats.Errors),
+			af,
+			direction,
+		)
+
+		// Message types without a name are summarized as "other".
+		var other float64
+
+		for messageType, count := range directionStats.TypeCount {
+			typeName, ok := typeNames[messageType]
+			if !ok {
+				other += float64(count)
+
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.messagesByTypeTotal,
+				prometheus.CounterValue,
+				float64(count),
+				af,
+				direction,
+				typeName,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.messagesByTypeTotal,
+			prometheus.CounterValue,
+			other,
+			af,
+			direction,
+			"other",
+		)
+	}
+
+	return nil
+}
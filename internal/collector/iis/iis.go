@@ -26,6 +26,7 @@ import (
 	"strings"
 
 	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
 	"github.com/prometheus-community/windows_exporter/internal/types"
 	"github.com/prometheus/client_golang/prometheus"
@@ -39,6 +40,9 @@ type Config struct {
 	SiteExclude *regexp.Regexp `yaml:"site-exclude"`
 	AppInclude  *regexp.Regexp `yaml:"app-include"`
 	AppExclude  *regexp.Regexp `yaml:"app-exclude"`
+	// SiteNames maps site IDs to the site label of the metrics whose performance counter instances
+	// are named after the site ID instead of the site name.
+	SiteNames SiteNames `yaml:"site-names"`
 }
 
 //nolint:gochecknoglobals
@@ -55,6 +59,11 @@ type Collector struct {
 	collectorAppPoolWAS
 	collectorW3SVCW3WP
 	collectorWebServiceCache
+	collectorTLS
+	collectorAppPoolWorkers
+	collectorASPNET
+	collectorConfig
+	collectorARR
 
 	config     Config
 	iisVersion simpleVersion
@@ -97,7 +106,7 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 		config: ConfigDefaults,
 	}
 
-	var appExclude, appInclude, siteExclude, siteInclude string
+	var appExclude, appInclude, siteExclude, siteInclude, siteNames string
 
 	app.Flag(
 		"collector.iis.app-exclude",
@@ -119,6 +128,11 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 		"Regexp of sites to include. Site name must both match include and not match exclude to be included.",
 	).Default(".+").StringVar(&siteInclude)
 
+	app.Flag(
+		"collector.iis.site-names",
+		"Comma-separated list of <id>=<name> pairs mapping IIS site IDs to site names. Sites not listed are resolved via applicationHost.config.",
+	).Default("").StringVar(&siteNames)
+
 	app.Action(func(*kingpin.ParseContext) error {
 		var err error
 
@@ -142,6 +156,11 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 			return fmt.Errorf("collector.iis.site-include: %w", err)
 		}
 
+		c.config.SiteNames, err = parseSiteNames(siteNames)
+		if err != nil {
+			return fmt.Errorf("collector.iis.site-names: %w", err)
+		}
+
 		return nil
 	})
 
@@ -158,8 +177,21 @@ func (c *Collector) Close() error {
 	c.perfDataCollectorAppPoolWAS.Close()
 	c.w3SVCW3WPPerfDataCollector.Close()
 	c.serviceCachePerfDataCollector.Close()
+	c.tlsPerfDataCollector.Close()
+	c.appPoolWorkersPerfDataCollector.Close()
+	c.aspNetPerfDataCollector.Close()
+
+	errs := make([]error, 0)
+
+	if c.tlsRenderContext != 0 {
+		errs = append(errs, wevtapi.EvtClose(c.tlsRenderContext))
+	}
+
+	if c.appPoolRecycleRenderContext != 0 {
+		errs = append(errs, wevtapi.EvtClose(c.appPoolRecycleRenderContext))
+	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
@@ -196,6 +228,26 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		errs = append(errs, fmt.Errorf("failed to build Web Service Cache collector: %w", err))
 	}
 
+	if err := c.buildTLS(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to build TLS collector: %w", err))
+	}
+
+	if err := c.buildAppPoolWorkers(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to build application pool worker collector: %w", err))
+	}
+
+	if err := c.buildASPNET(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to build ASP.NET Applications collector: %w", err))
+	}
+
+	if err := c.buildConfig(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to build configuration collector: %w", err))
+	}
+
+	if err := c.buildARR(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to build ARR collector: %w", err))
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -280,6 +332,26 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		errs = append(errs, fmt.Errorf("failed to collect Web Service Cache metrics: %w", err))
 	}
 
+	if err := c.collectTLS(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed to collect TLS metrics: %w", err))
+	}
+
+	if err := c.collectAppPoolWorkers(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed to collect application pool worker metrics: %w", err))
+	}
+
+	if err := c.collectASPNET(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed to collect ASP.NET Applications metrics: %w", err))
+	}
+
+	if err := c.collectConfig(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed to collect configuration metrics: %w", err))
+	}
+
+	if err := c.collectARR(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed to collect ARR metrics: %w", err))
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -0,0 +1,378 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package iis
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+// WAS events logged when a worker process requests a recycle. Which of them are logged is configured per
+// application pool by the logEventOnRecycle attribute, by default Time, Memory and PrivateMemory.
+// 📑 https://learn.microsoft.com/en-us/iis/configuration/system.applicationhost/applicationpools/add/recycling/
+//
+//nolint:gochecknoglobals
+var (
+	appPoolRecycleReasons = map[uint64]string{
+		5074: "time",
+		5075: "requests",
+		5076: "virtual_memory",
+		5077: "schedule",
+		5078: "isapi_unhealthy",
+		5079: "on_demand",
+		5080: "config_change",
+		5117: "private_memory",
+	}
+
+	appPoolRecycleQuery = "*[System[Provider[@Name='Microsoft-Windows-WAS'] and " +
+		"(EventID=5074 or EventID=5075 or EventID=5076 or EventID=5077 or EventID=5078 or EventID=5079 or EventID=5080 or EventID=5117) " +
+		"and EventRecordID > %d]]"
+
+	// appPoolRecycleRenderValuePaths are the event properties rendered for each WAS event.
+	// The order must match the appPoolRecycleValue* indices.
+	appPoolRecycleRenderValuePaths = []string{
+		"Event/System/EventRecordID",
+		"Event/System/EventID",
+		"Event/System/TimeCreated/@SystemTime",
+		"Event/EventData/Data[@Name='AppPoolID']",
+	}
+)
+
+const (
+	appPoolRecycleValueEventRecordID = iota
+	appPoolRecycleValueEventID
+	appPoolRecycleValueTimeCreated
+	appPoolRecycleValueAppPoolID
+)
+
+type appPoolRecycle struct {
+	app    string
+	reason string
+}
+
+// collectorAppPoolWorkers reports the worker processes of the application pools and metrics per application pool,
+// which, unlike the per-process W3SVC_W3WP metrics, keep their series when a worker process is recycled.
+type collectorAppPoolWorkers struct {
+	appPoolWorkersPerfDataCollector *pdh.Collector
+	appPoolWorkersPerfDataObject    []perfDataCounterValuesAppPoolWorkers
+
+	// workerProcessStartTimes caches the start times of the worker processes by PID.
+	workerProcessStartTimes map[uint32]float64
+
+	appPoolRecycleRenderContext wevtapi.EVT_HANDLE
+
+	// appPoolRecycleMu protects the recycle counters and appPoolRecycleLastRecordID against concurrent scrapes.
+	appPoolRecycleMu           sync.Mutex
+	appPoolRecycleLastRecordID uint64
+	appPoolRecycles            map[appPoolRecycle]float64
+	appPoolLastRecycles        map[string]float64
+
+	workerProcessStartTime      *prometheus.Desc
+	appPoolCurrentRequests      *prometheus.Desc
+	appPoolRequestsTotal        *prometheus.Desc
+	appPoolThreads              *prometheus.Desc
+	appPoolRecyclesTotal        *prometheus.Desc
+	appPoolLastRecycleTimestamp *prometheus.Desc
+	appPoolWorkerProcesses      *prometheus.Desc
+}
+
+type perfDataCounterValuesAppPoolWorkers struct {
+	Name string
+
+	ActiveRequests          float64 `perfdata:"Active Requests"`
+	ActiveThreadsCount      float64 `perfdata:"Active Threads Count"`
+	TotalHTTPRequestsServed float64 `perfdata:"Total HTTP Requests Served"`
+}
+
+// appPoolWorkerValues are the sums of the counter values of the worker processes of an application pool.
+type appPoolWorkerValues struct {
+	processes int
+	perfDataCounterValuesAppPoolWorkers
+}
+
+func (c *Collector) buildAppPoolWorkers() error {
+	c.workerProcessStartTime = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "worker_process_start_time_seconds"),
+		"Start time of the worker process of the application pool as unix timestamp",
+		[]string{"app", "pid"},
+		nil,
+	)
+	c.appPoolCurrentRequests = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "application_pool_current_requests"),
+		"Number of requests being processed by the worker processes of the application pool, including in-process ASP.NET Core applications",
+		[]string{"app"},
+		nil,
+	)
+	c.appPoolRequestsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "application_pool_requests_total"),
+		"Number of requests served by the running worker processes of the application pool, including in-process ASP.NET Core applications",
+		[]string{"app"},
+		nil,
+	)
+	c.appPoolThreads = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "application_pool_threads"),
+		"Number of threads actively processing requests in the worker processes of the application pool",
+		[]string{"app"},
+		nil,
+	)
+	c.appPoolWorkerProcesses = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "application_pool_worker_processes"),
+		"Number of worker processes of the application pool reporting W3SVC_W3WP counters. During an overlapped recycle, the old and the new worker process are counted.",
+		[]string{"app"},
+		nil,
+	)
+	c.appPoolRecyclesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "application_pool_recycles_by_reason_total"),
+		"Number of worker process recycles of the application pool logged by WAS since the exporter started",
+		[]string{"app", "reason"},
+		nil,
+	)
+	c.appPoolLastRecycleTimestamp = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "application_pool_last_recycle_timestamp_seconds"),
+		"Time of the last worker process recycle of the application pool logged by WAS since the exporter started as unix timestamp",
+		[]string{"app"},
+		nil,
+	)
+
+	c.workerProcessStartTimes = make(map[uint32]float64)
+	c.appPoolRecycles = make(map[appPoolRecycle]float64)
+	c.appPoolLastRecycles = make(map[string]float64)
+
+	var err error
+
+	c.appPoolRecycleLastRecordID, err = wevtapi.LatestEventRecordID(systemChannel)
+	if err != nil {
+		return fmt.Errorf("failed to read latest event of %s: %w", systemChannel, err)
+	}
+
+	c.appPoolRecycleRenderContext, err = wevtapi.EvtCreateRenderContext(appPoolRecycleRenderValuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	c.appPoolWorkersPerfDataCollector, err = pdh.NewCollector[perfDataCounterValuesAppPoolWorkers](c.logger, pdh.CounterTypeRaw, "W3SVC_W3WP", pdh.InstancesAll)
+	if err != nil {
+		return fmt.Errorf("failed to create W3SVC_W3WP collector: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectAppPoolWorkers(ch chan<- prometheus.Metric) error {
+	return errors.Join(
+		c.collectAppPoolWorkerProcesses(ch),
+		c.collectAppPoolRecycles(ch),
+	)
+}
+
+func (c *Collector) collectAppPoolWorkerProcesses(ch chan<- prometheus.Metric) error {
+	err := c.appPoolWorkersPerfDataCollector.Collect(&c.appPoolWorkersPerfDataObject)
+	if err != nil {
+		return fmt.Errorf("failed to collect W3SVC_W3WP metrics: %w", err)
+	}
+
+	appPools := make(map[string]*appPoolWorkerValues)
+	running := make(map[uint32]struct{}, len(c.appPoolWorkersPerfDataObject))
+
+	for _, data := range c.appPoolWorkersPerfDataObject {
+		// Instances are named <PID>_<NAME>, which skips the _Total instance.
+		match := workerProcessNameExtractor.FindStringSubmatch(data.Name)
+		if match == nil {
+			continue
+		}
+
+		pid, name := match[1], match[2]
+
+		if c.config.AppExclude.MatchString(name) || !c.config.AppInclude.MatchString(name) {
+			continue
+		}
+
+		values, ok := appPools[name]
+		if !ok {
+			values = &appPoolWorkerValues{}
+			appPools[name] = values
+		}
+
+		values.processes++
+		values.ActiveRequests += data.ActiveRequests
+		values.ActiveThreadsCount += data.ActiveThreadsCount
+		values.TotalHTTPRequestsServed += data.TotalHTTPRequestsServed
+
+		processID, err := strconv.ParseUint(pid, 10, 32)
+		if err != nil {
+			continue
+		}
+
+		running[uint32(processID)] = struct{}{}
+
+		startTime, err := c.workerProcessStartTimeByPID(uint32(processID))
+		if err != nil {
+			c.logger.Debug("failed to get start time of worker process",
+				slog.String("app", name),
+				slog.String("pid", pid),
+				slog.Any("err", err),
+			)
+
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.workerProcessStartTime,
+			prometheus.GaugeValue,
+			startTime,
+			name,
+			pid,
+		)
+	}
+
+	// Forget exited worker processes, their PIDs may be reused.
+	for pid := range c.workerProcessStartTimes {
+		if _, ok := running[pid]; !ok {
+			delete(c.workerProcessStartTimes, pid)
+		}
+	}
+
+	for name, values := range appPools {
+		ch <- prometheus.MustNewConstMetric(
+			c.appPoolWorkerProcesses,
+			prometheus.GaugeValue,
+			float64(values.processes),
+			name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.appPoolCurrentRequests,
+			prometheus.GaugeValue,
+			values.ActiveRequests,
+			name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.appPoolRequestsTotal,
+			prometheus.CounterValue,
+			values.TotalHTTPRequestsServed,
+			name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.appPoolThreads,
+			prometheus.GaugeValue,
+			values.ActiveThreadsCount,
+			name,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) workerProcessStartTimeByPID(pid uint32) (float64, error) {
+	if startTime, ok := c.workerProcessStartTimes[pid]; ok {
+		return startTime, nil
+	}
+
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open process: %w", err)
+	}
+
+	defer func() {
+		_ = windows.CloseHandle(handle)
+	}()
+
+	var creation, exit, kernel, user windows.Filetime
+
+	if err := windows.GetProcessTimes(handle, &creation, &exit, &kernel, &user); err != nil {
+		return 0, fmt.Errorf("failed to get process times: %w", err)
+	}
+
+	startTime := float64(creation.Nanoseconds()) / float64(time.Second)
+	c.workerProcessStartTimes[pid] = startTime
+
+	return startTime, nil
+}
+
+func (c *Collector) collectAppPoolRecycles(ch chan<- prometheus.Metric) error {
+	c.appPoolRecycleMu.Lock()
+	defer c.appPoolRecycleMu.Unlock()
+
+	query := fmt.Sprintf(appPoolRecycleQuery, c.appPoolRecycleLastRecordID)
+
+	if err := wevtapi.QueryValues(systemChannel, query, c.appPoolRecycleRenderContext, c.handleAppPoolRecycleEvent); err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", systemChannel, err)
+	}
+
+	for recycle, count := range c.appPoolRecycles {
+		ch <- prometheus.MustNewConstMetric(
+			c.appPoolRecyclesTotal,
+			prometheus.CounterValue,
+			count,
+			recycle.app,
+			recycle.reason,
+		)
+	}
+
+	for app, timestamp := range c.appPoolLastRecycles {
+		ch <- prometheus.MustNewConstMetric(
+			c.appPoolLastRecycleTimestamp,
+			prometheus.GaugeValue,
+			timestamp,
+			app,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) handleAppPoolRecycleEvent(values []any) {
+	if len(values) != len(appPoolRecycleRenderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[appPoolRecycleValueEventRecordID].(uint64)
+	c.appPoolRecycleLastRecordID = max(c.appPoolRecycleLastRecordID, recordID)
+
+	eventID, _ := values[appPoolRecycleValueEventID].(uint64)
+
+	reason, ok := appPoolRecycleReasons[eventID]
+	if !ok {
+		return
+	}
+
+	app, _ := values[appPoolRecycleValueAppPoolID].(string)
+	if app == "" || c.config.AppExclude.MatchString(app) || !c.config.AppInclude.MatchString(app) {
+		return
+	}
+
+	c.appPoolRecycles[appPoolRecycle{app: app, reason: reason}]++
+
+	// TimeCreated is rendered as FILETIME.
+	if timeCreated, ok := values[appPoolRecycleValueTimeCreated].(uint64); ok {
+		filetime := windows.Filetime{LowDateTime: uint32(timeCreated), HighDateTime: uint32(timeCreated >> 32)}
+		c.appPoolLastRecycles[app] = max(c.appPoolLastRecycles[app], float64(filetime.Nanoseconds())/float64(time.Second))
+	}
+}
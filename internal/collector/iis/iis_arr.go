@@ -0,0 +1,405 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package iis
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	adminManagerProgramID = "Microsoft.ApplicationHost.AdminManager"
+	applicationHostPath   = "MACHINE/WEBROOT/APPHOST"
+
+	// oleSFalse is returned by CoInitializeEx if COM was already initialized on this thread.
+	oleSFalse = 0x00000001
+)
+
+// webFarmServerStates are the values of the state attribute of the ARR counters of a server.
+//
+//nolint:gochecknoglobals
+var webFarmServerStates = map[int64]string{
+	0: "available",
+	1: "drain",
+	2: "unavailable",
+	3: "unavailable_gracefully",
+}
+
+// collectorARR reports the runtime counters of the servers of the Application Request Routing (ARR) web farms.
+// The web farms are read from applicationHost.config, the counters from the runtime state of ARR via the
+// configuration API of IIS, which IIS Manager shows in the monitoring of the web farm.
+type collectorARR struct {
+	webFarmServerState           *prometheus.Desc
+	webFarmServerHealthy         *prometheus.Desc
+	webFarmServerCurrentRequests *prometheus.Desc
+	webFarmServerRequestsTotal   *prometheus.Desc
+	webFarmServerFailedRequests  *prometheus.Desc
+	webFarmServerSentBytes       *prometheus.Desc
+	webFarmServerReceivedBytes   *prometheus.Desc
+	webFarmServerResponseTime    *prometheus.Desc
+}
+
+// applicationHostConfigWebFarms is the subset of applicationHost.config containing the web farms of ARR.
+type applicationHostConfigWebFarms struct {
+	WebFarms []xmlNode `xml:"webFarms>webFarm"`
+}
+
+// webFarmServer are the ARR counters of a server of a web farm. Counters that ARR doesn't report are nil.
+type webFarmServer struct {
+	farm    string
+	address string
+
+	state           *float64
+	healthy         *float64
+	currentRequests *float64
+	totalRequests   *float64
+	failedRequests  *float64
+	bytesSent       *float64
+	bytesReceived   *float64
+	responseTime    *float64
+}
+
+func (c *Collector) buildARR() error {
+	c.webFarmServerState = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "webfarm_server_state"),
+		"The state of the server in the ARR web farm. 1 for the current state, 0 for the others",
+		[]string{"farm", "server", "state"},
+		nil,
+	)
+	c.webFarmServerHealthy = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "webfarm_server_healthy"),
+		"Whether the server in the ARR web farm passes the health checks of ARR",
+		[]string{"farm", "server"},
+		nil,
+	)
+	c.webFarmServerCurrentRequests = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "webfarm_server_current_requests"),
+		"Number of requests that ARR currently routes to the server and waits for a response for",
+		[]string{"farm", "server"},
+		nil,
+	)
+	c.webFarmServerRequestsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "webfarm_server_requests_total"),
+		"Number of requests that ARR routed to the server",
+		[]string{"farm", "server"},
+		nil,
+	)
+	c.webFarmServerFailedRequests = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "webfarm_server_failed_requests_total"),
+		"Number of requests that ARR routed to the server and that failed",
+		[]string{"farm", "server"},
+		nil,
+	)
+	c.webFarmServerSentBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "webfarm_server_sent_bytes_total"),
+		"Number of bytes that ARR sent to the server",
+		[]string{"farm", "server"},
+		nil,
+	)
+	c.webFarmServerReceivedBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "webfarm_server_received_bytes_total"),
+		"Number of bytes that ARR received from the server",
+		[]string{"farm", "server"},
+		nil,
+	)
+	c.webFarmServerResponseTime = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "webfarm_server_response_time_seconds"),
+		"Average response time of the server to the requests routed by ARR",
+		[]string{"farm", "server"},
+		nil,
+	)
+
+	return nil
+}
+
+func (c *Collector) collectARR(ch chan<- prometheus.Metric) error {
+	var config applicationHostConfigWebFarms
+	if err := readApplicationHostConfig(&config); err != nil {
+		return err
+	}
+
+	// Hosts without ARR have no web farms, so the configuration API is not needed.
+	if len(config.WebFarms) == 0 {
+		return nil
+	}
+
+	servers, err := queryWebFarmServers()
+	if err != nil {
+		return fmt.Errorf("failed to query ARR counters: %w", err)
+	}
+
+	for _, server := range servers {
+		if server.state != nil {
+			for value, state := range webFarmServerStates {
+				ch <- prometheus.MustNewConstMetric(
+					c.webFarmServerState,
+					prometheus.GaugeValue,
+					utils.BoolToFloat(*server.state == float64(value)),
+					server.farm, server.address, state,
+				)
+			}
+		}
+
+		for _, metric := range []struct {
+			desc      *prometheus.Desc
+			valueType prometheus.ValueType
+			value     *float64
+		}{
+			{c.webFarmServerHealthy, prometheus.GaugeValue, server.healthy},
+			{c.webFarmServerCurrentRequests, prometheus.GaugeValue, server.currentRequests},
+			{c.webFarmServerRequestsTotal, prometheus.CounterValue, server.totalRequests},
+			{c.webFarmServerFailedRequests, prometheus.CounterValue, server.failedRequests},
+			{c.webFarmServerSentBytes, prometheus.CounterValue, server.bytesSent},
+			{c.webFarmServerReceivedBytes, prometheus.CounterValue, server.bytesReceived},
+			{c.webFarmServerResponseTime, prometheus.GaugeValue, server.responseTime},
+		} {
+			if metric.value == nil {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				metric.desc,
+				metric.valueType,
+				*metric.value,
+				server.farm, server.address,
+			)
+		}
+	}
+
+	return nil
+}
+
+// queryWebFarmServers reads the ARR counters of all servers of the web farms with the configuration API of IIS.
+func queryWebFarmServers() ([]webFarmServer, error) {
+	// COM must be initialized on the thread that calls the configuration API.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := ole.CoInitializeEx(0, ole.COINIT_APARTMENTTHREADED|ole.COINIT_DISABLE_OLE1DDE); err != nil {
+		var oleCode *ole.OleError
+		if errors.As(err, &oleCode) && oleCode.Code() != ole.S_OK && oleCode.Code() != oleSFalse {
+			return nil, err
+		}
+	}
+	defer ole.CoUninitialize()
+
+	adminManagerObj, err := oleutil.CreateObject(adminManagerProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", adminManagerProgramID, err)
+	}
+	defer adminManagerObj.Release()
+
+	adminManager, err := adminManagerObj.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return nil, err
+	}
+	defer adminManager.Release()
+
+	section, err := callDispatch(adminManager, "GetAdminSection", "webFarms", applicationHostPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webFarms section: %w", err)
+	}
+	defer section.Release()
+
+	servers := make([]webFarmServer, 0)
+
+	err = forEachElement(section, func(farm *ole.IDispatch) error {
+		farmName, err := propertyValue(farm, "name")
+		if err != nil {
+			return err
+		}
+
+		return forEachElement(farm, func(server *ole.IDispatch) error {
+			address, err := propertyValue(server, "address")
+			if err != nil {
+				return err
+			}
+
+			s := webFarmServer{
+				farm:    fmt.Sprint(farmName),
+				address: fmt.Sprint(address),
+			}
+
+			if err := readWebFarmServerCounters(server, &s); err != nil {
+				return fmt.Errorf("failed to read counters of server %s of web farm %s: %w", s.address, s.farm, err)
+			}
+
+			servers = append(servers, s)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return servers, nil
+}
+
+// readWebFarmServerCounters reads the applicationRequestRouting/counters element of a server. The element is
+// not stored in applicationHost.config, its values are the runtime state of ARR.
+func readWebFarmServerCounters(server *ole.IDispatch, s *webFarmServer) error {
+	arr, err := callDispatch(server, "GetElementByName", "applicationRequestRouting")
+	if err != nil {
+		return err
+	}
+	defer arr.Release()
+
+	counters, err := callDispatch(arr, "GetElementByName", "counters")
+	if err != nil {
+		return err
+	}
+	defer counters.Release()
+
+	for name, value := range map[string]**float64{
+		"state":           &s.state,
+		"isHealthy":       &s.healthy,
+		"currentRequests": &s.currentRequests,
+		"totalRequests":   &s.totalRequests,
+		"failedRequests":  &s.failedRequests,
+		"bytesSent":       &s.bytesSent,
+		"bytesReceived":   &s.bytesReceived,
+		"responseTime":    &s.responseTime,
+	} {
+		v, err := propertyValue(counters, name)
+		if err != nil {
+			// Older releases of ARR don't report all counters.
+			continue
+		}
+
+		f, ok := toFloat(v)
+		if !ok {
+			continue
+		}
+
+		// ARR reports the response time in milliseconds.
+		if name == "responseTime" {
+			f /= 1000
+		}
+
+		*value = &f
+	}
+
+	return nil
+}
+
+// forEachElement calls fn for each element of the collection of the configuration element.
+func forEachElement(element *ole.IDispatch, fn func(*ole.IDispatch) error) error {
+	collection, err := getDispatch(element, "Collection")
+	if err != nil {
+		return err
+	}
+	defer collection.Release()
+
+	countVar, err := oleutil.GetProperty(collection, "Count")
+	if err != nil {
+		return err
+	}
+
+	count, _ := toFloat(countVar.Value())
+	_ = countVar.Clear()
+
+	for i := range int(count) {
+		item, err := getDispatch(collection, "Item", i)
+		if err != nil {
+			return err
+		}
+
+		err = fn(item)
+		item.Release()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// propertyValue returns the value of the attribute of the configuration element.
+func propertyValue(element *ole.IDispatch, name string) (any, error) {
+	property, err := callDispatch(element, "GetPropertyByName", name)
+	if err != nil {
+		return nil, err
+	}
+	defer property.Release()
+
+	valueVar, err := oleutil.GetProperty(property, "Value")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = valueVar.Clear()
+	}()
+
+	return valueVar.Value(), nil
+}
+
+func callDispatch(obj *ole.IDispatch, method string, params ...any) (*ole.IDispatch, error) {
+	result, err := oleutil.CallMethod(obj, method, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.ToIDispatch(), nil
+}
+
+func getDispatch(obj *ole.IDispatch, property string, params ...any) (*ole.IDispatch, error) {
+	result, err := oleutil.GetProperty(obj, property, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.ToIDispatch(), nil
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case bool:
+		return utils.BoolToFloat(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,271 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package iis
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collectorASPNET reports the ASP.NET Applications counters of ASP.NET applications on the .NET Framework.
+// ASP.NET Core applications don't publish performance counters; their requests are counted by the
+// W3SVC_W3WP counters of the worker process, see collectorAppPoolWorkers.
+type collectorASPNET struct {
+	aspNetPerfDataCollector *pdh.Collector
+	aspNetPerfDataObject    []perfDataCounterValuesASPNET
+
+	aspNetRequestsExecuting *prometheus.Desc
+	aspNetRequestsQueued    *prometheus.Desc
+	aspNetRequestsTotal     *prometheus.Desc
+	aspNetRequestsFailed    *prometheus.Desc
+	aspNetErrorsTotal       *prometheus.Desc
+	aspNetSessionsActive    *prometheus.Desc
+}
+
+type perfDataCounterValuesASPNET struct {
+	Name string
+
+	RequestsExecuting          float64 `perfdata:"Requests Executing"`
+	RequestsInApplicationQueue float64 `perfdata:"Requests In Application Queue"`
+	RequestsTotal              float64 `perfdata:"Requests Total"`
+	RequestsFailed             float64 `perfdata:"Requests Failed"`
+	ErrorsTotal                float64 `perfdata:"Errors Total"`
+	SessionsActive             float64 `perfdata:"Sessions Active"`
+}
+
+func (c *Collector) buildASPNET() error {
+	var err error
+
+	c.aspNetPerfDataCollector, err = pdh.NewCollector[perfDataCounterValuesASPNET](c.logger, pdh.CounterTypeRaw, "ASP.NET Applications", pdh.InstancesAll)
+	if errors.Is(err, pdh.NewPdhError(pdh.CstatusNoObject)) {
+		c.logger.Debug("ASP.NET Applications performance counters not found, ASP.NET metrics are not collected")
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to create ASP.NET Applications collector: %w", err)
+	}
+
+	c.aspNetRequestsExecuting = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "aspnet_requests_executing"),
+		"Number of requests currently executing in the ASP.NET application",
+		[]string{"site", "path"},
+		nil,
+	)
+	c.aspNetRequestsQueued = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "aspnet_requests_queued"),
+		"Number of requests in the application request queue of the ASP.NET application",
+		[]string{"site", "path"},
+		nil,
+	)
+	c.aspNetRequestsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "aspnet_requests_total"),
+		"Number of requests since the ASP.NET application started",
+		[]string{"site", "path"},
+		nil,
+	)
+	c.aspNetRequestsFailed = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "aspnet_requests_failed_total"),
+		"Number of failed requests since the ASP.NET application started",
+		[]string{"site", "path"},
+		nil,
+	)
+	c.aspNetErrorsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "aspnet_errors_total"),
+		"Number of errors since the ASP.NET application started",
+		[]string{"site", "path"},
+		nil,
+	)
+	c.aspNetSessionsActive = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "aspnet_sessions_active"),
+		"Number of sessions currently active in the ASP.NET application",
+		[]string{"site", "path"},
+		nil,
+	)
+
+	return nil
+}
+
+func (c *Collector) collectASPNET(ch chan<- prometheus.Metric) error {
+	if c.aspNetPerfDataCollector == nil {
+		return nil
+	}
+
+	err := c.aspNetPerfDataCollector.Collect(&c.aspNetPerfDataObject)
+	if err != nil {
+		return fmt.Errorf("failed to collect ASP.NET Applications metrics: %w", err)
+	}
+
+	var siteNames map[string]string
+
+	for _, data := range c.aspNetPerfDataObject {
+		siteID, path, ok := parseASPNETInstance(data.Name)
+		if !ok {
+			continue
+		}
+
+		if siteNames == nil {
+			siteNames = c.resolveSiteNames()
+		}
+
+		site, ok := siteNames[siteID]
+		if !ok {
+			site = "W3SVC/" + siteID
+		}
+
+		if c.config.SiteExclude.MatchString(site) || !c.config.SiteInclude.MatchString(site) {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.aspNetRequestsExecuting,
+			prometheus.GaugeValue,
+			data.RequestsExecuting,
+			site,
+			path,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.aspNetRequestsQueued,
+			prometheus.GaugeValue,
+			data.RequestsInApplicationQueue,
+			site,
+			path,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.aspNetRequestsTotal,
+			prometheus.CounterValue,
+			data.RequestsTotal,
+			site,
+			path,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.aspNetRequestsFailed,
+			prometheus.CounterValue,
+			data.RequestsFailed,
+			site,
+			path,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.aspNetErrorsTotal,
+			prometheus.CounterValue,
+			data.ErrorsTotal,
+			site,
+			path,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.aspNetSessionsActive,
+			prometheus.GaugeValue,
+			data.SessionsActive,
+			site,
+			path,
+		)
+	}
+
+	return nil
+}
+
+// resolveSiteNames returns the site names by site ID. Names given by --collector.iis.site-names take precedence
+// over the names of applicationHost.config.
+func (c *Collector) resolveSiteNames() map[string]string {
+	siteNames := make(map[string]string, len(c.config.SiteNames))
+
+	var config applicationHostConfig
+	if err := readApplicationHostConfig(&config); err != nil {
+		c.logger.Debug("failed to resolve site names",
+			slog.Any("err", err),
+		)
+	}
+
+	for _, site := range config.Sites {
+		siteNames[site.ID] = site.Name
+	}
+
+	maps.Copy(siteNames, c.config.SiteNames)
+
+	return siteNames
+}
+
+// parseASPNETInstance returns the site ID and the virtual path of an ASP.NET Applications instance,
+// e.g. 1 and /shop/api for _LM_W3SVC_1_ROOT_shop_api. The instance names replace the slashes of the
+// metabase path with underscores, so underscores of application names are reported as slashes.
+func parseASPNETInstance(instance string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(instance, "_LM_W3SVC_")
+	if !ok {
+		return "", "", false
+	}
+
+	siteID, root, ok := strings.Cut(rest, "_")
+	if !ok || siteID == "" {
+		return "", "", false
+	}
+
+	path, ok := strings.CutPrefix(root, "ROOT")
+	if !ok || (path != "" && !strings.HasPrefix(path, "_")) {
+		return "", "", false
+	}
+
+	return siteID, "/" + strings.ReplaceAll(strings.TrimPrefix(path, "_"), "_", "/"), true
+}
+
+// SiteNames maps site IDs to site names.
+type SiteNames map[string]string
+
+// UnmarshalText parses a comma-separated list of <id>=<name> pairs, like --collector.iis.site-names.
+func (s *SiteNames) UnmarshalText(text []byte) error {
+	siteNames, err := parseSiteNames(string(text))
+	if err != nil {
+		return err
+	}
+
+	*s = siteNames
+
+	return nil
+}
+
+// parseSiteNames parses a comma-separated list of <id>=<name> pairs.
+func parseSiteNames(s string) (SiteNames, error) {
+	siteNames := make(SiteNames)
+
+	for value := range strings.SplitSeq(s, ",") {
+		if value == "" {
+			continue
+		}
+
+		id, name, ok := strings.Cut(value, "=")
+		if !ok || id == "" || name == "" {
+			return nil, fmt.Errorf("invalid site name %q, expected <id>=<name>", value)
+		}
+
+		siteNames[strings.TrimSpace(id)] = name
+	}
+
+	return siteNames, nil
+}
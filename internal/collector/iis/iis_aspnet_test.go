@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package iis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v3"
+)
+
+func TestParseASPNETInstance(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		instance string
+		siteID   string
+		path     string
+		ok       bool
+	}{
+		{instance: "_LM_W3SVC_1_ROOT", siteID: "1", path: "/", ok: true},
+		{instance: "_LM_W3SVC_2_ROOT_shop", siteID: "2", path: "/shop", ok: true},
+		{instance: "_LM_W3SVC_2_ROOT_shop_api", siteID: "2", path: "/shop/api", ok: true},
+		{instance: "__Total__"},
+		{instance: "_LM_W3SVC_1_ROOTX"},
+		{instance: "_LM_W3SVC__ROOT"},
+	} {
+		t.Run(tc.instance, func(t *testing.T) {
+			t.Parallel()
+
+			siteID, path, ok := parseASPNETInstance(tc.instance)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.siteID, siteID)
+			require.Equal(t, tc.path, path)
+		})
+	}
+}
+
+func TestParseSiteNames(t *testing.T) {
+	t.Parallel()
+
+	siteNames, err := parseSiteNames("1=Default Web Site,2=shop")
+	require.NoError(t, err)
+	require.Equal(t, SiteNames{"1": "Default Web Site", "2": "shop"}, siteNames)
+
+	siteNames, err = parseSiteNames("")
+	require.NoError(t, err)
+	require.Empty(t, siteNames)
+
+	_, err = parseSiteNames("1")
+	require.Error(t, err)
+
+	var config Config
+	require.NoError(t, yaml.Unmarshal([]byte(`site-names: "1=Default Web Site"`), &config))
+	require.Equal(t, SiteNames{"1": "Default Web Site"}, config.SiteNames)
+}
@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package iis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"hash"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collectorConfig reports the configuration of the sites and application pools of applicationHost.config.
+// The hash label changes with any attribute of the object or of the defaults it inherits, which allows to
+// detect configuration drift across the servers of a web farm.
+type collectorConfig struct {
+	appPoolConfigInfo *prometheus.Desc
+	siteConfigInfo    *prometheus.Desc
+	siteBindings      *prometheus.Desc
+}
+
+// xmlNode is an element of applicationHost.config with its attributes and child elements.
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []xmlNode  `xml:",any"`
+}
+
+// applicationHostConfigObjects is the subset of applicationHost.config containing the sites and application pools.
+type applicationHostConfigObjects struct {
+	ApplicationPoolDefaults xmlNode   `xml:"system.applicationHost>applicationPools>applicationPoolDefaults"`
+	ApplicationPools        []xmlNode `xml:"system.applicationHost>applicationPools>add"`
+	SiteDefaults            xmlNode   `xml:"system.applicationHost>sites>siteDefaults"`
+	ApplicationDefaults     xmlNode   `xml:"system.applicationHost>sites>applicationDefaults"`
+	Sites                   []xmlNode `xml:"system.applicationHost>sites>site"`
+}
+
+func (n xmlNode) attr(name string) (string, bool) {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// attrOrDefault returns the attribute of n, the attribute of the defaults element or the IIS schema default.
+func (n xmlNode) attrOrDefault(name string, defaults xmlNode, schemaDefault string) string {
+	if value, ok := n.attr(name); ok {
+		return value
+	}
+
+	if value, ok := defaults.attr(name); ok {
+		return value
+	}
+
+	return schemaDefault
+}
+
+func (n xmlNode) children(name string) []xmlNode {
+	children := make([]xmlNode, 0)
+
+	for _, node := range n.Nodes {
+		if node.XMLName.Local == name {
+			children = append(children, node)
+		}
+	}
+
+	return children
+}
+
+// writeTo writes a canonical form of the element to h. Attributes are sorted by name, so the hash doesn't depend
+// on the formatting of applicationHost.config. The order of child elements is kept, as it is significant for
+// collections like bindings.
+func (n xmlNode) writeTo(h hash.Hash) {
+	h.Write([]byte("<" + n.XMLName.Local))
+
+	attrs := slices.Clone(n.Attrs)
+	slices.SortFunc(attrs, func(a, b xml.Attr) int {
+		return strings.Compare(a.Name.Local, b.Name.Local)
+	})
+
+	for _, attr := range attrs {
+		h.Write([]byte(" " + attr.Name.Local + "=" + strconv.Quote(attr.Value)))
+	}
+
+	h.Write([]byte(">"))
+
+	for _, node := range n.Nodes {
+		node.writeTo(h)
+	}
+
+	h.Write([]byte("</" + n.XMLName.Local + ">"))
+}
+
+// configHash returns the first 16 hex digits of the SHA-256 hash of the canonical form of the elements.
+func configHash(nodes ...xmlNode) string {
+	h := sha256.New()
+
+	for _, node := range nodes {
+		node.writeTo(h)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func (c *Collector) buildConfig() error {
+	c.appPoolConfigInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "application_pool_config_info"),
+		"A metric with a constant '1' value labeled with the configuration of the application pool and a hash of all its settings",
+		[]string{"app", "pipeline_mode", "runtime_version", "start_mode", "hash"},
+		nil,
+	)
+	c.siteConfigInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "site_config_info"),
+		"A metric with a constant '1' value labeled with the configuration of the site and a hash of all its settings",
+		[]string{"site", "site_id", "app_pool", "preload_enabled", "hash"},
+		nil,
+	)
+	c.siteBindings = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "site_bindings"),
+		"Number of bindings of the site",
+		[]string{"site"},
+		nil,
+	)
+
+	return nil
+}
+
+func (c *Collector) collectConfig(ch chan<- prometheus.Metric) error {
+	var config applicationHostConfigObjects
+	if err := readApplicationHostConfig(&config); err != nil {
+		return err
+	}
+
+	for _, appPool := range config.ApplicationPools {
+		name, _ := appPool.attr("name")
+		if name == "" || c.config.AppExclude.MatchString(name) || !c.config.AppInclude.MatchString(name) {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.appPoolConfigInfo,
+			prometheus.GaugeValue,
+			1,
+			name,
+			appPool.attrOrDefault("managedPipelineMode", config.ApplicationPoolDefaults, "Integrated"),
+			appPool.attrOrDefault("managedRuntimeVersion", config.ApplicationPoolDefaults, "v4.0"),
+			appPool.attrOrDefault("startMode", config.ApplicationPoolDefaults, "OnDemand"),
+			configHash(config.ApplicationPoolDefaults, appPool),
+		)
+	}
+
+	for _, site := range config.Sites {
+		name, _ := site.attr("name")
+		if name == "" || c.config.SiteExclude.MatchString(name) || !c.config.SiteInclude.MatchString(name) {
+			continue
+		}
+
+		id, _ := site.attr("id")
+
+		// The root application defines the application pool and the preload setting of the site.
+		var root xmlNode
+
+		for _, application := range site.children("application") {
+			if path, _ := application.attr("path"); path == "/" {
+				root = application
+
+				break
+			}
+		}
+
+		var bindings int
+
+		for _, node := range site.children("bindings") {
+			bindings += len(node.children("binding"))
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.siteConfigInfo,
+			prometheus.GaugeValue,
+			1,
+			name,
+			id,
+			root.attrOrDefault("applicationPool", config.ApplicationDefaults, "DefaultAppPool"),
+			root.attrOrDefault("preloadEnabled", config.ApplicationDefaults, "false"),
+			configHash(config.SiteDefaults, config.ApplicationDefaults, site),
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.siteBindings,
+			prometheus.GaugeValue,
+			float64(bindings),
+			name,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package iis
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseConfigObjects(t *testing.T, content string) applicationHostConfigObjects {
+	t.Helper()
+
+	var config applicationHostConfigObjects
+
+	require.NoError(t, xml.Unmarshal([]byte(content), &config))
+
+	return config
+}
+
+func TestConfigHash(t *testing.T) {
+	t.Parallel()
+
+	config := parseConfigObjects(t, `<configuration><system.applicationHost>
+	<applicationPools>
+		<add name="DefaultAppPool" managedPipelineMode="Classic" startMode="AlwaysRunning" />
+		<add name="api" managedRuntimeVersion="" />
+		<applicationPoolDefaults managedRuntimeVersion="v2.0"><processModel identityType="ApplicationPoolIdentity" /></applicationPoolDefaults>
+	</applicationPools>
+	<sites>
+		<site name="Default Web Site" id="1">
+			<application path="/" preloadEnabled="true"><virtualDirectory path="/" physicalPath="C:\inetpub\wwwroot" /></application>
+			<bindings><binding protocol="http" bindingInformation="*:80:" /><binding protocol="https" bindingInformation="*:443:" /></bindings>
+		</site>
+	</sites>
+</system.applicationHost></configuration>`)
+
+	require.Len(t, config.ApplicationPools, 2)
+	require.Equal(t, "Classic", config.ApplicationPools[0].attrOrDefault("managedPipelineMode", config.ApplicationPoolDefaults, "Integrated"))
+	require.Equal(t, "v2.0", config.ApplicationPools[0].attrOrDefault("managedRuntimeVersion", config.ApplicationPoolDefaults, "v4.0"))
+	require.Empty(t, config.ApplicationPools[1].attrOrDefault("managedRuntimeVersion", config.ApplicationPoolDefaults, "v4.0"))
+	require.Equal(t, "Integrated", config.ApplicationPools[1].attrOrDefault("managedPipelineMode", config.ApplicationPoolDefaults, "Integrated"))
+
+	require.Len(t, config.Sites, 1)
+	require.Len(t, config.Sites[0].children("application"), 1)
+	require.Len(t, config.Sites[0].children("bindings")[0].children("binding"), 2)
+
+	// Attribute order and formatting don't change the hash.
+	reordered := parseConfigObjects(t, `<configuration><system.applicationHost><applicationPools>
+		<add startMode="AlwaysRunning"   managedPipelineMode="Classic" name="DefaultAppPool"/>
+	</applicationPools></system.applicationHost></configuration>`)
+	require.Equal(t, configHash(config.ApplicationPools[0]), configHash(reordered.ApplicationPools[0]))
+
+	changed := parseConfigObjects(t, `<configuration><system.applicationHost><applicationPools>
+		<add name="DefaultAppPool" managedPipelineMode="Integrated" startMode="AlwaysRunning" />
+	</applicationPools></system.applicationHost></configuration>`)
+	require.NotEqual(t, configHash(config.ApplicationPools[0]), configHash(changed.ApplicationPools[0]))
+
+	// Changed defaults change the hash of the objects inheriting them.
+	require.NotEqual(t,
+		configHash(config.ApplicationPoolDefaults, config.ApplicationPools[0]),
+		configHash(reordered.ApplicationPoolDefaults, reordered.ApplicationPools[0]),
+	)
+}
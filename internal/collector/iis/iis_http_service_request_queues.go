@@ -34,6 +34,7 @@ type collectorHttpServiceRequestQueues struct {
 	httpRequestQueuesTotalRejectedRequest *prometheus.Desc
 	httpRequestQueuesMaxQueueItemAge      *prometheus.Desc
 	httpRequestQueuesArrivalRate          *prometheus.Desc
+	appPoolQueueLength                    *prometheus.Desc
 }
 
 type perfDataCounterValuesHttpServiceRequestQueues struct {
@@ -83,6 +84,12 @@ func (c *Collector) buildHttpServiceRequestQueues() error {
 		[]string{"site"},
 		nil,
 	)
+	c.appPoolQueueLength = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "application_pool_queue_length"),
+		"Number of requests in the HTTP.sys request queue of the application pool. The queue is kept across worker process recycles.",
+		[]string{"app"},
+		nil,
+	)
 
 	return nil
 }
@@ -131,6 +138,18 @@ func (c *Collector) collectHttpServiceRequestQueues(ch chan<- prometheus.Metric)
 			data.HttpRequestQueuesArrivalRate,
 			data.Name,
 		)
+
+		// IIS names the request queue of an application pool after the application pool.
+		if c.config.AppExclude.MatchString(data.Name) || !c.config.AppInclude.MatchString(data.Name) {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.appPoolQueueLength,
+			prometheus.GaugeValue,
+			data.HttpRequestQueuesCurrentQueueSize,
+			data.Name,
+		)
 	}
 
 	return nil
@@ -0,0 +1,302 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package iis
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	applicationHostConfigPath = `%SystemRoot%\System32\inetsrv\config\applicationHost.config`
+
+	systemChannel = "System"
+
+	// Schannel events logged for failed TLS handshakes.
+	eventIDSchannelCredentialError    = 36871
+	eventIDSchannelNoCommonCipher     = 36874
+	eventIDSchannelFatalAlertReceived = 36887
+	eventIDSchannelFatalAlertSent     = 36888
+)
+
+//nolint:gochecknoglobals
+var (
+	schannelFailureQuery = fmt.Sprintf(
+		"*[System[Provider[@Name='Schannel'] and (EventID=%d or EventID=%d or EventID=%d or EventID=%d) and EventRecordID > %%d]]",
+		eventIDSchannelCredentialError, eventIDSchannelNoCommonCipher, eventIDSchannelFatalAlertReceived, eventIDSchannelFatalAlertSent,
+	)
+
+	// schannelRenderValuePaths are the event properties rendered for each Schannel event.
+	// The order must match the schannelValue* indices.
+	schannelRenderValuePaths = []string{
+		"Event/System/EventRecordID",
+		"Event/System/EventID",
+		"Event/EventData/Data[@Name='AlertDesc']",
+	}
+
+	schannelFailureReasons = map[uint64]string{
+		eventIDSchannelCredentialError:    "credential_error",
+		eventIDSchannelNoCommonCipher:     "no_common_cipher_suite",
+		eventIDSchannelFatalAlertReceived: "alert_received",
+		eventIDSchannelFatalAlertSent:     "alert_sent",
+	}
+)
+
+const (
+	schannelValueEventRecordID = iota
+	schannelValueEventID
+	schannelValueAlertDesc
+)
+
+type tlsHandshakeFailure struct {
+	reason string
+	alert  string
+}
+
+type collectorTLS struct {
+	tlsPerfDataCollector *pdh.Collector
+	tlsPerfDataObject    []perfDataCounterValuesSchannel
+
+	tlsRenderContext wevtapi.EVT_HANDLE
+
+	// tlsMu protects the handshake failure counters and tlsLastRecordID against concurrent scrapes.
+	tlsMu                sync.Mutex
+	tlsLastRecordID      uint64
+	tlsHandshakeFailures map[tlsHandshakeFailure]float64
+
+	tlsHandshakesTotal        *prometheus.Desc
+	tlsHandshakeFailuresTotal *prometheus.Desc
+	tlsSiteBindingInfo        *prometheus.Desc
+}
+
+type perfDataCounterValuesSchannel struct {
+	// Security System-Wide Statistics
+	SSLServerSideFullHandshakes      float64 `perfdata:"SSL Server-Side Full Handshakes"`
+	SSLServerSideReconnectHandshakes float64 `perfdata:"SSL Server-Side Reconnect Handshakes"`
+}
+
+// applicationHostConfig is the subset of applicationHost.config containing the sites and their bindings.
+type applicationHostConfig struct {
+	Sites []struct {
+		ID       string `xml:"id,attr"`
+		Name     string `xml:"name,attr"`
+		Bindings []struct {
+			Protocol           string `xml:"protocol,attr"`
+			BindingInformation string `xml:"bindingInformation,attr"`
+			SSLFlags           uint32 `xml:"sslFlags,attr"`
+		} `xml:"bindings>binding"`
+	} `xml:"system.applicationHost>sites>site"`
+}
+
+func (c *Collector) buildTLS() error {
+	c.tlsHandshakesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "tls_server_handshakes_total"),
+		"Number of server-side TLS handshakes handled by Schannel by type (full, reconnect)",
+		[]string{"type"},
+		nil,
+	)
+	c.tlsHandshakeFailuresTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "tls_handshake_failures_total"),
+		"Number of failed TLS handshakes logged by Schannel by reason and TLS alert code (Schannel events 36871, 36874, 36887 and 36888)",
+		[]string{"reason", "alert"},
+		nil,
+	)
+	c.tlsSiteBindingInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "site_tls_binding_info"),
+		"A metric with a constant '1' value labeled with the HTTPS bindings of the site",
+		[]string{"site", "binding", "sni"},
+		nil,
+	)
+
+	c.tlsHandshakeFailures = make(map[tlsHandshakeFailure]float64)
+
+	var err error
+
+	c.tlsLastRecordID, err = wevtapi.LatestEventRecordID(systemChannel)
+	if err != nil {
+		return fmt.Errorf("failed to read latest event of %s: %w", systemChannel, err)
+	}
+
+	c.tlsRenderContext, err = wevtapi.EvtCreateRenderContext(schannelRenderValuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	c.tlsPerfDataCollector, err = pdh.NewCollector[perfDataCounterValuesSchannel](c.logger, pdh.CounterTypeRaw, "Security System-Wide Statistics", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create Security System-Wide Statistics collector: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectTLS(ch chan<- prometheus.Metric) error {
+	return errors.Join(
+		c.collectTLSHandshakes(ch),
+		c.collectTLSHandshakeFailures(ch),
+		c.collectTLSSiteBindings(ch),
+	)
+}
+
+func (c *Collector) collectTLSHandshakes(ch chan<- prometheus.Metric) error {
+	err := c.tlsPerfDataCollector.Collect(&c.tlsPerfDataObject)
+	if err != nil {
+		return fmt.Errorf("failed to collect Security System-Wide Statistics metrics: %w", err)
+	}
+
+	if len(c.tlsPerfDataObject) == 0 {
+		return fmt.Errorf("failed to collect Security System-Wide Statistics metrics: %w", types.ErrNoDataUnexpected)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.tlsHandshakesTotal,
+		prometheus.CounterValue,
+		c.tlsPerfDataObject[0].SSLServerSideFullHandshakes,
+		"full",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.tlsHandshakesTotal,
+		prometheus.CounterValue,
+		c.tlsPerfDataObject[0].SSLServerSideReconnectHandshakes,
+		"reconnect",
+	)
+
+	return nil
+}
+
+func (c *Collector) collectTLSHandshakeFailures(ch chan<- prometheus.Metric) error {
+	c.tlsMu.Lock()
+	defer c.tlsMu.Unlock()
+
+	query := fmt.Sprintf(schannelFailureQuery, c.tlsLastRecordID)
+
+	if err := wevtapi.QueryValues(systemChannel, query, c.tlsRenderContext, c.handleSchannelEvent); err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", systemChannel, err)
+	}
+
+	for failure, count := range c.tlsHandshakeFailures {
+		ch <- prometheus.MustNewConstMetric(
+			c.tlsHandshakeFailuresTotal,
+			prometheus.CounterValue,
+			count,
+			failure.reason,
+			failure.alert,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) handleSchannelEvent(values []any) {
+	if len(values) != len(schannelRenderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[schannelValueEventRecordID].(uint64)
+	c.tlsLastRecordID = max(c.tlsLastRecordID, recordID)
+
+	eventID, _ := values[schannelValueEventID].(uint64)
+
+	reason, ok := schannelFailureReasons[eventID]
+	if !ok {
+		c.logger.Debug("unexpected event ID", slog.String("event_id", strconv.FormatUint(eventID, 10)))
+
+		return
+	}
+
+	// Only the fatal alert events contain the TLS alert code.
+	var alert string
+
+	switch alertDesc := values[schannelValueAlertDesc].(type) {
+	case uint64:
+		alert = strconv.FormatUint(alertDesc, 10)
+	case int64:
+		alert = strconv.FormatInt(alertDesc, 10)
+	case string:
+		alert = alertDesc
+	}
+
+	c.tlsHandshakeFailures[tlsHandshakeFailure{reason: reason, alert: alert}]++
+}
+
+// collectTLSSiteBindings reports the HTTPS bindings of the sites. Schannel events don't identify the site of
+// a failed handshake; the bindings allow to correlate failures with the sites sharing a certificate or port.
+func (c *Collector) collectTLSSiteBindings(ch chan<- prometheus.Metric) error {
+	var config applicationHostConfig
+	if err := readApplicationHostConfig(&config); err != nil {
+		return err
+	}
+
+	for _, site := range config.Sites {
+		if c.config.SiteExclude.MatchString(site.Name) || !c.config.SiteInclude.MatchString(site.Name) {
+			continue
+		}
+
+		for _, binding := range site.Bindings {
+			if !strings.EqualFold(binding.Protocol, "https") {
+				continue
+			}
+
+			// Bit 0 of sslFlags enables Server Name Indication for the binding.
+			sni := strconv.FormatBool(binding.SSLFlags&1 != 0)
+
+			ch <- prometheus.MustNewConstMetric(
+				c.tlsSiteBindingInfo,
+				prometheus.GaugeValue,
+				1,
+				site.Name,
+				binding.BindingInformation,
+				sni,
+			)
+		}
+	}
+
+	return nil
+}
+
+// readApplicationHostConfig parses applicationHost.config into v.
+func readApplicationHostConfig(v any) error {
+	path, err := registry.ExpandString(applicationHostConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to expand path %s: %w", applicationHostConfigPath, err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := xml.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return nil
+}
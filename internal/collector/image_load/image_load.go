@@ -0,0 +1,369 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package image_load
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	Name = "image_load"
+
+	codeIntegrityChannel = "Microsoft-Windows-CodeIntegrity/Operational"
+	systemChannel        = "System"
+
+	// Code Integrity events of images that were not loaded.
+	eventIDInvalidHash         = 3004
+	eventIDRevoked             = 3023
+	eventIDSigningLevel        = 3033
+	eventIDSigningLevelAudited = 3034
+	eventIDPolicyAudited       = 3076
+	eventIDPolicy              = 3077
+
+	// eventIDDriverLoadFailed is logged by Kernel-PnP if the driver of a device failed to load.
+	eventIDDriverLoadFailed = 219
+
+	reasonInvalidHash  = "invalid_hash"
+	reasonRevoked      = "revoked"
+	reasonSigningLevel = "signing_level"
+	reasonPolicy       = "policy"
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var (
+	codeIntegrityQuery = fmt.Sprintf(
+		"*[System[(EventID=%d or EventID=%d or EventID=%d or EventID=%d or EventID=%d or EventID=%d) and EventRecordID > %%d]]",
+		eventIDInvalidHash, eventIDRevoked, eventIDSigningLevel, eventIDSigningLevelAudited, eventIDPolicyAudited, eventIDPolicy,
+	)
+
+	driverLoadQuery = fmt.Sprintf(
+		"*[System[Provider[@Name='Microsoft-Windows-Kernel-PnP'] and EventID=%d and EventRecordID > %%d]]",
+		eventIDDriverLoadFailed,
+	)
+
+	// codeIntegrityValuePaths are the event properties rendered for each Code Integrity event.
+	// Depending on the event, the image is logged as "File Name" or as "FileNameBuffer".
+	// The order must match the value* indices.
+	codeIntegrityValuePaths = []string{
+		"Event/System/EventRecordID",
+		"Event/System/EventID",
+		"Event/EventData/Data[@Name='File Name']",
+		"Event/EventData/Data[@Name='FileNameBuffer']",
+	}
+
+	// driverLoadValuePaths are the event properties rendered for each Kernel-PnP event.
+	// The order must match the value* indices.
+	driverLoadValuePaths = []string{
+		"Event/System/EventRecordID",
+		"Event/System/EventID",
+		"Event/EventData/Data[@Name='DriverName']",
+		"Event/EventData/Data[@Name='Status']",
+	}
+)
+
+const (
+	valueEventRecordID = iota
+	valueEventID
+	valueFileName
+	valueFileNameBuffer
+)
+
+const (
+	valueDriverName = iota + valueFileName
+	valueStatus
+)
+
+// imageKey identifies the counters of an image.
+type imageKey struct {
+	image  string
+	reason string
+}
+
+// driverKey identifies the counters of a driver.
+type driverKey struct {
+	driver string
+	status string
+}
+
+// eventSource is an event log channel whose events are read incrementally. Events are counted from the start
+// of windows_exporter.
+type eventSource struct {
+	channel       string
+	query         string
+	renderContext wevtapi.EVT_HANDLE
+	lastRecordID  uint64
+	enabled       bool
+}
+
+// A Collector is a Prometheus Collector for failed image and driver loads.
+// Images blocked by Code Integrity, e.g. by a WDAC policy or a missing signature, are read from the
+// Code Integrity event log, drivers that failed to load from the Kernel-PnP events of the System event log.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	// mu protects the counters and the event sources against concurrent scrapes.
+	mu            sync.Mutex
+	codeIntegrity eventSource
+	driverLoad    eventSource
+	blocked       map[imageKey]float64
+	audited       map[imageKey]float64
+	driverFailed  map[driverKey]float64
+
+	blockedTotal      *prometheus.Desc
+	auditedTotal      *prometheus.Desc
+	driverFailedTotal *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	errs := make([]error, 0)
+
+	for _, source := range []*eventSource{&c.codeIntegrity, &c.driverLoad} {
+		if source.renderContext != 0 {
+			errs = append(errs, wevtapi.EvtClose(source.renderContext))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.blockedTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "blocked_total"),
+		"Number of image loads blocked by Code Integrity since windows_exporter started",
+		[]string{"image", "reason"},
+		nil,
+	)
+	c.auditedTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "audited_total"),
+		"Number of image loads that Code Integrity would have blocked in enforcement mode since windows_exporter started",
+		[]string{"image", "reason"},
+		nil,
+	)
+	c.driverFailedTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "driver_failures_total"),
+		"Number of device drivers that failed to load since windows_exporter started",
+		[]string{"driver", "status"},
+		nil,
+	)
+
+	c.blocked = make(map[imageKey]float64)
+	c.audited = make(map[imageKey]float64)
+	c.driverFailed = make(map[driverKey]float64)
+
+	c.codeIntegrity = eventSource{channel: codeIntegrityChannel, query: codeIntegrityQuery}
+	c.driverLoad = eventSource{channel: systemChannel, query: driverLoadQuery}
+
+	if err := c.buildSource(&c.codeIntegrity, codeIntegrityValuePaths); err != nil {
+		return err
+	}
+
+	return c.buildSource(&c.driverLoad, driverLoadValuePaths)
+}
+
+func (c *Collector) buildSource(source *eventSource, valuePaths []string) error {
+	lastRecordID, err := wevtapi.LatestEventRecordID(source.channel)
+	if err != nil {
+		if errors.Is(err, wevtapi.ERROR_EVT_CHANNEL_NOT_FOUND) {
+			c.logger.Debug("event log not found, skipping its metrics",
+				slog.String("channel", source.channel),
+			)
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to read latest event of %s: %w", source.channel, err)
+	}
+
+	source.lastRecordID = lastRecordID
+
+	source.renderContext, err = wevtapi.EvtCreateRenderContext(valuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	source.enabled = true
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	errs := make([]error, 0)
+
+	if err := c.readEvents(&c.codeIntegrity, c.handleCodeIntegrityEvent); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := c.readEvents(&c.driverLoad, c.handleDriverLoadEvent); err != nil {
+		errs = append(errs, err)
+	}
+
+	for key, count := range c.blocked {
+		ch <- prometheus.MustNewConstMetric(
+			c.blockedTotal,
+			prometheus.CounterValue,
+			count,
+			key.image, key.reason,
+		)
+	}
+
+	for key, count := range c.audited {
+		ch <- prometheus.MustNewConstMetric(
+			c.auditedTotal,
+			prometheus.CounterValue,
+			count,
+			key.image, key.reason,
+		)
+	}
+
+	for key, count := range c.driverFailed {
+		ch <- prometheus.MustNewConstMetric(
+			c.driverFailedTotal,
+			prometheus.CounterValue,
+			count,
+			key.driver, key.status,
+		)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) readEvents(source *eventSource, handle func(values []any) uint64) error {
+	if !source.enabled {
+		return nil
+	}
+
+	query := fmt.Sprintf(source.query, source.lastRecordID)
+
+	err := wevtapi.QueryValues(source.channel, query, source.renderContext, func(values []any) {
+		source.lastRecordID = max(source.lastRecordID, handle(values))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", source.channel, err)
+	}
+
+	return nil
+}
+
+// handleCodeIntegrityEvent counts the event and returns its record ID.
+func (c *Collector) handleCodeIntegrityEvent(values []any) uint64 {
+	if len(values) != len(codeIntegrityValuePaths) {
+		return 0
+	}
+
+	recordID, _ := values[valueEventRecordID].(uint64)
+	eventID, _ := values[valueEventID].(uint64)
+
+	fileName, _ := values[valueFileName].(string)
+	if fileName == "" {
+		fileName, _ = values[valueFileNameBuffer].(string)
+	}
+
+	image := imageName(fileName)
+
+	switch eventID {
+	case eventIDInvalidHash:
+		c.blocked[imageKey{image: image, reason: reasonInvalidHash}]++
+	case eventIDRevoked:
+		c.blocked[imageKey{image: image, reason: reasonRevoked}]++
+	case eventIDSigningLevel:
+		c.blocked[imageKey{image: image, reason: reasonSigningLevel}]++
+	case eventIDPolicy:
+		c.blocked[imageKey{image: image, reason: reasonPolicy}]++
+	case eventIDSigningLevelAudited:
+		c.audited[imageKey{image: image, reason: reasonSigningLevel}]++
+	case eventIDPolicyAudited:
+		c.audited[imageKey{image: image, reason: reasonPolicy}]++
+	default:
+		c.logger.Debug("unexpected event ID", slog.String("event_id", strconv.FormatUint(eventID, 10)))
+	}
+
+	return recordID
+}
+
+// handleDriverLoadEvent counts the event and returns its record ID.
+func (c *Collector) handleDriverLoadEvent(values []any) uint64 {
+	if len(values) != len(driverLoadValuePaths) {
+		return 0
+	}
+
+	recordID, _ := values[valueEventRecordID].(uint64)
+
+	driver, _ := values[valueDriverName].(string)
+	// The driver is logged as object name, e.g. \Driver\WUDFRd.
+	driver = driver[strings.LastIndex(driver, `\`)+1:]
+
+	status, _ := values[valueStatus].(uint64)
+
+	c.driverFailed[driverKey{driver: driver, status: fmt.Sprintf("0x%08x", status)}]++
+
+	return recordID
+}
+
+// imageName returns the lower case file name of the image path, e.g. foo.sys for
+// \Device\HarddiskVolume3\Windows\System32\drivers\Foo.sys. The same image is logged with different casing.
+func imageName(path string) string {
+	return strings.ToLower(path[strings.LastIndexAny(path, `\/`)+1:])
+}
@@ -0,0 +1,373 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package job_object
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"unsafe"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/kernel32"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	Name = "job_object"
+
+	// jobObjectMemoryUsageInformation is an undocumented information class, also used by hcsshim.
+	jobObjectMemoryUsageInformation = 28
+
+	// cpuRateScale is the unit of the CPU rate, 1/100 of a percent.
+	cpuRateScale = 10000
+)
+
+type Config struct {
+	JobObjectInclude *regexp.Regexp `yaml:"include"`
+	JobObjectExclude *regexp.Regexp `yaml:"exclude"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	JobObjectInclude: types.RegExpAny,
+	JobObjectExclude: types.RegExpEmpty,
+}
+
+// A Collector is a Prometheus Collector for named job objects.
+// Job objects are enumerated through the Job Object performance counters. Accounting information and limits are
+// queried from the job object itself, which requires windows_exporter to be allowed to open it.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	perfDataCollector *pdh.Collector
+	perfDataObject    []perfDataCounterValues
+
+	activeProcesses     *prometheus.Desc
+	terminatedProcesses *prometheus.Desc
+	cpuTimeTotal        *prometheus.Desc
+	cpuRateLimit        *prometheus.Desc
+	memoryLimit         *prometheus.Desc
+	memoryUsage         *prometheus.Desc
+	memoryPeak          *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.JobObjectExclude == nil {
+		config.JobObjectExclude = ConfigDefaults.JobObjectExclude
+	}
+
+	if config.JobObjectInclude == nil {
+		config.JobObjectInclude = ConfigDefaults.JobObjectInclude
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	var jobObjectExclude, jobObjectInclude string
+
+	app.Flag(
+		"collector.job_object.exclude",
+		"Regexp of job objects to exclude. Job object name must both match include and not match exclude to be included.",
+	).Default("").StringVar(&jobObjectExclude)
+
+	app.Flag(
+		"collector.job_object.include",
+		"Regexp of job objects to include. Job object name must both match include and not match exclude to be included.",
+	).Default(".+").StringVar(&jobObjectInclude)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		var err error
+
+		c.config.JobObjectExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", jobObjectExclude))
+		if err != nil {
+			return fmt.Errorf("collector.job_object.exclude: %w", err)
+		}
+
+		c.config.JobObjectInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", jobObjectInclude))
+		if err != nil {
+			return fmt.Errorf("collector.job_object.include: %w", err)
+		}
+
+		return nil
+	})
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	c.perfDataCollector.Close()
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.activeProcesses = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "active_processes"),
+		"Number of processes currently associated with the job object",
+		[]string{"job_object"},
+		nil,
+	)
+	c.terminatedProcesses = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "terminated_processes_total"),
+		"Number of processes of the job object that were terminated because of a limit violation",
+		[]string{"job_object"},
+		nil,
+	)
+	c.cpuTimeTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cpu_time_total"),
+		"CPU time used by all processes that were ever associated with the job object by mode (privileged, user)",
+		[]string{"job_object", "mode"},
+		nil,
+	)
+	c.cpuRateLimit = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cpu_rate_limit_ratio"),
+		"Maximum share of the processor time the job object may use per scheduling interval. Only reported if CPU rate control is enabled",
+		[]string{"job_object", "hard_cap"},
+		nil,
+	)
+	c.memoryLimit = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "memory_limit_bytes"),
+		"Commit limit of the job object (job) or of each of its processes (process). Only reported if the limit is set",
+		[]string{"job_object", "scope"},
+		nil,
+	)
+	c.memoryUsage = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "memory_usage_bytes"),
+		"Committed memory of all processes of the job object",
+		[]string{"job_object"},
+		nil,
+	)
+	c.memoryPeak = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "memory_peak_bytes"),
+		"Peak committed memory of all processes of the job object",
+		[]string{"job_object"},
+		nil,
+	)
+
+	var err error
+
+	c.perfDataCollector, err = pdh.NewCollector[perfDataCounterValues](c.logger, pdh.CounterTypeRaw, "Job Object", pdh.InstancesAll)
+	if err != nil {
+		return fmt.Errorf("failed to create Job Object collector: %w", err)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	err := c.perfDataCollector.Collect(&c.perfDataObject)
+	if err != nil {
+		if errors.Is(err, pdh.ErrNoData) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to collect Job Object metrics: %w", err)
+	}
+
+	for _, data := range c.perfDataObject {
+		if c.config.JobObjectExclude.MatchString(data.Name) || !c.config.JobObjectInclude.MatchString(data.Name) {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.activeProcesses,
+			prometheus.GaugeValue,
+			data.ProcessCountActive,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.terminatedProcesses,
+			prometheus.CounterValue,
+			data.ProcessCountTerminated,
+			data.Name,
+		)
+
+		if err := c.collectJobObject(ch, data.Name); err != nil {
+			c.logger.Debug("failed to query job object",
+				slog.String("job_object", data.Name),
+				slog.Any("err", err),
+			)
+		}
+	}
+
+	return nil
+}
+
+// collectJobObject reports the accounting information and limits of the job object.
+// Job objects created by services usually live in the global namespace, so both namespaces are tried.
+func (c *Collector) collectJobObject(ch chan<- prometheus.Metric, name string) error {
+	handle, err := kernel32.OpenJobObject(name)
+	if errors.Is(err, windows.ERROR_FILE_NOT_FOUND) {
+		handle, err = kernel32.OpenJobObject(`Global\` + name)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open job object: %w", err)
+	}
+
+	defer func(handle windows.Handle) {
+		_ = windows.CloseHandle(handle)
+	}(handle)
+
+	var accountingInfo kernel32.JobObjectBasicAccountingInformation
+
+	if err = windows.QueryInformationJobObject(
+		handle,
+		windows.JobObjectBasicAccountingInformation,
+		uintptr(unsafe.Pointer(&accountingInfo)),
+		uint32(unsafe.Sizeof(accountingInfo)),
+		nil,
+	); err != nil {
+		return fmt.Errorf("failed to query accounting information: %w", err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.cpuTimeTotal,
+		prometheus.CounterValue,
+		float64(accountingInfo.TotalKernelTime)*pdh.TicksToSecondScaleFactor,
+		name, "privileged",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.cpuTimeTotal,
+		prometheus.CounterValue,
+		float64(accountingInfo.TotalUserTime)*pdh.TicksToSecondScaleFactor,
+		name, "user",
+	)
+
+	var limitInfo windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION
+
+	if err = windows.QueryInformationJobObject(
+		handle,
+		windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&limitInfo)),
+		uint32(unsafe.Sizeof(limitInfo)),
+		nil,
+	); err != nil {
+		return fmt.Errorf("failed to query limit information: %w", err)
+	}
+
+	if limitInfo.BasicLimitInformation.LimitFlags&windows.JOB_OBJECT_LIMIT_JOB_MEMORY != 0 {
+		ch <- prometheus.MustNewConstMetric(
+			c.memoryLimit,
+			prometheus.GaugeValue,
+			float64(limitInfo.JobMemoryLimit),
+			name, "job",
+		)
+	}
+
+	if limitInfo.BasicLimitInformation.LimitFlags&windows.JOB_OBJECT_LIMIT_PROCESS_MEMORY != 0 {
+		ch <- prometheus.MustNewConstMetric(
+			c.memoryLimit,
+			prometheus.GaugeValue,
+			float64(limitInfo.ProcessMemoryLimit),
+			name, "process",
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.memoryPeak,
+		prometheus.GaugeValue,
+		float64(limitInfo.PeakJobMemoryUsed),
+		name,
+	)
+
+	var memoryInfo kernel32.JobObjectMemoryUsageInformation
+
+	if err = windows.QueryInformationJobObject(
+		handle,
+		jobObjectMemoryUsageInformation,
+		uintptr(unsafe.Pointer(&memoryInfo)),
+		uint32(unsafe.Sizeof(memoryInfo)),
+		nil,
+	); err == nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.memoryUsage,
+			prometheus.GaugeValue,
+			float64(memoryInfo.JobMemory),
+			name,
+		)
+	}
+
+	var rateInfo kernel32.JobObjectCPURateControlInformation
+
+	if err = windows.QueryInformationJobObject(
+		handle,
+		windows.JobObjectCpuRateControlInformation,
+		uintptr(unsafe.Pointer(&rateInfo)),
+		uint32(unsafe.Sizeof(rateInfo)),
+		nil,
+	); err != nil {
+		return fmt.Errorf("failed to query CPU rate control information: %w", err)
+	}
+
+	// Weight based rate control has no absolute limit.
+	if rateInfo.ControlFlags&kernel32.JobObjectCPURateControlEnable == 0 ||
+		rateInfo.ControlFlags&kernel32.JobObjectCPURateControlWeightBased != 0 {
+		return nil
+	}
+
+	rate := rateInfo.Rate
+	if rateInfo.ControlFlags&kernel32.JobObjectCPURateControlMinMaxRate != 0 {
+		rate = uint32(rateInfo.MaxRate())
+	}
+
+	hardCap := "false"
+	if rateInfo.ControlFlags&kernel32.JobObjectCPURateControlHardCap != 0 ||
+		rateInfo.ControlFlags&kernel32.JobObjectCPURateControlMinMaxRate != 0 {
+		hardCap = "true"
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.cpuRateLimit,
+		prometheus.GaugeValue,
+		float64(rate)/cpuRateScale,
+		name, hardCap,
+	)
+
+	return nil
+}
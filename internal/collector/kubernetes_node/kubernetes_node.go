@@ -0,0 +1,388 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package kubernetes_node
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/sysinfoapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	Name = "kubernetes_node"
+
+	unitCore = "core"
+	unitByte = "byte"
+
+	reservationSystem   = "system"
+	reservationKube     = "kube"
+	reservationEviction = "eviction"
+)
+
+type Config struct {
+	KubeletConfigFile string `yaml:"kubelet-config-file"`
+	SystemReserved    string `yaml:"system-reserved"`
+	KubeReserved      string `yaml:"kube-reserved"`
+	EvictionHard      string `yaml:"eviction-hard"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	KubeletConfigFile: "",
+	SystemReserved:    "",
+	KubeReserved:      "",
+	// The default hard eviction threshold of the kubelet on Windows.
+	EvictionHard: "memory.available<500Mi",
+}
+
+// kubeletConfiguration are the reservations of the KubeletConfiguration file of the kubelet.
+// 📑 https://kubernetes.io/docs/reference/config-api/kubelet-config.v1beta1/
+type kubeletConfiguration struct {
+	SystemReserved map[string]string `yaml:"systemReserved"`
+	KubeReserved   map[string]string `yaml:"kubeReserved"`
+	EvictionHard   map[string]string `yaml:"evictionHard"`
+}
+
+// reservations are the resources the kubelet withholds from the pods.
+type reservations struct {
+	system   resources
+	kube     resources
+	eviction evictionThreshold
+}
+
+// A Collector is a Prometheus Collector for the capacity, the allocatable and the used resources of a Windows node
+// of Kubernetes. The values follow the accounting of the kubelet on Windows, so dashboards of Windows nodes match
+// the dashboards of Linux nodes:
+//
+//	allocatable = capacity - system-reserved - kube-reserved - hard eviction threshold
+//
+// The used memory is the private working set of all processes, which the kubelet reports as working set of the node
+// and which the memory.available eviction signal is based on.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	// reservations are parsed from the flags. They are replaced by the kubelet configuration file on each scrape, if configured.
+	reservations reservations
+
+	perfDataCollectorProcessor *pdh.Collector
+	perfDataObjectProcessor    []perfDataCounterValuesProcessor
+	perfDataCollectorProcess   *pdh.Collector
+	perfDataObjectProcess      []perfDataCounterValuesProcess
+
+	capacity               *prometheus.Desc
+	allocatable            *prometheus.Desc
+	reserved               *prometheus.Desc
+	cpuUsageSecondsTotal   *prometheus.Desc
+	memoryWorkingSetBytes  *prometheus.Desc
+	memoryAvailableBytes   *prometheus.Desc
+	memoryAllocatableRatio *prometheus.Desc
+	memoryEvictionExceeded *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	app.Flag(
+		"collector.kubernetes_node.kubelet-config-file",
+		"Path of the KubeletConfiguration file of the kubelet, e.g. C:\\var\\lib\\kubelet\\config.yaml. If set, the reservations are read from the file instead of the reservation flags.",
+	).Default(ConfigDefaults.KubeletConfigFile).StringVar(&c.config.KubeletConfigFile)
+
+	app.Flag(
+		"collector.kubernetes_node.system-reserved",
+		"Resources reserved for the operating system, like the --system-reserved flag of the kubelet, e.g. cpu=500m,memory=1Gi.",
+	).Default(ConfigDefaults.SystemReserved).StringVar(&c.config.SystemReserved)
+
+	app.Flag(
+		"collector.kubernetes_node.kube-reserved",
+		"Resources reserved for the Kubernetes node components, like the --kube-reserved flag of the kubelet, e.g. cpu=500m,memory=1Gi.",
+	).Default(ConfigDefaults.KubeReserved).StringVar(&c.config.KubeReserved)
+
+	app.Flag(
+		"collector.kubernetes_node.eviction-hard",
+		"Hard eviction thresholds, like the --eviction-hard flag of the kubelet. Only memory.available is taken into account.",
+	).Default(ConfigDefaults.EvictionHard).StringVar(&c.config.EvictionHard)
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	c.perfDataCollectorProcessor.Close()
+	c.perfDataCollectorProcess.Close()
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	var err error
+
+	if c.reservations.system, err = parseResources(c.config.SystemReserved); err != nil {
+		return fmt.Errorf("invalid system-reserved: %w", err)
+	}
+
+	if c.reservations.kube, err = parseResources(c.config.KubeReserved); err != nil {
+		return fmt.Errorf("invalid kube-reserved: %w", err)
+	}
+
+	if c.reservations.eviction, err = parseEvictionHard(c.config.EvictionHard); err != nil {
+		return fmt.Errorf("invalid eviction-hard: %w", err)
+	}
+
+	c.capacity = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "capacity"),
+		"Capacity of the node by resource, like kube_node_status_capacity",
+		[]string{"resource", "unit"},
+		nil,
+	)
+	c.allocatable = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "allocatable"),
+		"Resources of the node allocatable by pods, like kube_node_status_allocatable. The capacity minus the reserved resources",
+		[]string{"resource", "unit"},
+		nil,
+	)
+	c.reserved = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "reserved"),
+		"Resources of the node reserved by the kubelet by type (system, kube, eviction)",
+		[]string{"resource", "unit", "type"},
+		nil,
+	)
+	c.cpuUsageSecondsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cpu_usage_seconds_total"),
+		"Cumulative CPU time of the node in core-seconds, like node_cpu_usage_seconds_total of the kubelet",
+		nil,
+		nil,
+	)
+	c.memoryWorkingSetBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "memory_working_set_bytes"),
+		"Private working set of all processes of the node, like node_memory_working_set_bytes of the kubelet on Windows",
+		nil,
+		nil,
+	)
+	c.memoryAvailableBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "memory_available_bytes"),
+		"Memory capacity minus the working set, which the kubelet compares against the memory.available eviction threshold",
+		nil,
+		nil,
+	)
+	c.memoryAllocatableRatio = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "memory_allocatable_utilization_ratio"),
+		"Working set of the node divided by the allocatable memory. Values above 1 mean that the reserved memory is used",
+		nil,
+		nil,
+	)
+	c.memoryEvictionExceeded = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "memory_eviction_threshold_exceeded"),
+		"1 if the available memory is below the memory.available hard eviction threshold, so the kubelet evicts pods",
+		nil,
+		nil,
+	)
+
+	c.perfDataCollectorProcessor, err = pdh.NewCollector[perfDataCounterValuesProcessor](c.logger, pdh.CounterTypeRaw, "Processor Information", pdh.InstancesAll)
+	if err != nil {
+		return fmt.Errorf("failed to create Processor Information collector: %w", err)
+	}
+
+	c.perfDataCollectorProcess, err = pdh.NewCollector[perfDataCounterValuesProcess](c.logger, pdh.CounterTypeRaw, "Process", pdh.InstancesTotal)
+	if err != nil {
+		return fmt.Errorf("failed to create Process collector: %w", err)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	reserved, err := c.currentReservations()
+	if err != nil {
+		return err
+	}
+
+	if err := c.perfDataCollectorProcessor.Collect(&c.perfDataObjectProcessor); err != nil {
+		return fmt.Errorf("failed to collect Processor Information metrics: %w", err)
+	}
+
+	if err := c.perfDataCollectorProcess.Collect(&c.perfDataObjectProcess); err != nil {
+		return fmt.Errorf("failed to collect Process metrics: %w", err)
+	}
+
+	if len(c.perfDataObjectProcess) == 0 {
+		return errors.New("perflib query for Process (_Total) returned empty result set")
+	}
+
+	memoryStatus, err := sysinfoapi.GlobalMemoryStatusEx()
+	if err != nil {
+		return fmt.Errorf("failed to get memory status: %w", err)
+	}
+
+	var cpuSeconds float64
+	for _, processor := range c.perfDataObjectProcessor {
+		// The privileged time includes the interrupt and DPC time.
+		cpuSeconds += processor.PrivilegedTimeSeconds + processor.UserTimeSeconds
+	}
+
+	cpuCapacity := float64(len(c.perfDataObjectProcessor))
+	memoryCapacity := float64(memoryStatus.TotalPhys)
+	evictionMemory := reserved.eviction.bytesOf(memoryCapacity)
+
+	cpuAllocatable := max(cpuCapacity-reserved.system.cpu-reserved.kube.cpu, 0)
+	memoryAllocatable := max(memoryCapacity-reserved.system.memory-reserved.kube.memory-evictionMemory, 0)
+
+	workingSet := c.perfDataObjectProcess[0].WorkingSetPrivate
+	memoryAvailable := max(memoryCapacity-workingSet, 0)
+
+	for _, metric := range []struct {
+		desc     *prometheus.Desc
+		value    float64
+		resource string
+		unit     string
+	}{
+		{c.capacity, cpuCapacity, resourceCPU, unitCore},
+		{c.capacity, memoryCapacity, resourceMemory, unitByte},
+		{c.allocatable, cpuAllocatable, resourceCPU, unitCore},
+		{c.allocatable, memoryAllocatable, resourceMemory, unitByte},
+	} {
+		ch <- prometheus.MustNewConstMetric(
+			metric.desc,
+			prometheus.GaugeValue,
+			metric.value,
+			metric.resource, metric.unit,
+		)
+	}
+
+	for _, metric := range []struct {
+		value           float64
+		resource        string
+		unit            string
+		reservationType string
+	}{
+		{reserved.system.cpu, resourceCPU, unitCore, reservationSystem},
+		{reserved.kube.cpu, resourceCPU, unitCore, reservationKube},
+		{reserved.system.memory, resourceMemory, unitByte, reservationSystem},
+		{reserved.kube.memory, resourceMemory, unitByte, reservationKube},
+		{evictionMemory, resourceMemory, unitByte, reservationEviction},
+	} {
+		ch <- prometheus.MustNewConstMetric(
+			c.reserved,
+			prometheus.GaugeValue,
+			metric.value,
+			metric.resource, metric.unit, metric.reservationType,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.cpuUsageSecondsTotal,
+		prometheus.CounterValue,
+		cpuSeconds,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.memoryWorkingSetBytes,
+		prometheus.GaugeValue,
+		workingSet,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.memoryAvailableBytes,
+		prometheus.GaugeValue,
+		memoryAvailable,
+	)
+
+	if memoryAllocatable > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			c.memoryAllocatableRatio,
+			prometheus.GaugeValue,
+			workingSet/memoryAllocatable,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.memoryEvictionExceeded,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(evictionMemory > 0 && memoryAvailable < evictionMemory),
+	)
+
+	return nil
+}
+
+// currentReservations returns the reservations of the kubelet configuration file, if configured, or of the flags.
+// The file is read on each scrape, so a changed configuration is picked up once the kubelet restarted.
+func (c *Collector) currentReservations() (reservations, error) {
+	if c.config.KubeletConfigFile == "" {
+		return c.reservations, nil
+	}
+
+	data, err := os.ReadFile(c.config.KubeletConfigFile)
+	if err != nil {
+		return reservations{}, fmt.Errorf("failed to read kubelet configuration: %w", err)
+	}
+
+	var config kubeletConfiguration
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return reservations{}, fmt.Errorf("failed to parse kubelet configuration %s: %w", c.config.KubeletConfigFile, err)
+	}
+
+	var reserved reservations
+
+	if reserved.system, err = resourcesOf(config.SystemReserved); err != nil {
+		return reservations{}, fmt.Errorf("invalid systemReserved in %s: %w", c.config.KubeletConfigFile, err)
+	}
+
+	if reserved.kube, err = resourcesOf(config.KubeReserved); err != nil {
+		return reservations{}, fmt.Errorf("invalid kubeReserved in %s: %w", c.config.KubeletConfigFile, err)
+	}
+
+	// If the configuration doesn't set evictionHard, the kubelet applies its default, which the eviction-hard flag stands for.
+	if config.EvictionHard == nil {
+		reserved.eviction = c.reservations.eviction
+	} else if reserved.eviction, err = evictionThresholdOf(config.EvictionHard); err != nil {
+		return reservations{}, fmt.Errorf("invalid evictionHard in %s: %w", c.config.KubeletConfigFile, err)
+	}
+
+	return reserved, nil
+}
@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package kubernetes_node
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	resourceCPU    = "cpu"
+	resourceMemory = "memory"
+
+	// signalMemoryAvailable is the eviction signal of the available memory of the node.
+	signalMemoryAvailable = "memory.available"
+)
+
+var errInvalidQuantity = errors.New("invalid quantity")
+
+// quantitySuffixes are the multipliers of the suffixes of Kubernetes resource quantities.
+// 📑 https://kubernetes.io/docs/reference/kubernetes-api/common-definitions/quantity/
+//
+//nolint:gochecknoglobals
+var quantitySuffixes = map[string]float64{
+	"n": 1e-9, "u": 1e-6, "m": 1e-3, "": 1,
+	"k": 1e3, "M": 1e6, "G": 1e9, "T": 1e12, "P": 1e15, "E": 1e18,
+	"Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30, "Ti": 1 << 40, "Pi": 1 << 50, "Ei": 1 << 60,
+}
+
+// resources are the CPU cores and the memory bytes of a reservation.
+type resources struct {
+	cpu    float64
+	memory float64
+}
+
+// evictionThreshold is the memory.available threshold of the hard eviction, either in bytes or as a fraction of the capacity.
+type evictionThreshold struct {
+	bytes    float64
+	fraction float64
+}
+
+// bytesOf returns the threshold in bytes for the given memory capacity.
+func (t evictionThreshold) bytesOf(capacity float64) float64 {
+	if t.fraction > 0 {
+		return t.fraction * capacity
+	}
+
+	return t.bytes
+}
+
+// parseQuantity parses a Kubernetes resource quantity, e.g. 500m, 1.5Gi or 2e9.
+func parseQuantity(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+
+	number := strings.TrimRightFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	suffix := s[len(number):]
+
+	// Decimal exponents, e.g. 2e9, are parsed as part of the number.
+	multiplier, ok := quantitySuffixes[suffix]
+	if !ok {
+		return 0, fmt.Errorf("%w %q: unknown suffix %q", errInvalidQuantity, s, suffix)
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("%w %q", errInvalidQuantity, s)
+	}
+
+	return value * multiplier, nil
+}
+
+// parseResources parses the CPU and memory of a reservation, e.g. the value of
+// the --system-reserved flag of the kubelet: cpu=500m,memory=1Gi. Other resources, e.g. ephemeral-storage, are ignored.
+func parseResources(s string) (resources, error) {
+	values := make(map[string]string)
+
+	for pair := range strings.SplitSeq(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return resources{}, fmt.Errorf("invalid resource %q, expected <name>=<quantity>", pair)
+		}
+
+		values[strings.TrimSpace(name)] = value
+	}
+
+	return resourcesOf(values)
+}
+
+// resourcesOf parses the CPU and memory of a reservation by resource name, e.g. systemReserved of the kubelet configuration.
+func resourcesOf(values map[string]string) (resources, error) {
+	var (
+		reserved resources
+		err      error
+	)
+
+	if value, ok := values[resourceCPU]; ok {
+		if reserved.cpu, err = parseQuantity(value); err != nil {
+			return resources{}, err
+		}
+	}
+
+	if value, ok := values[resourceMemory]; ok {
+		if reserved.memory, err = parseQuantity(value); err != nil {
+			return resources{}, err
+		}
+	}
+
+	return reserved, nil
+}
+
+// parseEvictionHard parses the memory.available threshold of the hard eviction, e.g. the value of
+// the --eviction-hard flag of the kubelet: memory.available<500Mi,nodefs.available<10%. Other signals are ignored.
+func parseEvictionHard(s string) (evictionThreshold, error) {
+	values := make(map[string]string)
+
+	for threshold := range strings.SplitSeq(s, ",") {
+		if strings.TrimSpace(threshold) == "" {
+			continue
+		}
+
+		signal, value, ok := strings.Cut(threshold, "<")
+		if !ok {
+			return evictionThreshold{}, fmt.Errorf("invalid eviction threshold %q, expected <signal><<quantity>", threshold)
+		}
+
+		values[strings.TrimSpace(signal)] = value
+	}
+
+	return evictionThresholdOf(values)
+}
+
+// evictionThresholdOf parses the memory.available threshold of the hard eviction by signal, e.g. evictionHard of the kubelet configuration.
+func evictionThresholdOf(values map[string]string) (evictionThreshold, error) {
+	value, ok := values[signalMemoryAvailable]
+	if !ok {
+		return evictionThreshold{}, nil
+	}
+
+	if percent, ok := strings.CutSuffix(strings.TrimSpace(value), "%"); ok {
+		fraction, err := strconv.ParseFloat(percent, 64)
+		if err != nil || fraction < 0 || fraction > 100 {
+			return evictionThreshold{}, fmt.Errorf("invalid eviction threshold %q", value)
+		}
+
+		return evictionThreshold{fraction: fraction / 100}, nil
+	}
+
+	bytes, err := parseQuantity(value)
+	if err != nil {
+		return evictionThreshold{}, err
+	}
+
+	return evictionThreshold{bytes: bytes}, nil
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package kubernetes_node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuantity(t *testing.T) {
+	t.Parallel()
+
+	for input, expected := range map[string]float64{
+		"2":     2,
+		"500m":  0.5,
+		"1.5Gi": 1.5 * (1 << 30),
+		"500Mi": 500 * (1 << 20),
+		"1G":    1e9,
+		"2e9":   2e9,
+	} {
+		value, err := parseQuantity(input)
+		require.NoError(t, err, input)
+		require.InDelta(t, expected, value, 1e-9, input)
+	}
+
+	for _, input := range []string{"", "1Xi", "-1", "Gi"} {
+		_, err := parseQuantity(input)
+		require.ErrorIs(t, err, errInvalidQuantity, input)
+	}
+}
+
+func TestParseReservations(t *testing.T) {
+	t.Parallel()
+
+	reserved, err := parseResources("cpu=500m, memory=2Gi,ephemeral-storage=1Gi")
+	require.NoError(t, err)
+	require.Equal(t, resources{cpu: 0.5, memory: 2 << 30}, reserved)
+
+	_, err = parseResources("cpu")
+	require.Error(t, err)
+
+	threshold, err := parseEvictionHard("memory.available<500Mi,nodefs.available<10%")
+	require.NoError(t, err)
+	require.InDelta(t, 500<<20, threshold.bytesOf(8<<30), 0)
+
+	threshold, err = parseEvictionHard("memory.available<10%")
+	require.NoError(t, err)
+	require.InDelta(t, 0.8*(1<<30), threshold.bytesOf(8<<30), 1)
+}
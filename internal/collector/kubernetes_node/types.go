@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package kubernetes_node
+
+type perfDataCounterValuesProcessor struct {
+	Name string
+
+	PrivilegedTimeSeconds float64 `perfdata:"% Privileged Time"` // \Processor Information(*)\% Privileged Time
+	UserTimeSeconds       float64 `perfdata:"% User Time"`       // \Processor Information(*)\% User Time
+}
+
+type perfDataCounterValuesProcess struct {
+	WorkingSetPrivate float64 `perfdata:"Working Set - Private"` // \Process(_Total)\Working Set - Private
+}
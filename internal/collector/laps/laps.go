@@ -0,0 +1,328 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package laps
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/netapi32"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "laps"
+
+	// stateKey is written by Windows LAPS after each password rotation.
+	stateKey = `SOFTWARE\Microsoft\Windows\CurrentVersion\LAPS\State`
+
+	defaultPasswordAgeDays = 30
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+// policySources are the locations of the LAPS policy in the order Windows LAPS evaluates them.
+// The first location that contains a policy is in effect.
+//
+//nolint:gochecknoglobals
+var policySources = []policySource{
+	{name: "csp", key: `SOFTWARE\Microsoft\Policies\LAPS`},
+	{name: "group_policy", key: `SOFTWARE\Microsoft\Windows\CurrentVersion\Policies\LAPS`},
+	{name: "local", key: `SOFTWARE\Microsoft\Windows\CurrentVersion\LAPS\Config`},
+	{name: "legacy", key: `SOFTWARE\Policies\Microsoft Services\AdmPwd`, legacy: true},
+}
+
+//nolint:gochecknoglobals
+var backupDirectories = map[uint64]string{
+	0: "disabled",
+	1: "entra_id",
+	2: "active_directory",
+}
+
+// A Collector is a Prometheus Collector for Windows LAPS and legacy Microsoft LAPS.
+// The policy and the last rotation are read from the local registry, the password age of the managed account
+// from the local account database, so no directory query is required.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	policyInfo         *prometheus.Desc
+	passwordMaxAge     *prometheus.Desc
+	lastPasswordUpdate *prometheus.Desc
+	passwordCompliant  *prometheus.Desc
+}
+
+type policySource struct {
+	name   string
+	key    string
+	legacy bool
+}
+
+type policy struct {
+	source          string
+	backupDirectory string
+	accountName     string
+	maxAge          time.Duration
+	legacy          bool
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.policyInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "policy_info"),
+		"LAPS policy in effect and the directory the password is backed up to",
+		[]string{"source", "backup_directory"},
+		nil,
+	)
+	c.passwordMaxAge = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "password_max_age_seconds"),
+		"Maximum age of the managed account password configured by the LAPS policy",
+		nil,
+		nil,
+	)
+	c.lastPasswordUpdate = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "last_password_update_timestamp_seconds"),
+		"Time of the last password rotation of the managed account as unix timestamp",
+		[]string{"account"},
+		nil,
+	)
+	c.passwordCompliant = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "password_compliant"),
+		"1 if the password of the managed account is younger than the maximum age of the LAPS policy, 0 otherwise",
+		[]string{"account"},
+		nil,
+	)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	p, err := readPolicy()
+	if err != nil {
+		return fmt.Errorf("failed to read LAPS policy: %w", err)
+	}
+
+	if p == nil {
+		return nil
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.policyInfo,
+		prometheus.GaugeValue,
+		1.0,
+		p.source,
+		p.backupDirectory,
+	)
+
+	if p.backupDirectory == "disabled" {
+		return nil
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.passwordMaxAge,
+		prometheus.GaugeValue,
+		p.maxAge.Seconds(),
+	)
+
+	accountName := p.accountName
+	if accountName == "" {
+		accountName, err = builtinAdministratorName()
+		if err != nil {
+			return fmt.Errorf("failed to resolve built-in administrator account: %w", err)
+		}
+	}
+
+	lastUpdate, err := c.lastPasswordUpdateTime(p, accountName)
+	if err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.lastPasswordUpdate,
+		prometheus.GaugeValue,
+		float64(lastUpdate.Unix()),
+		accountName,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.passwordCompliant,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(time.Since(lastUpdate) <= p.maxAge),
+		accountName,
+	)
+
+	return nil
+}
+
+// lastPasswordUpdateTime prefers the rotation time recorded by Windows LAPS. Legacy LAPS only records it in
+// Active Directory, so the password age of the local account is used instead.
+func (c *Collector) lastPasswordUpdateTime(p *policy, accountName string) (time.Time, error) {
+	if !p.legacy {
+		key, err := registry.OpenKey(registry.LOCAL_MACHINE, stateKey, registry.QUERY_VALUE)
+		if err == nil {
+			defer key.Close()
+
+			if value, _, err := key.GetIntegerValue("LastPasswordUpdateTime"); err == nil && value != 0 {
+				return time.Unix(0, (&windows.Filetime{
+					LowDateTime:  uint32(value),
+					HighDateTime: uint32(value >> 32),
+				}).Nanoseconds()), nil
+			}
+		} else if !errors.Is(err, registry.ErrNotExist) {
+			c.logger.Debug("failed to open Windows LAPS state",
+				slog.Any("err", err),
+			)
+		}
+	}
+
+	passwordAge, err := netapi32.GetUserPasswordAge(accountName)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read password age of %s: %w", accountName, err)
+	}
+
+	return time.Now().Add(-passwordAge), nil
+}
+
+// readPolicy returns the LAPS policy in effect or nil if LAPS is not configured.
+func readPolicy() (*policy, error) {
+	for _, source := range policySources {
+		key, err := registry.OpenKey(registry.LOCAL_MACHINE, source.key, registry.QUERY_VALUE)
+		if err != nil {
+			if errors.Is(err, registry.ErrNotExist) {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to open registry key %s: %w", source.key, err)
+		}
+
+		p := readPolicyValues(key, source)
+
+		key.Close()
+
+		if p != nil {
+			return p, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func readPolicyValues(key registry.Key, source policySource) *policy {
+	p := &policy{
+		source: source.name,
+		maxAge: defaultPasswordAgeDays * 24 * time.Hour,
+		legacy: source.legacy,
+	}
+
+	if source.legacy {
+		enabled, _, err := key.GetIntegerValue("AdmPwdEnabled")
+		if err != nil {
+			return nil
+		}
+
+		// Legacy LAPS always backs up the password to Active Directory.
+		p.backupDirectory = backupDirectories[0]
+		if enabled != 0 {
+			p.backupDirectory = backupDirectories[2]
+		}
+
+		p.accountName, _, _ = key.GetStringValue("AdminAccountName")
+	} else {
+		backupDirectory, _, err := key.GetIntegerValue("BackupDirectory")
+		if err != nil {
+			return nil
+		}
+
+		var ok bool
+		if p.backupDirectory, ok = backupDirectories[backupDirectory]; !ok {
+			p.backupDirectory = "unknown"
+		}
+
+		p.accountName, _, _ = key.GetStringValue("AdministratorAccountName")
+	}
+
+	if days, _, err := key.GetIntegerValue("PasswordAgeDays"); err == nil && days > 0 {
+		p.maxAge = time.Duration(days) * 24 * time.Hour
+	}
+
+	return p
+}
+
+// builtinAdministratorName returns the name of the local account with RID 500, which is managed if the policy
+// doesn't name an account. The account may be renamed, so it is resolved by SID.
+func builtinAdministratorName() (string, error) {
+	computerName, err := windows.ComputerName()
+	if err != nil {
+		return "", err
+	}
+
+	machineSID, _, _, err := windows.LookupSID("", computerName)
+	if err != nil {
+		return "", fmt.Errorf("failed to lookup SID of %s: %w", computerName, err)
+	}
+
+	administratorSID, err := windows.CreateWellKnownDomainSid(windows.WinAccountAdministratorSid, machineSID)
+	if err != nil {
+		return "", err
+	}
+
+	account, _, _, err := administratorSID.LookupAccount("")
+	if err != nil {
+		return "", err
+	}
+
+	return account, nil
+}
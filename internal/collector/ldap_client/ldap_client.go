@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package ldap_client
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/iphlpapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "ldap_client"
+
+	ldapRegistryKey = `SYSTEM\CurrentControlSet\Services\LDAP`
+
+	// signingLevelNegotiate is the default of LDAPClientIntegrity if the value is not configured.
+	signingLevelNegotiate = 1
+
+	transportLDAP  = "ldap"
+	transportLDAPS = "ldaps"
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+// ldapPorts are the well-known ports of domain controllers with their transport.
+// Connections to 389 and 3268 are clear text unless they use signing, sealing or StartTLS.
+//
+//nolint:gochecknoglobals
+var ldapPorts = map[uint16]string{
+	389:  transportLDAP,
+	636:  transportLDAPS,
+	3268: transportLDAP,
+	3269: transportLDAPS,
+}
+
+// A Collector is a Prometheus Collector for the LDAP client connections of the host.
+// Connections are counted from the TCP connection table by the remote port,
+// the signing policy is read from the LDAPClientIntegrity registry value.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	connections  *prometheus.Desc
+	signingLevel *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.connections = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "connections"),
+		"Number of established TCP connections of the host to remote LDAP ports by transport (ldap, ldaps)",
+		[]string{"port", "transport"},
+		nil,
+	)
+	c.signingLevel = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "signing_level"),
+		"LDAP client signing policy from LDAPClientIntegrity (0 = none, 1 = negotiate signing, 2 = require signing)",
+		nil,
+		nil,
+	)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if err := c.collectConnections(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting LDAP connection metrics: %w", err))
+	}
+
+	if err := c.collectSigningLevel(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting LDAP signing metrics: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectConnections(ch chan<- prometheus.Metric) error {
+	connections := make(map[uint16]float64, len(ldapPorts))
+	for port := range ldapPorts {
+		connections[port] = 0
+	}
+
+	for _, family := range []uint32{windows.AF_INET, windows.AF_INET6} {
+		ports, err := iphlpapi.GetTCPEstablishedRemotePorts(family)
+		if err != nil {
+			return err
+		}
+
+		for _, port := range ports {
+			if _, ok := ldapPorts[port]; ok {
+				connections[port]++
+			}
+		}
+	}
+
+	for port, count := range connections {
+		ch <- prometheus.MustNewConstMetric(
+			c.connections,
+			prometheus.GaugeValue,
+			count,
+			strconv.FormatUint(uint64(port), 10),
+			ldapPorts[port],
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectSigningLevel(ch chan<- prometheus.Metric) error {
+	level := uint64(signingLevelNegotiate)
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, ldapRegistryKey, registry.QUERY_VALUE)
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return fmt.Errorf("failed to open registry key %s: %w", ldapRegistryKey, err)
+	}
+
+	if err == nil {
+		defer key.Close()
+
+		value, _, err := key.GetIntegerValue("LDAPClientIntegrity")
+		if err != nil && !errors.Is(err, registry.ErrNotExist) {
+			return fmt.Errorf("failed to read LDAPClientIntegrity: %w", err)
+		}
+
+		if err == nil {
+			level = value
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.signingLevel,
+		prometheus.GaugeValue,
+		float64(level),
+	)
+
+	return nil
+}
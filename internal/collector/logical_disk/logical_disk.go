@@ -29,6 +29,8 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-ole/go-ole"
@@ -45,12 +47,15 @@ const (
 	Name                  = "logical_disk"
 	subCollectorMetrics   = "metrics"
 	subCollectorBitlocker = "bitlocker_status"
+	subCollectorTrend     = "free_space_trend"
+	subCollectorIOLatency = "io_latency"
 )
 
 type Config struct {
-	CollectorsEnabled []string       `yaml:"enabled"`
-	VolumeInclude     *regexp.Regexp `yaml:"volume-include"`
-	VolumeExclude     *regexp.Regexp `yaml:"volume-exclude"`
+	CollectorsEnabled    []string       `yaml:"enabled"`
+	VolumeInclude        *regexp.Regexp `yaml:"volume-include"`
+	VolumeExclude        *regexp.Regexp `yaml:"volume-exclude"`
+	FreeSpaceTrendWindow time.Duration  `yaml:"free-space-trend.window"`
 }
 
 //nolint:gochecknoglobals
@@ -58,8 +63,9 @@ var ConfigDefaults = Config{
 	CollectorsEnabled: []string{
 		subCollectorMetrics,
 	},
-	VolumeInclude: types.RegExpAny,
-	VolumeExclude: types.RegExpEmpty,
+	VolumeInclude:        types.RegExpAny,
+	VolumeExclude:        types.RegExpEmpty,
+	FreeSpaceTrendWindow: 24 * time.Hour,
 }
 
 // A Collector is a Prometheus Collector for perflib logicalDisk metrics.
@@ -78,6 +84,12 @@ type Collector struct {
 
 	ctxCancelFunc context.CancelFunc
 
+	ioLatency *ioLatency
+
+	// mu protects freeSpaceTrend against concurrent scrapes.
+	mu             sync.Mutex
+	freeSpaceTrend *freeSpaceTrend
+
 	avgReadQueue     *prometheus.Desc
 	avgWriteQueue    *prometheus.Desc
 	freeSpace        *prometheus.Desc
@@ -98,6 +110,11 @@ type Collector struct {
 	writeTime        *prometheus.Desc
 
 	bitlockerStatus *prometheus.Desc
+
+	freeSpaceChange     *prometheus.Desc
+	freeSpaceTrendRange *prometheus.Desc
+
+	ioLatencyDesc *prometheus.Desc
 }
 
 type volumeInfo struct {
@@ -107,6 +124,7 @@ type volumeInfo struct {
 	label        string
 	volumeType   string
 	readonly     float64
+	extents      []volumeExtent
 }
 
 func New(config *Config) *Collector {
@@ -122,6 +140,10 @@ func New(config *Config) *Collector {
 		config.VolumeInclude = ConfigDefaults.VolumeInclude
 	}
 
+	if config.FreeSpaceTrendWindow == 0 {
+		config.FreeSpaceTrendWindow = ConfigDefaults.FreeSpaceTrendWindow
+	}
+
 	c := &Collector{
 		config: *config,
 	}
@@ -149,12 +171,19 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 
 	app.Flag(
 		"collector.logical_disk.enabled",
-		fmt.Sprintf("Comma-separated list of collectors to use. Available collectors: %s, %s. Defaults to metrics, if not specified.",
+		fmt.Sprintf("Comma-separated list of collectors to use. Available collectors: %s, %s, %s, %s. Defaults to metrics, if not specified.",
 			subCollectorMetrics,
 			subCollectorBitlocker,
+			subCollectorTrend,
+			subCollectorIOLatency,
 		),
 	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
 
+	app.Flag(
+		"collector.logical_disk.free-space-trend.window",
+		"Sliding window of the free space trend of the free_space_trend sub collector.",
+	).Default(c.config.FreeSpaceTrendWindow.String()).DurationVar(&c.config.FreeSpaceTrendWindow)
+
 	app.Action(func(*kingpin.ParseContext) error {
 		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
 
@@ -185,6 +214,14 @@ func (c *Collector) Close() error {
 		c.ctxCancelFunc()
 	}
 
+	if c.ioLatency != nil {
+		if err := c.ioLatency.Close(); err != nil {
+			return fmt.Errorf("failed to close ETW subscription: %w", err)
+		}
+
+		c.ioLatency = nil
+	}
+
 	return nil
 }
 
@@ -192,13 +229,21 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 	c.logger = logger.With(slog.String("collector", Name))
 
 	for _, collector := range c.config.CollectorsEnabled {
-		if !slices.Contains([]string{subCollectorMetrics, subCollectorBitlocker}, collector) {
+		if !slices.Contains([]string{subCollectorMetrics, subCollectorBitlocker, subCollectorTrend, subCollectorIOLatency}, collector) {
 			return fmt.Errorf("unknown sub collector: %s. Possible values: %s", collector,
-				strings.Join([]string{subCollectorMetrics, subCollectorBitlocker}, ", "),
+				strings.Join([]string{subCollectorMetrics, subCollectorBitlocker, subCollectorTrend, subCollectorIOLatency}, ", "),
 			)
 		}
 	}
 
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorTrend) {
+		if c.config.FreeSpaceTrendWindow <= 0 {
+			return fmt.Errorf("free space trend window must be positive, got %s", c.config.FreeSpaceTrendWindow)
+		}
+
+		c.freeSpaceTrend = newFreeSpaceTrend(c.config.FreeSpaceTrendWindow)
+	}
+
 	c.information = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, Name, "info"),
 		"A metric with a constant '1' value labeled with logical disk information",
@@ -330,6 +375,27 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		nil,
 	)
 
+	c.freeSpaceChange = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "free_bytes_change_per_hour"),
+		"Change of the free space in bytes per hour over the free space trend window. Negative if space is consumed",
+		[]string{"volume"},
+		nil,
+	)
+
+	c.freeSpaceTrendRange = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "free_bytes_trend_range_seconds"),
+		"Time covered by the samples of free_bytes_change_per_hour, up to the free space trend window",
+		[]string{"volume"},
+		nil,
+	)
+
+	c.ioLatencyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "io_latency_seconds"),
+		"Response time of the disk I/Os of the volume completed since windows_exporter started, from the Microsoft-Windows-Kernel-Disk ETW provider",
+		[]string{"volume", "operation"},
+		nil,
+	)
+
 	var err error
 
 	c.perfDataCollector, err = pdh.NewCollector[perfDataCounterValues](logger.With(slog.String("collector", Name)), pdh.CounterTypeRaw, "LogicalDisk", pdh.InstancesAll)
@@ -356,9 +422,48 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		}
 	}
 
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorIOLatency) && c.ioLatency == nil {
+		extents, err := c.volumeExtents()
+		if err != nil {
+			return fmt.Errorf("failed to get disk extents of the volumes: %w", err)
+		}
+
+		c.ioLatency, err = newIOLatency(c, extents)
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to disk I/O events: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// volumeExtents returns the disk extents of the included volumes, which map the disk I/O events to the volumes.
+func (c *Collector) volumeExtents() ([]volumeExtent, error) {
+	if err := c.perfDataCollector.Collect(&c.perfDataObject); err != nil {
+		return nil, fmt.Errorf("failed to collect LogicalDisk metrics: %w", err)
+	}
+
+	volumes, err := getAllMountedVolumes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get volumes: %w", err)
+	}
+
+	extents := make([]volumeExtent, 0, len(c.perfDataObject))
+
+	for _, data := range c.perfDataObject {
+		if c.config.VolumeExclude.MatchString(data.Name) || !c.config.VolumeInclude.MatchString(data.Name) {
+			continue
+		}
+
+		// Volumes without disk extents, e.g. network drives, have no disk I/O.
+		if info, err := getVolumeInfo(volumes, data.Name); err == nil {
+			extents = append(extents, info.extents...)
+		}
+	}
+
+	return extents, nil
+}
+
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
 func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
@@ -374,6 +479,17 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		return fmt.Errorf("failed to get volumes: %w", err)
 	}
 
+	now := time.Now()
+	volumesSeen := make(map[string]struct{}, len(c.perfDataObject))
+	extents := make([]volumeExtent, 0, len(c.perfDataObject))
+
+	if c.freeSpaceTrend != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		defer c.freeSpaceTrend.retain(volumesSeen)
+	}
+
 	for _, data := range c.perfDataObject {
 		if c.config.VolumeExclude.MatchString(data.Name) || !c.config.VolumeInclude.MatchString(data.Name) {
 			continue
@@ -386,6 +502,8 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 			)
 		}
 
+		extents = append(extents, info.extents...)
+
 		ch <- prometheus.MustNewConstMetric(
 			c.information,
 			prometheus.GaugeValue,
@@ -512,6 +630,12 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 			)
 		}
 
+		if c.freeSpaceTrend != nil {
+			c.collectFreeSpaceTrend(ch, data.Name, data.FreeSpace*1024*1024, now)
+
+			volumesSeen[data.Name] = struct{}{}
+		}
+
 		if slices.Contains(c.config.CollectorsEnabled, subCollectorBitlocker) {
 			c.bitlockerReqCh <- data.Name
 
@@ -550,9 +674,39 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		}
 	}
 
+	if c.ioLatency != nil {
+		c.ioLatency.setExtents(extents)
+		c.collectIOLatency(ch)
+	}
+
 	return nil
 }
 
+// collectFreeSpaceTrend adds the free space of the volume to the trend and sends the change per hour,
+// once the samples cover enough time.
+func (c *Collector) collectFreeSpaceTrend(ch chan<- prometheus.Metric, volume string, freeBytes float64, now time.Time) {
+	c.freeSpaceTrend.observe(volume, now, freeBytes)
+
+	change, span, ok := c.freeSpaceTrend.changePerHour(volume)
+	if !ok {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.freeSpaceChange,
+		prometheus.GaugeValue,
+		change,
+		volume,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.freeSpaceTrendRange,
+		prometheus.GaugeValue,
+		span.Seconds(),
+		volume,
+	)
+}
+
 func getDriveType(driveType uint32) string {
 	switch driveType {
 	case windows.DRIVE_UNKNOWN:
@@ -615,6 +769,8 @@ func getVolumeInfo(volumes map[string]string, rootDrive string) (volumeInfo, err
 		return volumeInfo{}, fmt.Errorf("could not identify physical drive for %s: %w", rootDrive, err)
 	}
 
+	extents := parseVolumeDiskExtents(rootDrive, volumeDiskExtents[:bytesReturned])
+
 	numDiskIDs := uint(binary.LittleEndian.Uint32(volumeDiskExtents))
 	if numDiskIDs < 1 {
 		return volumeInfo{}, fmt.Errorf("could not identify physical drive for %s: no disk IDs returned", rootDrive)
@@ -650,7 +806,7 @@ func getVolumeInfo(volumes map[string]string, rootDrive string) (volumeInfo, err
 	)
 	if err != nil {
 		if driveType == windows.DRIVE_CDROM || driveType == windows.DRIVE_REMOVABLE {
-			return volumeInfo{}, nil
+			return volumeInfo{extents: extents}, nil
 		}
 
 		return volumeInfo{}, fmt.Errorf("could not get volume information for %s: %w", volumeInformationRootDrive, err)
@@ -663,6 +819,7 @@ func getVolumeInfo(volumes map[string]string, rootDrive string) (volumeInfo, err
 		filesystem:   windows.UTF16PtrToString(&volBufType[0]),
 		serialNumber: fmt.Sprintf("%X", volSerialNum),
 		readonly:     float64(fsFlags & windows.FILE_READ_ONLY_VOLUME),
+		extents:      extents,
 	}, nil
 }
 
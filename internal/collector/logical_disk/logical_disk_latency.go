@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package logical_disk
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/prometheus-community/windows_exporter/internal/etw"
+	"github.com/prometheus-community/windows_exporter/internal/headers/kernel32"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	kernelDiskEventRead  = 10
+	kernelDiskEventWrite = 11
+)
+
+//nolint:gochecknoglobals
+var (
+	// kernelDiskProvider is Microsoft-Windows-Kernel-Disk, which logs an event for each completed disk I/O.
+	kernelDiskProvider = etw.Provider{
+		GUID:     windows.GUID{Data1: 0xc7bde69a, Data2: 0xe1e0, Data3: 0x4177, Data4: [8]byte{0xb6, 0xef, 0x28, 0x3a, 0xd1, 0x52, 0x52, 0x71}},
+		Level:    etw.LevelInformation,
+		EventIDs: []uint16{kernelDiskEventRead, kernelDiskEventWrite},
+	}
+
+	ioLatencyBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+)
+
+// volumeExtent is a range of a disk that belongs to a volume, see IOCTL_VOLUME_GET_VOLUME_DISK_EXTENTS.
+type volumeExtent struct {
+	volume string
+	disk   uint32
+	start  uint64
+	length uint64
+}
+
+type ioLatencyKey struct {
+	volume    string
+	operation string
+}
+
+type ioLatencyHistogram struct {
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+// ioLatency builds histograms of the response time of each disk I/O from the events of the Microsoft-Windows-Kernel-Disk provider.
+// The events carry the disk number and the byte offset of the I/O, which are mapped to the volume by the disk extents of the volumes.
+type ioLatency struct {
+	subscription *etw.Subscription
+	// ticksPerSecond is the frequency of the query performance counter, the unit of the response time.
+	ticksPerSecond float64
+
+	mu sync.Mutex
+	// extents are the disk extents of the volumes, updated on each scrape.
+	extents    []volumeExtent
+	histograms map[ioLatencyKey]*ioLatencyHistogram
+}
+
+func newIOLatency(c *Collector, extents []volumeExtent) (*ioLatency, error) {
+	frequency, err := kernel32.QueryPerformanceFrequency()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query performance counter frequency: %w", err)
+	}
+
+	l := &ioLatency{
+		ticksPerSecond: float64(frequency),
+		extents:        extents,
+		histograms:     make(map[ioLatencyKey]*ioLatencyHistogram),
+	}
+
+	subscription, err := etw.Subscribe(c.logger, kernelDiskProvider, l.handleEvent)
+	if err != nil {
+		return nil, err
+	}
+
+	l.subscription = subscription
+
+	return l, nil
+}
+
+func (l *ioLatency) Close() error {
+	return l.subscription.Close()
+}
+
+func (l *ioLatency) setExtents(extents []volumeExtent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.extents = extents
+}
+
+// handleEvent parses the DiskRead and DiskWrite events:
+// DiskNumber (uint32), IrpFlags (uint32), TransferSize (uint32), Reserved (uint32), ByteOffset (uint64),
+// FileObject (pointer), Irp (pointer), HighResResponseTime (uint64), IssuingThreadId (uint32).
+func (l *ioLatency) handleEvent(record *etw.EventRecord) {
+	operation := "read"
+	if record.EventHeader.EventDescriptor.Id == kernelDiskEventWrite {
+		operation = "write"
+	}
+
+	data := record.Data()
+	responseTimeOffset := 24 + 2*record.PointerSize()
+
+	if len(data) < responseTimeOffset+8 {
+		return
+	}
+
+	diskNumber := binary.LittleEndian.Uint32(data[0:4])
+	byteOffset := binary.LittleEndian.Uint64(data[16:24])
+	latency := float64(binary.LittleEndian.Uint64(data[responseTimeOffset:])) / l.ticksPerSecond
+
+	l.observe(diskNumber, byteOffset, operation, latency)
+}
+
+// observe counts the I/O in the histogram of its volume. I/Os outside the extents of the collected volumes,
+// e.g. of the partition table or of excluded volumes, are not counted.
+func (l *ioLatency) observe(diskNumber uint32, byteOffset uint64, operation string, latency float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	i := slices.IndexFunc(l.extents, func(extent volumeExtent) bool {
+		return extent.disk == diskNumber && byteOffset >= extent.start && byteOffset-extent.start < extent.length
+	})
+	if i == -1 {
+		return
+	}
+
+	key := ioLatencyKey{volume: l.extents[i].volume, operation: operation}
+
+	histogram, ok := l.histograms[key]
+	if !ok {
+		histogram = &ioLatencyHistogram{buckets: make([]uint64, len(ioLatencyBuckets))}
+		l.histograms[key] = histogram
+	}
+
+	histogram.count++
+	histogram.sum += latency
+
+	// Buckets are cumulative, the latency is counted in the first matching bucket and all following buckets.
+	bucket, _ := slices.BinarySearch(ioLatencyBuckets, latency)
+	for ; bucket < len(ioLatencyBuckets); bucket++ {
+		histogram.buckets[bucket]++
+	}
+}
+
+func (c *Collector) collectIOLatency(ch chan<- prometheus.Metric) {
+	c.ioLatency.mu.Lock()
+	defer c.ioLatency.mu.Unlock()
+
+	for key, histogram := range c.ioLatency.histograms {
+		buckets := make(map[float64]uint64, len(ioLatencyBuckets))
+		for i, bound := range ioLatencyBuckets {
+			buckets[bound] = histogram.buckets[i]
+		}
+
+		ch <- prometheus.MustNewConstHistogram(
+			c.ioLatencyDesc,
+			histogram.count,
+			histogram.sum,
+			buckets,
+			key.volume,
+			key.operation,
+		)
+	}
+}
+
+// parseVolumeDiskExtents parses the VOLUME_DISK_EXTENTS structure returned by IOCTL_VOLUME_GET_VOLUME_DISK_EXTENTS:
+// NumberOfDiskExtents (uint32), padding (uint32) and the DISK_EXTENT structures of DiskNumber (uint32), padding (uint32),
+// StartingOffset (int64) and ExtentLength (int64).
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winioctl/ns-winioctl-volume_disk_extents
+func parseVolumeDiskExtents(volume string, data []byte) []volumeExtent {
+	if len(data) < 8 {
+		return nil
+	}
+
+	count := int(binary.LittleEndian.Uint32(data))
+	extents := make([]volumeExtent, 0, count)
+
+	for i := range count {
+		offset := 8 + i*diskExtentSize
+		if len(data) < offset+diskExtentSize {
+			break
+		}
+
+		extents = append(extents, volumeExtent{
+			volume: volume,
+			disk:   binary.LittleEndian.Uint32(data[offset:]),
+			start:  binary.LittleEndian.Uint64(data[offset+8:]),
+			length: binary.LittleEndian.Uint64(data[offset+16:]),
+		})
+	}
+
+	return extents
+}
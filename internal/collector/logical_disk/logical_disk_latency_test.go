@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package logical_disk
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVolumeDiskExtents(t *testing.T) {
+	t.Parallel()
+
+	// A volume spanning two disks.
+	data := make([]byte, 8+2*diskExtentSize)
+	binary.LittleEndian.PutUint32(data[0:], 2)
+	binary.LittleEndian.PutUint32(data[8:], 1)
+	binary.LittleEndian.PutUint64(data[16:], 1<<20)
+	binary.LittleEndian.PutUint64(data[24:], 1<<30)
+	binary.LittleEndian.PutUint32(data[32:], 2)
+	binary.LittleEndian.PutUint64(data[40:], 1<<20)
+	binary.LittleEndian.PutUint64(data[48:], 1<<29)
+
+	require.Equal(t, []volumeExtent{
+		{volume: "D:", disk: 1, start: 1 << 20, length: 1 << 30},
+		{volume: "D:", disk: 2, start: 1 << 20, length: 1 << 29},
+	}, parseVolumeDiskExtents("D:", data))
+
+	// Extents beyond the returned bytes are ignored.
+	require.Len(t, parseVolumeDiskExtents("D:", data[:8+diskExtentSize]), 1)
+	require.Empty(t, parseVolumeDiskExtents("D:", data[:4]))
+}
+
+func TestIOLatencyObserve(t *testing.T) {
+	t.Parallel()
+
+	l := &ioLatency{
+		extents: []volumeExtent{
+			{volume: "C:", disk: 0, start: 1 << 20, length: 1 << 30},
+			{volume: "D:", disk: 1, start: 1 << 20, length: 1 << 30},
+			{volume: "D:", disk: 2, start: 1 << 20, length: 1 << 30},
+		},
+		histograms: make(map[ioLatencyKey]*ioLatencyHistogram),
+	}
+
+	l.observe(0, 1<<20, "read", 0.0002)
+	l.observe(0, 1<<21, "read", 0.003)
+	l.observe(1, 1<<21, "write", 10)
+	l.observe(2, 1<<21, "write", 0.001)
+
+	// The partition table and other disks are not part of any volume.
+	l.observe(0, 0, "read", 0.001)
+	l.observe(0, 1<<20+1<<30, "read", 0.001)
+	l.observe(3, 1<<21, "read", 0.001)
+
+	require.Len(t, l.histograms, 2)
+
+	c := l.histograms[ioLatencyKey{volume: "C:", operation: "read"}]
+	require.Equal(t, uint64(2), c.count)
+	require.InDelta(t, 0.0032, c.sum, 1e-9)
+	require.Equal(t, []uint64{0, 1, 1, 1, 1, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2}, c.buckets)
+
+	// I/Os of a volume spanning multiple disks are counted in the histogram of the volume,
+	// latencies above the highest bucket only in the count.
+	d := l.histograms[ioLatencyKey{volume: "D:", operation: "write"}]
+	require.Equal(t, uint64(2), d.count)
+	require.Equal(t, []uint64{0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, d.buckets)
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package logical_disk
+
+import (
+	"time"
+)
+
+// freeSpaceTrendSampleInterval is the minimum time between two samples of the free space of a volume.
+// The free space counter only updates every 10-15 minutes, so more samples don't improve the trend.
+const freeSpaceTrendSampleInterval = time.Minute
+
+type freeSpaceSample struct {
+	time      time.Time
+	freeBytes float64
+}
+
+// freeSpaceTrend keeps the free space samples of the volumes within a sliding window, so slow-growing volumes
+// can be forecast even if the retention of Prometheus is shorter than the window.
+type freeSpaceTrend struct {
+	window  time.Duration
+	samples map[string][]freeSpaceSample
+}
+
+func newFreeSpaceTrend(window time.Duration) *freeSpaceTrend {
+	return &freeSpaceTrend{
+		window:  window,
+		samples: make(map[string][]freeSpaceSample),
+	}
+}
+
+// observe adds a sample of the volume and drops the samples that left the window.
+func (t *freeSpaceTrend) observe(volume string, now time.Time, freeBytes float64) {
+	samples := t.samples[volume]
+
+	if len(samples) > 0 && now.Sub(samples[len(samples)-1].time) < freeSpaceTrendSampleInterval {
+		return
+	}
+
+	samples = append(samples, freeSpaceSample{time: now, freeBytes: freeBytes})
+
+	first := 0
+	for first < len(samples)-1 && now.Sub(samples[first].time) > t.window {
+		first++
+	}
+
+	t.samples[volume] = samples[first:]
+}
+
+// retain drops the samples of volumes that are not in volumes, e.g. after a volume was unmounted.
+func (t *freeSpaceTrend) retain(volumes map[string]struct{}) {
+	for volume := range t.samples {
+		if _, ok := volumes[volume]; !ok {
+			delete(t.samples, volume)
+		}
+	}
+}
+
+// changePerHour returns the least squares slope of the free space of the volume in bytes per hour and the time
+// covered by the samples. ok is false until the samples cover at least freeSpaceTrendSampleInterval.
+func (t *freeSpaceTrend) changePerHour(volume string) (float64, time.Duration, bool) {
+	samples := t.samples[volume]
+	if len(samples) < 2 {
+		return 0, 0, false
+	}
+
+	span := samples[len(samples)-1].time.Sub(samples[0].time)
+	if span < freeSpaceTrendSampleInterval {
+		return 0, 0, false
+	}
+
+	// Times and values relative to the first sample keep the sums small enough for float64.
+	var sumX, sumY, sumXX, sumXY float64
+
+	for _, sample := range samples {
+		x := sample.time.Sub(samples[0].time).Hours()
+		y := sample.freeBytes - samples[0].freeBytes
+
+		sumX += x
+		sumY += y
+		sumXX += x * x
+		sumXY += x * y
+	}
+
+	n := float64(len(samples))
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, 0, false
+	}
+
+	return (n*sumXY - sumX*sumY) / denominator, span, true
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package logical_disk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreeSpaceTrend(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1700000000, 0)
+	trend := newFreeSpaceTrend(6 * time.Hour)
+
+	// A single sample has no trend.
+	trend.observe("C:", start, 100e9)
+
+	_, _, ok := trend.changePerHour("C:")
+	require.False(t, ok)
+
+	// 1 GB consumed per hour, sampled every 10 minutes. Samples within the sample interval are ignored.
+	for i := 1; i <= 36; i++ {
+		now := start.Add(time.Duration(i) * 10 * time.Minute)
+		trend.observe("C:", now, 100e9-float64(i)*1e9/6)
+		trend.observe("C:", now.Add(time.Second), 0)
+	}
+
+	change, span, ok := trend.changePerHour("C:")
+	require.True(t, ok)
+	require.InDelta(t, -1e9, change, 1)
+	require.Equal(t, 6*time.Hour, span)
+
+	// Samples older than the window are dropped, so a cleanup is reflected within the window.
+	for i := 37; i <= 72; i++ {
+		trend.observe("C:", start.Add(time.Duration(i)*10*time.Minute), 94e9+float64(i-36)*1e9/3)
+	}
+
+	change, span, ok = trend.changePerHour("C:")
+	require.True(t, ok)
+	require.InDelta(t, 2e9, change, 1)
+	require.Equal(t, 6*time.Hour, span)
+
+	trend.retain(map[string]struct{}{"D:": {}})
+
+	_, _, ok = trend.changePerHour("C:")
+	require.False(t, ok)
+}
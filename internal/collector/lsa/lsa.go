@@ -0,0 +1,306 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package lsa
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"unsafe"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/kernel32"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "lsa"
+
+	lsaRegistryKey = `SYSTEM\CurrentControlSet\Control\Lsa`
+
+	// securityServiceCredentialGuard is the identifier of Credential Guard
+	// in Win32_DeviceGuard SecurityServicesConfigured and SecurityServicesRunning.
+	securityServiceCredentialGuard = 1
+
+	// vbsStatusRunning is the VirtualizationBasedSecurityStatus value of a running VBS.
+	vbsStatusRunning = 2
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var deviceGuardQuery = utils.Must(mi.NewQuery("SELECT SecurityServicesConfigured, SecurityServicesRunning, VirtualizationBasedSecurityStatus FROM Win32_DeviceGuard"))
+
+// A Collector is a Prometheus Collector for the LSA protection and Credential Guard status.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	miSession          *mi.Session
+	deviceGuardEnabled bool
+
+	lsassProtected                     *prometheus.Desc
+	runAsPPLConfigured                 *prometheus.Desc
+	runAsPPLUEFILocked                 *prometheus.Desc
+	credentialGuardConfigured          *prometheus.Desc
+	credentialGuardRunning             *prometheus.Desc
+	virtualizationBasedSecurityRunning *prometheus.Desc
+}
+
+type win32DeviceGuard struct {
+	SecurityServicesConfigured        []uint32 `mi:"SecurityServicesConfigured"`
+	SecurityServicesRunning           []uint32 `mi:"SecurityServicesRunning"`
+	VirtualizationBasedSecurityStatus uint32   `mi:"VirtualizationBasedSecurityStatus"`
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.lsassProtected = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "lsass_protected_process_light"),
+		"Whether the LSASS process is running as protected process light (PPL)",
+		nil,
+		nil,
+	)
+	c.runAsPPLConfigured = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "run_as_ppl_configured"),
+		"Whether LSA protection is configured by the RunAsPPL registry value",
+		nil,
+		nil,
+	)
+	c.runAsPPLUEFILocked = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "run_as_ppl_uefi_locked"),
+		"Whether LSA protection is configured with UEFI lock by the RunAsPPL registry value",
+		nil,
+		nil,
+	)
+	c.credentialGuardConfigured = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "credential_guard_configured"),
+		"Whether Credential Guard is configured",
+		nil,
+		nil,
+	)
+	c.credentialGuardRunning = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "credential_guard_running"),
+		"Whether Credential Guard is running",
+		nil,
+		nil,
+	)
+	c.virtualizationBasedSecurityRunning = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "virtualization_based_security_running"),
+		"Whether virtualization-based security (VBS) is running",
+		nil,
+		nil,
+	)
+
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.miSession = miSession
+
+	var dst []win32DeviceGuard
+	if err := c.miSession.Query(&dst, mi.NamespaceRootDeviceGuard, deviceGuardQuery); err != nil {
+		if !errors.Is(err, mi.MI_RESULT_INVALID_NAMESPACE) {
+			return fmt.Errorf("WMI query failed: %w", err)
+		}
+
+		c.logger.Debug("Device Guard WMI namespace is not available, skipping Credential Guard metrics")
+	} else {
+		c.deviceGuardEnabled = true
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if err := c.collectLSASSProtection(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting LSASS protection metrics: %w", err))
+	}
+
+	if err := c.collectRunAsPPL(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting RunAsPPL metrics: %w", err))
+	}
+
+	if c.deviceGuardEnabled {
+		if err := c.collectDeviceGuard(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting Credential Guard metrics: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// collectLSASSProtection reads the protection level of the running lsass.exe process.
+// PROCESS_QUERY_LIMITED_INFORMATION is granted on protected processes, so no elevated
+// privileges are required.
+func (c *Collector) collectLSASSProtection(ch chan<- prometheus.Metric) error {
+	pid, err := findProcessID("lsass.exe")
+	if err != nil {
+		return err
+	}
+
+	process, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return fmt.Errorf("failed to open lsass.exe process: %w", err)
+	}
+
+	defer func() {
+		_ = windows.CloseHandle(process)
+	}()
+
+	protectionLevel, err := kernel32.GetProcessProtectionLevel(process)
+	if err != nil {
+		return fmt.Errorf("failed to get protection level of lsass.exe: %w", err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.lsassProtected,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(protectionLevel == kernel32.ProtectionLevelLsaLight),
+	)
+
+	return nil
+}
+
+// collectRunAsPPL reads the RunAsPPL registry value.
+// 1 enables LSA protection with UEFI lock, 2 enables it without UEFI lock.
+func (c *Collector) collectRunAsPPL(ch chan<- prometheus.Metric) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, lsaRegistryKey, registry.QUERY_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open registry key %s: %w", lsaRegistryKey, err)
+	}
+
+	defer key.Close()
+
+	runAsPPL, _, err := key.GetIntegerValue("RunAsPPL")
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return fmt.Errorf("failed to read registry value RunAsPPL: %w", err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.runAsPPLConfigured,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(runAsPPL == 1 || runAsPPL == 2),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.runAsPPLUEFILocked,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(runAsPPL == 1),
+	)
+
+	return nil
+}
+
+func (c *Collector) collectDeviceGuard(ch chan<- prometheus.Metric) error {
+	var dst []win32DeviceGuard
+	if err := c.miSession.Query(&dst, mi.NamespaceRootDeviceGuard, deviceGuardQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	if len(dst) == 0 {
+		return errors.New("WMI query returned empty result set")
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.credentialGuardConfigured,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(slices.Contains(dst[0].SecurityServicesConfigured, securityServiceCredentialGuard)),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.credentialGuardRunning,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(slices.Contains(dst[0].SecurityServicesRunning, securityServiceCredentialGuard)),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.virtualizationBasedSecurityRunning,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(dst[0].VirtualizationBasedSecurityStatus == vbsStatusRunning),
+	)
+
+	return nil
+}
+
+// findProcessID returns the process ID of the first process with the given executable name.
+func findProcessID(exeName string) (uint32, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create process snapshot: %w", err)
+	}
+
+	defer func() {
+		_ = windows.CloseHandle(snapshot)
+	}()
+
+	var entry windows.ProcessEntry32
+
+	entry.Size = uint32(unsafe.Sizeof(entry))
+
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		if strings.EqualFold(windows.UTF16ToString(entry.ExeFile[:]), exeName) {
+			return entry.ProcessID, nil
+		}
+	}
+
+	if !errors.Is(err, windows.ERROR_NO_MORE_FILES) {
+		return 0, fmt.Errorf("failed to enumerate processes: %w", err)
+	}
+
+	return 0, fmt.Errorf("process %s not found", exeName)
+}
@@ -0,0 +1,413 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mdm
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "mdm"
+
+	diagnosticsChannel = "Microsoft-Windows-DeviceManagement-Enterprise-Diagnostics-Provider/Admin"
+
+	// eventIDCommandFailure is logged by the MDM ConfigurationManager if a CSP command of a policy fails.
+	eventIDCommandFailure = 404
+
+	enrollmentsKey = `SOFTWARE\Microsoft\Enrollments`
+	// omaDMAccountsKey contains a subkey per enrollment ID with the OMA-DM session state.
+	omaDMAccountsKey = `SOFTWARE\Microsoft\Provisioning\OMADM\Accounts`
+
+	// enrollmentStateEnrolled is the EnrollmentState registry value of a completed enrollment.
+	enrollmentStateEnrolled = 1
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+// renderValuePaths are the event properties rendered for each MDM diagnostics event.
+// The order must match the value* indices.
+//
+//nolint:gochecknoglobals
+var renderValuePaths = []string{
+	"Event/System/EventRecordID",
+}
+
+const (
+	valueEventRecordID = iota
+)
+
+//nolint:gochecknoglobals
+var (
+	providerQuery = utils.Must(mi.NewQuery("SELECT InstanceID FROM MDM_DMClient_Provider01"))
+
+	// serverTimeLayouts are the formats of the OMA-DM session timestamps in the registry.
+	serverTimeLayouts = []string{"20060102T150405Z", "20060102T150405"}
+)
+
+// A Collector is a Prometheus Collector for MDM enrollment and sync metrics.
+// Enrolled providers are read from the DMClient CSP via the MDM WMI bridge, enrollment details and sync
+// timestamps from the registry and policy failures from the DeviceManagement diagnostics event log.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	miSession *mi.Session
+
+	providersEnabled bool
+	eventsEnabled    bool
+	renderContext    wevtapi.EVT_HANDLE
+
+	// mu protects policyFailures and lastRecordID against concurrent scrapes.
+	mu             sync.Mutex
+	lastRecordID   uint64
+	policyFailures float64
+
+	providerEnrolled    *prometheus.Desc
+	enrollmentInfo      *prometheus.Desc
+	enrolled            *prometheus.Desc
+	lastSuccessfulSync  *prometheus.Desc
+	lastSyncAttempt     *prometheus.Desc
+	policyFailuresTotal *prometheus.Desc
+}
+
+type dmClientProvider struct {
+	InstanceID string `mi:"InstanceID"`
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if c.renderContext != 0 {
+		return wevtapi.EvtClose(c.renderContext)
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.providerEnrolled = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "provider_enrolled"),
+		"MDM provider the device is enrolled with, as reported by the DMClient CSP",
+		[]string{"provider"},
+		nil,
+	)
+	c.enrollmentInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "enrollment_info"),
+		"Information about the MDM enrollment",
+		[]string{"enrollment_id", "provider", "upn"},
+		nil,
+	)
+	c.enrolled = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "enrolled"),
+		"1 if the MDM enrollment is completed, 0 otherwise",
+		[]string{"enrollment_id", "provider"},
+		nil,
+	)
+	c.lastSuccessfulSync = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "last_successful_sync_timestamp_seconds"),
+		"Time of the last successful OMA-DM session with the MDM server as unix timestamp",
+		[]string{"enrollment_id", "provider"},
+		nil,
+	)
+	c.lastSyncAttempt = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "last_sync_attempt_timestamp_seconds"),
+		"Time of the last OMA-DM session attempt with the MDM server as unix timestamp",
+		[]string{"enrollment_id", "provider"},
+		nil,
+	)
+	c.policyFailuresTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "policy_failures_total"),
+		"Number of failed CSP commands while applying MDM policies",
+		nil,
+		nil,
+	)
+
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.miSession = miSession
+
+	var dst []dmClientProvider
+	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2MDMDMMap, providerQuery); err != nil {
+		if !errors.Is(err, mi.MI_RESULT_INVALID_NAMESPACE) && !errors.Is(err, mi.MI_RESULT_ACCESS_DENIED) {
+			return fmt.Errorf("WMI query failed: %w", err)
+		}
+
+		c.logger.Debug("MDM WMI bridge is not available, windows_exporter must run as LocalSystem. Skipping provider metrics",
+			slog.Any("err", err),
+		)
+	} else {
+		c.providersEnabled = true
+	}
+
+	lastRecordID, err := wevtapi.LatestEventRecordID(diagnosticsChannel)
+	if err != nil {
+		if errors.Is(err, wevtapi.ERROR_EVT_CHANNEL_NOT_FOUND) {
+			c.logger.Debug("DeviceManagement diagnostics event log not found, skipping policy failure metrics")
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to read latest event of %s: %w", diagnosticsChannel, err)
+	}
+
+	c.lastRecordID = lastRecordID
+
+	c.renderContext, err = wevtapi.EvtCreateRenderContext(renderValuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	c.eventsEnabled = true
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if c.providersEnabled {
+		if err := c.collectProviders(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting provider metrics: %w", err))
+		}
+	}
+
+	if err := c.collectEnrollments(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting enrollment metrics: %w", err))
+	}
+
+	if c.eventsEnabled {
+		if err := c.collectPolicyFailures(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting policy failure metrics: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectProviders(ch chan<- prometheus.Metric) error {
+	var dst []dmClientProvider
+	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2MDMDMMap, providerQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	for _, provider := range dst {
+		ch <- prometheus.MustNewConstMetric(
+			c.providerEnrolled,
+			prometheus.GaugeValue,
+			1.0,
+			provider.InstanceID,
+		)
+	}
+
+	return nil
+}
+
+// collectEnrollments reports every enrollment with a provider. Enrollments without a provider are created by
+// Windows for local provisioning packages and are not managed by an MDM server.
+func (c *Collector) collectEnrollments(ch chan<- prometheus.Metric) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, enrollmentsKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to open registry key %s: %w", enrollmentsKey, err)
+	}
+
+	defer key.Close()
+
+	enrollmentIDs, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return fmt.Errorf("failed to read registry key %s: %w", enrollmentsKey, err)
+	}
+
+	for _, enrollmentID := range enrollmentIDs {
+		enrollmentKey, err := registry.OpenKey(key, enrollmentID, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+
+		provider, _, _ := enrollmentKey.GetStringValue("ProviderID")
+		upn, _, _ := enrollmentKey.GetStringValue("UPN")
+		state, _, _ := enrollmentKey.GetIntegerValue("EnrollmentState")
+
+		enrollmentKey.Close()
+
+		if provider == "" {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.enrollmentInfo,
+			prometheus.GaugeValue,
+			1.0,
+			enrollmentID,
+			provider,
+			upn,
+		)
+
+		enrolled := 0.0
+		if state == enrollmentStateEnrolled {
+			enrolled = 1.0
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.enrolled,
+			prometheus.GaugeValue,
+			enrolled,
+			enrollmentID,
+			provider,
+		)
+
+		c.collectSync(ch, enrollmentID, provider)
+	}
+
+	return nil
+}
+
+// collectSync reports the OMA-DM session timestamps of the enrollment. They are only present once the device
+// has contacted the MDM server.
+func (c *Collector) collectSync(ch chan<- prometheus.Metric, enrollmentID, provider string) {
+	keyPath := omaDMAccountsKey + `\` + enrollmentID + `\Protected\ConnInfo`
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.QUERY_VALUE)
+	if err != nil {
+		if !errors.Is(err, registry.ErrNotExist) {
+			c.logger.Debug("failed to open OMA-DM connection info",
+				slog.String("enrollment_id", enrollmentID),
+				slog.Any("err", err),
+			)
+		}
+
+		return
+	}
+
+	defer key.Close()
+
+	for valueName, desc := range map[string]*prometheus.Desc{
+		"ServerLastSuccessTime": c.lastSuccessfulSync,
+		"ServerLastAccessTime":  c.lastSyncAttempt,
+	} {
+		value, _, err := key.GetStringValue(valueName)
+		if err != nil {
+			continue
+		}
+
+		timestamp, err := parseServerTime(value)
+		if err != nil {
+			c.logger.Debug("failed to parse OMA-DM session time",
+				slog.String("enrollment_id", enrollmentID),
+				slog.String("value", valueName),
+				slog.Any("err", err),
+			)
+
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			desc,
+			prometheus.GaugeValue,
+			float64(timestamp.Unix()),
+			enrollmentID,
+			provider,
+		)
+	}
+}
+
+func parseServerTime(value string) (time.Time, error) {
+	var errs []error
+
+	for _, layout := range serverTimeLayouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t, nil
+		}
+
+		errs = append(errs, err)
+	}
+
+	return time.Time{}, errors.Join(errs...)
+}
+
+func (c *Collector) collectPolicyFailures(ch chan<- prometheus.Metric) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	query := fmt.Sprintf("*[System[EventID=%d and EventRecordID > %d]]", eventIDCommandFailure, c.lastRecordID)
+
+	if err := wevtapi.QueryValues(diagnosticsChannel, query, c.renderContext, c.handleEvent); err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", diagnosticsChannel, err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.policyFailuresTotal,
+		prometheus.CounterValue,
+		c.policyFailures,
+	)
+
+	return nil
+}
+
+func (c *Collector) handleEvent(values []any) {
+	if len(values) != len(renderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[valueEventRecordID].(uint64)
+	c.lastRecordID = max(c.lastRecordID, recordID)
+	c.policyFailures++
+}
@@ -18,14 +18,17 @@
 package mscluster
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -34,14 +37,21 @@ const (
 	Name = "mscluster"
 
 	subCollectorCluster       = "cluster"
+	subCollectorDisk          = "disk"
 	subCollectorNetwork       = "network"
 	subCollectorNode          = "node"
 	subCollectorResource      = "resource"
 	subCollectorResourceGroup = "resourcegroup"
+	subCollectorSharedVolume  = "sharedvolume"
 )
 
 type Config struct {
 	CollectorsEnabled []string `yaml:"enabled"`
+	// LeaderElection restricts the cluster-scoped metrics to the node that owns LeaderGroup, so an exporter
+	// can run on every node of the cluster without duplicate series.
+	LeaderElection bool `yaml:"leader_election"`
+	// LeaderGroup is the name of the resource group whose owner node is the leader. The core cluster group is used if empty.
+	LeaderGroup string `yaml:"leader_group"`
 }
 
 //nolint:gochecknoglobals
@@ -52,16 +62,22 @@ var ConfigDefaults = Config{
 		subCollectorNode,
 		subCollectorResource,
 		subCollectorResourceGroup,
+		subCollectorSharedVolume,
 	},
+	LeaderElection: false,
+	LeaderGroup:    "",
 }
 
 // A Collector is a Prometheus Collector for WMI MSCluster_Cluster metrics.
 type Collector struct {
 	collectorCluster
+	collectorDisk
 	collectorNetwork
 	collectorNode
 	collectorResource
 	collectorResourceGroup
+	collectorSharedVolume
+	collectorLeader
 
 	config    Config
 	miSession *mi.Session
@@ -96,6 +112,16 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 		"Comma-separated list of collectors to use.",
 	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
 
+	app.Flag(
+		"collector.mscluster.leader-election",
+		"Export the cluster-scoped metrics only on the node that owns the leader group. Node-local metrics are exported on every node.",
+	).Default(strconv.FormatBool(ConfigDefaults.LeaderElection)).BoolVar(&c.config.LeaderElection)
+
+	app.Flag(
+		"collector.mscluster.leader-group",
+		"Name of the resource group whose owner node is the leader. Defaults to the core cluster group.",
+	).Default(ConfigDefaults.LeaderGroup).StringVar(&c.config.LeaderGroup)
+
 	app.Action(func(*kingpin.ParseContext) error {
 		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
 
@@ -110,10 +136,18 @@ func (c *Collector) GetName() string {
 }
 
 func (c *Collector) Close() error {
+	if c.sharedVolumePerfDataCollector != nil {
+		c.sharedVolumePerfDataCollector.Close()
+	}
+
+	if c.networkEventRenderContext != 0 {
+		return wevtapi.EvtClose(c.networkEventRenderContext)
+	}
+
 	return nil
 }
 
-func (c *Collector) Build(_ *slog.Logger, miSession *mi.Session) error {
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
 	if len(c.config.CollectorsEnabled) == 0 {
 		return nil
 	}
@@ -126,6 +160,12 @@ func (c *Collector) Build(_ *slog.Logger, miSession *mi.Session) error {
 
 	errs := make([]error, 0)
 
+	if c.config.LeaderElection {
+		if err := c.buildLeader(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to build leader election: %w", err))
+		}
+	}
+
 	if slices.Contains(c.config.CollectorsEnabled, subCollectorCluster) {
 		if err := c.buildCluster(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to build cluster collector: %w", err))
@@ -156,26 +196,56 @@ func (c *Collector) Build(_ *slog.Logger, miSession *mi.Session) error {
 		}
 	}
 
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorDisk) {
+		if err := c.buildDisk(logger.With(slog.String("collector", Name))); err != nil {
+			errs = append(errs, fmt.Errorf("failed to build disk collector: %w", err))
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorSharedVolume) {
+		if err := c.buildSharedVolume(logger.With(slog.String("collector", Name))); err != nil {
+			errs = append(errs, fmt.Errorf("failed to build shared volume collector: %w", err))
+		}
+	}
+
 	return errors.Join(errs...)
 }
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
 func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	return c.CollectWithContext(context.Background(), ch)
+}
+
+// CollectWithContext collects like Collect, but cancels the WMI queries when ctx is done, so a hung
+// cluster provider doesn't block the collector beyond the scrape timeout.
+func (c *Collector) CollectWithContext(ctx context.Context, ch chan<- prometheus.Metric) error {
 	if len(c.config.CollectorsEnabled) == 0 {
 		return nil
 	}
 
-	errCh := make(chan error, 5)
+	errCh := make(chan error, 8)
+
+	// clusterScoped is false on the nodes that are not the leader. They export the node-local metrics only.
+	clusterScoped := true
+
+	if c.config.LeaderElection {
+		leader, err := c.collectLeader(ctx, ch)
+		if err != nil {
+			errCh <- fmt.Errorf("failed to collect leader metrics: %w", err)
+		}
+
+		clusterScoped = leader
+	}
 
 	wg := sync.WaitGroup{}
-	wg.Add(5)
+	wg.Add(7)
 
 	go func() {
 		defer wg.Done()
 
-		if slices.Contains(c.config.CollectorsEnabled, subCollectorCluster) {
-			if err := c.collectCluster(ch); err != nil {
+		if clusterScoped && slices.Contains(c.config.CollectorsEnabled, subCollectorCluster) {
+			if err := c.collectCluster(ctx, ch); err != nil {
 				errCh <- fmt.Errorf("failed to collect cluster metrics: %w", err)
 			}
 		}
@@ -185,21 +255,31 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		defer wg.Done()
 
 		if slices.Contains(c.config.CollectorsEnabled, subCollectorNetwork) {
-			if err := c.collectNetwork(ch); err != nil {
+			if err := c.collectNetwork(ctx, ch, clusterScoped); err != nil {
 				errCh <- fmt.Errorf("failed to collect network metrics: %w", err)
 			}
 		}
 	}()
 
+	go func() {
+		defer wg.Done()
+
+		if slices.Contains(c.config.CollectorsEnabled, subCollectorSharedVolume) {
+			if err := c.collectSharedVolume(ctx, ch, clusterScoped); err != nil {
+				errCh <- fmt.Errorf("failed to collect shared volume metrics: %w", err)
+			}
+		}
+	}()
+
 	go func() {
 		defer wg.Done()
 
 		nodeNames := make([]string, 0)
 
-		if slices.Contains(c.config.CollectorsEnabled, subCollectorNode) {
+		if clusterScoped && slices.Contains(c.config.CollectorsEnabled, subCollectorNode) {
 			var err error
 
-			nodeNames, err = c.collectNode(ch)
+			nodeNames, err = c.collectNode(ctx, ch)
 			if err != nil {
 				errCh <- fmt.Errorf("failed to collect node metrics: %w", err)
 			}
@@ -208,8 +288,8 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		go func() {
 			defer wg.Done()
 
-			if slices.Contains(c.config.CollectorsEnabled, subCollectorResource) {
-				if err := c.collectResource(ch, nodeNames); err != nil {
+			if clusterScoped && slices.Contains(c.config.CollectorsEnabled, subCollectorResource) {
+				if err := c.collectResource(ctx, ch, nodeNames); err != nil {
 					errCh <- fmt.Errorf("failed to collect resource metrics: %w", err)
 				}
 			}
@@ -218,12 +298,22 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		go func() {
 			defer wg.Done()
 
-			if slices.Contains(c.config.CollectorsEnabled, subCollectorResourceGroup) {
-				if err := c.collectResourceGroup(ch, nodeNames); err != nil {
+			if clusterScoped && slices.Contains(c.config.CollectorsEnabled, subCollectorResourceGroup) {
+				if err := c.collectResourceGroup(ctx, ch, nodeNames); err != nil {
 					errCh <- fmt.Errorf("failed to collect resource group metrics: %w", err)
 				}
 			}
 		}()
+
+		go func() {
+			defer wg.Done()
+
+			if clusterScoped && slices.Contains(c.config.CollectorsEnabled, subCollectorDisk) {
+				if err := c.collectDisk(ctx, ch, nodeNames); err != nil {
+					errCh <- fmt.Errorf("failed to collect disk metrics: %w", err)
+				}
+			}
+		}()
 	}()
 
 	wg.Wait()
@@ -237,3 +327,44 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 
 	return errors.Join(errs...)
 }
+
+// changeTracker counts the changes of a value per key between the collections, e.g. of the state of a resource.
+// Changes between two collections are counted once, keys that disappear are removed.
+type changeTracker struct {
+	mu      sync.Mutex
+	values  map[string]string
+	changes map[string]float64
+}
+
+func newChangeTracker() *changeTracker {
+	return &changeTracker{
+		values:  make(map[string]string),
+		changes: make(map[string]float64),
+	}
+}
+
+// update records the current values and returns the number of changes per key. The count of a new key starts at 0.
+func (t *changeTracker) update(values map[string]string) map[string]float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	changes := make(map[string]float64, len(values))
+
+	for key, value := range values {
+		if previous, ok := t.values[key]; ok && previous != value {
+			t.changes[key]++
+		}
+
+		changes[key] = t.changes[key]
+	}
+
+	for key := range t.values {
+		if _, ok := values[key]; !ok {
+			delete(t.changes, key)
+		}
+	}
+
+	t.values = values
+
+	return changes
+}
@@ -18,6 +18,7 @@
 package mscluster
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/prometheus-community/windows_exporter/internal/mi"
@@ -680,9 +681,9 @@ func (c *Collector) buildCluster() error {
 	return nil
 }
 
-func (c *Collector) collectCluster(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectCluster(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []msClusterCluster
-	if err := c.miSession.Query(&dst, mi.NamespaceRootMSCluster, c.clusterMIQuery); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootMSCluster, c.clusterMIQuery); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
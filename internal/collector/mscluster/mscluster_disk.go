@@ -0,0 +1,317 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mscluster
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	nameDisk = Name + "_disk"
+
+	ioctlStoragePersistentReserveIn = 0x2D5018 // IOCTL_STORAGE_PERSISTENT_RESERVE_IN
+
+	// Service actions of PERSISTENT RESERVE IN, see SPC-3.
+	reserveInReadKeys        = 0x00
+	reserveInReadReservation = 0x01
+)
+
+// reservationTypes are the names of the persistent reservation types of SPC-3, the type label.
+//
+//nolint:gochecknoglobals
+var reservationTypes = map[uint8]string{
+	1: "write_exclusive",
+	3: "exclusive_access",
+	5: "write_exclusive_registrants_only",
+	6: "exclusive_access_registrants_only",
+	7: "write_exclusive_all_registrants",
+	8: "exclusive_access_all_registrants",
+}
+
+type collectorDisk struct {
+	diskMIQuery mi.Query
+	diskLogger  *slog.Logger
+
+	diskOwnerChanges *changeTracker
+
+	diskOwnerNode                *prometheus.Desc
+	diskOwnerChangesTotal        *prometheus.Desc
+	diskReservationRegistrations *prometheus.Desc
+	diskReserved                 *prometheus.Desc
+	diskReservationGeneration    *prometheus.Desc
+}
+
+// msClusterDiskResource are the Physical Disk resources of the MSCluster_Resource WMI class.
+type msClusterDiskResource struct {
+	Name       string `mi:"Name"`
+	OwnerGroup string `mi:"OwnerGroup"`
+	OwnerNode  string `mi:"OwnerNode"`
+}
+
+// msClusterDisk represents the MSCluster_Disk WMI class
+// - https://learn.microsoft.com/en-us/previous-versions/windows/desktop/cluswmi/mscluster-disk
+type msClusterDisk struct {
+	Number uint32 `mi:"Number"`
+}
+
+// reservation is the persistent reservation state of a disk.
+type reservation struct {
+	generation    uint32
+	registrations int
+	// reservationType is empty if the disk is not reserved.
+	reservationType string
+}
+
+func (c *Collector) buildDisk(logger *slog.Logger) error {
+	diskMIQuery, err := mi.NewQuery("SELECT Name,OwnerGroup,OwnerNode FROM MSCluster_Resource WHERE Type = 'Physical Disk'")
+	if err != nil {
+		return fmt.Errorf("failed to create WMI query: %w", err)
+	}
+
+	c.diskMIQuery = diskMIQuery
+	c.diskLogger = logger
+	c.diskOwnerChanges = newChangeTracker()
+
+	c.diskOwnerNode = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameDisk, "owner_node"),
+		"The node owning the cluster disk. 0: Not owned; 1: Owned",
+		[]string{"owner_group", "node_name", "name"},
+		nil,
+	)
+	c.diskOwnerChangesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameDisk, "owner_changes_total"),
+		"Number of changes of the owner node of the cluster disk observed by the exporter.",
+		[]string{"owner_group", "name"},
+		nil,
+	)
+	c.diskReservationRegistrations = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameDisk, "reservation_registrations"),
+		"Number of persistent reservation keys registered on the cluster disk, usually one per node with access to the disk.",
+		[]string{"owner_group", "name"},
+		nil,
+	)
+	c.diskReserved = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameDisk, "reserved"),
+		"1 if the cluster disk holds a persistent reservation of the type, 0 if it is not reserved.",
+		[]string{"owner_group", "name", "type"},
+		nil,
+	)
+	c.diskReservationGeneration = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameDisk, "reservation_generation"),
+		"Generation of the persistent reservations of the cluster disk, which the disk increments with each registration, reservation and preemption.",
+		[]string{"owner_group", "name"},
+		nil,
+	)
+
+	var dst []msClusterDiskResource
+
+	if err := c.miSession.Query(&dst, mi.NamespaceRootMSCluster, c.diskMIQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectDisk(ctx context.Context, ch chan<- prometheus.Metric, nodeNames []string) error {
+	var dst []msClusterDiskResource
+
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootMSCluster, c.diskMIQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	owners := make(map[string]string, len(dst))
+	for _, v := range dst {
+		owners[v.Name] = v.OwnerNode
+	}
+
+	ownerChanges := c.diskOwnerChanges.update(owners)
+
+	for _, v := range dst {
+		for _, nodeName := range nodeNames {
+			isOwner := 0.0
+			if v.OwnerNode == nodeName {
+				isOwner = 1.0
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.diskOwnerNode,
+				prometheus.GaugeValue,
+				isOwner,
+				v.OwnerGroup, nodeName, v.Name,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.diskOwnerChangesTotal,
+			prometheus.CounterValue,
+			ownerChanges[v.Name],
+			v.OwnerGroup, v.Name,
+		)
+
+		r, err := c.queryDiskReservation(ctx, v.Name)
+		if err != nil {
+			// e.g. the disk is not attached to this node.
+			c.diskLogger.LogAttrs(ctx, slog.LevelDebug, "failed to query persistent reservation of cluster disk",
+				slog.String("disk", v.Name),
+				slog.Any("err", err),
+			)
+
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.diskReservationRegistrations,
+			prometheus.GaugeValue,
+			float64(r.registrations),
+			v.OwnerGroup, v.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.diskReservationGeneration,
+			prometheus.GaugeValue,
+			float64(r.generation),
+			v.OwnerGroup, v.Name,
+		)
+
+		reserved := 0.0
+		if r.reservationType != "" {
+			reserved = 1.0
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.diskReserved,
+			prometheus.GaugeValue,
+			reserved,
+			v.OwnerGroup, v.Name, r.reservationType,
+		)
+	}
+
+	return nil
+}
+
+// queryDiskReservation reads the persistent reservation of the disk of the Physical Disk resource via the local node.
+// The number of the disk on this node is read from the MSCluster_Disk associated with the resource.
+func (c *Collector) queryDiskReservation(ctx context.Context, resourceName string) (reservation, error) {
+	query, err := mi.NewQuery(fmt.Sprintf(
+		"ASSOCIATORS OF {MSCluster_Resource.Name='%s'} WHERE AssocClass = MSCluster_ResourceToDisk",
+		strings.ReplaceAll(resourceName, "'", `\'`),
+	))
+	if err != nil {
+		return reservation{}, fmt.Errorf("failed to create WMI query: %w", err)
+	}
+
+	var disks []msClusterDisk
+
+	if err := c.miSession.QueryContext(ctx, &disks, mi.NamespaceRootMSCluster, query); err != nil {
+		return reservation{}, fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	if len(disks) == 0 {
+		return reservation{}, fmt.Errorf("no disk associated with resource %s", resourceName)
+	}
+
+	return readReservation(`\\.\PhysicalDrive` + strconv.FormatUint(uint64(disks[0].Number), 10))
+}
+
+// readReservation sends the READ KEYS and READ RESERVATION service actions of PERSISTENT RESERVE IN to the disk.
+// Reading the reservations is allowed on nodes that don't hold the reservation.
+func readReservation(path string) (reservation, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return reservation{}, err
+	}
+
+	mode := uint32(windows.FILE_SHARE_READ | windows.FILE_SHARE_WRITE | windows.FILE_SHARE_DELETE)
+
+	handle, err := windows.CreateFile(pathPtr, windows.GENERIC_READ, mode, nil, windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		return reservation{}, err
+	}
+
+	defer func(fd windows.Handle) {
+		_ = windows.Close(fd)
+	}(handle)
+
+	keys, err := persistentReserveIn(handle, reserveInReadKeys)
+	if err != nil {
+		return reservation{}, fmt.Errorf("failed to read reservation keys: %w", err)
+	}
+
+	reservations, err := persistentReserveIn(handle, reserveInReadReservation)
+	if err != nil {
+		return reservation{}, fmt.Errorf("failed to read reservation: %w", err)
+	}
+
+	return parseReservation(keys, reservations)
+}
+
+// persistentReserveIn returns the parameter data of the service action, e.g. PRI_REGISTRATION_LIST for READ KEYS.
+func persistentReserveIn(handle windows.Handle, serviceAction uint8) ([]byte, error) {
+	data := make([]byte, 4096)
+
+	// PERSISTENT_RESERVE_COMMAND with Version and Size, followed by ServiceAction and AllocationLength of PR_IN.
+	command := make([]byte, 12)
+	binary.LittleEndian.PutUint32(command[0:], uint32(len(command)))
+	binary.LittleEndian.PutUint32(command[4:], uint32(len(command)))
+	command[8] = serviceAction
+	binary.LittleEndian.PutUint16(command[10:], uint16(len(data)))
+
+	var bytesReturned uint32
+
+	err := windows.DeviceIoControl(handle, ioctlStoragePersistentReserveIn, &command[0], uint32(len(command)), &data[0], uint32(len(data)), &bytesReturned, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return data[:bytesReturned], nil
+}
+
+// parseReservation parses the PRI_REGISTRATION_LIST and PRI_RESERVATION_LIST of PERSISTENT RESERVE IN.
+// Both start with the big endian generation and the length of the list; each key is 8 bytes long,
+// each reservation descriptor 16 bytes with the type in the lower nibble of byte 13.
+func parseReservation(keys, reservations []byte) (reservation, error) {
+	if len(keys) < 8 || len(reservations) < 8 {
+		return reservation{}, fmt.Errorf("persistent reservation data too short: %d and %d bytes", len(keys), len(reservations))
+	}
+
+	r := reservation{
+		generation:    binary.BigEndian.Uint32(reservations),
+		registrations: int(binary.BigEndian.Uint32(keys[4:]) / 8),
+	}
+
+	if length := binary.BigEndian.Uint32(reservations[4:]); length >= 16 && len(reservations) >= 8+16 {
+		reservationType, ok := reservationTypes[reservations[8+13]&0x0f]
+		if !ok {
+			reservationType = "unknown"
+		}
+
+		r.reservationType = reservationType
+	}
+
+	return r, nil
+}
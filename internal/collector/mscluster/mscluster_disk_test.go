@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mscluster
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReservation(t *testing.T) {
+	t.Parallel()
+
+	keys := make([]byte, 8+3*8)
+	binary.BigEndian.PutUint32(keys, 42)
+	binary.BigEndian.PutUint32(keys[4:], 3*8)
+
+	reservations := make([]byte, 8+16)
+	binary.BigEndian.PutUint32(reservations, 42)
+	binary.BigEndian.PutUint32(reservations[4:], 16)
+	reservations[8+13] = 0x05
+
+	r, err := parseReservation(keys, reservations)
+	require.NoError(t, err)
+	require.Equal(t, reservation{generation: 42, registrations: 3, reservationType: "write_exclusive_registrants_only"}, r)
+
+	binary.BigEndian.PutUint32(reservations[4:], 0)
+
+	r, err = parseReservation(keys, reservations[:8])
+	require.NoError(t, err)
+	require.Empty(t, r.reservationType)
+
+	_, err = parseReservation(keys[:4], reservations)
+	require.Error(t, err)
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mscluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/sysinfoapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// groupTypeCluster is the GroupType of the core cluster group, which holds the cluster name and IP address.
+// - https://learn.microsoft.com/en-us/previous-versions/windows/desktop/cluswmi/mscluster-resourcegroup
+const groupTypeCluster = 1
+
+type collectorLeader struct {
+	leaderMIQuery mi.Query
+	// leaderNodeName is the NetBIOS name of the local node, which is the name of the node in the cluster.
+	leaderNodeName string
+
+	leader *prometheus.Desc
+}
+
+type msClusterLeaderGroup struct {
+	Name      string `mi:"Name"`
+	GroupType uint   `mi:"GroupType"`
+	OwnerNode string `mi:"OwnerNode"`
+}
+
+func (c *Collector) buildLeader() error {
+	leaderMIQuery, err := mi.NewQuery("SELECT Name,GroupType,OwnerNode FROM MSCluster_ResourceGroup")
+	if err != nil {
+		return fmt.Errorf("failed to create WMI query: %w", err)
+	}
+
+	c.leaderMIQuery = leaderMIQuery
+
+	c.leaderNodeName, err = sysinfoapi.GetComputerName(sysinfoapi.ComputerNameNetBIOS)
+	if err != nil {
+		return fmt.Errorf("failed to get computer name: %w", err)
+	}
+
+	c.leader = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "leader"),
+		"1 if this node owns the leader group and exports the cluster-scoped metrics, 0 otherwise.",
+		[]string{"group"},
+		nil,
+	)
+
+	return nil
+}
+
+// collectLeader reports whether the local node owns the leader group. The leader group is the group configured
+// by leader_group, or the core cluster group if it is empty. Ownership moves with the group on failover, so exactly
+// one node exports the cluster-scoped metrics as long as the group is online.
+func (c *Collector) collectLeader(ctx context.Context, ch chan<- prometheus.Metric) (bool, error) {
+	var dst []msClusterLeaderGroup
+
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootMSCluster, c.leaderMIQuery); err != nil {
+		return false, fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	for _, group := range dst {
+		if c.config.LeaderGroup == "" && group.GroupType != groupTypeCluster {
+			continue
+		}
+
+		if c.config.LeaderGroup != "" && !strings.EqualFold(group.Name, c.config.LeaderGroup) {
+			continue
+		}
+
+		leader := strings.EqualFold(group.OwnerNode, c.leaderNodeName)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.leader,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(leader),
+			group.Name,
+		)
+
+		return leader, nil
+	}
+
+	if c.config.LeaderGroup == "" {
+		return false, errors.New("core cluster group not found")
+	}
+
+	return false, fmt.Errorf("leader group %q not found", c.config.LeaderGroup)
+}
@@ -18,23 +18,76 @@
 package mscluster
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
 
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
 	"github.com/prometheus-community/windows_exporter/internal/types"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-const nameNetwork = Name + "_network"
+const (
+	nameNetwork = Name + "_network"
+
+	systemChannel = "System"
+
+	// Failover Clustering events logged when the cluster network driver detects missed heartbeats.
+	eventIDNetworkInterfaceUnreachable = 1126
+	eventIDNetworkInterfaceFailed      = 1127
+	eventIDNetworkPartitioned          = 1129
+	eventIDNetworkDown                 = 1130
+	eventIDNodeRemoved                 = 1135
+)
+
+//nolint:gochecknoglobals
+var (
+	networkEventQuery = fmt.Sprintf(
+		"*[System[Provider[@Name='Microsoft-Windows-FailoverClustering'] and (EventID=%d or EventID=%d or EventID=%d or EventID=%d or EventID=%d) and EventRecordID > %%d]]",
+		eventIDNetworkInterfaceUnreachable, eventIDNetworkInterfaceFailed, eventIDNetworkPartitioned, eventIDNetworkDown, eventIDNodeRemoved,
+	)
+
+	// networkEventRenderValuePaths are the event properties rendered for each Failover Clustering event.
+	// The order must match the networkEventValue* indices.
+	networkEventRenderValuePaths = []string{
+		"Event/System/EventRecordID",
+		"Event/System/EventID",
+	}
+
+	networkEventTypes = map[uint64]string{
+		eventIDNetworkInterfaceUnreachable: "interface_unreachable",
+		eventIDNetworkInterfaceFailed:      "interface_failed",
+		eventIDNetworkPartitioned:          "network_partitioned",
+		eventIDNetworkDown:                 "network_down",
+		eventIDNodeRemoved:                 "node_removed",
+	}
+)
+
+const (
+	networkEventValueEventRecordID = iota
+	networkEventValueEventID
+)
 
 type collectorNetwork struct {
-	networkMIQuery mi.Query
+	networkMIQuery          mi.Query
+	networkInterfaceMIQuery mi.Query
+
+	networkEventRenderContext wevtapi.EVT_HANDLE
+
+	// networkEventMu protects the event counters and networkEventLastRecordID against concurrent scrapes.
+	networkEventMu           sync.Mutex
+	networkEventLastRecordID uint64
+	networkEventCounts       map[string]float64
 
 	networkCharacteristics *prometheus.Desc
 	networkFlags           *prometheus.Desc
 	networkMetric          *prometheus.Desc
 	networkRole            *prometheus.Desc
 	networkState           *prometheus.Desc
+	networkInterfaceState  *prometheus.Desc
+	networkEventsTotal     *prometheus.Desc
 }
 
 // msClusterNetwork represents the MSCluster_Network WMI class
@@ -49,14 +102,30 @@ type msClusterNetwork struct {
 	State           uint `mi:"State"`
 }
 
+// msClusterNetworkInterface represents the MSCluster_NetworkInterface WMI class
+// - https://learn.microsoft.com/en-us/previous-versions/windows/desktop/cluswmi/mscluster-networkinterface
+type msClusterNetworkInterface struct {
+	Name    string `mi:"Name"`
+	Node    string `mi:"Node"`
+	Network string `mi:"Network"`
+	State   uint   `mi:"State"`
+}
+
 func (c *Collector) buildNetwork() error {
-	networkMIQuery, err := mi.NewQuery("SELECT Characteristics,Flags,Metric,Role,State FROM MSCluster_Network")
+	networkMIQuery, err := mi.NewQuery("SELECT Name,Characteristics,Flags,Metric,Role,State FROM MSCluster_Network")
 	if err != nil {
 		return fmt.Errorf("failed to create WMI query: %w", err)
 	}
 
 	c.networkMIQuery = networkMIQuery
 
+	networkInterfaceMIQuery, err := mi.NewQuery("SELECT Name,Node,Network,State FROM MSCluster_NetworkInterface")
+	if err != nil {
+		return fmt.Errorf("failed to create WMI query: %w", err)
+	}
+
+	c.networkInterfaceMIQuery = networkInterfaceMIQuery
+
 	c.networkCharacteristics = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, nameNetwork, "characteristics"),
 		"Provides the characteristics of the network.",
@@ -88,21 +157,62 @@ func (c *Collector) buildNetwork() error {
 		nil,
 	)
 
+	c.networkInterfaceState = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameNetwork, "interface_state"),
+		"Provides the current state of the network interface of a node. -1: Unknown; 0: Unavailable; 1: Failed; 2: Unreachable; 3: Up",
+		[]string{"name", "node", "network"},
+		nil,
+	)
+	c.networkEventsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameNetwork, "events_total"),
+		"Number of Failover Clustering network events logged since the exporter started (events 1126, 1127, 1129, 1130 and 1135)",
+		[]string{"event"},
+		nil,
+	)
+
 	var dst []msClusterNetwork
 
 	if err := c.miSession.Query(&dst, mi.NamespaceRootMSCluster, c.networkMIQuery); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
+	c.networkEventCounts = make(map[string]float64, len(networkEventTypes))
+	for _, eventType := range networkEventTypes {
+		c.networkEventCounts[eventType] = 0
+	}
+
+	c.networkEventLastRecordID, err = wevtapi.LatestEventRecordID(systemChannel)
+	if err != nil {
+		return fmt.Errorf("failed to read latest event of %s: %w", systemChannel, err)
+	}
+
+	c.networkEventRenderContext, err = wevtapi.EvtCreateRenderContext(networkEventRenderValuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
 	return nil
 }
 
 // Collect sends the metric values for each metric
-// to the provided prometheus metric channel.
-func (c *Collector) collectNetwork(ch chan<- prometheus.Metric) error {
+// to the provided prometheus metric channel. The state of the networks and interfaces is cluster-scoped,
+// the events are those of the local node.
+func (c *Collector) collectNetwork(ctx context.Context, ch chan<- prometheus.Metric, clusterScoped bool) error {
+	if !clusterScoped {
+		return c.collectNetworkEvents(ch)
+	}
+
+	return errors.Join(
+		c.collectNetworkState(ctx, ch),
+		c.collectNetworkInterfaces(ctx, ch),
+		c.collectNetworkEvents(ch),
+	)
+}
+
+func (c *Collector) collectNetworkState(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []msClusterNetwork
 
-	if err := c.miSession.Query(&dst, mi.NamespaceRootMSCluster, c.networkMIQuery); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootMSCluster, c.networkMIQuery); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -145,3 +255,63 @@ func (c *Collector) collectNetwork(ch chan<- prometheus.Metric) error {
 
 	return nil
 }
+
+func (c *Collector) collectNetworkInterfaces(ctx context.Context, ch chan<- prometheus.Metric) error {
+	var dst []msClusterNetworkInterface
+
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootMSCluster, c.networkInterfaceMIQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	for _, v := range dst {
+		ch <- prometheus.MustNewConstMetric(
+			c.networkInterfaceState,
+			prometheus.GaugeValue,
+			float64(int32(v.State)),
+			v.Name,
+			v.Node,
+			v.Network,
+		)
+	}
+
+	return nil
+}
+
+// collectNetworkEvents counts the events of the local node since the exporter started. The events explain
+// node evictions after the fact, since the interface and network state return to up once the heartbeats recover.
+func (c *Collector) collectNetworkEvents(ch chan<- prometheus.Metric) error {
+	c.networkEventMu.Lock()
+	defer c.networkEventMu.Unlock()
+
+	query := fmt.Sprintf(networkEventQuery, c.networkEventLastRecordID)
+
+	if err := wevtapi.QueryValues(systemChannel, query, c.networkEventRenderContext, c.handleNetworkEvent); err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", systemChannel, err)
+	}
+
+	for eventType, count := range c.networkEventCounts {
+		ch <- prometheus.MustNewConstMetric(
+			c.networkEventsTotal,
+			prometheus.CounterValue,
+			count,
+			eventType,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) handleNetworkEvent(values []any) {
+	if len(values) != len(networkEventRenderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[networkEventValueEventRecordID].(uint64)
+	c.networkEventLastRecordID = max(c.networkEventLastRecordID, recordID)
+
+	eventID, _ := values[networkEventValueEventID].(uint64)
+
+	if eventType, ok := networkEventTypes[eventID]; ok {
+		c.networkEventCounts[eventType]++
+	}
+}
@@ -18,11 +18,13 @@
 package mscluster
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/prometheus-community/windows_exporter/internal/mi"
 	"github.com/prometheus-community/windows_exporter/internal/osversion"
 	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -43,6 +45,7 @@ type collectorNode struct {
 	nodeNodeHighestVersion    *prometheus.Desc
 	nodeNodeLowestVersion     *prometheus.Desc
 	nodeNodeWeight            *prometheus.Desc
+	nodeQuorumVote            *prometheus.Desc
 	nodeState                 *prometheus.Desc
 	nodeStatusInformation     *prometheus.Desc
 }
@@ -155,6 +158,12 @@ func (c *Collector) buildNode() error {
 		[]string{"name"},
 		nil,
 	)
+	c.nodeQuorumVote = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameNode, "quorum_vote"),
+		"Whether the node currently contributes a vote to the quorum, i.e. it is up and has a dynamic weight.",
+		[]string{"name"},
+		nil,
+	)
 	c.nodeState = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, nameNode, "state"),
 		"Returns the current state of a node. -1: Unknown; 0: Up; 1: Down; 2: Paused; 3: Joining",
@@ -179,10 +188,10 @@ func (c *Collector) buildNode() error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) collectNode(ch chan<- prometheus.Metric) ([]string, error) {
+func (c *Collector) collectNode(ctx context.Context, ch chan<- prometheus.Metric) ([]string, error) {
 	var dst []msClusterNode
 
-	if err := c.miSession.Query(&dst, mi.NamespaceRootMSCluster, c.nodeMIQuery); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootMSCluster, c.nodeMIQuery); err != nil {
 		return nil, fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -273,6 +282,13 @@ func (c *Collector) collectNode(ch chan<- prometheus.Metric) ([]string, error) {
 			v.Name,
 		)
 
+		ch <- prometheus.MustNewConstMetric(
+			c.nodeQuorumVote,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(v.State == 0 && v.DynamicWeight > 0),
+			v.Name,
+		)
+
 		ch <- prometheus.MustNewConstMetric(
 			c.nodeState,
 			prometheus.GaugeValue,
@@ -18,7 +18,9 @@
 package mscluster
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/prometheus-community/windows_exporter/internal/mi"
 	"github.com/prometheus-community/windows_exporter/internal/types"
@@ -30,6 +32,8 @@ const nameResource = Name + "_resource"
 type collectorResource struct {
 	resourceMIQuery mi.Query
 
+	resourceStateChanges *changeTracker
+
 	resourceCharacteristics        *prometheus.Desc
 	resourceDeadlockTimeout        *prometheus.Desc
 	resourceEmbeddedFailureAction  *prometheus.Desc
@@ -46,6 +50,7 @@ type collectorResource struct {
 	resourceRestartThreshold       *prometheus.Desc
 	resourceRetryPeriodOnFailure   *prometheus.Desc
 	resourceState                  *prometheus.Desc
+	resourceStateChangesTotal      *prometheus.Desc
 	resourceSubClass               *prometheus.Desc
 }
 
@@ -82,6 +87,7 @@ func (c *Collector) buildResource() error {
 	}
 
 	c.resourceMIQuery = resourceMIQuery
+	c.resourceStateChanges = newChangeTracker()
 
 	c.resourceCharacteristics = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, nameResource, "characteristics"),
@@ -185,6 +191,12 @@ func (c *Collector) buildResource() error {
 		[]string{"type", "owner_group", "name"},
 		nil,
 	)
+	c.resourceStateChangesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameResource, "state_changes_total"),
+		"Number of state changes of the resource observed between the collections.",
+		[]string{"type", "owner_group", "name"},
+		nil,
+	)
 	c.resourceSubClass = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, nameResource, "subclass"),
 		"Provides the list of references to nodes that can be the owner of this resource.",
@@ -203,13 +215,20 @@ func (c *Collector) buildResource() error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) collectResource(ch chan<- prometheus.Metric, nodeNames []string) error {
+func (c *Collector) collectResource(ctx context.Context, ch chan<- prometheus.Metric, nodeNames []string) error {
 	var dst []msClusterResource
 
-	if err := c.miSession.Query(&dst, mi.NamespaceRootMSCluster, c.resourceMIQuery); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootMSCluster, c.resourceMIQuery); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
+	states := make(map[string]string, len(dst))
+	for _, v := range dst {
+		states[v.Name] = strconv.FormatUint(uint64(v.State), 10)
+	}
+
+	stateChanges := c.resourceStateChanges.update(states)
+
 	for _, v := range dst {
 		ch <- prometheus.MustNewConstMetric(
 			c.resourceCharacteristics,
@@ -330,6 +349,13 @@ func (c *Collector) collectResource(ch chan<- prometheus.Metric, nodeNames []str
 			v.Type, v.OwnerGroup, v.Name,
 		)
 
+		ch <- prometheus.MustNewConstMetric(
+			c.resourceStateChangesTotal,
+			prometheus.CounterValue,
+			stateChanges[v.Name],
+			v.Type, v.OwnerGroup, v.Name,
+		)
+
 		ch <- prometheus.MustNewConstMetric(
 			c.resourceSubClass,
 			prometheus.GaugeValue,
@@ -18,10 +18,15 @@
 package mscluster
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
+	"github.com/prometheus-community/windows_exporter/internal/headers/clusapi"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
 	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -30,6 +35,9 @@ const nameResourceGroup = Name + "_resourcegroup"
 type collectorResourceGroup struct {
 	resourceGroupMIQuery mi.Query
 
+	resourceGroupStateChanges *changeTracker
+	resourceGroupOwnerChanges *changeTracker
+
 	resourceGroupAutoFailbackType    *prometheus.Desc
 	resourceGroupCharacteristics     *prometheus.Desc
 	resourceGroupColdStartSetting    *prometheus.Desc
@@ -40,10 +48,13 @@ type collectorResourceGroup struct {
 	resourceGroupFailOverThreshold   *prometheus.Desc
 	resourceGroupFlags               *prometheus.Desc
 	resourceGroupGroupType           *prometheus.Desc
+	resourceGroupOnPreferredOwner    *prometheus.Desc
+	resourceGroupOwnerChangesTotal   *prometheus.Desc
 	resourceGroupOwnerNode           *prometheus.Desc
 	resourceGroupPriority            *prometheus.Desc
 	resourceGroupResiliencyPeriod    *prometheus.Desc
 	resourceGroupState               *prometheus.Desc
+	resourceGroupStateChangesTotal   *prometheus.Desc
 }
 
 // msClusterResourceGroup represents the MSCluster_ResourceGroup WMI class
@@ -74,6 +85,8 @@ func (c *Collector) buildResourceGroup() error {
 	}
 
 	c.resourceGroupMIQuery = resourceGroupMIQuery
+	c.resourceGroupStateChanges = newChangeTracker()
+	c.resourceGroupOwnerChanges = newChangeTracker()
 
 	c.resourceGroupAutoFailbackType = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, nameResourceGroup, "auto_failback_type"),
@@ -135,10 +148,16 @@ func (c *Collector) buildResourceGroup() error {
 		[]string{"name"},
 		nil,
 	)
-	c.resourceGroupOwnerNode = prometheus.NewDesc(
-		prometheus.BuildFQName(types.Namespace, nameResourceGroup, "owner_node"),
-		"The node hosting the resource group. 0: Not hosted; 1: Hosted",
-		[]string{"node_name", "name"},
+	c.resourceGroupOnPreferredOwner = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameResourceGroup, "on_preferred_owner"),
+		"Whether the resource group is hosted by its most preferred owner. Not exported for groups without preferred owners.",
+		[]string{"name"},
+		nil,
+	)
+	c.resourceGroupOwnerChangesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameResourceGroup, "owner_changes_total"),
+		"Number of owner node changes of the resource group observed between the collections, e.g. by failovers.",
+		[]string{"name"},
 		nil,
 	)
 	c.resourceGroupOwnerNode = prometheus.NewDesc(
@@ -165,6 +184,12 @@ func (c *Collector) buildResourceGroup() error {
 		[]string{"name"},
 		nil,
 	)
+	c.resourceGroupStateChangesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameResourceGroup, "state_changes_total"),
+		"Number of state changes of the resource group observed between the collections.",
+		[]string{"name"},
+		nil,
+	)
 
 	var dst []msClusterResourceGroup
 
@@ -177,13 +202,30 @@ func (c *Collector) buildResourceGroup() error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) collectResourceGroup(ch chan<- prometheus.Metric, nodeNames []string) error {
+func (c *Collector) collectResourceGroup(ctx context.Context, ch chan<- prometheus.Metric, nodeNames []string) error {
 	var dst []msClusterResourceGroup
 
-	if err := c.miSession.Query(&dst, mi.NamespaceRootMSCluster, c.resourceGroupMIQuery); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootMSCluster, c.resourceGroupMIQuery); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
+	groupNames := make([]string, 0, len(dst))
+	states := make(map[string]string, len(dst))
+	owners := make(map[string]string, len(dst))
+
+	for _, v := range dst {
+		groupNames = append(groupNames, v.Name)
+		states[v.Name] = strconv.FormatUint(uint64(v.State), 10)
+		owners[v.Name] = v.OwnerNode
+	}
+
+	stateChanges := c.resourceGroupStateChanges.update(states)
+	ownerChanges := c.resourceGroupOwnerChanges.update(owners)
+
+	// The preferred owners are not part of MSCluster_ResourceGroup. If they can't be read,
+	// the other metrics are still sent.
+	preferredOwners, preferredOwnersErr := clusapi.GroupPreferredOwners(groupNames)
+
 	for _, v := range dst {
 		ch <- prometheus.MustNewConstMetric(
 			c.resourceGroupAutoFailbackType,
@@ -255,6 +297,22 @@ func (c *Collector) collectResourceGroup(ch chan<- prometheus.Metric, nodeNames
 			v.Name,
 		)
 
+		if groupPreferredOwners := preferredOwners[v.Name]; len(groupPreferredOwners) > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.resourceGroupOnPreferredOwner,
+				prometheus.GaugeValue,
+				utils.BoolToFloat(strings.EqualFold(v.OwnerNode, groupPreferredOwners[0])),
+				v.Name,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.resourceGroupOwnerChangesTotal,
+			prometheus.CounterValue,
+			ownerChanges[v.Name],
+			v.Name,
+		)
+
 		for _, nodeName := range nodeNames {
 			isCurrentState := 0.0
 			if v.OwnerNode == nodeName {
@@ -289,6 +347,17 @@ func (c *Collector) collectResourceGroup(ch chan<- prometheus.Metric, nodeNames
 			float64(v.State),
 			v.Name,
 		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.resourceGroupStateChangesTotal,
+			prometheus.CounterValue,
+			stateChanges[v.Name],
+			v.Name,
+		)
+	}
+
+	if preferredOwnersErr != nil {
+		return fmt.Errorf("failed to get preferred owners: %w", preferredOwnersErr)
 	}
 
 	return nil
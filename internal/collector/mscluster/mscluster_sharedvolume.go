@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mscluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const nameSharedVolume = Name + "_sharedvolume"
+
+type collectorSharedVolume struct {
+	sharedVolumeMIQuery mi.Query
+
+	// sharedVolumePerfDataCollector is nil if the node has no Cluster CSVFS counters, e.g. without CSV.
+	sharedVolumePerfDataCollector *pdh.Collector
+	sharedVolumePerfDataObject    []perfDataCounterValuesSharedVolume
+
+	sharedVolumeSizeBytes       *prometheus.Desc
+	sharedVolumeFreeBytes       *prometheus.Desc
+	sharedVolumeReadBytesTotal  *prometheus.Desc
+	sharedVolumeWriteBytesTotal *prometheus.Desc
+	sharedVolumeReadsTotal      *prometheus.Desc
+	sharedVolumeWritesTotal     *prometheus.Desc
+}
+
+// msClusterDiskPartition represents the MSCluster_DiskPartition WMI class
+// - https://learn.microsoft.com/en-us/previous-versions/windows/desktop/cluswmi/mscluster-diskpartition
+type msClusterDiskPartition struct {
+	Path        string `mi:"Path"`
+	VolumeLabel string `mi:"VolumeLabel"`
+	// FreeSpace and TotalSize are in MB.
+	FreeSpace uint32 `mi:"FreeSpace"`
+	TotalSize uint32 `mi:"TotalSize"`
+}
+
+type perfDataCounterValuesSharedVolume struct {
+	Name string
+
+	ReadBytes  float64 `perfdata:"Read Bytes/sec"`
+	WriteBytes float64 `perfdata:"Write Bytes/sec"`
+	Reads      float64 `perfdata:"Reads/sec"`
+	Writes     float64 `perfdata:"Writes/sec"`
+}
+
+func (c *Collector) buildSharedVolume(logger *slog.Logger) error {
+	sharedVolumeMIQuery, err := mi.NewQuery("SELECT Path,VolumeLabel,FreeSpace,TotalSize FROM MSCluster_DiskPartition")
+	if err != nil {
+		return fmt.Errorf("failed to create WMI query: %w", err)
+	}
+
+	c.sharedVolumeMIQuery = sharedVolumeMIQuery
+
+	c.sharedVolumeSizeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameSharedVolume, "size_bytes"),
+		"Total size of the Cluster Shared Volume.",
+		[]string{"path", "label"},
+		nil,
+	)
+	c.sharedVolumeFreeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameSharedVolume, "free_bytes"),
+		"Free space of the Cluster Shared Volume.",
+		[]string{"path", "label"},
+		nil,
+	)
+	c.sharedVolumeReadBytesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameSharedVolume, "read_bytes_total"),
+		"Number of bytes read from the Cluster Shared Volume by this node.",
+		[]string{"volume"},
+		nil,
+	)
+	c.sharedVolumeWriteBytesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameSharedVolume, "write_bytes_total"),
+		"Number of bytes written to the Cluster Shared Volume by this node.",
+		[]string{"volume"},
+		nil,
+	)
+	c.sharedVolumeReadsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameSharedVolume, "reads_total"),
+		"Number of read operations on the Cluster Shared Volume by this node.",
+		[]string{"volume"},
+		nil,
+	)
+	c.sharedVolumeWritesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, nameSharedVolume, "writes_total"),
+		"Number of write operations on the Cluster Shared Volume by this node.",
+		[]string{"volume"},
+		nil,
+	)
+
+	var dst []msClusterDiskPartition
+
+	if err := c.miSession.Query(&dst, mi.NamespaceRootMSCluster, c.sharedVolumeMIQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	c.sharedVolumePerfDataCollector, err = pdh.NewCollector[perfDataCounterValuesSharedVolume](logger, pdh.CounterTypeRaw, "Cluster CSVFS", pdh.InstancesAll)
+	if errors.Is(err, pdh.NewPdhError(pdh.CstatusNoObject)) {
+		logger.Debug("Cluster CSVFS performance counters not found, IO metrics of Cluster Shared Volumes are not collected")
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to create Cluster CSVFS collector: %w", err)
+	}
+
+	return nil
+}
+
+// collectSharedVolume sends the metrics of the Cluster Shared Volumes. The size comes from the disk partitions
+// of the cluster, which are Cluster Shared Volumes if they're mounted below ClusterStorage,
+// the IO from the Cluster CSVFS counters of the local node. The size is left out if clusterScoped is false.
+func (c *Collector) collectSharedVolume(ctx context.Context, ch chan<- prometheus.Metric, clusterScoped bool) error {
+	var dst []msClusterDiskPartition
+
+	if clusterScoped {
+		if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootMSCluster, c.sharedVolumeMIQuery); err != nil {
+			return fmt.Errorf("WMI query failed: %w", err)
+		}
+	}
+
+	for _, v := range dst {
+		if !strings.Contains(strings.ToLower(v.Path), `\clusterstorage\`) {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.sharedVolumeSizeBytes,
+			prometheus.GaugeValue,
+			float64(v.TotalSize)*1024*1024,
+			v.Path, v.VolumeLabel,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.sharedVolumeFreeBytes,
+			prometheus.GaugeValue,
+			float64(v.FreeSpace)*1024*1024,
+			v.Path, v.VolumeLabel,
+		)
+	}
+
+	if c.sharedVolumePerfDataCollector == nil {
+		return nil
+	}
+
+	err := c.sharedVolumePerfDataCollector.Collect(&c.sharedVolumePerfDataObject)
+	if errors.Is(err, pdh.ErrNoData) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to collect Cluster CSVFS metrics: %w", err)
+	}
+
+	for _, data := range c.sharedVolumePerfDataObject {
+		ch <- prometheus.MustNewConstMetric(
+			c.sharedVolumeReadBytesTotal,
+			prometheus.CounterValue,
+			data.ReadBytes,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.sharedVolumeWriteBytesTotal,
+			prometheus.CounterValue,
+			data.WriteBytes,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.sharedVolumeReadsTotal,
+			prometheus.CounterValue,
+			data.Reads,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.sharedVolumeWritesTotal,
+			prometheus.CounterValue,
+			data.Writes,
+			data.Name,
+		)
+	}
+
+	return nil
+}
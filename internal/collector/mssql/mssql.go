@@ -22,7 +22,9 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -39,22 +41,33 @@ const (
 	Name = "mssql"
 
 	subCollectorAccessMethods       = "accessmethods"
+	subCollectorAvailabilityGroup   = "aghealth"
 	subCollectorAvailabilityReplica = "availreplica"
+	subCollectorBackup              = "backup"
+	subCollectorBlocking            = "blocking"
 	subCollectorBufferManager       = "bufman"
 	subCollectorDatabases           = "databases"
 	subCollectorDatabaseReplica     = "dbreplica"
+	subCollectorErrorLog            = "errorlog"
 	subCollectorGeneralStatistics   = "genstats"
 	subCollectorInfo                = "info"
 	subCollectorLocks               = "locks"
 	subCollectorMemoryManager       = "memmgr"
+	subCollectorQueryStore          = "querystore"
 	subCollectorSQLErrors           = "sqlerrors"
 	subCollectorSQLStats            = "sqlstats"
 	subCollectorTransactions        = "transactions"
+	subCollectorWaitCategories      = "waitcategories"
 	subCollectorWaitStats           = "waitstats"
+	subCollectorXTPTransactions     = "xtp"
 )
 
 type Config struct {
 	CollectorsEnabled []string `yaml:"enabled"`
+	// ErrorLogMinSeverity is the minimum severity of the errors counted by the errorlog sub-collector.
+	ErrorLogMinSeverity int `yaml:"errorlog-min-severity"`
+	// ConnectionStringsFile is a file with the ODBC connection strings of the instances used by the DMV-backed sub-collectors.
+	ConnectionStringsFile string `yaml:"connection-strings-file"`
 }
 
 //nolint:gochecknoglobals
@@ -73,31 +86,40 @@ var ConfigDefaults = Config{
 		subCollectorSQLStats,
 		subCollectorTransactions,
 		subCollectorWaitStats,
+		subCollectorXTPTransactions,
 	},
+	ErrorLogMinSeverity: 17,
 }
 
 // A Collector is a Prometheus Collector for various WMI Win32_PerfRawData_MSSQLSERVER_* metrics.
 type Collector struct {
 	collectorAccessMethods
+	collectorAvailabilityGroupHealth
 	collectorAvailabilityReplica
+	collectorBackup
+	collectorBlocking
 	collectorBufferManager
 	collectorDatabaseReplica
 	collectorDatabases
+	collectorErrorLog
 	collectorGeneralStatistics
 	collectorInstance
 	collectorLocks
 	collectorMemoryManager
+	collectorQueryStore
 	collectorSQLErrors
 	collectorSQLStats
 	collectorTransactions
+	collectorWaitCategories
 	collectorWaitStats
+	collectorXTPTransactions
 
 	config Config
 
 	logger *slog.Logger
 
 	mssqlInstances []mssqlInstance
-	collectorFns   []func(ch chan<- prometheus.Metric) error
+	collectorFns   []func(ctx context.Context, ch chan<- prometheus.Metric) error
 	closeFns       []func()
 
 	// meta
@@ -114,6 +136,10 @@ func New(config *Config) *Collector {
 		config.CollectorsEnabled = ConfigDefaults.CollectorsEnabled
 	}
 
+	if config.ErrorLogMinSeverity == 0 {
+		config.ErrorLogMinSeverity = ConfigDefaults.ErrorLogMinSeverity
+	}
+
 	c := &Collector{
 		config: *config,
 	}
@@ -133,6 +159,16 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 		"Comma-separated list of collectors to use.",
 	).Default(strings.Join(c.config.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
 
+	app.Flag(
+		"collector.mssql.errorlog-min-severity",
+		"Minimum severity of the errors counted by the errorlog sub-collector.",
+	).Default(strconv.Itoa(c.config.ErrorLogMinSeverity)).IntVar(&c.config.ErrorLogMinSeverity)
+
+	app.Flag(
+		"collector.mssql.connection-strings-file",
+		"File with one <instance>=<ODBC connection string> line per instance, used by the sub-collectors querying SQL Server. Instances not listed are queried with Windows authentication.",
+	).Default(c.config.ConnectionStringsFile).StringVar(&c.config.ConnectionStringsFile)
+
 	app.Action(func(*kingpin.ParseContext) error {
 		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
 
@@ -162,23 +198,47 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		return fmt.Errorf("couldn't get SQL instances: %w", err)
 	}
 
+	if c.config.ConnectionStringsFile != "" {
+		if err := c.applyConnectionStrings(instances); err != nil {
+			return fmt.Errorf("failed to read %s: %w", c.config.ConnectionStringsFile, err)
+		}
+	}
+
 	c.mssqlInstances = instances
 
+	// The sub-collectors querying SQL Server set collectWithContext, so that their queries are cancelled
+	// at the collector timeout.
 	subCollectors := map[string]struct {
-		build   func() error
-		collect func(ch chan<- prometheus.Metric) error
-		close   func()
+		build              func() error
+		collect            func(ch chan<- prometheus.Metric) error
+		collectWithContext func(ctx context.Context, ch chan<- prometheus.Metric) error
+		close              func()
 	}{
 		subCollectorAccessMethods: {
 			build:   c.buildAccessMethods,
 			collect: c.collectAccessMethods,
 			close:   c.closeAccessMethods,
 		},
+		subCollectorAvailabilityGroup: {
+			build:              c.buildAvailabilityGroupHealth,
+			collectWithContext: c.collectAvailabilityGroupHealth,
+			close:              c.closeAvailabilityGroupHealth,
+		},
 		subCollectorAvailabilityReplica: {
 			build:   c.buildAvailabilityReplica,
 			collect: c.collectAvailabilityReplica,
 			close:   c.closeAvailabilityReplica,
 		},
+		subCollectorBackup: {
+			build:              c.buildBackup,
+			collectWithContext: c.collectBackup,
+			close:              c.closeBackup,
+		},
+		subCollectorBlocking: {
+			build:              c.buildBlocking,
+			collectWithContext: c.collectBlocking,
+			close:              c.closeBlocking,
+		},
 		subCollectorBufferManager: {
 			build:   c.buildBufferManager,
 			collect: c.collectBufferManager,
@@ -194,6 +254,11 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 			collect: c.collectDatabaseReplica,
 			close:   c.closeDatabaseReplica,
 		},
+		subCollectorErrorLog: {
+			build:   c.buildErrorLog,
+			collect: c.collectErrorLog,
+			close:   c.closeErrorLog,
+		},
 		subCollectorGeneralStatistics: {
 			build:   c.buildGeneralStatistics,
 			collect: c.collectGeneralStatistics,
@@ -214,6 +279,11 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 			collect: c.collectMemoryManager,
 			close:   c.closeMemoryManager,
 		},
+		subCollectorQueryStore: {
+			build:              c.buildQueryStore,
+			collectWithContext: c.collectQueryStore,
+			close:              c.closeQueryStore,
+		},
 		subCollectorSQLErrors: {
 			build:   c.buildSQLErrors,
 			collect: c.collectSQLErrors,
@@ -229,14 +299,24 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 			collect: c.collectTransactions,
 			close:   c.closeTransactions,
 		},
+		subCollectorWaitCategories: {
+			build:              c.buildWaitCategories,
+			collectWithContext: c.collectWaitCategories,
+			close:              c.closeWaitCategories,
+		},
 		subCollectorWaitStats: {
 			build:   c.buildWaitStats,
 			collect: c.collectWaitStats,
 			close:   c.closeWaitStats,
 		},
+		subCollectorXTPTransactions: {
+			build:   c.buildXTPTransactions,
+			collect: c.collectXTPTransactions,
+			close:   c.closeXTPTransactions,
+		},
 	}
 
-	c.collectorFns = make([]func(ch chan<- prometheus.Metric) error, 0, len(c.config.CollectorsEnabled))
+	c.collectorFns = make([]func(ctx context.Context, ch chan<- prometheus.Metric) error, 0, len(c.config.CollectorsEnabled))
 	c.closeFns = make([]func(), 0, len(c.config.CollectorsEnabled))
 	// Result must order, to prevent test failures.
 	sort.Strings(c.config.CollectorsEnabled)
@@ -252,7 +332,15 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 			errs = append(errs, fmt.Errorf("failed to build %s collector: %w", name, err))
 		}
 
-		c.collectorFns = append(c.collectorFns, subCollectors[name].collect)
+		collectFn := subCollectors[name].collectWithContext
+		if collectFn == nil {
+			collect := subCollectors[name].collect
+			collectFn = func(_ context.Context, ch chan<- prometheus.Metric) error {
+				return collect(ch)
+			}
+		}
+
+		c.collectorFns = append(c.collectorFns, collectFn)
 		c.closeFns = append(c.closeFns, subCollectors[name].close)
 	}
 
@@ -275,6 +363,12 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
 func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	return c.CollectWithContext(context.Background(), ch)
+}
+
+// CollectWithContext sends the metric values for each metric
+// to the provided prometheus Metric channel. Queries to SQL Server are cancelled once ctx is done.
+func (c *Collector) CollectWithContext(ctx context.Context, ch chan<- prometheus.Metric) error {
 	if len(c.mssqlInstances) == 0 {
 		return fmt.Errorf("no SQL instances found: %w", pdh.ErrNoData)
 	}
@@ -286,10 +380,10 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 	for _, fn := range c.collectorFns {
 		wg.Add(1)
 
-		go func(fn func(ch chan<- prometheus.Metric) error) {
+		go func(fn func(ctx context.Context, ch chan<- prometheus.Metric) error) {
 			defer wg.Done()
 
-			if err := fn(ch); err != nil {
+			if err := fn(ctx, ch); err != nil {
 				errCh <- err
 			}
 		}(fn)
@@ -348,6 +442,42 @@ func (c *Collector) getMSSQLInstances() ([]mssqlInstance, error) {
 	return sqlInstances, nil
 }
 
+// applyConnectionStrings sets the connection strings of the instances listed in the connection strings file.
+func (c *Collector) applyConnectionStrings(instances []mssqlInstance) error {
+	file, err := os.Open(c.config.ConnectionStringsFile)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	connectionStrings, err := parseConnectionStrings(file)
+	if err != nil {
+		return err
+	}
+
+	for i, instance := range instances {
+		connectionString, ok := connectionStrings[strings.ToUpper(instance.name)]
+		if !ok {
+			continue
+		}
+
+		instances[i].odbcConnectionString = connectionString
+
+		delete(connectionStrings, strings.ToUpper(instance.name))
+	}
+
+	for instance := range connectionStrings {
+		c.logger.Warn("connection string for unknown SQL instance",
+			slog.String("mssql_instance", instance),
+		)
+	}
+
+	return nil
+}
+
 // mssqlGetPerfObjectName returns the name of the Windows Performance
 // Counter object for the given SQL instance and Collector.
 func (c *Collector) mssqlGetPerfObjectName(sqlInstance mssqlInstance, collector string) string {
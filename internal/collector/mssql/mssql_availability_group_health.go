@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mssql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/odbc32"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// agReplicaHealthQuery returns the state of the availability replicas known to the instance.
+// Secondary replicas only know their own state, the rows of the other replicas are missing there.
+const agReplicaHealthQuery = `SELECT ag.name, ar.replica_server_name, ars.role_desc, ars.synchronization_health_desc, ars.connected_state_desc
+FROM sys.availability_groups ag
+JOIN sys.availability_replicas ar ON ar.group_id = ag.group_id
+JOIN sys.dm_hadr_availability_replica_states ars ON ars.replica_id = ar.replica_id`
+
+// agDatabaseHealthQuery returns the synchronization state of the local availability databases.
+const agDatabaseHealthQuery = `SELECT ag.name, DB_NAME(drs.database_id), drs.synchronization_state_desc
+FROM sys.dm_hadr_database_replica_states drs
+JOIN sys.availability_groups ag ON ag.group_id = drs.group_id
+WHERE drs.is_local = 1`
+
+//nolint:gochecknoglobals
+var (
+	agReplicaRoles               = []string{"primary", "secondary", "resolving"}
+	agSynchronizationHealthState = []string{"not_healthy", "partially_healthy", "healthy"}
+	agDatabaseSynchronization    = []string{"not_synchronizing", "synchronizing", "synchronized", "reverting", "initializing"}
+)
+
+type collectorAvailabilityGroupHealth struct {
+	// agHealthInstances has no performance counter collectors, the map is used to
+	// report the scrape duration and success of each instance.
+	agHealthInstances map[mssqlInstance]*pdh.Collector
+
+	agReplicaRole                  *prometheus.Desc
+	agReplicaSynchronizationHealth *prometheus.Desc
+	agReplicaConnected             *prometheus.Desc
+	agDatabaseSynchronizationState *prometheus.Desc
+}
+
+func (c *Collector) buildAvailabilityGroupHealth() error {
+	c.agHealthInstances = make(map[mssqlInstance]*pdh.Collector, len(c.mssqlInstances))
+
+	for _, sqlInstance := range c.mssqlInstances {
+		// The availability group DMVs are available since SQL Server 2012.
+		if sqlInstance.isVersionGreaterOrEqualThan(serverVersion2012) {
+			c.agHealthInstances[sqlInstance] = nil
+		}
+	}
+
+	c.agReplicaRole = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "ag_replica_role"),
+		"Current role of the availability replica (primary, secondary, resolving)",
+		[]string{"mssql_instance", "availability_group", "replica", "role"},
+		nil,
+	)
+	c.agReplicaSynchronizationHealth = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "ag_replica_synchronization_health"),
+		"Synchronization health of the availability replica (not_healthy, partially_healthy, healthy)",
+		[]string{"mssql_instance", "availability_group", "replica", "state"},
+		nil,
+	)
+	c.agReplicaConnected = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "ag_replica_connected"),
+		"Whether the secondary replica is connected to the primary replica",
+		[]string{"mssql_instance", "availability_group", "replica"},
+		nil,
+	)
+	c.agDatabaseSynchronizationState = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "ag_database_synchronization_state"),
+		"Synchronization state of the local availability database (not_synchronizing, synchronizing, synchronized, reverting, initializing)",
+		[]string{"mssql_instance", "availability_group", "database", "state"},
+		nil,
+	)
+
+	return nil
+}
+
+func (c *Collector) collectAvailabilityGroupHealth(ctx context.Context, ch chan<- prometheus.Metric) error {
+	return c.collect(ch, subCollectorAvailabilityGroup, c.agHealthInstances, func(ch chan<- prometheus.Metric, sqlInstance mssqlInstance, perfDataCollector *pdh.Collector) error {
+		return c.collectAvailabilityGroupHealthInstance(ctx, ch, sqlInstance, perfDataCollector)
+	})
+}
+
+func (c *Collector) collectAvailabilityGroupHealthInstance(ctx context.Context, ch chan<- prometheus.Metric, sqlInstance mssqlInstance, _ *pdh.Collector) error {
+	rows, err := odbc32.Query(ctx, sqlInstance.connectionString(), agReplicaHealthQuery, sqlQueryTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to query availability replicas of instance %s: %w", sqlInstance.name, err)
+	}
+
+	for _, row := range rows {
+		if len(row) != 5 || row[0] == nil || row[1] == nil {
+			continue
+		}
+
+		availabilityGroup, replica := *row[0], *row[1]
+
+		if row[2] != nil {
+			role := strings.ToLower(*row[2])
+
+			for _, state := range agReplicaRoles {
+				ch <- prometheus.MustNewConstMetric(
+					c.agReplicaRole,
+					prometheus.GaugeValue,
+					utils.BoolToFloat(state == role),
+					sqlInstance.name, availabilityGroup, replica, state,
+				)
+			}
+		}
+
+		if row[3] != nil {
+			health := strings.ToLower(*row[3])
+
+			for _, state := range agSynchronizationHealthState {
+				ch <- prometheus.MustNewConstMetric(
+					c.agReplicaSynchronizationHealth,
+					prometheus.GaugeValue,
+					utils.BoolToFloat(state == health),
+					sqlInstance.name, availabilityGroup, replica, state,
+				)
+			}
+		}
+
+		if row[4] != nil {
+			ch <- prometheus.MustNewConstMetric(
+				c.agReplicaConnected,
+				prometheus.GaugeValue,
+				utils.BoolToFloat(strings.EqualFold(*row[4], "CONNECTED")),
+				sqlInstance.name, availabilityGroup, replica,
+			)
+		}
+	}
+
+	rows, err = odbc32.Query(ctx, sqlInstance.connectionString(), agDatabaseHealthQuery, sqlQueryTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to query availability databases of instance %s: %w", sqlInstance.name, err)
+	}
+
+	for _, row := range rows {
+		if len(row) != 3 || row[0] == nil || row[1] == nil || row[2] == nil {
+			continue
+		}
+
+		synchronizationState := strings.ToLower(*row[2])
+
+		for _, state := range agDatabaseSynchronization {
+			ch <- prometheus.MustNewConstMetric(
+				c.agDatabaseSynchronizationState,
+				prometheus.GaugeValue,
+				utils.BoolToFloat(state == synchronizationState),
+				sqlInstance.name, *row[0], *row[1], state,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (c *Collector) closeAvailabilityGroupHealth() {}
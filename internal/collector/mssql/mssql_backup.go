@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mssql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/odbc32"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// backupQuery returns the age of the most recent full, differential and log backup
+// of every online database. Databases without any backup return a single row with a NULL backup type.
+const backupQuery = `SELECT d.name, d.recovery_model_desc, b.type, DATEDIFF(SECOND, MAX(b.backup_finish_date), GETDATE())
+FROM sys.databases d
+LEFT JOIN msdb.dbo.backupset b ON b.database_name = d.name AND b.type IN ('D', 'I', 'L')
+WHERE d.name <> 'tempdb' AND d.state = 0
+GROUP BY d.name, d.recovery_model_desc, b.type`
+
+//nolint:gochecknoglobals
+var backupTypes = map[string]string{
+	"D": "full",
+	"I": "differential",
+	"L": "log",
+}
+
+type collectorBackup struct {
+	// backupInstances has no performance counter collectors, the map is used to
+	// report the scrape duration and success of each instance.
+	backupInstances map[mssqlInstance]*pdh.Collector
+
+	backupAgeSeconds *prometheus.Desc
+}
+
+func (c *Collector) buildBackup() error {
+	c.backupInstances = make(map[mssqlInstance]*pdh.Collector, len(c.mssqlInstances))
+
+	for _, sqlInstance := range c.mssqlInstances {
+		c.backupInstances[sqlInstance] = nil
+	}
+
+	c.backupAgeSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "backup_age_seconds"),
+		"Seconds since the last backup of the database by backup type (full, differential, log). +Inf if the database never had a full backup",
+		[]string{"mssql_instance", "database", "recovery_model", "type"},
+		nil,
+	)
+
+	return nil
+}
+
+func (c *Collector) collectBackup(ctx context.Context, ch chan<- prometheus.Metric) error {
+	return c.collect(ch, subCollectorBackup, c.backupInstances, func(ch chan<- prometheus.Metric, sqlInstance mssqlInstance, perfDataCollector *pdh.Collector) error {
+		return c.collectBackupInstance(ctx, ch, sqlInstance, perfDataCollector)
+	})
+}
+
+func (c *Collector) collectBackupInstance(ctx context.Context, ch chan<- prometheus.Metric, sqlInstance mssqlInstance, _ *pdh.Collector) error {
+	rows, err := odbc32.Query(ctx, sqlInstance.connectionString(), backupQuery, sqlQueryTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to query backup history of instance %s: %w", sqlInstance.name, err)
+	}
+
+	// hasFullBackup tracks the databases that had a full backup, all others are reported as +Inf.
+	hasFullBackup := make(map[string]bool)
+	recoveryModels := make(map[string]string)
+
+	errs := make([]error, 0)
+
+	for _, row := range rows {
+		if len(row) != 4 || row[0] == nil || row[1] == nil {
+			continue
+		}
+
+		database, recoveryModel := *row[0], *row[1]
+
+		recoveryModels[database] = recoveryModel
+
+		if row[2] == nil || row[3] == nil {
+			continue
+		}
+
+		backupType, ok := backupTypes[*row[2]]
+		if !ok {
+			continue
+		}
+
+		age, err := strconv.ParseFloat(*row[3], 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse backup age of database %s: %w", database, err))
+
+			continue
+		}
+
+		if backupType == "full" {
+			hasFullBackup[database] = true
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.backupAgeSeconds,
+			prometheus.GaugeValue,
+			age,
+			sqlInstance.name, database, recoveryModel, backupType,
+		)
+	}
+
+	for database, recoveryModel := range recoveryModels {
+		if hasFullBackup[database] {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.backupAgeSeconds,
+			prometheus.GaugeValue,
+			math.Inf(1),
+			sqlInstance.name, database, recoveryModel, "full",
+		)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) closeBackup() {}
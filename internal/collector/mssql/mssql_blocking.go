@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mssql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/odbc32"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// blockingQuery returns the number of sessions waiting for a lock held by another session,
+// the longest wait of these sessions in milliseconds and the number of head blockers,
+// i.e. sessions blocking others without being blocked themselves.
+const blockingQuery = `SELECT COUNT(DISTINCT r.session_id), ISNULL(MAX(r.wait_time), 0),
+	(SELECT COUNT(DISTINCT b.blocking_session_id) FROM sys.dm_exec_requests b
+	WHERE b.blocking_session_id <> 0 AND NOT EXISTS (
+		SELECT 1 FROM sys.dm_exec_requests h WHERE h.session_id = b.blocking_session_id AND h.blocking_session_id <> 0
+	))
+FROM sys.dm_exec_requests r
+WHERE r.blocking_session_id <> 0`
+
+type collectorBlocking struct {
+	// blockingInstances has no performance counter collectors, the map is used to
+	// report the scrape duration and success of each instance.
+	blockingInstances map[mssqlInstance]*pdh.Collector
+
+	blockedSessions               *prometheus.Desc
+	blockedSessionsMaxWaitSeconds *prometheus.Desc
+	blockingHeadSessions          *prometheus.Desc
+}
+
+func (c *Collector) buildBlocking() error {
+	c.blockingInstances = make(map[mssqlInstance]*pdh.Collector, len(c.mssqlInstances))
+
+	for _, sqlInstance := range c.mssqlInstances {
+		c.blockingInstances[sqlInstance] = nil
+	}
+
+	c.blockedSessions = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "blocked_sessions"),
+		"Number of sessions waiting for a resource held by another session",
+		[]string{"mssql_instance"},
+		nil,
+	)
+	c.blockedSessionsMaxWaitSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "blocked_sessions_max_wait_seconds"),
+		"Longest current wait of the blocked sessions in seconds",
+		[]string{"mssql_instance"},
+		nil,
+	)
+	c.blockingHeadSessions = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "blocking_head_sessions"),
+		"Number of sessions at the head of a blocking chain, which block other sessions without being blocked themselves",
+		[]string{"mssql_instance"},
+		nil,
+	)
+
+	return nil
+}
+
+func (c *Collector) collectBlocking(ctx context.Context, ch chan<- prometheus.Metric) error {
+	return c.collect(ch, subCollectorBlocking, c.blockingInstances, func(ch chan<- prometheus.Metric, sqlInstance mssqlInstance, perfDataCollector *pdh.Collector) error {
+		return c.collectBlockingInstance(ctx, ch, sqlInstance, perfDataCollector)
+	})
+}
+
+func (c *Collector) collectBlockingInstance(ctx context.Context, ch chan<- prometheus.Metric, sqlInstance mssqlInstance, _ *pdh.Collector) error {
+	rows, err := odbc32.Query(ctx, sqlInstance.connectionString(), blockingQuery, sqlQueryTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to query blocked sessions of instance %s: %w", sqlInstance.name, err)
+	}
+
+	if len(rows) != 1 || len(rows[0]) != 3 {
+		return fmt.Errorf("unexpected result of blocked sessions query of instance %s", sqlInstance.name)
+	}
+
+	values := make([]float64, len(rows[0]))
+
+	for i, value := range rows[0] {
+		if value == nil {
+			continue
+		}
+
+		values[i], err = strconv.ParseFloat(*value, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse blocked sessions of instance %s: %w", sqlInstance.name, err)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.blockedSessions,
+		prometheus.GaugeValue,
+		values[0],
+		sqlInstance.name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.blockedSessionsMaxWaitSeconds,
+		prometheus.GaugeValue,
+		values[1]/1000,
+		sqlInstance.name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.blockingHeadSessions,
+		prometheus.GaugeValue,
+		values[2],
+		sqlInstance.name,
+	)
+
+	return nil
+}
+
+func (c *Collector) closeBlocking() {}
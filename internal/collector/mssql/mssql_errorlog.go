@@ -0,0 +1,358 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mssql
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	// errorLogLoginFailed is the error number of failed logins.
+	errorLogLoginFailed = 18456
+
+	// errorLogMaxRead limits the bytes read from the error log per scrape, e.g. after a burst of login failures.
+	// The rest is read by the next scrapes.
+	errorLogMaxRead = 16 << 20
+)
+
+//nolint:gochecknoglobals
+var (
+	// errorLogErrorRegex matches the error lines of the error log, e.g.
+	// 2024-05-01 10:00:00.12 Logon       Error: 18456, Severity: 14, State: 8.
+	errorLogErrorRegex = regexp.MustCompile(`Error: (\d+), Severity: (\d+), State: \d+`)
+
+	// errorLogStackDump is logged once for each stack dump, e.g.
+	// 2024-05-01 10:00:00.12 spid52      ***Stack Dump being sent to C:\...\LOG\SQLDump0001.txt
+	errorLogStackDump = []byte("Stack Dump being sent to")
+)
+
+// errorLogKey identifies the error counters of an instance.
+type errorLogKey struct {
+	errorNumber string
+	severity    string
+}
+
+// errorLogTail is the read position of the error log of an instance and the counts of its entries.
+// Entries are counted from the start of windows_exporter.
+type errorLogTail struct {
+	path string
+	// file identifies the file at the read position, so a cycled error log is read from its start.
+	file   os.FileInfo
+	offset int64
+	utf16  bool
+
+	errors        map[errorLogKey]float64
+	loginFailures float64
+	stackDumps    float64
+}
+
+type collectorErrorLog struct {
+	// errorLogInstances has no performance counter collectors, the map is used to
+	// report the scrape duration and success of each instance.
+	errorLogInstances map[mssqlInstance]*pdh.Collector
+	errorLogTails     map[string]*errorLogTail
+
+	errorLogErrors        *prometheus.Desc
+	errorLogLoginFailures *prometheus.Desc
+	errorLogStackDumps    *prometheus.Desc
+}
+
+func (c *Collector) buildErrorLog() error {
+	c.errorLogInstances = make(map[mssqlInstance]*pdh.Collector, len(c.mssqlInstances))
+	c.errorLogTails = make(map[string]*errorLogTail, len(c.mssqlInstances))
+
+	errs := make([]error, 0, len(c.mssqlInstances))
+
+	for _, sqlInstance := range c.mssqlInstances {
+		path, err := errorLogPath(sqlInstance)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to locate error log of instance %s: %w", sqlInstance.name, err))
+
+			continue
+		}
+
+		tail := &errorLogTail{path: path, errors: make(map[errorLogKey]float64)}
+
+		// Entries logged before windows_exporter started are skipped.
+		if err := tail.skipToEnd(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to open error log of instance %s: %w", sqlInstance.name, err))
+
+			continue
+		}
+
+		c.errorLogInstances[sqlInstance] = nil
+		c.errorLogTails[sqlInstance.name] = tail
+	}
+
+	c.errorLogErrors = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "errorlog_errors_total"),
+		"Number of errors in the error log with at least the configured severity since windows_exporter started",
+		[]string{"mssql_instance", "error", "severity"},
+		nil,
+	)
+	c.errorLogLoginFailures = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "errorlog_login_failures_total"),
+		"Number of failed logins (error 18456) in the error log since windows_exporter started",
+		[]string{"mssql_instance"},
+		nil,
+	)
+	c.errorLogStackDumps = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "errorlog_stack_dumps_total"),
+		"Number of stack dumps in the error log since windows_exporter started",
+		[]string{"mssql_instance"},
+		nil,
+	)
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectErrorLog(ch chan<- prometheus.Metric) error {
+	return c.collect(ch, subCollectorErrorLog, c.errorLogInstances, c.collectErrorLogInstance)
+}
+
+func (c *Collector) collectErrorLogInstance(ch chan<- prometheus.Metric, sqlInstance mssqlInstance, _ *pdh.Collector) error {
+	tail := c.errorLogTails[sqlInstance.name]
+
+	err := tail.read(c.config.ErrorLogMinSeverity)
+
+	for key, count := range tail.errors {
+		ch <- prometheus.MustNewConstMetric(
+			c.errorLogErrors,
+			prometheus.CounterValue,
+			count,
+			sqlInstance.name, key.errorNumber, key.severity,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.errorLogLoginFailures,
+		prometheus.CounterValue,
+		tail.loginFailures,
+		sqlInstance.name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.errorLogStackDumps,
+		prometheus.CounterValue,
+		tail.stackDumps,
+		sqlInstance.name,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to read error log of instance %s: %w", sqlInstance.name, err)
+	}
+
+	return nil
+}
+
+func (c *Collector) closeErrorLog() {}
+
+// errorLogPath returns the path of the ERRORLOG file of the instance, which is passed to SQL Server
+// with the -e startup parameter.
+func errorLogPath(sqlInstance mssqlInstance) (string, error) {
+	regKey := fmt.Sprintf(`Software\Microsoft\Microsoft SQL Server\%s\MSSQLServer\Parameters`, sqlInstance.instanceName)
+
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, regKey, registry.QUERY_VALUE)
+	if err != nil {
+		return "", fmt.Errorf("couldn't open registry %s: %w", regKey, err)
+	}
+
+	defer func(key registry.Key) {
+		_ = key.Close()
+	}(k)
+
+	names, err := k.ReadValueNames(0)
+	if err != nil {
+		return "", fmt.Errorf("couldn't read startup parameters: %w", err)
+	}
+
+	for _, name := range names {
+		if !strings.HasPrefix(name, "SQLArg") {
+			continue
+		}
+
+		value, _, err := k.GetStringValue(name)
+		if err != nil {
+			continue
+		}
+
+		if path, ok := strings.CutPrefix(value, "-e"); ok {
+			return path, nil
+		}
+	}
+
+	return "", errors.New("no -e startup parameter found")
+}
+
+// skipToEnd sets the read position to the end of the error log.
+func (t *errorLogTail) skipToEnd() error {
+	file, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if t.utf16, err = hasUTF16BOM(file); err != nil {
+		return err
+	}
+
+	t.file = info
+	t.offset = info.Size()
+
+	return nil
+}
+
+// read counts the complete lines written to the error log since the last read. If SQL Server cycled
+// the error log, e.g. on restart or by sp_cycle_errorlog, the new error log is read from its start.
+func (t *errorLogTail) read(minSeverity int) error {
+	file, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if !os.SameFile(info, t.file) || info.Size() < t.offset {
+		t.file = info
+		t.offset = 0
+
+		if t.utf16, err = hasUTF16BOM(file); err != nil {
+			return err
+		}
+	}
+
+	data := make([]byte, min(info.Size()-t.offset, errorLogMaxRead))
+	if len(data) == 0 {
+		return nil
+	}
+
+	n, err := file.ReadAt(data, t.offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	data = data[:n]
+
+	// The last line may still be written, so only complete lines are counted.
+	end := lineEnd(data, t.utf16)
+	if end == 0 {
+		return nil
+	}
+
+	t.offset += int64(end)
+
+	text := data[:end]
+	if t.utf16 {
+		text = decodeUTF16(text)
+	}
+
+	t.count(text, minSeverity)
+
+	return nil
+}
+
+// lineEnd returns the length of the complete lines of data. UTF-16 data starts at an even offset.
+func lineEnd(data []byte, isUTF16 bool) int {
+	if !isUTF16 {
+		return bytes.LastIndexByte(data, '\n') + 1
+	}
+
+	for i := len(data)&^1 - 2; i >= 0; i -= 2 {
+		if data[i] == '\n' && data[i+1] == 0 {
+			return i + 2
+		}
+	}
+
+	return 0
+}
+
+// count counts the errors, login failures and stack dumps of the lines.
+func (t *errorLogTail) count(text []byte, minSeverity int) {
+	for line := range bytes.Lines(text) {
+		if bytes.Contains(line, errorLogStackDump) {
+			t.stackDumps++
+
+			continue
+		}
+
+		match := errorLogErrorRegex.FindSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		errorNumber, severity := string(match[1]), string(match[2])
+
+		if errorNumber == strconv.Itoa(errorLogLoginFailed) {
+			t.loginFailures++
+		}
+
+		if s, err := strconv.Atoi(severity); err == nil && s >= minSeverity {
+			t.errors[errorLogKey{errorNumber: errorNumber, severity: severity}]++
+		}
+	}
+}
+
+// hasUTF16BOM returns true if the file starts with the byte order mark of UTF-16LE, which SQL Server writes.
+func hasUTF16BOM(file *os.File) (bool, error) {
+	bom := make([]byte, 2)
+
+	n, err := file.ReadAt(bom, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+
+	return n == 2 && bom[0] == 0xFF && bom[1] == 0xFE, nil
+}
+
+// decodeUTF16 converts UTF-16LE text to UTF-8. The byte order mark is dropped.
+func decodeUTF16(data []byte) []byte {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, uint16(data[i])|uint16(data[i+1])<<8)
+	}
+
+	if len(units) > 0 && units[0] == 0xFEFF {
+		units = units[1:]
+	}
+
+	return []byte(string(utf16.Decode(units)))
+}
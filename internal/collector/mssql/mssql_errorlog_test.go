@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mssql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encodeUTF16(s string) []byte {
+	data := []byte{0xFF, 0xFE}
+	for _, unit := range utf16.Encode([]rune(s)) {
+		data = append(data, byte(unit), byte(unit>>8))
+	}
+
+	return data
+}
+
+func TestErrorLogTail(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "ERRORLOG")
+	require.NoError(t, os.WriteFile(path, encodeUTF16("2024-05-01 10:00:00.00 Server      Error: 9002, Severity: 17, State: 2.\r\n"), 0o600))
+
+	tail := &errorLogTail{path: path, errors: make(map[errorLogKey]float64)}
+	require.NoError(t, tail.skipToEnd())
+	require.True(t, tail.utf16)
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	// The BOM is only written at the start of the file.
+	appendLog := func(s string) {
+		_, err := file.Write(encodeUTF16(s)[2:])
+		require.NoError(t, err)
+	}
+
+	appendLog("2024-05-01 10:01:00.00 Logon       Error: 18456, Severity: 14, State: 8.\r\n" +
+		"2024-05-01 10:01:00.00 Logon       Login failed for user 'app'. Reason: Password did not match.\r\n" +
+		"2024-05-01 10:02:00.00 spid52      Error: 824, Severity: 24, State: 2.\r\n" +
+		"2024-05-01 10:02:00.00 spid52      ***Stack Dump being sent to C:\\LOG\\SQLDump0001.txt\r\n" +
+		"2024-05-01 10:03:00.00 spid52      Error: 824, Sev")
+
+	require.NoError(t, tail.read(17))
+	require.InDelta(t, 1, tail.loginFailures, 0)
+	require.InDelta(t, 1, tail.stackDumps, 0)
+	require.Equal(t, map[errorLogKey]float64{{errorNumber: "824", severity: "24"}: 1}, tail.errors)
+
+	// The incomplete line is counted once it is complete.
+	appendLog("erity: 24, State: 2.\r\n")
+
+	require.NoError(t, tail.read(17))
+	require.Equal(t, map[errorLogKey]float64{{errorNumber: "824", severity: "24"}: 2}, tail.errors)
+
+	// A cycled error log is read from its start.
+	require.NoError(t, file.Close())
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, os.WriteFile(path, encodeUTF16("2024-05-01 11:00:00.00 Logon       Error: 18456, Severity: 14, State: 5.\r\n"), 0o600))
+
+	require.NoError(t, tail.read(17))
+	require.InDelta(t, 2, tail.loginFailures, 0)
+}
@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mssql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/odbc32"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// queryStoreDatabasesQuery lists the databases that may have Query Store settings.
+// Query Store can't be enabled on master and tempdb.
+const queryStoreDatabasesQuery = `SELECT name FROM sys.databases WHERE state = 0 AND name NOT IN ('master', 'tempdb')`
+
+//nolint:gochecknoglobals
+var (
+	queryStoreStates = []string{"off", "read_only", "read_write", "error"}
+
+	// sqlIdentifierEscaper escapes a name used inside a bracket-delimited T-SQL identifier.
+	sqlIdentifierEscaper = strings.NewReplacer(`]`, `]]`)
+	// sqlStringEscaper escapes a value used inside a T-SQL string literal.
+	sqlStringEscaper = strings.NewReplacer(`'`, `''`)
+)
+
+type collectorQueryStore struct {
+	// queryStoreInstances has no performance counter collectors, the map is used to
+	// report the scrape duration and success of each instance.
+	queryStoreInstances map[mssqlInstance]*pdh.Collector
+
+	queryStoreInfo         *prometheus.Desc
+	queryStoreState        *prometheus.Desc
+	queryStoreStorageUsed  *prometheus.Desc
+	queryStoreStorageLimit *prometheus.Desc
+}
+
+func (c *Collector) buildQueryStore() error {
+	c.queryStoreInstances = make(map[mssqlInstance]*pdh.Collector, len(c.mssqlInstances))
+
+	for _, sqlInstance := range c.mssqlInstances {
+		// Query Store was introduced with SQL Server 2016.
+		if !sqlInstance.isVersionGreaterOrEqualThan(serverVersion2016) {
+			continue
+		}
+
+		c.queryStoreInstances[sqlInstance] = nil
+	}
+
+	c.queryStoreInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "query_store_info"),
+		"A metric with a constant '1' value labeled with the configured Query Store state and capture mode of the database",
+		[]string{"mssql_instance", "database", "desired_state", "capture_mode"},
+		nil,
+	)
+	c.queryStoreState = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "query_store_state"),
+		"The actual operation mode of the Query Store of the database (off, read_only, read_write, error)",
+		[]string{"mssql_instance", "database", "state"},
+		nil,
+	)
+	c.queryStoreStorageUsed = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "query_store_storage_used_bytes"),
+		"Size of the Query Store of the database on disk",
+		[]string{"mssql_instance", "database"},
+		nil,
+	)
+	c.queryStoreStorageLimit = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "query_store_storage_limit_bytes"),
+		"Maximum size of the Query Store of the database. The Query Store switches to read_only once the limit is reached",
+		[]string{"mssql_instance", "database"},
+		nil,
+	)
+
+	return nil
+}
+
+func (c *Collector) collectQueryStore(ctx context.Context, ch chan<- prometheus.Metric) error {
+	return c.collect(ch, subCollectorQueryStore, c.queryStoreInstances, func(ch chan<- prometheus.Metric, sqlInstance mssqlInstance, perfDataCollector *pdh.Collector) error {
+		return c.collectQueryStoreInstance(ctx, ch, sqlInstance, perfDataCollector)
+	})
+}
+
+func (c *Collector) collectQueryStoreInstance(ctx context.Context, ch chan<- prometheus.Metric, sqlInstance mssqlInstance, _ *pdh.Collector) error {
+	databases, err := odbc32.Query(ctx, sqlInstance.connectionString(), queryStoreDatabasesQuery, sqlQueryTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to query databases of instance %s: %w", sqlInstance.name, err)
+	}
+
+	// sys.database_query_store_options only returns the options of the current database,
+	// so the views of all databases are combined into a single query.
+	selects := make([]string, 0, len(databases))
+
+	for _, row := range databases {
+		if len(row) != 1 || row[0] == nil {
+			continue
+		}
+
+		selects = append(selects, fmt.Sprintf(
+			"SELECT N'%s', actual_state_desc, desired_state_desc, query_capture_mode_desc, current_storage_size_mb, max_storage_size_mb FROM [%s].sys.database_query_store_options",
+			sqlStringEscaper.Replace(*row[0]),
+			sqlIdentifierEscaper.Replace(*row[0]),
+		))
+	}
+
+	if len(selects) == 0 {
+		return nil
+	}
+
+	rows, err := odbc32.Query(ctx, sqlInstance.connectionString(), strings.Join(selects, " UNION ALL "), sqlQueryTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to query Query Store options of instance %s: %w", sqlInstance.name, err)
+	}
+
+	errs := make([]error, 0)
+
+	for _, row := range rows {
+		if len(row) != 6 || row[0] == nil || row[1] == nil {
+			continue
+		}
+
+		database := *row[0]
+		actualState := strings.ToLower(*row[1])
+
+		for _, state := range queryStoreStates {
+			var value float64
+			if state == actualState {
+				value = 1
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.queryStoreState,
+				prometheus.GaugeValue,
+				value,
+				sqlInstance.name, database, state,
+			)
+		}
+
+		if row[2] != nil && row[3] != nil {
+			ch <- prometheus.MustNewConstMetric(
+				c.queryStoreInfo,
+				prometheus.GaugeValue,
+				1,
+				sqlInstance.name, database, strings.ToLower(*row[2]), strings.ToLower(*row[3]),
+			)
+		}
+
+		for desc, value := range map[*prometheus.Desc]*string{
+			c.queryStoreStorageUsed:  row[4],
+			c.queryStoreStorageLimit: row[5],
+		} {
+			if value == nil {
+				continue
+			}
+
+			sizeMB, err := strconv.ParseFloat(*value, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to parse Query Store size of database %s: %w", database, err))
+
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				desc,
+				prometheus.GaugeValue,
+				sizeMB*1024*1024,
+				sqlInstance.name, database,
+			)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) closeQueryStore() {}
@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mssql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/odbc32"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// waitCategoriesQuery returns the cumulative waits of the instance since the start or the last reset by wait type.
+const waitCategoriesQuery = `SELECT wait_type, waiting_tasks_count, wait_time_ms FROM sys.dm_os_wait_stats WHERE waiting_tasks_count > 0`
+
+// waitCategoryMapping maps wait types to the wait categories of Query Store.
+// The rules are evaluated in order, so the exceptions of a prefix come before the prefix.
+// 📑 https://learn.microsoft.com/en-us/sql/relational-databases/system-catalog-views/sys-query-store-wait-stats-transact-sql#wait-categories-mapping-table
+//
+//nolint:gochecknoglobals
+var waitCategoryMapping = []struct {
+	category string
+	types    []string
+	prefixes []string
+}{
+	{category: "cpu", types: []string{"SOS_SCHEDULER_YIELD"}},
+	{category: "worker_thread", types: []string{"THREADPOOL"}},
+	{category: "lock", prefixes: []string{"LCK_M_"}},
+	{category: "latch", prefixes: []string{"LATCH_"}},
+	{category: "buffer_latch", prefixes: []string{"PAGELATCH_"}},
+	{category: "buffer_io", prefixes: []string{"PAGEIOLATCH_"}},
+	{category: "compilation", types: []string{"RESOURCE_SEMAPHORE_QUERY_COMPILE"}},
+	{category: "sql_clr", prefixes: []string{"CLR", "SQLCLR"}},
+	{category: "mirroring", prefixes: []string{"DBMIRROR"}},
+	{category: "transaction", types: []string{"TRANSACTION_MUTEX"}, prefixes: []string{"XACT", "DTC", "TRAN_MARKLATCH_", "MSQL_XACT_"}},
+	{category: "idle", types: []string{
+		"LAZYWRITER_SLEEP", "SQLTRACE_BUFFER_FLUSH", "SQLTRACE_INCREMENTAL_FLUSH_SLEEP", "SQLTRACE_WAIT_ENTRIES",
+		"FT_IFTS_SCHEDULER_IDLE_WAIT", "XE_DISPATCHER_WAIT", "REQUEST_FOR_DEADLOCK_SEARCH", "LOGMGR_QUEUE",
+		"ONDEMAND_TASK_QUEUE", "CHECKPOINT_QUEUE", "XE_TIMER_EVENT",
+	}, prefixes: []string{"SLEEP_"}},
+	{category: "preemptive", prefixes: []string{"PREEMPTIVE_"}},
+	{category: "user_wait", types: []string{"WAITFOR", "WAIT_FOR_RESULTS", "BROKER_RECEIVE_WAITFOR"}},
+	{category: "service_broker", prefixes: []string{"BROKER_"}},
+	{category: "tran_log_io", types: []string{"LOGMGR", "LOGBUFFER", "LOGMGR_RESERVE_APPEND", "LOGMGR_FLUSH", "LOGMGR_PMM_LOG", "CHKPT", "WRITELOG"}},
+	{category: "network_io", types: []string{"ASYNC_NETWORK_IO", "NET_WAITFOR_PACKET", "PROXY_NETWORK_IO", "EXTERNAL_SCRIPT_NETWORK_IOF"}},
+	{category: "parallelism", types: []string{"CXPACKET", "CXCONSUMER", "EXCHANGE"}, prefixes: []string{"HT", "BMP", "BP"}},
+	{category: "memory", types: []string{
+		"RESOURCE_SEMAPHORE", "CMEMTHREAD", "CMEMPARTITIONED", "EE_PMOLOCK", "MEMORY_ALLOCATION_EXT",
+		"RESERVED_MEMORY_ALLOCATION_EXT", "MEMORY_GRANT_UPDATE",
+	}},
+	{category: "tracing", types: []string{
+		"TRACEWRITE", "SQLTRACE_LOCK", "SQLTRACE_FILE_BUFFER", "SQLTRACE_FILE_WRITE_IO_COMPLETION",
+		"SQLTRACE_FILE_READ_IO_COMPLETION", "SQLTRACE_PENDING_BUFFER_WRITERS", "SQLTRACE_SHUTDOWN", "QUERY_TRACEOUT", "TRACE_EVTNOTIF",
+	}},
+	{category: "full_text_search", types: []string{
+		"FT_RESTART_CRAWL", "FULLTEXT GATHERER", "MSSEARCH", "FT_METADATA_MUTEX", "FT_IFTSHC_MUTEX", "FT_IFTSISM_MUTEX",
+		"FT_IFTS_RWLOCK", "FT_COMPROWSET_RWLOCK", "FT_MASTER_MERGE", "FT_PROPERTYLIST_CACHE", "FT_MASTER_MERGE_COORDINATOR",
+		"PWAIT_RESOURCE_SEMAPHORE_FT_PARALLEL_QUERY_SYNC",
+	}},
+	{category: "other_disk_io", types: []string{"ASYNC_IO_COMPLETION", "IO_COMPLETION", "BACKUPIO", "WRITE_COMPLETION", "IO_QUEUE_LIMIT", "IO_RETRY"}},
+	{category: "log_rate_governor", types: []string{"LOG_RATE_GOVERNOR", "POOL_LOG_RATE_GOVERNOR", "HADR_THROTTLE_LOG_RATE_GOVERNOR", "INSTANCE_LOG_RATE_GOVERNOR"}},
+	{category: "replication", types: []string{"REPLICA_WRITES", "FCB_REPLICA_WRITE", "FCB_REPLICA_READ", "PWAIT_HADRSIM"}, prefixes: []string{"SE_REPL_", "REPL_", "HADR_", "PWAIT_HADR_"}},
+}
+
+// waitCategory returns the Query Store wait category of the wait type, or unknown if the wait type isn't mapped.
+func waitCategory(waitType string) string {
+	for _, mapping := range waitCategoryMapping {
+		for _, t := range mapping.types {
+			if waitType == t {
+				return mapping.category
+			}
+		}
+
+		for _, prefix := range mapping.prefixes {
+			if strings.HasPrefix(waitType, prefix) {
+				return mapping.category
+			}
+		}
+	}
+
+	return "unknown"
+}
+
+type collectorWaitCategories struct {
+	// waitCategoriesInstances has no performance counter collectors, the map is used to
+	// report the scrape duration and success of each instance.
+	waitCategoriesInstances map[mssqlInstance]*pdh.Collector
+
+	waitCategoryTime  *prometheus.Desc
+	waitCategoryWaits *prometheus.Desc
+}
+
+func (c *Collector) buildWaitCategories() error {
+	c.waitCategoriesInstances = make(map[mssqlInstance]*pdh.Collector, len(c.mssqlInstances))
+
+	for _, sqlInstance := range c.mssqlInstances {
+		c.waitCategoriesInstances[sqlInstance] = nil
+	}
+
+	c.waitCategoryTime = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "wait_category_time_seconds_total"),
+		"Time spent waiting by Query Store wait category, including the signal wait time",
+		[]string{"mssql_instance", "category"},
+		nil,
+	)
+	c.waitCategoryWaits = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "wait_category_waits_total"),
+		"Number of waits by Query Store wait category",
+		[]string{"mssql_instance", "category"},
+		nil,
+	)
+
+	return nil
+}
+
+func (c *Collector) collectWaitCategories(ctx context.Context, ch chan<- prometheus.Metric) error {
+	return c.collect(ch, subCollectorWaitCategories, c.waitCategoriesInstances, func(ch chan<- prometheus.Metric, sqlInstance mssqlInstance, perfDataCollector *pdh.Collector) error {
+		return c.collectWaitCategoriesInstance(ctx, ch, sqlInstance, perfDataCollector)
+	})
+}
+
+func (c *Collector) collectWaitCategoriesInstance(ctx context.Context, ch chan<- prometheus.Metric, sqlInstance mssqlInstance, _ *pdh.Collector) error {
+	rows, err := odbc32.Query(ctx, sqlInstance.connectionString(), waitCategoriesQuery, sqlQueryTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to query wait statistics of instance %s: %w", sqlInstance.name, err)
+	}
+
+	waits := make(map[string]float64)
+	waitTimes := make(map[string]float64)
+
+	errs := make([]error, 0)
+
+	for _, row := range rows {
+		if len(row) != 3 || row[0] == nil || row[1] == nil || row[2] == nil {
+			continue
+		}
+
+		count, err := strconv.ParseFloat(*row[1], 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse waits of wait type %s: %w", *row[0], err))
+
+			continue
+		}
+
+		waitTime, err := strconv.ParseFloat(*row[2], 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse wait time of wait type %s: %w", *row[0], err))
+
+			continue
+		}
+
+		category := waitCategory(*row[0])
+
+		waits[category] += count
+		waitTimes[category] += waitTime
+	}
+
+	for category, count := range waits {
+		ch <- prometheus.MustNewConstMetric(
+			c.waitCategoryWaits,
+			prometheus.CounterValue,
+			count,
+			sqlInstance.name, category,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.waitCategoryTime,
+			prometheus.CounterValue,
+			waitTimes[category]/1000,
+			sqlInstance.name, category,
+		)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) closeWaitCategories() {}
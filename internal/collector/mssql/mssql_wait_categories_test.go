@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mssql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitCategory(t *testing.T) {
+	t.Parallel()
+
+	for waitType, category := range map[string]string{
+		"LCK_M_X":                          "lock",
+		"PAGEIOLATCH_SH":                   "buffer_io",
+		"PAGELATCH_EX":                     "buffer_latch",
+		"LATCH_EX":                         "latch",
+		"WRITELOG":                         "tran_log_io",
+		"LOGMGR_QUEUE":                     "idle",
+		"BROKER_RECEIVE_WAITFOR":           "user_wait",
+		"BROKER_TASK_STOP":                 "service_broker",
+		"HADR_SYNC_COMMIT":                 "replication",
+		"HADR_THROTTLE_LOG_RATE_GOVERNOR":  "log_rate_governor",
+		"CXPACKET":                         "parallelism",
+		"SOS_SCHEDULER_YIELD":              "cpu",
+		"RESOURCE_SEMAPHORE":               "memory",
+		"RESOURCE_SEMAPHORE_QUERY_COMPILE": "compilation",
+		"VDI_CLIENT_OTHER":                 "unknown",
+	} {
+		require.Equal(t, category, waitCategory(waitType), waitType)
+	}
+}
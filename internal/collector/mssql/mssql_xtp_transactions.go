@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mssql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type collectorXTPTransactions struct {
+	xtpTransactionsPerfDataCollectors map[mssqlInstance]*pdh.Collector
+	xtpTransactionsPerfDataObject     []perfDataCounterValuesXTPTransactions
+
+	xtpCascadingAborts               *prometheus.Desc
+	xtpCommitDependenciesTaken       *prometheus.Desc
+	xtpReadOnlyTransactionsPrepared  *prometheus.Desc
+	xtpSavePointRefreshes            *prometheus.Desc
+	xtpSavePointRollbacks            *prometheus.Desc
+	xtpSavePointsCreated             *prometheus.Desc
+	xtpTransactionValidationFailures *prometheus.Desc
+	xtpTransactionsAbortedByUser     *prometheus.Desc
+	xtpTransactionsAborted           *prometheus.Desc
+	xtpTransactionsCreated           *prometheus.Desc
+}
+
+type perfDataCounterValuesXTPTransactions struct {
+	XTPCascadingAbortsPerSec               float64 `perfdata:"Cascading aborts/sec"`
+	XTPCommitDependenciesTakenPerSec       float64 `perfdata:"Commit dependencies taken/sec"`
+	XTPReadOnlyTransactionsPreparedPerSec  float64 `perfdata:"Read-only transactions prepared/sec"`
+	XTPSavePointRefreshesPerSec            float64 `perfdata:"Save point refreshes/sec"`
+	XTPSavePointRollbacksPerSec            float64 `perfdata:"Save point rollbacks/sec"`
+	XTPSavePointsCreatedPerSec             float64 `perfdata:"Save points created/sec"`
+	XTPTransactionValidationFailuresPerSec float64 `perfdata:"Transaction validation failures/sec"`
+	XTPTransactionsAbortedByUserPerSec     float64 `perfdata:"Transactions aborted by user/sec"`
+	XTPTransactionsAbortedPerSec           float64 `perfdata:"Transactions aborted/sec"`
+	XTPTransactionsCreatedPerSec           float64 `perfdata:"Transactions created/sec"`
+}
+
+func (c *Collector) buildXTPTransactions() error {
+	var err error
+
+	c.xtpTransactionsPerfDataCollectors = make(map[mssqlInstance]*pdh.Collector, len(c.mssqlInstances))
+	errs := make([]error, 0, len(c.mssqlInstances))
+
+	for _, sqlInstance := range c.mssqlInstances {
+		c.xtpTransactionsPerfDataCollectors[sqlInstance], err = pdh.NewCollector[perfDataCounterValuesXTPTransactions](c.logger, pdh.CounterTypeRaw, c.mssqlGetPerfObjectName(sqlInstance, "XTP Transactions"), nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create XTP Transactions collector for instance %s: %w", sqlInstance.name, err))
+		}
+	}
+
+	c.xtpCascadingAborts = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "xtp_cascading_aborts_total"),
+		"(XTPTransactions.CascadingAborts)",
+		[]string{"mssql_instance"},
+		nil,
+	)
+	c.xtpCommitDependenciesTaken = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "xtp_commit_dependencies_taken_total"),
+		"(XTPTransactions.CommitDependenciesTaken)",
+		[]string{"mssql_instance"},
+		nil,
+	)
+	c.xtpReadOnlyTransactionsPrepared = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "xtp_read_only_transactions_prepared_total"),
+		"(XTPTransactions.ReadOnlyTransactionsPrepared)",
+		[]string{"mssql_instance"},
+		nil,
+	)
+	c.xtpSavePointRefreshes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "xtp_savepoint_refreshes_total"),
+		"(XTPTransactions.SavePointRefreshes)",
+		[]string{"mssql_instance"},
+		nil,
+	)
+	c.xtpSavePointRollbacks = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "xtp_savepoint_rollbacks_total"),
+		"(XTPTransactions.SavePointRollbacks)",
+		[]string{"mssql_instance"},
+		nil,
+	)
+	c.xtpSavePointsCreated = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "xtp_savepoints_created_total"),
+		"(XTPTransactions.SavePointsCreated)",
+		[]string{"mssql_instance"},
+		nil,
+	)
+	c.xtpTransactionValidationFailures = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "xtp_transaction_validation_failures_total"),
+		"(XTPTransactions.TransactionValidationFailures)",
+		[]string{"mssql_instance"},
+		nil,
+	)
+	c.xtpTransactionsAbortedByUser = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "xtp_transactions_aborted_by_user_total"),
+		"(XTPTransactions.TransactionsAbortedByUser)",
+		[]string{"mssql_instance"},
+		nil,
+	)
+	c.xtpTransactionsAborted = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "xtp_transactions_aborted_total"),
+		"(XTPTransactions.TransactionsAborted)",
+		[]string{"mssql_instance"},
+		nil,
+	)
+	c.xtpTransactionsCreated = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "xtp_transactions_created_total"),
+		"(XTPTransactions.TransactionsCreated)",
+		[]string{"mssql_instance"},
+		nil,
+	)
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectXTPTransactions(ch chan<- prometheus.Metric) error {
+	return c.collect(ch, subCollectorXTPTransactions, c.xtpTransactionsPerfDataCollectors, c.collectXTPTransactionsInstance)
+}
+
+func (c *Collector) collectXTPTransactionsInstance(ch chan<- prometheus.Metric, sqlInstance mssqlInstance, perfDataCollector *pdh.Collector) error {
+	err := perfDataCollector.Collect(&c.xtpTransactionsPerfDataObject)
+	if err != nil {
+		return fmt.Errorf("failed to collect %s metrics: %w", c.mssqlGetPerfObjectName(sqlInstance, "XTP Transactions"), err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.xtpCascadingAborts,
+		prometheus.CounterValue,
+		c.xtpTransactionsPerfDataObject[0].XTPCascadingAbortsPerSec,
+		sqlInstance.name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.xtpCommitDependenciesTaken,
+		prometheus.CounterValue,
+		c.xtpTransactionsPerfDataObject[0].XTPCommitDependenciesTakenPerSec,
+		sqlInstance.name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.xtpReadOnlyTransactionsPrepared,
+		prometheus.CounterValue,
+		c.xtpTransactionsPerfDataObject[0].XTPReadOnlyTransactionsPreparedPerSec,
+		sqlInstance.name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.xtpSavePointRefreshes,
+		prometheus.CounterValue,
+		c.xtpTransactionsPerfDataObject[0].XTPSavePointRefreshesPerSec,
+		sqlInstance.name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.xtpSavePointRollbacks,
+		prometheus.CounterValue,
+		c.xtpTransactionsPerfDataObject[0].XTPSavePointRollbacksPerSec,
+		sqlInstance.name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.xtpSavePointsCreated,
+		prometheus.CounterValue,
+		c.xtpTransactionsPerfDataObject[0].XTPSavePointsCreatedPerSec,
+		sqlInstance.name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.xtpTransactionValidationFailures,
+		prometheus.CounterValue,
+		c.xtpTransactionsPerfDataObject[0].XTPTransactionValidationFailuresPerSec,
+		sqlInstance.name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.xtpTransactionsAbortedByUser,
+		prometheus.CounterValue,
+		c.xtpTransactionsPerfDataObject[0].XTPTransactionsAbortedByUserPerSec,
+		sqlInstance.name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.xtpTransactionsAborted,
+		prometheus.CounterValue,
+		c.xtpTransactionsPerfDataObject[0].XTPTransactionsAbortedPerSec,
+		sqlInstance.name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.xtpTransactionsCreated,
+		prometheus.CounterValue,
+		c.xtpTransactionsPerfDataObject[0].XTPTransactionsCreatedPerSec,
+		sqlInstance.name,
+	)
+
+	return nil
+}
+
+func (c *Collector) closeXTPTransactions() {
+	for _, perfDataCollector := range c.xtpTransactionsPerfDataCollectors {
+		perfDataCollector.Close()
+	}
+}
@@ -18,18 +18,26 @@
 package mssql
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"golang.org/x/sys/windows/registry"
 )
 
+// sqlQueryTimeout limits the login and execution time of queries run by the DMV-backed sub-collectors.
+const sqlQueryTimeout = 10 * time.Second
+
 type mssqlInstance struct {
 	name            string
 	instanceName    string
 	majorVersion    mssqlServerMajorVersion
 	edition         string
 	isFirstInstance bool
+	// odbcConnectionString overrides the connection string using Windows authentication, see connectionString.
+	odbcConnectionString string
 }
 
 func newMssqlInstance(key, name string) (mssqlInstance, error) {
@@ -66,6 +74,51 @@ func newMssqlInstance(key, name string) (mssqlInstance, error) {
 	}, nil
 }
 
+// connectionString returns the ODBC connection string of the instance from --collector.mssql.connection-strings-file
+// or the connection string for the local instance using Windows authentication.
+func (m mssqlInstance) connectionString() string {
+	if m.odbcConnectionString != "" {
+		return m.odbcConnectionString
+	}
+
+	server := `.`
+	if !m.isFirstInstance {
+		server = `.\` + m.name
+	}
+
+	return fmt.Sprintf("Driver={SQL Server};Server=%s;Database=master;Trusted_Connection=yes;", server)
+}
+
+// parseConnectionStrings parses the lines of a connection strings file in the format <instance>=<connection string>.
+// Empty lines and lines starting with # are ignored.
+func parseConnectionStrings(r io.Reader) (map[string]string, error) {
+	connectionStrings := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		instance, connectionString, ok := strings.Cut(text, "=")
+		instance = strings.TrimSpace(instance)
+		connectionString = strings.TrimSpace(connectionString)
+
+		if !ok || instance == "" || connectionString == "" {
+			return nil, fmt.Errorf("line %d: expected <instance>=<connection string>", line)
+		}
+
+		connectionStrings[strings.ToUpper(instance)] = connectionString
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return connectionStrings, nil
+}
+
 func (m mssqlInstance) isVersionGreaterOrEqualThan(version mssqlServerMajorVersion) bool {
 	return m.majorVersion.isGreaterOrEqualThan(version)
 }
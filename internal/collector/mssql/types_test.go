@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package mssql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConnectionStrings(t *testing.T) {
+	t.Parallel()
+
+	connectionStrings, err := parseConnectionStrings(strings.NewReader(`
+# Reporting instance with SQL Server authentication
+reporting = Driver={ODBC Driver 18 for SQL Server};Server=.\REPORTING;UID=exporter;PWD=a=b;Encrypt=yes;
+
+MSSQLSERVER=Driver={SQL Server};Server=.;Trusted_Connection=yes;
+`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"REPORTING":   `Driver={ODBC Driver 18 for SQL Server};Server=.\REPORTING;UID=exporter;PWD=a=b;Encrypt=yes;`,
+		"MSSQLSERVER": `Driver={SQL Server};Server=.;Trusted_Connection=yes;`,
+	}, connectionStrings)
+
+	_, err = parseConnectionStrings(strings.NewReader("MSSQLSERVER\n"))
+	require.ErrorContains(t, err, "line 1")
+}
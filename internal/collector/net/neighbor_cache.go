@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package net
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/iphlpapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var (
+	ipv4ProtocolQuery = utils.Must(mi.NewQuery("SELECT NeighborCacheLimitEntries FROM MSFT_NetIPv4Protocol"))
+	ipv6ProtocolQuery = utils.Must(mi.NewQuery("SELECT NeighborCacheLimitEntries FROM MSFT_NetIPv6Protocol"))
+
+	neighborStates = []iphlpapi.NL_NEIGHBOR_STATE{
+		iphlpapi.NlnsUnreachable,
+		iphlpapi.NlnsIncomplete,
+		iphlpapi.NlnsProbe,
+		iphlpapi.NlnsDelay,
+		iphlpapi.NlnsStale,
+		iphlpapi.NlnsReachable,
+		iphlpapi.NlnsPermanent,
+	}
+)
+
+type netIPProtocol struct {
+	NeighborCacheLimitEntries uint32 `mi:"NeighborCacheLimitEntries"`
+}
+
+// collectNeighborCache reports the ARP (IPv4) and NDP (IPv6) neighbor cache entries by interface and state,
+// together with the per-interface neighbor cache limit.
+func (c *Collector) collectNeighborCache(ch chan<- prometheus.Metric) error {
+	nicAdapterAddresses, err := adapterAddresses()
+	if err != nil {
+		return err
+	}
+
+	convertNicName := strings.NewReplacer("(", "[", ")", "]", "#", "_")
+
+	ipv4NicNames := make(map[uint32]string, len(nicAdapterAddresses))
+	ipv6NicNames := make(map[uint32]string, len(nicAdapterAddresses))
+
+	for _, nicAdapter := range nicAdapterAddresses {
+		nicName := convertNicName.Replace(windows.UTF16PtrToString(nicAdapter.Description))
+
+		if c.config.NicExclude.MatchString(nicName) ||
+			!c.config.NicInclude.MatchString(nicName) {
+			continue
+		}
+
+		ipv4NicNames[nicAdapter.IfIndex] = nicName
+		ipv6NicNames[nicAdapter.Ipv6IfIndex] = nicName
+	}
+
+	for family, nicNames := range map[uint32]map[uint32]string{
+		windows.AF_INET:  ipv4NicNames,
+		windows.AF_INET6: ipv6NicNames,
+	} {
+		stateCounts, err := iphlpapi.GetNeighborStateCounts(family)
+		if err != nil {
+			return err
+		}
+
+		for interfaceIndex, counts := range stateCounts {
+			nicName, ok := nicNames[interfaceIndex]
+			if !ok {
+				continue
+			}
+
+			for _, state := range neighborStates {
+				ch <- prometheus.MustNewConstMetric(
+					c.neighborCacheEntries,
+					prometheus.GaugeValue,
+					float64(counts[state]),
+					nicName,
+					addressFamily[uint16(family)],
+					state.String(),
+				)
+			}
+		}
+	}
+
+	for family, query := range map[uint16]mi.Query{
+		windows.AF_INET:  ipv4ProtocolQuery,
+		windows.AF_INET6: ipv6ProtocolQuery,
+	} {
+		var dst []netIPProtocol
+		if err := c.miSession.Query(&dst, mi.NamespaceRootStandardCimv2, query); err != nil {
+			return fmt.Errorf("WMI query failed: %w", err)
+		}
+
+		for _, protocol := range dst {
+			ch <- prometheus.MustNewConstMetric(
+				c.neighborCacheLimit,
+				prometheus.GaugeValue,
+				float64(protocol.NeighborCacheLimitEntries),
+				addressFamily[family],
+			)
+		}
+	}
+
+	return nil
+}
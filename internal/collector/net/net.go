@@ -40,6 +40,8 @@ const (
 
 	subCollectorMetrics = "metrics"
 	subCollectorNicInfo = "nic_info"
+
+	subCollectorNeighborCache = "neighbor_cache"
 )
 
 type Config struct {
@@ -60,7 +62,8 @@ var ConfigDefaults = Config{
 
 // A Collector is a Prometheus Collector for Perflib Network Interface metrics.
 type Collector struct {
-	config Config
+	config    Config
+	miSession *mi.Session
 
 	perfDataCollector *pdh.Collector
 	perfDataObject    []perfDataCounterValues
@@ -83,6 +86,9 @@ type Collector struct {
 	nicOperStatus    *prometheus.Desc
 	nicInfo          *prometheus.Desc
 	routeInfo        *prometheus.Desc
+
+	neighborCacheEntries *prometheus.Desc
+	neighborCacheLimit   *prometheus.Desc
 }
 
 func New(config *Config) *Collector {
@@ -165,15 +171,25 @@ func (c *Collector) Close() error {
 	return nil
 }
 
-func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	subCollectors := []string{subCollectorMetrics, subCollectorNicInfo, subCollectorNeighborCache}
+
 	for _, collector := range c.config.CollectorsEnabled {
-		if !slices.Contains([]string{subCollectorMetrics, subCollectorNicInfo}, collector) {
+		if !slices.Contains(subCollectors, collector) {
 			return fmt.Errorf("unknown sub collector: %s. Possible values: %s", collector,
-				strings.Join([]string{subCollectorMetrics, subCollectorNicInfo}, ", "),
+				strings.Join(subCollectors, ", "),
 			)
 		}
 	}
 
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorNeighborCache) {
+		if miSession == nil {
+			return errors.New("miSession is nil")
+		}
+
+		c.miSession = miSession
+	}
+
 	c.bytesReceivedTotal = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, Name, "bytes_received_total"),
 		"(Network.BytesReceivedPerSec)",
@@ -276,6 +292,18 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		[]string{"nic", "src", "dest", "metric"},
 		nil,
 	)
+	c.neighborCacheEntries = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "neighbor_cache_entries"),
+		"Number of ARP (IPv4) or NDP (IPv6) neighbor cache entries of the interface by state",
+		[]string{"nic", "family", "state"},
+		nil,
+	)
+	c.neighborCacheLimit = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "neighbor_cache_limit_entries"),
+		"Maximum number of neighbor cache entries per interface",
+		[]string{"family"},
+		nil,
+	)
 
 	var err error
 
@@ -310,6 +338,12 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		}
 	}
 
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorNeighborCache) {
+		if err := c.collectNeighborCache(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting neighbor cache metrics: %w", err))
+		}
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -0,0 +1,438 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package nlb
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const Name = "nlb"
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+// Status codes of the hosts, see wlbsctrl.h.
+const (
+	statusStopped    = 1005
+	statusConverging = 1006
+	statusConverged  = 1007
+	statusDefault    = 1008
+	statusDraining   = 1009
+	statusSuspended  = 1013
+)
+
+//nolint:gochecknoglobals
+var (
+	nodeQuery                 = utils.Must(mi.NewQuery("SELECT ComputerName, DedicatedIPAddress, HostPriority, Name, StatusCode FROM MicrosoftNLB_Node"))
+	portRuleLoadbalancedQuery = utils.Must(mi.NewQuery("SELECT Name, StartPort, EndPort, ProtocolType, EqualLoad, LoadWeight, Affinity FROM MicrosoftNLB_PortRuleLoadbalanced"))
+	portRuleFailoverQuery     = utils.Must(mi.NewQuery("SELECT Name, StartPort, EndPort, ProtocolType, Priority FROM MicrosoftNLB_PortRuleFailover"))
+	portRuleDisabledQuery     = utils.Must(mi.NewQuery("SELECT Name, StartPort, EndPort, ProtocolType FROM MicrosoftNLB_PortRuleDisabled"))
+
+	nodeStates = map[uint32]string{
+		statusStopped:    "stopped",
+		statusConverging: "converging",
+		statusConverged:  "converged",
+		statusDefault:    "converged_default",
+		statusDraining:   "draining",
+		statusSuspended:  "suspended",
+	}
+
+	// nodeStateNames are the values of the state label of windows_nlb_node_state.
+	nodeStateNames = []string{"stopped", "converging", "converged", "converged_default", "draining", "suspended", "unknown"}
+
+	protocols = map[uint32]string{
+		1: "tcp",
+		2: "udp",
+		3: "both",
+	}
+
+	affinities = map[uint32]string{
+		0: "none",
+		1: "single",
+		2: "class_c",
+	}
+)
+
+// A Collector is a Prometheus Collector for Network Load Balancing (NLB) clusters,
+// read from the WMI classes of the root/MicrosoftNLB namespace.
+type Collector struct {
+	config    Config
+	miSession *mi.Session
+
+	clusterConverged *prometheus.Desc
+	clusterNodes     *prometheus.Desc
+	nodeState        *prometheus.Desc
+	nodeHostPriority *prometheus.Desc
+	portRuleInfo     *prometheus.Desc
+	portRuleWeight   *prometheus.Desc
+	portRuleShare    *prometheus.Desc
+	portRulePriority *prometheus.Desc
+}
+
+type microsoftNLBNode struct {
+	ComputerName       string `mi:"ComputerName"`
+	DedicatedIPAddress string `mi:"DedicatedIPAddress"`
+	HostPriority       uint32 `mi:"HostPriority"`
+	Name               string `mi:"Name"`
+	StatusCode         uint32 `mi:"StatusCode"`
+}
+
+type microsoftNLBPortRuleLoadbalanced struct {
+	Name         string `mi:"Name"`
+	StartPort    uint32 `mi:"StartPort"`
+	EndPort      uint32 `mi:"EndPort"`
+	ProtocolType uint32 `mi:"ProtocolType"`
+	EqualLoad    bool   `mi:"EqualLoad"`
+	LoadWeight   uint32 `mi:"LoadWeight"`
+	Affinity     uint32 `mi:"Affinity"`
+}
+
+type microsoftNLBPortRuleFailover struct {
+	Name         string `mi:"Name"`
+	StartPort    uint32 `mi:"StartPort"`
+	EndPort      uint32 `mi:"EndPort"`
+	ProtocolType uint32 `mi:"ProtocolType"`
+	Priority     uint32 `mi:"Priority"`
+}
+
+type microsoftNLBPortRuleDisabled struct {
+	Name         string `mi:"Name"`
+	StartPort    uint32 `mi:"StartPort"`
+	EndPort      uint32 `mi:"EndPort"`
+	ProtocolType uint32 `mi:"ProtocolType"`
+}
+
+// portRule identifies a port rule of a cluster. The rule is configured on each host of the cluster.
+type portRule struct {
+	cluster   string
+	protocol  string
+	startPort string
+	endPort   string
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(_ *slog.Logger, miSession *mi.Session) error {
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.miSession = miSession
+
+	c.clusterConverged = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cluster_converged"),
+		"Whether all hosts of the cluster that are not stopped have converged",
+		[]string{"cluster"},
+		nil,
+	)
+	c.clusterNodes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cluster_nodes"),
+		"Number of hosts of the cluster",
+		[]string{"cluster"},
+		nil,
+	)
+	c.nodeState = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "node_state"),
+		"State of the host (stopped, converging, converged, converged_default, draining, suspended, unknown)",
+		[]string{"cluster", "host_id", "node", "state"},
+		nil,
+	)
+	c.nodeHostPriority = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "node_host_priority"),
+		"Host priority of the host, which is unique within the cluster. The host with the lowest value handles the traffic not covered by a port rule",
+		[]string{"cluster", "host_id", "node"},
+		nil,
+	)
+	c.portRuleInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "port_rule_info"),
+		"Port rule of the host with its filtering mode (multiple, single, disabled) and affinity",
+		[]string{"cluster", "host_id", "protocol", "start_port", "end_port", "filtering_mode", "affinity"},
+		nil,
+	)
+	c.portRuleWeight = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "port_rule_load_weight"),
+		"Load weight of the host for a port rule with multiple host filtering (0-100). Is -1 if the load is distributed equally",
+		[]string{"cluster", "host_id", "protocol", "start_port", "end_port"},
+		nil,
+	)
+	c.portRuleShare = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "port_rule_load_share_ratio"),
+		"Share of the traffic of a port rule with multiple host filtering that is distributed to the host, from the load weights of all hosts",
+		[]string{"cluster", "host_id", "protocol", "start_port", "end_port"},
+		nil,
+	)
+	c.portRulePriority = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "port_rule_handling_priority"),
+		"Handling priority of the host for a port rule with single host filtering. The host with the lowest value handles the traffic",
+		[]string{"cluster", "host_id", "protocol", "start_port", "end_port"},
+		nil,
+	)
+
+	// Fails with MI_RESULT_INVALID_NAMESPACE if the NLB feature is not installed.
+	var dst []microsoftNLBNode
+	if err := c.miSession.Query(&dst, mi.NamespaceRootMicrosoftNLB, nodeQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if err := c.collectNodes(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting node metrics: %w", err))
+	}
+
+	if err := c.collectPortRules(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting port rule metrics: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectNodes(ch chan<- prometheus.Metric) error {
+	var dst []microsoftNLBNode
+	if err := c.miSession.Query(&dst, mi.NamespaceRootMicrosoftNLB, nodeQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	nodes := make(map[string]int)
+	converging := make(map[string]bool)
+
+	for _, node := range dst {
+		cluster, hostID := splitName(node.Name)
+
+		nodes[cluster]++
+		converging[cluster] = converging[cluster] || node.StatusCode == statusConverging
+
+		state, ok := nodeStates[node.StatusCode]
+		if !ok {
+			state = "unknown"
+		}
+
+		for _, s := range nodeStateNames {
+			ch <- prometheus.MustNewConstMetric(
+				c.nodeState,
+				prometheus.GaugeValue,
+				utils.BoolToFloat(s == state),
+				cluster, hostID, node.ComputerName, s,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.nodeHostPriority,
+			prometheus.GaugeValue,
+			float64(node.HostPriority),
+			cluster, hostID, node.ComputerName,
+		)
+	}
+
+	for cluster, count := range nodes {
+		ch <- prometheus.MustNewConstMetric(
+			c.clusterNodes,
+			prometheus.GaugeValue,
+			float64(count),
+			cluster,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.clusterConverged,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(!converging[cluster]),
+			cluster,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectPortRules(ch chan<- prometheus.Metric) error {
+	var loadbalanced []microsoftNLBPortRuleLoadbalanced
+	if err := c.miSession.Query(&loadbalanced, mi.NamespaceRootMicrosoftNLB, portRuleLoadbalancedQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	var failover []microsoftNLBPortRuleFailover
+	if err := c.miSession.Query(&failover, mi.NamespaceRootMicrosoftNLB, portRuleFailoverQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	var disabled []microsoftNLBPortRuleDisabled
+	if err := c.miSession.Query(&disabled, mi.NamespaceRootMicrosoftNLB, portRuleDisabledQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	// The share of a host is its load weight divided by the sum of the load weights of the hosts of the rule.
+	// With equal load, each host counts with the same weight.
+	weights := make(map[portRule]float64)
+
+	for _, rule := range loadbalanced {
+		key := newPortRule(rule.Name, rule.ProtocolType, rule.StartPort, rule.EndPort)
+
+		weights[key] += loadWeight(rule)
+	}
+
+	for _, rule := range loadbalanced {
+		key := newPortRule(rule.Name, rule.ProtocolType, rule.StartPort, rule.EndPort)
+		_, hostID := splitName(rule.Name)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.portRuleInfo,
+			prometheus.GaugeValue,
+			1.0,
+			key.cluster, hostID, key.protocol, key.startPort, key.endPort, "multiple", affinity(rule.Affinity),
+		)
+
+		weight := float64(rule.LoadWeight)
+		if rule.EqualLoad {
+			weight = -1
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.portRuleWeight,
+			prometheus.GaugeValue,
+			weight,
+			key.cluster, hostID, key.protocol, key.startPort, key.endPort,
+		)
+
+		var share float64
+		if weights[key] > 0 {
+			share = loadWeight(rule) / weights[key]
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.portRuleShare,
+			prometheus.GaugeValue,
+			share,
+			key.cluster, hostID, key.protocol, key.startPort, key.endPort,
+		)
+	}
+
+	for _, rule := range failover {
+		key := newPortRule(rule.Name, rule.ProtocolType, rule.StartPort, rule.EndPort)
+		_, hostID := splitName(rule.Name)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.portRuleInfo,
+			prometheus.GaugeValue,
+			1.0,
+			key.cluster, hostID, key.protocol, key.startPort, key.endPort, "single", "",
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.portRulePriority,
+			prometheus.GaugeValue,
+			float64(rule.Priority),
+			key.cluster, hostID, key.protocol, key.startPort, key.endPort,
+		)
+	}
+
+	for _, rule := range disabled {
+		key := newPortRule(rule.Name, rule.ProtocolType, rule.StartPort, rule.EndPort)
+		_, hostID := splitName(rule.Name)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.portRuleInfo,
+			prometheus.GaugeValue,
+			1.0,
+			key.cluster, hostID, key.protocol, key.startPort, key.endPort, "disabled", "",
+		)
+	}
+
+	return nil
+}
+
+// splitName splits the name of the NLB WMI instances, "<cluster IP address>:<host ID>", into its parts.
+func splitName(name string) (string, string) {
+	index := strings.LastIndex(name, ":")
+	if index < 0 {
+		return name, ""
+	}
+
+	return name[:index], name[index+1:]
+}
+
+func newPortRule(name string, protocolType, startPort, endPort uint32) portRule {
+	cluster, _ := splitName(name)
+
+	protocol, ok := protocols[protocolType]
+	if !ok {
+		protocol = strconv.FormatUint(uint64(protocolType), 10)
+	}
+
+	return portRule{
+		cluster:   cluster,
+		protocol:  protocol,
+		startPort: strconv.FormatUint(uint64(startPort), 10),
+		endPort:   strconv.FormatUint(uint64(endPort), 10),
+	}
+}
+
+// loadWeight returns the weight of the host for the share of the traffic. Equal load counts as the default weight of 50.
+func loadWeight(rule microsoftNLBPortRuleLoadbalanced) float64 {
+	if rule.EqualLoad {
+		return 50
+	}
+
+	return float64(rule.LoadWeight)
+}
+
+func affinity(value uint32) string {
+	if name, ok := affinities[value]; ok {
+		return name
+	}
+
+	return strconv.FormatUint(uint64(value), 10)
+}
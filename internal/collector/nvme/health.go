@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package nvme
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// healthLogSize is the size of the SMART / Health Information log page.
+const healthLogSize = 512
+
+var errShortHealthLog = errors.New("health information log is too short")
+
+// criticalWarnings are the bits of the Critical Warning field of the health information log, the warning label.
+//
+//nolint:gochecknoglobals
+var criticalWarnings = []string{
+	"available_spare",
+	"temperature",
+	"reliability",
+	"read_only",
+	"volatile_memory_backup",
+	"persistent_memory_region",
+}
+
+// healthLog is the SMART / Health Information log page (log identifier 02h) of an NVMe controller.
+// The 128-bit counters are converted to float64, which loses precision beyond 2^53.
+// 📑 https://nvmexpress.org/specifications/ NVM Express Base Specification, section "SMART / Health Information"
+type healthLog struct {
+	criticalWarning         uint8
+	temperatureKelvin       uint16
+	availableSpare          uint8
+	availableSpareThreshold uint8
+	percentageUsed          uint8
+	dataUnitsRead           float64
+	dataUnitsWritten        float64
+	hostReadCommands        float64
+	hostWriteCommands       float64
+	controllerBusyMinutes   float64
+	powerCycles             float64
+	powerOnHours            float64
+	unsafeShutdowns         float64
+	mediaErrors             float64
+	errorLogEntries         float64
+	warningTempMinutes      uint32
+	criticalTempMinutes     uint32
+	// thermalTransitions and thermalSeconds are the transition count and the total time of the
+	// thermal management temperatures 1 (light throttling) and 2 (heavy throttling).
+	thermalTransitions [2]uint32
+	thermalSeconds     [2]uint32
+}
+
+func parseHealthLog(data []byte) (healthLog, error) {
+	if len(data) < healthLogSize {
+		return healthLog{}, errShortHealthLog
+	}
+
+	return healthLog{
+		criticalWarning:         data[0],
+		temperatureKelvin:       binary.LittleEndian.Uint16(data[1:]),
+		availableSpare:          data[3],
+		availableSpareThreshold: data[4],
+		percentageUsed:          data[5],
+		dataUnitsRead:           uint128(data[32:]),
+		dataUnitsWritten:        uint128(data[48:]),
+		hostReadCommands:        uint128(data[64:]),
+		hostWriteCommands:       uint128(data[80:]),
+		controllerBusyMinutes:   uint128(data[96:]),
+		powerCycles:             uint128(data[112:]),
+		powerOnHours:            uint128(data[128:]),
+		unsafeShutdowns:         uint128(data[144:]),
+		mediaErrors:             uint128(data[160:]),
+		errorLogEntries:         uint128(data[176:]),
+		warningTempMinutes:      binary.LittleEndian.Uint32(data[192:]),
+		criticalTempMinutes:     binary.LittleEndian.Uint32(data[196:]),
+		thermalTransitions:      [2]uint32{binary.LittleEndian.Uint32(data[216:]), binary.LittleEndian.Uint32(data[220:])},
+		thermalSeconds:          [2]uint32{binary.LittleEndian.Uint32(data[224:]), binary.LittleEndian.Uint32(data[228:])},
+	}, nil
+}
+
+// uint128 converts a little endian 128-bit unsigned integer to float64.
+func uint128(data []byte) float64 {
+	return float64(binary.LittleEndian.Uint64(data[8:]))*math.Pow(2, 64) + float64(binary.LittleEndian.Uint64(data))
+}
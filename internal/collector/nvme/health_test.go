@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package nvme
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHealthLog(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, healthLogSize)
+	data[0] = 0b0000_0110
+	binary.LittleEndian.PutUint16(data[1:], 318)
+	data[3] = 100
+	data[4] = 10
+	data[5] = 3
+	binary.LittleEndian.PutUint64(data[48:], 12_345_678)
+	binary.LittleEndian.PutUint64(data[56:], 1)
+	binary.LittleEndian.PutUint64(data[128:], 8_760)
+	binary.LittleEndian.PutUint64(data[160:], 2)
+	binary.LittleEndian.PutUint32(data[196:], 5)
+	binary.LittleEndian.PutUint32(data[216:], 7)
+	binary.LittleEndian.PutUint32(data[228:], 90)
+
+	log, err := parseHealthLog(data)
+	require.NoError(t, err)
+
+	require.Equal(t, uint8(0b0000_0110), log.criticalWarning)
+	require.Equal(t, uint16(318), log.temperatureKelvin)
+	require.Equal(t, uint8(100), log.availableSpare)
+	require.Equal(t, uint8(10), log.availableSpareThreshold)
+	require.Equal(t, uint8(3), log.percentageUsed)
+	require.InDelta(t, 1<<64+12_345_678.0, log.dataUnitsWritten, 1e5)
+	require.InDelta(t, 8_760, log.powerOnHours, 0)
+	require.InDelta(t, 2, log.mediaErrors, 0)
+	require.Equal(t, uint32(5), log.criticalTempMinutes)
+	require.Equal(t, [2]uint32{7, 0}, log.thermalTransitions)
+	require.Equal(t, [2]uint32{0, 90}, log.thermalSeconds)
+
+	_, err = parseHealthLog(data[:healthLogSize-1])
+	require.ErrorIs(t, err, errShortHealthLog)
+}
@@ -0,0 +1,469 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package nvme
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	Name = "nvme"
+
+	ioctlStorageQueryProperty = 0x2D1400 // IOCTL_STORAGE_QUERY_PROPERTY
+
+	// STORAGE_PROPERTY_ID and STORAGE_BUS_TYPE values, see winioctl.h.
+	storageDeviceProperty                 = 0
+	storageDeviceProtocolSpecificProperty = 50
+	busTypeNvme                           = 17
+
+	// STORAGE_PROTOCOL_TYPE and STORAGE_PROTOCOL_NVME_DATA_TYPE values and the identifier of the health log page.
+	protocolTypeNvme      = 3
+	nvmeDataTypeLogPage   = 2
+	nvmeLogPageHealthInfo = 2
+
+	// protocolSpecificDataSize is the size of STORAGE_PROTOCOL_SPECIFIC_DATA, which follows the
+	// PropertyId and QueryType of STORAGE_PROPERTY_QUERY and the Version and Size of STORAGE_PROTOCOL_DATA_DESCRIPTOR.
+	protocolSpecificDataSize = 40
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var diskDriveQuery = utils.Must(mi.NewQuery("SELECT Index, Model, SerialNumber, FirmwareRevision FROM Win32_DiskDrive"))
+
+// A Collector is a Prometheus Collector for the SMART / Health Information log of directly attached NVMe drives,
+// which is read with IOCTL_STORAGE_QUERY_PROPERTY. Drives behind RAID controllers, Storage Spaces or USB bridges
+// don't report the NVMe bus type and are left out.
+type Collector struct {
+	config    Config
+	logger    *slog.Logger
+	miSession *mi.Session
+
+	info                         *prometheus.Desc
+	criticalWarning              *prometheus.Desc
+	temperatureCelsius           *prometheus.Desc
+	availableSpareRatio          *prometheus.Desc
+	availableSpareThresholdRatio *prometheus.Desc
+	percentageUsedRatio          *prometheus.Desc
+	readBytesTotal               *prometheus.Desc
+	writtenBytesTotal            *prometheus.Desc
+	hostReadCommandsTotal        *prometheus.Desc
+	hostWriteCommandsTotal       *prometheus.Desc
+	controllerBusySecondsTotal   *prometheus.Desc
+	powerCyclesTotal             *prometheus.Desc
+	powerOnSecondsTotal          *prometheus.Desc
+	unsafeShutdownsTotal         *prometheus.Desc
+	mediaErrorsTotal             *prometheus.Desc
+	errorLogEntriesTotal         *prometheus.Desc
+	temperatureSecondsTotal      *prometheus.Desc
+	thermalThrottleEventsTotal   *prometheus.Desc
+	thermalThrottleSecondsTotal  *prometheus.Desc
+}
+
+type win32DiskDrive struct {
+	Index            uint32 `mi:"Index"`
+	Model            string `mi:"Model"`
+	SerialNumber     string `mi:"SerialNumber"`
+	FirmwareRevision string `mi:"FirmwareRevision"`
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.logger = logger.With(slog.String("collector", Name))
+	c.miSession = miSession
+
+	c.info = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "info"),
+		"NVMe drive with its model, serial number and firmware revision",
+		[]string{"disk", "model", "serial_number", "firmware"},
+		nil,
+	)
+	c.criticalWarning = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "critical_warning"),
+		"Critical warning of the health log by warning (1 if the warning is set)",
+		[]string{"disk", "warning"},
+		nil,
+	)
+	c.temperatureCelsius = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "temperature_celsius"),
+		"Composite temperature of the controller and the namespaces",
+		[]string{"disk"},
+		nil,
+	)
+	c.availableSpareRatio = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "available_spare_ratio"),
+		"Remaining spare capacity",
+		[]string{"disk"},
+		nil,
+	)
+	c.availableSpareThresholdRatio = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "available_spare_threshold_ratio"),
+		"Available spare below which the available spare critical warning is set",
+		[]string{"disk"},
+		nil,
+	)
+	c.percentageUsedRatio = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "percentage_used_ratio"),
+		"Vendor estimate of the used life of the drive, which may exceed 1",
+		[]string{"disk"},
+		nil,
+	)
+	c.readBytesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "read_bytes_total"),
+		"Data read by the host, with a resolution of 512,000 bytes",
+		[]string{"disk"},
+		nil,
+	)
+	c.writtenBytesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "written_bytes_total"),
+		"Data written by the host, with a resolution of 512,000 bytes",
+		[]string{"disk"},
+		nil,
+	)
+	c.hostReadCommandsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "host_read_commands_total"),
+		"Read commands completed by the controller",
+		[]string{"disk"},
+		nil,
+	)
+	c.hostWriteCommandsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "host_write_commands_total"),
+		"Write commands completed by the controller",
+		[]string{"disk"},
+		nil,
+	)
+	c.controllerBusySecondsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "controller_busy_seconds_total"),
+		"Time the controller was busy with I/O commands, with a resolution of minutes",
+		[]string{"disk"},
+		nil,
+	)
+	c.powerCyclesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "power_cycles_total"),
+		"Power cycles of the drive",
+		[]string{"disk"},
+		nil,
+	)
+	c.powerOnSecondsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "power_on_seconds_total"),
+		"Power-on time of the drive, with a resolution of hours",
+		[]string{"disk"},
+		nil,
+	)
+	c.unsafeShutdownsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "unsafe_shutdowns_total"),
+		"Shutdowns without a shutdown notification of the host",
+		[]string{"disk"},
+		nil,
+	)
+	c.mediaErrorsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "media_errors_total"),
+		"Unrecovered data integrity errors detected by the controller",
+		[]string{"disk"},
+		nil,
+	)
+	c.errorLogEntriesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "error_log_entries_total"),
+		"Error information log entries over the life of the controller",
+		[]string{"disk"},
+		nil,
+	)
+	c.temperatureSecondsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "temperature_above_threshold_seconds_total"),
+		"Time the composite temperature was above the warning or critical threshold, with a resolution of minutes",
+		[]string{"disk", "threshold"},
+		nil,
+	)
+	c.thermalThrottleEventsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "thermal_throttle_events_total"),
+		"Transitions to the thermal management temperature by level (1 light, 2 heavy throttling)",
+		[]string{"disk", "level"},
+		nil,
+	)
+	c.thermalThrottleSecondsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "thermal_throttle_seconds_total"),
+		"Time the controller throttled at the thermal management temperature by level (1 light, 2 heavy throttling)",
+		[]string{"disk", "level"},
+		nil,
+	)
+
+	var dst []win32DiskDrive
+	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, diskDriveQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	var disks []win32DiskDrive
+	if err := c.miSession.Query(&disks, mi.NamespaceRootCIMv2, diskDriveQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	var errs []error
+
+	for _, disk := range disks {
+		path := `\\.\PhysicalDrive` + strconv.FormatUint(uint64(disk.Index), 10)
+
+		log, ok, err := queryHealthLog(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to query health log of %s: %w", path, err))
+
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		c.collectDisk(ch, disk, log)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectDisk(ch chan<- prometheus.Metric, disk win32DiskDrive, log healthLog) {
+	diskID := strconv.FormatUint(uint64(disk.Index), 10)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.info,
+		prometheus.GaugeValue,
+		1,
+		diskID,
+		strings.TrimSpace(disk.Model),
+		strings.TrimSpace(disk.SerialNumber),
+		strings.TrimSpace(disk.FirmwareRevision),
+	)
+
+	for bit, warning := range criticalWarnings {
+		ch <- prometheus.MustNewConstMetric(
+			c.criticalWarning,
+			prometheus.GaugeValue,
+			float64(log.criticalWarning>>bit&1),
+			diskID,
+			warning,
+		)
+	}
+
+	// A temperature of 0 is reported by controllers that don't implement it.
+	if log.temperatureKelvin > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			c.temperatureCelsius,
+			prometheus.GaugeValue,
+			float64(log.temperatureKelvin)-273.15,
+			diskID,
+		)
+	}
+
+	for _, gauge := range []struct {
+		desc  *prometheus.Desc
+		value float64
+	}{
+		{c.availableSpareRatio, float64(log.availableSpare) / 100},
+		{c.availableSpareThresholdRatio, float64(log.availableSpareThreshold) / 100},
+		{c.percentageUsedRatio, float64(log.percentageUsed) / 100},
+	} {
+		ch <- prometheus.MustNewConstMetric(gauge.desc, prometheus.GaugeValue, gauge.value, diskID)
+	}
+
+	for _, counter := range []struct {
+		desc  *prometheus.Desc
+		value float64
+	}{
+		{c.readBytesTotal, log.dataUnitsRead * 512_000},
+		{c.writtenBytesTotal, log.dataUnitsWritten * 512_000},
+		{c.hostReadCommandsTotal, log.hostReadCommands},
+		{c.hostWriteCommandsTotal, log.hostWriteCommands},
+		{c.controllerBusySecondsTotal, log.controllerBusyMinutes * 60},
+		{c.powerCyclesTotal, log.powerCycles},
+		{c.powerOnSecondsTotal, log.powerOnHours * 3600},
+		{c.unsafeShutdownsTotal, log.unsafeShutdowns},
+		{c.mediaErrorsTotal, log.mediaErrors},
+		{c.errorLogEntriesTotal, log.errorLogEntries},
+	} {
+		ch <- prometheus.MustNewConstMetric(counter.desc, prometheus.CounterValue, counter.value, diskID)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.temperatureSecondsTotal,
+		prometheus.CounterValue,
+		float64(log.warningTempMinutes)*60,
+		diskID,
+		"warning",
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.temperatureSecondsTotal,
+		prometheus.CounterValue,
+		float64(log.criticalTempMinutes)*60,
+		diskID,
+		"critical",
+	)
+
+	for i := range log.thermalTransitions {
+		level := strconv.Itoa(i + 1)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.thermalThrottleEventsTotal,
+			prometheus.CounterValue,
+			float64(log.thermalTransitions[i]),
+			diskID,
+			level,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.thermalThrottleSecondsTotal,
+			prometheus.CounterValue,
+			float64(log.thermalSeconds[i]),
+			diskID,
+			level,
+		)
+	}
+}
+
+// queryHealthLog returns the health information log of the disk, or false if the disk is not an NVMe drive.
+// The bus type is queried without access rights, the protocol specific query requires read and write access
+// to the disk, i.e. administrative privileges.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/fileio/working-with-nvme-devices#example-nvme-get-log-pages-query
+func queryHealthLog(path string) (healthLog, bool, error) {
+	handle, err := openDisk(path, 0)
+	if err != nil {
+		return healthLog{}, false, err
+	}
+
+	nvme, err := isNvme(handle)
+	_ = windows.Close(handle)
+
+	if err != nil || !nvme {
+		return healthLog{}, false, err
+	}
+
+	handle, err = openDisk(path, windows.GENERIC_READ|windows.GENERIC_WRITE)
+	if err != nil {
+		return healthLog{}, false, err
+	}
+
+	defer func(fd windows.Handle) {
+		_ = windows.Close(fd)
+	}(handle)
+
+	// STORAGE_PROPERTY_QUERY with STORAGE_PROTOCOL_SPECIFIC_DATA as additional parameters, followed by the log page.
+	buffer := make([]byte, 8+protocolSpecificDataSize+healthLogSize)
+	binary.LittleEndian.PutUint32(buffer[0:], storageDeviceProtocolSpecificProperty)
+	binary.LittleEndian.PutUint32(buffer[8:], protocolTypeNvme)
+	binary.LittleEndian.PutUint32(buffer[12:], nvmeDataTypeLogPage)
+	binary.LittleEndian.PutUint32(buffer[16:], nvmeLogPageHealthInfo)
+	binary.LittleEndian.PutUint32(buffer[24:], protocolSpecificDataSize)
+	binary.LittleEndian.PutUint32(buffer[28:], healthLogSize)
+
+	var bytesReturned uint32
+
+	err = windows.DeviceIoControl(handle, ioctlStorageQueryProperty, &buffer[0], uint32(len(buffer)), &buffer[0], uint32(len(buffer)), &bytesReturned, nil)
+	if err != nil {
+		return healthLog{}, false, fmt.Errorf("failed to query health information log: %w", err)
+	}
+
+	// The response is a STORAGE_PROTOCOL_DATA_DESCRIPTOR, whose STORAGE_PROTOCOL_SPECIFIC_DATA at offset 8
+	// contains the offset and the length of the log page relative to itself.
+	offset := 8 + int(binary.LittleEndian.Uint32(buffer[24:]))
+	length := int(binary.LittleEndian.Uint32(buffer[28:]))
+
+	if length < healthLogSize || offset+healthLogSize > int(bytesReturned) {
+		return healthLog{}, false, errShortHealthLog
+	}
+
+	log, err := parseHealthLog(buffer[offset : offset+healthLogSize])
+	if err != nil {
+		return healthLog{}, false, err
+	}
+
+	return log, true, nil
+}
+
+func openDisk(path string, access uint32) (windows.Handle, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+
+	mode := uint32(windows.FILE_SHARE_READ | windows.FILE_SHARE_WRITE | windows.FILE_SHARE_DELETE)
+
+	return windows.CreateFile(pathPtr, access, mode, nil, windows.OPEN_EXISTING, 0, 0)
+}
+
+// isNvme reports whether the bus type of the disk is NVMe.
+func isNvme(handle windows.Handle) (bool, error) {
+	// STORAGE_PROPERTY_QUERY with PropertyId StorageDeviceProperty and QueryType PropertyStandardQuery.
+	query := make([]byte, 12)
+	binary.LittleEndian.PutUint32(query, storageDeviceProperty)
+
+	descriptor := make([]byte, 1024)
+
+	var bytesReturned uint32
+
+	err := windows.DeviceIoControl(handle, ioctlStorageQueryProperty, &query[0], uint32(len(query)), &descriptor[0], uint32(len(descriptor)), &bytesReturned, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to query device descriptor: %w", err)
+	}
+
+	// BusType is at offset 28 of STORAGE_DEVICE_DESCRIPTOR.
+	return bytesReturned >= 32 && binary.LittleEndian.Uint32(descriptor[28:]) == busTypeNvme, nil
+}
@@ -0,0 +1,295 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package onedrive
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "onedrive"
+
+	oneDriveKey         = `Software\Microsoft\OneDrive`
+	oneDriveAccountsKey = oneDriveKey + `\Accounts`
+	userShellFoldersKey = `Software\Microsoft\Windows\CurrentVersion\Explorer\User Shell Folders`
+	profileListKey      = `SOFTWARE\Microsoft\Windows NT\CurrentVersion\ProfileList`
+
+	// userSIDPrefix is the prefix of the SIDs of local and domain user accounts.
+	userSIDPrefix = "S-1-5-21-"
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+// knownFolders maps the User Shell Folders values of the folders moved by Known Folder Move to label values.
+//
+//nolint:gochecknoglobals
+var knownFolders = map[string]string{
+	"Desktop":     "desktop",
+	"Personal":    "documents",
+	"My Pictures": "pictures",
+}
+
+// A Collector is a Prometheus Collector for the OneDrive sync client.
+// The configuration of the sync client is read from the registry hives of the users that are logged on.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	clientInfo            *prometheus.Desc
+	accountInfo           *prometheus.Desc
+	knownFolderRedirected *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.clientInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "client_info"),
+		"A metric with a constant '1' value labeled with the version of the OneDrive sync client of the user",
+		[]string{"user", "version"},
+		nil,
+	)
+	c.accountInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "account_info"),
+		"A metric with a constant '1' value for each account configured in the OneDrive sync client of the user",
+		[]string{"user", "account", "type"},
+		nil,
+	)
+	c.knownFolderRedirected = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "known_folder_redirected"),
+		"1 if the known folder of the user is redirected into the OneDrive folder of the account (Known Folder Move), 0 otherwise",
+		[]string{"user", "account", "folder"},
+		nil,
+	)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	users, err := registry.USERS.ReadSubKeyNames(-1)
+	if err != nil {
+		return fmt.Errorf("failed to read registry key HKEY_USERS: %w", err)
+	}
+
+	errs := make([]error, 0)
+
+	for _, sid := range users {
+		if !strings.HasPrefix(sid, userSIDPrefix) || strings.HasSuffix(sid, "_Classes") {
+			continue
+		}
+
+		if err := c.collectUser(ch, sid); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting OneDrive metrics of %s: %w", sid, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectUser(ch chan<- prometheus.Metric, sid string) error {
+	key, err := registry.OpenKey(registry.USERS, sid+`\`+oneDriveKey, registry.QUERY_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to open registry key %s: %w", oneDriveKey, err)
+	}
+
+	defer key.Close()
+
+	user := lookupUser(sid)
+
+	if version, _, err := key.GetStringValue("Version"); err == nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.clientInfo,
+			prometheus.GaugeValue,
+			1,
+			user,
+			version,
+		)
+	}
+
+	accountsKey, err := registry.OpenKey(registry.USERS, sid+`\`+oneDriveAccountsKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to open registry key %s: %w", oneDriveAccountsKey, err)
+	}
+
+	defer accountsKey.Close()
+
+	accounts, err := accountsKey.ReadSubKeyNames(-1)
+	if err != nil {
+		return fmt.Errorf("failed to read registry key %s: %w", oneDriveAccountsKey, err)
+	}
+
+	shellFolders := c.userShellFolders(sid)
+
+	for _, account := range accounts {
+		userFolder, err := readStringValue(registry.USERS, sid+`\`+oneDriveAccountsKey+`\`+account, "UserFolder")
+		if err != nil {
+			// Accounts without a folder have never been signed in.
+			continue
+		}
+
+		accountType := "personal"
+		if strings.HasPrefix(account, "Business") {
+			accountType = "business"
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.accountInfo,
+			prometheus.GaugeValue,
+			1,
+			user,
+			account,
+			accountType,
+		)
+
+		for valueName, folder := range knownFolders {
+			ch <- prometheus.MustNewConstMetric(
+				c.knownFolderRedirected,
+				prometheus.GaugeValue,
+				utils.BoolToFloat(isSubPath(shellFolders[valueName], userFolder)),
+				user,
+				account,
+				folder,
+			)
+		}
+	}
+
+	return nil
+}
+
+// userShellFolders returns the paths of the known folders of the user. %USERPROFILE% is expanded with
+// the profile path of the user, since the environment of windows_exporter belongs to another user.
+func (c *Collector) userShellFolders(sid string) map[string]string {
+	profilePath, err := readStringValue(registry.LOCAL_MACHINE, profileListKey+`\`+sid, "ProfileImagePath")
+	if err != nil {
+		c.logger.Debug("failed to read the profile path of the user",
+			slog.String("sid", sid),
+			slog.Any("err", err),
+		)
+	}
+
+	folders := make(map[string]string, len(knownFolders))
+
+	for valueName := range knownFolders {
+		path, err := readStringValue(registry.USERS, sid+`\`+userShellFoldersKey, valueName)
+		if err != nil {
+			continue
+		}
+
+		if len(path) >= len("%USERPROFILE%") && strings.EqualFold(path[:len("%USERPROFILE%")], "%USERPROFILE%") {
+			path = profilePath + path[len("%USERPROFILE%"):]
+		}
+
+		folders[valueName] = path
+	}
+
+	return folders
+}
+
+func readStringValue(root registry.Key, path string, name string) (string, error) {
+	key, err := registry.OpenKey(root, path, registry.QUERY_VALUE)
+	if err != nil {
+		return "", err
+	}
+
+	defer key.Close()
+
+	value, _, err := key.GetStringValue(name)
+	if err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
+
+// isSubPath returns true if path is located within the directory dir.
+func isSubPath(path string, dir string) bool {
+	if path == "" || dir == "" {
+		return false
+	}
+
+	rel, err := filepath.Rel(strings.ToLower(filepath.Clean(dir)), strings.ToLower(filepath.Clean(path)))
+	if err != nil {
+		return false
+	}
+
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, `..\`)
+}
+
+// lookupUser returns the account name of the SID as DOMAIN\user, or the SID if the account can't be resolved.
+func lookupUser(sid string) string {
+	userSID, err := windows.StringToSid(sid)
+	if err != nil {
+		return sid
+	}
+
+	account, domain, _, err := userSID.LookupAccount("")
+	if err != nil {
+		return sid
+	}
+
+	return domain + `\` + account
+}
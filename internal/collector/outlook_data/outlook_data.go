@@ -0,0 +1,476 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package outlook_data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "outlook_data"
+
+	// profileListKey contains a subkey for each local user profile, named by the SID of the user.
+	profileListKey = `SOFTWARE\Microsoft\Windows NT\CurrentVersion\ProfileList`
+)
+
+type Config struct {
+	ScanInterval time.Duration `yaml:"scan-interval"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	ScanInterval: time.Hour,
+}
+
+// serviceSIDs are the well-known SIDs of the LocalSystem, LocalService and NetworkService accounts,
+// whose profiles are not user profiles.
+//
+//nolint:gochecknoglobals
+var serviceSIDs = map[string]struct{}{
+	"S-1-5-18": {},
+	"S-1-5-19": {},
+	"S-1-5-20": {},
+}
+
+// dataFileDirectories are the default directories of the Outlook data files below the profile directory:
+// the offline folder files (.ost) of Exchange and Microsoft 365 mailboxes and the personal folder files (.pst).
+//
+//nolint:gochecknoglobals
+var dataFileDirectories = []string{
+	`AppData\Local\Microsoft\Outlook`,
+	`Documents\Outlook Files`,
+}
+
+// dataFileTypes are the file extensions of the Outlook data files, the type label.
+//
+//nolint:gochecknoglobals
+var dataFileTypes = []string{"ost", "pst"}
+
+// A Collector is a Prometheus Collector for the size of the Outlook data files and the Windows Search indexes
+// of the local user profiles, which fill the profile disks of session hosts, e.g. of Citrix and Azure Virtual Desktop.
+// The files are scanned in the background every ScanInterval, since walking the profiles of a session host takes a while.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	ctxCancelFn context.CancelFunc
+
+	// mu protects the result of the last scan.
+	mu                    sync.RWMutex
+	users                 []userData
+	systemSearchIndexSize float64
+	scanDuration          float64
+	scanTime              time.Time
+
+	dataFiles                  *prometheus.Desc
+	dataFileSizeBytes          *prometheus.Desc
+	searchIndexSizeBytes       *prometheus.Desc
+	systemSearchIndexSizeBytes *prometheus.Desc
+	scanDurationSeconds        *prometheus.Desc
+	scanTimestampSeconds       *prometheus.Desc
+}
+
+// userData are the sizes of the data files of a user profile. user is the name of the profile directory.
+type userData struct {
+	user string
+	// files and sizeBytes are the number and the total size of the data files by type.
+	files     map[string]int
+	sizeBytes map[string]int64
+	// searchIndexBytes is the size of the per-user search index, which is missing if the index is shared by all users.
+	searchIndexBytes int64
+	searchIndex      bool
+}
+
+// profile is a local user profile of the ProfileList.
+type profile struct {
+	sid  string
+	path string
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.ScanInterval == 0 {
+		config.ScanInterval = ConfigDefaults.ScanInterval
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	app.Flag(
+		"collector.outlook_data.scan-interval",
+		"Interval of scanning the user profiles for Outlook data files and search indexes.",
+	).Default(ConfigDefaults.ScanInterval.String()).DurationVar(&c.config.ScanInterval)
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+// LastSuccess returns the time of the last successful scan, see collector.BackgroundCollector.
+func (c *Collector) LastSuccess() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.scanTime
+}
+
+func (c *Collector) Close() error {
+	if c.ctxCancelFn != nil {
+		c.ctxCancelFn()
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	if c.config.ScanInterval <= 0 {
+		return fmt.Errorf("invalid scan interval %s, expected a positive duration", c.config.ScanInterval)
+	}
+
+	c.dataFiles = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "files"),
+		"Number of Outlook data files of the user profile by type (ost, pst) at the last scan",
+		[]string{"user", "type"},
+		nil,
+	)
+	c.dataFileSizeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "file_size_bytes"),
+		"Total size of the Outlook data files of the user profile by type (ost, pst) at the last scan",
+		[]string{"user", "type"},
+		nil,
+	)
+	c.searchIndexSizeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "search_index_size_bytes"),
+		"Size of the per-user Windows Search index of the user profile at the last scan",
+		[]string{"user"},
+		nil,
+	)
+	c.systemSearchIndexSizeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "system_search_index_size_bytes"),
+		"Size of the Windows Search index shared by all users at the last scan",
+		nil,
+		nil,
+	)
+	c.scanDurationSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "scan_duration_seconds"),
+		"Duration of the last scan of the user profiles",
+		nil,
+		nil,
+	)
+	c.scanTimestampSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "scan_timestamp_seconds"),
+		"Timestamp of the end of the last scan of the user profiles",
+		nil,
+		nil,
+	)
+
+	if _, err := readProfiles(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.ctxCancelFn = cancel
+
+	go c.scheduleScan(ctx)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// The metrics are missing until the first scan finished.
+	if c.scanTime.IsZero() {
+		return nil
+	}
+
+	for _, user := range c.users {
+		for _, fileType := range dataFileTypes {
+			ch <- prometheus.MustNewConstMetric(
+				c.dataFiles,
+				prometheus.GaugeValue,
+				float64(user.files[fileType]),
+				user.user,
+				fileType,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.dataFileSizeBytes,
+				prometheus.GaugeValue,
+				float64(user.sizeBytes[fileType]),
+				user.user,
+				fileType,
+			)
+		}
+
+		if user.searchIndex {
+			ch <- prometheus.MustNewConstMetric(
+				c.searchIndexSizeBytes,
+				prometheus.GaugeValue,
+				float64(user.searchIndexBytes),
+				user.user,
+			)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.systemSearchIndexSizeBytes,
+		prometheus.GaugeValue,
+		c.systemSearchIndexSize,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.scanDurationSeconds,
+		prometheus.GaugeValue,
+		c.scanDuration,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.scanTimestampSeconds,
+		prometheus.GaugeValue,
+		float64(c.scanTime.Unix()),
+	)
+
+	return nil
+}
+
+func (c *Collector) scheduleScan(ctx context.Context) {
+	for {
+		if err := c.scan(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			c.logger.LogAttrs(ctx, slog.LevelWarn, "failed to scan the user profiles",
+				slog.Any("err", err),
+			)
+		}
+
+		select {
+		case <-time.After(c.config.ScanInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Collector) scan(ctx context.Context) error {
+	start := time.Now()
+
+	profiles, err := readProfiles()
+	if err != nil {
+		return err
+	}
+
+	users := make([]userData, 0, len(profiles))
+
+	for _, p := range profiles {
+		user, err := scanProfile(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		users = append(users, user)
+	}
+
+	programData, err := windows.KnownFolderPath(windows.FOLDERID_ProgramData, 0)
+	if err != nil {
+		return fmt.Errorf("failed to get the ProgramData folder: %w", err)
+	}
+
+	// Windows.edb, or Windows.db since Windows 11, and the files of the system-wide catalog.
+	systemSearchIndexSize, _, err := directorySize(ctx, filepath.Join(programData, `Microsoft\Search\Data\Applications\Windows`), nil)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.users = users
+	c.systemSearchIndexSize = float64(systemSearchIndexSize)
+	c.scanTime = time.Now()
+	c.scanDuration = c.scanTime.Sub(start).Seconds()
+
+	return nil
+}
+
+// scanProfile returns the sizes of the Outlook data files and of the per-user search index of the profile.
+// Multi-session editions of Windows and Windows Server 2019 and later keep a search index per user
+// in the roaming AppData of the profile, which is roamed by profile containers, e.g. of FSLogix.
+func scanProfile(ctx context.Context, p profile) (userData, error) {
+	user := userData{
+		user:      filepath.Base(p.path),
+		files:     make(map[string]int, len(dataFileTypes)),
+		sizeBytes: make(map[string]int64, len(dataFileTypes)),
+	}
+
+	for _, directory := range dataFileDirectories {
+		_, _, err := directorySize(ctx, filepath.Join(p.path, directory), func(path string, size int64) {
+			fileType := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+			if !slices.Contains(dataFileTypes, fileType) {
+				return
+			}
+
+			user.files[fileType]++
+			user.sizeBytes[fileType] += size
+		})
+		if err != nil {
+			return userData{}, err
+		}
+	}
+
+	searchIndexBytes, found, err := directorySize(ctx, filepath.Join(p.path, `AppData\Roaming\Microsoft\Search\Data\Applications`, p.sid), nil)
+	if err != nil {
+		return userData{}, err
+	}
+
+	user.searchIndexBytes = searchIndexBytes
+	user.searchIndex = found
+
+	return user, nil
+}
+
+// directorySize returns the size of the files below path and whether the directory exists. fn is called
+// for each file, if it is not nil. Junctions are not followed and files that can't be read are skipped.
+// Profile directories of users that are not logged on are missing on hosts with profile containers.
+func directorySize(ctx context.Context, path string, fn func(path string, size int64)) (int64, bool, error) {
+	var size int64
+
+	err := filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if err != nil {
+			if filePath == path {
+				return err
+			}
+
+			if entry != nil && entry.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil //nolint:nilerr // e.g. a file deleted during the scan
+		}
+
+		size += info.Size()
+
+		if fn != nil {
+			fn(filePath, info.Size())
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, false, nil
+		}
+
+		if errors.Is(err, context.Canceled) {
+			return 0, false, err
+		}
+
+		// e.g. the profile directory of another user can't be read.
+		return 0, false, nil
+	}
+
+	return size, true, nil
+}
+
+// readProfiles returns the user profiles of the ProfileList. Profiles of the service accounts and backups
+// of corrupted profiles ("<SID>.bak") are left out.
+func readProfiles() ([]profile, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, profileListKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open registry key %s: %w", profileListKey, err)
+	}
+
+	defer key.Close()
+
+	sids, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry key %s: %w", profileListKey, err)
+	}
+
+	profiles := make([]profile, 0, len(sids))
+
+	for _, sid := range sids {
+		if _, ok := serviceSIDs[sid]; ok || strings.HasSuffix(sid, ".bak") {
+			continue
+		}
+
+		profileKey, err := registry.OpenKey(key, sid, registry.QUERY_VALUE)
+		if err != nil {
+			// The profile may have been deleted since the subkeys were read.
+			continue
+		}
+
+		path, _, err := profileKey.GetStringValue("ProfileImagePath")
+		_ = profileKey.Close()
+
+		if err != nil {
+			continue
+		}
+
+		if path, err = registry.ExpandString(path); err != nil {
+			continue
+		}
+
+		profiles = append(profiles, profile{sid: sid, path: path})
+	}
+
+	return profiles, nil
+}
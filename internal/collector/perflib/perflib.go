@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package perflib
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	pdhregistry "github.com/prometheus-community/windows_exporter/internal/pdh/registry"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "perflib"
+
+	servicesRegistryKey = `SYSTEM\CurrentControlSet\Services`
+
+	reasonDisabled            = "disabled"
+	reasonMissingCounterIndex = "missing_counter_index"
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+// A Collector is a Prometheus Collector that detects broken performance counter providers.
+// Broken providers are the most common cause of performance counter collectors failing with
+// generic errors, and they are not reported by the performance counter API itself.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	// mu protects reported against concurrent scrapes.
+	mu sync.Mutex
+	// reported contains the broken providers that were already logged, to log each provider only once.
+	reported map[brokenProvider]struct{}
+
+	providerBroken *prometheus.Desc
+}
+
+type brokenProvider struct {
+	service string
+	object  string
+	reason  string
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.providerBroken = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "provider_broken"),
+		"1 if the performance counter provider of the service is broken. Only broken providers are reported",
+		[]string{"object", "service", "reason"},
+		nil,
+	)
+
+	c.reported = make(map[brokenProvider]struct{})
+
+	if _, err := c.scan(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	providers, err := c.scan()
+	if err != nil {
+		return err
+	}
+
+	for _, provider := range providers {
+		ch <- prometheus.MustNewConstMetric(
+			c.providerBroken,
+			prometheus.GaugeValue,
+			1,
+			provider.object,
+			provider.service,
+			provider.reason,
+		)
+	}
+
+	return nil
+}
+
+// scan checks the performance counter registration of all services and logs a remediation hint
+// for each broken provider found for the first time.
+func (c *Collector) scan() ([]brokenProvider, error) {
+	providers, err := findBrokenProviders()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, provider := range providers {
+		if _, ok := c.reported[provider]; ok {
+			continue
+		}
+
+		c.reported[provider] = struct{}{}
+
+		hint := "run 'lodctr /R' as administrator to rebuild the performance counter registry"
+		if provider.reason == reasonDisabled {
+			hint = fmt.Sprintf("run 'lodctr /E:%s' as administrator to enable the performance counters", provider.service)
+		}
+
+		c.logger.Warn("broken performance counter provider detected, "+hint,
+			slog.String("service", provider.service),
+			slog.String("object", provider.object),
+			slog.String("reason", provider.reason),
+		)
+	}
+
+	return providers, nil
+}
+
+func findBrokenProviders() ([]brokenProvider, error) {
+	servicesKey, err := registry.OpenKey(registry.LOCAL_MACHINE, servicesRegistryKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open registry key %s: %w", servicesRegistryKey, err)
+	}
+
+	defer servicesKey.Close()
+
+	services, err := servicesKey.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry key %s: %w", servicesRegistryKey, err)
+	}
+
+	providers := make([]brokenProvider, 0)
+
+	for _, service := range services {
+		key, err := registry.OpenKey(servicesKey, service+`\Performance`, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+
+		providers = append(providers, checkProvider(key, service)...)
+
+		key.Close()
+	}
+
+	return providers, nil
+}
+
+// checkProvider checks the Performance key of a service. Perflib sets "Disable Performance Counters" after
+// a provider failed repeatedly. "First Counter" is the index of the object name, which is missing in the
+// counter name table if the name table is corrupt or the provider was not registered with lodctr.
+func checkProvider(key registry.Key, service string) []brokenProvider {
+	providers := make([]brokenProvider, 0, 1)
+
+	object := service
+
+	firstCounter, _, err := key.GetIntegerValue("First Counter")
+	if err == nil {
+		if name := pdhregistry.CounterNameTable.LookupString(uint32(firstCounter)); name != "" {
+			object = name
+		} else {
+			providers = append(providers, brokenProvider{service: service, object: object, reason: reasonMissingCounterIndex})
+		}
+	} else if !errors.Is(err, registry.ErrNotExist) {
+		return providers
+	}
+
+	disabled, _, err := key.GetIntegerValue("Disable Performance Counters")
+	if err == nil && disabled != 0 {
+		providers = append(providers, brokenProvider{service: service, object: object, reason: reasonDisabled})
+	}
+
+	return providers
+}
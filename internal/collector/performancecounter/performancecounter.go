@@ -30,6 +30,7 @@ import (
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
 	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/pdh/registry"
 	"github.com/prometheus-community/windows_exporter/internal/types"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.yaml.in/yaml/v3"
@@ -185,7 +186,7 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 				return reflect.StructField{
 					Name: strings.ToUpper(sanitizeMetricName(name)),
 					Type: reflect.TypeOf(float64(0)),
-					Tag:  reflect.StructTag(fmt.Sprintf(`perfdata:"%s"`, name)),
+					Tag:  reflect.StructTag(fmt.Sprintf(`perfdata:"%s"`, registry.EnglishCounterName(name))),
 				}, nil
 			}(counter.Name)
 			if err != nil {
@@ -215,7 +216,9 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 			object.Type = pdh.CounterTypeRaw
 		}
 
-		collector, err := pdh.NewCollectorWithReflection(c.logger, object.Type, object.Object, object.Instances, valueType)
+		// Counters are added by their English name, which is the same on all installations.
+		// Localized names copied from perfmon are translated by their index.
+		collector, err := pdh.NewCollectorWithReflection(c.logger, object.Type, registry.EnglishCounterName(object.Object), object.Instances, valueType)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("failed collector for %s: %w", object.Name, err))
 		}
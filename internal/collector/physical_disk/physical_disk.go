@@ -18,14 +18,18 @@
 package physical_disk
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
 	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/sampler"
 	"github.com/prometheus-community/windows_exporter/internal/types"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -33,23 +37,37 @@ import (
 const Name = "physical_disk"
 
 type Config struct {
-	DiskInclude *regexp.Regexp `yaml:"disk-include"`
-	DiskExclude *regexp.Regexp `yaml:"disk-exclude"`
+	DiskInclude           *regexp.Regexp `yaml:"disk-include"`
+	DiskExclude           *regexp.Regexp `yaml:"disk-exclude"`
+	EnableStorageInfo     bool           `yaml:"storage-info"`
+	LatencySampleInterval time.Duration  `yaml:"latency-sample-interval"`
 }
 
 //nolint:gochecknoglobals
 var ConfigDefaults = Config{
-	DiskInclude: types.RegExpAny,
-	DiskExclude: types.RegExpEmpty,
+	DiskInclude:           types.RegExpAny,
+	DiskExclude:           types.RegExpEmpty,
+	EnableStorageInfo:     false,
+	LatencySampleInterval: 0,
 }
 
 // A Collector is a Prometheus Collector for perflib PhysicalDisk metrics.
 type Collector struct {
 	config Config
+	logger *slog.Logger
+
+	miSession *mi.Session
 
 	perfDataCollector *pdh.Collector
 	perfDataObject    []perfDataCounterValues
 
+	latencyPerfDataCollector *pdh.Collector
+	latencySampler           *sampler.Sampler
+	latencySampled           sampler.Descs
+
+	avgReadQueue     *prometheus.Desc
+	avgRequestsQueue *prometheus.Desc
+	avgWriteQueue    *prometheus.Desc
 	idleTime         *prometheus.Desc
 	readBytesTotal   *prometheus.Desc
 	readLatency      *prometheus.Desc
@@ -58,6 +76,7 @@ type Collector struct {
 	readsTotal       *prometheus.Desc
 	requestsQueued   *prometheus.Desc
 	splitIOs         *prometheus.Desc
+	storageInfo      *prometheus.Desc
 	writeBytesTotal  *prometheus.Desc
 	writeLatency     *prometheus.Desc
 	writeTime        *prometheus.Desc
@@ -101,6 +120,16 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 		"Regexp of disks to include. Disk number must both match include and not match exclude to be included.",
 	).Default(".+").StringVar(&diskInclude)
 
+	app.Flag(
+		"collector.physical_disk.storage-info",
+		"If enabled, windows_physical_disk_storage_info metrics linking disks to Storage Spaces pools and Cluster Shared Volumes are exposed.",
+	).Default(strconv.FormatBool(c.config.EnableStorageInfo)).BoolVar(&c.config.EnableStorageInfo)
+
+	app.Flag(
+		"collector.physical_disk.latency-sample-interval",
+		"Interval of sampling the transfer latency of the disks between scrapes, e.g. 1s. 0 disables sampling.",
+	).Default(c.config.LatencySampleInterval.String()).DurationVar(&c.config.LatencySampleInterval)
+
 	app.Action(func(*kingpin.ParseContext) error {
 		var err error
 
@@ -125,10 +154,16 @@ func (c *Collector) GetName() string {
 }
 
 func (c *Collector) Close() error {
+	c.latencySampler.Close()
+	c.latencySampler = nil
+	c.latencyPerfDataCollector.Close()
+
 	return nil
 }
 
-func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
 	c.requestsQueued = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, Name, "requests_queued"),
 		"The number of requests queued to the disk (PhysicalDisk.CurrentDiskQueueLength)",
@@ -136,6 +171,27 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		nil,
 	)
 
+	c.avgRequestsQueue = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "avg_requests_queued"),
+		"Average number of read and write requests that were queued for the selected disk during the sample interval (PhysicalDisk.AvgDiskQueueLength)",
+		[]string{"disk"},
+		nil,
+	)
+
+	c.avgReadQueue = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "avg_read_requests_queued"),
+		"Average number of read requests that were queued for the selected disk during the sample interval (PhysicalDisk.AvgDiskReadQueueLength)",
+		[]string{"disk"},
+		nil,
+	)
+
+	c.avgWriteQueue = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "avg_write_requests_queued"),
+		"Average number of write requests that were queued for the selected disk during the sample interval (PhysicalDisk.AvgDiskWriteQueueLength)",
+		[]string{"disk"},
+		nil,
+	)
+
 	c.readBytesTotal = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, Name, "read_bytes_total"),
 		"The number of bytes transferred from the disk during read operations (PhysicalDisk.DiskReadBytesPerSec)",
@@ -213,19 +269,60 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		nil,
 	)
 
+	c.storageInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "storage_info"),
+		"Links the physical disk to the Storage Spaces pool and the Cluster Shared Volume it belongs to, if any",
+		[]string{"disk", "storage_pool", "cluster_shared_volume"},
+		nil,
+	)
+
 	var err error
 
-	c.perfDataCollector, err = pdh.NewCollector[perfDataCounterValues](logger.With(slog.String("collector", Name)), pdh.CounterTypeRaw, "PhysicalDisk", pdh.InstancesAll)
+	c.perfDataCollector, err = pdh.NewCollector[perfDataCounterValues](c.logger, pdh.CounterTypeRaw, "PhysicalDisk", pdh.InstancesAll)
 	if err != nil {
 		return fmt.Errorf("failed to create PhysicalDisk collector: %w", err)
 	}
 
+	if c.config.EnableStorageInfo {
+		if miSession == nil {
+			return errors.New("miSession is nil")
+		}
+
+		c.miSession = miSession
+	}
+
+	if c.config.LatencySampleInterval > 0 && c.latencySampler == nil {
+		if err := c.buildLatencySampler(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
 func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if err := c.collectPDH(ch); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.config.EnableStorageInfo {
+		if err := c.collectStorageInfo(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed to collect storage info metrics: %w", err))
+		}
+	}
+
+	if c.latencySampler != nil {
+		c.latencySampler.Collect(ch, c.latencySampled)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectPDH(ch chan<- prometheus.Metric) error {
 	err := c.perfDataCollector.Collect(&c.perfDataObject)
 	if err != nil {
 		return fmt.Errorf("failed to collect PhysicalDisk metrics: %w", err)
@@ -248,6 +345,27 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 			disk_number,
 		)
 
+		ch <- prometheus.MustNewConstMetric(
+			c.avgRequestsQueue,
+			prometheus.GaugeValue,
+			data.AvgDiskQueueLength*pdh.TicksToSecondScaleFactor,
+			disk_number,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.avgReadQueue,
+			prometheus.GaugeValue,
+			data.AvgDiskReadQueueLength*pdh.TicksToSecondScaleFactor,
+			disk_number,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.avgWriteQueue,
+			prometheus.GaugeValue,
+			data.AvgDiskWriteQueueLength*pdh.TicksToSecondScaleFactor,
+			disk_number,
+		)
+
 		ch <- prometheus.MustNewConstMetric(
 			c.readBytesTotal,
 			prometheus.CounterValue,
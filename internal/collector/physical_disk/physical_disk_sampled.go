@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package physical_disk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/sampler"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+//nolint:gochecknoglobals
+var latencySampleBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+type latencyCounterValues struct {
+	Name string
+
+	AvgDiskSecPerTransfer float64 `perfdata:"Avg. Disk sec/Transfer"`
+}
+
+// buildLatencySampler samples the average transfer latency of the disks with a separate query of formatted values,
+// so each sample is the average latency of the I/Os completed since the previous sample.
+func (c *Collector) buildLatencySampler() error {
+	c.latencySampled = sampler.NewDescs(
+		prometheus.BuildFQName(types.Namespace, Name, "transfer_latency_sampled_seconds"),
+		"Average time of the disk transfers, sampled every --collector.physical_disk.latency-sample-interval (PhysicalDisk.AvgDiskSecPerTransfer)",
+		[]string{"disk"},
+	)
+
+	var err error
+
+	c.latencyPerfDataCollector, err = pdh.NewCollector[latencyCounterValues](c.logger, pdh.CounterTypeFormatted, "PhysicalDisk", pdh.InstancesAll)
+	if err != nil {
+		return fmt.Errorf("failed to create PhysicalDisk collector: %w", err)
+	}
+
+	var perfDataObject []latencyCounterValues
+
+	c.latencySampler, err = sampler.New(c.logger, sampler.Options{
+		Interval: c.config.LatencySampleInterval,
+		Buckets:  latencySampleBuckets,
+	}, func() ([]sampler.Sample, error) {
+		if err := c.latencyPerfDataCollector.Collect(&perfDataObject); err != nil {
+			return nil, fmt.Errorf("failed to collect PhysicalDisk metrics: %w", err)
+		}
+
+		samples := make([]sampler.Sample, 0, len(perfDataObject))
+
+		for _, data := range perfDataObject {
+			if c.config.DiskExclude.MatchString(data.Name) ||
+				!c.config.DiskInclude.MatchString(data.Name) {
+				continue
+			}
+
+			diskNumber, _, _ := strings.Cut(data.Name, " ")
+
+			samples = append(samples, sampler.Sample{LabelValues: []string{diskNumber}, Value: data.AvgDiskSecPerTransfer})
+		}
+
+		return samples, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start disk latency sampler: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package physical_disk
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type storagePool struct {
+	ObjectID     string `mi:"ObjectId"`
+	FriendlyName string `mi:"FriendlyName"`
+}
+
+type storagePoolPhysicalDisk struct {
+	DeviceID string `mi:"DeviceId"`
+}
+
+type clusterSharedVolumeResource struct {
+	Name string `mi:"Name"`
+}
+
+type clusterDisk struct {
+	Number uint32 `mi:"Number"`
+}
+
+type diskStorage struct {
+	storagePool         string
+	clusterSharedVolume string
+}
+
+//nolint:gochecknoglobals
+var (
+	storagePoolQuery         = utils.Must(mi.NewQuery("SELECT ObjectId, FriendlyName FROM MSFT_StoragePool WHERE IsPrimordial = FALSE"))
+	clusterSharedVolumeQuery = utils.Must(mi.NewQuery("SELECT Name FROM MSCluster_Resource WHERE Type = 'Physical Disk' AND IsClusterSharedVolume = TRUE"))
+
+	// wqlObjectPathEscaper escapes a key value used inside a WQL object path.
+	wqlObjectPathEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// collectStorageInfo links physical disk numbers to Storage Spaces pools and Cluster Shared Volumes.
+// Both sources are optional; hosts without Storage Spaces or failover clustering only report the disks
+// that could be linked.
+func (c *Collector) collectStorageInfo(ch chan<- prometheus.Metric) error {
+	disks := make(map[string]diskStorage)
+
+	if err := c.lookupStoragePools(disks); err != nil {
+		return err
+	}
+
+	if err := c.lookupClusterSharedVolumes(disks); err != nil {
+		return err
+	}
+
+	for diskNumber, storage := range disks {
+		if c.config.DiskExclude.MatchString(diskNumber) ||
+			!c.config.DiskInclude.MatchString(diskNumber) {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.storageInfo,
+			prometheus.GaugeValue,
+			1.0,
+			diskNumber,
+			storage.storagePool,
+			storage.clusterSharedVolume,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) lookupStoragePools(disks map[string]diskStorage) error {
+	var pools []storagePool
+	if err := c.miSession.Query(&pools, mi.NamespaceRootWindowsStorage, storagePoolQuery); err != nil {
+		if errors.Is(err, mi.MI_RESULT_INVALID_NAMESPACE) {
+			return nil
+		}
+
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	for _, pool := range pools {
+		query, err := mi.NewQuery(fmt.Sprintf(
+			`ASSOCIATORS OF {MSFT_StoragePool.ObjectId="%s"} WHERE ResultClass = MSFT_PhysicalDisk`,
+			wqlObjectPathEscaper.Replace(pool.ObjectID),
+		))
+		if err != nil {
+			return fmt.Errorf("failed to create WMI query: %w", err)
+		}
+
+		var physicalDisks []storagePoolPhysicalDisk
+		if err := c.miSession.Query(&physicalDisks, mi.NamespaceRootWindowsStorage, query); err != nil {
+			c.logger.Debug("failed to query physical disks of storage pool",
+				slog.String("storage_pool", pool.FriendlyName),
+				slog.Any("err", err),
+			)
+
+			continue
+		}
+
+		for _, physicalDisk := range physicalDisks {
+			storage := disks[physicalDisk.DeviceID]
+			storage.storagePool = pool.FriendlyName
+			disks[physicalDisk.DeviceID] = storage
+		}
+	}
+
+	return nil
+}
+
+func (c *Collector) lookupClusterSharedVolumes(disks map[string]diskStorage) error {
+	var resources []clusterSharedVolumeResource
+	if err := c.miSession.Query(&resources, mi.NamespaceRootMSCluster, clusterSharedVolumeQuery); err != nil {
+		if errors.Is(err, mi.MI_RESULT_INVALID_NAMESPACE) {
+			return nil
+		}
+
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	for _, resource := range resources {
+		query, err := mi.NewQuery(fmt.Sprintf(
+			`ASSOCIATORS OF {MSCluster_Resource.Name="%s"} WHERE ResultClass = MSCluster_Disk`,
+			wqlObjectPathEscaper.Replace(resource.Name),
+		))
+		if err != nil {
+			return fmt.Errorf("failed to create WMI query: %w", err)
+		}
+
+		var clusterDisks []clusterDisk
+		if err := c.miSession.Query(&clusterDisks, mi.NamespaceRootMSCluster, query); err != nil {
+			c.logger.Debug("failed to query disks of cluster shared volume",
+				slog.String("cluster_shared_volume", resource.Name),
+				slog.Any("err", err),
+			)
+
+			continue
+		}
+
+		for _, disk := range clusterDisks {
+			diskNumber := strconv.FormatUint(uint64(disk.Number), 10)
+
+			storage := disks[diskNumber]
+			storage.clusterSharedVolume = resource.Name
+			disks[diskNumber] = storage
+		}
+	}
+
+	return nil
+}
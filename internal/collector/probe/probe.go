@@ -0,0 +1,371 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	Name = "probe"
+
+	moduleTCP     = "tcp"
+	moduleHTTP    = "http"
+	moduleService = "service"
+)
+
+type Config struct {
+	TCPTargets     []string      `yaml:"tcp-targets"`
+	HTTPTargets    []string      `yaml:"http-targets"`
+	ServiceTargets []string      `yaml:"service-targets"`
+	Timeout        time.Duration `yaml:"timeout"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	TCPTargets:     []string{},
+	HTTPTargets:    []string{},
+	ServiceTargets: []string{},
+	Timeout:        5 * time.Second,
+}
+
+// A Collector runs local TCP, HTTP and service checks on each scrape.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	httpClient *http.Client
+
+	success        *prometheus.Desc
+	duration       *prometheus.Desc
+	httpStatusCode *prometheus.Desc
+}
+
+type probeResult struct {
+	module   string
+	target   string
+	success  bool
+	duration time.Duration
+
+	// httpStatusCode is only set for HTTP probes which received a response.
+	httpStatusCode int
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.TCPTargets == nil {
+		config.TCPTargets = ConfigDefaults.TCPTargets
+	}
+
+	if config.HTTPTargets == nil {
+		config.HTTPTargets = ConfigDefaults.HTTPTargets
+	}
+
+	if config.ServiceTargets == nil {
+		config.ServiceTargets = ConfigDefaults.ServiceTargets
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = ConfigDefaults.Timeout
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	var tcpTargets, httpTargets, serviceTargets string
+
+	app.Flag(
+		"collector.probe.tcp-targets",
+		"Comma-separated list of host:port addresses to check with a TCP connect.",
+	).Default("").StringVar(&tcpTargets)
+
+	app.Flag(
+		"collector.probe.http-targets",
+		"Comma-separated list of URLs to check with a HTTP GET request. Any 2xx or 3xx response is considered successful.",
+	).Default("").StringVar(&httpTargets)
+
+	app.Flag(
+		"collector.probe.service-targets",
+		"Comma-separated list of service names to check for responsiveness to control requests of the service control manager.",
+	).Default("").StringVar(&serviceTargets)
+
+	app.Flag(
+		"collector.probe.timeout",
+		"Timeout of each check.",
+	).Default(ConfigDefaults.Timeout.String()).DurationVar(&c.config.Timeout)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config.TCPTargets = splitTargets(tcpTargets)
+		c.config.HTTPTargets = splitTargets(httpTargets)
+		c.config.ServiceTargets = splitTargets(serviceTargets)
+
+		return nil
+	})
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if c.httpClient != nil {
+		c.httpClient.CloseIdleConnections()
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.success = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "success"),
+		"Whether the check was successful",
+		[]string{"module", "target"},
+		nil,
+	)
+	c.duration = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "duration_seconds"),
+		"Duration of the check in seconds",
+		[]string{"module", "target"},
+		nil,
+	)
+	c.httpStatusCode = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "http_status_code"),
+		"Response HTTP status code of the HTTP check",
+		[]string{"target"},
+		nil,
+	)
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return errors.New("unexpected type of http.DefaultTransport")
+	}
+
+	transport = transport.Clone()
+	// The checks target local endpoints, they must not be sent through a proxy.
+	transport.Proxy = nil
+	transport.DisableKeepAlives = true
+
+	c.httpClient = &http.Client{
+		Transport: transport,
+		Timeout:   c.config.Timeout,
+	}
+
+	if len(c.config.TCPTargets)+len(c.config.HTTPTargets)+len(c.config.ServiceTargets) == 0 {
+		c.logger.Warn("no probe targets configured")
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make([]probeResult, 0, len(c.config.TCPTargets)+len(c.config.HTTPTargets)+len(c.config.ServiceTargets))
+	)
+
+	run := func(module, target string, probe func(ctx context.Context, target string) (probeResult, error)) {
+		wg.Go(func() {
+			start := time.Now()
+			result, err := probe(ctx, target)
+			result.module = module
+			result.target = target
+			result.duration = time.Since(start)
+			result.success = err == nil
+
+			if err != nil {
+				c.logger.Debug("check failed",
+					slog.String("module", module),
+					slog.String("target", target),
+					slog.Any("err", err),
+				)
+			}
+
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		})
+	}
+
+	for _, target := range c.config.TCPTargets {
+		run(moduleTCP, target, probeTCP)
+	}
+
+	for _, target := range c.config.HTTPTargets {
+		run(moduleHTTP, target, c.probeHTTP)
+	}
+
+	for _, target := range c.config.ServiceTargets {
+		run(moduleService, target, probeService)
+	}
+
+	wg.Wait()
+
+	for _, result := range results {
+		ch <- prometheus.MustNewConstMetric(
+			c.success,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(result.success),
+			result.module,
+			result.target,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.duration,
+			prometheus.GaugeValue,
+			result.duration.Seconds(),
+			result.module,
+			result.target,
+		)
+
+		if result.module == moduleHTTP && result.httpStatusCode != 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.httpStatusCode,
+				prometheus.GaugeValue,
+				float64(result.httpStatusCode),
+				result.target,
+			)
+		}
+	}
+
+	return nil
+}
+
+func probeTCP(ctx context.Context, target string) (probeResult, error) {
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", target)
+	if err != nil {
+		return probeResult{}, err
+	}
+
+	return probeResult{}, conn.Close()
+}
+
+func (c *Collector) probeHTTP(ctx context.Context, target string) (probeResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return probeResult{}, err
+	}
+
+	req.Header.Set("User-Agent", "windows_exporter")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return probeResult{}, err
+	}
+
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	result := probeResult{httpStatusCode: resp.StatusCode}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return result, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return result, nil
+}
+
+// probeService sends a SERVICE_CONTROL_INTERROGATE request to the service.
+// The service control manager forwards the request to the service, which has to
+// respond within its control handler. A hung service fails with ERROR_SERVICE_REQUEST_TIMEOUT,
+// a stopped service with ERROR_SERVICE_NOT_ACTIVE.
+func probeService(_ context.Context, target string) (probeResult, error) {
+	scm, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_CONNECT)
+	if err != nil {
+		return probeResult{}, fmt.Errorf("failed to open scm: %w", err)
+	}
+
+	defer func() {
+		_ = windows.CloseServiceHandle(scm)
+	}()
+
+	serviceName, err := windows.UTF16PtrFromString(target)
+	if err != nil {
+		return probeResult{}, err
+	}
+
+	service, err := windows.OpenService(scm, serviceName, windows.SERVICE_INTERROGATE)
+	if err != nil {
+		return probeResult{}, fmt.Errorf("failed to open service: %w", err)
+	}
+
+	defer func() {
+		_ = windows.CloseServiceHandle(service)
+	}()
+
+	var status windows.SERVICE_STATUS
+
+	if err := windows.ControlService(service, windows.SERVICE_CONTROL_INTERROGATE, &status); err != nil {
+		return probeResult{}, fmt.Errorf("failed to interrogate service: %w", err)
+	}
+
+	return probeResult{}, nil
+}
+
+// splitTargets splits a comma-separated list of targets and drops empty entries.
+func splitTargets(targets string) []string {
+	result := make([]string, 0)
+
+	for target := range strings.SplitSeq(targets, ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			result = append(result, target)
+		}
+	}
+
+	return result
+}
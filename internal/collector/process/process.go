@@ -26,6 +26,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/alecthomas/kingpin/v2"
@@ -46,8 +47,22 @@ type Config struct {
 	EnableWorkerProcess bool           `yaml:"iis"`
 	EnableCMDLine       bool           `yaml:"cmdline"`
 	CounterVersion      uint8          `yaml:"counter-version"`
+	Aggregate           bool           `yaml:"aggregate"`
+	CommandLineInclude  *regexp.Regexp `yaml:"cmdline-include"`
+	CommandLineExclude  *regexp.Regexp `yaml:"cmdline-exclude"`
+	OwnerInclude        *regexp.Regexp `yaml:"owner-include"`
+	OwnerExclude        *regexp.Regexp `yaml:"owner-exclude"`
+	Groups              []Group        `yaml:"groups"`
+	ETW                 bool           `yaml:"etw"`
+	ShortLivedThreshold time.Duration  `yaml:"short-lived-threshold"`
 }
 
+// regExpAnyOrEmpty is the default of the command line and owner filters. Unlike types.RegExpAny, it matches
+// the empty command line and owner of processes that can't be opened.
+//
+//nolint:gochecknoglobals
+var regExpAnyOrEmpty = regexp.MustCompile("^(?:.*)$")
+
 //nolint:gochecknoglobals
 var ConfigDefaults = Config{
 	ProcessInclude:      types.RegExpAny,
@@ -55,6 +70,14 @@ var ConfigDefaults = Config{
 	EnableWorkerProcess: false,
 	EnableCMDLine:       true,
 	CounterVersion:      0,
+	Aggregate:           false,
+	CommandLineInclude:  regExpAnyOrEmpty,
+	CommandLineExclude:  types.RegExpEmpty,
+	OwnerInclude:        regExpAnyOrEmpty,
+	OwnerExclude:        types.RegExpEmpty,
+	Groups:              make([]Group, 0),
+	ETW:                 false,
+	ShortLivedThreshold: 10 * time.Second,
 }
 
 type Collector struct {
@@ -71,6 +94,8 @@ type Collector struct {
 
 	lookupCache sync.Map
 
+	kernelProcessEvents *kernelProcessEvents
+
 	mu sync.RWMutex
 
 	info              *prometheus.Desc
@@ -89,6 +114,16 @@ type Collector struct {
 	workingSet        *prometheus.Desc
 	workingSetPeak    *prometheus.Desc
 	workingSetPrivate *prometheus.Desc
+
+	workingSetHistogram   *prometheus.Desc
+	privateBytesHistogram *prometheus.Desc
+
+	group groupDescs
+
+	etwStartsTotal     *prometheus.Desc
+	etwExitsTotal      *prometheus.Desc
+	etwShortLivedTotal *prometheus.Desc
+	etwLifetime        *prometheus.Desc
 }
 
 func New(config *Config) *Collector {
@@ -104,6 +139,30 @@ func New(config *Config) *Collector {
 		config.ProcessInclude = ConfigDefaults.ProcessInclude
 	}
 
+	if config.CommandLineInclude == nil {
+		config.CommandLineInclude = ConfigDefaults.CommandLineInclude
+	}
+
+	if config.CommandLineExclude == nil {
+		config.CommandLineExclude = ConfigDefaults.CommandLineExclude
+	}
+
+	if config.OwnerInclude == nil {
+		config.OwnerInclude = ConfigDefaults.OwnerInclude
+	}
+
+	if config.OwnerExclude == nil {
+		config.OwnerExclude = ConfigDefaults.OwnerExclude
+	}
+
+	if config.Groups == nil {
+		config.Groups = ConfigDefaults.Groups
+	}
+
+	if config.ShortLivedThreshold == 0 {
+		config.ShortLivedThreshold = ConfigDefaults.ShortLivedThreshold
+	}
+
 	c := &Collector{
 		config: *config,
 	}
@@ -116,7 +175,12 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 		config: ConfigDefaults,
 	}
 
-	var processExclude, processInclude string
+	var (
+		processExclude, processInclude         string
+		commandLineExclude, commandLineInclude string
+		ownerExclude, ownerInclude             string
+		groups                                 string
+	)
 
 	app.Flag(
 		"collector.process.exclude",
@@ -143,6 +207,46 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 		"Version of the process collector to use. 1 for Process V1, 2 for Process V2. Defaults to 0 which will use the latest version available.",
 	).Default(strconv.FormatUint(uint64(c.config.CounterVersion), 10)).Uint8Var(&c.config.CounterVersion)
 
+	app.Flag(
+		"collector.process.aggregate",
+		"If enabled, only histograms of the working set and private bytes across all matching processes are exposed instead of per-process metrics.",
+	).Default(strconv.FormatBool(c.config.Aggregate)).BoolVar(&c.config.Aggregate)
+
+	app.Flag(
+		"collector.process.cmdline-exclude",
+		"Regexp of command lines of processes to exclude. The command line must both match cmdline-include and not match cmdline-exclude to be included.",
+	).Default("").StringVar(&commandLineExclude)
+
+	app.Flag(
+		"collector.process.cmdline-include",
+		"Regexp of command lines of processes to include. The command line must both match cmdline-include and not match cmdline-exclude to be included.",
+	).Default(".*").StringVar(&commandLineInclude)
+
+	app.Flag(
+		"collector.process.owner-exclude",
+		"Regexp of owners of processes to exclude, as in the owner label of windows_process_info. The owner must both match owner-include and not match owner-exclude to be included.",
+	).Default("").StringVar(&ownerExclude)
+
+	app.Flag(
+		"collector.process.owner-include",
+		"Regexp of owners of processes to include, as in the owner label of windows_process_info. The owner must both match owner-include and not match owner-exclude to be included.",
+	).Default(".*").StringVar(&ownerInclude)
+
+	app.Flag(
+		"collector.process.groups",
+		"Comma-separated list of groups as <name>=<regexp>. The metrics of all processes whose command line matches the regexp are summed up under the group label instead of per-process metrics. The name may reference submatches, e.g. $1.",
+	).Default("").StringVar(&groups)
+
+	app.Flag(
+		"collector.process.etw",
+		"If enabled, process starts and exits are counted by process name from the Microsoft-Windows-Kernel-Process ETW provider.",
+	).Default(strconv.FormatBool(c.config.ETW)).BoolVar(&c.config.ETW)
+
+	app.Flag(
+		"collector.process.short-lived-threshold",
+		"Processes that exit within this duration after their start are counted as short-lived. Requires collector.process.etw.",
+	).Default(c.config.ShortLivedThreshold.String()).DurationVar(&c.config.ShortLivedThreshold)
+
 	app.Action(func(*kingpin.ParseContext) error {
 		var err error
 
@@ -156,6 +260,31 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 			return fmt.Errorf("collector.process.include: %w", err)
 		}
 
+		c.config.CommandLineExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", commandLineExclude))
+		if err != nil {
+			return fmt.Errorf("collector.process.cmdline-exclude: %w", err)
+		}
+
+		c.config.CommandLineInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", commandLineInclude))
+		if err != nil {
+			return fmt.Errorf("collector.process.cmdline-include: %w", err)
+		}
+
+		c.config.OwnerExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", ownerExclude))
+		if err != nil {
+			return fmt.Errorf("collector.process.owner-exclude: %w", err)
+		}
+
+		c.config.OwnerInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", ownerInclude))
+		if err != nil {
+			return fmt.Errorf("collector.process.owner-include: %w", err)
+		}
+
+		c.config.Groups, err = parseGroups(groups)
+		if err != nil {
+			return fmt.Errorf("collector.process.groups: %w", err)
+		}
+
 		return nil
 	})
 
@@ -177,12 +306,24 @@ func (c *Collector) Close() error {
 		c.workerCh = nil
 	}
 
+	if c.kernelProcessEvents != nil {
+		if err := c.kernelProcessEvents.subscription.Close(); err != nil {
+			return fmt.Errorf("failed to close ETW subscription: %w", err)
+		}
+
+		c.kernelProcessEvents = nil
+	}
+
 	return nil
 }
 
 func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
 	c.logger = logger.With(slog.String("collector", Name))
 
+	if c.config.Aggregate && len(c.config.Groups) > 0 {
+		return errors.New("collector.process.aggregate and collector.process.groups can't be combined")
+	}
+
 	var err error
 
 	switch c.config.CounterVersion {
@@ -215,10 +356,25 @@ func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
 	c.mu = sync.RWMutex{}
 	c.lookupCache = sync.Map{}
 
-	if c.config.ProcessInclude.String() == "^(?:.*)$" && c.config.ProcessExclude.String() == "^(?:)$" {
+	if !c.config.Aggregate && len(c.config.Groups) == 0 && c.config.ProcessInclude.String() == "^(?:.*)$" && c.config.ProcessExclude.String() == "^(?:)$" {
 		logger.Warn("No filters specified for process collector. This will generate a very large number of metrics!")
 	}
 
+	c.workingSetHistogram = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "aggregate_working_set_bytes"),
+		"Distribution of the working set size of all matching processes. Only exposed if collector.process.aggregate is enabled.",
+		nil,
+		nil,
+	)
+	c.privateBytesHistogram = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "aggregate_private_bytes"),
+		"Distribution of the private commit of all matching processes. Only exposed if collector.process.aggregate is enabled.",
+		nil,
+		nil,
+	)
+
+	c.group = newGroupDescs()
+
 	c.info = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, Name, "info"),
 		"Process information.",
@@ -317,6 +473,37 @@ func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
 		nil,
 	)
 
+	c.etwStartsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "etw_starts_total"),
+		"Number of process starts since windows_exporter started, from the Microsoft-Windows-Kernel-Process ETW provider.",
+		[]string{"process"},
+		nil,
+	)
+	c.etwExitsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "etw_exits_total"),
+		"Number of process exits since windows_exporter started, from the Microsoft-Windows-Kernel-Process ETW provider.",
+		[]string{"process"},
+		nil,
+	)
+	c.etwShortLivedTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "etw_short_lived_total"),
+		"Number of processes that exited within collector.process.short-lived-threshold after their start, from the Microsoft-Windows-Kernel-Process ETW provider.",
+		[]string{"process"},
+		nil,
+	)
+	c.etwLifetime = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "etw_lifetime_seconds"),
+		"Distribution of the lifetime of the processes that exited since windows_exporter started, from the Microsoft-Windows-Kernel-Process ETW provider.",
+		nil,
+		nil,
+	)
+
+	if c.config.ETW && c.kernelProcessEvents == nil {
+		if err := c.subscribeKernelProcess(); err != nil {
+			return fmt.Errorf("failed to subscribe to process events: %w", err)
+		}
+	}
+
 	if c.config.EnableWorkerProcess {
 		if miSession == nil {
 			return errors.New("miSession is nil")
@@ -343,6 +530,10 @@ func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
 }
 
 func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	if c.kernelProcessEvents != nil {
+		c.collectETW(ch)
+	}
+
 	return c.collect(ch)
 }
 
@@ -424,7 +615,7 @@ func (c *Collector) getExtendedProcessInformation(hProcess windows.Handle) (stri
 
 	var cmdLine string
 
-	if c.config.EnableCMDLine {
+	if c.readCommandLine() {
 		cmdLineUTF16 := make([]uint16, processParameters.CommandLine.Length)
 
 		err = windows.ReadProcessMemory(hProcess,
@@ -513,3 +704,27 @@ func (c *Collector) openProcess(pid uint32) (windows.Handle, bool, error) {
 
 	return hProcess, false, nil
 }
+
+// readCommandLine reports whether the command line of the processes is required,
+// either for the cmdline label of windows_process_info or to filter and group the processes.
+func (c *Collector) readCommandLine() bool {
+	return c.config.EnableCMDLine || len(c.config.Groups) > 0 || !unfiltered(c.config.CommandLineInclude, c.config.CommandLineExclude)
+}
+
+// filtersByProcessInformation reports whether processes are filtered by command line or owner,
+// which requires to open each process.
+func (c *Collector) filtersByProcessInformation() bool {
+	return !unfiltered(c.config.CommandLineInclude, c.config.CommandLineExclude) || !unfiltered(c.config.OwnerInclude, c.config.OwnerExclude)
+}
+
+// matchProcessInformation reports whether the command line and the owner of a process match the filters.
+func (c *Collector) matchProcessInformation(cmdLine, owner string) bool {
+	return c.config.CommandLineInclude.MatchString(cmdLine) && !c.config.CommandLineExclude.MatchString(cmdLine) &&
+		c.config.OwnerInclude.MatchString(owner) && !c.config.OwnerExclude.MatchString(owner)
+}
+
+// unfiltered reports whether include and exclude are the defaults, which match all values.
+func unfiltered(include, exclude *regexp.Regexp) bool {
+	return include.String() == regExpAnyOrEmpty.String() &&
+		(exclude.String() == types.RegExpEmpty.String() || exclude.String() == "^(?:)$")
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package process
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// memoryBuckets are the upper bounds of the memory histograms, from 1 MiB to 64 GiB.
+//
+//nolint:gochecknoglobals
+var memoryBuckets = prometheus.ExponentialBuckets(1<<20, 4, 9)
+
+// histogram accumulates observations in the format expected by prometheus.MustNewConstHistogram.
+type histogram struct {
+	count   uint64
+	sum     float64
+	buckets map[float64]uint64
+}
+
+// processHistograms accumulates the processes. The workers observe processes concurrently
+// if processes are filtered by command line or owner.
+type processHistograms struct {
+	mu           sync.Mutex
+	workingSet   histogram
+	privateBytes histogram
+}
+
+func newHistogram() histogram {
+	buckets := make(map[float64]uint64, len(memoryBuckets))
+	for _, bucket := range memoryBuckets {
+		buckets[bucket] = 0
+	}
+
+	return histogram{buckets: buckets}
+}
+
+func (h *histogram) observe(value float64) {
+	h.count++
+	h.sum += value
+
+	// Buckets are cumulative.
+	for _, bucket := range memoryBuckets {
+		if value <= bucket {
+			h.buckets[bucket]++
+		}
+	}
+}
+
+func newProcessHistograms() *processHistograms {
+	return &processHistograms{
+		workingSet:   newHistogram(),
+		privateBytes: newHistogram(),
+	}
+}
+
+func (h *processHistograms) observe(process perfDataCounterValues) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.workingSet.observe(process.WorkingSet)
+	h.privateBytes.observe(process.PrivateBytes)
+}
+
+// collectHistograms exposes the memory distribution of all matching processes
+// without any per-process series.
+func (c *Collector) collectHistograms(ch chan<- prometheus.Metric, histograms *processHistograms) {
+	ch <- prometheus.MustNewConstHistogram(
+		c.workingSetHistogram,
+		histograms.workingSet.count,
+		histograms.workingSet.sum,
+		histograms.workingSet.buckets,
+	)
+
+	ch <- prometheus.MustNewConstHistogram(
+		c.privateBytesHistogram,
+		histograms.privateBytes.count,
+		histograms.privateBytes.sum,
+		histograms.privateBytes.buckets,
+	)
+}
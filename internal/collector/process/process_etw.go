@@ -0,0 +1,281 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package process
+
+import (
+	"encoding/binary"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/etw"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+// Event IDs of the Microsoft-Windows-Kernel-Process provider.
+const (
+	kernelProcessEventStart = 1
+	kernelProcessEventStop  = 2
+
+	kernelProcessKeywordProcess = 0x10
+
+	// maxTrackedProcesses bounds the image names of running processes, in case stop events are lost.
+	maxTrackedProcesses = 65536
+)
+
+//nolint:gochecknoglobals
+var (
+	kernelProcessProvider = etw.Provider{
+		GUID:            windows.GUID{Data1: 0x22fb2cd6, Data2: 0x0e7b, Data3: 0x422b, Data4: [8]byte{0xa0, 0xc7, 0x2f, 0xad, 0x1f, 0xd0, 0xe7, 0x16}},
+		Level:           etw.LevelInformation,
+		MatchAnyKeyword: kernelProcessKeywordProcess,
+		EventIDs:        []uint16{kernelProcessEventStart, kernelProcessEventStop},
+	}
+
+	// lifetimeBuckets are the upper bounds of windows_process_etw_lifetime_seconds, from 100ms to 1 day.
+	lifetimeBuckets = []float64{0.1, 1, 10, 60, 600, 3600, 86400}
+)
+
+// processCounts are the events of the processes of an image since windows_exporter started.
+type processCounts struct {
+	starts, exits, shortLived uint64
+}
+
+// kernelProcessEvents counts the process start and stop events of the Microsoft-Windows-Kernel-Process provider
+// by image name.
+type kernelProcessEvents struct {
+	subscription *etw.Subscription
+	logger       *slog.Logger
+	include      func(name string) bool
+	shortLived   time.Duration
+
+	mu     sync.Mutex
+	counts map[string]*processCounts
+	// images are the image names of the running processes by process ID. The stop event only
+	// contains the image file name of the kernel, which is truncated to 15 characters.
+	images         map[uint32]string
+	lifetimeCounts []uint64
+	lifetimeCount  uint64
+	lifetimeSum    float64
+}
+
+func (c *Collector) subscribeKernelProcess() error {
+	events := &kernelProcessEvents{
+		logger: c.logger,
+		include: func(name string) bool {
+			return c.config.ProcessInclude.MatchString(name) && !c.config.ProcessExclude.MatchString(name)
+		},
+		shortLived:     c.config.ShortLivedThreshold,
+		counts:         make(map[string]*processCounts),
+		images:         make(map[uint32]string),
+		lifetimeCounts: make([]uint64, len(lifetimeBuckets)),
+	}
+
+	subscription, err := etw.Subscribe(c.logger, kernelProcessProvider, events.handleEvent)
+	if err != nil {
+		return err
+	}
+
+	events.subscription = subscription
+	c.kernelProcessEvents = events
+
+	return nil
+}
+
+func (e *kernelProcessEvents) handleEvent(record *etw.EventRecord) {
+	pid, err := record.Property("ProcessID")
+	if err != nil || len(pid) < 4 {
+		e.logger.Debug("failed to get process ID of process event",
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	processID := binary.LittleEndian.Uint32(pid)
+
+	switch record.EventHeader.EventDescriptor.Id {
+	case kernelProcessEventStart:
+		image, err := record.Property("ImageName")
+		if err != nil {
+			e.logger.Debug("failed to get image name of process start event",
+				slog.Any("err", err),
+			)
+
+			return
+		}
+
+		e.start(processID, imageName(windows.UTF16ToString(bytesToUTF16(image))))
+	case kernelProcessEventStop:
+		image, err := record.Property("ImageName")
+		if err != nil {
+			e.logger.Debug("failed to get image name of process stop event",
+				slog.Any("err", err),
+			)
+
+			return
+		}
+
+		var lifetime time.Duration
+
+		createTime, errCreate := record.Property("CreateTime")
+		exitTime, errExit := record.Property("ExitTime")
+
+		if errCreate == nil && errExit == nil && len(createTime) >= 8 && len(exitTime) >= 8 {
+			lifetime = filetimeToTime(exitTime).Sub(filetimeToTime(createTime))
+		}
+
+		e.stop(processID, imageName(strings.TrimRight(string(image), "\x00")), lifetime)
+	}
+}
+
+func (e *kernelProcessEvents) start(processID uint32, name string) {
+	if !e.include(name) {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.images) >= maxTrackedProcesses {
+		clear(e.images)
+	}
+
+	e.images[processID] = name
+	e.countsOf(name).starts++
+}
+
+// stop counts the exit of a process. lifetime is 0 if the event doesn't contain the create and exit time.
+func (e *kernelProcessEvents) stop(processID uint32, name string, lifetime time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if started, ok := e.images[processID]; ok {
+		name = started
+
+		delete(e.images, processID)
+	} else if !e.include(name) {
+		return
+	}
+
+	counts := e.countsOf(name)
+	counts.exits++
+
+	if lifetime <= 0 {
+		return
+	}
+
+	if lifetime < e.shortLived {
+		counts.shortLived++
+	}
+
+	for i, bucket := range lifetimeBuckets {
+		if lifetime.Seconds() <= bucket {
+			e.lifetimeCounts[i]++
+		}
+	}
+
+	e.lifetimeCount++
+	e.lifetimeSum += lifetime.Seconds()
+}
+
+func (e *kernelProcessEvents) countsOf(name string) *processCounts {
+	counts, ok := e.counts[name]
+	if !ok {
+		counts = &processCounts{}
+		e.counts[name] = counts
+	}
+
+	return counts
+}
+
+func (c *Collector) collectETW(ch chan<- prometheus.Metric) {
+	e := c.kernelProcessEvents
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for name, counts := range e.counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.etwStartsTotal,
+			prometheus.CounterValue,
+			float64(counts.starts),
+			name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.etwExitsTotal,
+			prometheus.CounterValue,
+			float64(counts.exits),
+			name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.etwShortLivedTotal,
+			prometheus.CounterValue,
+			float64(counts.shortLived),
+			name,
+		)
+	}
+
+	buckets := make(map[float64]uint64, len(lifetimeBuckets))
+	for i, bucket := range lifetimeBuckets {
+		buckets[bucket] = e.lifetimeCounts[i]
+	}
+
+	ch <- prometheus.MustNewConstHistogram(
+		c.etwLifetime,
+		e.lifetimeCount,
+		e.lifetimeSum,
+		buckets,
+	)
+}
+
+// imageName returns the process name of an image path as in the process label of the Process counters,
+// e.g. svchost for \Device\HarddiskVolume3\Windows\System32\svchost.exe.
+func imageName(image string) string {
+	if i := strings.LastIndexAny(image, `\/`); i >= 0 {
+		image = image[i+1:]
+	}
+
+	if len(image) > 4 && strings.EqualFold(image[len(image)-4:], ".exe") {
+		image = image[:len(image)-4]
+	}
+
+	return image
+}
+
+func bytesToUTF16(b []byte) []uint16 {
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+
+	return u
+}
+
+func filetimeToTime(b []byte) time.Time {
+	ft := windows.Filetime{
+		LowDateTime:  binary.LittleEndian.Uint32(b),
+		HighDateTime: binary.LittleEndian.Uint32(b[4:]),
+	}
+
+	return time.Unix(0, ft.Nanoseconds())
+}
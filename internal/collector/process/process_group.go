@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package process
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Group sums the metrics of all processes whose command line matches CommandLine.
+// Name may reference submatches of CommandLine, e.g. $1, to create a group per distinct value.
+type Group struct {
+	Name        string
+	CommandLine *regexp.Regexp
+}
+
+// UnmarshalText parses a group in the format <name>=<regexp>.
+func (g *Group) UnmarshalText(text []byte) error {
+	name, expr, ok := strings.Cut(string(text), "=")
+	if !ok || name == "" {
+		return fmt.Errorf("invalid group %q, expected <name>=<regexp>", text)
+	}
+
+	commandLine, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", expr))
+	if err != nil {
+		return fmt.Errorf("invalid group %q: %w", text, err)
+	}
+
+	g.Name = name
+	g.CommandLine = commandLine
+
+	return nil
+}
+
+// parseGroups parses a comma-separated list of groups.
+func parseGroups(s string) ([]Group, error) {
+	groups := make([]Group, 0)
+
+	for value := range strings.SplitSeq(s, ",") {
+		if value == "" {
+			continue
+		}
+
+		var group Group
+		if err := group.UnmarshalText([]byte(value)); err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// groupName returns the name of the first group whose regexp matches the command line.
+func (c *Collector) groupName(cmdLine string) (string, bool) {
+	for _, group := range c.config.Groups {
+		match := group.CommandLine.FindStringSubmatchIndex(cmdLine)
+		if match == nil {
+			continue
+		}
+
+		name := string(group.CommandLine.ExpandString(nil, group.Name, cmdLine, match))
+		if name == "" {
+			continue
+		}
+
+		return name, true
+	}
+
+	return "", false
+}
+
+// processGroupValues are the sums of the counter values of the processes of a group.
+type processGroupValues struct {
+	processes int
+	perfDataCounterValues
+}
+
+// processGroups accumulates the processes of the groups. The workers add processes concurrently.
+type processGroups struct {
+	mu     sync.Mutex
+	groups map[string]*processGroupValues
+}
+
+func newProcessGroups() *processGroups {
+	return &processGroups{
+		groups: make(map[string]*processGroupValues),
+	}
+}
+
+func (g *processGroups) add(name string, process perfDataCounterValues) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	values, ok := g.groups[name]
+	if !ok {
+		values = &processGroupValues{}
+		g.groups[name] = values
+	}
+
+	values.processes++
+	values.HandleCount += process.HandleCount
+	values.PercentPrivilegedTime += process.PercentPrivilegedTime
+	values.PercentUserTime += process.PercentUserTime
+	values.IoOtherBytesPerSec += process.IoOtherBytesPerSec
+	values.IoOtherOperationsPerSec += process.IoOtherOperationsPerSec
+	values.IoReadBytesPerSec += process.IoReadBytesPerSec
+	values.IoReadOperationsPerSec += process.IoReadOperationsPerSec
+	values.IoWriteBytesPerSec += process.IoWriteBytesPerSec
+	values.IoWriteOperationsPerSec += process.IoWriteOperationsPerSec
+	values.PageFaultsPerSec += process.PageFaultsPerSec
+	values.PageFileBytes += process.PageFileBytes
+	values.PoolNonPagedBytes += process.PoolNonPagedBytes
+	values.PoolPagedBytes += process.PoolPagedBytes
+	values.PrivateBytes += process.PrivateBytes
+	values.ThreadCount += process.ThreadCount
+	values.VirtualBytes += process.VirtualBytes
+	values.WorkingSet += process.WorkingSet
+	values.WorkingSetPrivate += process.WorkingSetPrivate
+}
+
+// groupDescs are the metrics of the groups, which are labeled with the group instead of the process.
+type groupDescs struct {
+	processes         *prometheus.Desc
+	cpuTimeTotal      *prometheus.Desc
+	handleCount       *prometheus.Desc
+	ioBytesTotal      *prometheus.Desc
+	ioOperationsTotal *prometheus.Desc
+	pageFaultsTotal   *prometheus.Desc
+	pageFileBytes     *prometheus.Desc
+	poolBytes         *prometheus.Desc
+	privateBytes      *prometheus.Desc
+	threadCount       *prometheus.Desc
+	virtualBytes      *prometheus.Desc
+	workingSet        *prometheus.Desc
+	workingSetPrivate *prometheus.Desc
+}
+
+func newGroupDescs() groupDescs {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "group_"+name),
+			help,
+			append([]string{"group"}, labels...),
+			nil,
+		)
+	}
+
+	return groupDescs{
+		processes:         desc("processes", "Number of processes of the group."),
+		cpuTimeTotal:      desc("cpu_time_total", "Elapsed time that all of the threads of the processes of the group used the processor by mode (privileged, user).", "mode"),
+		handleCount:       desc("handles", "Total number of handles the processes of the group have open."),
+		ioBytesTotal:      desc("io_bytes_total", "Bytes issued to I/O operations by the processes of the group in different modes (read, write, other).", "mode"),
+		ioOperationsTotal: desc("io_operations_total", "I/O operations issued by the processes of the group in different modes (read, write, other).", "mode"),
+		pageFaultsTotal:   desc("page_faults_total", "Page faults by the threads executing in the processes of the group."),
+		pageFileBytes:     desc("page_file_bytes", "Current number of bytes the processes of the group have used in the paging file(s)."),
+		poolBytes:         desc("pool_bytes", "Bytes of the processes of the group in the paged or nonpaged pool.", "pool"),
+		privateBytes:      desc("private_bytes", "Current number of bytes the processes of the group have allocated that cannot be shared with other processes."),
+		threadCount:       desc("threads", "Number of threads currently active in the processes of the group."),
+		virtualBytes:      desc("virtual_bytes", "Current size, in bytes, of the virtual address space that the processes of the group are using."),
+		workingSet:        desc("working_set_bytes", "Sum of the working sets of the processes of the group."),
+		workingSetPrivate: desc("working_set_private_bytes", "Sum of the private working sets of the processes of the group."),
+	}
+}
+
+// collectGroups exposes the sums of the processes of each group.
+func (c *Collector) collectGroups(ch chan<- prometheus.Metric, groups *processGroups) {
+	groups.mu.Lock()
+	defer groups.mu.Unlock()
+
+	for name, values := range groups.groups {
+		ch <- prometheus.MustNewConstMetric(c.group.processes, prometheus.GaugeValue, float64(values.processes), name)
+		ch <- prometheus.MustNewConstMetric(c.group.cpuTimeTotal, prometheus.CounterValue, values.PercentPrivilegedTime, name, "privileged")
+		ch <- prometheus.MustNewConstMetric(c.group.cpuTimeTotal, prometheus.CounterValue, values.PercentUserTime, name, "user")
+		ch <- prometheus.MustNewConstMetric(c.group.handleCount, prometheus.GaugeValue, values.HandleCount, name)
+		ch <- prometheus.MustNewConstMetric(c.group.ioBytesTotal, prometheus.CounterValue, values.IoOtherBytesPerSec, name, "other")
+		ch <- prometheus.MustNewConstMetric(c.group.ioOperationsTotal, prometheus.CounterValue, values.IoOtherOperationsPerSec, name, "other")
+		ch <- prometheus.MustNewConstMetric(c.group.ioBytesTotal, prometheus.CounterValue, values.IoReadBytesPerSec, name, "read")
+		ch <- prometheus.MustNewConstMetric(c.group.ioOperationsTotal, prometheus.CounterValue, values.IoReadOperationsPerSec, name, "read")
+		ch <- prometheus.MustNewConstMetric(c.group.ioBytesTotal, prometheus.CounterValue, values.IoWriteBytesPerSec, name, "write")
+		ch <- prometheus.MustNewConstMetric(c.group.ioOperationsTotal, prometheus.CounterValue, values.IoWriteOperationsPerSec, name, "write")
+		ch <- prometheus.MustNewConstMetric(c.group.pageFaultsTotal, prometheus.CounterValue, values.PageFaultsPerSec, name)
+		ch <- prometheus.MustNewConstMetric(c.group.pageFileBytes, prometheus.GaugeValue, values.PageFileBytes, name)
+		ch <- prometheus.MustNewConstMetric(c.group.poolBytes, prometheus.GaugeValue, values.PoolNonPagedBytes, name, "nonpaged")
+		ch <- prometheus.MustNewConstMetric(c.group.poolBytes, prometheus.GaugeValue, values.PoolPagedBytes, name, "paged")
+		ch <- prometheus.MustNewConstMetric(c.group.privateBytes, prometheus.GaugeValue, values.PrivateBytes, name)
+		ch <- prometheus.MustNewConstMetric(c.group.threadCount, prometheus.GaugeValue, values.ThreadCount, name)
+		ch <- prometheus.MustNewConstMetric(c.group.virtualBytes, prometheus.GaugeValue, values.VirtualBytes, name)
+		ch <- prometheus.MustNewConstMetric(c.group.workingSet, prometheus.GaugeValue, values.WorkingSet, name)
+		ch <- prometheus.MustNewConstMetric(c.group.workingSetPrivate, prometheus.GaugeValue, values.WorkingSetPrivate, name)
+	}
+}
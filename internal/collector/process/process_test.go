@@ -18,6 +18,7 @@
 package process_test
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/alecthomas/kingpin/v2"
@@ -37,3 +38,17 @@ func BenchmarkProcessCollector(b *testing.B) {
 func TestCollector(t *testing.T) {
 	testutils.TestCollector(t, process.New, nil)
 }
+
+func TestCollectorAggregate(t *testing.T) {
+	testutils.TestCollector(t, process.New, &process.Config{
+		Aggregate: true,
+	})
+}
+
+func TestCollectorGroups(t *testing.T) {
+	testutils.TestCollector(t, process.New, &process.Config{
+		Groups: []process.Group{
+			{Name: "exe_$1", CommandLine: regexp.MustCompile(`^.*\\([^\\]+?\.exe).*$`)},
+		},
+	})
+}
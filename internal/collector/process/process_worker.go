@@ -37,6 +37,8 @@ type processWorkerRequest struct {
 	performanceCounterValues perfDataCounterValues
 	waitGroup                *sync.WaitGroup
 	workerProcesses          []WorkerProcess
+	histograms               *processHistograms
+	groups                   *processGroups
 }
 
 func (c *Collector) collect(ch chan<- prometheus.Metric) error {
@@ -48,7 +50,7 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) error {
 	err = nil
 
 	var workerProcesses []WorkerProcess
-	if c.config.EnableWorkerProcess {
+	if c.config.EnableWorkerProcess && !c.config.Aggregate && len(c.config.Groups) == 0 {
 		if err = c.miSession.Query(&workerProcesses, mi.NamespaceRootWebAdministration, c.workerProcessMIQueryQuery); err != nil {
 			err = fmt.Errorf("WMI query for collector.process.iis failed: %w", err)
 		}
@@ -56,6 +58,19 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) error {
 
 	wg := &sync.WaitGroup{}
 
+	var histograms *processHistograms
+	if c.config.Aggregate {
+		histograms = newProcessHistograms()
+	}
+
+	var groups *processGroups
+	if len(c.config.Groups) > 0 {
+		groups = newProcessGroups()
+	}
+
+	// Filters by command line or owner require to open each process, which the workers do.
+	filtersByProcessInformation := c.filtersByProcessInformation()
+
 	for _, process := range c.perfDataObject {
 		// Duplicate processes are suffixed #, and an index number. Remove those.
 		name, _, _ := strings.Cut(process.Name, ":") // Process V2
@@ -77,6 +92,12 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) error {
 			continue
 		}
 
+		if histograms != nil && !filtersByProcessInformation {
+			histograms.observe(process)
+
+			continue
+		}
+
 		wg.Add(1)
 
 		c.workerCh <- processWorkerRequest{
@@ -85,11 +106,21 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) error {
 			performanceCounterValues: process,
 			workerProcesses:          workerProcesses,
 			waitGroup:                wg,
+			histograms:               histograms,
+			groups:                   groups,
 		}
 	}
 
 	wg.Wait()
 
+	if histograms != nil {
+		c.collectHistograms(ch, histograms)
+	}
+
+	if groups != nil {
+		c.collectGroups(ch, groups)
+	}
+
 	return err
 }
 
@@ -135,6 +166,28 @@ func (c *Collector) collectWorker() {
 				)
 			}
 
+			if !c.matchProcessInformation(cmdLine, processOwner) {
+				return
+			}
+
+			if req.histograms != nil {
+				req.histograms.observe(data)
+
+				return
+			}
+
+			if req.groups != nil {
+				if group, ok := c.groupName(cmdLine); ok {
+					req.groups.add(group, data)
+				}
+
+				return
+			}
+
+			if !c.config.EnableCMDLine {
+				cmdLine = ""
+			}
+
 			pidString := strconv.FormatUint(pid, 10)
 
 			ch <- prometheus.MustNewConstMetric(
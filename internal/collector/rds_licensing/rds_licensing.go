@@ -0,0 +1,320 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package rds_licensing
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "rds_licensing"
+
+	gracePeriodRegistryKey = `SYSTEM\CurrentControlSet\Control\Terminal Server\RCM\GracePeriod`
+	gracePeriodValuePrefix = "L$RTMTIMEBOMB"
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var (
+	keyPackTypes = map[uint32]string{
+		0: "unknown",
+		1: "retail",
+		2: "volume",
+		3: "concurrent",
+		4: "temporary",
+		5: "open",
+		6: "not_supported",
+	}
+
+	productTypes = map[uint32]string{
+		0: "per_device",
+		1: "per_user",
+		2: "not_valid",
+	}
+)
+
+// A Collector is a Prometheus Collector for Remote Desktop Licensing metrics.
+// Key pack metrics are read from WMI Win32_TSLicenseKeyPack on license servers,
+// the grace period from the registry on Remote Desktop Session Hosts.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	miSession *mi.Session
+	miQuery   mi.Query
+
+	keyPacksEnabled bool
+
+	licenses              *prometheus.Desc
+	keyPackExpiration     *prometheus.Desc
+	keyPackInfo           *prometheus.Desc
+	gracePeriodExpiration *prometheus.Desc
+	gracePeriodRemaining  *prometheus.Desc
+}
+
+type win32TSLicenseKeyPack struct {
+	KeyPackID         uint32    `mi:"KeyPackId"`
+	KeyPackType       uint32    `mi:"KeyPackType"`
+	ProductType       uint32    `mi:"ProductType"`
+	ProductVersion    string    `mi:"ProductVersion"`
+	TypeAndModel      string    `mi:"TypeAndModel"`
+	TotalLicenses     uint32    `mi:"TotalLicenses"`
+	AvailableLicenses uint32    `mi:"AvailableLicenses"`
+	IssuedLicenses    uint32    `mi:"IssuedLicenses"`
+	ExpirationDate    time.Time `mi:"ExpirationDate"`
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.licenses = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "licenses"),
+		"Number of client access licenses of the key pack by state",
+		[]string{"key_pack_id", "state"},
+		nil,
+	)
+	c.keyPackInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "key_pack_info"),
+		"Information about the license key pack installed on the license server",
+		[]string{"key_pack_id", "key_pack_type", "product_type", "product_version", "type_and_model"},
+		nil,
+	)
+	c.keyPackExpiration = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "key_pack_expiration_timestamp_seconds"),
+		"Expiration date of the license key pack as unix timestamp",
+		[]string{"key_pack_id"},
+		nil,
+	)
+	c.gracePeriodExpiration = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "grace_period_expiration_timestamp_seconds"),
+		"End of the licensing grace period of the Remote Desktop Session Host as unix timestamp",
+		nil,
+		nil,
+	)
+	c.gracePeriodRemaining = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "grace_period_remaining_seconds"),
+		"Remaining time of the licensing grace period of the Remote Desktop Session Host",
+		nil,
+		nil,
+	)
+
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	miQuery, err := mi.NewQuery("SELECT KeyPackId, KeyPackType, ProductType, ProductVersion, TypeAndModel, TotalLicenses, AvailableLicenses, IssuedLicenses, ExpirationDate FROM Win32_TSLicenseKeyPack")
+	if err != nil {
+		return fmt.Errorf("failed to create WMI query: %w", err)
+	}
+
+	c.miQuery = miQuery
+	c.miSession = miSession
+
+	var dst []win32TSLicenseKeyPack
+	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, c.miQuery); err != nil {
+		if !errors.Is(err, mi.MI_RESULT_INVALID_CLASS) {
+			return fmt.Errorf("WMI query failed: %w", err)
+		}
+
+		c.logger.Debug("Remote Desktop Licensing role is not installed, skipping key pack metrics")
+	} else {
+		c.keyPacksEnabled = true
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if c.keyPacksEnabled {
+		if err := c.collectKeyPacks(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting license key pack metrics: %w", err))
+		}
+	}
+
+	if err := c.collectGracePeriod(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting grace period metrics: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectKeyPacks(ch chan<- prometheus.Metric) error {
+	var dst []win32TSLicenseKeyPack
+	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, c.miQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	for _, keyPack := range dst {
+		keyPackID := strconv.FormatUint(uint64(keyPack.KeyPackID), 10)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.keyPackInfo,
+			prometheus.GaugeValue,
+			1.0,
+			keyPackID,
+			keyPackTypes[keyPack.KeyPackType],
+			productTypes[keyPack.ProductType],
+			keyPack.ProductVersion,
+			keyPack.TypeAndModel,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.licenses,
+			prometheus.GaugeValue,
+			float64(keyPack.TotalLicenses),
+			keyPackID,
+			"total",
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.licenses,
+			prometheus.GaugeValue,
+			float64(keyPack.AvailableLicenses),
+			keyPackID,
+			"available",
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.licenses,
+			prometheus.GaugeValue,
+			float64(keyPack.IssuedLicenses),
+			keyPackID,
+			"issued",
+		)
+
+		if !keyPack.ExpirationDate.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				c.keyPackExpiration,
+				prometheus.GaugeValue,
+				float64(keyPack.ExpirationDate.Unix()),
+				keyPackID,
+			)
+		}
+	}
+
+	return nil
+}
+
+// collectGracePeriod reads the grace period end from the L$RTMTIMEBOMB registry value.
+// The value only exists on Remote Desktop Session Hosts and contains a FILETIME.
+func (c *Collector) collectGracePeriod(ch chan<- prometheus.Metric) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, gracePeriodRegistryKey, registry.QUERY_VALUE|registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil
+		}
+
+		if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+			c.logger.Debug("access to the grace period registry key denied, windows_exporter must run as LocalSystem",
+				slog.Any("err", err),
+			)
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to open registry key %s: %w", gracePeriodRegistryKey, err)
+	}
+
+	defer key.Close()
+
+	valueNames, err := key.ReadValueNames(0)
+	if err != nil {
+		return fmt.Errorf("failed to read registry value names of %s: %w", gracePeriodRegistryKey, err)
+	}
+
+	for _, valueName := range valueNames {
+		if !strings.HasPrefix(valueName, gracePeriodValuePrefix) {
+			continue
+		}
+
+		value, _, err := key.GetBinaryValue(valueName)
+		if err != nil {
+			return fmt.Errorf("failed to read registry value %s: %w", valueName, err)
+		}
+
+		if len(value) < 8 {
+			return fmt.Errorf("registry value %s is too short: %d bytes", valueName, len(value))
+		}
+
+		expiration := time.Unix(0, (&windows.Filetime{
+			LowDateTime:  binary.LittleEndian.Uint32(value[0:4]),
+			HighDateTime: binary.LittleEndian.Uint32(value[4:8]),
+		}).Nanoseconds())
+
+		ch <- prometheus.MustNewConstMetric(
+			c.gracePeriodExpiration,
+			prometheus.GaugeValue,
+			float64(expiration.Unix()),
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.gracePeriodRemaining,
+			prometheus.GaugeValue,
+			max(time.Until(expiration).Seconds(), 0),
+		)
+
+		return nil
+	}
+
+	return nil
+}
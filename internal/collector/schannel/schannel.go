@@ -0,0 +1,427 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package schannel
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "schannel"
+
+	systemChannel = "System"
+
+	// eventIDHandshakeCompleted is logged by Schannel for each successful handshake, if success events are enabled
+	// in the EventLogging value of schannelKey.
+	eventIDHandshakeCompleted = 36880
+
+	schannelKey = `SYSTEM\CurrentControlSet\Control\SecurityProviders\SCHANNEL`
+	// eventLoggingSuccess is the flag of the EventLogging value that enables the logging of successful handshakes.
+	eventLoggingSuccess = 0x4
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var (
+	handshakeQuery = fmt.Sprintf(
+		"*[System[Provider[@Name='Schannel'] and EventID=%d and EventRecordID > %%d]]",
+		eventIDHandshakeCompleted,
+	)
+
+	// renderValuePaths are the event properties rendered for each handshake event.
+	// The order must match the value* indices.
+	renderValuePaths = []string{
+		"Event/System/EventRecordID",
+		"Event/EventData/Data[@Name='Type']",
+		"Event/EventData/Data[@Name='Protocol']",
+		"Event/EventData/Data[@Name='CipherSuite']",
+	}
+
+	// protocols are the names of the SP_PROT_* flags of the negotiated protocol, see schannel.h.
+	protocols = map[uint64]string{
+		0x0000_0004: "SSL 2.0",
+		0x0000_0008: "SSL 2.0",
+		0x0000_0010: "SSL 3.0",
+		0x0000_0020: "SSL 3.0",
+		0x0000_0040: "TLS 1.0",
+		0x0000_0080: "TLS 1.0",
+		0x0000_0100: "TLS 1.1",
+		0x0000_0200: "TLS 1.1",
+		0x0000_0400: "TLS 1.2",
+		0x0000_0800: "TLS 1.2",
+		0x0000_1000: "TLS 1.3",
+		0x0000_2000: "TLS 1.3",
+		0x0001_0000: "DTLS 1.0",
+		0x0002_0000: "DTLS 1.0",
+		0x0004_0000: "DTLS 1.2",
+		0x0008_0000: "DTLS 1.2",
+	}
+)
+
+const (
+	valueEventRecordID = iota
+	valueType
+	valueProtocol
+	valueCipherSuite
+)
+
+type handshake struct {
+	side        string
+	protocol    string
+	cipherSuite string
+}
+
+// A Collector is a Prometheus Collector for the TLS handshakes of Schannel, the TLS implementation of Windows.
+// The handshake counters are read from the Security System-Wide Statistics performance counters,
+// the protocol versions and cipher suites from the handshake events of the System event log.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	perfDataCollector *pdh.Collector
+	perfDataObject    []perfDataCounterValues
+
+	renderContext wevtapi.EVT_HANDLE
+
+	// mu protects the handshake counters and lastRecordID against concurrent scrapes.
+	mu           sync.Mutex
+	lastRecordID uint64
+	handshakes   map[handshake]float64
+
+	handshakesTotal            *prometheus.Desc
+	sessionCacheEntries        *prometheus.Desc
+	sessionCacheActiveEntries  *prometheus.Desc
+	protocolHandshakesTotal    *prometheus.Desc
+	cipherSuiteHandshakesTotal *prometheus.Desc
+	successEventLogging        *prometheus.Desc
+}
+
+type perfDataCounterValues struct {
+	// Security System-Wide Statistics
+	SSLClientSideFullHandshakes      float64 `perfdata:"SSL Client-Side Full Handshakes"`
+	SSLClientSideReconnectHandshakes float64 `perfdata:"SSL Client-Side Reconnect Handshakes"`
+	SSLServerSideFullHandshakes      float64 `perfdata:"SSL Server-Side Full Handshakes"`
+	SSLServerSideReconnectHandshakes float64 `perfdata:"SSL Server-Side Reconnect Handshakes"`
+	SessionCacheEntries              float64 `perfdata:"Schannel Session Cache Entries"`
+	ActiveSessionCacheEntries        float64 `perfdata:"Active Schannel Session Cache Entries"`
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if c.perfDataCollector != nil {
+		c.perfDataCollector.Close()
+	}
+
+	if c.renderContext != 0 {
+		return wevtapi.EvtClose(c.renderContext)
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.handshakesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "handshakes_total"),
+		"Number of TLS handshakes by side (client, server) and type (full, reconnect). Reconnect handshakes resume a session of the session cache",
+		[]string{"side", "type"},
+		nil,
+	)
+	c.sessionCacheEntries = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "session_cache_entries"),
+		"Number of entries of the Schannel session cache",
+		nil,
+		nil,
+	)
+	c.sessionCacheActiveEntries = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "session_cache_active_entries"),
+		"Number of entries of the Schannel session cache that are in use",
+		nil,
+		nil,
+	)
+	c.protocolHandshakesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "protocol_handshakes_total"),
+		"Number of successful TLS handshakes by side and negotiated protocol version, from Schannel event 36880",
+		[]string{"side", "protocol"},
+		nil,
+	)
+	c.cipherSuiteHandshakesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cipher_suite_handshakes_total"),
+		"Number of successful TLS handshakes by side and negotiated cipher suite, from Schannel event 36880",
+		[]string{"side", "cipher_suite"},
+		nil,
+	)
+	c.successEventLogging = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "success_event_logging_enabled"),
+		"Whether Schannel logs successful handshakes (EventLogging flag 0x4), which is required for the protocol and cipher suite metrics",
+		nil,
+		nil,
+	)
+
+	c.handshakes = make(map[handshake]float64)
+
+	var err error
+
+	c.lastRecordID, err = wevtapi.LatestEventRecordID(systemChannel)
+	if err != nil {
+		return fmt.Errorf("failed to read latest event of %s: %w", systemChannel, err)
+	}
+
+	c.renderContext, err = wevtapi.EvtCreateRenderContext(renderValuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	c.perfDataCollector, err = pdh.NewCollector[perfDataCounterValues](c.logger, pdh.CounterTypeRaw, "Security System-Wide Statistics", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create Security System-Wide Statistics collector: %w", err)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	return errors.Join(
+		c.collectPerfData(ch),
+		c.collectHandshakeEvents(ch),
+	)
+}
+
+func (c *Collector) collectPerfData(ch chan<- prometheus.Metric) error {
+	err := c.perfDataCollector.Collect(&c.perfDataObject)
+	if err != nil {
+		return fmt.Errorf("failed to collect Security System-Wide Statistics metrics: %w", err)
+	}
+
+	if len(c.perfDataObject) == 0 {
+		return fmt.Errorf("failed to collect Security System-Wide Statistics metrics: %w", types.ErrNoDataUnexpected)
+	}
+
+	data := c.perfDataObject[0]
+
+	for _, handshakes := range []struct {
+		side, handshakeType string
+		value               float64
+	}{
+		{"client", "full", data.SSLClientSideFullHandshakes},
+		{"client", "reconnect", data.SSLClientSideReconnectHandshakes},
+		{"server", "full", data.SSLServerSideFullHandshakes},
+		{"server", "reconnect", data.SSLServerSideReconnectHandshakes},
+	} {
+		ch <- prometheus.MustNewConstMetric(
+			c.handshakesTotal,
+			prometheus.CounterValue,
+			handshakes.value,
+			handshakes.side,
+			handshakes.handshakeType,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.sessionCacheEntries,
+		prometheus.GaugeValue,
+		data.SessionCacheEntries,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.sessionCacheActiveEntries,
+		prometheus.GaugeValue,
+		data.ActiveSessionCacheEntries,
+	)
+
+	return nil
+}
+
+// collectHandshakeEvents counts the handshake events since windows_exporter started. Schannel logs them only
+// if success events are enabled, which is reported as windows_schannel_success_event_logging_enabled.
+func (c *Collector) collectHandshakeEvents(ch chan<- prometheus.Metric) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(
+		c.successEventLogging,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(successEventLoggingEnabled()),
+	)
+
+	query := fmt.Sprintf(handshakeQuery, c.lastRecordID)
+
+	if err := wevtapi.QueryValues(systemChannel, query, c.renderContext, c.handleEvent); err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", systemChannel, err)
+	}
+
+	protocolCounts := make(map[[2]string]float64)
+	cipherSuiteCounts := make(map[[2]string]float64)
+
+	for h, count := range c.handshakes {
+		protocolCounts[[2]string{h.side, h.protocol}] += count
+		cipherSuiteCounts[[2]string{h.side, h.cipherSuite}] += count
+	}
+
+	for key, count := range protocolCounts {
+		ch <- prometheus.MustNewConstMetric(
+			c.protocolHandshakesTotal,
+			prometheus.CounterValue,
+			count,
+			key[0], key[1],
+		)
+	}
+
+	for key, count := range cipherSuiteCounts {
+		ch <- prometheus.MustNewConstMetric(
+			c.cipherSuiteHandshakesTotal,
+			prometheus.CounterValue,
+			count,
+			key[0], key[1],
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) handleEvent(values []any) {
+	if len(values) != len(renderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[valueEventRecordID].(uint64)
+	c.lastRecordID = max(c.lastRecordID, recordID)
+
+	side, _ := values[valueType].(string)
+
+	c.handshakes[handshake{
+		side:        normalizeSide(side),
+		protocol:    protocolName(values[valueProtocol]),
+		cipherSuite: cipherSuiteName(values[valueCipherSuite]),
+	}]++
+}
+
+func normalizeSide(side string) string {
+	side = strings.ToLower(strings.TrimSpace(side))
+	if side != "client" && side != "server" {
+		return "unknown"
+	}
+
+	return side
+}
+
+// protocolName returns the name of the negotiated protocol. Depending on the Windows version, the event contains
+// the name, e.g. "TLS 1.2", or the SP_PROT_* flag.
+func protocolName(value any) string {
+	switch protocol := value.(type) {
+	case string:
+		if hex, ok := strings.CutPrefix(strings.ToLower(protocol), "0x"); ok {
+			if flag, err := strconv.ParseUint(hex, 16, 32); err == nil {
+				return protocolName(flag)
+			}
+		}
+
+		if protocol == "" {
+			return "unknown"
+		}
+
+		return protocol
+	case uint64:
+		if name, ok := protocols[protocol]; ok {
+			return name
+		}
+
+		return fmt.Sprintf("0x%X", protocol)
+	default:
+		return "unknown"
+	}
+}
+
+// cipherSuiteName returns the IANA number of the negotiated cipher suite, e.g. 0xC030.
+func cipherSuiteName(value any) string {
+	switch cipherSuite := value.(type) {
+	case string:
+		if cipherSuite == "" {
+			return "unknown"
+		}
+
+		if hex, ok := strings.CutPrefix(strings.ToLower(cipherSuite), "0x"); ok {
+			if number, err := strconv.ParseUint(hex, 16, 32); err == nil {
+				return fmt.Sprintf("0x%04X", number)
+			}
+		}
+
+		return cipherSuite
+	case uint64:
+		return fmt.Sprintf("0x%04X", cipherSuite)
+	default:
+		return "unknown"
+	}
+}
+
+// successEventLoggingEnabled reads the EventLogging value of Schannel. Its default is 1, which logs errors only.
+func successEventLoggingEnabled() bool {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, schannelKey, registry.QUERY_VALUE)
+	if err != nil {
+		return false
+	}
+
+	defer key.Close()
+
+	value, _, err := key.GetIntegerValue("EventLogging")
+	if err != nil {
+		return false
+	}
+
+	return value&eventLoggingSuccess != 0
+}
@@ -24,6 +24,7 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-ole/go-ole"
@@ -49,9 +50,11 @@ var ConfigDefaults = Config{
 type Collector struct {
 	config Config
 
-	lastResult *prometheus.Desc
-	missedRuns *prometheus.Desc
-	state      *prometheus.Desc
+	lastResult     *prometheus.Desc
+	lastResultCode *prometheus.Desc
+	lastRunTime    *prometheus.Desc
+	missedRuns     *prometheus.Desc
+	state          *prometheus.Desc
 }
 
 // TaskState ...
@@ -80,6 +83,7 @@ type ScheduledTask struct {
 	State           TaskState
 	MissedRunsCount float64
 	LastTaskResult  TaskResult
+	LastRunTime     time.Time
 }
 
 type ScheduledTasks []ScheduledTask
@@ -156,6 +160,20 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 		nil,
 	)
 
+	c.lastResultCode = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "last_result_code"),
+		"The result code that was returned the last time the registered task was run, e.g. the exit code of the action",
+		[]string{"task"},
+		nil,
+	)
+
+	c.lastRunTime = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "last_run_timestamp_seconds"),
+		"The time the registered task was last run as unix timestamp",
+		[]string{"task"},
+		nil,
+	)
+
 	c.missedRuns = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, Name, "missed_runs"),
 		"The number of times the registered task missed a scheduled run",
@@ -224,6 +242,22 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) error {
 			task.Path,
 		)
 
+		ch <- prometheus.MustNewConstMetric(
+			c.lastResultCode,
+			prometheus.GaugeValue,
+			float64(task.LastTaskResult),
+			task.Path,
+		)
+
+		if !task.LastRunTime.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				c.lastRunTime,
+				prometheus.GaugeValue,
+				float64(task.LastRunTime.Unix()),
+				task.Path,
+			)
+		}
+
 		ch <- prometheus.MustNewConstMetric(
 			c.missedRuns,
 			prometheus.GaugeValue,
@@ -409,6 +443,17 @@ func parseTask(task *ole.IDispatch) (ScheduledTask, error) {
 		}
 	}()
 
+	taskLastRunTimeVar, err := oleutil.GetProperty(task, "LastRunTime")
+	if err != nil {
+		return scheduledTask, err
+	}
+
+	defer func() {
+		if tempErr := taskLastRunTimeVar.Clear(); tempErr != nil {
+			err = tempErr
+		}
+	}()
+
 	scheduledTask.Name = taskNameVar.ToString()
 	scheduledTask.Path = strings.ReplaceAll(taskPathVar.ToString(), "\\", "/")
 
@@ -420,6 +465,16 @@ func parseTask(task *ole.IDispatch) (ScheduledTask, error) {
 	scheduledTask.MissedRunsCount = float64(taskNumberOfMissedRunsVar.Val)
 	scheduledTask.LastTaskResult = TaskResult(taskLastTaskResultVar.Val)
 
+	// LastRunTime is a DATE in local time, which go-ole returns with the UTC location.
+	// Tasks that have never run report 1899-12-30, which is before the unix epoch.
+	if lastRunTime, ok := taskLastRunTimeVar.Value().(time.Time); ok && lastRunTime.Year() >= 1970 {
+		scheduledTask.LastRunTime = time.Date(
+			lastRunTime.Year(), lastRunTime.Month(), lastRunTime.Day(),
+			lastRunTime.Hour(), lastRunTime.Minute(), lastRunTime.Second(), lastRunTime.Nanosecond(),
+			time.Local,
+		)
+	}
+
 	return scheduledTask, err
 }
 
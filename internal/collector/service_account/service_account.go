@@ -0,0 +1,620 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package service_account
+
+import (
+	"crypto/sha1" //nolint:gosec // the thumbprint of a certificate is its SHA-1 hash
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/netapi32"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "service_account"
+
+	servicesKey = `SYSTEM\CurrentControlSet\Services`
+
+	// serviceCertificatesKey contains a subkey for each service that has its own certificate store.
+	serviceCertificatesKey = `SOFTWARE\Microsoft\Cryptography\Services`
+
+	systemChannel = "System"
+
+	// eventIDLogonFailed is logged by the Service Control Manager if a service could not log on
+	// with the configured password.
+	eventIDLogonFailed = 7038
+
+	accountTypeSystem  = "system"
+	accountTypeVirtual = "virtual"
+	accountTypeLocal   = "local"
+	accountTypeDomain  = "domain"
+	accountTypeGMSA    = "gmsa"
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var (
+	logonFailedQuery = fmt.Sprintf(
+		"*[System[Provider[@Name='Service Control Manager'] and EventID=%d and EventRecordID > %%d]]",
+		eventIDLogonFailed,
+	)
+
+	// logonFailedValuePaths are the event properties rendered for each logon failure event.
+	// The order must match the value* indices.
+	logonFailedValuePaths = []string{
+		"Event/System/EventRecordID",
+		"Event/EventData/Data[@Name='param1']",
+		"Event/EventData/Data[@Name='param2']",
+	}
+
+	// gmsaStates are the state labels of the MSA_INFO_STATE values.
+	gmsaStates = []struct {
+		name  string
+		state uint32
+	}{
+		{"not_exist", netapi32.MsaInfoNotExist},
+		{"not_service", netapi32.MsaInfoNotService},
+		{"cannot_install", netapi32.MsaInfoCannotInstall},
+		{"can_install", netapi32.MsaInfoCanInstall},
+		{"installed", netapi32.MsaInfoInstalled},
+	}
+)
+
+const (
+	valueEventRecordID = iota
+	valueService
+	valueAccount
+)
+
+// serviceAccount is the account a service runs as.
+type serviceAccount struct {
+	// name is the account as configured for the service, e.g. CONTOSO\svc-sql or .\backup.
+	name        string
+	accountType string
+	// domain and user are the parts of the name, e.g. CONTOSO and svc-sql.
+	domain string
+	user   string
+}
+
+// passwordPolicy is the result of the maximum password age lookup of a server.
+type passwordPolicy struct {
+	maxAge time.Duration
+	err    error
+}
+
+// logonFailureKey identifies the counters of logon failures.
+type logonFailureKey struct {
+	service string
+	account string
+}
+
+// A Collector is a Prometheus Collector for the accounts Windows services run as.
+// It exports the password expiry of local and domain accounts, whether the computer can retrieve the password
+// of group managed service accounts, logon failures of services and the certificates of the service certificate stores.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	// mu protects the logon failure counters and the event log position against concurrent scrapes.
+	mu            sync.Mutex
+	renderContext wevtapi.EVT_HANDLE
+	lastRecordID  uint64
+	eventsEnabled bool
+	logonFailures map[logonFailureKey]float64
+
+	accountInfo             *prometheus.Desc
+	passwordNeverExpires    *prometheus.Desc
+	passwordExpiryTimestamp *prometheus.Desc
+	accountDisabled         *prometheus.Desc
+	gmsaState               *prometheus.Desc
+	logonFailuresTotal      *prometheus.Desc
+	certificateNotAfter     *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if c.renderContext != 0 {
+		return wevtapi.EvtClose(c.renderContext)
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.accountInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "info"),
+		"Account a service runs as. type is system, virtual, local, domain or gmsa",
+		[]string{"service", "account", "type"},
+		nil,
+	)
+	c.passwordNeverExpires = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "password_never_expires"),
+		"Whether the password of a local or domain service account never expires",
+		[]string{"account"},
+		nil,
+	)
+	c.passwordExpiryTimestamp = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "password_expiry_timestamp_seconds"),
+		"Time the password of a local or domain service account expires according to the maximum password age, in seconds since epoch",
+		[]string{"account"},
+		nil,
+	)
+	c.accountDisabled = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "disabled"),
+		"Whether a local or domain service account is disabled",
+		[]string{"account"},
+		nil,
+	)
+	c.gmsaState = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "gmsa_state"),
+		"State of a group managed service account on this computer. Only installed accounts can retrieve their password",
+		[]string{"account", "state"},
+		nil,
+	)
+	c.logonFailuresTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "logon_failures_total"),
+		"Number of times a service could not log on with the configured password since windows_exporter started",
+		[]string{"service", "account"},
+		nil,
+	)
+	c.certificateNotAfter = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "certificate_not_after_timestamp_seconds"),
+		"Time after which a certificate of the personal certificate store of a service is no longer valid, in seconds since epoch",
+		[]string{"service", "subject", "thumbprint"},
+		nil,
+	)
+
+	c.logonFailures = make(map[logonFailureKey]float64)
+
+	lastRecordID, err := wevtapi.LatestEventRecordID(systemChannel)
+	if err != nil {
+		c.logger.Warn("failed to read latest event of the System event log, service logon failures are not counted",
+			slog.Any("err", err),
+		)
+
+		return nil
+	}
+
+	c.lastRecordID = lastRecordID
+
+	c.renderContext, err = wevtapi.EvtCreateRenderContext(logonFailedValuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	c.eventsEnabled = true
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if err := c.collectAccounts(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting service accounts: %w", err))
+	}
+
+	if err := c.collectLogonFailures(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting service logon failures: %w", err))
+	}
+
+	if err := c.collectCertificates(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting service certificates: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectAccounts(ch chan<- prometheus.Metric) error {
+	computerName, err := windows.ComputerName()
+	if err != nil {
+		return fmt.Errorf("failed to get computer name: %w", err)
+	}
+
+	services, err := serviceAccounts(computerName)
+	if err != nil {
+		return err
+	}
+
+	// Several services often run as the same account, which is only queried once.
+	accounts := make(map[string]serviceAccount)
+
+	for service, account := range services {
+		ch <- prometheus.MustNewConstMetric(
+			c.accountInfo,
+			prometheus.GaugeValue,
+			1,
+			service, account.name, account.accountType,
+		)
+
+		if account.accountType == accountTypeSystem || account.accountType == accountTypeVirtual {
+			continue
+		}
+
+		// The account with the smallest name is kept, so the account label doesn't change between scrapes
+		// if services use different spellings of the same account.
+		key := strings.ToLower(account.domain + `\` + account.user)
+		if current, ok := accounts[key]; !ok || account.name < current.name {
+			accounts[key] = account
+		}
+	}
+
+	// The domain controller and the password policies are looked up lazily, since most hosts only have
+	// services running as built-in accounts.
+	var (
+		dcName     string
+		dcErr      error
+		dcLookedUp bool
+	)
+
+	policies := make(map[string]passwordPolicy)
+
+	for _, account := range accounts {
+		if account.accountType == accountTypeGMSA {
+			c.collectGMSA(ch, account)
+
+			continue
+		}
+
+		var serverName string
+
+		if account.accountType == accountTypeDomain {
+			if !dcLookedUp {
+				dcLookedUp = true
+
+				var info netapi32.DomainControllerInfo
+
+				info, dcErr = netapi32.DsGetDcName(netapi32.DS_RETURN_DNS_NAME)
+				dcName = `\\` + info.DomainControllerName
+			}
+
+			if dcErr != nil {
+				c.logger.Debug("failed to locate a domain controller",
+					slog.String("account", account.name),
+					slog.Any("err", dcErr),
+				)
+
+				continue
+			}
+
+			serverName = dcName
+		}
+
+		c.collectPassword(ch, account, serverName, policies)
+	}
+
+	return nil
+}
+
+// collectPassword sends the password expiry of a local or domain account, whose users are read from serverName.
+// policies caches the password policies by server.
+func (c *Collector) collectPassword(ch chan<- prometheus.Metric, account serviceAccount, serverName string, policies map[string]passwordPolicy) {
+	info, err := netapi32.GetUserInfo(serverName, account.user)
+	if err != nil {
+		c.logger.Debug("failed to get user info of service account",
+			slog.String("account", account.name),
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.accountDisabled,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(info.Flags&netapi32.UF_ACCOUNTDISABLE != 0),
+		account.name,
+	)
+
+	neverExpires := info.Flags&netapi32.UF_DONT_EXPIRE_PASSWD != 0
+
+	policy, ok := policies[serverName]
+	if !ok {
+		policy.maxAge, policy.err = netapi32.GetMaxPasswordAge(serverName)
+		policies[serverName] = policy
+	}
+
+	maxAge, err := policy.maxAge, policy.err
+	if err != nil {
+		c.logger.Debug("failed to get the maximum password age",
+			slog.String("server", serverName),
+			slog.Any("err", err),
+		)
+	} else if maxAge == 0 {
+		neverExpires = true
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.passwordNeverExpires,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(neverExpires),
+		account.name,
+	)
+
+	if neverExpires || err != nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.passwordExpiryTimestamp,
+		prometheus.GaugeValue,
+		float64(time.Now().Add(maxAge-info.PasswordAge).Unix()),
+		account.name,
+	)
+}
+
+func (c *Collector) collectGMSA(ch chan<- prometheus.Metric, account serviceAccount) {
+	state, err := netapi32.QueryServiceAccount(account.user)
+	if err != nil {
+		c.logger.Debug("failed to query group managed service account",
+			slog.String("account", account.name),
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	for _, s := range gmsaStates {
+		ch <- prometheus.MustNewConstMetric(
+			c.gmsaState,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(state == s.state),
+			account.name, s.name,
+		)
+	}
+}
+
+func (c *Collector) collectLogonFailures(ch chan<- prometheus.Metric) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var err error
+
+	if c.eventsEnabled {
+		query := fmt.Sprintf(logonFailedQuery, c.lastRecordID)
+
+		err = wevtapi.QueryValues(systemChannel, query, c.renderContext, func(values []any) {
+			if len(values) != len(logonFailedValuePaths) {
+				return
+			}
+
+			recordID, _ := values[valueEventRecordID].(uint64)
+			service, _ := values[valueService].(string)
+			account, _ := values[valueAccount].(string)
+
+			c.logonFailures[logonFailureKey{service: service, account: account}]++
+			c.lastRecordID = max(c.lastRecordID, recordID)
+		})
+	}
+
+	for key, count := range c.logonFailures {
+		ch <- prometheus.MustNewConstMetric(
+			c.logonFailuresTotal,
+			prometheus.CounterValue,
+			count,
+			key.service, key.account,
+		)
+	}
+
+	return err
+}
+
+func (c *Collector) collectCertificates(ch chan<- prometheus.Metric) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, serviceCertificatesKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to open %s: %w", serviceCertificatesKey, err)
+	}
+
+	defer key.Close()
+
+	services, err := key.ReadSubKeyNames(0)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", serviceCertificatesKey, err)
+	}
+
+	errs := make([]error, 0)
+
+	for _, service := range services {
+		if err := c.collectServiceStore(ch, service); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting certificates of service %s: %w", service, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// collectServiceStore sends the certificates of the personal certificate store of a service.
+func (c *Collector) collectServiceStore(ch chan<- prometheus.Metric, service string) error {
+	storeNamePtr, err := windows.UTF16PtrFromString(service + `\My`)
+	if err != nil {
+		return err
+	}
+
+	store, err := windows.CertOpenStore(
+		windows.CERT_STORE_PROV_SYSTEM,
+		0,
+		0,
+		windows.CERT_SYSTEM_STORE_SERVICES|windows.CERT_STORE_READONLY_FLAG|windows.CERT_STORE_OPEN_EXISTING_FLAG,
+		uintptr(unsafe.Pointer(storeNamePtr)),
+	)
+	if err != nil {
+		// Services may have other stores than the personal store.
+		if errors.Is(err, windows.ERROR_FILE_NOT_FOUND) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to open certificate store: %w", err)
+	}
+
+	defer windows.CertCloseStore(store, 0) //nolint:errcheck
+
+	var certContext *windows.CertContext
+
+	for {
+		// CertEnumCertificatesInStore frees the previous context.
+		certContext, err = windows.CertEnumCertificatesInStore(store, certContext)
+		if certContext == nil {
+			if errors.Is(err, windows.Errno(windows.CRYPT_E_NOT_FOUND)) {
+				return nil
+			}
+
+			return fmt.Errorf("failed to enumerate certificates: %w", err)
+		}
+
+		// The context is freed by the next call, so the certificate must not reference its memory.
+		encoded := slices.Clone(unsafe.Slice(certContext.EncodedCert, certContext.Length))
+
+		certificate, err := x509.ParseCertificate(encoded)
+		if err != nil {
+			c.logger.Debug("failed to parse certificate",
+				slog.String("service", service),
+				slog.Any("err", err),
+			)
+
+			continue
+		}
+
+		thumbprint := sha1.Sum(encoded) //nolint:gosec
+
+		ch <- prometheus.MustNewConstMetric(
+			c.certificateNotAfter,
+			prometheus.GaugeValue,
+			float64(certificate.NotAfter.Unix()),
+			service, certificate.Subject.String(), strings.ToUpper(hex.EncodeToString(thumbprint[:])),
+		)
+	}
+}
+
+// serviceAccounts returns the accounts of the Win32 services that are not disabled, by service name.
+func serviceAccounts(computerName string) (map[string]serviceAccount, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, servicesKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", servicesKey, err)
+	}
+
+	defer key.Close()
+
+	names, err := key.ReadSubKeyNames(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", servicesKey, err)
+	}
+
+	accounts := make(map[string]serviceAccount, len(names))
+
+	for _, name := range names {
+		serviceKey, err := registry.OpenKey(key, name, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+
+		serviceType, _, typeErr := serviceKey.GetIntegerValue("Type")
+		start, _, startErr := serviceKey.GetIntegerValue("Start")
+		objectName, _, nameErr := serviceKey.GetStringValue("ObjectName")
+
+		_ = serviceKey.Close()
+
+		// Drivers have no ObjectName or the driver object as ObjectName.
+		if typeErr != nil || startErr != nil || nameErr != nil ||
+			serviceType&(windows.SERVICE_WIN32_OWN_PROCESS|windows.SERVICE_WIN32_SHARE_PROCESS) == 0 ||
+			start == windows.SERVICE_DISABLED {
+			continue
+		}
+
+		accounts[name] = parseAccount(objectName, computerName)
+	}
+
+	return accounts, nil
+}
+
+// parseAccount classifies the ObjectName of a service, e.g. LocalSystem, NT AUTHORITY\LocalService,
+// NT SERVICE\MSSQLSERVER, .\backup, CONTOSO\svc-sql, svc-sql@contoso.com or CONTOSO\gmsa-sql$.
+func parseAccount(objectName, computerName string) serviceAccount {
+	account := serviceAccount{name: objectName, user: objectName}
+
+	if domain, user, ok := strings.Cut(objectName, `\`); ok {
+		account.domain, account.user = domain, user
+	} else if user, domain, ok := strings.Cut(objectName, "@"); ok {
+		account.domain, account.user = domain, user
+	}
+
+	switch {
+	case objectName == "" || strings.EqualFold(objectName, "LocalSystem") || strings.EqualFold(account.domain, "NT AUTHORITY"):
+		account.accountType = accountTypeSystem
+	case strings.EqualFold(account.domain, "NT SERVICE"):
+		account.accountType = accountTypeVirtual
+	case strings.HasSuffix(account.user, "$"):
+		account.accountType = accountTypeGMSA
+	case account.domain == "." || strings.EqualFold(account.domain, computerName):
+		account.accountType = accountTypeLocal
+	default:
+		account.accountType = accountTypeDomain
+	}
+
+	return account
+}
@@ -0,0 +1,473 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package service_slo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/psapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	Name = "service_slo"
+
+	checkState  = "state"
+	checkCPU    = "cpu"
+	checkMemory = "memory"
+	checkTCP    = "tcp"
+)
+
+type Config struct {
+	// Services are the names of the services, optionally followed by "=" and the host:port address of a TCP check.
+	Services []string `yaml:"services"`
+	// MaxCPURatio is the highest CPU usage of the process of a service considered healthy, 0 disables the check.
+	MaxCPURatio float64 `yaml:"max-cpu-ratio"`
+	// MaxWorkingSetBytes is the largest working set of the process of a service considered healthy, 0 disables the check.
+	MaxWorkingSetBytes uint64        `yaml:"max-working-set-bytes"`
+	Timeout            time.Duration `yaml:"timeout"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	Services:           []string{},
+	MaxCPURatio:        0,
+	MaxWorkingSetBytes: 0,
+	Timeout:            5 * time.Second,
+}
+
+// A Collector combines the state, the resource usage of the process and an optional TCP check
+// of a list of services into one health gauge per service.
+type Collector struct {
+	config  Config
+	logger  *slog.Logger
+	targets []target
+
+	// cpuSamples are the CPU times of the processes of the previous collection by service name,
+	// to calculate the CPU usage between two collections.
+	cpuSamplesMu sync.Mutex
+	cpuSamples   map[string]cpuSample
+
+	health            *prometheus.Desc
+	checkSuccess      *prometheus.Desc
+	processCPUTime    *prometheus.Desc
+	processCPUUsage   *prometheus.Desc
+	processWorkingSet *prometheus.Desc
+	tcpCheckDuration  *prometheus.Desc
+}
+
+type target struct {
+	name string
+	// address is the host:port of the TCP check, empty if the service has none.
+	address string
+}
+
+type cpuSample struct {
+	processID uint32
+	cpuTime   time.Duration
+	time      time.Time
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.Services == nil {
+		config.Services = ConfigDefaults.Services
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = ConfigDefaults.Timeout
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	var services string
+
+	app.Flag(
+		"collector.service_slo.services",
+		"Comma-separated list of service names, each optionally followed by = and the host:port address of a TCP check, e.g. sshd=localhost:22.",
+	).Default("").StringVar(&services)
+
+	app.Flag(
+		"collector.service_slo.max-cpu-ratio",
+		"Highest CPU usage of the process of a service considered healthy, 1 is one logical processor. 0 disables the check.",
+	).Default(strconv.FormatFloat(ConfigDefaults.MaxCPURatio, 'f', -1, 64)).Float64Var(&c.config.MaxCPURatio)
+
+	app.Flag(
+		"collector.service_slo.max-working-set-bytes",
+		"Largest working set of the process of a service considered healthy. 0 disables the check.",
+	).Default(strconv.FormatUint(ConfigDefaults.MaxWorkingSetBytes, 10)).Uint64Var(&c.config.MaxWorkingSetBytes)
+
+	app.Flag(
+		"collector.service_slo.timeout",
+		"Timeout of the TCP checks.",
+	).Default(ConfigDefaults.Timeout.String()).DurationVar(&c.config.Timeout)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config.Services = make([]string, 0)
+
+		for service := range strings.SplitSeq(services, ",") {
+			if service = strings.TrimSpace(service); service != "" {
+				c.config.Services = append(c.config.Services, service)
+			}
+		}
+
+		return nil
+	})
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+	c.cpuSamples = make(map[string]cpuSample)
+	c.targets = make([]target, 0, len(c.config.Services))
+
+	for _, service := range c.config.Services {
+		name, address, _ := strings.Cut(service, "=")
+
+		t := target{name: strings.TrimSpace(name), address: strings.TrimSpace(address)}
+		if t.name == "" {
+			return fmt.Errorf("invalid service %q: the name is empty", service)
+		}
+
+		if t.address != "" {
+			if _, _, err := net.SplitHostPort(t.address); err != nil {
+				return fmt.Errorf("invalid TCP check address of service %s: %w", t.name, err)
+			}
+		}
+
+		c.targets = append(c.targets, t)
+	}
+
+	if len(c.targets) == 0 {
+		c.logger.Warn("no services configured")
+	}
+
+	c.health = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "health"),
+		"1 if all checks of the service are successful, 0 otherwise.",
+		[]string{"name"},
+		nil,
+	)
+	c.checkSuccess = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "check_success"),
+		"Whether the check of the service was successful.",
+		[]string{"name", "check"},
+		nil,
+	)
+	c.processCPUTime = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "process_cpu_time_seconds_total"),
+		"CPU time of the process of the service, in user and privileged mode.",
+		[]string{"name"},
+		nil,
+	)
+	c.processCPUUsage = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "process_cpu_usage_ratio"),
+		"CPU usage of the process of the service since the previous collection, 1 is one logical processor.",
+		[]string{"name"},
+		nil,
+	)
+	c.processWorkingSet = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "process_working_set_bytes"),
+		"Working set of the process of the service.",
+		[]string{"name"},
+		nil,
+	)
+	c.tcpCheckDuration = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "tcp_check_duration_seconds"),
+		"Duration of the TCP check of the service.",
+		[]string{"name"},
+		nil,
+	)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	if len(c.targets) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	scm, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_CONNECT)
+	if err != nil {
+		return fmt.Errorf("failed to open scm: %w", err)
+	}
+
+	defer func() {
+		_ = windows.CloseServiceHandle(scm)
+	}()
+
+	var wg sync.WaitGroup
+
+	for _, t := range c.targets {
+		wg.Go(func() {
+			c.collectService(ctx, ch, scm, t)
+		})
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+func (c *Collector) collectService(ctx context.Context, ch chan<- prometheus.Metric, scm windows.Handle, t target) {
+	checks := make(map[string]bool, 4)
+
+	status, err := queryServiceStatus(scm, t.name)
+	if err != nil {
+		c.logger.Debug("failed to query service status",
+			slog.String("service", t.name),
+			slog.Any("err", err),
+		)
+	}
+
+	checks[checkState] = err == nil && status.CurrentState == windows.SERVICE_RUNNING
+
+	if checks[checkState] && status.ProcessId != 0 {
+		c.collectProcess(ch, t.name, status.ProcessId, checks)
+	} else {
+		c.forgetCPUSample(t.name)
+
+		// Without a process, the resource checks fail with the state check.
+		if c.config.MaxCPURatio > 0 {
+			checks[checkCPU] = false
+		}
+
+		if c.config.MaxWorkingSetBytes > 0 {
+			checks[checkMemory] = false
+		}
+	}
+
+	if t.address != "" {
+		start := time.Now()
+		err := probeTCP(ctx, t.address)
+
+		if err != nil {
+			c.logger.Debug("TCP check failed",
+				slog.String("service", t.name),
+				slog.String("address", t.address),
+				slog.Any("err", err),
+			)
+		}
+
+		checks[checkTCP] = err == nil
+
+		ch <- prometheus.MustNewConstMetric(
+			c.tcpCheckDuration,
+			prometheus.GaugeValue,
+			time.Since(start).Seconds(),
+			t.name,
+		)
+	}
+
+	healthy := true
+
+	for check, success := range checks {
+		healthy = healthy && success
+
+		ch <- prometheus.MustNewConstMetric(
+			c.checkSuccess,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(success),
+			t.name, check,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.health,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(healthy),
+		t.name,
+	)
+}
+
+// collectProcess collects the resource usage of the process of the service and records the results of the resource checks.
+// Services sharing a process, e.g. services hosted by svchost.exe, report the usage of the whole process.
+func (c *Collector) collectProcess(ch chan<- prometheus.Metric, name string, processID uint32, checks map[string]bool) {
+	process, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, processID)
+	if err != nil {
+		c.logger.Debug("failed to open process of service",
+			slog.String("service", name),
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	defer func() {
+		_ = windows.CloseHandle(process)
+	}()
+
+	var creation, exit, kernel, user windows.Filetime
+
+	if err := windows.GetProcessTimes(process, &creation, &exit, &kernel, &user); err != nil {
+		c.logger.Debug("failed to get process times of service",
+			slog.String("service", name),
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	memory, err := psapi.GetProcessMemoryInfo(process)
+	if err != nil {
+		c.logger.Debug("failed to get process memory info of service",
+			slog.String("service", name),
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	now := time.Now()
+	cpuTime := filetimeDuration(kernel) + filetimeDuration(user)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.processCPUTime,
+		prometheus.CounterValue,
+		cpuTime.Seconds(),
+		name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.processWorkingSet,
+		prometheus.GaugeValue,
+		float64(memory.WorkingSetSize),
+		name,
+	)
+
+	if c.config.MaxWorkingSetBytes > 0 {
+		checks[checkMemory] = uint64(memory.WorkingSetSize) <= c.config.MaxWorkingSetBytes
+	}
+
+	c.cpuSamplesMu.Lock()
+	previous, ok := c.cpuSamples[name]
+	c.cpuSamples[name] = cpuSample{processID: processID, cpuTime: cpuTime, time: now}
+	c.cpuSamplesMu.Unlock()
+
+	// The first collection and the first collection after a restart of the service have no usage yet,
+	// the CPU check passes until there is one.
+	if !ok || previous.processID != processID || !now.After(previous.time) {
+		if c.config.MaxCPURatio > 0 {
+			checks[checkCPU] = true
+		}
+
+		return
+	}
+
+	usage := (cpuTime - previous.cpuTime).Seconds() / now.Sub(previous.time).Seconds()
+
+	ch <- prometheus.MustNewConstMetric(
+		c.processCPUUsage,
+		prometheus.GaugeValue,
+		usage,
+		name,
+	)
+
+	if c.config.MaxCPURatio > 0 {
+		checks[checkCPU] = usage <= c.config.MaxCPURatio
+	}
+}
+
+func (c *Collector) forgetCPUSample(name string) {
+	c.cpuSamplesMu.Lock()
+	delete(c.cpuSamples, name)
+	c.cpuSamplesMu.Unlock()
+}
+
+func queryServiceStatus(scm windows.Handle, name string) (windows.SERVICE_STATUS_PROCESS, error) {
+	serviceName, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return windows.SERVICE_STATUS_PROCESS{}, err
+	}
+
+	service, err := windows.OpenService(scm, serviceName, windows.SERVICE_QUERY_STATUS)
+	if err != nil {
+		return windows.SERVICE_STATUS_PROCESS{}, fmt.Errorf("failed to open service: %w", err)
+	}
+
+	defer func() {
+		_ = windows.CloseServiceHandle(service)
+	}()
+
+	var (
+		status      windows.SERVICE_STATUS_PROCESS
+		bytesNeeded uint32
+	)
+
+	if err := windows.QueryServiceStatusEx(service, windows.SC_STATUS_PROCESS_INFO, (*byte)(unsafe.Pointer(&status)), uint32(unsafe.Sizeof(status)), &bytesNeeded); err != nil {
+		return windows.SERVICE_STATUS_PROCESS{}, fmt.Errorf("failed to query service status: %w", err)
+	}
+
+	return status, nil
+}
+
+func probeTCP(ctx context.Context, address string) error {
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// filetimeDuration converts a FILETIME holding a duration in 100ns intervals, e.g. the CPU time of GetProcessTimes.
+func filetimeDuration(ft windows.Filetime) time.Duration {
+	return time.Duration(uint64(ft.HighDateTime)<<32|uint64(ft.LowDateTime)) * 100
+}
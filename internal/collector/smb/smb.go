@@ -18,8 +18,11 @@
 package smb
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
@@ -28,15 +31,32 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-const Name = "smb"
+const (
+	Name                       = "smb"
+	subCollectorServerShares   = "server_shares"
+	subCollectorServerSessions = "server_sessions"
+)
 
-type Config struct{}
+type Config struct {
+	CollectorsEnabled []string `yaml:"enabled"`
+}
 
 //nolint:gochecknoglobals
-var ConfigDefaults = Config{}
+var ConfigDefaults = Config{
+	CollectorsEnabled: []string{
+		subCollectorServerShares,
+		subCollectorServerSessions,
+	},
+}
 
+// A Collector is a Prometheus Collector for the SMB server. Share metrics are read from the
+// SMB Server Shares performance counters, sessions from WMI MSFT_SmbSession.
 type Collector struct {
 	config Config
+	logger *slog.Logger
+
+	miSession       *mi.Session
+	sessionsEnabled bool
 
 	perfDataCollector *pdh.Collector
 	perfDataObject    []perfDataCounterValues
@@ -49,6 +69,12 @@ type Collector struct {
 	metadataRequests     *prometheus.Desc
 	sentBytes            *prometheus.Desc
 	filesOpened          *prometheus.Desc
+	readSeconds          *prometheus.Desc
+	writeSeconds         *prometheus.Desc
+	requestSeconds       *prometheus.Desc
+
+	sessions         *prometheus.Desc
+	sessionOpenFiles *prometheus.Desc
 }
 
 func New(config *Config) *Collector {
@@ -56,6 +82,10 @@ func New(config *Config) *Collector {
 		config = &ConfigDefaults
 	}
 
+	if config.CollectorsEnabled == nil {
+		config.CollectorsEnabled = ConfigDefaults.CollectorsEnabled
+	}
+
 	c := &Collector{
 		config: *config,
 	}
@@ -63,8 +93,29 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(_ *kingpin.Application) *Collector {
-	return &Collector{}
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+	c.config.CollectorsEnabled = make([]string, 0)
+
+	var collectorsEnabled string
+
+	app.Flag(
+		"collector.smb.enabled",
+		fmt.Sprintf("Comma-separated list of collectors to use. Available collectors: %s, %s.",
+			subCollectorServerShares,
+			subCollectorServerSessions,
+		),
+	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+
+		return nil
+	})
+
+	return c
 }
 
 func (c *Collector) GetName() string {
@@ -72,12 +123,24 @@ func (c *Collector) GetName() string {
 }
 
 func (c *Collector) Close() error {
-	c.perfDataCollector.Close()
+	if c.perfDataCollector != nil {
+		c.perfDataCollector.Close()
+	}
 
 	return nil
 }
 
-func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	for _, collector := range c.config.CollectorsEnabled {
+		if !slices.Contains([]string{subCollectorServerShares, subCollectorServerSessions}, collector) {
+			return fmt.Errorf("unknown sub collector: %s. Possible values: %s", collector,
+				strings.Join([]string{subCollectorServerShares, subCollectorServerSessions}, ", "),
+			)
+		}
+	}
+
 	c.currentOpenFileCount = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, Name, "server_shares_current_open_file_count"),
 		"Current total count open files on the SMB Server Share",
@@ -127,11 +190,38 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		nil,
 	)
 
-	var err error
+	c.readSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "server_shares_read_seconds_total"),
+		"Seconds waiting for read requests on the SMB Server Share",
+		[]string{"share"},
+		nil,
+	)
+	c.writeSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "server_shares_write_seconds_total"),
+		"Seconds waiting for write requests on the SMB Server Share",
+		[]string{"share"},
+		nil,
+	)
+	c.requestSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "server_shares_request_seconds_total"),
+		"Seconds waiting for read and write requests on the SMB Server Share",
+		[]string{"share"},
+		nil,
+	)
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorServerShares) {
+		var err error
 
-	c.perfDataCollector, err = pdh.NewCollector[perfDataCounterValues](logger.With(slog.String("collector", Name)), pdh.CounterTypeRaw, "SMB Server Shares", pdh.InstancesAll)
-	if err != nil {
-		return fmt.Errorf("failed to create SMB Server Shares collector: %w", err)
+		c.perfDataCollector, err = pdh.NewCollector[perfDataCounterValues](c.logger, pdh.CounterTypeRaw, "SMB Server Shares", pdh.InstancesAll)
+		if err != nil {
+			return fmt.Errorf("failed to create SMB Server Shares collector: %w", err)
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorServerSessions) {
+		if err := c.buildSessions(miSession); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -139,6 +229,24 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 
 // Collect collects smb metrics and sends them to prometheus.
 func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if c.perfDataCollector != nil {
+		if err := c.collectServerShares(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting share metrics: %w", err))
+		}
+	}
+
+	if c.sessionsEnabled {
+		if err := c.collectSessions(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting session metrics: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectServerShares(ch chan<- prometheus.Metric) error {
 	err := c.perfDataCollector.Collect(&c.perfDataObject)
 	if err != nil {
 		return fmt.Errorf("failed to collect SMB Server Shares metrics: %w", err)
@@ -200,6 +308,27 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 			data.FilesOpened,
 			data.Name,
 		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.readSeconds,
+			prometheus.CounterValue,
+			data.AvgSecPerRead*pdh.TicksToSecondScaleFactor,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.writeSeconds,
+			prometheus.CounterValue,
+			data.AvgSecPerWrite*pdh.TicksToSecondScaleFactor,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.requestSeconds,
+			prometheus.CounterValue,
+			data.AvgSecPerDataRequest*pdh.TicksToSecondScaleFactor,
+			data.Name,
+		)
 	}
 
 	return nil
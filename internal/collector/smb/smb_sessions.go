@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package smb
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+//nolint:gochecknoglobals
+var sessionQuery = utils.Must(mi.NewQuery("SELECT ClientComputerName, ClientUserName, Dialect, NumOpens, Signed, Encrypted FROM MSFT_SmbSession"))
+
+// sessionKey groups the sessions of a client. A client may have multiple sessions, e.g. one per user or
+// after a reconnect, so sessions are counted instead of exposing one series per session ID.
+type sessionKey struct {
+	client    string
+	user      string
+	dialect   string
+	signed    bool
+	encrypted bool
+}
+
+type openFilesKey struct {
+	client string
+	user   string
+}
+
+// buildSessions prepares the session metrics. Without the SMB WMI provider, or on releases whose MSFT_SmbSession
+// lacks the Signed and Encrypted properties, the session metrics are skipped.
+func (c *Collector) buildSessions(miSession *mi.Session) error {
+	c.sessions = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "server_sessions"),
+		"Number of SMB Server sessions by client, user, dialect, signing and encryption (MSFT_SmbSession)",
+		[]string{"client", "user", "dialect", "signed", "encrypted"},
+		nil,
+	)
+	c.sessionOpenFiles = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "server_sessions_open_files"),
+		"Number of files opened by the SMB Server sessions of the client and user (MSFT_SmbSession.NumOpens)",
+		[]string{"client", "user"},
+		nil,
+	)
+
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.miSession = miSession
+
+	var dst []msftSmbSession
+	if err := c.miSession.Query(&dst, mi.NamespaceRootWindowsSMB, sessionQuery); err != nil {
+		if !errors.Is(err, mi.MI_RESULT_INVALID_NAMESPACE) && !errors.Is(err, mi.MI_RESULT_INVALID_CLASS) &&
+			!errors.Is(err, mi.MI_RESULT_INVALID_QUERY) && !errors.Is(err, mi.MI_RESULT_NO_SUCH_PROPERTY) {
+			return fmt.Errorf("WMI query failed: %w", err)
+		}
+
+		c.logger.Debug("MSFT_SmbSession is not available, skipping session metrics",
+			slog.Any("err", err),
+		)
+
+		return nil
+	}
+
+	c.sessionsEnabled = true
+
+	return nil
+}
+
+func (c *Collector) collectSessions(ch chan<- prometheus.Metric) error {
+	var dst []msftSmbSession
+	if err := c.miSession.Query(&dst, mi.NamespaceRootWindowsSMB, sessionQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	sessions := make(map[sessionKey]float64)
+	openFiles := make(map[openFilesKey]float64)
+
+	for _, session := range dst {
+		sessions[sessionKey{
+			client:    session.ClientComputerName,
+			user:      session.ClientUserName,
+			dialect:   session.Dialect,
+			signed:    session.Signed,
+			encrypted: session.Encrypted,
+		}]++
+
+		openFiles[openFilesKey{client: session.ClientComputerName, user: session.ClientUserName}] += float64(session.NumOpens)
+	}
+
+	for key, count := range sessions {
+		ch <- prometheus.MustNewConstMetric(
+			c.sessions,
+			prometheus.GaugeValue,
+			count,
+			key.client,
+			key.user,
+			key.dialect,
+			strconv.FormatBool(key.signed),
+			strconv.FormatBool(key.encrypted),
+		)
+	}
+
+	for key, count := range openFiles {
+		ch <- prometheus.MustNewConstMetric(
+			c.sessionOpenFiles,
+			prometheus.GaugeValue,
+			count,
+			key.client,
+			key.user,
+		)
+	}
+
+	return nil
+}
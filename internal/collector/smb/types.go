@@ -28,4 +28,16 @@ type perfDataCounterValues struct {
 	MetadataRequests     float64 `perfdata:"Metadata Requests/sec"`
 	SentBytes            float64 `perfdata:"Sent Bytes/sec"`
 	FilesOpened          float64 `perfdata:"Files Opened/sec"`
+	AvgSecPerRead        float64 `perfdata:"Avg. sec/Read"`
+	AvgSecPerWrite       float64 `perfdata:"Avg. sec/Write"`
+	AvgSecPerDataRequest float64 `perfdata:"Avg. sec/Data Request"`
+}
+
+type msftSmbSession struct {
+	ClientComputerName string `mi:"ClientComputerName"`
+	ClientUserName     string `mi:"ClientUserName"`
+	Dialect            string `mi:"Dialect"`
+	NumOpens           uint64 `mi:"NumOpens"`
+	Signed             bool   `mi:"Signed"`
+	Encrypted          bool   `mi:"Encrypted"`
 }
@@ -0,0 +1,285 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package smb_security
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "smb_security"
+
+	lanmanServerParametersKey = `SYSTEM\CurrentControlSet\Services\LanmanServer\Parameters`
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var serverConfigurationQuery = utils.Must(mi.NewQuery(
+	"SELECT EnableSMB1Protocol, EnableSecuritySignature, RequireSecuritySignature, EncryptData, RejectUnencryptedAccess, AutoShareServer, AutoShareWorkstation FROM MSFT_SmbServerConfiguration",
+))
+
+// A Collector is a Prometheus Collector for the security relevant configuration of the SMB server.
+// The configuration is read from WMI MSFT_SmbServerConfiguration, null session settings from the registry.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	miSession *mi.Session
+
+	smb1Enabled                 *prometheus.Desc
+	signingEnabled              *prometheus.Desc
+	signingRequired             *prometheus.Desc
+	encryptionRequired          *prometheus.Desc
+	unencryptedAccessRejected   *prometheus.Desc
+	adminSharesEnabled          *prometheus.Desc
+	nullSessionShare            *prometheus.Desc
+	nullSessionAccessRestricted *prometheus.Desc
+}
+
+type msftSmbServerConfiguration struct {
+	EnableSMB1Protocol       bool `mi:"EnableSMB1Protocol"`
+	EnableSecuritySignature  bool `mi:"EnableSecuritySignature"`
+	RequireSecuritySignature bool `mi:"RequireSecuritySignature"`
+	EncryptData              bool `mi:"EncryptData"`
+	RejectUnencryptedAccess  bool `mi:"RejectUnencryptedAccess"`
+	AutoShareServer          bool `mi:"AutoShareServer"`
+	AutoShareWorkstation     bool `mi:"AutoShareWorkstation"`
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.smb1Enabled = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "smb1_enabled"),
+		"1 if the SMB 1.0 protocol is enabled on the SMB server, 0 otherwise",
+		nil,
+		nil,
+	)
+	c.signingEnabled = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "signing_enabled"),
+		"1 if the SMB server signs packets if the client requests it, 0 otherwise",
+		nil,
+		nil,
+	)
+	c.signingRequired = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "signing_required"),
+		"1 if the SMB server requires signed packets, 0 otherwise",
+		nil,
+		nil,
+	)
+	c.encryptionRequired = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "encryption_required"),
+		"1 if the SMB server encrypts the traffic of all shares, 0 otherwise",
+		nil,
+		nil,
+	)
+	c.unencryptedAccessRejected = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "unencrypted_access_rejected"),
+		"1 if the SMB server rejects clients that do not support encryption, 0 otherwise",
+		nil,
+		nil,
+	)
+	c.adminSharesEnabled = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "admin_shares_enabled"),
+		"1 if the administrative shares (C$, ADMIN$) are created automatically, 0 otherwise",
+		[]string{"type"},
+		nil,
+	)
+	c.nullSessionShare = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "null_session_share"),
+		"Share that can be accessed by anonymous users",
+		[]string{"share"},
+		nil,
+	)
+	c.nullSessionAccessRestricted = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "null_session_access_restricted"),
+		"1 if anonymous access to named pipes and shares is restricted to the null session shares and pipes, 0 otherwise",
+		nil,
+		nil,
+	)
+
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.miSession = miSession
+
+	var dst []msftSmbServerConfiguration
+	if err := c.miSession.Query(&dst, mi.NamespaceRootWindowsSMB, serverConfigurationQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if err := c.collectServerConfiguration(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting SMB server configuration: %w", err))
+	}
+
+	if err := c.collectNullSessions(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting null session configuration: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectServerConfiguration(ch chan<- prometheus.Metric) error {
+	var dst []msftSmbServerConfiguration
+	if err := c.miSession.Query(&dst, mi.NamespaceRootWindowsSMB, serverConfigurationQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	if len(dst) == 0 {
+		return errors.New("WMI query returned empty result set")
+	}
+
+	config := dst[0]
+
+	ch <- prometheus.MustNewConstMetric(
+		c.smb1Enabled,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(config.EnableSMB1Protocol),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.signingEnabled,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(config.EnableSecuritySignature),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.signingRequired,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(config.RequireSecuritySignature),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.encryptionRequired,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(config.EncryptData),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.unencryptedAccessRejected,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(config.RejectUnencryptedAccess),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.adminSharesEnabled,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(config.AutoShareServer),
+		"server",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.adminSharesEnabled,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(config.AutoShareWorkstation),
+		"workstation",
+	)
+
+	return nil
+}
+
+// collectNullSessions reads the shares that are accessible without authentication. Windows falls back to
+// restricting anonymous access if RestrictNullSessAccess is not set.
+func (c *Collector) collectNullSessions(ch chan<- prometheus.Metric) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, lanmanServerParametersKey, registry.QUERY_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open registry key %s: %w", lanmanServerParametersKey, err)
+	}
+
+	defer key.Close()
+
+	shares, _, err := key.GetStringsValue("NullSessionShares")
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return fmt.Errorf("failed to read registry value NullSessionShares: %w", err)
+	}
+
+	for _, share := range shares {
+		share = strings.TrimSpace(share)
+		if share == "" {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.nullSessionShare,
+			prometheus.GaugeValue,
+			1.0,
+			share,
+		)
+	}
+
+	restricted := true
+
+	if value, _, err := key.GetIntegerValue("RestrictNullSessAccess"); err == nil {
+		restricted = value != 0
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.nullSessionAccessRestricted,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(restricted),
+	)
+
+	return nil
+}
@@ -0,0 +1,314 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package smb_witness
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const Name = "smb_witness"
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var registrationsQuery = utils.Must(mi.NewQuery("SELECT ClientName,FileServerNodeName,NetworkName,WitnessNodeName FROM MSFT_SmbWitnessClient"))
+
+// clientChannels are the event log channels of the SMB witness client by the channel label.
+// The admin channel logs failed registrations and notifications, the informational channel the
+// notifications received from the witness service, e.g. to move to another node of the cluster.
+//
+//nolint:gochecknoglobals
+var clientChannels = map[string]string{
+	"admin":         "Microsoft-Windows-SMBWitnessClient/Admin",
+	"informational": "Microsoft-Windows-SMBWitnessClient/Informational",
+}
+
+// eventLevels are the names of the standard levels of events, the level label.
+//
+//nolint:gochecknoglobals
+var eventLevels = map[uint64]string{
+	1: "critical",
+	2: "error",
+	3: "warning",
+	4: "information",
+	5: "verbose",
+}
+
+// renderValuePaths are the event properties rendered for each SMB witness client event.
+// The order must match the value* indices.
+//
+//nolint:gochecknoglobals
+var renderValuePaths = []string{
+	"Event/System/EventRecordID",
+	"Event/System/EventID",
+	"Event/System/Level",
+}
+
+const (
+	valueEventRecordID = iota
+	valueEventID
+	valueLevel
+)
+
+// A Collector is a Prometheus Collector for the registrations of the SMB witness service
+// and the events of the SMB witness client.
+type Collector struct {
+	config    Config
+	logger    *slog.Logger
+	miSession *mi.Session
+
+	// registrationsEnabled is false if the SMBWitness WMI namespace is missing, e.g. on hosts without the witness service.
+	registrationsEnabled bool
+
+	renderContext wevtapi.EVT_HANDLE
+	// mu protects lastRecordIDs and events against concurrent scrapes.
+	mu sync.Mutex
+	// lastRecordIDs are the record IDs of the last counted event by channel label of the available channels.
+	lastRecordIDs map[string]uint64
+	events        map[eventKey]float64
+
+	registrations *prometheus.Desc
+	clientEvents  *prometheus.Desc
+}
+
+// msftSmbWitnessClient represents a registration of a client with the witness service.
+type msftSmbWitnessClient struct {
+	ClientName         string `mi:"ClientName"`
+	FileServerNodeName string `mi:"FileServerNodeName"`
+	NetworkName        string `mi:"NetworkName"`
+	WitnessNodeName    string `mi:"WitnessNodeName"`
+}
+
+type registrationKey struct {
+	client         string
+	networkName    string
+	fileServerNode string
+	witnessNode    string
+}
+
+type eventKey struct {
+	channel string
+	id      uint64
+	level   string
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if c.renderContext != 0 {
+		return wevtapi.EvtClose(c.renderContext)
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.miSession = miSession
+
+	c.registrations = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "registrations"),
+		"Number of shares that the client registered with the witness service for notifications about the file server node",
+		[]string{"client", "network_name", "file_server_node", "witness_node"},
+		nil,
+	)
+	c.clientEvents = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "client_events_total"),
+		"Number of events logged by the SMB witness client since windows_exporter started",
+		[]string{"channel", "id", "level"},
+		nil,
+	)
+
+	var dst []msftSmbWitnessClient
+	if err := c.miSession.Query(&dst, mi.NamespaceRootWindowsSMBWitness, registrationsQuery); err != nil {
+		if !errors.Is(err, mi.MI_RESULT_INVALID_NAMESPACE) {
+			return fmt.Errorf("WMI query failed: %w", err)
+		}
+
+		c.logger.Debug("SMBWitness WMI namespace is not available, skipping registration metrics")
+	} else {
+		c.registrationsEnabled = true
+	}
+
+	c.lastRecordIDs = make(map[string]uint64, len(clientChannels))
+	c.events = make(map[eventKey]float64)
+
+	for label, channel := range clientChannels {
+		lastRecordID, err := wevtapi.LatestEventRecordID(channel)
+		if err != nil {
+			if errors.Is(err, wevtapi.ERROR_EVT_CHANNEL_NOT_FOUND) {
+				c.logger.Debug("SMB witness client event log not found, skipping its events",
+					slog.String("channel", channel),
+				)
+
+				continue
+			}
+
+			return fmt.Errorf("failed to read latest event of %s: %w", channel, err)
+		}
+
+		c.lastRecordIDs[label] = lastRecordID
+	}
+
+	if len(c.lastRecordIDs) > 0 {
+		var err error
+
+		c.renderContext, err = wevtapi.EvtCreateRenderContext(renderValuePaths)
+		if err != nil {
+			return fmt.Errorf("failed to create event render context: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if c.registrationsEnabled {
+		if err := c.collectRegistrations(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting registration metrics: %w", err))
+		}
+	}
+
+	if len(c.lastRecordIDs) > 0 {
+		if err := c.collectClientEvents(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting client event metrics: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectRegistrations(ch chan<- prometheus.Metric) error {
+	var dst []msftSmbWitnessClient
+	if err := c.miSession.Query(&dst, mi.NamespaceRootWindowsSMBWitness, registrationsQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	registrations := make(map[registrationKey]float64)
+
+	for _, registration := range dst {
+		registrations[registrationKey{
+			client:         registration.ClientName,
+			networkName:    registration.NetworkName,
+			fileServerNode: registration.FileServerNodeName,
+			witnessNode:    registration.WitnessNodeName,
+		}]++
+	}
+
+	for key, count := range registrations {
+		ch <- prometheus.MustNewConstMetric(
+			c.registrations,
+			prometheus.GaugeValue,
+			count,
+			key.client, key.networkName, key.fileServerNode, key.witnessNode,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectClientEvents(ch chan<- prometheus.Metric) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	errs := make([]error, 0)
+
+	for label, lastRecordID := range c.lastRecordIDs {
+		channel := clientChannels[label]
+		query := fmt.Sprintf("*[System[EventRecordID > %d]]", lastRecordID)
+
+		err := wevtapi.QueryValues(channel, query, c.renderContext, func(values []any) {
+			c.handleEvent(label, values)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read events of %s: %w", channel, err))
+		}
+	}
+
+	for key, count := range c.events {
+		ch <- prometheus.MustNewConstMetric(
+			c.clientEvents,
+			prometheus.CounterValue,
+			count,
+			key.channel, strconv.FormatUint(key.id, 10), key.level,
+		)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) handleEvent(channel string, values []any) {
+	if len(values) != len(renderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[valueEventRecordID].(uint64)
+	c.lastRecordIDs[channel] = max(c.lastRecordIDs[channel], recordID)
+
+	eventID, _ := values[valueEventID].(uint64)
+	levelValue, _ := values[valueLevel].(uint64)
+
+	level, ok := eventLevels[levelValue]
+	if !ok {
+		// Level 0 is used by events that are logged regardless of the level.
+		level = "information"
+	}
+
+	c.events[eventKey{channel: channel, id: eventID, level: level}]++
+}
@@ -18,11 +18,14 @@
 package smbclient
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
 
 	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
 	"github.com/prometheus-community/windows_exporter/internal/pdh"
 	"github.com/prometheus-community/windows_exporter/internal/types"
@@ -40,10 +43,20 @@ var ConfigDefaults = Config{}
 
 type Collector struct {
 	config Config
+	logger *slog.Logger
 
 	perfDataCollector *pdh.Collector
 	perfDataObject    []perfDataCounterValues
 
+	reconnectsEnabled bool
+	renderContext     wevtapi.EVT_HANDLE
+
+	// mu protects the reconnect counters and lastRecordID against concurrent scrapes.
+	mu                sync.Mutex
+	lastRecordID      uint64
+	sessionReconnects map[string]float64
+	shareReconnects   map[reconnectKey]float64
+
 	readBytesTotal                            *prometheus.Desc
 	readBytesTransmittedViaSMBDirectTotal     *prometheus.Desc
 	readRequestQueueSecsTotal                 *prometheus.Desc
@@ -66,6 +79,9 @@ type Collector struct {
 	metadataRequestsTotal *prometheus.Desc
 	requestQueueSecsTotal *prometheus.Desc
 	requestSecs           *prometheus.Desc
+
+	sessionReconnectsTotal *prometheus.Desc
+	shareReconnectsTotal   *prometheus.Desc
 }
 
 func New(config *Config) *Collector {
@@ -89,10 +105,16 @@ func (c *Collector) GetName() string {
 }
 
 func (c *Collector) Close() error {
+	if c.renderContext != 0 {
+		return wevtapi.EvtClose(c.renderContext)
+	}
+
 	return nil
 }
 
 func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
 	// desc creates a new prometheus description
 	desc := func(metricName string, description string, labels []string) *prometheus.Desc {
 		return prometheus.NewDesc(
@@ -187,19 +209,43 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		"Seconds waiting for write requests on this share",
 		[]string{"server", "share"},
 	)
+	c.sessionReconnectsTotal = desc("session_reconnects_total",
+		"The number of times the session to this server was re-established since the start of windows_exporter",
+		[]string{"server"},
+	)
+	c.shareReconnectsTotal = desc("share_reconnects_total",
+		"The number of times the connection to this share was re-established since the start of windows_exporter",
+		[]string{"server", "share"},
+	)
 
 	var err error
 
-	c.perfDataCollector, err = pdh.NewCollector[perfDataCounterValues](logger.With(slog.String("collector", Name)), pdh.CounterTypeRaw, "SMB Client Shares", pdh.InstancesAll)
+	c.perfDataCollector, err = pdh.NewCollector[perfDataCounterValues](c.logger, pdh.CounterTypeRaw, "SMB Client Shares", pdh.InstancesAll)
 	if err != nil {
 		return fmt.Errorf("failed to create SMB Client Shares collector: %w", err)
 	}
 
-	return nil
+	return c.buildReconnects()
 }
 
 // Collect collects smb client metrics and sends them to prometheus.
 func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if err := c.collectShares(ch); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.reconnectsEnabled {
+		if err := c.collectReconnects(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting reconnect metrics: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectShares(ch chan<- prometheus.Metric) error {
 	err := c.perfDataCollector.Collect(&c.perfDataObject)
 	if err != nil {
 		return fmt.Errorf("failed to collect SMB Client Shares metrics: %w", err)
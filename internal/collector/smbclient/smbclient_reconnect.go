@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package smbclient
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	operationalChannel = "Microsoft-Windows-SMBClient/Operational"
+
+	// eventIDSessionReconnected is logged if the client re-established a session to a server.
+	eventIDSessionReconnected = 30806
+	// eventIDShareReconnected is logged if the client re-established the connection to a share.
+	eventIDShareReconnected = 30808
+)
+
+// renderValuePaths are the event properties rendered for each SMB client reconnect event.
+// The order must match the value* indices.
+//
+//nolint:gochecknoglobals
+var renderValuePaths = []string{
+	"Event/System/EventRecordID",
+	"Event/System/EventID",
+	"Event/EventData/Data[@Name='ServerName']",
+	"Event/EventData/Data[@Name='ShareName']",
+}
+
+const (
+	valueEventRecordID = iota
+	valueEventID
+	valueServerName
+	valueShareName
+)
+
+type reconnectKey struct {
+	server string
+	share  string
+}
+
+// buildReconnects prepares reading the reconnect events. Reconnects are counted from the start of windows_exporter.
+func (c *Collector) buildReconnects() error {
+	lastRecordID, err := wevtapi.LatestEventRecordID(operationalChannel)
+	if err != nil {
+		if errors.Is(err, wevtapi.ERROR_EVT_CHANNEL_NOT_FOUND) {
+			c.logger.Debug("SMB client operational event log not found, skipping reconnect metrics")
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to read latest event of %s: %w", operationalChannel, err)
+	}
+
+	c.lastRecordID = lastRecordID
+	c.sessionReconnects = make(map[string]float64)
+	c.shareReconnects = make(map[reconnectKey]float64)
+
+	c.renderContext, err = wevtapi.EvtCreateRenderContext(renderValuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	c.reconnectsEnabled = true
+
+	return nil
+}
+
+func (c *Collector) collectReconnects(ch chan<- prometheus.Metric) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	query := fmt.Sprintf("*[System[(EventID=%d or EventID=%d) and EventRecordID > %d]]",
+		eventIDSessionReconnected, eventIDShareReconnected, c.lastRecordID,
+	)
+
+	if err := wevtapi.QueryValues(operationalChannel, query, c.renderContext, c.handleEvent); err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", operationalChannel, err)
+	}
+
+	for server, count := range c.sessionReconnects {
+		ch <- prometheus.MustNewConstMetric(
+			c.sessionReconnectsTotal,
+			prometheus.CounterValue,
+			count,
+			server,
+		)
+	}
+
+	for key, count := range c.shareReconnects {
+		ch <- prometheus.MustNewConstMetric(
+			c.shareReconnectsTotal,
+			prometheus.CounterValue,
+			count,
+			key.server, key.share,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) handleEvent(values []any) {
+	if len(values) != len(renderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[valueEventRecordID].(uint64)
+	c.lastRecordID = max(c.lastRecordID, recordID)
+
+	eventID, _ := values[valueEventID].(uint64)
+
+	switch eventID {
+	case eventIDSessionReconnected:
+		server, _ := values[valueServerName].(string)
+		c.sessionReconnects[strings.TrimLeft(server, `\`)]++
+	case eventIDShareReconnected:
+		share, _ := values[valueShareName].(string)
+
+		// The share name is logged as UNC path \\server\share, like the instance names of the performance counters.
+		parsed := strings.FieldsFunc(share, func(r rune) bool { return r == '\\' })
+		if len(parsed) != 2 {
+			return
+		}
+
+		c.shareReconnects[reconnectKey{server: parsed[0], share: parsed[1]}]++
+	}
+}
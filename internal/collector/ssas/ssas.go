@@ -0,0 +1,512 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package ssas
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "ssas"
+
+	subCollectorMemory     = "memory"
+	subCollectorProcessing = "processing"
+	subCollectorThreads    = "threads"
+	subCollectorConnection = "connection"
+)
+
+type Config struct {
+	CollectorsEnabled []string `yaml:"enabled"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	CollectorsEnabled: []string{
+		subCollectorMemory,
+		subCollectorProcessing,
+		subCollectorThreads,
+		subCollectorConnection,
+	},
+}
+
+// ssasInstance is an installed Analysis Services instance.
+type ssasInstance struct {
+	name string
+	// perfObjectPrefix is the prefix of the performance objects of the instance,
+	// MSAS<version> for the default instance and MSOLAP$<name> for named instances.
+	perfObjectPrefix string
+}
+
+// A Collector is a Prometheus Collector for SQL Server Analysis Services metrics.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	ssasInstances []ssasInstance
+
+	memoryPerfDataCollectors     map[string]*pdh.Collector
+	processingPerfDataCollectors map[string]*pdh.Collector
+	threadsPerfDataCollectors    map[string]*pdh.Collector
+	connectionPerfDataCollectors map[string]*pdh.Collector
+
+	memoryLimitHardBytes *prometheus.Desc
+	memoryLimitHighBytes *prometheus.Desc
+	memoryLimitLowBytes  *prometheus.Desc
+	memoryUsageBytes     *prometheus.Desc
+
+	processingRowsReadTotal    *prometheus.Desc
+	processingRowsWrittenTotal *prometheus.Desc
+
+	queryPoolBusyThreads    *prometheus.Desc
+	queryPoolIdleThreads    *prometheus.Desc
+	queryPoolJobQueueLength *prometheus.Desc
+
+	connections   *prometheus.Desc
+	userSessions  *prometheus.Desc
+	requestsTotal *prometheus.Desc
+	failuresTotal *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.CollectorsEnabled == nil {
+		config.CollectorsEnabled = ConfigDefaults.CollectorsEnabled
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+	c.config.CollectorsEnabled = make([]string, 0)
+
+	var collectorsEnabled string
+
+	app.Flag(
+		"collector.ssas.enabled",
+		"Comma-separated list of collectors to use. Defaults to all, if not specified.",
+	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+
+		return nil
+	})
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	for _, perfDataCollectors := range []map[string]*pdh.Collector{
+		c.memoryPerfDataCollectors,
+		c.processingPerfDataCollectors,
+		c.threadsPerfDataCollectors,
+		c.connectionPerfDataCollectors,
+	} {
+		for _, perfDataCollector := range perfDataCollectors {
+			perfDataCollector.Close()
+		}
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	subCollectors := []string{subCollectorMemory, subCollectorProcessing, subCollectorThreads, subCollectorConnection}
+
+	for _, collector := range c.config.CollectorsEnabled {
+		if !slices.Contains(subCollectors, collector) {
+			return fmt.Errorf("unknown sub collector: %s. Possible values: %s", collector,
+				strings.Join(subCollectors, ", "),
+			)
+		}
+	}
+
+	c.logger = logger.With(slog.String("collector", Name))
+
+	var err error
+
+	c.ssasInstances, err = c.getSSASInstances()
+	if err != nil {
+		return err
+	}
+
+	errs := make([]error, 0)
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorMemory) {
+		c.memoryLimitHardBytes = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "memory_limit_hard_bytes"),
+			"Hard memory limit of the instance. Requests are rejected once the memory usage exceeds the limit",
+			[]string{"ssas_instance"},
+			nil,
+		)
+		c.memoryLimitHighBytes = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "memory_limit_high_bytes"),
+			"High memory limit of the instance. Memory is cleaned up aggressively above the limit",
+			[]string{"ssas_instance"},
+			nil,
+		)
+		c.memoryLimitLowBytes = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "memory_limit_low_bytes"),
+			"Low memory limit of the instance. Memory is cleaned up above the limit",
+			[]string{"ssas_instance"},
+			nil,
+		)
+		c.memoryUsageBytes = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "memory_usage_bytes"),
+			"Memory used by the instance, used to calculate the memory price",
+			[]string{"ssas_instance"},
+			nil,
+		)
+
+		c.memoryPerfDataCollectors, err = buildPerfDataCollectors[perfDataCounterValuesMemory](c.logger, c.ssasInstances, "Memory")
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorProcessing) {
+		c.processingRowsReadTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "processing_rows_read_total"),
+			"Number of rows read from relational data sources during processing",
+			[]string{"ssas_instance"},
+			nil,
+		)
+		c.processingRowsWrittenTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "processing_rows_written_total"),
+			"Number of rows written during processing",
+			[]string{"ssas_instance"},
+			nil,
+		)
+
+		c.processingPerfDataCollectors, err = buildPerfDataCollectors[perfDataCounterValuesProcessing](c.logger, c.ssasInstances, "Processing")
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorThreads) {
+		c.queryPoolBusyThreads = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "query_pool_busy_threads"),
+			"Number of busy threads in the query thread pool",
+			[]string{"ssas_instance"},
+			nil,
+		)
+		c.queryPoolIdleThreads = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "query_pool_idle_threads"),
+			"Number of idle threads in the query thread pool",
+			[]string{"ssas_instance"},
+			nil,
+		)
+		c.queryPoolJobQueueLength = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "query_pool_job_queue_length"),
+			"Number of jobs in the queue of the query thread pool",
+			[]string{"ssas_instance"},
+			nil,
+		)
+
+		c.threadsPerfDataCollectors, err = buildPerfDataCollectors[perfDataCounterValuesThreads](c.logger, c.ssasInstances, "Threads")
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorConnection) {
+		c.connections = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "connections"),
+			"Number of client connections established to the instance",
+			[]string{"ssas_instance"},
+			nil,
+		)
+		c.userSessions = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "user_sessions"),
+			"Number of user sessions established to the instance",
+			[]string{"ssas_instance"},
+			nil,
+		)
+		c.requestsTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "connection_requests_total"),
+			"Number of connection requests to the instance",
+			[]string{"ssas_instance"},
+			nil,
+		)
+		c.failuresTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "connection_failures_total"),
+			"Number of failed connection attempts to the instance",
+			[]string{"ssas_instance"},
+			nil,
+		)
+
+		c.connectionPerfDataCollectors, err = buildPerfDataCollectors[perfDataCounterValuesConnection](c.logger, c.ssasInstances, "Connection")
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorMemory) {
+		if err := collectPerfData(c.memoryPerfDataCollectors, func(instance string, data perfDataCounterValuesMemory) {
+			c.collectMemory(ch, instance, data)
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting memory metrics: %w", err))
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorProcessing) {
+		if err := collectPerfData(c.processingPerfDataCollectors, func(instance string, data perfDataCounterValuesProcessing) {
+			c.collectProcessing(ch, instance, data)
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting processing metrics: %w", err))
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorThreads) {
+		if err := collectPerfData(c.threadsPerfDataCollectors, func(instance string, data perfDataCounterValuesThreads) {
+			c.collectThreads(ch, instance, data)
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting threads metrics: %w", err))
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorConnection) {
+		if err := collectPerfData(c.connectionPerfDataCollectors, func(instance string, data perfDataCounterValuesConnection) {
+			c.collectConnection(ch, instance, data)
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting connection metrics: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectMemory(ch chan<- prometheus.Metric, instance string, data perfDataCounterValuesMemory) {
+	ch <- prometheus.MustNewConstMetric(
+		c.memoryLimitHardBytes,
+		prometheus.GaugeValue,
+		data.MemoryLimitHardKB*1024,
+		instance,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.memoryLimitHighBytes,
+		prometheus.GaugeValue,
+		data.MemoryLimitHighKB*1024,
+		instance,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.memoryLimitLowBytes,
+		prometheus.GaugeValue,
+		data.MemoryLimitLowKB*1024,
+		instance,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.memoryUsageBytes,
+		prometheus.GaugeValue,
+		data.MemoryUsageKB*1024,
+		instance,
+	)
+}
+
+func (c *Collector) collectProcessing(ch chan<- prometheus.Metric, instance string, data perfDataCounterValuesProcessing) {
+	ch <- prometheus.MustNewConstMetric(
+		c.processingRowsReadTotal,
+		prometheus.CounterValue,
+		data.RowsReadPerSec,
+		instance,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.processingRowsWrittenTotal,
+		prometheus.CounterValue,
+		data.RowsWrittenPerSec,
+		instance,
+	)
+}
+
+func (c *Collector) collectThreads(ch chan<- prometheus.Metric, instance string, data perfDataCounterValuesThreads) {
+	ch <- prometheus.MustNewConstMetric(
+		c.queryPoolBusyThreads,
+		prometheus.GaugeValue,
+		data.QueryPoolBusyThreads,
+		instance,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.queryPoolIdleThreads,
+		prometheus.GaugeValue,
+		data.QueryPoolIdleThreads,
+		instance,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.queryPoolJobQueueLength,
+		prometheus.GaugeValue,
+		data.QueryPoolJobQueueLength,
+		instance,
+	)
+}
+
+func (c *Collector) collectConnection(ch chan<- prometheus.Metric, instance string, data perfDataCounterValuesConnection) {
+	ch <- prometheus.MustNewConstMetric(
+		c.connections,
+		prometheus.GaugeValue,
+		data.CurrentConnections,
+		instance,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.userSessions,
+		prometheus.GaugeValue,
+		data.CurrentUserSessions,
+		instance,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.requestsTotal,
+		prometheus.CounterValue,
+		data.TotalRequests,
+		instance,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.failuresTotal,
+		prometheus.CounterValue,
+		data.TotalFailures,
+		instance,
+	)
+}
+
+// getSSASInstances reads the installed Analysis Services instances from the registry.
+// The value data contains the instance ID, e.g. MSAS16.MSSQLSERVER.
+func (c *Collector) getSSASInstances() ([]ssasInstance, error) {
+	regKey := `Software\Microsoft\Microsoft SQL Server\Instance Names\OLAP`
+
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, regKey, registry.QUERY_VALUE)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open registry to determine Analysis Services instances: %w", err)
+	}
+
+	defer func(key registry.Key) {
+		_ = key.Close()
+	}(k)
+
+	instanceNames, err := k.ReadValueNames(0)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read subkey names: %w", err)
+	}
+
+	ssasInstances := make([]ssasInstance, 0, len(instanceNames))
+
+	for _, instanceName := range instanceNames {
+		instanceID, _, err := k.GetStringValue(instanceName)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get instance info: %w", err)
+		}
+
+		perfObjectPrefix := "MSOLAP$" + instanceName
+		if instanceName == "MSSQLSERVER" {
+			perfObjectPrefix, _, _ = strings.Cut(instanceID, ".")
+		}
+
+		ssasInstances = append(ssasInstances, ssasInstance{
+			name:             instanceName,
+			perfObjectPrefix: perfObjectPrefix,
+		})
+	}
+
+	c.logger.Debug(fmt.Sprintf("detected Analysis Services instances: %#v", ssasInstances))
+
+	return ssasInstances, nil
+}
+
+// buildPerfDataCollectors creates a performance counter collector for the object of each instance.
+func buildPerfDataCollectors[T any](logger *slog.Logger, ssasInstances []ssasInstance, object string) (map[string]*pdh.Collector, error) {
+	perfDataCollectors := make(map[string]*pdh.Collector, len(ssasInstances))
+	errs := make([]error, 0, len(ssasInstances))
+
+	for _, instance := range ssasInstances {
+		perfDataCollector, err := pdh.NewCollector[T](logger, pdh.CounterTypeRaw, instance.perfObjectPrefix+":"+object, nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create %s collector for instance %s: %w", object, instance.name, err))
+
+			continue
+		}
+
+		perfDataCollectors[instance.name] = perfDataCollector
+	}
+
+	return perfDataCollectors, errors.Join(errs...)
+}
+
+// collectPerfData collects the performance counters of each instance and passes them to fn.
+func collectPerfData[T any](perfDataCollectors map[string]*pdh.Collector, fn func(instance string, data T)) error {
+	errs := make([]error, 0, len(perfDataCollectors))
+
+	var perfDataObject []T
+
+	for instance, perfDataCollector := range perfDataCollectors {
+		if err := perfDataCollector.Collect(&perfDataObject); err != nil {
+			errs = append(errs, fmt.Errorf("failed to collect metrics of instance %s: %w", instance, err))
+
+			continue
+		}
+
+		if len(perfDataObject) == 0 {
+			errs = append(errs, fmt.Errorf("failed to collect metrics of instance %s: %w", instance, types.ErrNoDataUnexpected))
+
+			continue
+		}
+
+		fn(instance, perfDataObject[0])
+	}
+
+	return errors.Join(errs...)
+}
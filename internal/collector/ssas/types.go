@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package ssas
+
+type perfDataCounterValuesMemory struct {
+	// MSAS<version>:Memory
+	MemoryLimitHardKB float64 `perfdata:"Memory Limit Hard KB"`
+	MemoryLimitHighKB float64 `perfdata:"Memory Limit High KB"`
+	MemoryLimitLowKB  float64 `perfdata:"Memory Limit Low KB"`
+	MemoryUsageKB     float64 `perfdata:"Memory Usage KB"`
+}
+
+type perfDataCounterValuesProcessing struct {
+	// MSAS<version>:Processing
+	RowsReadPerSec    float64 `perfdata:"Rows read/sec"`
+	RowsWrittenPerSec float64 `perfdata:"Rows written/sec"`
+}
+
+type perfDataCounterValuesThreads struct {
+	// MSAS<version>:Threads
+	QueryPoolBusyThreads    float64 `perfdata:"Query pool busy threads"`
+	QueryPoolIdleThreads    float64 `perfdata:"Query pool idle threads"`
+	QueryPoolJobQueueLength float64 `perfdata:"Query pool job queue length"`
+}
+
+type perfDataCounterValuesConnection struct {
+	// MSAS<version>:Connection
+	CurrentConnections  float64 `perfdata:"Current connections"`
+	CurrentUserSessions float64 `perfdata:"Current user sessions"`
+	TotalFailures       float64 `perfdata:"Total failures"`
+	TotalRequests       float64 `perfdata:"Total requests"`
+}
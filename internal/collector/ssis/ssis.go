@@ -0,0 +1,317 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package ssis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/odbc32"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	Name = "ssis"
+
+	queryTimeout = 10 * time.Second
+
+	executionsQuery = `SELECT status, COUNT(*) FROM SSISDB.catalog.executions GROUP BY status`
+
+	runningExecutionsQuery = `SELECT folder_name, project_name, package_name, COUNT(*)
+FROM SSISDB.catalog.executions
+WHERE status = 2
+GROUP BY folder_name, project_name, package_name`
+
+	// cleanupJobQuery returns whether the operation cleanup is enabled, and the outcome and age of the last run
+	// of the SQL Server Agent job that removes old operation and project version records from the catalog.
+	cleanupJobQuery = `SELECT p.property_value, last_run.run_status, DATEDIFF(SECOND, msdb.dbo.agent_datetime(last_run.run_date, last_run.run_time), GETDATE())
+FROM SSISDB.catalog.catalog_properties p
+OUTER APPLY (
+	SELECT TOP 1 h.run_status, h.run_date, h.run_time
+	FROM msdb.dbo.sysjobs j
+	INNER JOIN msdb.dbo.sysjobhistory h ON h.job_id = j.job_id
+	WHERE j.name = N'SSIS Server Maintenance Job' AND h.step_id = 0
+	ORDER BY h.instance_id DESC
+) last_run
+WHERE p.property_name = 'OPERATION_CLEANUP_ENABLED'`
+)
+
+type Config struct {
+	Server string `yaml:"server"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	Server: ".",
+}
+
+// executionStatuses maps the status column of catalog.executions to label values.
+//
+//nolint:gochecknoglobals
+var executionStatuses = map[string]string{
+	"1": "created",
+	"2": "running",
+	"3": "canceled",
+	"4": "failed",
+	"5": "pending",
+	"6": "ended_unexpectedly",
+	"7": "succeeded",
+	"8": "stopping",
+	"9": "completed",
+}
+
+// A Collector is a Prometheus Collector for SQL Server Integration Services metrics.
+// The metrics are read from the SSISDB catalog of the configured SQL Server instance.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	connectionString string
+
+	executions               *prometheus.Desc
+	runningExecutions        *prometheus.Desc
+	cleanupEnabled           *prometheus.Desc
+	cleanupJobLastRunSuccess *prometheus.Desc
+	cleanupJobLastRunAge     *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.Server == "" {
+		config.Server = ConfigDefaults.Server
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	app.Flag(
+		"collector.ssis.server",
+		"SQL Server instance hosting the SSISDB catalog, e.g. . for the local default instance or .\\NAME for a named instance.",
+	).Default(ConfigDefaults.Server).StringVar(&c.config.Server)
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.connectionString = fmt.Sprintf("Driver={SQL Server};Server=%s;Database=master;Trusted_Connection=yes;", c.config.Server)
+
+	c.executions = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "executions"),
+		"Number of package executions retained in the SSISDB catalog by status",
+		[]string{"status"},
+		nil,
+	)
+	c.runningExecutions = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "running_executions"),
+		"Number of currently running executions of the package",
+		[]string{"folder", "project", "package"},
+		nil,
+	)
+	c.cleanupEnabled = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cleanup_enabled"),
+		"1 if the operation cleanup of the SSISDB catalog is enabled, 0 otherwise",
+		nil,
+		nil,
+	)
+	c.cleanupJobLastRunSuccess = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cleanup_job_last_run_success"),
+		"1 if the last run of the SSIS Server Maintenance Job succeeded, 0 otherwise",
+		nil,
+		nil,
+	)
+	c.cleanupJobLastRunAge = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cleanup_job_last_run_age_seconds"),
+		"Seconds since the last run of the SSIS Server Maintenance Job",
+		nil,
+		nil,
+	)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	return c.CollectWithContext(context.Background(), ch)
+}
+
+// CollectWithContext sends the metric values for each metric
+// to the provided prometheus Metric channel. The queries are cancelled once ctx is done.
+func (c *Collector) CollectWithContext(ctx context.Context, ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if err := c.collectExecutions(ctx, ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting execution metrics: %w", err))
+	}
+
+	if err := c.collectRunningExecutions(ctx, ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting running execution metrics: %w", err))
+	}
+
+	if err := c.collectCleanupJob(ctx, ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting cleanup job metrics: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectExecutions(ctx context.Context, ch chan<- prometheus.Metric) error {
+	rows, err := odbc32.Query(ctx, c.connectionString, executionsQuery, queryTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to query catalog executions: %w", err)
+	}
+
+	counts := make(map[string]float64, len(executionStatuses))
+
+	for _, row := range rows {
+		if len(row) != 2 || row[0] == nil || row[1] == nil {
+			continue
+		}
+
+		count, err := strconv.ParseFloat(*row[1], 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse execution count: %w", err)
+		}
+
+		counts[*row[0]] = count
+	}
+
+	// Report all statuses, so rate and delta functions work without gaps.
+	for status, label := range executionStatuses {
+		ch <- prometheus.MustNewConstMetric(
+			c.executions,
+			prometheus.GaugeValue,
+			counts[status],
+			label,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectRunningExecutions(ctx context.Context, ch chan<- prometheus.Metric) error {
+	rows, err := odbc32.Query(ctx, c.connectionString, runningExecutionsQuery, queryTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to query running executions: %w", err)
+	}
+
+	for _, row := range rows {
+		if len(row) != 4 || row[0] == nil || row[1] == nil || row[2] == nil || row[3] == nil {
+			continue
+		}
+
+		count, err := strconv.ParseFloat(*row[3], 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse running execution count: %w", err)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.runningExecutions,
+			prometheus.GaugeValue,
+			count,
+			*row[0], *row[1], *row[2],
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectCleanupJob(ctx context.Context, ch chan<- prometheus.Metric) error {
+	rows, err := odbc32.Query(ctx, c.connectionString, cleanupJobQuery, queryTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to query cleanup job history: %w", err)
+	}
+
+	if len(rows) == 0 || len(rows[0]) != 3 {
+		return fmt.Errorf("failed to query cleanup job history: %w", types.ErrNoDataUnexpected)
+	}
+
+	row := rows[0]
+
+	if row[0] != nil {
+		cleanupEnabled := 0.0
+		if strings.EqualFold(*row[0], "TRUE") {
+			cleanupEnabled = 1.0
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.cleanupEnabled,
+			prometheus.GaugeValue,
+			cleanupEnabled,
+		)
+	}
+
+	// The job has no history if it never ran or SQL Server Agent is not running.
+	if row[1] == nil || row[2] == nil {
+		return nil
+	}
+
+	lastRunSuccess := 0.0
+	if *row[1] == "1" {
+		lastRunSuccess = 1.0
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.cleanupJobLastRunSuccess,
+		prometheus.GaugeValue,
+		lastRunSuccess,
+	)
+
+	lastRunAge, err := strconv.ParseFloat(*row[2], 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse cleanup job run age: %w", err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.cleanupJobLastRunAge,
+		prometheus.GaugeValue,
+		lastRunAge,
+	)
+
+	return nil
+}
@@ -0,0 +1,400 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package ssrs
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	Name = "ssrs"
+
+	subCollectorWebService = "web_service"
+	subCollectorService    = "service"
+)
+
+type Config struct {
+	CollectorsEnabled []string `yaml:"enabled"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	CollectorsEnabled: []string{
+		subCollectorWebService,
+		subCollectorService,
+	},
+}
+
+// webServiceObjects are the names of the web service performance object, newest first.
+// SQL Server Reporting Services 2016 and later and Power BI Report Server use the MSRS 2016 object.
+//
+//nolint:gochecknoglobals
+var webServiceObjects = []string{"MSRS 2016 Web Service", "MSRS 2011 Web Service"}
+
+// A Collector is a Prometheus Collector for SQL Server Reporting Services and Power BI Report Server metrics.
+type Collector struct {
+	config Config
+
+	webServicePerfDataCollector *pdh.Collector
+	webServicePerfDataObject    []perfDataCounterValuesWebService
+
+	servicePerfDataCollector *pdh.Collector
+	servicePerfDataObject    []perfDataCounterValuesService
+
+	activeSessions          *prometheus.Desc
+	cacheHitsTotal          *prometheus.Desc
+	cacheMissesTotal        *prometheus.Desc
+	processingFailuresTotal *prometheus.Desc
+	rejectedThreadsTotal    *prometheus.Desc
+	reportsExecutedTotal    *prometheus.Desc
+	requestsTotal           *prometheus.Desc
+
+	activeConnections          *prometheus.Desc
+	httpErrorsTotal            *prometheus.Desc
+	httpRequestsTotal          *prometheus.Desc
+	memoryPressureState        *prometheus.Desc
+	requestsExecuting          *prometheus.Desc
+	requestsNotAuthorizedTotal *prometheus.Desc
+	requestsRejectedTotal      *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.CollectorsEnabled == nil {
+		config.CollectorsEnabled = ConfigDefaults.CollectorsEnabled
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+	c.config.CollectorsEnabled = make([]string, 0)
+
+	var collectorsEnabled string
+
+	app.Flag(
+		"collector.ssrs.enabled",
+		"Comma-separated list of collectors to use. Defaults to all, if not specified.",
+	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+
+		return nil
+	})
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorWebService) {
+		c.webServicePerfDataCollector.Close()
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorService) {
+		c.servicePerfDataCollector.Close()
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	for _, collector := range c.config.CollectorsEnabled {
+		if !slices.Contains([]string{subCollectorWebService, subCollectorService}, collector) {
+			return fmt.Errorf("unknown sub collector: %s. Possible values: %s", collector,
+				strings.Join([]string{subCollectorWebService, subCollectorService}, ", "),
+			)
+		}
+	}
+
+	logger = logger.With(slog.String("collector", Name))
+
+	var err error
+
+	errs := make([]error, 0)
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorWebService) {
+		c.activeSessions = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "active_sessions"),
+			"Number of active sessions of the report server",
+			[]string{"ssrs_instance"},
+			nil,
+		)
+		c.cacheHitsTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "cache_hits_total"),
+			"Number of report requests that were served from the report cache",
+			[]string{"ssrs_instance"},
+			nil,
+		)
+		c.cacheMissesTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "cache_misses_total"),
+			"Number of report requests that could not be served from the report cache",
+			[]string{"ssrs_instance"},
+			nil,
+		)
+		c.processingFailuresTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "processing_failures_total"),
+			"Number of errors of the report processor",
+			[]string{"ssrs_instance"},
+			nil,
+		)
+		c.rejectedThreadsTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "rejected_threads_total"),
+			"Number of requests for data processing threads that were rejected and processed synchronously",
+			[]string{"ssrs_instance"},
+			nil,
+		)
+		c.reportsExecutedTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "reports_executed_total"),
+			"Number of reports that were executed successfully",
+			[]string{"ssrs_instance"},
+			nil,
+		)
+		c.requestsTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "requests_total"),
+			"Number of requests made to the report server web service",
+			[]string{"ssrs_instance"},
+			nil,
+		)
+
+		// Only one of the web service objects exists, depending on the installed version.
+		for _, object := range webServiceObjects {
+			c.webServicePerfDataCollector, err = pdh.NewCollector[perfDataCounterValuesWebService](logger, pdh.CounterTypeRaw, object, pdh.InstancesAll)
+			if err == nil {
+				break
+			}
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create Reporting Services Web Service collector: %w", err))
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorService) {
+		c.activeConnections = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "active_connections"),
+			"Number of connections currently active on the report server",
+			nil,
+			nil,
+		)
+		c.httpErrorsTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "http_errors_total"),
+			"Number of HTTP requests to the report server that failed with an error",
+			nil,
+			nil,
+		)
+		c.httpRequestsTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "http_requests_total"),
+			"Number of HTTP requests processed by the report server",
+			nil,
+			nil,
+		)
+		c.memoryPressureState = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "memory_pressure_state"),
+			"Memory pressure state of the report server: 1 (none), 2 (low), 3 (medium), 4 (high), 5 (maximum)",
+			nil,
+			nil,
+		)
+		c.requestsExecuting = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "requests_executing"),
+			"Number of requests currently executing on the report server",
+			nil,
+			nil,
+		)
+		c.requestsNotAuthorizedTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "requests_not_authorized_total"),
+			"Number of requests to the report server that failed with HTTP 401",
+			nil,
+			nil,
+		)
+		c.requestsRejectedTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "requests_rejected_total"),
+			"Number of requests that were rejected by the report server because of insufficient resources",
+			nil,
+			nil,
+		)
+
+		c.servicePerfDataCollector, err = pdh.NewCollector[perfDataCounterValuesService](logger, pdh.CounterTypeRaw, "ReportServer:Service", nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create ReportServer:Service collector: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorWebService) {
+		if err := c.collectWebService(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting Reporting Services Web Service metrics: %w", err))
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorService) {
+		if err := c.collectService(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting ReportServer:Service metrics: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectWebService(ch chan<- prometheus.Metric) error {
+	err := c.webServicePerfDataCollector.Collect(&c.webServicePerfDataObject)
+	if err != nil {
+		return fmt.Errorf("failed to collect Reporting Services Web Service metrics: %w", err)
+	}
+
+	for _, data := range c.webServicePerfDataObject {
+		ch <- prometheus.MustNewConstMetric(
+			c.activeSessions,
+			prometheus.GaugeValue,
+			data.ActiveSessions,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.cacheHitsTotal,
+			prometheus.CounterValue,
+			data.TotalCacheHits,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.cacheMissesTotal,
+			prometheus.CounterValue,
+			data.TotalCacheMisses,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.processingFailuresTotal,
+			prometheus.CounterValue,
+			data.TotalProcessingFailures,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.rejectedThreadsTotal,
+			prometheus.CounterValue,
+			data.TotalRejectedThreads,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.reportsExecutedTotal,
+			prometheus.CounterValue,
+			data.TotalReportsExecuted,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.requestsTotal,
+			prometheus.CounterValue,
+			data.TotalRequests,
+			data.Name,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectService(ch chan<- prometheus.Metric) error {
+	err := c.servicePerfDataCollector.Collect(&c.servicePerfDataObject)
+	if err != nil {
+		return fmt.Errorf("failed to collect ReportServer:Service metrics: %w", err)
+	}
+
+	if len(c.servicePerfDataObject) == 0 {
+		return fmt.Errorf("failed to collect ReportServer:Service metrics: %w", types.ErrNoDataUnexpected)
+	}
+
+	data := c.servicePerfDataObject[0]
+
+	ch <- prometheus.MustNewConstMetric(
+		c.activeConnections,
+		prometheus.GaugeValue,
+		data.ActiveConnections,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.httpErrorsTotal,
+		prometheus.CounterValue,
+		data.ErrorsTotal,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.httpRequestsTotal,
+		prometheus.CounterValue,
+		data.RequestsTotal,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.memoryPressureState,
+		prometheus.GaugeValue,
+		data.MemoryPressureState,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.requestsExecuting,
+		prometheus.GaugeValue,
+		data.RequestsExecuting,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.requestsNotAuthorizedTotal,
+		prometheus.CounterValue,
+		data.RequestsNotAuthorized,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.requestsRejectedTotal,
+		prometheus.CounterValue,
+		data.RequestsRejected,
+	)
+
+	return nil
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package ssrs
+
+type perfDataCounterValuesWebService struct {
+	Name string
+
+	// MSRS 2016 Web Service, MSRS 2011 Web Service
+	ActiveSessions          float64 `perfdata:"Active Sessions"`
+	TotalCacheHits          float64 `perfdata:"Total Cache Hits"`
+	TotalCacheMisses        float64 `perfdata:"Total Cache Misses"`
+	TotalProcessingFailures float64 `perfdata:"Total Processing Failures"`
+	TotalRejectedThreads    float64 `perfdata:"Total Rejected Threads"`
+	TotalReportsExecuted    float64 `perfdata:"Total Reports Executed"`
+	TotalRequests           float64 `perfdata:"Total Requests"`
+}
+
+type perfDataCounterValuesService struct {
+	// ReportServer:Service
+	ActiveConnections     float64 `perfdata:"Active Connections"`
+	ErrorsTotal           float64 `perfdata:"Errors Total"`
+	MemoryPressureState   float64 `perfdata:"Memory Pressure State"`
+	RequestsExecuting     float64 `perfdata:"Requests Executing"`
+	RequestsNotAuthorized float64 `perfdata:"Requests Not Authorized"`
+	RequestsRejected      float64 `perfdata:"Requests Rejected"`
+	RequestsTotal         float64 `perfdata:"Requests Total"`
+}
@@ -0,0 +1,426 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package storage_hotplug
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	Name = "storage_hotplug"
+
+	kindDisk    = "disk"
+	kindOptical = "optical"
+
+	ioctlStorageQueryProperty = 0x2D1400 // IOCTL_STORAGE_QUERY_PROPERTY
+	ioctlStorageCheckVerify2  = 0x2D0800 // IOCTL_STORAGE_CHECK_VERIFY2
+)
+
+type Config struct {
+	PollInterval time.Duration `yaml:"poll-interval"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	PollInterval: 10 * time.Second,
+}
+
+//nolint:gochecknoglobals
+var (
+	diskDriveQuery  = utils.Must(mi.NewQuery("SELECT Index, PNPDeviceID FROM Win32_DiskDrive"))
+	cdromDriveQuery = utils.Must(mi.NewQuery("SELECT Drive, PNPDeviceID FROM Win32_CDROMDrive"))
+
+	// busTypes are the names of the STORAGE_BUS_TYPE values, see winioctl.h.
+	busTypes = []string{
+		"unknown", "scsi", "atapi", "ata", "1394", "ssa", "fibre_channel", "usb", "raid", "iscsi",
+		"sas", "sata", "sd", "mmc", "virtual", "file_backed_virtual", "spaces", "nvme", "scm", "ufs",
+	}
+
+	removableDriveTypes = map[uint32]string{
+		windows.DRIVE_REMOVABLE: "removable",
+		windows.DRIVE_CDROM:     "cdrom",
+	}
+)
+
+// A Collector is a Prometheus Collector for the arrival and removal of disks and optical drives, e.g. USB drives
+// attached to a server, and for the removable and optical volumes that are currently attached.
+// The disks are polled in the background every PollInterval, so a disk that is attached and removed
+// between two polls is not counted.
+type Collector struct {
+	config    Config
+	logger    *slog.Logger
+	miSession *mi.Session
+
+	ctxCancelFn context.CancelFunc
+
+	// mu protects the devices and counters of the poller.
+	mu sync.Mutex
+	// devices are the attached disks and optical drives by PnP device ID. It is nil until the first poll finished.
+	devices  map[string]device
+	arrivals map[device]float64
+	removals map[device]float64
+	pollTime time.Time
+
+	diskArrivalsTotal    *prometheus.Desc
+	diskRemovalsTotal    *prometheus.Desc
+	disks                *prometheus.Desc
+	removableVolumeMedia *prometheus.Desc
+	pollTimestampSeconds *prometheus.Desc
+}
+
+// device is the kind and the bus type of a disk or optical drive, which are the labels of its metrics.
+type device struct {
+	kind    string
+	busType string
+}
+
+type win32DiskDrive struct {
+	Index       uint32 `mi:"Index"`
+	PNPDeviceID string `mi:"PNPDeviceID"`
+}
+
+type win32CDROMDrive struct {
+	Drive       string `mi:"Drive"`
+	PNPDeviceID string `mi:"PNPDeviceID"`
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.PollInterval == 0 {
+		config.PollInterval = ConfigDefaults.PollInterval
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	app.Flag(
+		"collector.storage_hotplug.poll-interval",
+		"Interval of polling the attached disks and optical drives for arrivals and removals.",
+	).Default(ConfigDefaults.PollInterval.String()).DurationVar(&c.config.PollInterval)
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+// LastSuccess returns the time of the last successful poll, see collector.BackgroundCollector.
+func (c *Collector) LastSuccess() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.pollTime
+}
+
+func (c *Collector) Close() error {
+	if c.ctxCancelFn != nil {
+		c.ctxCancelFn()
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	if c.config.PollInterval <= 0 {
+		return fmt.Errorf("invalid poll interval %s, expected a positive duration", c.config.PollInterval)
+	}
+
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.miSession = miSession
+
+	c.diskArrivalsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "disk_arrivals_total"),
+		"Number of disks and optical drives that were attached since windows_exporter started, by kind (disk, optical) and bus type",
+		[]string{"kind", "bus_type"},
+		nil,
+	)
+	c.diskRemovalsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "disk_removals_total"),
+		"Number of disks and optical drives that were removed since windows_exporter started, by kind (disk, optical) and bus type",
+		[]string{"kind", "bus_type"},
+		nil,
+	)
+	c.disks = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "disks"),
+		"Number of attached disks and optical drives by kind (disk, optical) and bus type",
+		[]string{"kind", "bus_type"},
+		nil,
+	)
+	c.removableVolumeMedia = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "removable_volume_media_present"),
+		"Attached removable or optical volume, 1 if a medium is inserted",
+		[]string{"volume", "drive_type", "bus_type"},
+		nil,
+	)
+	c.pollTimestampSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "poll_timestamp_seconds"),
+		"Timestamp of the last successful poll of the attached disks and optical drives",
+		nil,
+		nil,
+	)
+
+	c.arrivals = make(map[device]float64)
+	c.removals = make(map[device]float64)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.ctxCancelFn = cancel
+
+	go c.schedulePoll(ctx)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	c.collectDevices(ch)
+	c.collectRemovableVolumes(ch)
+
+	return nil
+}
+
+func (c *Collector) collectDevices(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The metrics are missing until the first poll finished.
+	if c.devices == nil {
+		return
+	}
+
+	attached := make(map[device]float64)
+	for _, d := range c.devices {
+		attached[d]++
+	}
+
+	for d, count := range attached {
+		ch <- prometheus.MustNewConstMetric(
+			c.disks,
+			prometheus.GaugeValue,
+			count,
+			d.kind, d.busType,
+		)
+	}
+
+	for d, count := range c.arrivals {
+		ch <- prometheus.MustNewConstMetric(
+			c.diskArrivalsTotal,
+			prometheus.CounterValue,
+			count,
+			d.kind, d.busType,
+		)
+	}
+
+	for d, count := range c.removals {
+		ch <- prometheus.MustNewConstMetric(
+			c.diskRemovalsTotal,
+			prometheus.CounterValue,
+			count,
+			d.kind, d.busType,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.pollTimestampSeconds,
+		prometheus.GaugeValue,
+		float64(c.pollTime.Unix()),
+	)
+}
+
+// collectRemovableVolumes reports the volumes with a drive letter on removable media or optical drives.
+func (c *Collector) collectRemovableVolumes(ch chan<- prometheus.Metric) {
+	drives, err := windows.GetLogicalDrives()
+	if err != nil {
+		return
+	}
+
+	for i := range 26 {
+		if drives&(1<<i) == 0 {
+			continue
+		}
+
+		volume := string(rune('A'+i)) + ":"
+
+		root, err := windows.UTF16PtrFromString(volume + `\`)
+		if err != nil {
+			continue
+		}
+
+		driveType, ok := removableDriveTypes[windows.GetDriveType(root)]
+		if !ok {
+			continue
+		}
+
+		busType, mediaPresent := queryDevice(`\\.\` + volume)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.removableVolumeMedia,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(mediaPresent),
+			volume, driveType, busType,
+		)
+	}
+}
+
+func (c *Collector) schedulePoll(ctx context.Context) {
+	for {
+		if err := c.poll(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			c.logger.LogAttrs(ctx, slog.LevelWarn, "failed to poll the attached disks",
+				slog.Any("err", err),
+			)
+		}
+
+		select {
+		case <-time.After(c.config.PollInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// poll compares the attached disks and optical drives with the previous poll. The first poll only records the devices.
+func (c *Collector) poll(ctx context.Context) error {
+	var disks []win32DiskDrive
+	if err := c.miSession.QueryContext(ctx, &disks, mi.NamespaceRootCIMv2, diskDriveQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	var cdroms []win32CDROMDrive
+	if err := c.miSession.QueryContext(ctx, &cdroms, mi.NamespaceRootCIMv2, cdromDriveQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	devices := make(map[string]device, len(disks)+len(cdroms))
+
+	for _, disk := range disks {
+		devices[disk.PNPDeviceID] = c.device(disk.PNPDeviceID, kindDisk, `\\.\PhysicalDrive`+strconv.FormatUint(uint64(disk.Index), 10))
+	}
+
+	for _, cdrom := range cdroms {
+		devices[cdrom.PNPDeviceID] = c.device(cdrom.PNPDeviceID, kindOptical, `\\.\`+cdrom.Drive)
+	}
+
+	if c.devices != nil {
+		for id, d := range devices {
+			if _, ok := c.devices[id]; !ok {
+				c.arrivals[d]++
+			}
+		}
+
+		for id, d := range c.devices {
+			if _, ok := devices[id]; !ok {
+				c.removals[d]++
+			}
+		}
+	}
+
+	c.devices = devices
+	c.pollTime = time.Now()
+
+	return nil
+}
+
+// device returns the labels of the device. The bus type of a known device is reused, since a removed
+// device can't be queried. Must be called with mu held.
+func (c *Collector) device(id, kind, path string) device {
+	if d, ok := c.devices[id]; ok {
+		return d
+	}
+
+	busType, _ := queryDevice(path)
+
+	return device{kind: kind, busType: busType}
+}
+
+// queryDevice returns the bus type of the device and whether a medium is inserted. The device is opened without
+// access rights, which is sufficient for the queries and doesn't require administrative privileges.
+func queryDevice(path string) (string, bool) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return busTypes[0], false
+	}
+
+	mode := uint32(windows.FILE_SHARE_READ | windows.FILE_SHARE_WRITE | windows.FILE_SHARE_DELETE)
+
+	handle, err := windows.CreateFile(pathPtr, 0, mode, nil, windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		return busTypes[0], false
+	}
+
+	defer func(fd windows.Handle) {
+		_ = windows.Close(fd)
+	}(handle)
+
+	// STORAGE_PROPERTY_QUERY with PropertyId StorageDeviceProperty and QueryType PropertyStandardQuery.
+	query := make([]byte, 12)
+	descriptor := make([]byte, 1024)
+
+	var bytesReturned uint32
+
+	busType := busTypes[0]
+
+	err = windows.DeviceIoControl(handle, ioctlStorageQueryProperty, &query[0], uint32(len(query)), &descriptor[0], uint32(len(descriptor)), &bytesReturned, nil)
+	// BusType is at offset 28 of STORAGE_DEVICE_DESCRIPTOR.
+	if err == nil && bytesReturned >= 32 {
+		if value := binary.LittleEndian.Uint32(descriptor[28:]); int(value) < len(busTypes) {
+			busType = busTypes[value]
+		}
+	}
+
+	var mediaChangeCount uint32
+
+	err = windows.DeviceIoControl(handle, ioctlStorageCheckVerify2, nil, 0, (*byte)(unsafe.Pointer(&mediaChangeCount)), uint32(unsafe.Sizeof(mediaChangeCount)), &bytesReturned, nil)
+
+	return busType, err == nil
+}
@@ -26,16 +26,24 @@ import (
 	"github.com/prometheus-community/windows_exporter/internal/headers/kernel32"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
 	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/sampler"
 	"github.com/prometheus-community/windows_exporter/internal/types"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 const Name = "system"
 
-type Config struct{}
+type Config struct {
+	QueueLengthSampleInterval time.Duration `yaml:"queue-length-sample-interval"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	QueueLengthSampleInterval: 0,
+}
 
 //nolint:gochecknoglobals
-var ConfigDefaults = Config{}
+var queueLengthBuckets = []float64{0, 1, 2, 4, 8, 16, 32, 64, 128}
 
 // A Collector is a Prometheus Collector for WMI metrics.
 type Collector struct {
@@ -46,6 +54,10 @@ type Collector struct {
 	perfDataCollector *pdh.Collector
 	perfDataObject    []perfDataCounterValues
 
+	queueLengthPerfDataCollector *pdh.Collector
+	queueLengthSampler           *sampler.Sampler
+	queueLengthSampled           sampler.Descs
+
 	contextSwitchesTotal     *prometheus.Desc
 	exceptionDispatchesTotal *prometheus.Desc
 	processorQueueLength     *prometheus.Desc
@@ -68,8 +80,17 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(_ *kingpin.Application) *Collector {
-	return &Collector{}
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	app.Flag(
+		"collector.system.queue-length-sample-interval",
+		"Interval of sampling the processor queue length between scrapes, e.g. 1s. 0 disables sampling.",
+	).Default(ConfigDefaults.QueueLengthSampleInterval.String()).DurationVar(&c.config.QueueLengthSampleInterval)
+
+	return c
 }
 
 func (c *Collector) GetName() string {
@@ -77,6 +98,8 @@ func (c *Collector) GetName() string {
 }
 
 func (c *Collector) Close() error {
+	c.queueLengthSampler.Close()
+	c.queueLengthPerfDataCollector.Close()
 	c.perfDataCollector.Close()
 
 	return nil
@@ -142,6 +165,49 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		return fmt.Errorf("failed to create System collector: %w", err)
 	}
 
+	if c.config.QueueLengthSampleInterval > 0 {
+		if err := c.buildQueueLengthSampler(logger); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildQueueLengthSampler samples the processor queue length with a separate query, since the sampler runs
+// concurrently with the scrapes.
+func (c *Collector) buildQueueLengthSampler(logger *slog.Logger) error {
+	c.queueLengthSampled = sampler.NewDescs(
+		prometheus.BuildFQName(types.Namespace, Name, "processor_queue_length_sampled"),
+		"Length of processor queue, sampled every --collector.system.queue-length-sample-interval",
+		nil,
+	)
+
+	var err error
+
+	c.queueLengthPerfDataCollector, err = pdh.NewCollector[queueLengthCounterValues](logger.With(slog.String("collector", Name)), pdh.CounterTypeRaw, "System", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create System collector: %w", err)
+	}
+
+	var perfDataObject []queueLengthCounterValues
+
+	c.queueLengthSampler, err = sampler.New(logger.With(slog.String("collector", Name)), sampler.Options{
+		Interval: c.config.QueueLengthSampleInterval,
+		Buckets:  queueLengthBuckets,
+	}, func() ([]sampler.Sample, error) {
+		if err := c.queueLengthPerfDataCollector.Collect(&perfDataObject); err != nil {
+			return nil, fmt.Errorf("failed to collect System metrics: %w", err)
+		} else if len(perfDataObject) == 0 {
+			return nil, fmt.Errorf("failed to collect System metrics: %w", types.ErrNoDataUnexpected)
+		}
+
+		return []sampler.Sample{{Value: perfDataObject[0].ProcessorQueueLength}}, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start processor queue length sampler: %w", err)
+	}
+
 	return nil
 }
 
@@ -206,5 +272,9 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		float64(4294967295),
 	)
 
+	if c.queueLengthSampler != nil {
+		c.queueLengthSampler.Collect(ch, c.queueLengthSampled)
+	}
+
 	return nil
 }
@@ -25,3 +25,7 @@ type perfDataCounterValues struct {
 	Processes                 float64 `perfdata:"Processes"`
 	Threads                   float64 `perfdata:"Threads"`
 }
+
+type queueLengthCounterValues struct {
+	ProcessorQueueLength float64 `perfdata:"Processor Queue Length"`
+}
@@ -41,6 +41,7 @@ const (
 
 	subCollectorMetrics          = "metrics"
 	subCollectorConnectionsState = "connections_state"
+	subCollectorETW              = "etw"
 )
 
 type Config struct {
@@ -58,6 +59,7 @@ var ConfigDefaults = Config{
 // A Collector is a Prometheus Collector for WMI Win32_PerfRawData_Tcpip_TCPv{4,6} metrics.
 type Collector struct {
 	config Config
+	logger *slog.Logger
 
 	perfDataCollector4 *pdh.Collector
 	perfDataCollector6 *pdh.Collector
@@ -74,6 +76,10 @@ type Collector struct {
 	segmentsRetransmittedTotal *prometheus.Desc
 	segmentsSentTotal          *prometheus.Desc
 	connectionsStateCount      *prometheus.Desc
+
+	kernelNetworkEvents *kernelNetworkEvents
+	retransmitEvents    *prometheus.Desc
+	connectionEvents    *prometheus.Desc
 }
 
 func New(config *Config) *Collector {
@@ -124,10 +130,20 @@ func (c *Collector) Close() error {
 		c.perfDataCollector6.Close()
 	}
 
+	if c.kernelNetworkEvents != nil {
+		if err := c.kernelNetworkEvents.subscription.Close(); err != nil {
+			return fmt.Errorf("failed to close ETW subscription: %w", err)
+		}
+
+		c.kernelNetworkEvents = nil
+	}
+
 	return nil
 }
 
 func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
 	labels := []string{"af"}
 
 	c.connectionFailures = prometheus.NewDesc(
@@ -207,6 +223,25 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		}
 	}
 
+	c.retransmitEvents = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "etw_retransmit_events_total"),
+		"Number of TCP retransmissions since windows_exporter started, from the Microsoft-Windows-Kernel-Network ETW provider",
+		labels,
+		nil,
+	)
+	c.connectionEvents = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "etw_connection_events_total"),
+		"Number of TCP connection events since windows_exporter started, from the Microsoft-Windows-Kernel-Network ETW provider",
+		[]string{"af", "event"},
+		nil,
+	)
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorETW) && c.kernelNetworkEvents == nil {
+		if err := c.subscribeKernelNetwork(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to subscribe to TCP events: %w", err))
+		}
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -227,6 +262,10 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		}
 	}
 
+	if c.kernelNetworkEvents != nil {
+		c.collectETW(ch)
+	}
+
 	return errors.Join(errs...)
 }
 
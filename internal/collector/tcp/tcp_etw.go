@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package tcp
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus-community/windows_exporter/internal/etw"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+// Event IDs of the Microsoft-Windows-Kernel-Network provider. The IPv6 events are offset by kernelNetworkIPv6Offset.
+const (
+	kernelNetworkEventConnect    = 12
+	kernelNetworkEventDisconnect = 13
+	kernelNetworkEventRetransmit = 14
+	kernelNetworkEventAccept     = 15
+	kernelNetworkEventReconnect  = 16
+
+	kernelNetworkIPv6Offset = 16
+)
+
+//nolint:gochecknoglobals
+var (
+	kernelNetworkProvider = etw.Provider{
+		GUID:  windows.GUID{Data1: 0x7dd42a49, Data2: 0x5329, Data3: 0x4832, Data4: [8]byte{0x8d, 0xfd, 0x43, 0xd9, 0x79, 0x15, 0x3a, 0x88}},
+		Level: etw.LevelInformation,
+		// The send and receive events are not selected, since they are logged for each TCP segment.
+		EventIDs: []uint16{
+			kernelNetworkEventConnect, kernelNetworkEventDisconnect, kernelNetworkEventRetransmit, kernelNetworkEventAccept, kernelNetworkEventReconnect,
+			kernelNetworkEventConnect + kernelNetworkIPv6Offset, kernelNetworkEventDisconnect + kernelNetworkIPv6Offset,
+			kernelNetworkEventRetransmit + kernelNetworkIPv6Offset, kernelNetworkEventAccept + kernelNetworkIPv6Offset,
+			kernelNetworkEventReconnect + kernelNetworkIPv6Offset,
+		},
+	}
+
+	kernelNetworkConnectionEvents = map[uint16]string{
+		kernelNetworkEventConnect:    "connect",
+		kernelNetworkEventDisconnect: "disconnect",
+		kernelNetworkEventAccept:     "accept",
+		kernelNetworkEventReconnect:  "reconnect",
+	}
+)
+
+// kernelNetworkEvents counts the TCP events of the Microsoft-Windows-Kernel-Network provider
+// by event ID, indexed by [IPv6][event ID].
+type kernelNetworkEvents struct {
+	subscription *etw.Subscription
+	counts       [2][kernelNetworkEventReconnect + 1]atomic.Uint64
+}
+
+func (c *Collector) subscribeKernelNetwork() error {
+	events := &kernelNetworkEvents{}
+
+	subscription, err := etw.Subscribe(c.logger, kernelNetworkProvider, events.handleEvent)
+	if err != nil {
+		return err
+	}
+
+	events.subscription = subscription
+	c.kernelNetworkEvents = events
+
+	return nil
+}
+
+func (e *kernelNetworkEvents) handleEvent(record *etw.EventRecord) {
+	eventID := record.EventHeader.EventDescriptor.Id
+
+	var ipv6 int
+	if eventID > kernelNetworkEventReconnect {
+		ipv6 = 1
+		eventID -= kernelNetworkIPv6Offset
+	}
+
+	if eventID < kernelNetworkEventConnect || eventID > kernelNetworkEventReconnect {
+		return
+	}
+
+	e.counts[ipv6][eventID].Add(1)
+}
+
+func (c *Collector) collectETW(ch chan<- prometheus.Metric) {
+	for ipv6, af := range []string{ipAddressFamilyIPv4, ipAddressFamilyIPv6} {
+		counts := &c.kernelNetworkEvents.counts[ipv6]
+
+		ch <- prometheus.MustNewConstMetric(
+			c.retransmitEvents,
+			prometheus.CounterValue,
+			float64(counts[kernelNetworkEventRetransmit].Load()),
+			af,
+		)
+
+		for eventID, event := range kernelNetworkConnectionEvents {
+			ch <- prometheus.MustNewConstMetric(
+				c.connectionEvents,
+				prometheus.CounterValue,
+				float64(counts[eventID].Load()),
+				af,
+				event,
+			)
+		}
+	}
+}
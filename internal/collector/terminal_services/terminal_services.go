@@ -23,6 +23,7 @@ import (
 	"log/slog"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus-community/windows_exporter/internal/headers/wtsapi32"
@@ -82,21 +83,25 @@ type Collector struct {
 
 	hServer windows.Handle
 
-	sessionInfo                 *prometheus.Desc
-	connectionBrokerPerformance *prometheus.Desc
-	handleCount                 *prometheus.Desc
-	pageFaultsPerSec            *prometheus.Desc
-	pageFileBytes               *prometheus.Desc
-	pageFileBytesPeak           *prometheus.Desc
-	percentCPUTime              *prometheus.Desc
-	poolNonPagedBytes           *prometheus.Desc
-	poolPagedBytes              *prometheus.Desc
-	privateBytes                *prometheus.Desc
-	threadCount                 *prometheus.Desc
-	virtualBytes                *prometheus.Desc
-	virtualBytesPeak            *prometheus.Desc
-	workingSet                  *prometheus.Desc
-	workingSetPeak              *prometheus.Desc
+	sessionInfo                  *prometheus.Desc
+	sessionIdleSeconds           *prometheus.Desc
+	sessionDisconnectedSeconds   *prometheus.Desc
+	sessionsIdleDuration         *prometheus.Desc
+	sessionsDisconnectedDuration *prometheus.Desc
+	connectionBrokerPerformance  *prometheus.Desc
+	handleCount                  *prometheus.Desc
+	pageFaultsPerSec             *prometheus.Desc
+	pageFileBytes                *prometheus.Desc
+	pageFileBytesPeak            *prometheus.Desc
+	percentCPUTime               *prometheus.Desc
+	poolNonPagedBytes            *prometheus.Desc
+	poolPagedBytes               *prometheus.Desc
+	privateBytes                 *prometheus.Desc
+	threadCount                  *prometheus.Desc
+	virtualBytes                 *prometheus.Desc
+	virtualBytesPeak             *prometheus.Desc
+	workingSet                   *prometheus.Desc
+	workingSetPeak               *prometheus.Desc
 }
 
 func New(config *Config) *Collector {
@@ -143,6 +148,30 @@ func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
 		[]string{"session_name", "user", "host", "state", "id"},
 		nil,
 	)
+	c.sessionIdleSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "session_idle_seconds"),
+		"Seconds since the last user input in the session",
+		[]string{"session_name", "user", "id"},
+		nil,
+	)
+	c.sessionDisconnectedSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "session_disconnected_seconds"),
+		"Seconds since the session was disconnected. Only reported for disconnected sessions",
+		[]string{"session_name", "user", "id"},
+		nil,
+	)
+	c.sessionsIdleDuration = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "sessions_idle_duration_seconds"),
+		"Distribution of the idle time of user sessions",
+		nil,
+		nil,
+	)
+	c.sessionsDisconnectedDuration = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "sessions_disconnected_duration_seconds"),
+		"Distribution of the disconnected time of disconnected user sessions",
+		nil,
+		nil,
+	)
 	c.connectionBrokerPerformance = prometheus.NewDesc(
 		prometheus.BuildFQName(types.Namespace, Name, "connection_broker_performance_total"),
 		"The total number of connections handled by the Connection Brokers since the service started.",
@@ -450,6 +479,9 @@ func (c *Collector) collectWTSSessions(ch chan<- prometheus.Metric) error {
 		return fmt.Errorf("failed to enumerate WTS sessions: %w", err)
 	}
 
+	idleDurations := newDurationHistogram()
+	disconnectedDurations := newDurationHistogram()
+
 	for _, session := range sessions {
 		// only connect metrics for remote named sessions
 		n := strings.ReplaceAll(session.SessionName, "#", " ")
@@ -462,6 +494,10 @@ func (c *Collector) collectWTSSessions(ch chan<- prometheus.Metric) error {
 			userName = fmt.Sprintf("%s\\%s", session.DomainName, session.UserName)
 		}
 
+		if session.UserName != "" {
+			c.collectWTSSessionDurations(ch, session, n, userName, idleDurations, disconnectedDurations)
+		}
+
 		for stateID, stateName := range wtsapi32.WTSSessionStates {
 			isState := 0.0
 			if session.State == stateID {
@@ -481,5 +517,114 @@ func (c *Collector) collectWTSSessions(ch chan<- prometheus.Metric) error {
 		}
 	}
 
+	ch <- prometheus.MustNewConstHistogram(
+		c.sessionsIdleDuration,
+		idleDurations.count,
+		idleDurations.sum,
+		idleDurations.buckets,
+	)
+
+	ch <- prometheus.MustNewConstHistogram(
+		c.sessionsDisconnectedDuration,
+		disconnectedDurations.count,
+		disconnectedDurations.sum,
+		disconnectedDurations.buckets,
+	)
+
 	return nil
 }
+
+// collectWTSSessionDurations exposes the idle and disconnected duration of a user session.
+// Both are derived from the server's current time as reported by WTSQuerySessionInformation,
+// so the values are not affected by clock differences between the exporter and the session.
+func (c *Collector) collectWTSSessionDurations(
+	ch chan<- prometheus.Metric,
+	session wtsapi32.WTSSession,
+	sessionName, userName string,
+	idleDurations, disconnectedDurations *durationHistogram,
+) {
+	times, err := wtsapi32.WTSQuerySessionTimes(c.hServer, session.SessionID)
+	if err != nil {
+		c.logger.Debug("failed to query WTS session times",
+			slog.String("session_name", sessionName),
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	sessionID := strconv.Itoa(int(session.SessionID))
+
+	if !times.LastInputTime.IsZero() {
+		idle := times.CurrentTime.Sub(times.LastInputTime)
+		idleDurations.observe(idle)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.sessionIdleSeconds,
+			prometheus.GaugeValue,
+			idle.Seconds(),
+			sessionName,
+			userName,
+			sessionID,
+		)
+	}
+
+	if wtsapi32.WTSSessionStates[session.State] == "disconnected" && !times.DisconnectTime.IsZero() {
+		disconnected := times.CurrentTime.Sub(times.DisconnectTime)
+		disconnectedDurations.observe(disconnected)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.sessionDisconnectedSeconds,
+			prometheus.GaugeValue,
+			disconnected.Seconds(),
+			sessionName,
+			userName,
+			sessionID,
+		)
+	}
+}
+
+// sessionDurationBuckets are aligned with common RDS session time limit policies.
+//
+//nolint:gochecknoglobals
+var sessionDurationBuckets = []float64{
+	(5 * time.Minute).Seconds(),
+	(15 * time.Minute).Seconds(),
+	(30 * time.Minute).Seconds(),
+	time.Hour.Seconds(),
+	(2 * time.Hour).Seconds(),
+	(4 * time.Hour).Seconds(),
+	(8 * time.Hour).Seconds(),
+	(24 * time.Hour).Seconds(),
+}
+
+type durationHistogram struct {
+	count   uint64
+	sum     float64
+	buckets map[float64]uint64
+}
+
+func newDurationHistogram() *durationHistogram {
+	h := &durationHistogram{
+		buckets: make(map[float64]uint64, len(sessionDurationBuckets)),
+	}
+
+	for _, bucket := range sessionDurationBuckets {
+		h.buckets[bucket] = 0
+	}
+
+	return h
+}
+
+func (h *durationHistogram) observe(d time.Duration) {
+	seconds := d.Seconds()
+
+	h.count++
+	h.sum += seconds
+
+	for _, bucket := range sessionDurationBuckets {
+		if seconds <= bucket {
+			h.buckets[bucket]++
+		}
+	}
+}
@@ -0,0 +1,233 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package textfile
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// openMetricsTypes maps the metric types of OpenMetrics to the types of the text format.
+//
+//nolint:gochecknoglobals
+var openMetricsTypes = map[string]string{
+	"counter":        "counter",
+	"gauge":          "gauge",
+	"histogram":      "histogram",
+	"gaugehistogram": "histogram",
+	"summary":        "summary",
+	"info":           "gauge",
+	"stateset":       "gauge",
+	"unknown":        "untyped",
+}
+
+// openMetricsSuffixes are the suffixes of the samples of a metric family in OpenMetrics.
+//
+//nolint:gochecknoglobals
+var openMetricsSuffixes = []string{"_total", "_created", "_bucket", "_count", "_sum", "_gcount", "_gsum", "_info", ""}
+
+// convertOpenMetrics converts content in the OpenMetrics format to the text format, which is parsed by expfmt.
+// Content is detected as OpenMetrics by the # EOF line, # UNIT lines, types that only exist in OpenMetrics
+// and exemplars. Other content is returned unchanged.
+//
+// Exemplars, units and the _created samples are dropped, since the text format can't represent them.
+//
+// 📑 https://github.com/prometheus/OpenMetrics/blob/main/specification/OpenMetrics.md
+func convertOpenMetrics(content []byte) ([]byte, error) {
+	lines := strings.Split(string(content), "\n")
+	types := make(map[string]string)
+	openMetrics := false
+	eof := -1
+
+	for i, line := range lines {
+		switch {
+		case line == "# EOF":
+			if eof < 0 {
+				eof = i
+			}
+
+			openMetrics = true
+		case strings.HasPrefix(line, "# TYPE "):
+			fields := strings.Fields(line)
+			if len(fields) != 4 {
+				continue
+			}
+
+			types[fields[2]] = fields[3]
+
+			switch fields[3] {
+			case "gaugehistogram", "info", "stateset", "unknown":
+				openMetrics = true
+			}
+		case strings.HasPrefix(line, "# UNIT "):
+			openMetrics = true
+		case line != "" && !strings.HasPrefix(line, "#"):
+			if _, rest := splitSample(line); strings.Contains(rest, " # {") {
+				openMetrics = true
+			}
+		}
+	}
+
+	if !openMetrics {
+		return content, nil
+	}
+
+	if eof < 0 {
+		return nil, errors.New("OpenMetrics content without # EOF, the file may be truncated")
+	}
+
+	for _, line := range lines[eof+1:] {
+		if strings.TrimSpace(line) != "" {
+			return nil, fmt.Errorf("content after # EOF: %q", line)
+		}
+	}
+
+	var converted strings.Builder
+
+	for i, line := range lines[:eof] {
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "# TYPE "):
+			fields := strings.Fields(line)
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("line %d: invalid TYPE line %q", i+1, line)
+			}
+
+			textType, ok := openMetricsTypes[fields[3]]
+			if !ok {
+				return nil, fmt.Errorf("line %d: unknown metric type %q", i+1, fields[3])
+			}
+
+			fmt.Fprintf(&converted, "# TYPE %s %s\n", familyName(fields[2], fields[3]), textType)
+		case strings.HasPrefix(line, "# HELP "):
+			name, help, _ := strings.Cut(strings.TrimPrefix(line, "# HELP "), " ")
+
+			fmt.Fprintf(&converted, "# HELP %s %s\n", familyName(name, types[name]), help)
+		case strings.HasPrefix(line, "#"):
+			// # UNIT lines.
+			continue
+		default:
+			sample, ok, err := convertSample(line, types)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+
+			if ok {
+				converted.WriteString(sample)
+				converted.WriteByte('\n')
+			}
+		}
+	}
+
+	return []byte(converted.String()), nil
+}
+
+// familyName returns the name of the metric family in the text format, which includes the suffix of the samples
+// of counters and info metrics.
+func familyName(name, metricType string) string {
+	switch metricType {
+	case "counter":
+		return name + "_total"
+	case "info":
+		return name + "_info"
+	default:
+		return name
+	}
+}
+
+// convertSample converts a sample line. It returns false for samples that are dropped.
+func convertSample(line string, types map[string]string) (string, bool, error) {
+	series, rest := splitSample(line)
+
+	// Exemplars follow the value and the timestamp.
+	if i := strings.Index(rest, " # "); i >= 0 {
+		rest = rest[:i]
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return "", false, fmt.Errorf("invalid sample %q", line)
+	}
+
+	name, labels, _ := strings.Cut(series, "{")
+	if labels != "" {
+		labels = "{" + labels
+	}
+
+	for _, suffix := range openMetricsSuffixes {
+		family, ok := strings.CutSuffix(name, suffix)
+		if !ok {
+			continue
+		}
+
+		metricType, ok := types[family]
+		if !ok {
+			continue
+		}
+
+		switch {
+		case suffix == "_created" && metricType != "gauge" && metricType != "unknown":
+			return "", false, nil
+		case suffix == "_gcount" && metricType == "gaugehistogram":
+			name = family + "_count"
+		case suffix == "_gsum" && metricType == "gaugehistogram":
+			name = family + "_sum"
+		}
+
+		break
+	}
+
+	sample := name + labels + " " + fields[0]
+
+	// Timestamps are seconds in OpenMetrics and milliseconds in the text format.
+	if len(fields) == 2 {
+		timestamp, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid timestamp %q", fields[1])
+		}
+
+		sample += " " + strconv.FormatInt(int64(math.Round(timestamp*1000)), 10)
+	}
+
+	return sample, true, nil
+}
+
+// splitSample splits a sample line into the metric name with the labels and the rest of the line.
+// Label values may contain spaces, braces and escaped quotes.
+func splitSample(line string) (string, string) {
+	inQuotes := false
+
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case inQuotes && c == '\\':
+			i++
+		case c == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && c == '}':
+			return line[:i+1], line[i+1:]
+		case !inQuotes && c == ' ' && !strings.Contains(line[:i], "{"):
+			return line[:i], line[i:]
+		}
+	}
+
+	return line, ""
+}
@@ -18,6 +18,8 @@
 package textfile
 
 import (
+	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
@@ -27,6 +29,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
@@ -57,7 +60,19 @@ type Collector struct {
 	// Only set for testing to get predictable output.
 	mTime *float64
 
-	modTimeDesc *prometheus.Desc
+	// cache holds the metric families of the files by path, so unchanged files are not parsed again.
+	cacheMu sync.Mutex
+	cache   map[string]cachedFile
+
+	modTimeDesc    *prometheus.Desc
+	parseErrorDesc *prometheus.Desc
+}
+
+// cachedFile are the metric families of a file, which are valid as long as the file keeps its mtime and size.
+type cachedFile struct {
+	modTime        time.Time
+	size           int64
+	metricFamilies []*dto.MetricFamily
 }
 
 func New(config *Config) *Collector {
@@ -85,7 +100,7 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 
 	app.Flag(
 		"collector.textfile.directories",
-		"Directory or Directories to read text files with metrics from.",
+		"Comma-separated list of directories to read text files with metrics from. Directories may contain glob patterns, e.g. 'C:\\agents\\*\\metrics'.",
 	).Default(strings.Join(ConfigDefaults.TextFileDirectories, ",")).StringVar(&textFileDirectories)
 
 	app.Action(func(*kingpin.ParseContext) error {
@@ -117,6 +132,15 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		nil,
 	)
 
+	c.parseErrorDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, "textfile", "parse_error"),
+		"1 if the textfile couldn't be read or parsed, 0 otherwise.",
+		[]string{"file"},
+		nil,
+	)
+
+	c.cache = make(map[string]cachedFile)
+
 	return nil
 }
 
@@ -314,6 +338,7 @@ func (cr carriageReturnFilteringReader) Read(p []byte) (int, error) {
 // Collect implements the Collector interface.
 func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 	mTimes := map[string]time.Time{}
+	parseErrors := map[string]bool{}
 
 	// Create empty metricFamily slice here and append parsedFamilies to it inside the loop.
 	// Once loop is complete, raise error if any duplicates are present.
@@ -321,27 +346,35 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 	var metricFamilies []*dto.MetricFamily
 
 	errs := make([]error, 0)
+	cache := make(map[string]cachedFile)
+
+	directories, err := expandDirectories(c.config.TextFileDirectories)
+	if err != nil {
+		errs = append(errs, err)
+	}
 
 	// Iterate over files and accumulate their metrics.
-	for _, directory := range c.config.TextFileDirectories {
+	for _, directory := range directories {
 		err := filepath.WalkDir(directory, func(path string, dirEntry os.DirEntry, err error) error {
 			if err != nil {
 				return fmt.Errorf("error reading directory: %w", err)
 			}
 
-			if !dirEntry.IsDir() && strings.HasSuffix(dirEntry.Name(), ".prom") {
+			if !dirEntry.IsDir() && isTextFile(dirEntry.Name()) {
 				c.logger.Debug("Processing file: " + path)
 
-				families_array, err := scrapeFile(path, c.logger)
+				fileInfo, err := os.Stat(path)
 				if err != nil {
-					errs = append(errs, fmt.Errorf("error scraping file %q: %w", path, err))
+					errs = append(errs, fmt.Errorf("error reading file info %q: %w", path, err))
+					parseErrors[dirEntry.Name()] = true
 
 					return nil
 				}
 
-				fileInfo, err := os.Stat(path)
+				families_array, err := c.scrapeCachedFile(path, fileInfo)
 				if err != nil {
-					errs = append(errs, fmt.Errorf("error reading file info %q: %w", path, err))
+					errs = append(errs, fmt.Errorf("error scraping file %q: %w", path, err))
+					parseErrors[dirEntry.Name()] = true
 
 					return nil
 				}
@@ -354,6 +387,11 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 
 				mTimes[fileInfo.Name()] = fileInfo.ModTime()
 
+				if _, ok := parseErrors[fileInfo.Name()]; !ok {
+					parseErrors[fileInfo.Name()] = false
+				}
+
+				cache[path] = cachedFile{modTime: fileInfo.ModTime(), size: fileInfo.Size(), metricFamilies: families_array}
 				metricFamilies = append(metricFamilies, families_array...)
 			}
 
@@ -364,7 +402,13 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		}
 	}
 
+	// Files that were removed or failed are dropped from the cache.
+	c.cacheMu.Lock()
+	c.cache = cache
+	c.cacheMu.Unlock()
+
 	c.exportMTimes(mTimes, ch)
+	c.exportParseErrors(parseErrors, ch)
 
 	// If duplicates are detected across *multiple* files, return error.
 	if duplicateMetricEntry(metricFamilies) {
@@ -378,28 +422,83 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 	return errors.Join(errs...)
 }
 
+// expandDirectories expands the glob patterns of the directories. Patterns without matches are ignored,
+// e.g. if an agent has not created its directory yet.
+func expandDirectories(directories []string) ([]string, error) {
+	expanded := make([]string, 0, len(directories))
+	errs := make([]error, 0)
+
+	for _, directory := range directories {
+		if !strings.ContainsAny(directory, "*?[") {
+			expanded = append(expanded, directory)
+
+			continue
+		}
+
+		matches, err := filepath.Glob(directory)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid textfile directory pattern %q: %w", directory, err))
+
+			continue
+		}
+
+		expanded = append(expanded, matches...)
+	}
+
+	return expanded, errors.Join(errs...)
+}
+
+// isTextFile reports whether the file contains metrics, either uncompressed or compressed with gzip.
+func isTextFile(name string) bool {
+	return strings.HasSuffix(name, ".prom") || strings.HasSuffix(name, ".prom.gz")
+}
+
+// scrapeCachedFile returns the metric families of the file from the cache if the file is unchanged since the last collection.
+func (c *Collector) scrapeCachedFile(path string, fileInfo os.FileInfo) ([]*dto.MetricFamily, error) {
+	c.cacheMu.Lock()
+	cached, ok := c.cache[path]
+	c.cacheMu.Unlock()
+
+	if ok && cached.modTime.Equal(fileInfo.ModTime()) && cached.size == fileInfo.Size() {
+		return cached.metricFamilies, nil
+	}
+
+	return scrapeFile(path, c.logger)
+}
+
+func (c *Collector) exportParseErrors(parseErrors map[string]bool, ch chan<- prometheus.Metric) {
+	// Sorting is needed for predictable output comparison in tests.
+	filenames := make([]string, 0, len(parseErrors))
+	for filename := range parseErrors {
+		filenames = append(filenames, filename)
+	}
+
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		value := 0.0
+		if parseErrors[filename] {
+			value = 1.0
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.parseErrorDesc, prometheus.GaugeValue, value, filename)
+	}
+}
+
 func scrapeFile(path string, logger *slog.Logger) ([]*dto.MetricFamily, error) {
-	file, err := os.Open(path)
+	content, err := readFile(path, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	parser := expfmt.NewTextParser(model.UTF8Validation)
-
-	r, encoding := utfbom.Skip(carriageReturnFilteringReader{r: file})
-	if err = checkBOM(encoding); err != nil {
+	content, err = convertOpenMetrics(content)
+	if err != nil {
 		return nil, err
 	}
 
-	parsedFamilies, err := parser.TextToMetricFamilies(r)
-
-	closeErr := file.Close()
-	if closeErr != nil {
-		logger.Warn("error closing file "+path,
-			slog.Any("err", closeErr),
-		)
-	}
+	parser := expfmt.NewTextParser(model.UTF8Validation)
 
+	parsedFamilies, err := parser.TextToMetricFamilies(bytes.NewReader(content))
 	if err != nil {
 		return nil, err
 	}
@@ -430,6 +529,47 @@ func scrapeFile(path string, logger *slog.Logger) ([]*dto.MetricFamily, error) {
 	return families_array, nil
 }
 
+// readFile returns the content of the file without BOM and carriage returns. Files with the suffix .gz are decompressed.
+func readFile(path string, logger *slog.Logger) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := file.Close(); err != nil {
+			logger.Warn("error closing file "+path,
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	var reader io.Reader = file
+
+	if strings.HasSuffix(path, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress file: %w", err)
+		}
+
+		defer gzipReader.Close()
+
+		reader = gzipReader
+	}
+
+	r, encoding := utfbom.Skip(carriageReturnFilteringReader{r: reader})
+	if err = checkBOM(encoding); err != nil {
+		return nil, err
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return content, nil
+}
+
 func checkBOM(encoding utfbom.Encoding) error {
 	if encoding == utfbom.Unknown || encoding == utfbom.UTF8 {
 		return nil
@@ -179,3 +179,66 @@ func TestDuplicateMetricEntry(t *testing.T) {
 		t.Errorf("Unexpected duplicate found in differentValues")
 	}
 }
+
+func TestConvertOpenMetrics(t *testing.T) {
+	t.Parallel()
+
+	content := `# TYPE job_runs counter
+# HELP job_runs Runs of the \"batch\" job.
+job_runs_total{job="a b {c}"} 3 # {trace_id="abc"} 1.0 1700000000.5
+job_runs_created{job="a b {c}"} 1700000000
+# TYPE job info
+job_info{version="1.2"} 1
+# TYPE job_duration_seconds gaugehistogram
+# UNIT job_duration_seconds seconds
+job_duration_seconds_bucket{le="+Inf"} 2
+job_duration_seconds_gcount 2
+job_duration_seconds_gsum 1.5
+# TYPE job_state stateset
+job_state{job_state="running"} 1
+# TYPE job_last_run_seconds gauge
+job_last_run_seconds 1700000000 1700000000.001
+# EOF
+`
+
+	expected := `# TYPE job_runs_total counter
+# HELP job_runs_total Runs of the \"batch\" job.
+job_runs_total{job="a b {c}"} 3
+# TYPE job_info gauge
+job_info{version="1.2"} 1
+# TYPE job_duration_seconds histogram
+job_duration_seconds_bucket{le="+Inf"} 2
+job_duration_seconds_count 2
+job_duration_seconds_sum 1.5
+# TYPE job_state gauge
+job_state{job_state="running"} 1
+# TYPE job_last_run_seconds gauge
+job_last_run_seconds 1700000000 1700000000001
+`
+
+	converted, err := convertOpenMetrics([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(converted) != expected {
+		t.Errorf("Unexpected output %q", converted)
+	}
+
+	// The text format is returned unchanged.
+	text := "# TYPE job_runs_total counter\njob_runs_total 3\n"
+
+	converted, err = convertOpenMetrics([]byte(text))
+	if err != nil || string(converted) != text {
+		t.Errorf("Unexpected output %q, %v", converted, err)
+	}
+
+	for _, invalid := range []string{
+		"# TYPE job info\njob_info 1\n",
+		"# TYPE job info\njob_info 1\n# EOF\njob_info 1\n",
+	} {
+		if _, err := convertOpenMetrics([]byte(invalid)); err == nil {
+			t.Errorf("Missing expected error for %q", invalid)
+		}
+	}
+}
@@ -110,3 +110,41 @@ func TestDuplicateFileName(t *testing.T) {
 	require.Contains(t, got, "file")
 	require.NotContains(t, got, "sub_file")
 }
+
+//nolint:paralleltest
+func TestOpenMetricsAndGzip(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	textFileCollector := textfile.New(&textfile.Config{
+		TextFileDirectories: []string{baseDir + "/open*"},
+	})
+
+	collectors := collector.New(map[string]collector.Collector{textfile.Name: textFileCollector})
+	require.NoError(t, collectors.Build(t.Context(), logger))
+
+	metrics := make(chan prometheus.Metric)
+	got := ""
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- textFileCollector.Collect(metrics)
+
+		close(metrics)
+	}()
+
+	for val := range metrics {
+		var metric dto.Metric
+
+		err := val.Write(&metric)
+		require.NoError(t, err)
+
+		got += val.Desc().String() + metric.String()
+	}
+
+	require.NoError(t, <-errCh)
+
+	require.Contains(t, got, `fqName: "batch_job_runs_total"`)
+	require.Contains(t, got, `fqName: "batch_job_records"`)
+	require.NotContains(t, got, "batch_job_runs_created")
+	require.Contains(t, got, `fqName: "windows_textfile_parse_error"`)
+}
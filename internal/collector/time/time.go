@@ -42,10 +42,15 @@ const (
 	collectorSystemTime  = "system_time"
 	collectorClockSource = "clock_source"
 	collectorNTP         = "ntp"
+	collectorNTPServer   = "ntp_server"
+	collectorClockJump   = "clock_jump"
+	collectorSkew        = "skew"
 )
 
 type Config struct {
 	CollectorsEnabled []string `yaml:"enabled"`
+	// ClockJumpThreshold is the minimum size of a step of the system clock that is counted as jump.
+	ClockJumpThreshold time.Duration `yaml:"clock-jump-threshold"`
 }
 
 //nolint:gochecknoglobals
@@ -54,7 +59,9 @@ var ConfigDefaults = Config{
 		collectorSystemTime,
 		collectorClockSource,
 		collectorNTP,
+		collectorClockJump,
 	},
+	ClockJumpThreshold: time.Second,
 }
 
 // Collector is a Prometheus Collector for Perflib counter metrics.
@@ -78,6 +85,18 @@ type Collector struct {
 	ntpRoundTripDelay               *prometheus.Desc
 	ntpServerIncomingRequestsTotal  *prometheus.Desc
 	ntpServerOutgoingResponsesTotal *prometheus.Desc
+
+	ntpServerEnabled        *prometheus.Desc
+	ntpServerStratum        *prometheus.Desc
+	ntpServerRootDelay      *prometheus.Desc
+	ntpServerRootDispersion *prometheus.Desc
+	ntpServerSynchronized   *prometheus.Desc
+
+	clockJumpWatcher *clockJumpWatcher
+	clockJumpsTotal  *prometheus.Desc
+	clockLastJump    *prometheus.Desc
+
+	skew *prometheus.Desc
 }
 
 func New(config *Config) *Collector {
@@ -89,6 +108,10 @@ func New(config *Config) *Collector {
 		config.CollectorsEnabled = ConfigDefaults.CollectorsEnabled
 	}
 
+	if config.ClockJumpThreshold == 0 {
+		config.ClockJumpThreshold = ConfigDefaults.ClockJumpThreshold
+	}
+
 	c := &Collector{
 		config: *config,
 	}
@@ -109,6 +132,11 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 		"Comma-separated list of collectors to use. Defaults to all, if not specified. ntp may not available on all systems.",
 	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
 
+	app.Flag(
+		"collector.time.clock-jump-threshold",
+		"Minimum size of a step of the system clock that is counted by the clock_jump collector.",
+	).Default(c.config.ClockJumpThreshold.String()).DurationVar(&c.config.ClockJumpThreshold)
+
 	app.Action(func(*kingpin.ParseContext) error {
 		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
 
@@ -127,6 +155,11 @@ func (c *Collector) Close() error {
 		c.perfDataCollector.Close()
 	}
 
+	if c.clockJumpWatcher != nil {
+		c.clockJumpWatcher.stop()
+		c.clockJumpWatcher = nil
+	}
+
 	return nil
 }
 
@@ -134,7 +167,7 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 	c.logger = logger.With(slog.String("collector", Name))
 
 	for _, collector := range c.config.CollectorsEnabled {
-		if !slices.Contains([]string{collectorSystemTime, collectorClockSource, collectorNTP}, collector) {
+		if !slices.Contains([]string{collectorSystemTime, collectorClockSource, collectorNTP, collectorNTPServer, collectorClockJump, collectorSkew}, collector) {
 			return fmt.Errorf("unknown collector: %s", collector)
 		}
 	}
@@ -203,6 +236,22 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 		nil,
 	)
 
+	if slices.Contains(c.config.CollectorsEnabled, collectorNTPServer) {
+		c.buildNTPServer()
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, collectorClockJump) {
+		if c.config.ClockJumpThreshold <= 0 {
+			return errors.New("clock-jump-threshold must be greater than 0")
+		}
+
+		c.buildClockJump()
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, collectorSkew) {
+		c.buildSkew()
+	}
+
 	if slices.Contains(c.config.CollectorsEnabled, collectorNTP) {
 		var err error
 
@@ -238,6 +287,22 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
 		}
 	}
 
+	if slices.Contains(c.config.CollectorsEnabled, collectorNTPServer) {
+		if err := c.collectNTPServer(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting ntp server metrics: %w", err))
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, collectorClockJump) {
+		c.collectClockJump(ch)
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, collectorSkew) {
+		if err := c.collectSkew(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting clock skew metrics: %w", err))
+		}
+	}
+
 	return errors.Join(errs...)
 }
 
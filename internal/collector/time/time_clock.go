@@ -0,0 +1,233 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package time
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/netapi32"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+// clockJumpInterval is the interval in which the system clock is compared with the monotonic clock.
+const clockJumpInterval = time.Second
+
+// clockJumpWatcher detects steps of the system clock, e.g. if W32Time corrects a large offset or
+// an administrator sets the time. A step is the difference between the elapsed system time
+// and the elapsed monotonic time since the previous check.
+type clockJumpWatcher struct {
+	threshold time.Duration
+
+	mu       sync.Mutex
+	forward  float64
+	backward float64
+	// lastJump is the size of the last step in seconds, negative for backward steps.
+	lastJump float64
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+func newClockJumpWatcher(threshold time.Duration) *clockJumpWatcher {
+	w := &clockJumpWatcher{
+		threshold: threshold,
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+func (w *clockJumpWatcher) run() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(clockJumpInterval)
+	defer ticker.Stop()
+
+	previous := time.Now()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		w.observe(now.Round(0).Sub(previous.Round(0)) - now.Sub(previous))
+		previous = now
+	}
+}
+
+// observe counts jump as step if it exceeds the threshold.
+func (w *clockJumpWatcher) observe(jump time.Duration) {
+	if jump.Abs() < w.threshold {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if jump > 0 {
+		w.forward++
+	} else {
+		w.backward++
+	}
+
+	w.lastJump = jump.Seconds()
+}
+
+func (w *clockJumpWatcher) values() (float64, float64, float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.forward, w.backward, w.lastJump
+}
+
+func (w *clockJumpWatcher) stop() {
+	close(w.stopCh)
+	<-w.doneCh
+}
+
+func (c *Collector) buildClockJump() {
+	c.clockJumpsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "clock_jumps_total"),
+		"Number of steps of the system clock larger than the threshold since the exporter started.",
+		[]string{"direction"},
+		nil,
+	)
+	c.clockLastJump = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "clock_last_jump_seconds"),
+		"Size of the last step of the system clock larger than the threshold, negative for backward steps. 0 if no step was detected.",
+		nil,
+		nil,
+	)
+
+	c.clockJumpWatcher = newClockJumpWatcher(c.config.ClockJumpThreshold)
+}
+
+func (c *Collector) collectClockJump(ch chan<- prometheus.Metric) {
+	forward, backward, lastJump := c.clockJumpWatcher.values()
+
+	ch <- prometheus.MustNewConstMetric(
+		c.clockJumpsTotal,
+		prometheus.CounterValue,
+		forward,
+		"forward",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.clockJumpsTotal,
+		prometheus.CounterValue,
+		backward,
+		"backward",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.clockLastJump,
+		prometheus.GaugeValue,
+		lastJump,
+	)
+}
+
+func (c *Collector) buildSkew() {
+	c.skew = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "skew_seconds"),
+		"Offset of the time source of W32Time to the system clock, in seconds. Positive if the system clock is behind.",
+		[]string{"source"},
+		nil,
+	)
+}
+
+// collectSkew queries the current time source of W32Time with NTP, e.g. the domain controller of the domain hierarchy.
+// Unlike windows_time_computed_time_offset_seconds, the offset is measured on each scrape and has a sign.
+func (c *Collector) collectSkew(ch chan<- prometheus.Metric) error {
+	source, err := timeSource()
+	if err != nil {
+		return err
+	}
+
+	// The clock is not synchronized, so there is no source to compare with.
+	if source == "" {
+		return nil
+	}
+
+	response, err := queryNTPServer(net.JoinHostPort(source, "123"))
+	if err != nil {
+		return fmt.Errorf("failed to query time source %s: %w", source, err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.skew,
+		prometheus.GaugeValue,
+		response.offset,
+		source,
+	)
+
+	return nil
+}
+
+// timeSource returns the host W32Time synchronizes with. With the domain hierarchy, this is the domain controller
+// that DsGetDcName locates as time server, like W32Time does. Otherwise it is the first peer of NtpServer.
+// An empty host is returned if W32Time doesn't synchronize the clock.
+func timeSource() (string, error) {
+	keyPath := `SYSTEM\CurrentControlSet\Services\W32Time\Parameters`
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.QUERY_VALUE)
+	if err != nil {
+		return "", fmt.Errorf("failed to open registry key: %w", err)
+	}
+
+	defer key.Close()
+
+	syncType, _, err := key.GetStringValue("Type")
+	if err != nil {
+		return "", fmt.Errorf("failed to read 'Type' value: %w", err)
+	}
+
+	switch syncType {
+	case "NT5DS", "AllSync":
+		dc, err := netapi32.DsGetDcName(netapi32.DS_TIMESERV_REQUIRED | netapi32.DS_GOOD_TIMESERV_PREFERRED | netapi32.DS_RETURN_DNS_NAME)
+		if err != nil {
+			return "", fmt.Errorf("failed to locate time server of the domain: %w", err)
+		}
+
+		return dc.DomainControllerName, nil
+	case "NTP":
+		peers, _, err := key.GetStringValue("NtpServer")
+		if err != nil {
+			return "", fmt.Errorf("failed to read 'NtpServer' value: %w", err)
+		}
+
+		// Peers are separated by spaces and may have flags, e.g. "time.windows.com,0x9".
+		peer, _, _ := strings.Cut(strings.TrimSpace(peers), " ")
+		peer, _, _ = strings.Cut(peer, ",")
+
+		return peer, nil
+	default:
+		return "", nil
+	}
+}
@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package time
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	ntpServerRegistryKey = `SYSTEM\CurrentControlSet\Services\W32Time\TimeProviders\NtpServer`
+
+	ntpServerAddress = "127.0.0.1:123"
+	ntpServerTimeout = time.Second
+
+	ntpPacketSize = 48
+
+	// ntpClientRequest is the first byte of an NTP request: leap indicator 0, version 4, mode 3 (client).
+	ntpClientRequest = 0<<6 | 4<<3 | 3
+	ntpModeServer    = 4
+
+	// ntpLeapAlarm is the leap indicator of a server that is not synchronized.
+	ntpLeapAlarm = 3
+
+	// ntpEpochOffset is the number of seconds between the NTP epoch 1900-01-01 and the Unix epoch.
+	ntpEpochOffset = 2208988800
+)
+
+// ntpServerResponse contains the fields of an NTP response that describe the quality of the time offered by the server.
+type ntpServerResponse struct {
+	leapIndicator  uint8
+	stratum        uint8
+	rootDelay      float64
+	rootDispersion float64
+	// offset is the offset of the server clock to the local clock, in seconds. It is positive if the local clock is behind.
+	offset float64
+}
+
+func (c *Collector) buildNTPServer() {
+	c.ntpServerEnabled = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "ntp_server_enabled"),
+		"1 if the NTP server provider of W32Time is enabled, 0 otherwise",
+		nil,
+		nil,
+	)
+	c.ntpServerStratum = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "ntp_server_stratum"),
+		"Stratum offered by the NTP server to its clients",
+		nil,
+		nil,
+	)
+	c.ntpServerRootDelay = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "ntp_server_root_delay_seconds"),
+		"Root delay offered by the NTP server to its clients, in seconds",
+		nil,
+		nil,
+	)
+	c.ntpServerRootDispersion = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "ntp_server_root_dispersion_seconds"),
+		"Root dispersion offered by the NTP server to its clients, in seconds",
+		nil,
+		nil,
+	)
+	c.ntpServerSynchronized = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "ntp_server_synchronized"),
+		"1 if the NTP server reports itself as synchronized to its clients, 0 otherwise",
+		nil,
+		nil,
+	)
+}
+
+// collectNTPServer queries the local NTP server in the same way a client does. The response contains
+// the stratum, root delay and root dispersion offered to clients, which are not exposed as performance counters.
+func (c *Collector) collectNTPServer(ch chan<- prometheus.Metric) error {
+	enabled, err := ntpServerEnabled()
+	if err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.ntpServerEnabled,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(enabled),
+	)
+
+	if !enabled {
+		return nil
+	}
+
+	response, err := queryNTPServer(ntpServerAddress)
+	if err != nil {
+		return fmt.Errorf("failed to query NTP server %s: %w", ntpServerAddress, err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.ntpServerStratum,
+		prometheus.GaugeValue,
+		float64(response.stratum),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.ntpServerRootDelay,
+		prometheus.GaugeValue,
+		response.rootDelay,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.ntpServerRootDispersion,
+		prometheus.GaugeValue,
+		response.rootDispersion,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.ntpServerSynchronized,
+		prometheus.GaugeValue,
+		utils.BoolToFloat(response.leapIndicator != ntpLeapAlarm && response.stratum > 0 && response.stratum < 16),
+	)
+
+	return nil
+}
+
+func ntpServerEnabled() (bool, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, ntpServerRegistryKey, registry.QUERY_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to open registry key %s: %w", ntpServerRegistryKey, err)
+	}
+
+	defer key.Close()
+
+	enabled, _, err := key.GetIntegerValue("Enabled")
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to read 'Enabled' value: %w", err)
+	}
+
+	return enabled != 0, nil
+}
+
+func queryNTPServer(address string) (ntpServerResponse, error) {
+	conn, err := net.DialTimeout("udp", address, ntpServerTimeout)
+	if err != nil {
+		return ntpServerResponse{}, err
+	}
+
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(ntpServerTimeout)); err != nil {
+		return ntpServerResponse{}, err
+	}
+
+	request := make([]byte, ntpPacketSize)
+	request[0] = ntpClientRequest
+
+	// The transmit timestamp of the request is returned as originate timestamp of the response.
+	originate := time.Now()
+	binary.BigEndian.PutUint64(request[40:48], toNTPTimestamp(originate))
+
+	if _, err := conn.Write(request); err != nil {
+		return ntpServerResponse{}, err
+	}
+
+	response := make([]byte, ntpPacketSize)
+
+	n, err := conn.Read(response)
+	if err != nil {
+		return ntpServerResponse{}, err
+	}
+
+	destination := time.Now()
+
+	if n < ntpPacketSize {
+		return ntpServerResponse{}, fmt.Errorf("short NTP response: %d bytes", n)
+	}
+
+	if mode := response[0] & 0x7; mode != ntpModeServer {
+		return ntpServerResponse{}, fmt.Errorf("unexpected NTP mode %d", mode)
+	}
+
+	if binary.BigEndian.Uint64(response[24:32]) != toNTPTimestamp(originate) {
+		return ntpServerResponse{}, errors.New("NTP response doesn't match the request")
+	}
+
+	// The offset is computed like in RFC 5905 from the receive and transmit timestamps of the server.
+	// The monotonic clock is used for the round trip, so a clock step during the request doesn't distort the offset.
+	receive := fromNTPTimestamp(binary.BigEndian.Uint64(response[32:40]))
+	transmit := fromNTPTimestamp(binary.BigEndian.Uint64(response[40:48]))
+	roundTrip := destination.Sub(originate)
+	offset := (receive.Sub(originate.Round(0)) + transmit.Sub(originate.Round(0).Add(roundTrip))) / 2
+
+	// Root delay and root dispersion use the NTP short format, 16 bit seconds and 16 bit fraction.
+	return ntpServerResponse{
+		leapIndicator:  response[0] >> 6,
+		stratum:        response[1],
+		rootDelay:      float64(binary.BigEndian.Uint32(response[4:8])) / (1 << 16),
+		rootDispersion: float64(binary.BigEndian.Uint32(response[8:12])) / (1 << 16),
+		offset:         offset.Seconds(),
+	}, nil
+}
+
+// toNTPTimestamp converts t to the NTP timestamp format, 32 bit seconds since 1900 and 32 bit fraction.
+func toNTPTimestamp(t time.Time) uint64 {
+	seconds := uint64(t.Unix() + ntpEpochOffset)
+	fraction := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+
+	return seconds<<32 | fraction
+}
+
+func fromNTPTimestamp(timestamp uint64) time.Time {
+	seconds := int64(timestamp>>32) - ntpEpochOffset
+	nanoseconds := int64(timestamp&0xffffffff) * int64(time.Second) >> 32
+
+	return time.Unix(seconds, nanoseconds)
+}
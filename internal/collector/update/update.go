@@ -40,7 +40,7 @@ const Name = "update"
 
 type Config struct {
 	Online         bool          `yaml:"online"`
-	ScrapeInterval time.Duration `yaml:"scrape_interval"`
+	ScrapeInterval time.Duration `yaml:"scrape-interval"`
 }
 
 //nolint:gochecknoglobals
@@ -63,6 +63,7 @@ type Collector struct {
 	logger *slog.Logger
 
 	metricsBuf []prometheus.Metric
+	fetchTime  time.Time
 
 	pendingUpdate              *prometheus.Desc
 	pendingUpdateLastPublished *prometheus.Desc
@@ -100,6 +101,14 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 	return c
 }
 
+// LastSuccess returns the time of the last successful update query, see collector.BackgroundCollector.
+func (c *Collector) LastSuccess() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.fetchTime
+}
+
 func (c *Collector) Close() error {
 	c.ctxCancelFn()
 
@@ -284,6 +293,7 @@ func (c *Collector) scheduleUpdateStatus(ctx context.Context, logger *slog.Logge
 
 		c.mu.Lock()
 		c.metricsBuf = metricsBuf
+		c.fetchTime = time.Now()
 		c.mu.Unlock()
 
 		select {
@@ -0,0 +1,336 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package update_policy
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "update_policy"
+
+	sourceGroupPolicy = "group_policy"
+	sourceMDM         = "mdm"
+	sourceUser        = "user"
+
+	updateTypeFeature = "feature"
+	updateTypeQuality = "quality"
+
+	// pauseDateLayout is the format of the PauseFeatureUpdatesStartTime and PauseQualityUpdatesStartTime values.
+	pauseDateLayout = "2006-01-02"
+)
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+// policySource is a registry key of Windows Update for Business policies and the names of its values.
+// Group policies only apply if the value named by the enabled* field is 1, MDM policies don't have such values.
+// 📑 https://learn.microsoft.com/en-us/windows/deployment/update/waas-wu-settings
+// 📑 https://learn.microsoft.com/en-us/windows/client-management/mdm/policy-csp-update
+type policySource struct {
+	name string
+	key  string
+
+	enabledDeferFeature  string
+	enabledDeferQuality  string
+	enabledDeadline      string
+	enabledActiveHours   string
+	enabledTargetRelease string
+	targetReleaseValue   string
+	activeHoursOnly      bool
+}
+
+//nolint:gochecknoglobals
+var policySources = []policySource{
+	{
+		name:                 sourceGroupPolicy,
+		key:                  `SOFTWARE\Policies\Microsoft\Windows\WindowsUpdate`,
+		enabledDeferFeature:  "DeferFeatureUpdates",
+		enabledDeferQuality:  "DeferQualityUpdates",
+		enabledDeadline:      "SetComplianceDeadline",
+		enabledActiveHours:   "SetActiveHours",
+		enabledTargetRelease: "TargetReleaseVersion",
+		targetReleaseValue:   "TargetReleaseVersionInfo",
+	},
+	{
+		name:               sourceMDM,
+		key:                `SOFTWARE\Microsoft\PolicyManager\current\device\Update`,
+		targetReleaseValue: "TargetReleaseVersion",
+	},
+	{
+		// The active hours set by the user or detected by Windows, if no policy sets them.
+		name:            sourceUser,
+		key:             `SOFTWARE\Microsoft\WindowsUpdate\UX\Settings`,
+		activeHoursOnly: true,
+	},
+}
+
+// A Collector is a Prometheus Collector for the Windows Update for Business policies of the deferrals, deadlines,
+// pauses, active hours and target release version, set by group policy or MDM, e.g. Intune.
+// Both sources are exported, because the effective source depends on the ControlPolicyConflict policy.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	deferralDays             *prometheus.Desc
+	deadlineDays             *prometheus.Desc
+	deadlineGracePeriodDays  *prometheus.Desc
+	pauseStartTimestamp      *prometheus.Desc
+	activeHoursStartHour     *prometheus.Desc
+	activeHoursEndHour       *prometheus.Desc
+	targetReleaseVersionInfo *prometheus.Desc
+	deadlineNoAutoRestart    *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.deferralDays = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "deferral_days"),
+		"Number of days the updates are deferred after their release, by source (group_policy, mdm) and update type (feature, quality)",
+		[]string{"source", "update_type"},
+		nil,
+	)
+	c.deadlineDays = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "deadline_days"),
+		"Number of days after the offer of an update until its installation and restart are enforced, by source and update type",
+		[]string{"source", "update_type"},
+		nil,
+	)
+	c.deadlineGracePeriodDays = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "deadline_grace_period_days"),
+		"Number of days after the deadline until the restart is enforced, by source and update type",
+		[]string{"source", "update_type"},
+		nil,
+	)
+	c.deadlineNoAutoRestart = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "deadline_no_auto_restart"),
+		"1 if the device doesn't restart automatically outside of the active hours before the deadline, by source",
+		[]string{"source"},
+		nil,
+	)
+	c.pauseStartTimestamp = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "pause_start_timestamp_seconds"),
+		"Start of the pause of the updates as unix timestamp, by source and update type",
+		[]string{"source", "update_type"},
+		nil,
+	)
+	c.activeHoursStartHour = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "active_hours_start_hour"),
+		"Hour of the day the active hours start, in which the device doesn't restart for updates, by source (group_policy, mdm, user)",
+		[]string{"source"},
+		nil,
+	)
+	c.activeHoursEndHour = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "active_hours_end_hour"),
+		"Hour of the day the active hours end, by source (group_policy, mdm, user)",
+		[]string{"source"},
+		nil,
+	)
+	c.targetReleaseVersionInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "target_release_version_info"),
+		"Target product and feature update version the device stays on or moves to, e.g. Windows 11 and 23H2, by source",
+		[]string{"source", "product_version", "target_release_version"},
+		nil,
+	)
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	for _, source := range policySources {
+		if err := c.collectSource(ch, source); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting %s update policies: %w", source.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectSource(ch chan<- prometheus.Metric, source policySource) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, source.key, registry.QUERY_VALUE)
+	if err != nil {
+		// The key only exists if a policy of the source is configured.
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to open registry key %s: %w", source.key, err)
+	}
+
+	defer key.Close()
+
+	values := policyValues{key: key}
+
+	if values.enabled(source.enabledActiveHours) {
+		start, startOK := values.integer("ActiveHoursStart")
+		end, endOK := values.integer("ActiveHoursEnd")
+
+		if startOK && endOK {
+			ch <- prometheus.MustNewConstMetric(c.activeHoursStartHour, prometheus.GaugeValue, start, source.name)
+			ch <- prometheus.MustNewConstMetric(c.activeHoursEndHour, prometheus.GaugeValue, end, source.name)
+		}
+	}
+
+	if source.activeHoursOnly {
+		return values.err
+	}
+
+	for _, setting := range []struct {
+		desc       *prometheus.Desc
+		value      string
+		enabled    string
+		updateType string
+	}{
+		{c.deferralDays, "DeferFeatureUpdatesPeriodInDays", source.enabledDeferFeature, updateTypeFeature},
+		{c.deferralDays, "DeferQualityUpdatesPeriodInDays", source.enabledDeferQuality, updateTypeQuality},
+		{c.deadlineDays, "ConfigureDeadlineForFeatureUpdates", source.enabledDeadline, updateTypeFeature},
+		{c.deadlineDays, "ConfigureDeadlineForQualityUpdates", source.enabledDeadline, updateTypeQuality},
+		{c.deadlineGracePeriodDays, "ConfigureDeadlineGracePeriodForFeatureUpdates", source.enabledDeadline, updateTypeFeature},
+		{c.deadlineGracePeriodDays, "ConfigureDeadlineGracePeriod", source.enabledDeadline, updateTypeQuality},
+	} {
+		if !values.enabled(setting.enabled) {
+			continue
+		}
+
+		if days, ok := values.integer(setting.value); ok {
+			ch <- prometheus.MustNewConstMetric(setting.desc, prometheus.GaugeValue, days, source.name, setting.updateType)
+		}
+	}
+
+	if values.enabled(source.enabledDeadline) {
+		if noAutoReboot, ok := values.integer("ConfigureDeadlineNoAutoReboot"); ok {
+			ch <- prometheus.MustNewConstMetric(c.deadlineNoAutoRestart, prometheus.GaugeValue, noAutoReboot, source.name)
+		}
+	}
+
+	for updateType, value := range map[string]string{
+		updateTypeFeature: "PauseFeatureUpdatesStartTime",
+		updateTypeQuality: "PauseQualityUpdatesStartTime",
+	} {
+		start, ok := values.string(value)
+		if !ok || start == "" {
+			continue
+		}
+
+		startTime, err := time.Parse(pauseDateLayout, start)
+		if err != nil {
+			c.logger.Debug("invalid pause start time",
+				slog.String("source", source.name),
+				slog.String("value", value),
+				slog.String("start", start),
+			)
+
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.pauseStartTimestamp, prometheus.GaugeValue, float64(startTime.Unix()), source.name, updateType)
+	}
+
+	if values.enabled(source.enabledTargetRelease) {
+		productVersion, _ := values.string("ProductVersion")
+
+		if targetRelease, ok := values.string(source.targetReleaseValue); ok && targetRelease != "" {
+			ch <- prometheus.MustNewConstMetric(c.targetReleaseVersionInfo, prometheus.GaugeValue, 1, source.name, productVersion, targetRelease)
+		}
+	}
+
+	return values.err
+}
+
+// policyValues reads the values of a policy key. Missing values are reported as not ok, other errors are kept in err.
+type policyValues struct {
+	key registry.Key
+	err error
+}
+
+// enabled returns whether the policy enabled by the given value is enabled. An empty name is always enabled.
+func (v *policyValues) enabled(name string) bool {
+	if name == "" {
+		return true
+	}
+
+	value, ok := v.integer(name)
+
+	return ok && value == 1
+}
+
+func (v *policyValues) integer(name string) (float64, bool) {
+	value, _, err := v.key.GetIntegerValue(name)
+	if err == nil {
+		return float64(value), true
+	}
+
+	if !errors.Is(err, registry.ErrNotExist) {
+		v.err = errors.Join(v.err, fmt.Errorf("failed to read %s: %w", name, err))
+	}
+
+	return 0, false
+}
+
+func (v *policyValues) string(name string) (string, bool) {
+	value, _, err := v.key.GetStringValue(name)
+	if err == nil {
+		return value, true
+	}
+
+	if !errors.Is(err, registry.ErrNotExist) {
+		v.err = errors.Join(v.err, fmt.Errorf("failed to read %s: %w", name, err))
+	}
+
+	return "", false
+}
@@ -0,0 +1,445 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package user_profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "user_profile"
+
+	// profileListKey contains a subkey for each local user profile, named by the SID of the user.
+	profileListKey = `SOFTWARE\Microsoft\Windows NT\CurrentVersion\ProfileList`
+)
+
+type Config struct {
+	StaleDays        int           `yaml:"stale-days"`
+	SizeScanInterval time.Duration `yaml:"size-scan-interval"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	StaleDays:        90,
+	SizeScanInterval: 6 * time.Hour,
+}
+
+// serviceSIDs are the well-known SIDs of the LocalSystem, LocalService and NetworkService accounts,
+// whose profiles are not user profiles.
+//
+//nolint:gochecknoglobals
+var serviceSIDs = map[string]struct{}{
+	"S-1-5-18": {},
+	"S-1-5-19": {},
+	"S-1-5-20": {},
+}
+
+// A Collector is a Prometheus Collector for the local user profiles, which fill the system drive of RDS hosts
+// and shared workstations if they are not cleaned up.
+// The size of the profiles is calculated in the background every SizeScanInterval, since walking
+// the profile directories can take minutes.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	ctxCancelFn context.CancelFunc
+
+	// mu protects the result of the last size scan.
+	mu           sync.RWMutex
+	sizeBytes    float64
+	scanDuration float64
+	scanTime     time.Time
+
+	profiles                 *prometheus.Desc
+	loadedProfiles           *prometheus.Desc
+	staleProfiles            *prometheus.Desc
+	sizeBytesDesc            *prometheus.Desc
+	sizeScanDurationSeconds  *prometheus.Desc
+	sizeScanTimestampSeconds *prometheus.Desc
+}
+
+// profile is a local user profile of the ProfileList.
+type profile struct {
+	path    string
+	loaded  bool
+	lastUse time.Time
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.StaleDays == 0 {
+		config.StaleDays = ConfigDefaults.StaleDays
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	app.Flag(
+		"collector.user_profile.stale-days",
+		"Number of days after the last use of a profile after which it is counted as stale.",
+	).Default(strconv.Itoa(ConfigDefaults.StaleDays)).IntVar(&c.config.StaleDays)
+
+	app.Flag(
+		"collector.user_profile.size-scan-interval",
+		"Interval of calculating the size of the profile directories. 0 disables the size calculation.",
+	).Default(ConfigDefaults.SizeScanInterval.String()).DurationVar(&c.config.SizeScanInterval)
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+// LastSuccess returns the time of the last successful size scan, see collector.BackgroundCollector.
+func (c *Collector) LastSuccess() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.scanTime
+}
+
+func (c *Collector) Close() error {
+	if c.ctxCancelFn != nil {
+		c.ctxCancelFn()
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	if c.config.StaleDays <= 0 {
+		return fmt.Errorf("invalid stale days %d, expected a positive number", c.config.StaleDays)
+	}
+
+	c.profiles = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "profiles"),
+		"Number of local user profiles",
+		nil,
+		nil,
+	)
+	c.loadedProfiles = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "loaded_profiles"),
+		"Number of local user profiles that are loaded, e.g. of logged on users",
+		nil,
+		nil,
+	)
+	c.staleProfiles = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "stale_profiles"),
+		"Number of local user profiles that were not used for more than the stale days",
+		nil,
+		nil,
+	)
+	c.sizeBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "size_bytes"),
+		"Total size of the files of all local user profiles at the last size scan",
+		nil,
+		nil,
+	)
+	c.sizeScanDurationSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "size_scan_duration_seconds"),
+		"Duration of the last size scan of the profile directories",
+		nil,
+		nil,
+	)
+	c.sizeScanTimestampSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "size_scan_timestamp_seconds"),
+		"Timestamp of the end of the last size scan of the profile directories",
+		nil,
+		nil,
+	)
+
+	if _, err := readProfiles(); err != nil {
+		return err
+	}
+
+	if c.config.SizeScanInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.ctxCancelFn = cancel
+
+		go c.scheduleSizeScan(ctx)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	profiles, err := readProfiles()
+	if err != nil {
+		return err
+	}
+
+	staleBefore := time.Now().AddDate(0, 0, -c.config.StaleDays)
+
+	var loaded, stale int
+
+	for _, p := range profiles {
+		switch {
+		case p.loaded:
+			loaded++
+		case !p.lastUse.IsZero() && p.lastUse.Before(staleBefore):
+			stale++
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.profiles,
+		prometheus.GaugeValue,
+		float64(len(profiles)),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.loadedProfiles,
+		prometheus.GaugeValue,
+		float64(loaded),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.staleProfiles,
+		prometheus.GaugeValue,
+		float64(stale),
+	)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// The size metrics are missing until the first scan finished.
+	if c.scanTime.IsZero() {
+		return nil
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.sizeBytesDesc,
+		prometheus.GaugeValue,
+		c.sizeBytes,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.sizeScanDurationSeconds,
+		prometheus.GaugeValue,
+		c.scanDuration,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.sizeScanTimestampSeconds,
+		prometheus.GaugeValue,
+		float64(c.scanTime.Unix()),
+	)
+
+	return nil
+}
+
+func (c *Collector) scheduleSizeScan(ctx context.Context) {
+	for {
+		if err := c.scanSize(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			c.logger.LogAttrs(ctx, slog.LevelWarn, "failed to calculate the size of the user profiles",
+				slog.Any("err", err),
+			)
+		}
+
+		select {
+		case <-time.After(c.config.SizeScanInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Collector) scanSize(ctx context.Context) error {
+	start := time.Now()
+
+	profiles, err := readProfiles()
+	if err != nil {
+		return err
+	}
+
+	var sizeBytes int64
+
+	for _, p := range profiles {
+		size, err := directorySize(ctx, p.path)
+		if err != nil {
+			return err
+		}
+
+		sizeBytes += size
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sizeBytes = float64(sizeBytes)
+	c.scanTime = time.Now()
+	c.scanDuration = c.scanTime.Sub(start).Seconds()
+
+	return nil
+}
+
+// directorySize returns the size of the files below path. Junctions, e.g. "Application Data" of legacy
+// applications, are not followed, so no file is counted twice. Files that can't be read are skipped.
+func directorySize(ctx context.Context, path string) (int64, error) {
+	var size int64
+
+	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if err != nil {
+			if entry != nil && entry.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil //nolint:nilerr // e.g. a file deleted during the scan
+		}
+
+		size += info.Size()
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// readProfiles returns the user profiles of the ProfileList. Profiles of the service accounts and backups
+// of corrupted profiles ("<SID>.bak") are left out.
+func readProfiles() ([]profile, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, profileListKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open registry key %s: %w", profileListKey, err)
+	}
+
+	defer key.Close()
+
+	sids, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry key %s: %w", profileListKey, err)
+	}
+
+	profiles := make([]profile, 0, len(sids))
+
+	for _, sid := range sids {
+		if _, ok := serviceSIDs[sid]; ok || strings.HasSuffix(sid, ".bak") {
+			continue
+		}
+
+		p, err := readProfile(key, sid)
+		if err != nil {
+			// The profile may have been deleted since the subkeys were read.
+			continue
+		}
+
+		profiles = append(profiles, p)
+	}
+
+	return profiles, nil
+}
+
+func readProfile(profileList registry.Key, sid string) (profile, error) {
+	key, err := registry.OpenKey(profileList, sid, registry.QUERY_VALUE)
+	if err != nil {
+		return profile{}, err
+	}
+
+	defer key.Close()
+
+	path, _, err := key.GetStringValue("ProfileImagePath")
+	if err != nil {
+		return profile{}, err
+	}
+
+	path, err = registry.ExpandString(path)
+	if err != nil {
+		return profile{}, err
+	}
+
+	p := profile{path: path}
+
+	// The hive of a loaded profile is mounted below HKEY_USERS.
+	if loadedKey, err := registry.OpenKey(registry.USERS, sid, registry.QUERY_VALUE); err == nil {
+		_ = loadedKey.Close()
+		p.loaded = true
+
+		return p, nil
+	}
+
+	p.lastUse = lastUse(key, path)
+
+	return p, nil
+}
+
+// lastUse returns the time the profile was last unloaded, which Windows records since Windows 10 1809 and
+// Windows Server 2019. For older profiles, the modification time of the registry hive of the profile is used.
+// LastUseTime of Win32_UserProfile is not used, since it is updated by antivirus scans and backups.
+func lastUse(key registry.Key, path string) time.Time {
+	high, _, errHigh := key.GetIntegerValue("LocalProfileUnloadTimeHigh")
+	low, _, errLow := key.GetIntegerValue("LocalProfileUnloadTimeLow")
+
+	if errHigh == nil && errLow == nil && high|low != 0 {
+		filetime := windows.Filetime{HighDateTime: uint32(high), LowDateTime: uint32(low)}
+
+		return time.Unix(0, filetime.Nanoseconds())
+	}
+
+	info, err := os.Stat(filepath.Join(path, "NTUSER.DAT"))
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package wdac
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// policyOptionAuditMode is the "Enabled:Audit Mode" rule option flag of a binary code integrity policy.
+	policyOptionAuditMode = 0x00010000
+
+	// policyHeaderSize is the size of the fixed binary policy header up to and including the policy version.
+	policyHeaderSize = 4 + 16 + 16 + 4 + 4*4 + 8
+)
+
+// policy is the relevant subset of the binary code integrity policy header.
+type policy struct {
+	optionFlags uint32
+	version     string
+	signed      bool
+}
+
+// pkcs7ContentInfo and pkcs7SignedData are the ASN.1 structures of a signed policy (RFC 5652).
+// Only the encapsulated content is decoded, certificates and signer infos are ignored.
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     pkcs7SignedData `asn1:"explicit,tag:0"`
+}
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo pkcs7EncapsulatedContentInfo
+	Rest             asn1.RawValue `asn1:"optional"`
+}
+
+type pkcs7EncapsulatedContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+// parsePolicy parses the header of a binary code integrity policy (.cip/.p7b).
+// Signed policies are wrapped in a PKCS#7 SignedData structure.
+func parsePolicy(data []byte) (policy, error) {
+	var p policy
+
+	// A DER encoded PKCS#7 structure always starts with a SEQUENCE tag.
+	if len(data) > 0 && data[0] == 0x30 {
+		var contentInfo pkcs7ContentInfo
+		if _, err := asn1.Unmarshal(data, &contentInfo); err != nil {
+			return p, fmt.Errorf("failed to parse signed policy: %w", err)
+		}
+
+		data = contentInfo.Content.EncapContentInfo.EContent
+		p.signed = true
+	}
+
+	if len(data) < policyHeaderSize {
+		return p, errors.New("policy is too short")
+	}
+
+	var header struct {
+		FormatVersion            uint32
+		PolicyTypeID             [16]byte
+		PlatformID               [16]byte
+		OptionFlags              uint32
+		EKURuleEntryCount        uint32
+		FileRuleEntryCount       uint32
+		SignerRuleEntryCount     uint32
+		SignerScenarioEntryCount uint32
+		VersionRevision          uint16
+		VersionBuild             uint16
+		VersionMinor             uint16
+		VersionMajor             uint16
+	}
+
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &header); err != nil {
+		return p, fmt.Errorf("failed to read policy header: %w", err)
+	}
+
+	p.optionFlags = header.OptionFlags
+	p.version = fmt.Sprintf("%d.%d.%d.%d", header.VersionMajor, header.VersionMinor, header.VersionBuild, header.VersionRevision)
+
+	return p, nil
+}
+
+func (p policy) auditMode() bool {
+	return p.optionFlags&policyOptionAuditMode != 0
+}
@@ -0,0 +1,271 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package wdac
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const Name = "wdac"
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var (
+	deviceGuardQuery = utils.Must(mi.NewQuery("SELECT CodeIntegrityPolicyEnforcementStatus, UsermodeCodeIntegrityPolicyEnforcementStatus FROM Win32_DeviceGuard"))
+
+	enforcementStatuses = []string{"off", "audit", "enforced"}
+)
+
+// A Collector is a Prometheus Collector for Windows Defender Application Control (WDAC) policies.
+// The effective enforcement status is read from WMI Win32_DeviceGuard, the deployed policies
+// from the binary policy files in the CodeIntegrity directory.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	miSession          *mi.Session
+	deviceGuardEnabled bool
+
+	// policyFiles are the glob patterns of the deployed binary policy files.
+	policyFiles []string
+
+	enforcementStatus *prometheus.Desc
+	policies          *prometheus.Desc
+	policyInfo        *prometheus.Desc
+	policyAuditMode   *prometheus.Desc
+}
+
+type win32DeviceGuard struct {
+	CodeIntegrityPolicyEnforcementStatus         uint32 `mi:"CodeIntegrityPolicyEnforcementStatus"`
+	UsermodeCodeIntegrityPolicyEnforcementStatus uint32 `mi:"UsermodeCodeIntegrityPolicyEnforcementStatus"`
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.enforcementStatus = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "code_integrity_policy_enforcement_status"),
+		"Effective enforcement status of the code integrity policies (off, audit, enforced) for kernel mode and user mode",
+		[]string{"scope", "status"},
+		nil,
+	)
+	c.policies = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "policies"),
+		"Number of deployed code integrity policies",
+		nil,
+		nil,
+	)
+	c.policyInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "policy_info"),
+		"Information about the deployed code integrity policy",
+		[]string{"policy_id", "version", "signed"},
+		nil,
+	)
+	c.policyAuditMode = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "policy_audit_mode"),
+		"Whether the deployed code integrity policy is in audit mode (1) or enforced (0)",
+		[]string{"policy_id"},
+		nil,
+	)
+
+	systemDirectory, err := windows.GetSystemDirectory()
+	if err != nil {
+		return fmt.Errorf("failed to get system directory: %w", err)
+	}
+
+	c.policyFiles = []string{
+		// Multiple policy format, supported since Windows 10 1903.
+		filepath.Join(systemDirectory, "CodeIntegrity", "CiPolicies", "Active", "*.cip"),
+		// Legacy single policy format.
+		filepath.Join(systemDirectory, "CodeIntegrity", "SIPolicy.p7b"),
+	}
+
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.miSession = miSession
+
+	var dst []win32DeviceGuard
+	if err := c.miSession.Query(&dst, mi.NamespaceRootDeviceGuard, deviceGuardQuery); err != nil {
+		if !errors.Is(err, mi.MI_RESULT_INVALID_NAMESPACE) {
+			return fmt.Errorf("WMI query failed: %w", err)
+		}
+
+		c.logger.Debug("Device Guard WMI namespace is not available, skipping enforcement status metrics")
+	} else {
+		c.deviceGuardEnabled = true
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if c.deviceGuardEnabled {
+		if err := c.collectEnforcementStatus(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting enforcement status metrics: %w", err))
+		}
+	}
+
+	if err := c.collectPolicies(ch); err != nil {
+		errs = append(errs, fmt.Errorf("failed collecting policy metrics: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectEnforcementStatus(ch chan<- prometheus.Metric) error {
+	var dst []win32DeviceGuard
+	if err := c.miSession.Query(&dst, mi.NamespaceRootDeviceGuard, deviceGuardQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	if len(dst) == 0 {
+		return errors.New("WMI query returned empty result set")
+	}
+
+	for i, status := range enforcementStatuses {
+		ch <- prometheus.MustNewConstMetric(
+			c.enforcementStatus,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(dst[0].CodeIntegrityPolicyEnforcementStatus == uint32(i)),
+			"kernel",
+			status,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.enforcementStatus,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(dst[0].UsermodeCodeIntegrityPolicyEnforcementStatus == uint32(i)),
+			"user",
+			status,
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) collectPolicies(ch chan<- prometheus.Metric) error {
+	var count int
+
+	for _, pattern := range c.policyFiles {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("failed to list policy files %s: %w", pattern, err)
+		}
+
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				if errors.Is(err, os.ErrPermission) {
+					c.logger.Debug("access to the policy file denied",
+						slog.String("file", file),
+						slog.Any("err", err),
+					)
+
+					continue
+				}
+
+				return fmt.Errorf("failed to read policy file %s: %w", file, err)
+			}
+
+			p, err := parsePolicy(data)
+			if err != nil {
+				c.logger.Warn("failed to parse policy file",
+					slog.String("file", file),
+					slog.Any("err", err),
+				)
+
+				continue
+			}
+
+			count++
+
+			policyID := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+
+			ch <- prometheus.MustNewConstMetric(
+				c.policyInfo,
+				prometheus.GaugeValue,
+				1.0,
+				policyID,
+				p.version,
+				strconv.FormatBool(p.signed),
+			)
+
+			ch <- prometheus.MustNewConstMetric(
+				c.policyAuditMode,
+				prometheus.GaugeValue,
+				utils.BoolToFloat(p.auditMode()),
+				policyID,
+			)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.policies,
+		prometheus.GaugeValue,
+		float64(count),
+	)
+
+	return nil
+}
@@ -0,0 +1,335 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package web_application_proxy
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	Name = "web_application_proxy"
+
+	adminChannel = "Microsoft-Windows-WebApplicationProxy/Admin"
+)
+
+type Config struct {
+	// BackendFailureEventIDs are the events logged if a published application couldn't be reached.
+	BackendFailureEventIDs []string `yaml:"backend-failure-event-ids"`
+	// EdgeTokenRejectionEventIDs are the events logged if an edge token was rejected, e.g. because it was replayed.
+	EdgeTokenRejectionEventIDs []string `yaml:"edge-token-rejection-event-ids"`
+	// FederationFailureEventIDs are the events logged if AD FS couldn't be reached or rejected the proxy.
+	FederationFailureEventIDs []string `yaml:"federation-failure-event-ids"`
+}
+
+// The default event IDs follow the event tables of the Web Application Proxy troubleshooting guide.
+// 📑 https://learn.microsoft.com/en-us/windows-server/remote/remote-access/web-application-proxy/troubleshooting-web-application-proxy
+//
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	BackendFailureEventIDs:     []string{"13006", "13007", "13013", "13019"},
+	EdgeTokenRejectionEventIDs: []string{"13039", "13040"},
+	FederationFailureEventIDs:  []string{"12000", "12019", "12020", "12021"},
+}
+
+// renderValuePaths are the event properties rendered for each event.
+// The order must match the value* indices.
+//
+//nolint:gochecknoglobals
+var renderValuePaths = []string{
+	"Event/System/EventRecordID",
+	"Event/System/EventID",
+	"Event/System/Level",
+}
+
+const (
+	valueEventRecordID = iota
+	valueEventID
+	valueLevel
+)
+
+const (
+	categoryBackendFailure = iota
+	categoryEdgeTokenRejection
+	categoryFederationFailure
+)
+
+// A Collector is a Prometheus Collector for the events of Web Application Proxy.
+// The events of the Admin channel written since windows_exporter started are counted.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	renderContext wevtapi.EVT_HANDLE
+	// categories are the categories of the configured event IDs.
+	categories map[uint64]int
+
+	// mu protects the counters, channelExists and lastRecordID against concurrent scrapes.
+	mu            sync.Mutex
+	channelExists bool
+	lastRecordID  uint64
+	categoryCount [3]float64
+	errorCounts   map[uint64]float64
+
+	backendFailuresTotal     *prometheus.Desc
+	edgeTokenRejectionsTotal *prometheus.Desc
+	federationFailuresTotal  *prometheus.Desc
+	errorsTotal              *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.BackendFailureEventIDs == nil {
+		config.BackendFailureEventIDs = ConfigDefaults.BackendFailureEventIDs
+	}
+
+	if config.EdgeTokenRejectionEventIDs == nil {
+		config.EdgeTokenRejectionEventIDs = ConfigDefaults.EdgeTokenRejectionEventIDs
+	}
+
+	if config.FederationFailureEventIDs == nil {
+		config.FederationFailureEventIDs = ConfigDefaults.FederationFailureEventIDs
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	var backendFailureEventIDs, edgeTokenRejectionEventIDs, federationFailureEventIDs string
+
+	app.Flag(
+		"collector.web_application_proxy.backend-failure-event-ids",
+		"Comma-separated list of the Admin event IDs counted as backend failures.",
+	).Default(strings.Join(ConfigDefaults.BackendFailureEventIDs, ",")).StringVar(&backendFailureEventIDs)
+
+	app.Flag(
+		"collector.web_application_proxy.edge-token-rejection-event-ids",
+		"Comma-separated list of the Admin event IDs counted as rejected edge tokens, e.g. replayed tokens.",
+	).Default(strings.Join(ConfigDefaults.EdgeTokenRejectionEventIDs, ",")).StringVar(&edgeTokenRejectionEventIDs)
+
+	app.Flag(
+		"collector.web_application_proxy.federation-failure-event-ids",
+		"Comma-separated list of the Admin event IDs counted as failures to reach AD FS.",
+	).Default(strings.Join(ConfigDefaults.FederationFailureEventIDs, ",")).StringVar(&federationFailureEventIDs)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config.BackendFailureEventIDs = splitList(backendFailureEventIDs)
+		c.config.EdgeTokenRejectionEventIDs = splitList(edgeTokenRejectionEventIDs)
+		c.config.FederationFailureEventIDs = splitList(federationFailureEventIDs)
+
+		return nil
+	})
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if c.renderContext != 0 {
+		return wevtapi.EvtClose(c.renderContext)
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	var err error
+
+	c.categories, err = eventCategories(map[int][]string{
+		categoryBackendFailure:     c.config.BackendFailureEventIDs,
+		categoryEdgeTokenRejection: c.config.EdgeTokenRejectionEventIDs,
+		categoryFederationFailure:  c.config.FederationFailureEventIDs,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.backendFailuresTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "backend_failures_total"),
+		"Number of requests to published applications that failed because the backend server couldn't be reached or returned an error",
+		nil,
+		nil,
+	)
+	c.edgeTokenRejectionsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "edge_token_rejections_total"),
+		"Number of rejected edge tokens, e.g. replayed, expired or nonvalid tokens",
+		nil,
+		nil,
+	)
+	c.federationFailuresTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "federation_failures_total"),
+		"Number of failures to reach AD FS or to authenticate to AD FS with the proxy trust certificate",
+		nil,
+		nil,
+	)
+	c.errorsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "errors_total"),
+		"Number of critical and error events in the Admin event log of Web Application Proxy by event ID",
+		[]string{"event_id"},
+		nil,
+	)
+
+	c.errorCounts = make(map[uint64]float64)
+
+	if err := c.openChannel(); err != nil {
+		return err
+	}
+
+	c.renderContext, err = wevtapi.EvtCreateRenderContext(renderValuePaths)
+	if err != nil {
+		return fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	return nil
+}
+
+// openChannel reads the latest record ID of the Admin channel, which only exists if Web Application Proxy is installed.
+func (c *Collector) openChannel() error {
+	lastRecordID, err := wevtapi.LatestEventRecordID(adminChannel)
+	if err != nil {
+		if errors.Is(err, wevtapi.ERROR_EVT_CHANNEL_NOT_FOUND) {
+			c.logger.Debug("Web Application Proxy event log not found, waiting for the installation of Web Application Proxy")
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to read latest event of %s: %w", adminChannel, err)
+	}
+
+	c.channelExists = true
+	c.lastRecordID = lastRecordID
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.channelExists {
+		if err := c.openChannel(); err != nil || !c.channelExists {
+			return err
+		}
+	}
+
+	query := fmt.Sprintf("*[System[EventRecordID > %d]]", c.lastRecordID)
+
+	if err := wevtapi.QueryValues(adminChannel, query, c.renderContext, c.handleEvent); err != nil {
+		return fmt.Errorf("failed to read events of %s: %w", adminChannel, err)
+	}
+
+	for category, desc := range []*prometheus.Desc{
+		categoryBackendFailure:     c.backendFailuresTotal,
+		categoryEdgeTokenRejection: c.edgeTokenRejectionsTotal,
+		categoryFederationFailure:  c.federationFailuresTotal,
+	} {
+		ch <- prometheus.MustNewConstMetric(
+			desc,
+			prometheus.CounterValue,
+			c.categoryCount[category],
+		)
+	}
+
+	for eventID, count := range c.errorCounts {
+		ch <- prometheus.MustNewConstMetric(
+			c.errorsTotal,
+			prometheus.CounterValue,
+			count,
+			strconv.FormatUint(eventID, 10),
+		)
+	}
+
+	return nil
+}
+
+func (c *Collector) handleEvent(values []any) {
+	if len(values) != len(renderValuePaths) {
+		return
+	}
+
+	recordID, _ := values[valueEventRecordID].(uint64)
+	c.lastRecordID = max(c.lastRecordID, recordID)
+
+	eventID, _ := values[valueEventID].(uint64)
+
+	if category, ok := c.categories[eventID]; ok {
+		c.categoryCount[category]++
+	}
+
+	// Level 1 is critical, 2 is error.
+	if level, _ := values[valueLevel].(uint64); level == 1 || level == 2 {
+		c.errorCounts[eventID]++
+	}
+}
+
+// eventCategories returns the category of each event ID. An event ID must not be in more than one category.
+func eventCategories(eventIDs map[int][]string) (map[uint64]int, error) {
+	categories := make(map[uint64]int)
+
+	for category, ids := range eventIDs {
+		for _, id := range ids {
+			eventID, err := strconv.ParseUint(strings.TrimSpace(id), 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("invalid event ID %q: %w", id, err)
+			}
+
+			if _, ok := categories[eventID]; ok {
+				return nil, fmt.Errorf("event ID %d is configured for more than one metric", eventID)
+			}
+
+			categories[eventID] = category
+		}
+	}
+
+	return categories, nil
+}
+
+func splitList(s string) []string {
+	return slices.DeleteFunc(strings.Split(s, ","), func(item string) bool {
+		return strings.TrimSpace(item) == ""
+	})
+}
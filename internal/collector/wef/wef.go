@@ -0,0 +1,370 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package wef
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	Name = "wef"
+
+	// subscriptionsKey contains a subkey per subscription of the Windows Event Collector service.
+	subscriptionsKey = `SOFTWARE\Microsoft\Windows\CurrentVersion\EventCollector\Subscriptions`
+	// eventSourcesKey is the subkey of a subscription with a subkey per event source.
+	eventSourcesKey = "EventSources"
+
+	defaultLogFile = "ForwardedEvents"
+)
+
+type Config struct {
+	// ActiveThreshold is the maximum age of the last heartbeat of an active event source.
+	ActiveThreshold time.Duration `yaml:"active-threshold"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	ActiveThreshold: 2 * time.Hour,
+}
+
+// heartbeatLagBuckets are the upper bounds of the heartbeat lag histogram in seconds.
+// Event sources send a heartbeat every hour in the Normal and MinLatency delivery modes
+// and every 6 hours in the MinBandwidth delivery mode.
+//
+//nolint:gochecknoglobals
+var heartbeatLagBuckets = []float64{
+	(15 * time.Minute).Seconds(),
+	time.Hour.Seconds(),
+	(2 * time.Hour).Seconds(),
+	(6 * time.Hour).Seconds(),
+	(12 * time.Hour).Seconds(),
+	(24 * time.Hour).Seconds(),
+	(7 * 24 * time.Hour).Seconds(),
+}
+
+// A Collector is a Prometheus Collector for Windows Event Forwarding subscriptions of a Windows Event Collector (WEC).
+// Subscriptions and the heartbeats of their event sources are read from the registry,
+// the delivered events from the record IDs of the destination logs.
+type Collector struct {
+	config Config
+	logger *slog.Logger
+
+	subscriptionEnabled *prometheus.Desc
+	sources             *prometheus.Desc
+	activeSources       *prometheus.Desc
+	heartbeatLag        *prometheus.Desc
+	logEvents           *prometheus.Desc
+}
+
+type subscription struct {
+	name    string
+	enabled bool
+	logFile string
+	// heartbeats are the last heartbeats of the event sources. Sources that never sent a heartbeat are zero.
+	heartbeats []time.Time
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.ActiveThreshold == 0 {
+		config.ActiveThreshold = ConfigDefaults.ActiveThreshold
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	app.Flag(
+		"collector.wef.active-threshold",
+		"Maximum age of the last heartbeat of an event source to be counted as active.",
+	).Default(c.config.ActiveThreshold.String()).DurationVar(&c.config.ActiveThreshold)
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	c.subscriptionEnabled = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "subscription_enabled"),
+		"Whether the event subscription is enabled",
+		[]string{"subscription"},
+		nil,
+	)
+	c.sources = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "subscription_sources"),
+		"Number of event sources that ever connected to the subscription",
+		[]string{"subscription"},
+		nil,
+	)
+	c.activeSources = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "subscription_active_sources"),
+		"Number of event sources of the subscription with a heartbeat within --collector.wef.active-threshold",
+		[]string{"subscription"},
+		nil,
+	)
+	c.heartbeatLag = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "subscription_heartbeat_lag_seconds"),
+		"Distribution of the time since the last heartbeat of the event sources of the subscription",
+		[]string{"subscription"},
+		nil,
+	)
+	c.logEvents = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "log_events_total"),
+		"Number of events written to the destination log of event subscriptions, by the record ID of the latest event",
+		[]string{"log"},
+		nil,
+	)
+
+	// The key doesn't exist if the Windows Event Collector was never configured.
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, subscriptionsKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return fmt.Errorf("failed to open registry key %s: %w", subscriptionsKey, err)
+	}
+
+	return key.Close()
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	subscriptions, err := readSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	logFiles := make(map[string]struct{})
+
+	for _, sub := range subscriptions {
+		logFiles[sub.logFile] = struct{}{}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.subscriptionEnabled,
+			prometheus.GaugeValue,
+			utils.BoolToFloat(sub.enabled),
+			sub.name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.sources,
+			prometheus.GaugeValue,
+			float64(len(sub.heartbeats)),
+			sub.name,
+		)
+
+		var (
+			activeSources float64
+			lagCount      uint64
+			lagSum        float64
+		)
+
+		lagBuckets := make(map[float64]uint64, len(heartbeatLagBuckets))
+		for _, bucket := range heartbeatLagBuckets {
+			lagBuckets[bucket] = 0
+		}
+
+		for _, heartbeat := range sub.heartbeats {
+			if heartbeat.IsZero() {
+				continue
+			}
+
+			lag := max(now.Sub(heartbeat), 0)
+			if lag <= c.config.ActiveThreshold {
+				activeSources++
+			}
+
+			lagCount++
+			lagSum += lag.Seconds()
+
+			for _, bucket := range heartbeatLagBuckets {
+				if lag.Seconds() <= bucket {
+					lagBuckets[bucket]++
+				}
+			}
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.activeSources,
+			prometheus.GaugeValue,
+			activeSources,
+			sub.name,
+		)
+
+		ch <- prometheus.MustNewConstHistogram(
+			c.heartbeatLag,
+			lagCount,
+			lagSum,
+			lagBuckets,
+			sub.name,
+		)
+	}
+
+	errs := make([]error, 0)
+
+	for logFile := range logFiles {
+		recordID, err := wevtapi.LatestEventRecordID(logFile)
+		if err != nil {
+			if errors.Is(err, wevtapi.ERROR_EVT_CHANNEL_NOT_FOUND) {
+				continue
+			}
+
+			errs = append(errs, fmt.Errorf("failed to read latest event of %s: %w", logFile, err))
+
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.logEvents,
+			prometheus.CounterValue,
+			float64(recordID),
+			logFile,
+		)
+	}
+
+	return errors.Join(errs...)
+}
+
+func readSubscriptions() ([]subscription, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, subscriptionsKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open registry key %s: %w", subscriptionsKey, err)
+	}
+
+	defer key.Close()
+
+	names, err := key.ReadSubKeyNames(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subscriptions: %w", err)
+	}
+
+	subscriptions := make([]subscription, 0, len(names))
+
+	for _, name := range names {
+		sub, err := readSubscription(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read subscription %s: %w", name, err)
+		}
+
+		subscriptions = append(subscriptions, sub)
+	}
+
+	return subscriptions, nil
+}
+
+func readSubscription(name string) (subscription, error) {
+	sub := subscription{
+		name:    name,
+		logFile: defaultLogFile,
+	}
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, subscriptionsKey+`\`+name, registry.QUERY_VALUE)
+	if err != nil {
+		return sub, fmt.Errorf("failed to open registry key: %w", err)
+	}
+
+	defer key.Close()
+
+	enabled, _, err := key.GetIntegerValue("Enabled")
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return sub, fmt.Errorf("failed to read Enabled: %w", err)
+	}
+
+	sub.enabled = enabled != 0
+
+	if logFile, _, err := key.GetStringValue("LogFile"); err == nil && logFile != "" {
+		sub.logFile = logFile
+	}
+
+	sourcesKey, err := registry.OpenKey(key, eventSourcesKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		// Subscriptions without connected event sources have no EventSources key.
+		if errors.Is(err, registry.ErrNotExist) {
+			return sub, nil
+		}
+
+		return sub, fmt.Errorf("failed to open event sources: %w", err)
+	}
+
+	defer sourcesKey.Close()
+
+	sources, err := sourcesKey.ReadSubKeyNames(0)
+	if err != nil {
+		return sub, fmt.Errorf("failed to read event sources: %w", err)
+	}
+
+	sub.heartbeats = make([]time.Time, 0, len(sources))
+
+	for _, source := range sources {
+		sub.heartbeats = append(sub.heartbeats, readHeartbeat(sourcesKey, source))
+	}
+
+	return sub, nil
+}
+
+// readHeartbeat returns the LastHeartbeatTime of the event source, which is stored as FILETIME.
+// It returns the zero time if the event source never sent a heartbeat.
+func readHeartbeat(sourcesKey registry.Key, source string) time.Time {
+	key, err := registry.OpenKey(sourcesKey, source, registry.QUERY_VALUE)
+	if err != nil {
+		return time.Time{}
+	}
+
+	defer key.Close()
+
+	heartbeat, _, err := key.GetIntegerValue("LastHeartbeatTime")
+	if err != nil || heartbeat == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, (&windows.Filetime{
+		LowDateTime:  uint32(heartbeat),
+		HighDateTime: uint32(heartbeat >> 32),
+	}).Nanoseconds())
+}
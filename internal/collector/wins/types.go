@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package wins
+
+type perfDataCounterValuesServer struct {
+	// WINS Server
+	UniqueRegistrationsPerSec float64 `perfdata:"Unique Registrations/sec"`
+	GroupRegistrationsPerSec  float64 `perfdata:"Group Registrations/sec"`
+	UniqueRenewalsPerSec      float64 `perfdata:"Unique Renewals/sec"`
+	GroupRenewalsPerSec       float64 `perfdata:"Group Renewals/sec"`
+	UniqueConflictsPerSec     float64 `perfdata:"Unique Conflicts/sec"`
+	GroupConflictsPerSec      float64 `perfdata:"Group Conflicts/sec"`
+	SuccessfulReleasesPerSec  float64 `perfdata:"Successful Releases/sec"`
+	FailedReleasesPerSec      float64 `perfdata:"Failed Releases/sec"`
+	SuccessfulQueriesPerSec   float64 `perfdata:"Successful Queries/sec"`
+	FailedQueriesPerSec       float64 `perfdata:"Failed Queries/sec"`
+}
+
+type perfDataCounterValuesNBTConnection struct {
+	Name string
+
+	// NBT Connection
+	BytesReceivedPerSec float64 `perfdata:"Bytes Received/sec"`
+	BytesSentPerSec     float64 `perfdata:"Bytes Sent/sec"`
+}
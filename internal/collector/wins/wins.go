@@ -0,0 +1,327 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package wins
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	Name = "wins"
+
+	subCollectorServer        = "server"
+	subCollectorNBTConnection = "nbt_connection"
+)
+
+type Config struct {
+	CollectorsEnabled []string `yaml:"enabled"`
+}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	CollectorsEnabled: []string{
+		subCollectorServer,
+		subCollectorNBTConnection,
+	},
+}
+
+// A Collector is a Prometheus Collector for WINS Server and NetBIOS over TCP/IP metrics.
+type Collector struct {
+	config Config
+
+	serverPerfDataCollector *pdh.Collector
+	serverPerfDataObject    []perfDataCounterValuesServer
+
+	nbtConnectionPerfDataCollector *pdh.Collector
+	nbtConnectionPerfDataObject    []perfDataCounterValuesNBTConnection
+
+	registrationsTotal *prometheus.Desc
+	renewalsTotal      *prometheus.Desc
+	conflictsTotal     *prometheus.Desc
+	releasesTotal      *prometheus.Desc
+	queriesTotal       *prometheus.Desc
+
+	nbtConnectionBytesReceivedTotal *prometheus.Desc
+	nbtConnectionBytesSentTotal     *prometheus.Desc
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.CollectorsEnabled == nil {
+		config.CollectorsEnabled = ConfigDefaults.CollectorsEnabled
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+	c.config.CollectorsEnabled = make([]string, 0)
+
+	var collectorsEnabled string
+
+	app.Flag(
+		"collector.wins.enabled",
+		"Comma-separated list of collectors to use. Defaults to all, if not specified.",
+	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+
+		return nil
+	})
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorServer) {
+		c.serverPerfDataCollector.Close()
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorNBTConnection) {
+		c.nbtConnectionPerfDataCollector.Close()
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
+	for _, collector := range c.config.CollectorsEnabled {
+		if !slices.Contains([]string{subCollectorServer, subCollectorNBTConnection}, collector) {
+			return fmt.Errorf("unknown sub collector: %s. Possible values: %s", collector,
+				strings.Join([]string{subCollectorServer, subCollectorNBTConnection}, ", "),
+			)
+		}
+	}
+
+	logger = logger.With(slog.String("collector", Name))
+
+	var err error
+
+	errs := make([]error, 0)
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorServer) {
+		c.registrationsTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "registrations_total"),
+			"Number of name registrations received by the WINS server by name type (unique, group)",
+			[]string{"type"},
+			nil,
+		)
+		c.renewalsTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "renewals_total"),
+			"Number of name renewals received by the WINS server by name type (unique, group)",
+			[]string{"type"},
+			nil,
+		)
+		c.conflictsTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "conflicts_total"),
+			"Number of name registration conflicts seen by the WINS server by name type (unique, group)",
+			[]string{"type"},
+			nil,
+		)
+		c.releasesTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "releases_total"),
+			"Number of name releases processed by the WINS server by result (success, failure)",
+			[]string{"result"},
+			nil,
+		)
+		c.queriesTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "queries_total"),
+			"Number of name queries processed by the WINS server by result (success, failure)",
+			[]string{"result"},
+			nil,
+		)
+
+		c.serverPerfDataCollector, err = pdh.NewCollector[perfDataCounterValuesServer](logger, pdh.CounterTypeRaw, "WINS Server", nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create WINS Server collector: %w", err))
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorNBTConnection) {
+		c.nbtConnectionBytesReceivedTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "nbt_connection_bytes_received_total"),
+			"Number of bytes received over the NetBIOS over TCP/IP connection",
+			[]string{"connection"},
+			nil,
+		)
+		c.nbtConnectionBytesSentTotal = prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, Name, "nbt_connection_bytes_sent_total"),
+			"Number of bytes sent over the NetBIOS over TCP/IP connection",
+			[]string{"connection"},
+			nil,
+		)
+
+		c.nbtConnectionPerfDataCollector, err = pdh.NewCollector[perfDataCounterValuesNBTConnection](logger, pdh.CounterTypeRaw, "NBT Connection", pdh.InstancesAll)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create NBT Connection collector: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	errs := make([]error, 0)
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorServer) {
+		if err := c.collectServer(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting WINS Server metrics: %w", err))
+		}
+	}
+
+	if slices.Contains(c.config.CollectorsEnabled, subCollectorNBTConnection) {
+		if err := c.collectNBTConnection(ch); err != nil {
+			errs = append(errs, fmt.Errorf("failed collecting NBT Connection metrics: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectServer(ch chan<- prometheus.Metric) error {
+	err := c.serverPerfDataCollector.Collect(&c.serverPerfDataObject)
+	if err != nil {
+		return fmt.Errorf("failed to collect WINS Server metrics: %w", err)
+	}
+
+	data := c.serverPerfDataObject[0]
+
+	ch <- prometheus.MustNewConstMetric(
+		c.registrationsTotal,
+		prometheus.CounterValue,
+		data.UniqueRegistrationsPerSec,
+		"unique",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.registrationsTotal,
+		prometheus.CounterValue,
+		data.GroupRegistrationsPerSec,
+		"group",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.renewalsTotal,
+		prometheus.CounterValue,
+		data.UniqueRenewalsPerSec,
+		"unique",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.renewalsTotal,
+		prometheus.CounterValue,
+		data.GroupRenewalsPerSec,
+		"group",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.conflictsTotal,
+		prometheus.CounterValue,
+		data.UniqueConflictsPerSec,
+		"unique",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.conflictsTotal,
+		prometheus.CounterValue,
+		data.GroupConflictsPerSec,
+		"group",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.releasesTotal,
+		prometheus.CounterValue,
+		data.SuccessfulReleasesPerSec,
+		"success",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.releasesTotal,
+		prometheus.CounterValue,
+		data.FailedReleasesPerSec,
+		"failure",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.queriesTotal,
+		prometheus.CounterValue,
+		data.SuccessfulQueriesPerSec,
+		"success",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.queriesTotal,
+		prometheus.CounterValue,
+		data.FailedQueriesPerSec,
+		"failure",
+	)
+
+	return nil
+}
+
+func (c *Collector) collectNBTConnection(ch chan<- prometheus.Metric) error {
+	err := c.nbtConnectionPerfDataCollector.Collect(&c.nbtConnectionPerfDataObject)
+	if err != nil {
+		return fmt.Errorf("failed to collect NBT Connection metrics: %w", err)
+	}
+
+	for _, data := range c.nbtConnectionPerfDataObject {
+		ch <- prometheus.MustNewConstMetric(
+			c.nbtConnectionBytesReceivedTotal,
+			prometheus.CounterValue,
+			data.BytesReceivedPerSec,
+			data.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.nbtConnectionBytesSentTotal,
+			prometheus.CounterValue,
+			data.BytesSentPerSec,
+			data.Name,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,412 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package wmi_health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const Name = "wmi_health"
+
+type Config struct {
+	// Checks are the WMI classes to query, as namespace:class, e.g. root/CIMv2:Win32_OperatingSystem.
+	Checks []string `yaml:"checks"`
+	// CheckInterval is the interval of the checks.
+	CheckInterval time.Duration `yaml:"check-interval"`
+	// Timeout is the time after which a query is cancelled and the check fails.
+	Timeout time.Duration `yaml:"timeout"`
+	// LatencyThreshold is the duration of a query above which the namespace is reported as unhealthy.
+	LatencyThreshold time.Duration `yaml:"latency-threshold"`
+}
+
+// The default checks query the namespaces used by the collectors enabled by default and by the storage collectors.
+//
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{
+	Checks: []string{
+		"root/CIMv2:Win32_OperatingSystem",
+		"root/StandardCimv2:MSFT_NetAdapter",
+		"root/Microsoft/Windows/Storage:MSFT_PhysicalDisk",
+	},
+	CheckInterval:    time.Minute,
+	Timeout:          10 * time.Second,
+	LatencyThreshold: 2 * time.Second,
+}
+
+//nolint:gochecknoglobals
+var classNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// A Collector is a Prometheus Collector that checks whether WMI namespaces answer queries.
+// A degraded WMI repository breaks all collectors using WMI at once, so the checks run in the background every
+// CheckInterval and a hung query doesn't block the scrapes.
+type Collector struct {
+	config    Config
+	logger    *slog.Logger
+	miSession *mi.Session
+
+	ctxCancelFn context.CancelFunc
+	checks      []check
+
+	// mu protects the results of the last checks.
+	mu        sync.RWMutex
+	results   []checkResult
+	checkTime time.Time
+
+	healthy               *prometheus.Desc
+	querySuccess          *prometheus.Desc
+	queryDuration         *prometheus.Desc
+	queryInstances        *prometheus.Desc
+	checkTimestampSeconds *prometheus.Desc
+}
+
+type check struct {
+	namespace string
+	class     string
+
+	miNamespace mi.Namespace
+	miQuery     mi.Query
+}
+
+type checkResult struct {
+	success   bool
+	duration  time.Duration
+	instances int
+}
+
+// instance is the result type of the check queries. All properties are ignored, only the instances are counted.
+type instance struct{}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	if config.Checks == nil {
+		config.Checks = ConfigDefaults.Checks
+	}
+
+	if config.CheckInterval == 0 {
+		config.CheckInterval = ConfigDefaults.CheckInterval
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = ConfigDefaults.Timeout
+	}
+
+	if config.LatencyThreshold == 0 {
+		config.LatencyThreshold = ConfigDefaults.LatencyThreshold
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(app *kingpin.Application) *Collector {
+	c := &Collector{
+		config: ConfigDefaults,
+	}
+
+	var checks string
+
+	app.Flag(
+		"collector.wmi_health.checks",
+		"Comma-separated list of WMI classes to query, as namespace:class.",
+	).Default(strings.Join(ConfigDefaults.Checks, ",")).StringVar(&checks)
+
+	app.Flag(
+		"collector.wmi_health.check-interval",
+		"Interval of the WMI checks.",
+	).Default(ConfigDefaults.CheckInterval.String()).DurationVar(&c.config.CheckInterval)
+
+	app.Flag(
+		"collector.wmi_health.timeout",
+		"Timeout of each WMI query.",
+	).Default(ConfigDefaults.Timeout.String()).DurationVar(&c.config.Timeout)
+
+	app.Flag(
+		"collector.wmi_health.latency-threshold",
+		"Duration of a WMI query above which the namespace is reported as unhealthy.",
+	).Default(ConfigDefaults.LatencyThreshold.String()).DurationVar(&c.config.LatencyThreshold)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config.Checks = make([]string, 0)
+
+		for item := range strings.SplitSeq(checks, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				c.config.Checks = append(c.config.Checks, item)
+			}
+		}
+
+		return nil
+	})
+
+	return c
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+// LastSuccess returns the time of the last successful checks, see collector.BackgroundCollector.
+func (c *Collector) LastSuccess() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.checkTime
+}
+
+func (c *Collector) Close() error {
+	if c.ctxCancelFn != nil {
+		c.ctxCancelFn()
+	}
+
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	c.logger = logger.With(slog.String("collector", Name))
+
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.miSession = miSession
+
+	if c.config.CheckInterval <= 0 {
+		return fmt.Errorf("invalid check interval %s, expected a positive duration", c.config.CheckInterval)
+	}
+
+	if c.config.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout %s, expected a positive duration", c.config.Timeout)
+	}
+
+	if len(c.config.Checks) == 0 {
+		return errors.New("no checks configured, see --collector.wmi_health.checks")
+	}
+
+	c.checks = make([]check, 0, len(c.config.Checks))
+
+	for _, item := range c.config.Checks {
+		check, err := parseCheck(item)
+		if err != nil {
+			return err
+		}
+
+		c.checks = append(c.checks, check)
+	}
+
+	c.healthy = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "healthy"),
+		"Whether the query of the class succeeded within the latency threshold",
+		[]string{"namespace", "class"},
+		nil,
+	)
+	c.querySuccess = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "query_success"),
+		"Whether the query of the class succeeded within the timeout",
+		[]string{"namespace", "class"},
+		nil,
+	)
+	c.queryDuration = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "query_duration_seconds"),
+		"Duration of the query of the class, including failed queries",
+		[]string{"namespace", "class"},
+		nil,
+	)
+	c.queryInstances = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "query_instances"),
+		"Number of instances returned by the query of the class",
+		[]string{"namespace", "class"},
+		nil,
+	)
+	c.checkTimestampSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "check_timestamp_seconds"),
+		"Timestamp of the end of the last WMI checks",
+		nil,
+		nil,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.ctxCancelFn = cancel
+
+	go c.scheduleChecks(ctx)
+
+	return nil
+}
+
+// parseCheck parses a check in the format namespace:class. Backslashes in the namespace are accepted like in PowerShell.
+func parseCheck(item string) (check, error) {
+	namespace, class, ok := strings.Cut(item, ":")
+	if !ok || namespace == "" {
+		return check{}, fmt.Errorf("invalid check %q, expected namespace:class", item)
+	}
+
+	namespace = strings.ReplaceAll(namespace, `\`, "/")
+
+	if !classNameRegexp.MatchString(class) {
+		return check{}, fmt.Errorf("invalid class name %q of check %q", class, item)
+	}
+
+	miNamespace, err := mi.NewNamespace(namespace)
+	if err != nil {
+		return check{}, fmt.Errorf("invalid namespace of check %q: %w", item, err)
+	}
+
+	miQuery, err := mi.NewQuery("SELECT * FROM " + class)
+	if err != nil {
+		return check{}, fmt.Errorf("invalid class name of check %q: %w", item, err)
+	}
+
+	return check{
+		namespace:   namespace,
+		class:       class,
+		miNamespace: miNamespace,
+		miQuery:     miQuery,
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// The metrics are missing until the first checks finished.
+	if c.checkTime.IsZero() {
+		return nil
+	}
+
+	for i, result := range c.results {
+		check := c.checks[i]
+
+		ch <- prometheus.MustNewConstMetric(
+			c.healthy,
+			prometheus.GaugeValue,
+			boolToFloat(result.success && result.duration <= c.config.LatencyThreshold),
+			check.namespace, check.class,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.querySuccess,
+			prometheus.GaugeValue,
+			boolToFloat(result.success),
+			check.namespace, check.class,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.queryDuration,
+			prometheus.GaugeValue,
+			result.duration.Seconds(),
+			check.namespace, check.class,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.queryInstances,
+			prometheus.GaugeValue,
+			float64(result.instances),
+			check.namespace, check.class,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.checkTimestampSeconds,
+		prometheus.GaugeValue,
+		float64(c.checkTime.Unix()),
+	)
+
+	return nil
+}
+
+func (c *Collector) scheduleChecks(ctx context.Context) {
+	for {
+		c.runChecks(ctx)
+
+		select {
+		case <-time.After(c.config.CheckInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runChecks queries the classes one after another, so a degraded WMI service isn't loaded with parallel queries.
+func (c *Collector) runChecks(ctx context.Context) {
+	results := make([]checkResult, len(c.checks))
+
+	for i, check := range c.checks {
+		results[i] = c.runCheck(ctx, check)
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.results = results
+	c.checkTime = time.Now()
+}
+
+func (c *Collector) runCheck(ctx context.Context, check check) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	var dst []instance
+
+	start := time.Now()
+	err := c.miSession.QueryContext(ctx, &dst, check.miNamespace, check.miQuery)
+	duration := time.Since(start)
+
+	if err != nil {
+		c.logger.LogAttrs(ctx, slog.LevelWarn, "WMI check failed",
+			slog.String("namespace", check.namespace),
+			slog.String("class", check.class),
+			slog.Duration("duration", duration),
+			slog.Any("err", err),
+		)
+
+		return checkResult{duration: duration}
+	}
+
+	return checkResult{
+		success:   true,
+		duration:  duration,
+		instances: len(dst),
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,317 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package wmi_provider_host
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/headers/psapi"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const Name = "wmi_provider_host"
+
+type Config struct{}
+
+//nolint:gochecknoglobals
+var ConfigDefaults = Config{}
+
+//nolint:gochecknoglobals
+var providersQuery = utils.Must(mi.NewQuery("SELECT HostProcessIdentifier, Namespace, provider, HostingModel FROM MSFT_Providers"))
+
+// A Collector is a Prometheus Collector for the resource usage of the WMI provider hosts (WmiPrvSE.exe)
+// and the WMI providers loaded by each host. A host may load several providers, so the resource usage is exported
+// per host and attributed to the providers by joining wmi_provider_host_provider_info on process_id.
+type Collector struct {
+	config    Config
+	logger    *slog.Logger
+	miSession *mi.Session
+
+	// mu protects the CLSIDs against concurrent scrapes.
+	mu sync.Mutex
+	// clsids are the CLSIDs of the providers by namespace and provider name. Registrations rarely change,
+	// so each provider is looked up once.
+	clsids map[providerKey]string
+
+	providerInfo     *prometheus.Desc
+	cpuTimeTotal     *prometheus.Desc
+	workingSetBytes  *prometheus.Desc
+	privateBytes     *prometheus.Desc
+	startTimeSeconds *prometheus.Desc
+}
+
+type providerKey struct {
+	namespace string
+	provider  string
+}
+
+type msftProviders struct {
+	HostProcessIdentifier uint32 `mi:"HostProcessIdentifier"`
+	Namespace             string `mi:"Namespace"`
+	Provider              string `mi:"provider"`
+	HostingModel          string `mi:"HostingModel"`
+}
+
+type win32Provider struct {
+	CLSID string `mi:"CLSID"`
+}
+
+func New(config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	return c
+}
+
+func NewWithFlags(_ *kingpin.Application) *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) GetName() string {
+	return Name
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	if miSession == nil {
+		return errors.New("miSession is nil")
+	}
+
+	c.logger = logger.With(slog.String("collector", Name))
+	c.miSession = miSession
+	c.clsids = make(map[providerKey]string)
+
+	c.providerInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "provider_info"),
+		"WMI provider loaded by the provider host process, by namespace, provider, CLSID and hosting model",
+		[]string{"process_id", "namespace", "provider", "clsid", "hosting_model"},
+		nil,
+	)
+	c.cpuTimeTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "cpu_time_total"),
+		"CPU time of the provider host process in seconds, by mode (user, privileged)",
+		[]string{"process_id", "mode"},
+		nil,
+	)
+	c.workingSetBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "working_set_bytes"),
+		"Working set of the provider host process",
+		[]string{"process_id"},
+		nil,
+	)
+	c.privateBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "private_bytes"),
+		"Private memory committed by the provider host process",
+		[]string{"process_id"},
+		nil,
+	)
+	c.startTimeSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(types.Namespace, Name, "start_time_timestamp_seconds"),
+		"Start time of the provider host process as unix timestamp",
+		[]string{"process_id"},
+		nil,
+	)
+
+	var dst []msftProviders
+	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, providersQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	return nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) error {
+	var providers []msftProviders
+	if err := c.miSession.Query(&providers, mi.NamespaceRootCIMv2, providersQuery); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hosts := make(map[uint32]struct{})
+
+	for _, provider := range providers {
+		// Providers loaded in-process of the WMI service (winmgmt) or of a decoupled provider have no provider host.
+		if provider.HostProcessIdentifier == 0 {
+			continue
+		}
+
+		hosts[provider.HostProcessIdentifier] = struct{}{}
+		namespace := strings.ReplaceAll(provider.Namespace, `\`, "/")
+
+		ch <- prometheus.MustNewConstMetric(
+			c.providerInfo,
+			prometheus.GaugeValue,
+			1,
+			strconv.FormatUint(uint64(provider.HostProcessIdentifier), 10),
+			namespace,
+			provider.Provider,
+			c.clsid(namespace, provider.Provider),
+			provider.HostingModel,
+		)
+	}
+
+	for pid := range hosts {
+		if err := c.collectHost(ch, pid); err != nil {
+			// The provider host exits after its providers were idle for a while.
+			c.logger.Debug("failed to collect provider host",
+				slog.Uint64("process_id", uint64(pid)),
+				slog.Any("err", err),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (c *Collector) collectHost(ch chan<- prometheus.Metric, pid uint32) error {
+	process, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return fmt.Errorf("failed to open process: %w", err)
+	}
+
+	defer func() {
+		_ = windows.CloseHandle(process)
+	}()
+
+	var creation, exit, kernel, user windows.Filetime
+
+	if err := windows.GetProcessTimes(process, &creation, &exit, &kernel, &user); err != nil {
+		return fmt.Errorf("failed to get process times: %w", err)
+	}
+
+	memory, err := psapi.GetProcessMemoryInfo(process)
+	if err != nil {
+		return fmt.Errorf("failed to get process memory info: %w", err)
+	}
+
+	processID := strconv.FormatUint(uint64(pid), 10)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.cpuTimeTotal,
+		prometheus.CounterValue,
+		filetimeDuration(user).Seconds(),
+		processID, "user",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.cpuTimeTotal,
+		prometheus.CounterValue,
+		filetimeDuration(kernel).Seconds(),
+		processID, "privileged",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.workingSetBytes,
+		prometheus.GaugeValue,
+		float64(memory.WorkingSetSize),
+		processID,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.privateBytes,
+		prometheus.GaugeValue,
+		float64(memory.PrivateUsage),
+		processID,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.startTimeSeconds,
+		prometheus.GaugeValue,
+		float64(creation.Nanoseconds())/float64(time.Second),
+		processID,
+	)
+
+	return nil
+}
+
+// clsid returns the CLSID of the provider registration in the namespace, or an empty string if it can't be read.
+// Must be called with mu held.
+func (c *Collector) clsid(namespace, provider string) string {
+	key := providerKey{namespace: namespace, provider: provider}
+
+	if clsid, ok := c.clsids[key]; ok {
+		return clsid
+	}
+
+	clsid, err := c.queryCLSID(namespace, provider)
+	if err != nil {
+		c.logger.Debug("failed to query provider registration",
+			slog.String("namespace", namespace),
+			slog.String("provider", provider),
+			slog.Any("err", err),
+		)
+
+		// Not cached, so the registration is queried again on the next scrape.
+		return ""
+	}
+
+	c.clsids[key] = clsid
+
+	return clsid
+}
+
+func (c *Collector) queryCLSID(namespace, provider string) (string, error) {
+	miNamespace, err := mi.NewNamespace(namespace)
+	if err != nil {
+		return "", err
+	}
+
+	miQuery, err := mi.NewQuery(fmt.Sprintf("SELECT CLSID FROM __Win32Provider WHERE Name = '%s'", strings.ReplaceAll(provider, "'", `\'`)))
+	if err != nil {
+		return "", err
+	}
+
+	var registrations []win32Provider
+	if err := c.miSession.Query(&registrations, miNamespace, miQuery); err != nil {
+		return "", err
+	}
+
+	if len(registrations) == 0 {
+		return "", nil
+	}
+
+	return strings.ToLower(strings.Trim(registrations[0].CLSID, "{}")), nil
+}
+
+// filetimeDuration converts a FILETIME holding a duration in 100ns intervals, e.g. the CPU time of GetProcessTimes.
+func filetimeDuration(ft windows.Filetime) time.Duration {
+	return time.Duration(uint64(ft.HighDateTime)<<32|uint64(ft.LowDateTime)) * 100
+}
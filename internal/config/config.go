@@ -21,10 +21,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"strings"
 
 	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/httphandler"
 	"github.com/prometheus-community/windows_exporter/pkg/collector"
 	"go.yaml.in/yaml/v3"
 )
@@ -35,11 +37,27 @@ type configFile struct {
 	Debug struct {
 		Enabled bool `yaml:"enabled"`
 	} `yaml:"debug"`
+	Profile    string                               `yaml:"profile"`
+	Profiles   map[string]httphandler.ProfileConfig `yaml:"profiles"`
 	Collectors struct {
-		Enabled string `yaml:"enabled"`
+		Enabled                string `yaml:"enabled"`
+		Disabled               string `yaml:"disabled"`
+		PluginsFile            string `yaml:"plugins-file"`
+		MaxConcurrency         string `yaml:"max-concurrency"`
+		SelfTest               string `yaml:"self-test"`
+		SelfTestScrapeInterval string `yaml:"self-test.scrape-interval"`
+		Maintenance            string `yaml:"maintenance"`
+		MaintenanceSuppress    string `yaml:"maintenance.suppress"`
 	} `yaml:"collectors"`
 	Collector collector.Config `yaml:"collector"`
-	Log       struct {
+	Compat    struct {
+		MetricNames        string `yaml:"metric-names"`
+		MetricNamesExclude string `yaml:"metric-names.exclude"`
+	} `yaml:"compat"`
+	Relabel struct {
+		ConfigFile string `yaml:"config-file"`
+	} `yaml:"relabel"`
+	Log struct {
 		Level  string `yaml:"level"`
 		Format string `yaml:"format"`
 		File   string `yaml:"file"`
@@ -49,15 +67,70 @@ type configFile struct {
 		MemoryLimit string `yaml:"memory-limit"`
 	} `yaml:"process"`
 	Scrape struct {
-		TimeoutMargin string `yaml:"timeout-margin"`
+		TimeoutMargin     string `yaml:"timeout-margin"`
+		CollectorTimeouts string `yaml:"collector-timeouts"`
 	} `yaml:"scrape"`
+	Probe struct {
+		Enabled        bool   `yaml:"enabled"`
+		Collectors     string `yaml:"collectors"`
+		Protocol       string `yaml:"protocol"`
+		Authentication string `yaml:"authentication"`
+		Username       string `yaml:"username"`
+		PasswordFile   string `yaml:"password-file"`
+		Targets        string `yaml:"targets"`
+	} `yaml:"probe"`
+	OTLP struct {
+		Endpoint string `yaml:"endpoint"`
+		Interval string `yaml:"interval"`
+		Timeout  string `yaml:"timeout"`
+		Headers  string `yaml:"headers"`
+		Align    bool   `yaml:"align"`
+		Jitter   string `yaml:"jitter"`
+	} `yaml:"otlp"`
+	SNMP struct {
+		ListenAddress   string `yaml:"listen-address"`
+		Community       string `yaml:"community"`
+		BaseOID         string `yaml:"base-oid"`
+		RefreshInterval string `yaml:"refresh-interval"`
+	} `yaml:"snmp"`
+	Zabbix struct {
+		Server   string `yaml:"server"`
+		Host     string `yaml:"host"`
+		Interval string `yaml:"interval"`
+		Timeout  string `yaml:"timeout"`
+		KeysFile string `yaml:"keys-file"`
+	} `yaml:"zabbix"`
+	Snapshot struct {
+		Path     string `yaml:"path"`
+		Interval string `yaml:"interval"`
+		Timeout  string `yaml:"timeout"`
+	} `yaml:"snapshot"`
+	RemoteWrite struct {
+		URL      string `yaml:"url"`
+		Interval string `yaml:"interval"`
+		Timeout  string `yaml:"timeout"`
+		Headers  string `yaml:"headers"`
+		Labels   string `yaml:"labels"`
+		WALDir   string `yaml:"wal-dir"`
+		MaxSize  string `yaml:"max-size"`
+	} `yaml:"remote-write"`
 	Telemetry struct {
 		Path string `yaml:"path"`
 	} `yaml:"telemetry"`
 	Web struct {
-		DisableExporterMetrics bool `yaml:"disable-exporter-metrics"`
-		ListenAddresses        any  `yaml:"listen-address"`
-		Config                 struct {
+		DisableExporterMetrics bool   `yaml:"disable-exporter-metrics"`
+		EnableReload           bool   `yaml:"enable-reload"`
+		EnableDelta            bool   `yaml:"enable-delta"`
+		CacheDuration          string `yaml:"cache-duration"`
+		CacheCollectors        string `yaml:"cache-collectors"`
+		AdminAPI               struct {
+			TokenFile string `yaml:"token-file"`
+		} `yaml:"admin-api"`
+		ProfilesFile     string `yaml:"profiles-file"`
+		AllowedNetworks  string `yaml:"allowed-networks"`
+		AllowedClientCNs string `yaml:"allowed-client-cns"`
+		ListenAddresses  any    `yaml:"listen-address"`
+		Config           struct {
 			File string `yaml:"file"`
 		} `yaml:"config"`
 	} `yaml:"web"`
@@ -73,24 +146,57 @@ type Resolver struct {
 }
 
 // Parse parses the command line arguments and configuration files.
-func Parse(app *kingpin.Application, args []string) error {
+// It returns the selected command.
+func Parse(app *kingpin.Application, args []string) (string, error) {
 	configFile := ParseConfigFile(args)
-	if configFile != "" {
-		resolver, err := NewConfigFileResolver(configFile)
+	profile := ParseProfile(args)
+
+	switch {
+	case configFile != "":
+		resolver, err := NewConfigFileResolver(configFile, profile)
 		if err != nil {
-			return fmt.Errorf("failed to load configuration file: %w", err)
+			return "", fmt.Errorf("failed to load configuration file: %w", err)
 		}
 
 		if err = resolver.Bind(app, args); err != nil {
-			return fmt.Errorf("failed to bind configuration: %w", err)
+			return "", fmt.Errorf("failed to bind configuration: %w", err)
 		}
+	case profile != "":
+		return "", errors.New("--profile requires a configuration file with profiles, see --config.file")
 	}
 
-	if _, err := app.Parse(args); err != nil {
-		return fmt.Errorf("failed to parse flags: %w", err)
+	command, err := app.Parse(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse flags: %w", err)
 	}
 
-	return nil
+	return command, nil
+}
+
+// ParseWithProfile parses the command line arguments and configuration files like Parse, but the values
+// of profile take the place of the values of the configuration file and of its selected profile,
+// e.g. to build the collectors of a scrape profile.
+func ParseWithProfile(app *kingpin.Application, args []string, profile httphandler.ProfileConfig) (string, error) {
+	var rawValues map[string]any
+
+	if configFile := ParseConfigFile(args); configFile != "" {
+		var err error
+
+		if _, rawValues, err = readConfigFile(configFile); err != nil {
+			return "", fmt.Errorf("failed to load configuration file: %w", err)
+		}
+	}
+
+	if err := newResolver(rawValues, ProfileValues(profile)).Bind(app, args); err != nil {
+		return "", fmt.Errorf("failed to bind configuration: %w", err)
+	}
+
+	command, err := app.Parse(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	return command, nil
 }
 
 // ParseConfigFile manually parses the configuration file from the command line arguments.
@@ -116,54 +222,103 @@ func ParseConfigFile(args []string) string {
 	return ""
 }
 
-// NewConfigFileResolver returns a Resolver structure.
-func NewConfigFileResolver(filePath string) (*Resolver, error) {
-	flags := map[string]string{}
+// NewConfigFileResolver returns a Resolver structure. The values of the profile, or of the profile
+// selected in the configuration file if profile is empty, take the place of the values of the configuration file.
+func NewConfigFileResolver(filePath, profile string) (*Resolver, error) {
+	configFileStructure, rawValues, err := readConfigFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle an empty file, indicating no configuration was found.
+	if rawValues == nil {
+		if profile != "" {
+			return nil, fmt.Errorf("unknown profile %q, the configuration file has no profiles", profile)
+		}
+
+		return newResolver(nil, nil), nil
+	}
+
+	if err := validateProfiles(configFileStructure.Profiles); err != nil {
+		return nil, err
+	}
+
+	if profile == "" {
+		profile = configFileStructure.Profile
+	}
+
+	var values map[string]string
+
+	if profile != "" {
+		values, err = profileValues(profile, configFileStructure.Profiles)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newResolver(rawValues, values), nil
+}
+
+// newResolver returns a Resolver of the values of the configuration file. The values of the profile take the place
+// of the values of the configuration file.
+func newResolver(rawValues map[string]any, profileValues map[string]string) *Resolver {
+	flags := maps.Clone(profileValues)
+	if flags == nil {
+		flags = map[string]string{}
+	}
+
+	// The profiles are no flags, the values of the selected profile are in profileValues.
+	delete(rawValues, "profiles")
+
+	// Flatten nested YAML values
+	flattenedValues := flatten(rawValues)
+	for k, v := range flattenedValues {
+		if _, ok := flags[k]; !ok {
+			flags[k] = v
+		}
+	}
+
+	return &Resolver{flags: flags}
+}
+
+// readConfigFile decodes the configuration file strictly and returns the undecoded values for the flags.
+// The values are nil for an empty file.
+func readConfigFile(filePath string) (configFile, map[string]any, error) {
+	var configFileStructure configFile
 
 	file, err := os.Open(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open configuration file: %w", err)
+		return configFileStructure, nil, fmt.Errorf("failed to open configuration file: %w", err)
 	}
 
 	defer func() {
 		_ = file.Close()
 	}()
 
-	var configFileStructure configFile
-
 	decoder := yaml.NewDecoder(file)
 	decoder.KnownFields(true)
 
 	if err = decoder.Decode(&configFileStructure); err != nil {
-		// Handle EOF error gracefully, indicating no configuration was found.
 		if errors.Is(err, io.EOF) {
-			return &Resolver{flags: flags}, nil
+			return configFileStructure, nil, nil
 		}
 
-		return nil, fmt.Errorf("configuration file validation error: %w", err)
+		return configFileStructure, nil, fmt.Errorf("configuration file validation error: %w", err)
 	}
 
 	_, err = file.Seek(0, io.SeekStart)
 	if err != nil {
-		return nil, fmt.Errorf("failed to rewind file: %w", err)
+		return configFileStructure, nil, fmt.Errorf("failed to rewind file: %w", err)
 	}
 
 	var rawValues map[string]interface{}
 
 	decoder = yaml.NewDecoder(file)
 	if err = decoder.Decode(&rawValues); err != nil {
-		return nil, fmt.Errorf("failed to parse configuration file: %w", err)
-	}
-
-	// Flatten nested YAML values
-	flattenedValues := flatten(rawValues)
-	for k, v := range flattenedValues {
-		if _, ok := flags[k]; !ok {
-			flags[k] = v
-		}
+		return configFileStructure, nil, fmt.Errorf("failed to parse configuration file: %w", err)
 	}
 
-	return &Resolver{flags: flags}, nil
+	return configFileStructure, rawValues, nil
 }
 
 func (c *Resolver) setDefault(v getFlagger) {
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// SetEnabledCollectors sets collectors.enabled in the configuration file.
+// Other values and comments in the file are kept.
+func SetEnabledCollectors(filePath string, collectors []string) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return fmt.Errorf("failed to parse configuration file: %w", err)
+	}
+
+	// An empty file has no document node.
+	if len(document.Content) == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return errors.New("configuration file is not a YAML mapping")
+	}
+
+	collectorsNode, err := mappingValue(root, "collectors", yaml.MappingNode)
+	if err != nil {
+		return err
+	}
+
+	enabledNode, err := mappingValue(collectorsNode, "enabled", yaml.ScalarNode)
+	if err != nil {
+		return err
+	}
+
+	enabledNode.Tag = "!!str"
+	enabledNode.Value = strings.Join(collectors, ",")
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&document); err != nil {
+		return fmt.Errorf("failed to encode configuration file: %w", err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode configuration file: %w", err)
+	}
+
+	// Write a temporary file first, so a failed write doesn't leave a truncated configuration file behind.
+	if err := os.WriteFile(filePath+".tmp", buf.Bytes(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write configuration file: %w", err)
+	}
+
+	if err := os.Rename(filePath+".tmp", filePath); err != nil {
+		return fmt.Errorf("failed to replace configuration file: %w", err)
+	}
+
+	return nil
+}
+
+// mappingValue returns the value of key in the YAML mapping. A missing key is added with an empty value of the given kind.
+func mappingValue(mapping *yaml.Node, key string, kind yaml.Kind) (*yaml.Node, error) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+
+		value := mapping.Content[i+1]
+		if value.Kind != kind {
+			return nil, fmt.Errorf("unexpected type of %s in configuration file", key)
+		}
+
+		return value, nil
+	}
+
+	value := &yaml.Node{Kind: kind}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+
+	return value, nil
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetEnabledCollectors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name: "existing",
+			content: `# collectors of this host
+collectors:
+  enabled: cpu,os # trailing comment
+log:
+  level: debug
+`,
+			expected: `# collectors of this host
+collectors:
+  enabled: cpu,os,service # trailing comment
+log:
+  level: debug
+`,
+		},
+		{
+			name: "missing",
+			content: `log:
+  level: debug
+`,
+			expected: `log:
+  level: debug
+collectors:
+  enabled: cpu,os,service
+`,
+		},
+		{
+			name:    "empty",
+			content: ``,
+			expected: `collectors:
+  enabled: cpu,os,service
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(filePath, []byte(tc.content), 0o600))
+
+			require.NoError(t, SetEnabledCollectors(filePath, []string{"cpu", "os", "service"}))
+
+			content, err := os.ReadFile(filePath)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(content))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		filePath := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(filePath, []byte("collectors: cpu\n"), 0o600))
+
+		require.Error(t, SetEnabledCollectors(filePath, []string{"cpu"}))
+	})
+}
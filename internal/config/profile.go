@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/httphandler"
+)
+
+//nolint:gochecknoglobals
+var profileNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ReadProfiles returns the profiles of the configuration file by name, e.g.
+//
+//	profile: sql-server
+//	profiles:
+//	  sql-server:
+//	    collectors: ["[defaults]", mssql]
+//	    collector:
+//	      service:
+//	        include: "MSSQL.*|SQLAgent.*"
+//
+// The profiles have the schema of the profiles of --web.profiles-file, see httphandler.ProfilesConfig.
+func ReadProfiles(filePath string) (map[string]httphandler.ProfileConfig, error) {
+	configFileStructure, _, err := readConfigFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateProfiles(configFileStructure.Profiles); err != nil {
+		return nil, err
+	}
+
+	return configFileStructure.Profiles, nil
+}
+
+// ProfileValues returns the flag values of a profile, which take the place of the values of the configuration file:
+// collectors and exclude are the values of collectors.enabled and collectors.disabled, the collector
+// section the values of the collector.* flags. The filters of a profile only apply to the scrapes of the profile.
+func ProfileValues(profile httphandler.ProfileConfig) map[string]string {
+	values := flatten(map[string]any{"collector": map[string]any(profile.Collector)})
+
+	if len(profile.Collectors) > 0 {
+		values["collectors.enabled"] = strings.Join(profile.Collectors, ",")
+	}
+
+	if len(profile.Exclude) > 0 {
+		values["collectors.disabled"] = strings.Join(profile.Exclude, ",")
+	}
+
+	return values
+}
+
+// validateProfiles checks the profile names, which are also selected per scrape by the path <telemetry.path>/<name>.
+func validateProfiles(profiles map[string]httphandler.ProfileConfig) error {
+	for name := range profiles {
+		if !profileNameRegexp.MatchString(name) {
+			return fmt.Errorf("invalid profile name %q, expected letters, digits, _ and -", name)
+		}
+	}
+
+	return nil
+}
+
+// profileValues returns the flag values of the profile of the configuration file.
+func profileValues(name string, profiles map[string]httphandler.ProfileConfig) (map[string]string, error) {
+	profile, ok := profiles[name]
+	if !ok {
+		if len(profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile %q, the configuration file has no profiles", name)
+		}
+
+		return nil, fmt.Errorf("unknown profile %q, expected one of %s", name, strings.Join(slices.Sorted(maps.Keys(profiles)), ", "))
+	}
+
+	return ProfileValues(profile), nil
+}
+
+// ParseProfile returns the profile selected on the command line with --profile.
+func ParseProfile(args []string) string {
+	for i, arg := range args {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "profile" {
+			continue
+		}
+
+		if hasValue {
+			return value
+		}
+
+		if len(args) > i+1 {
+			return args[i+1]
+		}
+	}
+
+	return ""
+}
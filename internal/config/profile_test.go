@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus-community/windows_exporter/internal/httphandler"
+	"github.com/stretchr/testify/require"
+)
+
+const profilesConfig = `collectors:
+  enabled: cpu,os
+collector:
+  service:
+    include: "wuauserv"
+log:
+  level: debug
+profile: sql-server
+profiles:
+  sql-server:
+    collectors: ["[defaults]", mssql]
+    collector:
+      service:
+        include: "MSSQL.*|SQLAgent.*"
+  domain-controller:
+    collectors: ["[defaults]", ad, dns]
+    exclude: [physical_disk]
+  empty:
+`
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestNewConfigFileResolverProfile(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfigFile(t, profilesConfig)
+
+	for _, tc := range []struct {
+		name     string
+		profile  string
+		expected map[string]string
+	}{
+		{
+			name:    "profile of the configuration file",
+			profile: "",
+			expected: map[string]string{
+				"collectors.enabled":        "[defaults],mssql",
+				"collector.service.include": "MSSQL.*|SQLAgent.*",
+				"log.level":                 "debug",
+				"profile":                   "sql-server",
+			},
+		},
+		{
+			name:    "profile of the command line",
+			profile: "domain-controller",
+			expected: map[string]string{
+				"collectors.enabled":        "[defaults],ad,dns",
+				"collectors.disabled":       "physical_disk",
+				"collector.service.include": "wuauserv",
+				"log.level":                 "debug",
+				"profile":                   "sql-server",
+			},
+		},
+		{
+			name:    "profile without values",
+			profile: "empty",
+			expected: map[string]string{
+				"collectors.enabled":        "cpu,os",
+				"collector.service.include": "wuauserv",
+				"log.level":                 "debug",
+				"profile":                   "sql-server",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resolver, err := NewConfigFileResolver(path, tc.profile)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, resolver.flags)
+		})
+	}
+}
+
+func TestNewConfigFileResolverProfileErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewConfigFileResolver(writeConfigFile(t, profilesConfig), "exchange")
+	require.ErrorContains(t, err, `unknown profile "exchange", expected one of domain-controller, empty, sql-server`)
+
+	_, err = NewConfigFileResolver(writeConfigFile(t, "log:\n  level: debug\n"), "sql-server")
+	require.ErrorContains(t, err, "the configuration file has no profiles")
+
+	_, err = NewConfigFileResolver(writeConfigFile(t, "profiles:\n  sql/server:\n    collectors: [mssql]\n"), "")
+	require.ErrorContains(t, err, "invalid profile name")
+
+	_, err = NewConfigFileResolver(writeConfigFile(t, "profiles:\n  sql-server:\n    log:\n      level: debug\n"), "")
+	require.ErrorContains(t, err, "configuration file validation error")
+
+	_, err = NewConfigFileResolver(writeConfigFile(t, "profiles:\n  sql-server:\n    collector:\n      servce:\n        include: MSSQL.*\n"), "")
+	require.ErrorContains(t, err, "invalid collector settings")
+}
+
+func TestReadProfiles(t *testing.T) {
+	t.Parallel()
+
+	profiles, err := ReadProfiles(writeConfigFile(t, profilesConfig))
+	require.NoError(t, err)
+	require.Equal(t, map[string]httphandler.ProfileConfig{
+		"sql-server": {
+			Collectors: []string{"[defaults]", "mssql"},
+			Collector:  httphandler.ProfileSettings{"service": map[string]any{"include": "MSSQL.*|SQLAgent.*"}},
+		},
+		"domain-controller": {Collectors: []string{"[defaults]", "ad", "dns"}, Exclude: []string{"physical_disk"}},
+		"empty":             {},
+	}, profiles)
+}
+
+func TestProfileValues(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, map[string]string{
+		"collectors.enabled":                 "[defaults],iis",
+		"collectors.disabled":                "physical_disk",
+		"collector.iis.site-include":         "Default Web Site",
+		"collector.service.include":          "W3SVC|WAS",
+		"collector.logical_disk.volume-list": "C:,D:",
+	}, ProfileValues(httphandler.ProfileConfig{
+		Collectors: []string{"[defaults]", "iis"},
+		Exclude:    []string{"physical_disk"},
+		Filters:    map[string]httphandler.ProfileFilter{"iis": {Include: map[string]string{"site": "Default.*"}}},
+		Collector: httphandler.ProfileSettings{
+			"iis":          map[string]any{"site-include": "Default Web Site"},
+			"service":      map[string]any{"include": "W3SVC|WAS"},
+			"logical_disk": map[string]any{"volume-list": []any{"C:", "D:"}},
+		},
+	}))
+
+	require.Empty(t, ProfileValues(httphandler.ProfileConfig{}))
+}
+
+func TestParseProfile(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{args: []string{"--profile=sql-server"}, expected: "sql-server"},
+		{args: []string{"-profile=sql-server"}, expected: "sql-server"},
+		{args: []string{"--config.file=config.yaml", "--profile", "sql-server"}, expected: "sql-server"},
+		{args: []string{"--profiles-file=profiles.yaml", "profile"}, expected: ""},
+		{args: []string{"--web.profiles-file=profiles.yaml"}, expected: ""},
+		{args: nil, expected: ""},
+	} {
+		require.Equal(t, tc.expected, ParseProfile(tc.args), tc.args)
+	}
+}
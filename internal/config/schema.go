@@ -0,0 +1,401 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/httphandler"
+	"go.yaml.in/yaml/v3"
+)
+
+// commandLineOnlyFlags are the flags that can't be set in the configuration file.
+//
+//nolint:gochecknoglobals
+var commandLineOnlyFlags = []string{"help", "version", "config.file", "collector.exchange.list"}
+
+// durationPattern matches the durations accepted by time.ParseDuration.
+const durationPattern = `^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+
+//nolint:gochecknoglobals
+var (
+	durationType         = reflect.TypeFor[time.Duration]()
+	regexpType           = reflect.TypeFor[*regexp.Regexp]()
+	textUnmarshalerType  = reflect.TypeFor[encoding.TextUnmarshaler]()
+	yamlUnmarshalerType  = reflect.TypeFor[yaml.Unmarshaler]()
+	configFileStructType = reflect.TypeFor[configFile]()
+	profilesType         = reflect.TypeFor[map[string]httphandler.ProfileConfig]()
+)
+
+// configNode is a section or a value of the configuration file.
+type configNode struct {
+	key  string
+	typ  reflect.Type
+	flag *kingpin.FlagModel
+	// children are the values of a section in the order of the configuration file structure.
+	children []*configNode
+	// opaque is set for sections that are decoded by the collector, so their values are not validated by the schema.
+	opaque bool
+	// profile is the collector section of the profiles, which has the values of the collector section of the root.
+	profile *configNode
+}
+
+// jsonSchema is the subset of JSON Schema used to describe the configuration file.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+}
+
+// Schema returns the JSON schema of the configuration file. Descriptions and defaults are taken from the flags of app.
+func Schema(app *kingpin.Application) ([]byte, error) {
+	root, err := newConfigTree(app)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := root.schema()
+	schema.Schema = "https://json-schema.org/draft/2020-12/schema"
+	schema.Title = "windows_exporter configuration file"
+
+	content, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+
+	return append(content, '\n'), nil
+}
+
+// Defaults returns a configuration file with the default values of all flags of app.
+func Defaults(app *kingpin.Application) ([]byte, error) {
+	root, err := newConfigTree(app)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := root.yamlNode()
+	if err != nil {
+		return nil, err
+	}
+
+	document.HeadComment = "windows_exporter configuration file with the default values of all settings."
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(document); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration file: %w", err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration file: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// newConfigTree maps the configuration file structure to the flags of app. Values of the configuration file
+// without a flag would be ignored silently, flags without a value can't be set in the configuration file.
+// Both are reported as error.
+func newConfigTree(app *kingpin.Application) (*configNode, error) {
+	flags := make(map[string]*kingpin.FlagModel)
+
+	for _, flag := range app.Model().Flags {
+		if flag.Hidden || slices.Contains(commandLineOnlyFlags, flag.Name) {
+			continue
+		}
+
+		flags[flag.Name] = flag
+	}
+
+	allFlags := maps.Clone(flags)
+
+	errs := make([]error, 0)
+	root := newConfigSection("", configFileStructType, nil, false, flags, &errs)
+
+	if field, ok := configFileStructType.FieldByName("Collector"); ok {
+		for _, child := range root.children {
+			if child.typ == profilesType {
+				child.profile = newConfigSection("collector", field.Type, []string{"collector"}, false, maps.Clone(allFlags), &errs)
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(flags)) {
+		errs = append(errs, fmt.Errorf("flag --%s can't be set in the configuration file", name))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// newConfigSection returns the section of a struct type. Flags of the values are removed from flags.
+func newConfigSection(key string, typ reflect.Type, keys []string, opaque bool, flags map[string]*kingpin.FlagModel, errs *[]error) *configNode {
+	section := &configNode{
+		key:    key,
+		typ:    typ,
+		opaque: opaque || reflect.PointerTo(typ).Implements(yamlUnmarshalerType),
+	}
+
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		fieldKey, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if fieldKey == "" || fieldKey == "-" {
+			continue
+		}
+
+		fieldKeys := append(slices.Clone(keys), fieldKey)
+
+		// The collector section of the profiles is added by newConfigTree, since it has the flags of the
+		// collector section of the root.
+		if field.Type == profilesType {
+			section.children = append(section.children, &configNode{key: fieldKey, typ: field.Type})
+
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			section.children = append(section.children, newConfigSection(fieldKey, field.Type, fieldKeys, section.opaque, flags, errs))
+
+			continue
+		}
+
+		name := strings.Join(fieldKeys, ".")
+
+		flag, ok := flags[name]
+		if !ok {
+			*errs = append(*errs, fmt.Errorf("configuration file value %s has no flag", name))
+
+			continue
+		}
+
+		delete(flags, name)
+
+		section.children = append(section.children, &configNode{
+			key:    fieldKey,
+			typ:    field.Type,
+			flag:   flag,
+			opaque: section.opaque,
+		})
+	}
+
+	return section
+}
+
+func (n *configNode) schema() *jsonSchema {
+	if n.profile != nil {
+		collectorSchema := n.profile.schema()
+		// The values of a profile are only set if they differ from the configuration file.
+		collectorSchema.clearDefaults()
+
+		collectorsSchema := typeSchema(reflect.TypeFor[[]string]())
+		collectorsSchema.Description = "Collectors of the profile, which may contain [defaults]. Sets collectors.enabled if the profile is selected with --profile."
+		excludeSchema := typeSchema(reflect.TypeFor[[]string]())
+		excludeSchema.Description = "Collectors excluded by the profile. Sets collectors.disabled if the profile is selected with --profile."
+
+		return &jsonSchema{
+			Type: "object",
+			AdditionalProperties: &jsonSchema{
+				Type: "object",
+				Properties: map[string]*jsonSchema{
+					"collectors": collectorsSchema,
+					"exclude":    excludeSchema,
+					"filters":    {Type: "object", Description: "Label filters of the metrics of the collectors, applied to the scrapes of the profile."},
+					"collector":  collectorSchema,
+				},
+				AdditionalProperties: false,
+			},
+		}
+	}
+
+	if n.flag == nil {
+		schema := &jsonSchema{
+			Type:       "object",
+			Properties: make(map[string]*jsonSchema, len(n.children)),
+		}
+
+		if !n.opaque {
+			schema.AdditionalProperties = false
+		}
+
+		for _, child := range n.children {
+			schema.Properties[child.key] = child.schema()
+		}
+
+		return schema
+	}
+
+	schema := &jsonSchema{}
+	if !n.opaque {
+		schema = typeSchema(n.typ)
+	}
+
+	schema.Description = n.flag.Help
+	schema.Default = n.defaultValue()
+
+	return schema
+}
+
+func (n *configNode) yamlNode() (*yaml.Node, error) {
+	if n.flag != nil {
+		node := &yaml.Node{}
+		if err := node.Encode(n.defaultValue()); err != nil {
+			return nil, fmt.Errorf("failed to encode default of %s: %w", n.flag.Name, err)
+		}
+
+		return node, nil
+	}
+
+	mapping := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, child := range n.children {
+		// Sections of collectors without flags are left out.
+		if child.flag == nil && len(child.children) == 0 {
+			continue
+		}
+
+		value, err := child.yamlNode()
+		if err != nil {
+			return nil, err
+		}
+
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: child.key}
+		if child.flag != nil {
+			key.HeadComment = child.flag.Help
+		}
+
+		mapping.Content = append(mapping.Content, key, value)
+	}
+
+	return mapping, nil
+}
+
+// defaultValue converts the default of the flag to the type of the value in the configuration file.
+// Lists are comma-separated on the command line.
+func (n *configNode) defaultValue() any {
+	value := strings.Join(n.flag.Default, ",")
+
+	switch {
+	case n.typ == durationType, n.typ == regexpType:
+		return value
+	case n.typ.Kind() == reflect.Interface:
+		if len(n.flag.Default) == 1 {
+			return n.flag.Default[0]
+		}
+
+		return append([]string{}, n.flag.Default...)
+	}
+
+	switch n.typ.Kind() {
+	case reflect.Bool:
+		if v, err := strconv.ParseBool(value); err == nil || value == "" {
+			return v
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return v
+		}
+	case reflect.Float32, reflect.Float64:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+	case reflect.Slice:
+		values := make([]string, 0)
+
+		for v := range strings.SplitSeq(value, ",") {
+			if v != "" {
+				values = append(values, v)
+			}
+		}
+
+		return values
+	default:
+	}
+
+	return value
+}
+
+func (s *jsonSchema) clearDefaults() {
+	s.Default = nil
+
+	for _, property := range s.Properties {
+		property.clearDefaults()
+	}
+}
+
+// typeSchema returns the schema of a value of the configuration file.
+func typeSchema(typ reflect.Type) *jsonSchema {
+	switch {
+	case typ == durationType:
+		return &jsonSchema{Type: "string", Pattern: durationPattern}
+	case typ == regexpType:
+		return &jsonSchema{Type: "string", Format: "regex"}
+	case typ.Implements(textUnmarshalerType), reflect.PointerTo(typ).Implements(textUnmarshalerType):
+		// e.g. the groups of the process collector, which are decoded from strings.
+		return &jsonSchema{Type: "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &jsonSchema{Type: "array", Items: typeSchema(typ.Elem())}
+	case reflect.Map, reflect.Struct:
+		return &jsonSchema{Type: "object"}
+	case reflect.Pointer:
+		return typeSchema(typ.Elem())
+	default:
+		// e.g. web.listen-address, which is a single address or a list.
+		return &jsonSchema{}
+	}
+}
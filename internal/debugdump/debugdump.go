@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+// Package debugdump records the raw values of the PDH and WMI queries of a collection for the /debug/dump endpoint.
+// Recording is disabled unless a dump is running, so the queries only pay for an atomic load.
+package debugdump
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	SourcePDH = "pdh"
+	SourceMI  = "mi"
+)
+
+// Record is the result of a query, e.g. the instances of a PDH object or of a WMI query.
+type Record struct {
+	Source string `json:"source"`
+	// Query is the PDH object or the WMI namespace and query.
+	Query  string          `json:"query"`
+	Values json.RawMessage `json:"values"`
+}
+
+// Recorder collects the records of a dump.
+type Recorder struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+//nolint:gochecknoglobals
+var active atomic.Pointer[Recorder]
+
+// Start starts recording. It returns nil if another recording is running.
+func Start() *Recorder {
+	recorder := &Recorder{}
+	if !active.CompareAndSwap(nil, recorder) {
+		return nil
+	}
+
+	return recorder
+}
+
+// Stop stops recording and returns the records in the order of the queries.
+func (r *Recorder) Stop() []Record {
+	active.CompareAndSwap(r, nil)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.records
+}
+
+// Enabled returns true if a recording is running. Callers use it to skip preparing the values of Add.
+func Enabled() bool {
+	return active.Load() != nil
+}
+
+// Add records the values of a query if a recording is running. The values are encoded immediately,
+// so the caller may reuse them afterward.
+func Add(source, query string, values any) {
+	recorder := active.Load()
+	if recorder == nil {
+		return
+	}
+
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		encoded, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	recorder.records = append(recorder.records, Record{Source: source, Query: query, Values: encoded})
+}
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package debugdump_test
+
+import (
+	"testing"
+
+	"github.com/prometheus-community/windows_exporter/internal/debugdump"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	debugdump.Add(debugdump.SourcePDH, "Processor", []float64{1})
+	require.False(t, debugdump.Enabled())
+
+	recorder := debugdump.Start()
+	require.NotNil(t, recorder)
+	require.True(t, debugdump.Enabled())
+	require.Nil(t, debugdump.Start())
+
+	values := []float64{1, 2}
+	debugdump.Add(debugdump.SourcePDH, "Processor", values)
+	values[0] = 3
+
+	debugdump.Add(debugdump.SourceMI, `root\cimv2: SELECT * FROM Win32_OperatingSystem`, make(chan int))
+
+	records := recorder.Stop()
+	require.False(t, debugdump.Enabled())
+	require.Len(t, records, 2)
+	require.JSONEq(t, `[1,2]`, string(records[0].Values))
+	require.Equal(t, debugdump.SourceMI, records[1].Source)
+	require.Contains(t, string(records[1].Values), "error")
+
+	debugdump.Add(debugdump.SourcePDH, "Processor", values)
+	require.Len(t, recorder.Stop(), 2)
+}
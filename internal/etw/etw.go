@@ -0,0 +1,365 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+// Package etw consumes the events of Event Tracing for Windows (ETW) providers in a real-time trace session.
+// Collectors subscribe to providers with Subscribe. All subscriptions share one trace session, which is
+// started with the first subscription and stopped with the last.
+package etw
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// SessionName is the name of the trace session of windows_exporter, e.g. for logman.exe query -ets.
+const SessionName = "windows_exporter"
+
+// Trace levels of the events. Events with a level up to Provider.Level are delivered.
+const (
+	LevelError       uint8 = 2
+	LevelWarning     uint8 = 3
+	LevelInformation uint8 = 4
+	LevelVerbose     uint8 = 5
+)
+
+// Provider selects the events of an ETW provider.
+type Provider struct {
+	GUID  windows.GUID
+	Level uint8
+	// MatchAnyKeyword selects the events by keyword. 0 selects all events.
+	MatchAnyKeyword uint64
+	// EventIDs are the IDs of the delivered events. If empty, all events are delivered.
+	// The events are filtered by ETW, so unwanted high-frequency events don't reach the session.
+	EventIDs []uint16
+}
+
+// Handler is called for each event of the provider on the thread of the trace session.
+// It must return quickly and must not keep the record after returning.
+type Handler func(record *EventRecord)
+
+// Subscription delivers the events of a provider to a handler until it is closed.
+type Subscription struct {
+	provider Provider
+	handler  Handler
+	closed   bool
+}
+
+type session struct {
+	mu     sync.Mutex
+	logger *slog.Logger
+	// handle controls the session, traceHandle consumes its events.
+	handle        TRACEHANDLE
+	traceHandle   TRACEHANDLE
+	subscriptions map[windows.GUID][]*Subscription
+	done          chan struct{}
+}
+
+//nolint:gochecknoglobals
+var (
+	defaultSession = &session{subscriptions: make(map[windows.GUID][]*Subscription)}
+
+	// handlers is a copy of the subscriptions of defaultSession, read by the event callback without locking.
+	handlers atomic.Pointer[map[windows.GUID][]*Subscription]
+
+	// eventRecordCallback is created once, since callbacks created by windows.NewCallback are never released.
+	eventRecordCallback = sync.OnceValue(func() uintptr {
+		return windows.NewCallback(onEvent)
+	})
+)
+
+// Subscribe enables the provider in the trace session of windows_exporter and calls handler for its events.
+// Several subscriptions may select events of the same provider, e.g. while the collectors are reloaded.
+// Requires administrative privileges or membership in the Performance Log Users group.
+func Subscribe(logger *slog.Logger, provider Provider, handler Handler) (*Subscription, error) {
+	s := defaultSession
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.logger == nil {
+		s.logger = logger
+	}
+
+	if s.done == nil {
+		if err := s.start(); err != nil {
+			return nil, fmt.Errorf("failed to start ETW session %s: %w", SessionName, err)
+		}
+	}
+
+	subscription := &Subscription{provider: provider, handler: handler}
+
+	s.subscriptions[provider.GUID] = append(s.subscriptions[provider.GUID], subscription)
+
+	if err := s.enable(provider.GUID); err != nil {
+		s.remove(subscription)
+
+		if len(s.subscriptions) == 0 {
+			_ = s.stop()
+		}
+
+		return nil, fmt.Errorf("failed to enable ETW provider %s: %w", provider.GUID, err)
+	}
+
+	s.publish()
+
+	return subscription, nil
+}
+
+// Close stops the delivery of events to the handler. The trace session is stopped with the last subscription.
+func (sub *Subscription) Close() error {
+	s := defaultSession
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sub.closed {
+		return nil
+	}
+
+	sub.closed = true
+
+	s.remove(sub)
+	s.publish()
+
+	errs := make([]error, 0)
+
+	if err := s.enable(sub.provider.GUID); err != nil {
+		errs = append(errs, fmt.Errorf("failed to update ETW provider %s: %w", sub.provider.GUID, err))
+	}
+
+	if len(s.subscriptions) == 0 {
+		if err := s.stop(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop ETW session %s: %w", SessionName, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (sub *Subscription) wants(eventID uint16) bool {
+	return len(sub.provider.EventIDs) == 0 || slices.Contains(sub.provider.EventIDs, eventID)
+}
+
+func (s *session) start() error {
+	name, err := windows.UTF16PtrFromString(SessionName)
+	if err != nil {
+		return err
+	}
+
+	err = StartTrace(&s.handle, name, newTraceProperties())
+	if errors.Is(err, windows.ERROR_ALREADY_EXISTS) {
+		// The session of a previous windows_exporter process was not stopped, e.g. after a crash.
+		s.logger.Debug("stopping stale ETW session", slog.String("session", SessionName))
+
+		_ = ControlTrace(0, name, newTraceProperties(), EVENT_TRACE_CONTROL_STOP)
+
+		err = StartTrace(&s.handle, name, newTraceProperties())
+	}
+
+	if err != nil {
+		return fmt.Errorf("StartTrace: %w", err)
+	}
+
+	logfile := EVENT_TRACE_LOGFILEW{
+		LoggerName:          name,
+		ProcessTraceMode:    PROCESS_TRACE_MODE_REAL_TIME | PROCESS_TRACE_MODE_EVENT_RECORD,
+		EventRecordCallback: eventRecordCallback(),
+	}
+
+	s.traceHandle, err = OpenTrace(&logfile)
+	if err != nil {
+		_ = ControlTrace(s.handle, nil, newTraceProperties(), EVENT_TRACE_CONTROL_STOP)
+
+		return fmt.Errorf("OpenTrace: %w", err)
+	}
+
+	done := make(chan struct{})
+	s.done = done
+
+	go func(traceHandle TRACEHANDLE) {
+		defer close(done)
+
+		// ProcessTrace blocks until the session is stopped.
+		if err := ProcessTrace(&traceHandle); err != nil && !errors.Is(err, windows.ERROR_CANCELLED) {
+			s.logger.Warn("ETW session stopped unexpectedly",
+				slog.String("session", SessionName),
+				slog.Any("err", err),
+			)
+		}
+	}(s.traceHandle)
+
+	return nil
+}
+
+func (s *session) stop() error {
+	errs := make([]error, 0)
+
+	if err := ControlTrace(s.handle, nil, newTraceProperties(), EVENT_TRACE_CONTROL_STOP); err != nil {
+		errs = append(errs, fmt.Errorf("ControlTrace: %w", err))
+	}
+
+	if err := CloseTrace(s.traceHandle); err != nil {
+		errs = append(errs, fmt.Errorf("CloseTrace: %w", err))
+	}
+
+	<-s.done
+
+	s.done = nil
+	s.handle = 0
+	s.traceHandle = 0
+
+	return errors.Join(errs...)
+}
+
+// enable enables the provider with the union of the events selected by its subscriptions,
+// or disables it if there are no subscriptions left.
+func (s *session) enable(guid windows.GUID) error {
+	subscriptions := s.subscriptions[guid]
+	if len(subscriptions) == 0 {
+		return EnableTraceEx2(s.handle, &guid, EVENT_CONTROL_CODE_DISABLE_PROVIDER, 0, 0, 0, 0, nil)
+	}
+
+	var (
+		level    uint8
+		matchAny uint64
+		eventIDs []uint16
+	)
+
+	allKeywords := false
+	allEvents := false
+
+	for _, subscription := range subscriptions {
+		level = max(level, subscription.provider.Level)
+
+		if subscription.provider.MatchAnyKeyword == 0 {
+			allKeywords = true
+		}
+
+		matchAny |= subscription.provider.MatchAnyKeyword
+
+		if len(subscription.provider.EventIDs) == 0 {
+			allEvents = true
+		}
+
+		eventIDs = append(eventIDs, subscription.provider.EventIDs...)
+	}
+
+	if allKeywords {
+		matchAny = 0
+	}
+
+	parameters := &ENABLE_TRACE_PARAMETERS{
+		Version: ENABLE_TRACE_PARAMETERS_VERSION_2,
+	}
+
+	var filter []uint16
+
+	if !allEvents {
+		slices.Sort(eventIDs)
+		filter = eventIDFilter(slices.Compact(eventIDs))
+
+		parameters.EnableFilterDesc = &EVENT_FILTER_DESCRIPTOR{
+			Ptr:  uint64(uintptr(unsafe.Pointer(&filter[0]))),
+			Size: uint32(len(filter) * 2),
+			Type: EVENT_FILTER_TYPE_EVENT_ID,
+		}
+		parameters.FilterDescCount = 1
+	}
+
+	err := EnableTraceEx2(s.handle, &guid, EVENT_CONTROL_CODE_ENABLE_PROVIDER, level, matchAny, 0, 0, parameters)
+
+	runtime.KeepAlive(filter)
+
+	return err
+}
+
+func (s *session) remove(subscription *Subscription) {
+	guid := subscription.provider.GUID
+
+	s.subscriptions[guid] = slices.DeleteFunc(s.subscriptions[guid], func(other *Subscription) bool {
+		return other == subscription
+	})
+
+	if len(s.subscriptions[guid]) == 0 {
+		delete(s.subscriptions, guid)
+	}
+}
+
+// publish copies the subscriptions for the event callback.
+func (s *session) publish() {
+	subscriptions := make(map[windows.GUID][]*Subscription, len(s.subscriptions))
+	for guid, providerSubscriptions := range s.subscriptions {
+		subscriptions[guid] = slices.Clone(providerSubscriptions)
+	}
+
+	handlers.Store(&subscriptions)
+}
+
+func onEvent(record *EventRecord) uintptr {
+	subscriptions := handlers.Load()
+	if subscriptions == nil {
+		return 0
+	}
+
+	for _, subscription := range (*subscriptions)[record.EventHeader.ProviderId] {
+		if subscription.wants(record.EventHeader.EventDescriptor.Id) {
+			subscription.handler(record)
+		}
+	}
+
+	return 0
+}
+
+// newTraceProperties returns EVENT_TRACE_PROPERTIES of a real-time session, followed by space for the session name.
+func newTraceProperties() *EVENT_TRACE_PROPERTIES {
+	size := unsafe.Sizeof(EVENT_TRACE_PROPERTIES{})
+	bufferSize := size + uintptr(len(SessionName)+1)*2
+
+	// A []uint64 keeps the 8 byte alignment of the structure.
+	buffer := make([]uint64, (bufferSize+7)/8)
+
+	properties := (*EVENT_TRACE_PROPERTIES)(unsafe.Pointer(&buffer[0]))
+	properties.Wnode.BufferSize = uint32(len(buffer) * 8)
+	properties.Wnode.Flags = WNODE_FLAG_TRACED_GUID
+	// Use the query performance counter for event timestamps.
+	properties.Wnode.ClientContext = 1
+	properties.LogFileMode = EVENT_TRACE_REAL_TIME_MODE
+	// Buffers of 64 KB, flushed every second.
+	properties.BufferSize = 64
+	properties.FlushTimer = 1
+	properties.LoggerNameOffset = uint32(size)
+
+	return properties
+}
+
+// eventIDFilter returns an EVENT_FILTER_EVENT_ID structure that delivers the given events:
+// FilterIn (1 byte), Reserved (1 byte), Count (2 bytes), followed by the event IDs.
+func eventIDFilter(eventIDs []uint16) []uint16 {
+	filter := make([]uint16, 2, 2+len(eventIDs))
+	filter[0] = 1 // FilterIn = TRUE, Reserved = 0
+	filter[1] = uint16(len(eventIDs))
+
+	return append(filter, eventIDs...)
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package etw
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructureSizes(t *testing.T) {
+	t.Parallel()
+
+	if unsafe.Sizeof(uintptr(0)) != 8 {
+		t.Skip("sizes are only checked on 64-bit platforms")
+	}
+
+	// Sizes of the C structures of the Windows SDK on 64-bit platforms.
+	require.Equal(t, uintptr(120), unsafe.Sizeof(EVENT_TRACE_PROPERTIES{}))
+	require.Equal(t, uintptr(280), unsafe.Sizeof(TRACE_LOGFILE_HEADER{}))
+	require.Equal(t, uintptr(448), unsafe.Sizeof(EVENT_TRACE_LOGFILEW{}))
+	require.Equal(t, uintptr(80), unsafe.Sizeof(EVENT_HEADER{}))
+	require.Equal(t, uintptr(112), unsafe.Sizeof(EventRecord{}))
+	require.Equal(t, uintptr(16), unsafe.Sizeof(PROPERTY_DATA_DESCRIPTOR{}))
+}
+
+func TestEventIDFilter(t *testing.T) {
+	t.Parallel()
+
+	filter := eventIDFilter([]uint16{10, 11})
+
+	require.Equal(t, []byte{1, 0, 2, 0, 10, 0, 11, 0}, unsafe.Slice((*byte)(unsafe.Pointer(&filter[0])), len(filter)*2))
+}
+
+func TestNewTraceProperties(t *testing.T) {
+	t.Parallel()
+
+	properties := newTraceProperties()
+
+	require.Equal(t, uint32(unsafe.Sizeof(EVENT_TRACE_PROPERTIES{})), properties.LoggerNameOffset)
+	require.GreaterOrEqual(t, properties.Wnode.BufferSize, properties.LoggerNameOffset+uint32(len(SessionName)+1)*2)
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package etw
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Property returns the value of a property of the event by name, decoded by the Trace Data Helper (TDH)
+// from the manifest of the provider. Unlike the offsets in Data, the names don't depend on the version of the event.
+// It must only be called during the handler call.
+func (r *EventRecord) Property(name string) ([]byte, error) {
+	propertyName, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptor := PROPERTY_DATA_DESCRIPTOR{
+		PropertyName: uint64(uintptr(unsafe.Pointer(propertyName))),
+		ArrayIndex:   PROPERTY_DATA_DESCRIPTOR_ARRAY_INDEX_NONE,
+	}
+
+	// The name is only referenced by the integer field of the descriptor.
+	defer runtime.KeepAlive(propertyName)
+
+	var size uint32
+	if err := TdhGetPropertySize(r, &descriptor, &size); err != nil {
+		return nil, fmt.Errorf("failed to get size of property %s: %w", name, err)
+	}
+
+	if size == 0 {
+		return nil, nil
+	}
+
+	buffer := make([]byte, size)
+	if err := TdhGetProperty(r, &descriptor, buffer); err != nil {
+		return nil, fmt.Errorf("failed to get property %s: %w", name, err)
+	}
+
+	return buffer, nil
+}
@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package etw
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var (
+	modadvapi32        = windows.NewLazySystemDLL("advapi32.dll")
+	procStartTraceW    = modadvapi32.NewProc("StartTraceW")
+	procControlTraceW  = modadvapi32.NewProc("ControlTraceW")
+	procEnableTraceEx2 = modadvapi32.NewProc("EnableTraceEx2")
+	procOpenTraceW     = modadvapi32.NewProc("OpenTraceW")
+	procProcessTrace   = modadvapi32.NewProc("ProcessTrace")
+	procCloseTrace     = modadvapi32.NewProc("CloseTrace")
+
+	modtdh                 = windows.NewLazySystemDLL("tdh.dll")
+	procTdhGetPropertySize = modtdh.NewProc("TdhGetPropertySize")
+	procTdhGetProperty     = modtdh.NewProc("TdhGetProperty")
+)
+
+// StartTrace registers and starts an event tracing session.
+// https://learn.microsoft.com/en-us/windows/win32/api/evntrace/nf-evntrace-starttracew
+func StartTrace(handle *TRACEHANDLE, name *uint16, properties *EVENT_TRACE_PROPERTIES) error {
+	ret, _, _ := procStartTraceW.Call(
+		uintptr(unsafe.Pointer(handle)),
+		uintptr(unsafe.Pointer(name)),
+		uintptr(unsafe.Pointer(properties)),
+	)
+	if ret != 0 {
+		return windows.Errno(ret)
+	}
+
+	return nil
+}
+
+// ControlTrace flushes, queries, updates or stops an event tracing session.
+// https://learn.microsoft.com/en-us/windows/win32/api/evntrace/nf-evntrace-controltracew
+func ControlTrace(handle TRACEHANDLE, name *uint16, properties *EVENT_TRACE_PROPERTIES, controlCode uint32) error {
+	ret, _, _ := procControlTraceW.Call(
+		uintptr(handle),
+		uintptr(unsafe.Pointer(name)),
+		uintptr(unsafe.Pointer(properties)),
+		uintptr(controlCode),
+	)
+	if ret != 0 {
+		return windows.Errno(ret)
+	}
+
+	return nil
+}
+
+// EnableTraceEx2 enables or disables a provider in an event tracing session.
+// https://learn.microsoft.com/en-us/windows/win32/api/evntrace/nf-evntrace-enabletraceex2
+func EnableTraceEx2(handle TRACEHANDLE, providerID *windows.GUID, controlCode uint32, level uint8, matchAnyKeyword, matchAllKeyword uint64, timeout uint32, parameters *ENABLE_TRACE_PARAMETERS) error {
+	ret, _, _ := procEnableTraceEx2.Call(
+		uintptr(handle),
+		uintptr(unsafe.Pointer(providerID)),
+		uintptr(controlCode),
+		uintptr(level),
+		uintptr(matchAnyKeyword),
+		uintptr(matchAllKeyword),
+		uintptr(timeout),
+		uintptr(unsafe.Pointer(parameters)),
+	)
+	if ret != 0 {
+		return windows.Errno(ret)
+	}
+
+	return nil
+}
+
+// OpenTrace opens a real-time event tracing session for consuming.
+// https://learn.microsoft.com/en-us/windows/win32/api/evntrace/nf-evntrace-opentracew
+func OpenTrace(logfile *EVENT_TRACE_LOGFILEW) (TRACEHANDLE, error) {
+	ret, _, err := procOpenTraceW.Call(
+		uintptr(unsafe.Pointer(logfile)),
+	)
+	if TRACEHANDLE(ret) == INVALID_PROCESSTRACE_HANDLE {
+		return 0, err
+	}
+
+	return TRACEHANDLE(ret), nil
+}
+
+// ProcessTrace delivers the events of the session to the callback. It blocks until the session is stopped or closed.
+// https://learn.microsoft.com/en-us/windows/win32/api/evntrace/nf-evntrace-processtrace
+func ProcessTrace(handle *TRACEHANDLE) error {
+	ret, _, _ := procProcessTrace.Call(
+		uintptr(unsafe.Pointer(handle)),
+		1,
+		0,
+		0,
+	)
+	if ret != 0 {
+		return windows.Errno(ret)
+	}
+
+	return nil
+}
+
+// CloseTrace closes a handle returned by OpenTrace.
+// https://learn.microsoft.com/en-us/windows/win32/api/evntrace/nf-evntrace-closetrace
+func CloseTrace(handle TRACEHANDLE) error {
+	ret, _, _ := procCloseTrace.Call(
+		uintptr(handle),
+	)
+	// ERROR_CTX_CLOSE_PENDING is returned if events are still being delivered, the trace is closed afterward.
+	if ret != 0 && windows.Errno(ret) != windows.ERROR_CTX_CLOSE_PENDING {
+		return windows.Errno(ret)
+	}
+
+	return nil
+}
+
+// TdhGetPropertySize returns the size of a property of the event.
+// https://learn.microsoft.com/en-us/windows/win32/api/tdh/nf-tdh-tdhgetpropertysize
+func TdhGetPropertySize(record *EventRecord, descriptor *PROPERTY_DATA_DESCRIPTOR, size *uint32) error {
+	ret, _, _ := procTdhGetPropertySize.Call(
+		uintptr(unsafe.Pointer(record)),
+		0,
+		0,
+		1,
+		uintptr(unsafe.Pointer(descriptor)),
+		uintptr(unsafe.Pointer(size)),
+	)
+	if ret != 0 {
+		return windows.Errno(ret)
+	}
+
+	return nil
+}
+
+// TdhGetProperty copies the value of a property of the event to buffer.
+// https://learn.microsoft.com/en-us/windows/win32/api/tdh/nf-tdh-tdhgetproperty
+func TdhGetProperty(record *EventRecord, descriptor *PROPERTY_DATA_DESCRIPTOR, buffer []byte) error {
+	ret, _, _ := procTdhGetProperty.Call(
+		uintptr(unsafe.Pointer(record)),
+		0,
+		0,
+		1,
+		uintptr(unsafe.Pointer(descriptor)),
+		uintptr(len(buffer)),
+		uintptr(unsafe.Pointer(&buffer[0])),
+	)
+	if ret != 0 {
+		return windows.Errno(ret)
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package etw
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// https://learn.microsoft.com/en-us/windows/win32/etw/wnode-header
+const (
+	WNODE_FLAG_TRACED_GUID = 0x00020000
+
+	EVENT_TRACE_REAL_TIME_MODE = 0x00000100
+
+	EVENT_TRACE_CONTROL_STOP = 1
+
+	EVENT_CONTROL_CODE_DISABLE_PROVIDER = 0
+	EVENT_CONTROL_CODE_ENABLE_PROVIDER  = 1
+
+	ENABLE_TRACE_PARAMETERS_VERSION_2 = 2
+
+	// EVENT_FILTER_TYPE_EVENT_ID filters by EVENT_FILTER_EVENT_ID, see eventIDFilter.
+	EVENT_FILTER_TYPE_EVENT_ID = 0x80000200
+
+	PROCESS_TRACE_MODE_REAL_TIME    = 0x00000100
+	PROCESS_TRACE_MODE_EVENT_RECORD = 0x10000000
+
+	EVENT_HEADER_FLAG_32_BIT_HEADER = 0x0020
+
+	// PROPERTY_DATA_DESCRIPTOR_ARRAY_INDEX_NONE selects a property that is not an array.
+	PROPERTY_DATA_DESCRIPTOR_ARRAY_INDEX_NONE = 0xFFFFFFFF
+
+	// INVALID_PROCESSTRACE_HANDLE is returned by OpenTrace on failure.
+	INVALID_PROCESSTRACE_HANDLE = ^TRACEHANDLE(0)
+)
+
+type TRACEHANDLE uint64
+
+type WNODE_HEADER struct {
+	BufferSize        uint32
+	ProviderId        uint32
+	HistoricalContext uint64
+	TimeStamp         int64
+	Guid              windows.GUID
+	ClientContext     uint32
+	Flags             uint32
+}
+
+// EVENT_TRACE_PROPERTIES is followed by the logger name in the same allocation, see newTraceProperties.
+type EVENT_TRACE_PROPERTIES struct {
+	Wnode               WNODE_HEADER
+	BufferSize          uint32
+	MinimumBuffers      uint32
+	MaximumBuffers      uint32
+	MaximumFileSize     uint32
+	LogFileMode         uint32
+	FlushTimer          uint32
+	EnableFlags         uint32
+	AgeLimit            int32
+	NumberOfBuffers     uint32
+	FreeBuffers         uint32
+	EventsLost          uint32
+	BuffersWritten      uint32
+	LogBuffersLost      uint32
+	RealTimeBuffersLost uint32
+	LoggerThreadId      windows.Handle
+	LogFileNameOffset   uint32
+	LoggerNameOffset    uint32
+}
+
+type EVENT_FILTER_DESCRIPTOR struct {
+	Ptr  uint64
+	Size uint32
+	Type uint32
+}
+
+type ENABLE_TRACE_PARAMETERS struct {
+	Version          uint32
+	EnableProperty   uint32
+	ControlFlags     uint32
+	SourceId         windows.GUID
+	EnableFilterDesc *EVENT_FILTER_DESCRIPTOR
+	FilterDescCount  uint32
+}
+
+type EVENT_TRACE_HEADER struct {
+	Size           uint16
+	FieldTypeFlags uint16
+	Version        uint32
+	ThreadId       uint32
+	ProcessId      uint32
+	TimeStamp      int64
+	Guid           windows.GUID
+	ProcessorTime  uint64
+}
+
+type EVENT_TRACE struct {
+	Header           EVENT_TRACE_HEADER
+	InstanceId       uint32
+	ParentInstanceId uint32
+	ParentGuid       windows.GUID
+	MofData          uintptr
+	MofLength        uint32
+	ClientContext    uint32
+}
+
+type TRACE_LOGFILE_HEADER struct {
+	BufferSize         uint32
+	Version            uint32
+	ProviderVersion    uint32
+	NumberOfProcessors uint32
+	EndTime            int64
+	TimerResolution    uint32
+	MaximumFileSize    uint32
+	LogFileMode        uint32
+	BuffersWritten     uint32
+	LogInstanceGuid    windows.GUID
+	LoggerName         *uint16
+	LogFileName        *uint16
+	TimeZone           windows.Timezoneinformation
+	BootTime           int64
+	PerfFreq           int64
+	StartTime          int64
+	ReservedFlags      uint32
+	BuffersLost        uint32
+}
+
+type EVENT_TRACE_LOGFILEW struct {
+	LogFileName         *uint16
+	LoggerName          *uint16
+	CurrentTime         int64
+	BuffersRead         uint32
+	ProcessTraceMode    uint32
+	CurrentEvent        EVENT_TRACE
+	LogfileHeader       TRACE_LOGFILE_HEADER
+	BufferCallback      uintptr
+	BufferSize          uint32
+	Filled              uint32
+	EventsLost          uint32
+	EventRecordCallback uintptr
+	IsKernelTrace       uint32
+	Context             uintptr
+}
+
+type EVENT_DESCRIPTOR struct {
+	Id      uint16
+	Version uint8
+	Channel uint8
+	Level   uint8
+	Opcode  uint8
+	Task    uint16
+	Keyword uint64
+}
+
+type EVENT_HEADER struct {
+	Size            uint16
+	HeaderType      uint16
+	Flags           uint16
+	EventProperty   uint16
+	ThreadId        uint32
+	ProcessId       uint32
+	TimeStamp       int64
+	ProviderId      windows.GUID
+	EventDescriptor EVENT_DESCRIPTOR
+	ProcessorTime   uint64
+	ActivityId      windows.GUID
+}
+
+type ETW_BUFFER_CONTEXT struct {
+	ProcessorNumber uint8
+	Alignment       uint8
+	LoggerId        uint16
+}
+
+// PROPERTY_DATA_DESCRIPTOR selects a property of an event by name, see EventRecord.Property.
+type PROPERTY_DATA_DESCRIPTOR struct {
+	PropertyName uint64
+	ArrayIndex   uint32
+	Reserved     uint32
+}
+
+// EventRecord is EVENT_RECORD, the event passed to the handlers of a Subscription.
+type EventRecord struct {
+	EventHeader       EVENT_HEADER
+	BufferContext     ETW_BUFFER_CONTEXT
+	ExtendedDataCount uint16
+	UserDataLength    uint16
+	ExtendedData      uintptr
+	UserData          unsafe.Pointer
+	UserContext       uintptr
+}
+
+// Data returns the user data of the event. It is only valid during the handler call.
+func (r *EventRecord) Data() []byte {
+	if r.UserData == nil || r.UserDataLength == 0 {
+		return nil
+	}
+
+	return unsafe.Slice((*byte)(r.UserData), r.UserDataLength)
+}
+
+// PointerSize returns the size of pointers in the user data, which depends on the bitness of the process that logged the event.
+func (r *EventRecord) PointerSize() int {
+	if r.EventHeader.Flags&EVENT_HEADER_FLAG_32_BIT_HEADER != 0 {
+		return 4
+	}
+
+	return 8
+}
@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package bits
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var (
+	// CLSID_BackgroundCopyManager
+	clsidBackgroundCopyManager = ole.NewGUID("{4991D34B-80A1-4291-83B6-3328366B9097}")
+	// IID_IBackgroundCopyManager
+	iidBackgroundCopyManager = ole.NewGUID("{5CE34C0D-0DC9-4C1F-897C-DAA1B78CEE7C}")
+)
+
+const (
+	// BG_JOB_ENUM_ALL_USERS enumerates the jobs of all users. The caller must be an administrator.
+	bgJobEnumAllUsers = 0x0001
+
+	// BG_SIZE_UNKNOWN is reported as BytesTotal until BITS determined the size of all files of the job.
+	SizeUnknown = ^uint64(0)
+
+	sFalse = 0x00000001
+)
+
+// JobState is the state of a BITS job.
+//
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/bits/ne-bits-bg_job_state
+type JobState uint32
+
+const (
+	JobStateQueued JobState = iota
+	JobStateConnecting
+	JobStateTransferring
+	JobStateSuspended
+	JobStateError
+	JobStateTransientError
+	JobStateTransferred
+	JobStateAcknowledged
+	JobStateCancelled
+)
+
+// BG_JOB_PROGRESS
+//
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/bits/ns-bits-bg_job_progress
+type BG_JOB_PROGRESS struct {
+	BytesTotal       uint64
+	BytesTransferred uint64
+	FilesTotal       uint32
+	FilesTransferred uint32
+}
+
+// Job is a snapshot of a BITS job.
+type Job struct {
+	// Owner is the SID of the job owner.
+	Owner    string
+	State    JobState
+	Progress BG_JOB_PROGRESS
+}
+
+type iBackgroundCopyManagerVtbl struct {
+	ole.IUnknownVtbl
+	CreateJob           uintptr
+	GetJob              uintptr
+	EnumJobs            uintptr
+	GetErrorDescription uintptr
+}
+
+type iEnumBackgroundCopyJobsVtbl struct {
+	ole.IUnknownVtbl
+	Next     uintptr
+	Skip     uintptr
+	Reset    uintptr
+	Clone    uintptr
+	GetCount uintptr
+}
+
+type iBackgroundCopyJobVtbl struct {
+	ole.IUnknownVtbl
+	AddFileSet  uintptr
+	AddFile     uintptr
+	EnumFiles   uintptr
+	Suspend     uintptr
+	Resume      uintptr
+	Cancel      uintptr
+	Complete    uintptr
+	GetId       uintptr
+	GetType     uintptr
+	GetProgress uintptr
+	GetTimes    uintptr
+	GetState    uintptr
+	GetError    uintptr
+	GetOwner    uintptr
+}
+
+// EnumJobs returns the BITS jobs of all users.
+func EnumJobs() ([]Job, error) {
+	// COM must be initialized on the OS thread the calls are made on.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := ole.CoInitializeEx(0, ole.COINIT_MULTITHREADED); err != nil {
+		var oleCode *ole.OleError
+		if errors.As(err, &oleCode) && oleCode.Code() != ole.S_OK && oleCode.Code() != sFalse {
+			return nil, fmt.Errorf("CoInitializeEx: %w", err)
+		}
+	}
+
+	defer ole.CoUninitialize()
+
+	manager, err := ole.CreateInstance(clsidBackgroundCopyManager, iidBackgroundCopyManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BackgroundCopyManager: %w", err)
+	}
+
+	defer manager.Release()
+
+	managerVtbl := (*iBackgroundCopyManagerVtbl)(unsafe.Pointer(manager.RawVTable))
+
+	var enum *ole.IUnknown
+
+	if hr, _, _ := syscall.SyscallN(managerVtbl.EnumJobs, uintptr(unsafe.Pointer(manager)), bgJobEnumAllUsers, uintptr(unsafe.Pointer(&enum))); hr != 0 {
+		return nil, fmt.Errorf("IBackgroundCopyManager::EnumJobs: %w", ole.NewError(hr))
+	}
+
+	defer enum.Release()
+
+	enumVtbl := (*iEnumBackgroundCopyJobsVtbl)(unsafe.Pointer(enum.RawVTable))
+
+	var count uint32
+
+	if hr, _, _ := syscall.SyscallN(enumVtbl.GetCount, uintptr(unsafe.Pointer(enum)), uintptr(unsafe.Pointer(&count))); hr != 0 {
+		return nil, fmt.Errorf("IEnumBackgroundCopyJobs::GetCount: %w", ole.NewError(hr))
+	}
+
+	jobs := make([]Job, 0, count)
+
+	for {
+		var (
+			job     *ole.IUnknown
+			fetched uint32
+		)
+
+		hr, _, _ := syscall.SyscallN(enumVtbl.Next, uintptr(unsafe.Pointer(enum)), 1, uintptr(unsafe.Pointer(&job)), uintptr(unsafe.Pointer(&fetched)))
+		if hr != 0 && hr != sFalse {
+			return nil, fmt.Errorf("IEnumBackgroundCopyJobs::Next: %w", ole.NewError(hr))
+		}
+
+		if fetched == 0 {
+			break
+		}
+
+		info, err := jobInfo(job)
+
+		job.Release()
+
+		if err != nil {
+			// The job may have been completed or cancelled since the enumeration started.
+			continue
+		}
+
+		jobs = append(jobs, info)
+	}
+
+	return jobs, nil
+}
+
+func jobInfo(job *ole.IUnknown) (Job, error) {
+	var info Job
+
+	jobVtbl := (*iBackgroundCopyJobVtbl)(unsafe.Pointer(job.RawVTable))
+
+	if hr, _, _ := syscall.SyscallN(jobVtbl.GetState, uintptr(unsafe.Pointer(job)), uintptr(unsafe.Pointer(&info.State))); hr != 0 {
+		return Job{}, fmt.Errorf("IBackgroundCopyJob::GetState: %w", ole.NewError(hr))
+	}
+
+	if hr, _, _ := syscall.SyscallN(jobVtbl.GetProgress, uintptr(unsafe.Pointer(job)), uintptr(unsafe.Pointer(&info.Progress))); hr != 0 {
+		return Job{}, fmt.Errorf("IBackgroundCopyJob::GetProgress: %w", ole.NewError(hr))
+	}
+
+	var owner *uint16
+
+	if hr, _, _ := syscall.SyscallN(jobVtbl.GetOwner, uintptr(unsafe.Pointer(job)), uintptr(unsafe.Pointer(&owner))); hr != 0 {
+		return Job{}, fmt.Errorf("IBackgroundCopyJob::GetOwner: %w", ole.NewError(hr))
+	}
+
+	info.Owner = windows.UTF16PtrToString(owner)
+	windows.CoTaskMemFree(unsafe.Pointer(owner))
+
+	return info, nil
+}
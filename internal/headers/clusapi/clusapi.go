@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package clusapi
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var (
+	modClusAPI                = windows.NewLazySystemDLL("clusapi.dll")
+	procOpenCluster           = modClusAPI.NewProc("OpenCluster")
+	procCloseCluster          = modClusAPI.NewProc("CloseCluster")
+	procOpenClusterGroup      = modClusAPI.NewProc("OpenClusterGroup")
+	procCloseClusterGroup     = modClusAPI.NewProc("CloseClusterGroup")
+	procClusterGroupOpenEnum  = modClusAPI.NewProc("ClusterGroupOpenEnum")
+	procClusterGroupEnum      = modClusAPI.NewProc("ClusterGroupEnum")
+	procClusterGroupCloseEnum = modClusAPI.NewProc("ClusterGroupCloseEnum")
+)
+
+// CLUSTER_GROUP_ENUM_NODES enumerates the preferred owners of a group, in the order of preference.
+// https://learn.microsoft.com/en-us/windows/win32/api/clusapi/ne-clusapi-cluster_group_enum
+const CLUSTER_GROUP_ENUM_NODES = 0x00000002
+
+// GroupPreferredOwners returns the preferred owners of the groups of the local cluster, in the order of preference.
+// Groups without preferred owners have an empty list. Groups that don't exist anymore are left out.
+func GroupPreferredOwners(groupNames []string) (map[string][]string, error) {
+	cluster, err := openCluster()
+	if err != nil {
+		return nil, fmt.Errorf("OpenCluster: %w", err)
+	}
+
+	defer closeCluster(cluster)
+
+	owners := make(map[string][]string, len(groupNames))
+
+	for _, groupName := range groupNames {
+		nodes, err := groupPreferredOwners(cluster, groupName)
+		if errors.Is(err, windows.ERROR_GROUP_NOT_FOUND) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("group %s: %w", groupName, err)
+		}
+
+		owners[groupName] = nodes
+	}
+
+	return owners, nil
+}
+
+func groupPreferredOwners(cluster windows.Handle, groupName string) ([]string, error) {
+	group, err := openClusterGroup(cluster, groupName)
+	if err != nil {
+		return nil, fmt.Errorf("OpenClusterGroup: %w", err)
+	}
+
+	defer closeClusterGroup(group)
+
+	groupEnum, err := clusterGroupOpenEnum(group, CLUSTER_GROUP_ENUM_NODES)
+	if err != nil {
+		return nil, fmt.Errorf("ClusterGroupOpenEnum: %w", err)
+	}
+
+	defer clusterGroupCloseEnum(groupEnum)
+
+	nodes := make([]string, 0)
+	buf := make([]uint16, 256)
+
+	for index := uint32(0); ; index++ {
+		var objectType uint32
+
+		size := uint32(len(buf))
+
+		ret := clusterGroupEnum(groupEnum, index, &objectType, &buf[0], &size)
+
+		switch ret {
+		case windows.ERROR_SUCCESS:
+			nodes = append(nodes, windows.UTF16ToString(buf[:size]))
+		case windows.ERROR_MORE_DATA:
+			// size is the length of the name without the terminating null character.
+			buf = make([]uint16, size+1)
+			index--
+		case windows.ERROR_NO_MORE_ITEMS:
+			return nodes, nil
+		default:
+			return nil, fmt.Errorf("ClusterGroupEnum: %w", ret)
+		}
+	}
+}
+
+// openCluster https://learn.microsoft.com/en-us/windows/win32/api/clusapi/nf-clusapi-opencluster
+func openCluster() (windows.Handle, error) {
+	// A nil cluster name opens the cluster of the local node.
+	ret, _, err := procOpenCluster.Call(0)
+	if ret == 0 {
+		return 0, err
+	}
+
+	return windows.Handle(ret), nil
+}
+
+// closeCluster https://learn.microsoft.com/en-us/windows/win32/api/clusapi/nf-clusapi-closecluster
+func closeCluster(cluster windows.Handle) {
+	_, _, _ = procCloseCluster.Call(uintptr(cluster))
+}
+
+// openClusterGroup https://learn.microsoft.com/en-us/windows/win32/api/clusapi/nf-clusapi-openclustergroup
+func openClusterGroup(cluster windows.Handle, groupName string) (windows.Handle, error) {
+	name, err := windows.UTF16PtrFromString(groupName)
+	if err != nil {
+		return 0, err
+	}
+
+	ret, _, err := procOpenClusterGroup.Call(uintptr(cluster), uintptr(unsafe.Pointer(name)))
+	if ret == 0 {
+		return 0, err
+	}
+
+	return windows.Handle(ret), nil
+}
+
+// closeClusterGroup https://learn.microsoft.com/en-us/windows/win32/api/clusapi/nf-clusapi-closeclustergroup
+func closeClusterGroup(group windows.Handle) {
+	_, _, _ = procCloseClusterGroup.Call(uintptr(group))
+}
+
+// clusterGroupOpenEnum https://learn.microsoft.com/en-us/windows/win32/api/clusapi/nf-clusapi-clustergroupopenenum
+func clusterGroupOpenEnum(group windows.Handle, enumType uint32) (windows.Handle, error) {
+	ret, _, err := procClusterGroupOpenEnum.Call(uintptr(group), uintptr(enumType))
+	if ret == 0 {
+		return 0, err
+	}
+
+	return windows.Handle(ret), nil
+}
+
+// clusterGroupEnum https://learn.microsoft.com/en-us/windows/win32/api/clusapi/nf-clusapi-clustergroupenum
+func clusterGroupEnum(groupEnum windows.Handle, index uint32, objectType *uint32, name *uint16, size *uint32) windows.Errno {
+	ret, _, _ := procClusterGroupEnum.Call(
+		uintptr(groupEnum),
+		uintptr(index),
+		uintptr(unsafe.Pointer(objectType)),
+		uintptr(unsafe.Pointer(name)),
+		uintptr(unsafe.Pointer(size)),
+	)
+
+	return windows.Errno(ret)
+}
+
+// clusterGroupCloseEnum https://learn.microsoft.com/en-us/windows/win32/api/clusapi/nf-clusapi-clustergroupcloseenum
+func clusterGroupCloseEnum(groupEnum windows.Handle) {
+	_, _, _ = procClusterGroupCloseEnum.Call(uintptr(groupEnum))
+}
@@ -33,8 +33,10 @@ var (
 	procDhcpGetSuperScopeInfoV4          = modDhcpServer.NewProc("DhcpGetSuperScopeInfoV4")
 	procDhcpRpcFreeMemory                = modDhcpServer.NewProc("DhcpRpcFreeMemory")
 	procDhcpV4EnumSubnetReservations     = modDhcpServer.NewProc("DhcpV4EnumSubnetReservations")
+	procDhcpV4FailoverEnumRelationship   = modDhcpServer.NewProc("DhcpV4FailoverEnumRelationship")
 	procDhcpV4FailoverGetScopeStatistics = modDhcpServer.NewProc("DhcpV4FailoverGetScopeStatistics")
 	procDhcpGetMibInfoV5                 = modDhcpServer.NewProc("DhcpGetMibInfoV5")
+	procDhcpGetMibInfoV6                 = modDhcpServer.NewProc("DhcpGetMibInfoV6")
 )
 
 func GetDHCPV4ScopeStatistics() ([]DHCPV4Scope, error) {
@@ -85,6 +87,7 @@ func GetDHCPV4ScopeStatistics() ([]DHCPV4Scope, error) {
 				SuperScopeNumber: subnet.SuperScopeNumber,
 				State:            subnetInfo.SubnetState,
 
+				Addresses:                     -1,
 				AddressesFree:                 -1,
 				AddressesFreeOnPartnerServer:  -1,
 				AddressesFreeOnThisServer:     -1,
@@ -99,6 +102,7 @@ func GetDHCPV4ScopeStatistics() ([]DHCPV4Scope, error) {
 				scope.AddressesInUse = float64(subnetScopeInfo.NumAddressesInUse)
 				scope.AddressesFree = float64(subnetScopeInfo.NumAddressesFree)
 				scope.PendingOffers = float64(subnetScopeInfo.NumPendingOffers)
+				scope.Addresses = scope.AddressesFree + scope.AddressesInUse
 			}
 
 			subnetReservationCount, err := dhcpV4EnumSubnetReservations(subnet.SubnetAddress)
@@ -114,6 +118,7 @@ func GetDHCPV4ScopeStatistics() ([]DHCPV4Scope, error) {
 			defer dhcpRpcFreeMemory(unsafe.Pointer(subnetStatistics))
 
 			if err == nil {
+				scope.Addresses = float64(subnetStatistics.NumAddr)
 				scope.AddressesFree = float64(subnetStatistics.AddrFree)
 				scope.AddressesInUse = float64(subnetStatistics.AddrInUse)
 				scope.AddressesFreeOnPartnerServer = float64(subnetStatistics.PartnerAddrFree)
@@ -135,6 +140,144 @@ func GetDHCPV4ScopeStatistics() ([]DHCPV4Scope, error) {
 	return scopes, errors.Join(errs...)
 }
 
+// GetDHCPV4FailoverRelationships returns the failover relationships of the DHCPv4 server.
+func GetDHCPV4FailoverRelationships() ([]DHCPV4FailoverRelationship, error) {
+	var (
+		relationships []DHCPV4FailoverRelationship
+		resumeHandle  uint32
+	)
+
+	for {
+		var relationshipArray *DHCP_FAILOVER_RELATIONSHIP_ARRAY
+
+		err := dhcpV4FailoverEnumRelationship(&resumeHandle, &relationshipArray)
+		if relationshipArray != nil {
+			converted, convertErr := relationshipArray.relationships()
+			relationshipArray.free()
+
+			if convertErr != nil {
+				return nil, convertErr
+			}
+
+			relationships = append(relationships, converted...)
+		}
+
+		switch {
+		case errors.Is(err, windows.ERROR_MORE_DATA):
+			continue
+		case errors.Is(err, windows.ERROR_NO_MORE_ITEMS):
+			return relationships, nil
+		case err != nil:
+			return nil, err
+		default:
+			return relationships, nil
+		}
+	}
+}
+
+// relationships converts the relationships of the array, which is allocated by the DHCP server API.
+// The scopes are returned with their subnet mask, like DHCPV4Scope.ScopeIPAddress.
+func (a *DHCP_FAILOVER_RELATIONSHIP_ARRAY) relationships() ([]DHCPV4FailoverRelationship, error) {
+	relationships := make([]DHCPV4FailoverRelationship, 0, a.NumElements)
+
+	for _, relationship := range unsafe.Slice(a.pRelationships, a.NumElements) {
+		converted := DHCPV4FailoverRelationship{
+			Name:       windows.UTF16PtrToString(relationship.RelationshipName),
+			Mode:       relationship.Mode,
+			ServerType: relationship.ServerType,
+			State:      relationship.State,
+		}
+
+		if relationship.ServerType == PrimaryServer {
+			converted.PartnerServer = windows.UTF16PtrToString(relationship.SecondaryServerName)
+		} else {
+			converted.PartnerServer = windows.UTF16PtrToString(relationship.PrimaryServerName)
+		}
+
+		if relationship.pScopes != nil {
+			for _, scope := range unsafe.Slice(relationship.pScopes.Elements, relationship.pScopes.NumElements) {
+				scopeIPAddress, err := subnetIPNet(scope)
+				if err != nil {
+					return nil, err
+				}
+
+				converted.Scopes = append(converted.Scopes, scopeIPAddress)
+			}
+		}
+
+		relationships = append(relationships, converted)
+	}
+
+	return relationships, nil
+}
+
+func subnetIPNet(subnetAddress DHCP_IP_ADDRESS) (net.IPNet, error) {
+	var subnetInfo *DHCP_SUBNET_INFO
+
+	if err := dhcpGetSubnetInfo(subnetAddress, &subnetInfo); err != nil {
+		return net.IPNet{}, fmt.Errorf("failed to get subnet info: %w", err)
+	}
+
+	defer dhcpRpcFreeMemory(unsafe.Pointer(subnetInfo))
+
+	return net.IPNet{IP: subnetInfo.SubnetAddress.IPv4(), Mask: subnetInfo.SubnetMask.IPv4Mask()}, nil
+}
+
+// free releases the array and the strings and scopes of its relationships.
+func (a *DHCP_FAILOVER_RELATIONSHIP_ARRAY) free() {
+	for _, relationship := range unsafe.Slice(a.pRelationships, a.NumElements) {
+		dhcpRpcFreeMemory(unsafe.Pointer(relationship.RelationshipName))
+		dhcpRpcFreeMemory(unsafe.Pointer(relationship.PrimaryServerName))
+		dhcpRpcFreeMemory(unsafe.Pointer(relationship.SecondaryServerName))
+		dhcpRpcFreeMemory(unsafe.Pointer(relationship.SharedSecret))
+
+		if relationship.pScopes != nil {
+			dhcpRpcFreeMemory(unsafe.Pointer(relationship.pScopes.Elements))
+			dhcpRpcFreeMemory(unsafe.Pointer(relationship.pScopes))
+		}
+	}
+
+	dhcpRpcFreeMemory(unsafe.Pointer(a.pRelationships))
+	dhcpRpcFreeMemory(unsafe.Pointer(a))
+}
+
+func GetDHCPV6Statistics() (DHCPV6Statistics, error) {
+	var mibInfo *DHCP_MIB_INFO_V6
+
+	if err := dhcpGetMibInfoV6(&mibInfo); err != nil {
+		return DHCPV6Statistics{}, fmt.Errorf("dhcpGetMibInfoV6: %w", err)
+	} else if mibInfo == nil {
+		return DHCPV6Statistics{}, errors.New("dhcpGetMibInfoV6 returned nil")
+	}
+
+	defer dhcpRpcFreeMemory(unsafe.Pointer(mibInfo))
+
+	statistics := DHCPV6Statistics{
+		Solicits:   float64(mibInfo.Solicits),
+		Advertises: float64(mibInfo.Advertises),
+		Requests:   float64(mibInfo.Requests),
+		Renews:     float64(mibInfo.Renews),
+		Rebinds:    float64(mibInfo.Rebinds),
+		Replies:    float64(mibInfo.Replies),
+		Confirms:   float64(mibInfo.Confirms),
+		Declines:   float64(mibInfo.Declines),
+		Releases:   float64(mibInfo.Releases),
+		Informs:    float64(mibInfo.Informs),
+		Scopes:     make([]DHCPV6Scope, 0, mibInfo.Scopes),
+	}
+
+	for _, scopeInfo := range unsafe.Slice(mibInfo.ScopeInfo, mibInfo.Scopes) {
+		statistics.Scopes = append(statistics.Scopes, DHCPV6Scope{
+			SubnetAddress:     scopeInfo.Subnet.IPv6(),
+			AddressesFree:     float64(scopeInfo.NumAddressesFree),
+			AddressesInUse:    float64(scopeInfo.NumAddressesInuse),
+			PendingAdvertises: float64(scopeInfo.NumPendingAdvertises),
+		})
+	}
+
+	return statistics, nil
+}
+
 // dhcpGetSubnetInfo https://learn.microsoft.com/en-us/windows/win32/api/dhcpsapi/nf-dhcpsapi-dhcpgetsubnetinfo
 func dhcpGetSubnetInfo(subnetAddress DHCP_IP_ADDRESS, subnetInfo **DHCP_SUBNET_INFO) error {
 	ret, _, _ := procDhcpGetSubnetInfo.Call(
@@ -165,6 +308,26 @@ func dhcpV4FailoverGetScopeStatistics(scopeId DHCP_IP_ADDRESS, stats **DHCP_FAIL
 	return nil
 }
 
+// dhcpV4FailoverEnumRelationship https://learn.microsoft.com/en-us/windows/win32/api/dhcpsapi/nf-dhcpsapi-dhcpv4failoverenumrelationship
+func dhcpV4FailoverEnumRelationship(resumeHandle *uint32, relationships **DHCP_FAILOVER_RELATIONSHIP_ARRAY) error {
+	var read, total uint32
+
+	ret, _, _ := procDhcpV4FailoverEnumRelationship.Call(
+		0,
+		uintptr(unsafe.Pointer(resumeHandle)),
+		0xFFFFFFFF,
+		uintptr(unsafe.Pointer(relationships)),
+		uintptr(unsafe.Pointer(&read)),
+		uintptr(unsafe.Pointer(&total)),
+	)
+
+	if ret != 0 {
+		return fmt.Errorf("dhcpV4FailoverEnumRelationship failed with code %w", windows.Errno(ret))
+	}
+
+	return nil
+}
+
 // dhcpGetSuperScopeInfoV4 https://learn.microsoft.com/en-us/windows/win32/api/dhcpsapi/nf-dhcpsapi-dhcpgetsuperscopeinfov4
 func dhcpGetSuperScopeInfoV4(superScopeTable **DHCP_SUPER_SCOPE_TABLE) error {
 	ret, _, _ := procDhcpGetSuperScopeInfoV4.Call(
@@ -193,6 +356,20 @@ func dhcpGetMibInfoV5(mibInfo **DHCP_MIB_INFO_V5) error {
 	return nil
 }
 
+// dhcpGetMibInfoV6 https://learn.microsoft.com/en-us/windows/win32/api/dhcpsapi/nf-dhcpsapi-dhcpgetmibinfov6
+func dhcpGetMibInfoV6(mibInfo **DHCP_MIB_INFO_V6) error {
+	ret, _, _ := procDhcpGetMibInfoV6.Call(
+		0,
+		uintptr(unsafe.Pointer(mibInfo)),
+	)
+
+	if ret != 0 {
+		return fmt.Errorf("dhcpGetMibInfoV6 failed with code %w", windows.Errno(ret))
+	}
+
+	return nil
+}
+
 // dhcpV4EnumSubnetReservations https://learn.microsoft.com/en-us/windows/win32/api/dhcpsapi/nf-dhcpsapi-dhcpv4enumsubnetreservations
 func dhcpV4EnumSubnetReservations(subnetAddress DHCP_IP_ADDRESS) (uint32, error) {
 	var (
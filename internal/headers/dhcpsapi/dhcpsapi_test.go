@@ -39,3 +39,18 @@ func TestGetDHCPV4ScopeStatistics(t *testing.T) {
 
 	require.NoError(t, err)
 }
+
+func TestGetDHCPV4FailoverRelationships(t *testing.T) {
+	t.Parallel()
+
+	if procDhcpV4FailoverEnumRelationship.Find() != nil {
+		t.Skip("DhcpV4FailoverEnumRelationship is not available")
+	}
+
+	_, err := GetDHCPV4FailoverRelationships()
+	if errors.Is(err, windows.Errno(1753)) {
+		t.Skip(err.Error())
+	}
+
+	require.NoError(t, err)
+}
@@ -35,6 +35,8 @@ type DHCPV4Scope struct {
 	SuperScopeNumber uint32
 	ScopeIPAddress   net.IPNet
 
+	// Addresses is the size of the address pool, i.e. the free addresses and the addresses in use.
+	Addresses                     float64
 	AddressesFree                 float64
 	AddressesFreeOnPartnerServer  float64
 	AddressesFreeOnThisServer     float64
@@ -124,6 +126,113 @@ type DHCP_FAILOVER_STATISTICS struct {
 	ThisAddrInUse    win32.DWORD
 }
 
+// DHCPV4FailoverRelationship is a failover relationship of the DHCPv4 server with a partner server.
+type DHCPV4FailoverRelationship struct {
+	Name          string
+	Mode          DHCP_FAILOVER_MODE
+	ServerType    DHCP_FAILOVER_SERVER
+	State         FSM_STATE
+	PartnerServer string
+	Scopes        []net.IPNet
+}
+
+// DHCP_FAILOVER_MODE https://learn.microsoft.com/en-us/windows/win32/api/dhcpsapi/ne-dhcpsapi-dhcp_failover_mode
+type DHCP_FAILOVER_MODE uint32
+
+const (
+	LoadBalance DHCP_FAILOVER_MODE = 0
+	HotStandby  DHCP_FAILOVER_MODE = 1
+)
+
+//nolint:gochecknoglobals
+var DHCP_FAILOVER_MODE_NAMES = map[DHCP_FAILOVER_MODE]string{
+	LoadBalance: "LoadBalance",
+	HotStandby:  "HotStandby",
+}
+
+// DHCP_FAILOVER_SERVER https://learn.microsoft.com/en-us/windows/win32/api/dhcpsapi/ne-dhcpsapi-dhcp_failover_server
+type DHCP_FAILOVER_SERVER uint32
+
+const (
+	PrimaryServer   DHCP_FAILOVER_SERVER = 0
+	SecondaryServer DHCP_FAILOVER_SERVER = 1
+)
+
+//nolint:gochecknoglobals
+var DHCP_FAILOVER_SERVER_NAMES = map[DHCP_FAILOVER_SERVER]string{
+	PrimaryServer:   "Primary",
+	SecondaryServer: "Secondary",
+}
+
+// FSM_STATE https://learn.microsoft.com/en-us/windows/win32/api/dhcpsapi/ne-dhcpsapi-fsm_state
+type FSM_STATE uint32
+
+const (
+	NO_STATE           FSM_STATE = 0
+	INIT               FSM_STATE = 1
+	STARTUP            FSM_STATE = 2
+	NORMAL             FSM_STATE = 3
+	COMMUNICATION_INT  FSM_STATE = 4
+	PARTNER_DOWN       FSM_STATE = 5
+	POTENTIAL_CONFLICT FSM_STATE = 6
+	CONFLICT_DONE      FSM_STATE = 7
+	RESOLUTION_INT     FSM_STATE = 8
+	RECOVER            FSM_STATE = 9
+	RECOVER_WAIT       FSM_STATE = 10
+	RECOVER_DONE       FSM_STATE = 11
+	PAUSED             FSM_STATE = 12
+	SHUTDOWN           FSM_STATE = 13
+)
+
+//nolint:gochecknoglobals
+var FSM_STATE_NAMES = map[FSM_STATE]string{
+	NO_STATE:           "NoState",
+	INIT:               "Init",
+	STARTUP:            "Startup",
+	NORMAL:             "Normal",
+	COMMUNICATION_INT:  "CommunicationInterrupted",
+	PARTNER_DOWN:       "PartnerDown",
+	POTENTIAL_CONFLICT: "PotentialConflict",
+	CONFLICT_DONE:      "ConflictDone",
+	RESOLUTION_INT:     "ResolutionInterrupted",
+	RECOVER:            "Recover",
+	RECOVER_WAIT:       "RecoverWait",
+	RECOVER_DONE:       "RecoverDone",
+	PAUSED:             "Paused",
+	SHUTDOWN:           "Shutdown",
+}
+
+// DHCP_FAILOVER_RELATIONSHIP_ARRAY https://learn.microsoft.com/en-us/windows/win32/api/dhcpsapi/ns-dhcpsapi-dhcp_failover_relationship_array
+type DHCP_FAILOVER_RELATIONSHIP_ARRAY struct {
+	NumElements    win32.DWORD
+	pRelationships *DHCP_FAILOVER_RELATIONSHIP
+}
+
+// DHCP_FAILOVER_RELATIONSHIP https://learn.microsoft.com/en-us/windows/win32/api/dhcpsapi/ns-dhcpsapi-dhcp_failover_relationship
+type DHCP_FAILOVER_RELATIONSHIP struct {
+	PrimaryServer   DHCP_IP_ADDRESS
+	SecondaryServer DHCP_IP_ADDRESS
+	Mode            DHCP_FAILOVER_MODE
+	ServerType      DHCP_FAILOVER_SERVER
+	State           FSM_STATE
+	PrevState       FSM_STATE
+	Mclt            win32.DWORD
+	SafePeriod      win32.DWORD
+	// The strings are *uint16 instead of win32.LPWSTR, so they can be freed with DhcpRpcFreeMemory.
+	RelationshipName    *uint16
+	PrimaryServerName   *uint16
+	SecondaryServerName *uint16
+	pScopes             *DHCP_IP_ARRAY
+	Percentage          byte
+	SharedSecret        *uint16
+}
+
+// DHCP_IP_ARRAY https://learn.microsoft.com/en-us/windows/win32/api/dhcpsapi/ns-dhcpsapi-dhcp_ip_array
+type DHCP_IP_ARRAY struct {
+	NumElements win32.DWORD
+	Elements    *DHCP_IP_ADDRESS
+}
+
 type DHCP_MIB_INFO_V5 struct {
 	Discovers               win32.DWORD
 	Offers                  win32.DWORD
@@ -152,3 +261,67 @@ type DHCP_SUBNET_MIB_INFO_V5 struct {
 	NumAddressesFree  win32.DWORD
 	NumPendingOffers  win32.DWORD
 }
+
+type DHCPV6Scope struct {
+	SubnetAddress net.IP
+
+	AddressesFree     float64
+	AddressesInUse    float64
+	PendingAdvertises float64
+}
+
+// DHCPV6Statistics contains the message counters and scope statistics of the DHCPv6 server.
+type DHCPV6Statistics struct {
+	Solicits   float64
+	Advertises float64
+	Requests   float64
+	Renews     float64
+	Rebinds    float64
+	Replies    float64
+	Confirms   float64
+	Declines   float64
+	Releases   float64
+	Informs    float64
+
+	Scopes []DHCPV6Scope
+}
+
+// DHCP_IPV6_ADDRESS https://learn.microsoft.com/en-us/windows/win32/api/dhcpsapi/ns-dhcpsapi-dhcp_ipv6_address
+type DHCP_IPV6_ADDRESS struct {
+	HighOrderBits uint64
+	LowOrderBits  uint64
+}
+
+func (ip DHCP_IPV6_ADDRESS) IPv6() net.IP {
+	ipBytes := make([]byte, 16)
+
+	binary.BigEndian.PutUint64(ipBytes[:8], ip.HighOrderBits)
+	binary.BigEndian.PutUint64(ipBytes[8:], ip.LowOrderBits)
+
+	return ipBytes
+}
+
+// DHCP_MIB_INFO_V6 https://learn.microsoft.com/en-us/windows/win32/api/dhcpsapi/ns-dhcpsapi-dhcp_mib_info_v6
+type DHCP_MIB_INFO_V6 struct {
+	Solicits        win32.DWORD
+	Advertises      win32.DWORD
+	Requests        win32.DWORD
+	Renews          win32.DWORD
+	Rebinds         win32.DWORD
+	Replies         win32.DWORD
+	Confirms        win32.DWORD
+	Declines        win32.DWORD
+	Releases        win32.DWORD
+	Informs         win32.DWORD
+	ServerStartTime win32.DATE_TIME
+	Scopes          win32.DWORD
+	ScopeInfo       *SCOPE_MIB_INFO_V6
+}
+
+// SCOPE_MIB_INFO_V6 https://learn.microsoft.com/en-us/windows/win32/api/dhcpsapi/ns-dhcpsapi-scope_mib_info_v6
+type SCOPE_MIB_INFO_V6 struct {
+	Subnet               DHCP_IPV6_ADDRESS
+	NumAddressesInuse    uint64
+	NumAddressesFree     uint64
+	NumPendingAdvertises uint64
+}
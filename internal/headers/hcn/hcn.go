@@ -35,30 +35,46 @@ var (
 	hcnBodyEmpty         = utils.Must(windows.UTF16PtrFromString(""))
 	hcnMethodGet         = utils.Must(windows.UTF16PtrFromString("GET"))
 	hcnPathEndpoints     = utils.Must(windows.UTF16PtrFromString("/endpoints/"))
+	hcnPathNetworks      = utils.Must(windows.UTF16PtrFromString("/networks/"))
+	hcnPathPolicyLists   = utils.Must(windows.UTF16PtrFromString("/policylists/"))
 	hcnPathEndpointStats = utils.Must(windows.UTF16FromString("/endpointstats/"))
 )
 
 func ListEndpoints() ([]EndpointProperties, error) {
-	result, err := hnsCall(hcnMethodGet, hcnPathEndpoints, hcnBodyEmpty)
+	return hnsList[EndpointProperties](hcnPathEndpoints)
+}
+
+// ListNetworks returns the networks of the Host Networking Service.
+func ListNetworks() ([]NetworkProperties, error) {
+	return hnsList[NetworkProperties](hcnPathNetworks)
+}
+
+// ListPolicyLists returns the policy lists of the Host Networking Service, e.g. the load balancers of kube-proxy.
+func ListPolicyLists() ([]PolicyListProperties, error) {
+	return hnsList[PolicyListProperties](hcnPathPolicyLists)
+}
+
+func hnsList[T any](path *uint16) ([]T, error) {
+	result, err := hnsCall(hcnMethodGet, path, hcnBodyEmpty)
 	if err != nil {
 		return nil, err
 	}
 
-	var endpoints struct {
-		Success bool                 `json:"success"`
-		Error   string               `json:"error"`
-		Output  []EndpointProperties `json:"output"`
+	var list struct {
+		Success bool   `json:"success"`
+		Error   string `json:"error"`
+		Output  []T    `json:"output"`
 	}
 
-	if err := json.Unmarshal([]byte(result), &endpoints); err != nil {
+	if err := json.Unmarshal([]byte(result), &list); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal JSON %s: %w", result, err)
 	}
 
-	if !endpoints.Success {
-		return nil, fmt.Errorf("HNSCall failed: %s", endpoints.Error)
+	if !list.Success {
+		return nil, fmt.Errorf("HNSCall failed: %s", list.Error)
 	}
 
-	return endpoints.Output, nil
+	return list.Output, nil
 }
 
 func GetHNSEndpointStats(endpointID string) (EndpointStats, error) {
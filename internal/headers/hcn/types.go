@@ -27,9 +27,30 @@ type Endpoint = windows.Handle
 //
 // https://learn.microsoft.com/en-us/virtualization/api/hcn/hns_schema#HostComputeEndpoint
 type EndpointProperties struct {
-	ID               string   `json:"ID"`
-	State            int      `json:"State"`
-	SharedContainers []string `json:"SharedContainers"`
+	ID                 string   `json:"ID"`
+	State              int      `json:"State"`
+	SharedContainers   []string `json:"SharedContainers"`
+	VirtualNetworkName string   `json:"VirtualNetworkName"`
+	IsRemoteEndpoint   bool     `json:"IsRemoteEndpoint"`
+	Policies           []Policy `json:"Policies"`
+}
+
+// NetworkProperties contains the properties of an HNS network.
+type NetworkProperties struct {
+	ID   string `json:"ID"`
+	Name string `json:"Name"`
+	Type string `json:"Type"`
+}
+
+// PolicyListProperties contains the properties of an HNS policy list, e.g. a load balancer.
+type PolicyListProperties struct {
+	ID       string   `json:"ID"`
+	Policies []Policy `json:"Policies"`
+}
+
+// Policy is a policy of an endpoint or a policy list. Only the type is decoded.
+type Policy struct {
+	Type string `json:"Type"`
 }
 
 type EndpointStats struct {
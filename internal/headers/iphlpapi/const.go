@@ -18,6 +18,9 @@
 package iphlpapi
 
 const (
-	TCPTableOwnerPIDAll      uint32 = 5
-	TCPTableOwnerPIDListener uint32 = 3
+	TCPTableOwnerPIDAll         uint32 = 5
+	TCPTableOwnerPIDConnections uint32 = 4
+	TCPTableOwnerPIDListener    uint32 = 3
+
+	UDPTableOwnerPID uint32 = 1
 )
@@ -29,6 +29,10 @@ import (
 var (
 	modiphlpapi             = windows.NewLazySystemDLL("iphlpapi.dll")
 	procGetExtendedTcpTable = modiphlpapi.NewProc("GetExtendedTcpTable")
+	procGetExtendedUdpTable = modiphlpapi.NewProc("GetExtendedUdpTable")
+	procGetIcmpStatisticsEx = modiphlpapi.NewProc("GetIcmpStatisticsEx")
+	procGetIpNetTable2      = modiphlpapi.NewProc("GetIpNetTable2")
+	procFreeMibTable        = modiphlpapi.NewProc("FreeMibTable")
 )
 
 func GetTCPConnectionStates(family uint32) (map[MIB_TCP_STATE]uint32, error) {
@@ -95,6 +99,106 @@ func GetOwnerPIDOfTCPPort(family uint32, tcpPort uint16) (uint32, error) {
 	}
 }
 
+// GetTCPLocalPorts returns the local port of every TCP connection and listener of the given address family.
+func GetTCPLocalPorts(family uint32) ([]uint16, error) {
+	switch family {
+	case windows.AF_INET:
+		table, err := getExtendedTcpTable[MIB_TCPROW_OWNER_PID](family, TCPTableOwnerPIDAll)
+		if err != nil {
+			return nil, fmt.Errorf("failed getExtendedTcpTable: %w", err)
+		}
+
+		ports := make([]uint16, len(table))
+		for i, row := range table {
+			ports[i] = row.dwLocalPort.uint16()
+		}
+
+		return ports, nil
+	case windows.AF_INET6:
+		table, err := getExtendedTcpTable[MIB_TCP6ROW_OWNER_PID](family, TCPTableOwnerPIDAll)
+		if err != nil {
+			return nil, fmt.Errorf("failed getExtendedTcpTable: %w", err)
+		}
+
+		ports := make([]uint16, len(table))
+		for i, row := range table {
+			ports[i] = row.dwLocalPort.uint16()
+		}
+
+		return ports, nil
+	default:
+		return nil, fmt.Errorf("unsupported address family %d", family)
+	}
+}
+
+// GetTCPEstablishedRemotePorts returns the remote port of every established TCP connection of the given address family.
+func GetTCPEstablishedRemotePorts(family uint32) ([]uint16, error) {
+	switch family {
+	case windows.AF_INET:
+		table, err := getExtendedTcpTable[MIB_TCPROW_OWNER_PID](family, TCPTableOwnerPIDConnections)
+		if err != nil {
+			return nil, fmt.Errorf("failed getExtendedTcpTable: %w", err)
+		}
+
+		ports := make([]uint16, 0, len(table))
+		for _, row := range table {
+			if row.dwState == TCPStateEstablished {
+				ports = append(ports, row.dwRemotePort.uint16())
+			}
+		}
+
+		return ports, nil
+	case windows.AF_INET6:
+		table, err := getExtendedTcpTable[MIB_TCP6ROW_OWNER_PID](family, TCPTableOwnerPIDConnections)
+		if err != nil {
+			return nil, fmt.Errorf("failed getExtendedTcpTable: %w", err)
+		}
+
+		ports := make([]uint16, 0, len(table))
+		for _, row := range table {
+			if row.dwState == TCPStateEstablished {
+				ports = append(ports, row.dwRemotePort.uint16())
+			}
+		}
+
+		return ports, nil
+	default:
+		return nil, fmt.Errorf("unsupported address family %d", family)
+	}
+}
+
+// GetUDPLocalPorts returns the local port of every UDP endpoint of the given address family.
+func GetUDPLocalPorts(family uint32) ([]uint16, error) {
+	switch family {
+	case windows.AF_INET:
+		table, err := getExtendedUdpTable[MIB_UDPROW_OWNER_PID](family, UDPTableOwnerPID)
+		if err != nil {
+			return nil, fmt.Errorf("failed getExtendedUdpTable: %w", err)
+		}
+
+		ports := make([]uint16, len(table))
+		for i, row := range table {
+			ports[i] = row.dwLocalPort.uint16()
+		}
+
+		return ports, nil
+	case windows.AF_INET6:
+		table, err := getExtendedUdpTable[MIB_UDP6ROW_OWNER_PID](family, UDPTableOwnerPID)
+		if err != nil {
+			return nil, fmt.Errorf("failed getExtendedUdpTable: %w", err)
+		}
+
+		ports := make([]uint16, len(table))
+		for i, row := range table {
+			ports[i] = row.dwLocalPort.uint16()
+		}
+
+		return ports, nil
+	default:
+		return nil, fmt.Errorf("unsupported address family %d", family)
+	}
+}
+
 func getExtendedTcpTable[T any](ulAf uint32, tableClass uint32) ([]T, error) {
 	var size uint32
 
@@ -128,3 +232,90 @@ func getExtendedTcpTable[T any](ulAf uint32, tableClass uint32) ([]T, error) {
 
 	return unsafe.Slice((*T)(unsafe.Pointer(&buf[4])), binary.LittleEndian.Uint32(buf)), nil
 }
+
+func getExtendedUdpTable[T any](ulAf uint32, tableClass uint32) ([]T, error) {
+	var size uint32
+
+	ret, _, _ := procGetExtendedUdpTable.Call(
+		0,
+		uintptr(unsafe.Pointer(&size)),
+		0,
+		uintptr(ulAf),
+		uintptr(tableClass),
+		0,
+	)
+
+	if ret != uintptr(windows.ERROR_INSUFFICIENT_BUFFER) {
+		return nil, fmt.Errorf("getExtendedUdpTable (size query) failed with code %d", ret)
+	}
+
+	buf := make([]byte, size)
+
+	ret, _, _ = procGetExtendedUdpTable.Call(
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(unsafe.Pointer(&size)),
+		0,
+		uintptr(ulAf),
+		uintptr(tableClass),
+		0,
+	)
+
+	if ret != 0 {
+		return nil, fmt.Errorf("getExtendedUdpTable (data query) failed with code %d", ret)
+	}
+
+	return unsafe.Slice((*T)(unsafe.Pointer(&buf[4])), binary.LittleEndian.Uint32(buf)), nil
+}
+
+// GetIcmpStatisticsEx retrieves the ICMP statistics of the given address family (AF_INET or AF_INET6).
+// https://learn.microsoft.com/en-us/windows/win32/api/iphlpapi/nf-iphlpapi-geticmpstatisticsex
+func GetIcmpStatisticsEx(family uint32) (MIB_ICMP_EX, error) {
+	var stats MIB_ICMP_EX
+
+	ret, _, _ := procGetIcmpStatisticsEx.Call(
+		uintptr(unsafe.Pointer(&stats)),
+		uintptr(family),
+	)
+
+	if ret != 0 {
+		return stats, fmt.Errorf("GetIcmpStatisticsEx failed with code %w", windows.Errno(ret))
+	}
+
+	return stats, nil
+}
+
+// GetNeighborStateCounts returns the number of neighbor cache entries by interface index and state
+// for the given address family (AF_INET for ARP, AF_INET6 for NDP).
+// https://learn.microsoft.com/en-us/windows/win32/api/netioapi/nf-netioapi-getipnettable2
+func GetNeighborStateCounts(family uint32) (map[uint32]map[NL_NEIGHBOR_STATE]uint32, error) {
+	var table unsafe.Pointer
+
+	ret, _, _ := procGetIpNetTable2.Call(
+		uintptr(family),
+		uintptr(unsafe.Pointer(&table)),
+	)
+
+	if ret != 0 {
+		return nil, fmt.Errorf("GetIpNetTable2 failed with code %w", windows.Errno(ret))
+	}
+
+	defer func() {
+		_, _, _ = procFreeMibTable.Call(uintptr(table))
+	}()
+
+	// MIB_IPNET_TABLE2 starts with the number of entries, followed by the 8-byte aligned rows.
+	numEntries := *(*uint32)(table)
+	rows := unsafe.Slice((*MIB_IPNET_ROW2)(unsafe.Add(table, 8)), numEntries)
+
+	stateCounts := make(map[uint32]map[NL_NEIGHBOR_STATE]uint32)
+
+	for _, row := range rows {
+		if _, ok := stateCounts[row.InterfaceIndex]; !ok {
+			stateCounts[row.InterfaceIndex] = make(map[NL_NEIGHBOR_STATE]uint32)
+		}
+
+		stateCounts[row.InterfaceIndex][row.State]++
+	}
+
+	return stateCounts, nil
+}
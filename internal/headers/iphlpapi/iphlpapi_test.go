@@ -54,3 +54,72 @@ func TestGetOwnerPIDOfTCPPort(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, os.Getpid(), pid)
 }
+
+func TestGetTCPEstablishedRemotePorts(t *testing.T) {
+	t.Parallel()
+
+	var listenConf net.ListenConfig
+
+	lister, err := listenConf.Listen(t.Context(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, lister.Close())
+	})
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(t.Context(), "tcp", lister.Addr().String())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, conn.Close())
+	})
+
+	tcpAddr, ok := lister.Addr().(*net.TCPAddr)
+	require.True(t, ok)
+
+	ports, err := iphlpapi.GetTCPEstablishedRemotePorts(windows.AF_INET)
+	require.NoError(t, err)
+	require.Contains(t, ports, uint16(tcpAddr.Port))
+}
+
+func TestGetIcmpStatisticsEx(t *testing.T) {
+	t.Parallel()
+
+	_, err := iphlpapi.GetIcmpStatisticsEx(windows.AF_INET)
+	require.NoError(t, err)
+
+	_, err = iphlpapi.GetIcmpStatisticsEx(windows.AF_INET6)
+	require.NoError(t, err)
+}
+
+func TestGetUDPLocalPorts(t *testing.T) {
+	t.Parallel()
+
+	var listenConf net.ListenConfig
+
+	conn, err := listenConf.ListenPacket(t.Context(), "udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, conn.Close())
+	})
+
+	udpAddr, ok := conn.LocalAddr().(*net.UDPAddr)
+	require.True(t, ok)
+
+	ports, err := iphlpapi.GetUDPLocalPorts(windows.AF_INET)
+	require.NoError(t, err)
+	require.Contains(t, ports, uint16(udpAddr.Port))
+}
+
+func TestGetNeighborStateCounts(t *testing.T) {
+	t.Parallel()
+
+	_, err := iphlpapi.GetNeighborStateCounts(windows.AF_INET)
+	require.NoError(t, err)
+
+	_, err = iphlpapi.GetNeighborStateCounts(windows.AF_INET6)
+	require.NoError(t, err)
+}
@@ -52,6 +52,27 @@ type MIB_TCP6ROW_OWNER_PID struct {
 	dwOwningPid     uint32
 }
 
+// MIB_UDPROW_OWNER_PID structure for IPv4.
+// https://learn.microsoft.com/en-us/windows/win32/api/udpmib/ns-udpmib-mib_udprow_owner_pid
+//
+//nolint:unused
+type MIB_UDPROW_OWNER_PID struct {
+	dwLocalAddr BigEndianUint32
+	dwLocalPort BigEndianUint32
+	dwOwningPid uint32
+}
+
+// MIB_UDP6ROW_OWNER_PID structure for IPv6.
+// https://learn.microsoft.com/en-us/windows/win32/api/udpmib/ns-udpmib-mib_udp6row_owner_pid
+//
+//nolint:unused
+type MIB_UDP6ROW_OWNER_PID struct {
+	ucLocalAddr    [16]byte
+	dwLocalScopeId uint32
+	dwLocalPort    BigEndianUint32
+	dwOwningPid    uint32
+}
+
 type MIB_TCP_STATE uint32
 
 const (
@@ -160,3 +181,69 @@ type MIB_IF_ROW2 struct {
 	OutBroadcastOctets          uint64
 	OutQLen                     uint64
 }
+
+// MIB_ICMP_EX structure contains the inbound and outbound ICMP statistics.
+// https://learn.microsoft.com/en-us/windows/win32/api/ipmib/ns-ipmib-mib_icmp_ex_xpsp1
+type MIB_ICMP_EX struct {
+	InStats  MIBICMPSTATS_EX
+	OutStats MIBICMPSTATS_EX
+}
+
+// MIBICMPSTATS_EX structure contains the ICMP message counters of one direction.
+// TypeCount is indexed by the ICMP or ICMPv6 message type.
+// https://learn.microsoft.com/en-us/windows/win32/api/ipmib/ns-ipmib-mibicmpstats_ex_xpsp1
+type MIBICMPSTATS_EX struct {
+	Msgs      uint32
+	Errors    uint32
+	TypeCount [256]uint32
+}
+
+// MIB_IPNET_ROW2 structure contains a neighbor (ARP or NDP) cache entry.
+// https://learn.microsoft.com/en-us/windows/win32/api/netioapi/ns-netioapi-mib_ipnet_row2
+//
+//nolint:unused
+type MIB_IPNET_ROW2 struct {
+	address               [28]byte // SOCKADDR_INET
+	InterfaceIndex        uint32
+	InterfaceLuid         uint64
+	physicalAddress       [IF_MAX_PHYS_ADDRESS_LENGTH]byte
+	physicalAddressLength uint32
+	State                 NL_NEIGHBOR_STATE
+	flags                 uint8
+	reachabilityTime      uint32
+}
+
+// NL_NEIGHBOR_STATE is the state of a neighbor cache entry.
+// https://learn.microsoft.com/en-us/windows/win32/api/nldef/ne-nldef-nl_neighbor_state
+type NL_NEIGHBOR_STATE uint32
+
+const (
+	NlnsUnreachable NL_NEIGHBOR_STATE = iota
+	NlnsIncomplete
+	NlnsProbe
+	NlnsDelay
+	NlnsStale
+	NlnsReachable
+	NlnsPermanent
+)
+
+func (state NL_NEIGHBOR_STATE) String() string {
+	switch state {
+	case NlnsUnreachable:
+		return "unreachable"
+	case NlnsIncomplete:
+		return "incomplete"
+	case NlnsProbe:
+		return "probe"
+	case NlnsDelay:
+		return "delay"
+	case NlnsStale:
+		return "stale"
+	case NlnsReachable:
+		return "reachable"
+	case NlnsPermanent:
+		return "permanent"
+	default:
+		return fmt.Sprintf("unknown_%d", state)
+	}
+}
@@ -32,6 +32,8 @@ var (
 	procGetTickCount                     = modkernel32.NewProc("GetTickCount64")
 	procOpenJobObject                    = modkernel32.NewProc("OpenJobObjectW")
 	procIsProcessInJob                   = modkernel32.NewProc("IsProcessInJob")
+	procGetProcessInformation            = modkernel32.NewProc("GetProcessInformation")
+	procQueryPerformanceFrequency        = modkernel32.NewProc("QueryPerformanceFrequency")
 )
 
 // SYSTEMTIME contains a date and time.
@@ -87,3 +89,47 @@ func GetTickCount64() uint64 {
 
 	return uint64(ret)
 }
+
+const (
+	// ProcessProtectionLevelInfo is the PROCESS_INFORMATION_CLASS to retrieve the protection level of a process.
+	// 📑 https://learn.microsoft.com/en-us/windows/win32/api/processthreadsapi/ne-processthreadsapi-process_information_class
+	ProcessProtectionLevelInfo = 7
+
+	// ProtectionLevelLsaLight is the protection level of LSASS running as protected process light (PPL).
+	// 📑 https://learn.microsoft.com/en-us/windows/win32/api/processthreadsapi/ns-processthreadsapi-process_protection_level_information
+	ProtectionLevelLsaLight = 4
+	// ProtectionLevelNone indicates that the process is not protected.
+	ProtectionLevelNone = 0xFFFFFFFE
+)
+
+// GetProcessProtectionLevel retrieves the protection level of the specified process.
+// The handle must have the PROCESS_QUERY_LIMITED_INFORMATION access right.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/processthreadsapi/nf-processthreadsapi-getprocessinformation
+func GetProcessProtectionLevel(process windows.Handle) (uint32, error) {
+	var protectionLevel uint32
+
+	ret, _, err := procGetProcessInformation.Call(
+		uintptr(process),
+		ProcessProtectionLevelInfo,
+		uintptr(unsafe.Pointer(&protectionLevel)),
+		unsafe.Sizeof(protectionLevel),
+	)
+	if ret == 0 {
+		return 0, err
+	}
+
+	return protectionLevel, nil
+}
+
+// QueryPerformanceFrequency retrieves the frequency of the performance counter in counts per second.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/profileapi/nf-profileapi-queryperformancefrequency
+func QueryPerformanceFrequency() (int64, error) {
+	var frequency int64
+
+	r0, _, err := procQueryPerformanceFrequency.Call(uintptr(unsafe.Pointer(&frequency)))
+	if r0 == 0 {
+		return 0, err
+	}
+
+	return frequency, nil
+}
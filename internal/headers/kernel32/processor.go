@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package kernel32
+
+import (
+	"errors"
+	"math/bits"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var procGetLogicalProcessorInformationEx = modkernel32.NewProc("GetLogicalProcessorInformationEx")
+
+// relationProcessorCore is the LOGICAL_PROCESSOR_RELATIONSHIP value to retrieve the processor cores.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winnt/ne-winnt-logical_processor_relationship
+const relationProcessorCore = 0
+
+// ltpPcSmt is set in PROCESSOR_RELATIONSHIP.Flags if the core has more than one logical processor.
+const ltpPcSmt = 0x1
+
+// groupAffinity is a wrapper for GROUP_AFFINITY.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winnt/ns-winnt-group_affinity
+type groupAffinity struct {
+	Mask     uintptr
+	Group    uint16
+	Reserved [3]uint16
+}
+
+// processorRelationship is a wrapper for PROCESSOR_RELATIONSHIP.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winnt/ns-winnt-processor_relationship
+type processorRelationship struct {
+	Flags           uint8
+	EfficiencyClass uint8
+	Reserved        [20]byte
+	GroupCount      uint16
+	GroupMask       [1]groupAffinity
+}
+
+// systemLogicalProcessorInformationEx is a wrapper for SYSTEM_LOGICAL_PROCESSOR_INFORMATION_EX
+// limited to the PROCESSOR_RELATIONSHIP member of the union.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winnt/ns-winnt-system_logical_processor_information_ex
+type systemLogicalProcessorInformationEx struct {
+	Relationship uint32
+	Size         uint32
+	Processor    processorRelationship
+}
+
+// ProcessorNumber identifies a logical processor by its processor group and number within the group.
+type ProcessorNumber struct {
+	Group  uint16
+	Number uint8
+}
+
+// ProcessorCore is a physical processor core.
+type ProcessorCore struct {
+	// EfficiencyClass is the relative performance of the core. On hybrid processors, e.g. ARM big.LITTLE designs,
+	// cores with a higher efficiency class have a higher performance and a higher power consumption.
+	// It is 0 on processors with homogeneous cores.
+	EfficiencyClass uint8
+	// SMT is true if the core has more than one logical processor.
+	SMT bool
+	// LogicalProcessors are the logical processors of the core.
+	LogicalProcessors []ProcessorNumber
+}
+
+// GetProcessorCores retrieves the physical processor cores of the system.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/sysinfoapi/nf-sysinfoapi-getlogicalprocessorinformationex
+func GetProcessorCores() ([]ProcessorCore, error) {
+	var length uint32
+
+	ret, _, err := procGetLogicalProcessorInformationEx.Call(relationProcessorCore, 0, uintptr(unsafe.Pointer(&length)))
+	if ret == 0 && !errors.Is(err, windows.ERROR_INSUFFICIENT_BUFFER) {
+		return nil, err
+	}
+
+	if length == 0 {
+		return nil, nil
+	}
+
+	buffer := make([]byte, length)
+
+	ret, _, err = procGetLogicalProcessorInformationEx.Call(
+		relationProcessorCore,
+		uintptr(unsafe.Pointer(&buffer[0])),
+		uintptr(unsafe.Pointer(&length)),
+	)
+	if ret == 0 {
+		return nil, err
+	}
+
+	cores := make([]ProcessorCore, 0)
+
+	for offset := uint32(0); offset < length; {
+		info := (*systemLogicalProcessorInformationEx)(unsafe.Pointer(&buffer[offset]))
+		if info.Size == 0 {
+			break
+		}
+
+		offset += info.Size
+
+		if info.Relationship != relationProcessorCore {
+			continue
+		}
+
+		core := ProcessorCore{
+			EfficiencyClass: info.Processor.EfficiencyClass,
+			SMT:             info.Processor.Flags&ltpPcSmt != 0,
+		}
+
+		groupMasks := unsafe.Slice(&info.Processor.GroupMask[0], info.Processor.GroupCount)
+		for _, groupMask := range groupMasks {
+			for mask := uint64(groupMask.Mask); mask != 0; mask &= mask - 1 {
+				core.LogicalProcessors = append(core.LogicalProcessors, ProcessorNumber{
+					Group:  groupMask.Group,
+					Number: uint8(bits.TrailingZeros64(mask)),
+				})
+			}
+		}
+
+		cores = append(cores, core)
+	}
+
+	return cores, nil
+}
@@ -48,6 +48,26 @@ type JobObjectMemoryUsageInformation struct {
 	PeakJobMemoryUsed uint64
 }
 
+const (
+	JobObjectCPURateControlEnable      = 0x1
+	JobObjectCPURateControlWeightBased = 0x2
+	JobObjectCPURateControlHardCap     = 0x4
+	JobObjectCPURateControlMinMaxRate  = 0x10
+)
+
+// JobObjectCPURateControlInformation contains CPU rate control information for a job object.
+// Rate is CpuRate, Weight or MinRate and MaxRate depending on ControlFlags.
+// https://learn.microsoft.com/en-us/windows/win32/api/winnt/ns-winnt-jobobject_cpu_rate_control_information
+type JobObjectCPURateControlInformation struct {
+	ControlFlags uint32
+	Rate         uint32
+}
+
+// MaxRate returns the upper 16 bits of Rate, which contain the maximum rate if JobObjectCPURateControlMinMaxRate is set.
+func (i JobObjectCPURateControlInformation) MaxRate() uint16 {
+	return uint16(i.Rate >> 16)
+}
+
 type JobObjectBasicProcessIDList struct {
 	NumberOfAssignedProcesses uint32
 	NumberOfProcessIdsInList  uint32
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package netapi32
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Flags of DsGetDcName.
+// https://learn.microsoft.com/en-us/windows/win32/api/dsgetdc/nf-dsgetdc-dsgetdcnamew
+const (
+	DS_FORCE_REDISCOVERY          = 0x00000001
+	DS_DIRECTORY_SERVICE_REQUIRED = 0x00000010
+	DS_TIMESERV_REQUIRED          = 0x00000800
+	DS_GOOD_TIMESERV_PREFERRED    = 0x00002000
+	DS_RETURN_DNS_NAME            = 0x40000000
+)
+
+// Flags of DOMAIN_CONTROLLER_INFO, which describe the services the domain controller advertises.
+// https://learn.microsoft.com/en-us/windows/win32/api/dsgetdc/ns-dsgetdc-domain_controller_infow
+const (
+	DS_PDC_FLAG           = 0x00000001
+	DS_GC_FLAG            = 0x00000004
+	DS_LDAP_FLAG          = 0x00000008
+	DS_DS_FLAG            = 0x00000010
+	DS_KDC_FLAG           = 0x00000020
+	DS_TIMESERV_FLAG      = 0x00000040
+	DS_CLOSEST_FLAG       = 0x00000080
+	DS_WRITABLE_FLAG      = 0x00000100
+	DS_GOOD_TIMESERV_FLAG = 0x00000200
+	DS_WS_FLAG            = 0x00002000
+)
+
+// NERR_NetNameNotFound is returned by NetShareGetInfo if the share does not exist.
+const NERR_NetNameNotFound = 2310
+
+//nolint:gochecknoglobals
+var (
+	procDsGetDcNameW    = netapi32.NewProc("DsGetDcNameW")
+	procNetShareGetInfo = netapi32.NewProc("NetShareGetInfo")
+)
+
+// domainControllerInfo is a wrapper of DOMAIN_CONTROLLER_INFOW
+// https://learn.microsoft.com/en-us/windows/win32/api/dsgetdc/ns-dsgetdc-domain_controller_infow
+type domainControllerInfo struct {
+	DomainControllerName        *uint16
+	DomainControllerAddress     *uint16
+	DomainControllerAddressType uint32
+	DomainGuid                  windows.GUID
+	DomainName                  *uint16
+	DnsForestName               *uint16
+	Flags                       uint32
+	DcSiteName                  *uint16
+	ClientSiteName              *uint16
+}
+
+// DomainControllerInfo is an idiomatic wrapper of domainControllerInfo.
+type DomainControllerInfo struct {
+	// DomainControllerName is the name of the domain controller without the leading backslashes.
+	DomainControllerName string
+	DomainName           string
+	DnsForestName        string
+	Flags                uint32
+	DcSiteName           string
+}
+
+// DsGetDcName locates a domain controller of the domain of the computer with the given flags.
+// If it is called on a domain controller that advertises itself, the local domain controller is returned.
+// https://learn.microsoft.com/en-us/windows/win32/api/dsgetdc/nf-dsgetdc-dsgetdcnamew
+func DsGetDcName(flags uint32) (DomainControllerInfo, error) {
+	var info *domainControllerInfo
+
+	r1, _, _ := procDsGetDcNameW.Call(0, 0, 0, 0, uintptr(flags), uintptr(unsafe.Pointer(&info)))
+	if info != nil {
+		defer procNetApiBufferFree.Call(uintptr(unsafe.Pointer(info))) //nolint:errcheck
+	}
+
+	if ret := uint32(r1); ret != 0 {
+		return DomainControllerInfo{}, fmt.Errorf("DsGetDcName failed: %w", windows.Errno(ret))
+	}
+
+	dcName := windows.UTF16PtrToString(info.DomainControllerName)
+	for len(dcName) > 0 && dcName[0] == '\\' {
+		dcName = dcName[1:]
+	}
+
+	return DomainControllerInfo{
+		DomainControllerName: dcName,
+		DomainName:           windows.UTF16PtrToString(info.DomainName),
+		DnsForestName:        windows.UTF16PtrToString(info.DnsForestName),
+		Flags:                info.Flags,
+		DcSiteName:           windows.UTF16PtrToString(info.DcSiteName),
+	}, nil
+}
+
+// ShareExists reports whether the local computer shares a folder with the given name.
+// https://learn.microsoft.com/en-us/windows/win32/api/lmshare/nf-lmshare-netsharegetinfo
+func ShareExists(shareName string) (bool, error) {
+	shareNamePtr, err := windows.UTF16PtrFromString(shareName)
+	if err != nil {
+		return false, err
+	}
+
+	// SHARE_INFO_0 only contains the name of the share.
+	var info *uint16
+
+	r1, _, _ := procNetShareGetInfo.Call(0, uintptr(unsafe.Pointer(shareNamePtr)), 0, uintptr(unsafe.Pointer(&info)))
+	if info != nil {
+		defer procNetApiBufferFree.Call(uintptr(unsafe.Pointer(info))) //nolint:errcheck
+	}
+
+	switch ret := uint32(r1); ret {
+	case 0:
+		return true, nil
+	case NERR_NetNameNotFound:
+		return false, nil
+	default:
+		if status, ok := NetApiStatus[ret]; ok {
+			return false, fmt.Errorf("NetShareGetInfo failed: %s", status)
+		}
+
+		return false, fmt.Errorf("NetShareGetInfo failed: %d", ret)
+	}
+}
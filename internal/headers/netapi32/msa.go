@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package netapi32
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// States of MSA_INFO_0, which describe whether a managed service account can be used on the computer.
+// https://learn.microsoft.com/en-us/windows/win32/api/lmaccess/ne-lmaccess-msa_info_state
+const (
+	MsaInfoNotExist      = 1
+	MsaInfoNotService    = 2
+	MsaInfoCannotInstall = 3
+	MsaInfoCanInstall    = 4
+	MsaInfoInstalled     = 5
+)
+
+//nolint:gochecknoglobals
+var procNetQueryServiceAccount = netapi32.NewProc("NetQueryServiceAccount")
+
+// msaInfo0 is a wrapper of MSA_INFO_0
+// https://learn.microsoft.com/en-us/windows/win32/api/lmaccess/ns-lmaccess-msa_info_0
+type msaInfo0 struct {
+	State uint32
+}
+
+// QueryServiceAccount returns the MsaInfo* state of the managed service account on the local computer.
+// For group managed service accounts, the state is MsaInfoInstalled only if the computer can retrieve the password.
+// https://learn.microsoft.com/en-us/windows/win32/api/lmaccess/nf-lmaccess-netqueryserviceaccount
+func QueryServiceAccount(accountName string) (uint32, error) {
+	accountNamePtr, err := windows.UTF16PtrFromString(accountName)
+	if err != nil {
+		return 0, err
+	}
+
+	var info *msaInfo0
+
+	r1, _, _ := procNetQueryServiceAccount.Call(0, uintptr(unsafe.Pointer(accountNamePtr)), 0, uintptr(unsafe.Pointer(&info)))
+	if info != nil {
+		defer procNetApiBufferFree.Call(uintptr(unsafe.Pointer(info))) //nolint:errcheck
+	}
+
+	// NetQueryServiceAccount returns an NTSTATUS.
+	if r1 != 0 {
+		return 0, fmt.Errorf("NetQueryServiceAccount failed: %w", windows.NTStatus(uint32(r1)))
+	}
+
+	return info.State, nil
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package netapi32
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Flags of USER_INFO_1.
+// https://learn.microsoft.com/en-us/windows/win32/api/lmaccess/ns-lmaccess-user_info_1
+const (
+	UF_ACCOUNTDISABLE     = 0x00000002
+	UF_DONT_EXPIRE_PASSWD = 0x00010000
+)
+
+// TIMEQ_FOREVER is the max_passwd_age of USER_MODALS_INFO_0 if passwords never expire.
+const TIMEQ_FOREVER = 0xFFFFFFFF
+
+//nolint:gochecknoglobals
+var (
+	procNetUserGetInfo   = netapi32.NewProc("NetUserGetInfo")
+	procNetUserModalsGet = netapi32.NewProc("NetUserModalsGet")
+)
+
+// userInfo1 is a wrapper of USER_INFO_1
+// https://learn.microsoft.com/en-us/windows/win32/api/lmaccess/ns-lmaccess-user_info_1
+type userInfo1 struct {
+	usri1_name         *uint16
+	usri1_password     *uint16
+	usri1_password_age uint32
+	usri1_priv         uint32
+	usri1_home_dir     *uint16
+	usri1_comment      *uint16
+	usri1_flags        uint32
+	usri1_script_path  *uint16
+}
+
+// userModalsInfo0 is a wrapper of USER_MODALS_INFO_0
+// https://learn.microsoft.com/en-us/windows/win32/api/lmaccess/ns-lmaccess-user_modals_info_0
+type userModalsInfo0 struct {
+	usrmod0_min_passwd_len    uint32
+	usrmod0_max_passwd_age    uint32
+	usrmod0_min_passwd_age    uint32
+	usrmod0_force_logoff      uint32
+	usrmod0_password_hist_len uint32
+}
+
+// UserInfo is an idiomatic wrapper of userInfo1.
+type UserInfo struct {
+	// PasswordAge is the time since the password was last changed.
+	PasswordAge time.Duration
+	// Flags are the UF_* flags of the account.
+	Flags uint32
+}
+
+// GetUserPasswordAge returns the time since the password of the local user was last changed.
+// https://learn.microsoft.com/en-us/windows/win32/api/lmaccess/nf-lmaccess-netusergetinfo
+func GetUserPasswordAge(userName string) (time.Duration, error) {
+	info, err := GetUserInfo("", userName)
+	if err != nil {
+		return 0, err
+	}
+
+	return info.PasswordAge, nil
+}
+
+// GetUserInfo returns the password age and the flags of a user of the given server.
+// If serverName is empty, the local computer is queried. Domain users are queried on a domain controller.
+// https://learn.microsoft.com/en-us/windows/win32/api/lmaccess/nf-lmaccess-netusergetinfo
+func GetUserInfo(serverName, userName string) (UserInfo, error) {
+	serverNamePtr, err := optionalUTF16Ptr(serverName)
+	if err != nil {
+		return UserInfo{}, err
+	}
+
+	userNamePtr, err := windows.UTF16PtrFromString(userName)
+	if err != nil {
+		return UserInfo{}, err
+	}
+
+	var info *userInfo1
+
+	r1, _, _ := procNetUserGetInfo.Call(uintptr(unsafe.Pointer(serverNamePtr)), uintptr(unsafe.Pointer(userNamePtr)), 1, uintptr(unsafe.Pointer(&info)))
+	if info != nil {
+		defer procNetApiBufferFree.Call(uintptr(unsafe.Pointer(info))) //nolint:errcheck
+	}
+
+	if ret := uint32(r1); ret != 0 {
+		if status, ok := NetApiStatus[ret]; ok {
+			return UserInfo{}, errors.New(status)
+		}
+
+		return UserInfo{}, fmt.Errorf("NetUserGetInfo failed: %d", ret)
+	}
+
+	return UserInfo{
+		PasswordAge: time.Duration(info.usri1_password_age) * time.Second,
+		Flags:       info.usri1_flags,
+	}, nil
+}
+
+// GetMaxPasswordAge returns the maximum password age of the password policy of the given server.
+// If serverName is empty, the policy of the local computer is returned. The result is 0 if passwords never expire.
+// https://learn.microsoft.com/en-us/windows/win32/api/lmaccess/nf-lmaccess-netusermodalsget
+func GetMaxPasswordAge(serverName string) (time.Duration, error) {
+	serverNamePtr, err := optionalUTF16Ptr(serverName)
+	if err != nil {
+		return 0, err
+	}
+
+	var info *userModalsInfo0
+
+	r1, _, _ := procNetUserModalsGet.Call(uintptr(unsafe.Pointer(serverNamePtr)), 0, uintptr(unsafe.Pointer(&info)))
+	if info != nil {
+		defer procNetApiBufferFree.Call(uintptr(unsafe.Pointer(info))) //nolint:errcheck
+	}
+
+	if ret := uint32(r1); ret != 0 {
+		if status, ok := NetApiStatus[ret]; ok {
+			return 0, errors.New(status)
+		}
+
+		return 0, fmt.Errorf("NetUserModalsGet failed: %d", ret)
+	}
+
+	if info.usrmod0_max_passwd_age == TIMEQ_FOREVER {
+		return 0, nil
+	}
+
+	return time.Duration(info.usrmod0_max_passwd_age) * time.Second, nil
+}
+
+// optionalUTF16Ptr returns nil for an empty string, which the NetApi functions interpret as the local computer.
+func optionalUTF16Ptr(s string) (*uint16, error) {
+	if s == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	return windows.UTF16PtrFromString(s)
+}
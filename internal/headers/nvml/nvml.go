@@ -0,0 +1,247 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+// Package nvml wraps the NVIDIA Management Library (NVML), which is installed with the NVIDIA driver.
+// https://docs.nvidia.com/deploy/nvml-api/index.html
+package nvml
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	deviceNameBufferSize = 96
+	deviceUUIDBufferSize = 96
+
+	// temperatureGPU is NVML_TEMPERATURE_GPU, the temperature sensor on the GPU die.
+	temperatureGPU = 0
+)
+
+// Return is an nvmlReturn_t error code.
+type Return uint32
+
+const (
+	SUCCESS             Return = 0
+	ERROR_NOT_SUPPORTED Return = 3
+)
+
+// ErrNotSupported is returned for queries that the device doesn't support, e.g. the power usage of consumer GPUs.
+var ErrNotSupported = errors.New("not supported by the device")
+
+//nolint:gochecknoglobals
+var (
+	modNvml                        = windows.NewLazySystemDLL("nvml.dll")
+	procNvmlInitV2                 = modNvml.NewProc("nvmlInit_v2")
+	procNvmlShutdown               = modNvml.NewProc("nvmlShutdown")
+	procNvmlErrorString            = modNvml.NewProc("nvmlErrorString")
+	procNvmlDeviceGetCountV2       = modNvml.NewProc("nvmlDeviceGetCount_v2")
+	procNvmlDeviceGetHandleByIndex = modNvml.NewProc("nvmlDeviceGetHandleByIndex_v2")
+	procNvmlDeviceGetName          = modNvml.NewProc("nvmlDeviceGetName")
+	procNvmlDeviceGetUUID          = modNvml.NewProc("nvmlDeviceGetUUID")
+	procNvmlDeviceGetPciInfoV3     = modNvml.NewProc("nvmlDeviceGetPciInfo_v3")
+	procNvmlDeviceGetUtilization   = modNvml.NewProc("nvmlDeviceGetUtilizationRates")
+	procNvmlDeviceGetMemoryInfo    = modNvml.NewProc("nvmlDeviceGetMemoryInfo")
+	procNvmlDeviceGetTemperature   = modNvml.NewProc("nvmlDeviceGetTemperature")
+	procNvmlDeviceGetPowerUsage    = modNvml.NewProc("nvmlDeviceGetPowerUsage")
+	procNvmlDeviceGetEncoderUtil   = modNvml.NewProc("nvmlDeviceGetEncoderUtilization")
+	procNvmlDeviceGetDecoderUtil   = modNvml.NewProc("nvmlDeviceGetDecoderUtilization")
+	procNvmlSystemGetDriverVersion = modNvml.NewProc("nvmlSystemGetDriverVersion")
+)
+
+// Device is an nvmlDevice_t handle.
+type Device uintptr
+
+// Utilization is nvmlUtilization_t. Both values are percentages of the last sample period.
+type Utilization struct {
+	GPU    uint32
+	Memory uint32
+}
+
+// Memory is nvmlMemory_t in bytes.
+type Memory struct {
+	Total uint64
+	Free  uint64
+	Used  uint64
+}
+
+// PciInfo is nvmlPciInfo_t.
+type PciInfo struct {
+	busIDLegacy    [16]byte
+	Domain         uint32
+	Bus            uint32
+	Device         uint32
+	PciDeviceID    uint32
+	PciSubSystemID uint32
+	busID          [32]byte
+}
+
+// BusID returns the PCI bus ID, e.g. 00000000:08:00.0.
+func (p PciInfo) BusID() string {
+	return windows.ByteSliceToString(p.busID[:])
+}
+
+func (r Return) Error() string {
+	if err := procNvmlErrorString.Find(); err != nil {
+		return fmt.Sprintf("NVML error %d", uint32(r))
+	}
+
+	ret, _, _ := procNvmlErrorString.Call(uintptr(r))
+
+	// ret is a pointer to a static string of nvml.dll.
+	return windows.BytePtrToString(*(**byte)(unsafe.Pointer(&ret)))
+}
+
+func (r Return) Is(target error) bool {
+	return r == ERROR_NOT_SUPPORTED && target == ErrNotSupported //nolint:errorlint
+}
+
+// Load returns an error if nvml.dll isn't installed, e.g. on hosts without an NVIDIA GPU.
+func Load() error {
+	return modNvml.Load()
+}
+
+func Init() error {
+	return call(procNvmlInitV2)
+}
+
+func Shutdown() error {
+	return call(procNvmlShutdown)
+}
+
+// SystemGetDriverVersion returns the version of the installed NVIDIA driver.
+func SystemGetDriverVersion() (string, error) {
+	var version [80]byte
+
+	if err := call(procNvmlSystemGetDriverVersion, uintptr(unsafe.Pointer(&version[0])), uintptr(len(version))); err != nil {
+		return "", err
+	}
+
+	return windows.ByteSliceToString(version[:]), nil
+}
+
+func DeviceGetCount() (uint32, error) {
+	var count uint32
+
+	err := call(procNvmlDeviceGetCountV2, uintptr(unsafe.Pointer(&count)))
+
+	return count, err
+}
+
+func DeviceGetHandleByIndex(index uint32) (Device, error) {
+	var device Device
+
+	err := call(procNvmlDeviceGetHandleByIndex, uintptr(index), uintptr(unsafe.Pointer(&device)))
+
+	return device, err
+}
+
+func (d Device) Name() (string, error) {
+	var name [deviceNameBufferSize]byte
+
+	if err := call(procNvmlDeviceGetName, uintptr(d), uintptr(unsafe.Pointer(&name[0])), uintptr(len(name))); err != nil {
+		return "", err
+	}
+
+	return windows.ByteSliceToString(name[:]), nil
+}
+
+func (d Device) UUID() (string, error) {
+	var uuid [deviceUUIDBufferSize]byte
+
+	if err := call(procNvmlDeviceGetUUID, uintptr(d), uintptr(unsafe.Pointer(&uuid[0])), uintptr(len(uuid))); err != nil {
+		return "", err
+	}
+
+	return windows.ByteSliceToString(uuid[:]), nil
+}
+
+func (d Device) PciInfo() (PciInfo, error) {
+	var pciInfo PciInfo
+
+	err := call(procNvmlDeviceGetPciInfoV3, uintptr(d), uintptr(unsafe.Pointer(&pciInfo)))
+
+	return pciInfo, err
+}
+
+func (d Device) UtilizationRates() (Utilization, error) {
+	var utilization Utilization
+
+	err := call(procNvmlDeviceGetUtilization, uintptr(d), uintptr(unsafe.Pointer(&utilization)))
+
+	return utilization, err
+}
+
+// EncoderUtilization returns the utilization of the video encoder in percent.
+func (d Device) EncoderUtilization() (uint32, error) {
+	return d.samplingUtilization(procNvmlDeviceGetEncoderUtil)
+}
+
+// DecoderUtilization returns the utilization of the video decoder in percent.
+func (d Device) DecoderUtilization() (uint32, error) {
+	return d.samplingUtilization(procNvmlDeviceGetDecoderUtil)
+}
+
+func (d Device) samplingUtilization(proc *windows.LazyProc) (uint32, error) {
+	var utilization, samplingPeriod uint32
+
+	err := call(proc, uintptr(d), uintptr(unsafe.Pointer(&utilization)), uintptr(unsafe.Pointer(&samplingPeriod)))
+
+	return utilization, err
+}
+
+func (d Device) MemoryInfo() (Memory, error) {
+	var memory Memory
+
+	err := call(procNvmlDeviceGetMemoryInfo, uintptr(d), uintptr(unsafe.Pointer(&memory)))
+
+	return memory, err
+}
+
+// Temperature returns the temperature of the GPU die in degrees Celsius.
+func (d Device) Temperature() (uint32, error) {
+	var temperature uint32
+
+	err := call(procNvmlDeviceGetTemperature, uintptr(d), temperatureGPU, uintptr(unsafe.Pointer(&temperature)))
+
+	return temperature, err
+}
+
+// PowerUsage returns the power draw of the device in milliwatts.
+func (d Device) PowerUsage() (uint32, error) {
+	var power uint32
+
+	err := call(procNvmlDeviceGetPowerUsage, uintptr(d), uintptr(unsafe.Pointer(&power)))
+
+	return power, err
+}
+
+func call(proc *windows.LazyProc, args ...uintptr) error {
+	if err := proc.Find(); err != nil {
+		return err
+	}
+
+	ret, _, _ := proc.Call(args...)
+	if Return(ret) != SUCCESS {
+		return fmt.Errorf("%s failed: %w", proc.Name, Return(ret))
+	}
+
+	return nil
+}
@@ -0,0 +1,345 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package odbc32
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var (
+	mododbc32 = windows.NewLazySystemDLL("odbc32.dll")
+
+	procSQLAllocHandle     = mododbc32.NewProc("SQLAllocHandle")
+	procSQLFreeHandle      = mododbc32.NewProc("SQLFreeHandle")
+	procSQLSetEnvAttr      = mododbc32.NewProc("SQLSetEnvAttr")
+	procSQLSetConnectAttrW = mododbc32.NewProc("SQLSetConnectAttrW")
+	procSQLSetStmtAttrW    = mododbc32.NewProc("SQLSetStmtAttrW")
+	procSQLDriverConnectW  = mododbc32.NewProc("SQLDriverConnectW")
+	procSQLDisconnect      = mododbc32.NewProc("SQLDisconnect")
+	procSQLCancel          = mododbc32.NewProc("SQLCancel")
+	procSQLExecDirectW     = mododbc32.NewProc("SQLExecDirectW")
+	procSQLNumResultCols   = mododbc32.NewProc("SQLNumResultCols")
+	procSQLFetch           = mododbc32.NewProc("SQLFetch")
+	procSQLGetData         = mododbc32.NewProc("SQLGetData")
+	procSQLGetDiagRecW     = mododbc32.NewProc("SQLGetDiagRecW")
+)
+
+// SQLHANDLE is a handle to an ODBC environment, connection or statement.
+type SQLHANDLE uintptr
+
+// 📑 https://learn.microsoft.com/en-us/sql/odbc/reference/syntax/sqlallochandle-function
+const (
+	sqlHandleEnv  = 1
+	sqlHandleDbc  = 2
+	sqlHandleStmt = 3
+)
+
+const (
+	sqlSuccess         = 0
+	sqlSuccessWithInfo = 1
+	sqlNoData          = 100
+
+	sqlNTS            = -3
+	sqlNullData       = -1
+	sqlNoTotal        = -4
+	sqlDriverNoPrompt = 0
+
+	sqlAttrODBCVersion  = 200
+	sqlOVODBC3          = 3
+	sqlAttrLoginTimeout = 103
+	sqlAttrQueryTimeout = 0
+	sqlCWChar           = -8
+	sqlMaxMessageLength = 512
+	sqlStateLength      = 5
+	columnBufferLength  = 4096
+)
+
+// Query connects to the data source described by the connection string, runs the query and returns
+// all rows of the result set as strings. NULL values are returned as nil.
+// The timeout applies to the login and to the query execution. It is shortened to the deadline of ctx, and the
+// query is cancelled with SQLCancel once ctx is done.
+func Query(ctx context.Context, connectionString string, query string, timeout time.Duration) ([][]*string, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = min(timeout, time.Until(deadline))
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	env, err := allocHandle(sqlHandleEnv, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate environment handle: %w", err)
+	}
+
+	defer func() {
+		_ = freeHandle(sqlHandleEnv, env)
+	}()
+
+	ret, _, _ := procSQLSetEnvAttr.Call(uintptr(env), sqlAttrODBCVersion, sqlOVODBC3, 0)
+	if err := diagError(ret, sqlHandleEnv, env); err != nil {
+		return nil, fmt.Errorf("SQLSetEnvAttr failed: %w", err)
+	}
+
+	dbc, err := allocHandle(sqlHandleDbc, env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate connection handle: %w", err)
+	}
+
+	defer func() {
+		_ = freeHandle(sqlHandleDbc, dbc)
+	}()
+
+	timeoutSeconds := uintptr(max(timeout/time.Second, 1))
+
+	ret, _, _ = procSQLSetConnectAttrW.Call(uintptr(dbc), sqlAttrLoginTimeout, timeoutSeconds, 0)
+	if err := diagError(ret, sqlHandleDbc, dbc); err != nil {
+		return nil, fmt.Errorf("SQLSetConnectAttr failed: %w", err)
+	}
+
+	connectionStringPtr, err := windows.UTF16PtrFromString(connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, _, _ = procSQLDriverConnectW.Call(
+		uintptr(dbc),
+		0,
+		uintptr(unsafe.Pointer(connectionStringPtr)),
+		uintptr(sqlNTS&0xFFFF),
+		0,
+		0,
+		0,
+		sqlDriverNoPrompt,
+	)
+	if err := diagError(ret, sqlHandleDbc, dbc); err != nil {
+		return nil, fmt.Errorf("SQLDriverConnect failed: %w", err)
+	}
+
+	defer func() {
+		_, _, _ = procSQLDisconnect.Call(uintptr(dbc))
+	}()
+
+	stmt, err := allocHandle(sqlHandleStmt, dbc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate statement handle: %w", err)
+	}
+
+	defer func() {
+		_ = freeHandle(sqlHandleStmt, stmt)
+	}()
+
+	// SQLCancel cancels the SQLExecDirect, SQLFetch or SQLGetData call running on the statement.
+	// It is called before the statement is freed.
+	done := make(chan struct{})
+	cancelled := make(chan struct{})
+
+	go func() {
+		defer close(cancelled)
+
+		select {
+		case <-ctx.Done():
+			_, _, _ = procSQLCancel.Call(uintptr(stmt))
+		case <-done:
+		}
+	}()
+
+	defer func() {
+		close(done)
+		<-cancelled
+	}()
+
+	ret, _, _ = procSQLSetStmtAttrW.Call(uintptr(stmt), sqlAttrQueryTimeout, timeoutSeconds, 0)
+	if err := diagError(ret, sqlHandleStmt, stmt); err != nil {
+		return nil, fmt.Errorf("SQLSetStmtAttr failed: %w", err)
+	}
+
+	queryPtr, err := windows.UTF16PtrFromString(query)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, _, _ = procSQLExecDirectW.Call(uintptr(stmt), uintptr(unsafe.Pointer(queryPtr)), uintptr(sqlNTS&0xFFFFFFFF))
+	if err := diagError(ret, sqlHandleStmt, stmt); err != nil {
+		return nil, statementError(ctx, "SQLExecDirect", err)
+	}
+
+	var columns int16
+
+	ret, _, _ = procSQLNumResultCols.Call(uintptr(stmt), uintptr(unsafe.Pointer(&columns)))
+	if err := diagError(ret, sqlHandleStmt, stmt); err != nil {
+		return nil, fmt.Errorf("SQLNumResultCols failed: %w", err)
+	}
+
+	rows := make([][]*string, 0)
+	buf := make([]uint16, columnBufferLength)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		ret, _, _ = procSQLFetch.Call(uintptr(stmt))
+		if int16(ret) == sqlNoData {
+			return rows, nil
+		}
+
+		if err := diagError(ret, sqlHandleStmt, stmt); err != nil {
+			return nil, statementError(ctx, "SQLFetch", err)
+		}
+
+		row := make([]*string, columns)
+
+		for column := range row {
+			row[column], err = getData(stmt, column+1, buf)
+			if err != nil {
+				return nil, statementError(ctx, "SQLGetData", err)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+// getData returns the value of a column of the current row, or nil for NULL. Values that don't fit into buf are
+// returned in parts by successive SQLGetData calls, which return SQL_SUCCESS_WITH_INFO for all parts but the last
+// and SQL_NO_DATA once the value is complete.
+func getData(stmt SQLHANDLE, column int, buf []uint16) (*string, error) {
+	value := make([]uint16, 0, len(buf))
+
+	for {
+		// SQLLEN is pointer-sized.
+		var indicator int
+
+		ret, _, _ := procSQLGetData.Call(
+			uintptr(stmt),
+			uintptr(column),
+			uintptr(sqlCWChar&0xFFFF),
+			uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(len(buf)*2),
+			uintptr(unsafe.Pointer(&indicator)),
+		)
+		if int16(ret) == sqlNoData {
+			break
+		}
+
+		if err := diagError(ret, sqlHandleStmt, stmt); err != nil {
+			return nil, err
+		}
+
+		if indicator == sqlNullData {
+			return nil, nil //nolint:nilnil
+		}
+
+		// The indicator is the length of the remaining value in bytes, or SQL_NO_TOTAL if unknown.
+		// A part that doesn't fit fills buf up to the null terminator.
+		length := len(buf) - 1
+		if indicator != sqlNoTotal && indicator/2 < length {
+			length = indicator / 2
+		}
+
+		value = append(value, buf[:length]...)
+
+		if int16(ret) == sqlSuccess {
+			break
+		}
+	}
+
+	result := windows.UTF16ToString(value)
+
+	return &result, nil
+}
+
+// statementError returns the error of a failed call on the statement, or the error of ctx if SQLCancel cancelled the call.
+func statementError(ctx context.Context, call string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%s cancelled: %w", call, ctxErr)
+	}
+
+	return fmt.Errorf("%s failed: %w", call, err)
+}
+
+func allocHandle(handleType int16, inputHandle SQLHANDLE) (SQLHANDLE, error) {
+	var handle SQLHANDLE
+
+	ret, _, _ := procSQLAllocHandle.Call(uintptr(handleType), uintptr(inputHandle), uintptr(unsafe.Pointer(&handle)))
+	if int16(ret) != sqlSuccess && int16(ret) != sqlSuccessWithInfo {
+		return 0, fmt.Errorf("SQLAllocHandle failed with code %d", int16(ret))
+	}
+
+	return handle, nil
+}
+
+func freeHandle(handleType int16, handle SQLHANDLE) error {
+	ret, _, _ := procSQLFreeHandle.Call(uintptr(handleType), uintptr(handle))
+	if int16(ret) != sqlSuccess {
+		return fmt.Errorf("SQLFreeHandle failed with code %d", int16(ret))
+	}
+
+	return nil
+}
+
+// diagError converts the SQLRETURN value of an ODBC call into an error,
+// including the diagnostic records of the handle.
+func diagError(ret uintptr, handleType int16, handle SQLHANDLE) error {
+	if int16(ret) == sqlSuccess || int16(ret) == sqlSuccessWithInfo {
+		return nil
+	}
+
+	messages := make([]string, 0, 1)
+
+	state := make([]uint16, sqlStateLength+1)
+	message := make([]uint16, sqlMaxMessageLength)
+
+	for record := 1; ; record++ {
+		var (
+			nativeError   int32
+			messageLength int16
+		)
+
+		diagRet, _, _ := procSQLGetDiagRecW.Call(
+			uintptr(handleType),
+			uintptr(handle),
+			uintptr(record),
+			uintptr(unsafe.Pointer(&state[0])),
+			uintptr(unsafe.Pointer(&nativeError)),
+			uintptr(unsafe.Pointer(&message[0])),
+			uintptr(len(message)),
+			uintptr(unsafe.Pointer(&messageLength)),
+		)
+		if int16(diagRet) != sqlSuccess && int16(diagRet) != sqlSuccessWithInfo {
+			break
+		}
+
+		messages = append(messages, fmt.Sprintf("[%s] %s", windows.UTF16ToString(state), windows.UTF16ToString(message)))
+	}
+
+	if len(messages) == 0 {
+		return fmt.Errorf("ODBC call failed with code %d", int16(ret))
+	}
+
+	return errors.New(strings.Join(messages, "; "))
+}
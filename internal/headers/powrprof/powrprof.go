@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package powrprof
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var (
+	modpowrprof = windows.NewLazySystemDLL("powrprof.dll")
+
+	procCallNtPowerInformation = modpowrprof.NewProc("CallNtPowerInformation")
+)
+
+// processorInformation is the POWER_INFORMATION_LEVEL to retrieve PROCESSOR_POWER_INFORMATION.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/powerbase/nf-powerbase-callntpowerinformation
+const processorInformation = 11
+
+// ProcessorPowerInformation is a wrapper for PROCESSOR_POWER_INFORMATION.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/power/processor-power-information-str
+type ProcessorPowerInformation struct {
+	Number           uint32
+	MaxMhz           uint32
+	CurrentMhz       uint32
+	MhzLimit         uint32
+	MaxIdleState     uint32
+	CurrentIdleState uint32
+}
+
+// GetProcessorPowerInformation retrieves the power information of the logical processors in the processor group
+// of the calling thread. count must be the number of logical processors in the group.
+func GetProcessorPowerInformation(count int) ([]ProcessorPowerInformation, error) {
+	if count == 0 {
+		return nil, nil
+	}
+
+	info := make([]ProcessorPowerInformation, count)
+
+	ret, _, _ := procCallNtPowerInformation.Call(
+		processorInformation,
+		0,
+		0,
+		uintptr(unsafe.Pointer(&info[0])),
+		uintptr(len(info))*unsafe.Sizeof(info[0]),
+	)
+	if ret != 0 {
+		return nil, fmt.Errorf("CallNtPowerInformation failed: %w", windows.NTStatus(ret))
+	}
+
+	return info, nil
+}
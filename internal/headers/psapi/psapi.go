@@ -42,10 +42,27 @@ type PerformanceInformation struct {
 	ThreadCount       uint32
 }
 
+// ProcessMemoryCountersEx is a wrapper of the PROCESS_MEMORY_COUNTERS_EX struct.
+// https://learn.microsoft.com/en-us/windows/win32/api/psapi/ns-psapi-process_memory_counters_ex
+type ProcessMemoryCountersEx struct {
+	cb                         uint32
+	PageFaultCount             uint32
+	PeakWorkingSetSize         uint
+	WorkingSetSize             uint
+	QuotaPeakPagedPoolUsage    uint
+	QuotaPagedPoolUsage        uint
+	QuotaPeakNonPagedPoolUsage uint
+	QuotaNonPagedPoolUsage     uint
+	PagefileUsage              uint
+	PeakPagefileUsage          uint
+	PrivateUsage               uint
+}
+
 //nolint:gochecknoglobals
 var (
-	psapi                  = windows.NewLazySystemDLL("psapi.dll")
-	procGetPerformanceInfo = psapi.NewProc("GetPerformanceInfo")
+	psapi                    = windows.NewLazySystemDLL("psapi.dll")
+	procGetPerformanceInfo   = psapi.NewProc("GetPerformanceInfo")
+	procGetProcessMemoryInfo = psapi.NewProc("GetProcessMemoryInfo")
 )
 
 // GetPerformanceInfo returns the dereferenced version of GetLPPerformanceInfo.
@@ -62,3 +79,19 @@ func GetPerformanceInfo() (PerformanceInformation, error) {
 
 	return lppi, nil
 }
+
+// GetProcessMemoryInfo returns the memory usage of the process. The handle requires PROCESS_QUERY_LIMITED_INFORMATION.
+// https://learn.microsoft.com/en-us/windows/win32/api/psapi/nf-psapi-getprocessmemoryinfo
+func GetProcessMemoryInfo(process windows.Handle) (ProcessMemoryCountersEx, error) {
+	var counters ProcessMemoryCountersEx
+
+	size := (uint32)(unsafe.Sizeof(counters))
+	counters.cb = size
+	r1, _, err := procGetProcessMemoryInfo.Call(uintptr(process), uintptr(unsafe.Pointer(&counters)), uintptr(size))
+
+	if r1 == 0 {
+		return ProcessMemoryCountersEx{}, err
+	}
+
+	return counters, nil
+}
@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package virtdisk
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var (
+	modvirtdisk = windows.NewLazySystemDLL("virtdisk.dll")
+
+	procOpenVirtualDisk           = modvirtdisk.NewProc("OpenVirtualDisk")
+	procGetVirtualDiskInformation = modvirtdisk.NewProc("GetVirtualDiskInformation")
+)
+
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/virtdisk/ne-virtdisk-get_virtual_disk_info_version
+const (
+	getVirtualDiskInfoParentLocation  = 3
+	getVirtualDiskInfoProviderSubtype = 7
+)
+
+// ProviderSubtype is the type of a virtual disk.
+//
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/virtdisk/ns-virtdisk-get_virtual_disk_info
+type ProviderSubtype uint32
+
+const (
+	ProviderSubtypeFixed        ProviderSubtype = 2
+	ProviderSubtypeDynamic      ProviderSubtype = 3
+	ProviderSubtypeDifferencing ProviderSubtype = 4
+)
+
+const (
+	openVirtualDiskVersion2      = 2
+	openVirtualDiskFlagNoParents = 0x1
+	virtualDiskAccessNone        = 0
+
+	// infoHeaderSize is the offset of the union within GET_VIRTUAL_DISK_INFO.
+	// The union is 8-byte aligned because of the ULONGLONG members of the Size member.
+	infoHeaderSize = 8
+
+	parentLocationBufferSize = 4096
+)
+
+// VIRTUAL_STORAGE_TYPE
+//
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/virtdisk/ns-virtdisk-virtual_storage_type
+type VIRTUAL_STORAGE_TYPE struct {
+	DeviceId uint32
+	VendorId windows.GUID
+}
+
+// OPEN_VIRTUAL_DISK_PARAMETERS with the Version2 member of the union.
+// The trailing padding covers the larger Version3 member.
+//
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/virtdisk/ns-virtdisk-open_virtual_disk_parameters
+type OPEN_VIRTUAL_DISK_PARAMETERS struct {
+	Version        uint32
+	GetInfoOnly    int32
+	ReadOnly       int32
+	ResiliencyGuid windows.GUID
+	_              [16]byte
+}
+
+// OpenVirtualDisk opens the VHD or VHDX file at path for reading its metadata.
+// The disk is opened without its parents, so disks attached to running virtual machines can be opened as well.
+func OpenVirtualDisk(path string) (windows.Handle, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+
+	// A zero DeviceId and VendorId let the system detect the disk format.
+	storageType := VIRTUAL_STORAGE_TYPE{}
+	parameters := OPEN_VIRTUAL_DISK_PARAMETERS{
+		Version:     openVirtualDiskVersion2,
+		GetInfoOnly: 1,
+	}
+
+	var handle windows.Handle
+
+	ret, _, _ := procOpenVirtualDisk.Call(
+		uintptr(unsafe.Pointer(&storageType)),
+		uintptr(unsafe.Pointer(pathPtr)),
+		virtualDiskAccessNone,
+		openVirtualDiskFlagNoParents,
+		uintptr(unsafe.Pointer(&parameters)),
+		uintptr(unsafe.Pointer(&handle)),
+	)
+	if ret != 0 {
+		return 0, windows.Errno(ret)
+	}
+
+	return handle, nil
+}
+
+// GetProviderSubtype returns whether the virtual disk is a fixed, dynamic or differencing disk.
+func GetProviderSubtype(handle windows.Handle) (ProviderSubtype, error) {
+	buf := make([]byte, infoHeaderSize+8)
+
+	if err := getVirtualDiskInformation(handle, getVirtualDiskInfoProviderSubtype, &buf); err != nil {
+		return 0, err
+	}
+
+	return ProviderSubtype(*(*uint32)(unsafe.Pointer(&buf[infoHeaderSize]))), nil
+}
+
+// GetParentLocations returns the possible paths of the parent of a differencing disk.
+// Since the disk is opened without its parents, the parent is unresolved and the
+// relative and absolute paths stored in the disk are returned.
+func GetParentLocations(handle windows.Handle) ([]string, error) {
+	buf := make([]byte, infoHeaderSize+parentLocationBufferSize)
+
+	if err := getVirtualDiskInformation(handle, getVirtualDiskInfoParentLocation, &buf); err != nil {
+		return nil, err
+	}
+
+	// ParentResolved (BOOL) is followed by the ParentLocationBuffer, a list of
+	// null-terminated strings terminated by an empty string.
+	locationBuffer := unsafe.Slice(
+		(*uint16)(unsafe.Pointer(&buf[infoHeaderSize+4])),
+		(len(buf)-infoHeaderSize-4)/2,
+	)
+
+	locations := make([]string, 0, 2)
+
+	for len(locationBuffer) > 0 && locationBuffer[0] != 0 {
+		location := windows.UTF16ToString(locationBuffer)
+		locations = append(locations, location)
+
+		locationBuffer = locationBuffer[min(len(windows.StringToUTF16(location)), len(locationBuffer)):]
+	}
+
+	return locations, nil
+}
+
+func getVirtualDiskInformation(handle windows.Handle, version uint32, buf *[]byte) error {
+	for range 2 {
+		*(*uint32)(unsafe.Pointer(&(*buf)[0])) = version
+
+		size := uint32(len(*buf))
+
+		ret, _, _ := procGetVirtualDiskInformation.Call(
+			uintptr(handle),
+			uintptr(unsafe.Pointer(&size)),
+			uintptr(unsafe.Pointer(&(*buf)[0])),
+			0,
+		)
+
+		switch {
+		case ret == 0:
+			return nil
+		case errors.Is(windows.Errno(ret), windows.ERROR_INSUFFICIENT_BUFFER) && size > uint32(len(*buf)):
+			*buf = make([]byte, size)
+		default:
+			return windows.Errno(ret)
+		}
+	}
+
+	return fmt.Errorf("GetVirtualDiskInformation: %w", windows.ERROR_INSUFFICIENT_BUFFER)
+}
@@ -0,0 +1,401 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package wevtapi
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var (
+	modwevtapi = windows.NewLazySystemDLL("wevtapi.dll")
+
+	procEvtQuery               = modwevtapi.NewProc("EvtQuery")
+	procEvtNext                = modwevtapi.NewProc("EvtNext")
+	procEvtClose               = modwevtapi.NewProc("EvtClose")
+	procEvtCreateRenderContext = modwevtapi.NewProc("EvtCreateRenderContext")
+	procEvtRender              = modwevtapi.NewProc("EvtRender")
+)
+
+// EVT_HANDLE is a handle to an event log object.
+type EVT_HANDLE windows.Handle
+
+// EVT_QUERY_FLAGS
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winevt/ne-winevt-evt_query_flags
+const (
+	EvtQueryChannelPath      = 0x1
+	EvtQueryFilePath         = 0x2
+	EvtQueryForwardDirection = 0x100
+	EvtQueryReverseDirection = 0x200
+)
+
+const (
+	// evtRenderContextValues renders the values specified by XPath expressions.
+	// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winevt/ne-winevt-evt_render_context_flags
+	evtRenderContextValues = 0
+	// evtRenderContextUser renders the user data or event data properties of the event.
+	evtRenderContextUser = 2
+	// evtRenderEventValues renders the event properties specified in the rendering context.
+	// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winevt/ne-winevt-evt_render_flags
+	evtRenderEventValues = 0
+)
+
+// EVT_VARIANT_TYPE
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winevt/ne-winevt-evt_variant_type
+const (
+	evtVarTypeNull     = 0
+	evtVarTypeString   = 1
+	evtVarTypeSByte    = 3
+	evtVarTypeByte     = 4
+	evtVarTypeInt16    = 5
+	evtVarTypeUInt16   = 6
+	evtVarTypeInt32    = 7
+	evtVarTypeUInt32   = 8
+	evtVarTypeInt64    = 9
+	evtVarTypeUInt64   = 10
+	evtVarTypeBoolean  = 13
+	evtVarTypeFileTime = 17
+	evtVarTypeHexInt32 = 20
+	evtVarTypeHexInt64 = 21
+)
+
+// eventBatchSize is the number of events retrieved per EvtNext call by QueryValues.
+const eventBatchSize = 64
+
+// ERROR_EVT_CHANNEL_NOT_FOUND is returned if the specified channel could not be found.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/wes/windows-event-log-error-constants
+const ERROR_EVT_CHANNEL_NOT_FOUND windows.Errno = 15007
+
+// evtVariant is the EVT_VARIANT structure. The union is represented by an
+// unsafe.Pointer, numeric values are read from its memory.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winevt/ns-winevt-evt_variant
+type evtVariant struct {
+	Value unsafe.Pointer
+	Count uint32
+	Type  uint32
+}
+
+// EvtQuery runs a structured XPath query against the given channel.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winevt/nf-winevt-evtquery
+func EvtQuery(path string, query string, flags uint32) (EVT_HANDLE, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+
+	queryPtr, err := windows.UTF16PtrFromString(query)
+	if err != nil {
+		return 0, err
+	}
+
+	r1, _, err := procEvtQuery.Call(
+		0,
+		uintptr(unsafe.Pointer(pathPtr)),
+		uintptr(unsafe.Pointer(queryPtr)),
+		uintptr(flags),
+	)
+	if r1 == 0 {
+		return 0, err
+	}
+
+	return EVT_HANDLE(r1), nil
+}
+
+// EvtNext retrieves the next events of a query result set into events.
+// It returns the number of retrieved events, and windows.ERROR_NO_MORE_ITEMS
+// once the result set is exhausted.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winevt/nf-winevt-evtnext
+func EvtNext(resultSet EVT_HANDLE, events []EVT_HANDLE, timeout uint32) (uint32, error) {
+	if len(events) == 0 {
+		return 0, errors.New("events must not be empty")
+	}
+
+	var returned uint32
+
+	r1, _, err := procEvtNext.Call(
+		uintptr(resultSet),
+		uintptr(len(events)),
+		uintptr(unsafe.Pointer(&events[0])),
+		uintptr(timeout),
+		0,
+		uintptr(unsafe.Pointer(&returned)),
+	)
+	if r1 == 0 {
+		return 0, err
+	}
+
+	return returned, nil
+}
+
+// EvtClose closes an open event log handle.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winevt/nf-winevt-evtclose
+func EvtClose(handle EVT_HANDLE) error {
+	r1, _, err := procEvtClose.Call(uintptr(handle))
+	if r1 == 0 {
+		return err
+	}
+
+	return nil
+}
+
+// EvtCreateRenderContext creates a context to render the values of the given
+// XPath expressions, e.g. "Event/System/EventRecordID".
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winevt/nf-winevt-evtcreaterendercontext
+func EvtCreateRenderContext(valuePaths []string) (EVT_HANDLE, error) {
+	if len(valuePaths) == 0 {
+		return 0, errors.New("valuePaths must not be empty")
+	}
+
+	valuePathPtrs := make([]*uint16, len(valuePaths))
+
+	for i, valuePath := range valuePaths {
+		ptr, err := windows.UTF16PtrFromString(valuePath)
+		if err != nil {
+			return 0, err
+		}
+
+		valuePathPtrs[i] = ptr
+	}
+
+	r1, _, err := procEvtCreateRenderContext.Call(
+		uintptr(len(valuePathPtrs)),
+		uintptr(unsafe.Pointer(&valuePathPtrs[0])),
+		evtRenderContextValues,
+	)
+	if r1 == 0 {
+		return 0, err
+	}
+
+	return EVT_HANDLE(r1), nil
+}
+
+// EvtCreateUserRenderContext creates a context to render the event data properties of an event in the order
+// of the event template, e.g. the unnamed Data elements of events logged by classic event sources.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winevt/nf-winevt-evtcreaterendercontext
+func EvtCreateUserRenderContext() (EVT_HANDLE, error) {
+	r1, _, err := procEvtCreateRenderContext.Call(
+		0,
+		0,
+		evtRenderContextUser,
+	)
+	if r1 == 0 {
+		return 0, err
+	}
+
+	return EVT_HANDLE(r1), nil
+}
+
+// EvtRenderValues renders the values of the render context for the given event.
+// Strings are returned as string, numeric values as uint64 or int64, and
+// missing values as nil. Other value types are not supported and returned as nil.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winevt/nf-winevt-evtrender
+func EvtRenderValues(context EVT_HANDLE, event EVT_HANDLE) ([]any, error) {
+	var (
+		bufferUsed    uint32
+		propertyCount uint32
+	)
+
+	r1, _, err := procEvtRender.Call(
+		uintptr(context),
+		uintptr(event),
+		evtRenderEventValues,
+		0,
+		0,
+		uintptr(unsafe.Pointer(&bufferUsed)),
+		uintptr(unsafe.Pointer(&propertyCount)),
+	)
+	if r1 == 0 && !errors.Is(err, windows.ERROR_INSUFFICIENT_BUFFER) {
+		return nil, err
+	}
+
+	if bufferUsed == 0 {
+		return nil, nil
+	}
+
+	// Allocate an uint64 slice to guarantee the alignment of the EVT_VARIANT structures.
+	buffer := make([]uint64, (bufferUsed+7)/8)
+
+	r1, _, err = procEvtRender.Call(
+		uintptr(context),
+		uintptr(event),
+		evtRenderEventValues,
+		uintptr(len(buffer)*8),
+		uintptr(unsafe.Pointer(&buffer[0])),
+		uintptr(unsafe.Pointer(&bufferUsed)),
+		uintptr(unsafe.Pointer(&propertyCount)),
+	)
+	if r1 == 0 {
+		return nil, err
+	}
+
+	variants := unsafe.Slice((*evtVariant)(unsafe.Pointer(&buffer[0])), propertyCount)
+	values := make([]any, propertyCount)
+
+	for i, variant := range variants {
+		values[i] = variant.value()
+	}
+
+	return values, nil
+}
+
+func (v *evtVariant) value() any {
+	raw := unsafe.Pointer(&v.Value)
+
+	switch v.Type {
+	case evtVarTypeString:
+		if v.Value == nil {
+			return ""
+		}
+
+		return windows.UTF16PtrToString((*uint16)(v.Value))
+	case evtVarTypeByte, evtVarTypeBoolean:
+		return uint64(*(*uint8)(raw))
+	case evtVarTypeUInt16:
+		return uint64(*(*uint16)(raw))
+	case evtVarTypeUInt32, evtVarTypeHexInt32:
+		return uint64(*(*uint32)(raw))
+	case evtVarTypeUInt64, evtVarTypeHexInt64, evtVarTypeFileTime:
+		return *(*uint64)(raw)
+	case evtVarTypeSByte:
+		return int64(*(*int8)(raw))
+	case evtVarTypeInt16:
+		return int64(*(*int16)(raw))
+	case evtVarTypeInt32:
+		return int64(*(*int32)(raw))
+	case evtVarTypeInt64:
+		return *(*int64)(raw)
+	case evtVarTypeNull:
+		return nil
+	default:
+		return nil
+	}
+}
+
+// QueryValues runs a structured XPath query against the given channel in forward direction
+// and calls fn with the rendered values of the render context for each matching event.
+func QueryValues(channel string, query string, renderContext EVT_HANDLE, fn func(values []any)) error {
+	resultSet, err := EvtQuery(channel, query, EvtQueryChannelPath|EvtQueryForwardDirection)
+	if err != nil {
+		return fmt.Errorf("failed to query events: %w", err)
+	}
+
+	defer func() {
+		_ = EvtClose(resultSet)
+	}()
+
+	events := make([]EVT_HANDLE, eventBatchSize)
+
+	for {
+		returned, err := EvtNext(resultSet, events, windows.INFINITE)
+		if err != nil {
+			if errors.Is(err, windows.ERROR_NO_MORE_ITEMS) {
+				return nil
+			}
+
+			return fmt.Errorf("failed to retrieve events: %w", err)
+		}
+
+		if err := renderValues(renderContext, events[:returned], fn); err != nil {
+			return err
+		}
+	}
+}
+
+// renderValues calls fn with the rendered values of each event of a batch returned by EvtNext.
+// All events of the batch are closed, including the remaining ones if rendering an event fails.
+func renderValues(renderContext EVT_HANDLE, events []EVT_HANDLE, fn func(values []any)) error {
+	defer func() {
+		for _, event := range events {
+			_ = EvtClose(event)
+		}
+	}()
+
+	for _, event := range events {
+		values, err := EvtRenderValues(renderContext, event)
+		if err != nil {
+			return fmt.Errorf("failed to render event: %w", err)
+		}
+
+		fn(values)
+	}
+
+	return nil
+}
+
+// LatestEventValues returns the rendered values of the render context for the newest event matching the
+// structured XPath query, or nil if no event matches.
+func LatestEventValues(channel string, query string, renderContext EVT_HANDLE) ([]any, error) {
+	resultSet, err := EvtQuery(channel, query, EvtQueryChannelPath|EvtQueryReverseDirection)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = EvtClose(resultSet)
+	}()
+
+	events := make([]EVT_HANDLE, 1)
+
+	returned, err := EvtNext(resultSet, events, windows.INFINITE)
+	if err != nil {
+		if errors.Is(err, windows.ERROR_NO_MORE_ITEMS) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if returned == 0 {
+		return nil, nil
+	}
+
+	defer func() {
+		_ = EvtClose(events[0])
+	}()
+
+	return EvtRenderValues(renderContext, events[0])
+}
+
+// LatestEventRecordID returns the record ID of the newest event in the channel, or 0 if the channel is empty.
+func LatestEventRecordID(channel string) (uint64, error) {
+	renderContext, err := EvtCreateRenderContext([]string{"Event/System/EventRecordID"})
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		_ = EvtClose(renderContext)
+	}()
+
+	values, err := LatestEventValues(channel, "*", renderContext)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	recordID, _ := values[0].(uint64)
+
+	return recordID, nil
+}
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package wintrust
+
+import "golang.org/x/sys/windows"
+
+// catalogInfo is a wrapper of the CATALOG_INFO struct.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/mscat/ns-mscat-catalog_info
+type catalogInfo struct {
+	size        uint32
+	catalogFile [windows.MAX_PATH]uint16
+}
+
+// wintrustCatalogInfo is a wrapper of the WINTRUST_CATALOG_INFO struct.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/wintrust/ns-wintrust-wintrust_catalog_info
+type wintrustCatalogInfo struct {
+	size                   uint32
+	catalogVersion         uint32
+	catalogFilePath        *uint16
+	memberTag              *uint16
+	memberFilePath         *uint16
+	memberFile             windows.Handle
+	calculatedFileHash     *byte
+	calculatedFileHashSize uint32
+	catalogContext         uintptr
+	catAdmin               windows.Handle
+}
+
+// cryptProviderCert is the head of the CRYPT_PROVIDER_CERT struct.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/wintrust/ns-wintrust-crypt_provider_cert
+type cryptProviderCert struct {
+	size uint32
+	cert *windows.CertContext
+}
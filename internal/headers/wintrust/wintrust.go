@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package wintrust
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var (
+	modwintrust = windows.NewLazySystemDLL("wintrust.dll")
+
+	procCryptCATAdminAcquireContext2         = modwintrust.NewProc("CryptCATAdminAcquireContext2")
+	procCryptCATAdminReleaseContext          = modwintrust.NewProc("CryptCATAdminReleaseContext")
+	procCryptCATAdminCalcHashFromFileHandle2 = modwintrust.NewProc("CryptCATAdminCalcHashFromFileHandle2")
+	procCryptCATAdminEnumCatalogFromHash     = modwintrust.NewProc("CryptCATAdminEnumCatalogFromHash")
+	procCryptCATAdminReleaseCatalogContext   = modwintrust.NewProc("CryptCATAdminReleaseCatalogContext")
+	procCryptCATCatalogInfoFromContext       = modwintrust.NewProc("CryptCATCatalogInfoFromContext")
+	procWTHelperProvDataFromStateData        = modwintrust.NewProc("WTHelperProvDataFromStateData")
+	procWTHelperGetProvSignerFromChain       = modwintrust.NewProc("WTHelperGetProvSignerFromChain")
+	procWTHelperGetProvCertFromChain         = modwintrust.NewProc("WTHelperGetProvCertFromChain")
+)
+
+// catalogHashAlgorithms are the hash algorithms of the catalogs, SHA1 for catalogs before Windows 8.
+//
+//nolint:gochecknoglobals
+var catalogHashAlgorithms = []string{"SHA256", "SHA1"}
+
+var errNoSignature = windows.Errno(windows.TRUST_E_NOSIGNATURE)
+
+// VerifyFile verifies the Authenticode signature of the file, which is either embedded in the file or,
+// like for most binaries of Windows, in a catalog of the system. It returns the simple display name of the
+// signing certificate, which is also returned if the signature is not trusted, e.g. because the certificate expired.
+// The error is nil if the signature is trusted, otherwise the [windows.Errno] of WinVerifyTrust,
+// e.g. TRUST_E_NOSIGNATURE if the file is not signed. Revocation is not checked, since it requires network access.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/wintrust/nf-wintrust-winverifytrust
+func VerifyFile(path string) (string, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := windows.CreateFile(
+		pathPtr,
+		windows.GENERIC_READ,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil,
+		windows.OPEN_EXISTING,
+		windows.FILE_ATTRIBUTE_NORMAL,
+		0,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		_ = windows.CloseHandle(file)
+	}()
+
+	publisher, err := verify(windows.WTD_CHOICE_FILE, unsafe.Pointer(&windows.WinTrustFileInfo{
+		Size:     uint32(unsafe.Sizeof(windows.WinTrustFileInfo{})),
+		FilePath: pathPtr,
+		File:     file,
+	}))
+	if !errors.Is(err, errNoSignature) {
+		return publisher, err
+	}
+
+	for _, algorithm := range catalogHashAlgorithms {
+		publisher, err = verifyCatalogMember(pathPtr, file, algorithm)
+		if !errors.Is(err, errNoSignature) {
+			return publisher, err
+		}
+	}
+
+	return "", errNoSignature
+}
+
+// verifyCatalogMember verifies the signature of the catalog the file is a member of.
+// It returns TRUST_E_NOSIGNATURE if the hash of the file is in no catalog.
+func verifyCatalogMember(pathPtr *uint16, file windows.Handle, algorithm string) (string, error) {
+	algorithmPtr, err := windows.UTF16PtrFromString(algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	var catAdmin windows.Handle
+
+	r1, _, err := procCryptCATAdminAcquireContext2.Call(
+		uintptr(unsafe.Pointer(&catAdmin)),
+		0,
+		uintptr(unsafe.Pointer(algorithmPtr)),
+		0,
+		0,
+	)
+	if r1 == 0 {
+		return "", err
+	}
+
+	defer func() {
+		_, _, _ = procCryptCATAdminReleaseContext.Call(uintptr(catAdmin), 0)
+	}()
+
+	// Large enough for SHA256 and SHA1 hashes.
+	hash := make([]byte, 32)
+	hashSize := uint32(len(hash))
+
+	r1, _, err = procCryptCATAdminCalcHashFromFileHandle2.Call(
+		uintptr(catAdmin),
+		uintptr(file),
+		uintptr(unsafe.Pointer(&hashSize)),
+		uintptr(unsafe.Pointer(&hash[0])),
+		0,
+	)
+	if r1 == 0 {
+		return "", err
+	}
+
+	catInfo, _, _ := procCryptCATAdminEnumCatalogFromHash.Call(
+		uintptr(catAdmin),
+		uintptr(unsafe.Pointer(&hash[0])),
+		uintptr(hashSize),
+		0,
+		0,
+	)
+	if catInfo == 0 {
+		return "", errNoSignature
+	}
+
+	defer func() {
+		_, _, _ = procCryptCATAdminReleaseCatalogContext.Call(uintptr(catAdmin), catInfo, 0)
+	}()
+
+	info := catalogInfo{size: uint32(unsafe.Sizeof(catalogInfo{}))}
+
+	r1, _, err = procCryptCATCatalogInfoFromContext.Call(catInfo, uintptr(unsafe.Pointer(&info)), 0)
+	if r1 == 0 {
+		return "", err
+	}
+
+	// The catalog members are tagged with the hex encoded hash of the file.
+	memberTag, err := windows.UTF16PtrFromString(strings.ToUpper(hex.EncodeToString(hash[:hashSize])))
+	if err != nil {
+		return "", err
+	}
+
+	return verify(windows.WTD_CHOICE_CATALOG, unsafe.Pointer(&wintrustCatalogInfo{
+		size:                   uint32(unsafe.Sizeof(wintrustCatalogInfo{})),
+		catalogFilePath:        &info.catalogFile[0],
+		memberTag:              memberTag,
+		memberFilePath:         pathPtr,
+		memberFile:             file,
+		calculatedFileHash:     &hash[0],
+		calculatedFileHashSize: hashSize,
+		catAdmin:               catAdmin,
+	}))
+}
+
+// verify calls WinVerifyTrust for the file or catalog member and returns the signer of the signature.
+func verify(unionChoice uint32, info unsafe.Pointer) (string, error) {
+	data := &windows.WinTrustData{
+		Size:                            uint32(unsafe.Sizeof(windows.WinTrustData{})),
+		UIChoice:                        windows.WTD_UI_NONE,
+		RevocationChecks:                windows.WTD_REVOKE_NONE,
+		UnionChoice:                     unionChoice,
+		FileOrCatalogOrBlobOrSgnrOrCert: info,
+		StateAction:                     windows.WTD_STATEACTION_VERIFY,
+		ProvFlags:                       windows.WTD_CACHE_ONLY_URL_RETRIEVAL,
+	}
+
+	err := windows.WinVerifyTrustEx(windows.InvalidHWND, &windows.WINTRUST_ACTION_GENERIC_VERIFY_V2, data)
+
+	var publisher string
+	if data.StateData != 0 {
+		publisher = signerName(data.StateData)
+	}
+
+	data.StateAction = windows.WTD_STATEACTION_CLOSE
+	_ = windows.WinVerifyTrustEx(windows.InvalidHWND, &windows.WINTRUST_ACTION_GENERIC_VERIFY_V2, data)
+
+	return publisher, err
+}
+
+// signerName returns the simple display name of the certificate of the first signer of the verification state,
+// or an empty string if the signature has no signer.
+func signerName(stateData windows.Handle) string {
+	provData, _, _ := procWTHelperProvDataFromStateData.Call(uintptr(stateData))
+	if provData == 0 {
+		return ""
+	}
+
+	signer, _, _ := procWTHelperGetProvSignerFromChain.Call(provData, 0, 0, 0)
+	if signer == 0 {
+		return ""
+	}
+
+	r1, _, _ := procWTHelperGetProvCertFromChain.Call(signer, 0)
+	if r1 == 0 {
+		return ""
+	}
+
+	providerCert := *(**cryptProviderCert)(unsafe.Pointer(&r1))
+	if providerCert.cert == nil {
+		return ""
+	}
+
+	name := make([]uint16, 256)
+	size := windows.CertGetNameString(providerCert.cert, windows.CERT_NAME_SIMPLE_DISPLAY_TYPE, 0, nil, &name[0], uint32(len(name)))
+
+	if size <= 1 {
+		return ""
+	}
+
+	return windows.UTF16ToString(name[:size])
+}
@@ -0,0 +1,303 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package wldap32
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Scopes of the searches.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winldap/nf-winldap-ldap_search_sw
+const (
+	LDAP_SCOPE_BASE     = 0x00
+	LDAP_SCOPE_ONELEVEL = 0x01
+	LDAP_SCOPE_SUBTREE  = 0x02
+)
+
+const (
+	ldapPort = 389
+
+	ldapOptReferrals       = 0x08
+	ldapOptProtocolVersion = 0x11
+	ldapVersion3           = 3
+
+	ldapAuthNegotiate = 0x0486
+
+	ldapSuccess           = 0x00
+	ldapNoResultsReturned = 0x5e
+
+	// pageSize is the number of entries of a page of a paged search. Active Directory returns at most
+	// MaxPageSize entries per page, 1000 by default.
+	pageSize = 1000
+
+	// noAttributes requests no attributes of the entries, e.g. to count them.
+	noAttributes = "1.1"
+)
+
+//nolint:gochecknoglobals
+var (
+	modwldap32 = windows.NewLazySystemDLL("wldap32.dll")
+
+	procLdapInitW             = modwldap32.NewProc("ldap_initW")
+	procLdapSetOptionW        = modwldap32.NewProc("ldap_set_optionW")
+	procLdapConnect           = modwldap32.NewProc("ldap_connect")
+	procLdapBindSW            = modwldap32.NewProc("ldap_bind_sW")
+	procLdapUnbind            = modwldap32.NewProc("ldap_unbind")
+	procLdapSearchSW          = modwldap32.NewProc("ldap_search_sW")
+	procLdapSearchInitPageW   = modwldap32.NewProc("ldap_search_init_pageW")
+	procLdapGetNextPageS      = modwldap32.NewProc("ldap_get_next_page_s")
+	procLdapSearchAbandonPage = modwldap32.NewProc("ldap_search_abandon_page")
+	procLdapCountEntries      = modwldap32.NewProc("ldap_count_entries")
+	procLdapFirstEntry        = modwldap32.NewProc("ldap_first_entry")
+	procLdapGetValuesW        = modwldap32.NewProc("ldap_get_valuesW")
+	procLdapValueFreeW        = modwldap32.NewProc("ldap_value_freeW")
+	procLdapMsgfree           = modwldap32.NewProc("ldap_msgfree")
+	procLdapErr2stringW       = modwldap32.NewProc("ldap_err2stringW")
+	procLdapGetLastError      = modwldap32.NewProc("LdapGetLastError")
+)
+
+// Error is an LDAP result code.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/ldap/return-values
+type Error uint32
+
+func (e Error) Error() string {
+	r1, _, _ := procLdapErr2stringW.Call(uintptr(e))
+	if r1 == 0 {
+		return fmt.Sprintf("LDAP error 0x%x", uint32(e))
+	}
+
+	return fmt.Sprintf("%s (0x%x)", windows.UTF16PtrToString(*(**uint16)(unsafe.Pointer(&r1))), uint32(e))
+}
+
+// Conn is a connection to an LDAP server. It must not be used concurrently.
+type Conn struct {
+	ld uintptr
+}
+
+// Connect connects to the LDAP server on the host and binds with the credentials of the process,
+// negotiating Kerberos or NTLM.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winldap/nf-winldap-ldap_initw
+func Connect(host string) (*Conn, error) {
+	hostPtr, err := windows.UTF16PtrFromString(host)
+	if err != nil {
+		return nil, err
+	}
+
+	ld, _, _ := procLdapInitW.Call(uintptr(unsafe.Pointer(hostPtr)), ldapPort)
+	if ld == 0 {
+		r1, _, _ := procLdapGetLastError.Call()
+
+		return nil, fmt.Errorf("ldap_init failed: %w", Error(r1))
+	}
+
+	conn := &Conn{ld: ld}
+
+	if err := conn.connect(); err != nil {
+		_ = conn.Close()
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (c *Conn) connect() error {
+	version := uint32(ldapVersion3)
+
+	if r1, _, _ := procLdapSetOptionW.Call(c.ld, ldapOptProtocolVersion, uintptr(unsafe.Pointer(&version))); r1 != ldapSuccess {
+		return fmt.Errorf("ldap_set_option LDAP_OPT_PROTOCOL_VERSION failed: %w", Error(r1))
+	}
+
+	// Referrals to other domains of the forest are not followed. LDAP_OPT_OFF is a NULL pointer.
+	if r1, _, _ := procLdapSetOptionW.Call(c.ld, ldapOptReferrals, 0); r1 != ldapSuccess {
+		return fmt.Errorf("ldap_set_option LDAP_OPT_REFERRALS failed: %w", Error(r1))
+	}
+
+	if r1, _, _ := procLdapConnect.Call(c.ld, 0); r1 != ldapSuccess {
+		return fmt.Errorf("ldap_connect failed: %w", Error(r1))
+	}
+
+	if r1, _, _ := procLdapBindSW.Call(c.ld, 0, 0, ldapAuthNegotiate); r1 != ldapSuccess {
+		return fmt.Errorf("ldap_bind_s failed: %w", Error(r1))
+	}
+
+	return nil
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	if c.ld == 0 {
+		return nil
+	}
+
+	r1, _, _ := procLdapUnbind.Call(c.ld)
+	c.ld = 0
+
+	if r1 != ldapSuccess {
+		return fmt.Errorf("ldap_unbind failed: %w", Error(r1))
+	}
+
+	return nil
+}
+
+// Values returns the values of the attribute of the first entry matching the filter.
+// It returns nil if no entry matches or the entry has no values of the attribute.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winldap/nf-winldap-ldap_get_valuesw
+func (c *Conn) Values(baseDN string, scope uint32, filter, attribute string) ([]string, error) {
+	baseDNPtr, filterPtr, err := searchParameters(baseDN, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	attributePtr, err := windows.UTF16PtrFromString(attribute)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := []*uint16{attributePtr, nil}
+
+	var res uintptr
+
+	r1, _, _ := procLdapSearchSW.Call(
+		c.ld,
+		uintptr(unsafe.Pointer(baseDNPtr)),
+		uintptr(scope),
+		uintptr(unsafe.Pointer(filterPtr)),
+		uintptr(unsafe.Pointer(&attributes[0])),
+		0,
+		uintptr(unsafe.Pointer(&res)),
+	)
+	if res != 0 {
+		defer procLdapMsgfree.Call(res) //nolint:errcheck
+	}
+
+	if r1 != ldapSuccess {
+		return nil, fmt.Errorf("ldap_search_s failed: %w", Error(r1))
+	}
+
+	entry, _, _ := procLdapFirstEntry.Call(c.ld, res)
+	if entry == 0 {
+		return nil, nil
+	}
+
+	vals, _, _ := procLdapGetValuesW.Call(c.ld, entry, uintptr(unsafe.Pointer(attributePtr)))
+	if vals == 0 {
+		return nil, nil
+	}
+
+	defer procLdapValueFreeW.Call(vals) //nolint:errcheck
+
+	values := make([]string, 0, 1)
+
+	for ptr := *(***uint16)(unsafe.Pointer(&vals)); *ptr != nil; ptr = (**uint16)(unsafe.Add(unsafe.Pointer(ptr), unsafe.Sizeof(ptr))) {
+		values = append(values, windows.UTF16PtrToString(*ptr))
+	}
+
+	return values, nil
+}
+
+// Count returns the number of entries of the subtree of baseDN matching the filter.
+// The entries are requested in pages without attributes, so that counting is not limited by MaxPageSize.
+// 📑 https://learn.microsoft.com/en-us/windows/win32/api/winldap/nf-winldap-ldap_search_init_pagew
+func (c *Conn) Count(baseDN, filter string) (int, error) {
+	baseDNPtr, filterPtr, err := searchParameters(baseDN, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	noAttributesPtr, err := windows.UTF16PtrFromString(noAttributes)
+	if err != nil {
+		return 0, err
+	}
+
+	attributes := []*uint16{noAttributesPtr, nil}
+
+	search, _, _ := procLdapSearchInitPageW.Call(
+		c.ld,
+		uintptr(unsafe.Pointer(baseDNPtr)),
+		LDAP_SCOPE_SUBTREE,
+		uintptr(unsafe.Pointer(filterPtr)),
+		uintptr(unsafe.Pointer(&attributes[0])),
+		0,
+		0,
+		0,
+		0,
+		0,
+		0,
+	)
+	if search == 0 {
+		r1, _, _ := procLdapGetLastError.Call()
+
+		return 0, fmt.Errorf("ldap_search_init_page failed: %w", Error(r1))
+	}
+
+	defer procLdapSearchAbandonPage.Call(c.ld, search) //nolint:errcheck
+
+	var count int
+
+	for {
+		var (
+			res        uintptr
+			totalCount uint32
+		)
+
+		r1, _, _ := procLdapGetNextPageS.Call(
+			c.ld,
+			search,
+			0,
+			pageSize,
+			uintptr(unsafe.Pointer(&totalCount)),
+			uintptr(unsafe.Pointer(&res)),
+		)
+
+		if res != 0 {
+			entries, _, _ := procLdapCountEntries.Call(c.ld, res)
+			_, _, _ = procLdapMsgfree.Call(res)
+
+			// ldap_count_entries returns -1 on errors.
+			if int32(entries) > 0 {
+				count += int(int32(entries))
+			}
+		}
+
+		switch r1 {
+		case ldapSuccess:
+		case ldapNoResultsReturned:
+			return count, nil
+		default:
+			return 0, fmt.Errorf("ldap_get_next_page_s failed: %w", Error(r1))
+		}
+	}
+}
+
+func searchParameters(baseDN, filter string) (*uint16, *uint16, error) {
+	baseDNPtr, err := windows.UTF16PtrFromString(baseDN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filterPtr, err := windows.UTF16PtrFromString(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return baseDNPtr, filterPtr, nil
+}
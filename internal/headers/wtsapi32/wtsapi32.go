@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 	"unsafe"
 
 	"golang.org/x/sys/windows"
@@ -88,6 +89,43 @@ type wtsSessionInfo1 struct {
 	pFarmName *uint16
 }
 
+// wtsInfoClass values for WTSQuerySessionInformation.
+// docs: https://learn.microsoft.com/en-us/windows/win32/api/wtsapi32/ne-wtsapi32-wts_info_class
+const (
+	wtsSessionInfo uint32 = 24
+)
+
+// wtsInfo contains information about a Remote Desktop Services session.
+// docs: https://learn.microsoft.com/en-us/windows/win32/api/wtsapi32/ns-wtsapi32-wtsinfow
+type wtsInfo struct {
+	State                   uint32
+	SessionID               uint32
+	IncomingBytes           uint32
+	OutgoingBytes           uint32
+	IncomingFrames          uint32
+	OutgoingFrames          uint32
+	IncomingCompressedBytes uint32
+	OutgoingCompressedBytes uint32
+	WinStationName          [32]uint16
+	Domain                  [17]uint16
+	UserName                [21]uint16
+	ConnectTime             int64
+	DisconnectTime          int64
+	LastInputTime           int64
+	LogonTime               int64
+	CurrentTime             int64
+}
+
+// WTSSessionTimes holds the timestamps of a session reported by WTSQuerySessionInformation.
+// A zero [time.Time] means the event did not happen for this session.
+type WTSSessionTimes struct {
+	ConnectTime    time.Time
+	DisconnectTime time.Time
+	LastInputTime  time.Time
+	LogonTime      time.Time
+	CurrentTime    time.Time
+}
+
 type WTSSession struct {
 	ExecEnvID   uint32
 	State       WTSConnectState
@@ -106,6 +144,8 @@ var (
 	procWTSOpenServerEx        = wtsapi32.NewProc("WTSOpenServerExW")
 	procWTSEnumerateSessionsEx = wtsapi32.NewProc("WTSEnumerateSessionsExW")
 	procWTSFreeMemoryEx        = wtsapi32.NewProc("WTSFreeMemoryExW")
+	procWTSFreeMemory          = wtsapi32.NewProc("WTSFreeMemory")
+	procWTSQuerySessionInfo    = wtsapi32.NewProc("WTSQuerySessionInformationW")
 	procWTSCloseServer         = wtsapi32.NewProc("WTSCloseServer")
 
 	WTSSessionStates = map[WTSConnectState]string{
@@ -219,3 +259,48 @@ func WTSEnumerateSessionsEx(server windows.Handle, logger *slog.Logger) ([]WTSSe
 
 	return sessions, nil
 }
+
+// WTSQuerySessionTimes returns the connect, disconnect, last input and logon times of a session.
+func WTSQuerySessionTimes(server windows.Handle, sessionID uint32) (WTSSessionTimes, error) {
+	var (
+		data          *wtsInfo
+		bytesReturned uint32
+	)
+
+	r1, _, err := procWTSQuerySessionInfo.Call(
+		uintptr(server),
+		uintptr(sessionID),
+		uintptr(wtsSessionInfo),
+		uintptr(unsafe.Pointer(&data)),
+		uintptr(unsafe.Pointer(&bytesReturned)),
+	)
+
+	if r1 != 1 {
+		return WTSSessionTimes{}, err
+	}
+
+	defer procWTSFreeMemory.Call(uintptr(unsafe.Pointer(data))) //nolint:errcheck
+
+	if uintptr(bytesReturned) < unsafe.Sizeof(wtsInfo{}) {
+		return WTSSessionTimes{}, fmt.Errorf("WTSQuerySessionInformation returned %d bytes, expected %d", bytesReturned, unsafe.Sizeof(wtsInfo{}))
+	}
+
+	return WTSSessionTimes{
+		ConnectTime:    filetimeToTime(data.ConnectTime),
+		DisconnectTime: filetimeToTime(data.DisconnectTime),
+		LastInputTime:  filetimeToTime(data.LastInputTime),
+		LogonTime:      filetimeToTime(data.LogonTime),
+		CurrentTime:    filetimeToTime(data.CurrentTime),
+	}, nil
+}
+
+func filetimeToTime(ft int64) time.Time {
+	if ft == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, (&windows.Filetime{
+		LowDateTime:  uint32(ft),
+		HighDateTime: uint32(ft >> 32),
+	}).Nanoseconds())
+}
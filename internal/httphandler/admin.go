@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+)
+
+// AdminHandler lists, enables and disables collectors at runtime:
+//
+//	GET  /api/v1/collectors
+//	POST /api/v1/collectors/{name}/enable
+//	POST /api/v1/collectors/{name}/disable
+//
+// Requests must send the admin token as bearer token.
+type AdminHandler struct {
+	logger  *slog.Logger
+	handler *MetricsHTTPHandler
+	token   []byte
+	// persist enables or disables the collector in the configuration file and reloads the configuration.
+	persist func(ctx context.Context, name string, enabled bool) error
+}
+
+type adminCollector struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Interface guard.
+var _ http.Handler = (*AdminHandler)(nil)
+
+func NewAdminHandler(logger *slog.Logger, handler *MetricsHTTPHandler, token []byte, persist func(ctx context.Context, name string, enabled bool) error) AdminHandler {
+	return AdminHandler{
+		logger:  logger,
+		handler: handler,
+		token:   token,
+		persist: persist,
+	}
+}
+
+func (h AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, h.token) {
+		return
+	}
+
+	name := r.PathValue("name")
+	if name == "" {
+		h.listCollectors(w)
+
+		return
+	}
+
+	if _, ok := collector.BuildersWithFlags[name]; !ok {
+		http.Error(w, fmt.Sprintf("unknown collector %s", name), http.StatusNotFound)
+
+		return
+	}
+
+	var enabled bool
+
+	switch action := r.PathValue("action"); action {
+	case "enable":
+		enabled = true
+	case "disable":
+		enabled = false
+	default:
+		http.Error(w, fmt.Sprintf("unknown action %s, expected enable or disable", action), http.StatusNotFound)
+
+		return
+	}
+
+	var persist bool
+
+	if value := r.URL.Query().Get("persist"); value != "" {
+		var err error
+
+		persist, err = strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid persist parameter: %s", err), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	if err := h.setCollectorEnabled(r.Context(), name, enabled, persist); err != nil {
+		h.logger.Warn("Couldn't change collector",
+			slog.String("remote", r.RemoteAddr),
+			slog.String("collector", name),
+			slog.Bool("enabled", enabled),
+			slog.Any("err", err),
+		)
+
+		status := http.StatusInternalServerError
+		if errors.Is(err, collector.ErrCollectorNotEnabled) {
+			status = http.StatusConflict
+		}
+
+		http.Error(w, err.Error(), status)
+
+		return
+	}
+
+	h.logger.Info("Changed collector at runtime",
+		slog.String("remote", r.RemoteAddr),
+		slog.String("collector", name),
+		slog.Bool("enabled", enabled),
+		slog.Bool("persist", persist),
+	)
+
+	h.listCollectors(w)
+}
+
+// authorized returns true if the request sends the admin token as bearer token. Otherwise, it responds with
+// 401 Unauthorized.
+func authorized(w http.ResponseWriter, r *http.Request, adminToken []byte) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), adminToken) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+
+		return false
+	}
+
+	return true
+}
+
+func (h AdminHandler) setCollectorEnabled(ctx context.Context, name string, enabled, persist bool) error {
+	if !persist {
+		if err := h.handler.SetCollectorDisabled(name, !enabled); err != nil {
+			if errors.Is(err, collector.ErrCollectorNotEnabled) {
+				return fmt.Errorf("%w, collectors that are not enabled at startup require persist=true", err)
+			}
+
+			return err
+		}
+
+		return nil
+	}
+
+	if h.persist == nil {
+		return errors.New("persisting requires a configuration file, see --config.file")
+	}
+
+	return h.persist(ctx, name, enabled)
+}
+
+func (h AdminHandler) listCollectors(w http.ResponseWriter) {
+	collectors := h.handler.collectorStates()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(collectors)
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminHandler(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	adminHandler := NewAdminHandler(slog.New(slog.DiscardHandler), nil, []byte("secret"), nil)
+	mux.Handle("GET /api/v1/collectors", adminHandler)
+	mux.Handle("POST /api/v1/collectors/{name}/{action}", adminHandler)
+
+	for _, tc := range []struct {
+		name          string
+		path          string
+		authorization string
+		status        int
+	}{
+		{name: "no token", path: "/api/v1/collectors/cpu/disable", status: http.StatusUnauthorized},
+		{name: "wrong token", path: "/api/v1/collectors/cpu/disable", authorization: "Bearer wrong", status: http.StatusUnauthorized},
+		{name: "basic auth", path: "/api/v1/collectors/cpu/disable", authorization: "Basic c2VjcmV0", status: http.StatusUnauthorized},
+		{name: "unknown collector", path: "/api/v1/collectors/unknown/disable", authorization: "Bearer secret", status: http.StatusNotFound},
+		{name: "unknown action", path: "/api/v1/collectors/cpu/restart", authorization: "Bearer secret", status: http.StatusNotFound},
+		{name: "invalid persist", path: "/api/v1/collectors/cpu/disable?persist=maybe", authorization: "Bearer secret", status: http.StatusBadRequest},
+		{name: "persist without config file", path: "/api/v1/collectors/cpu/enable?persist=true", authorization: "Bearer secret", status: http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodPost, tc.path, nil)
+			if tc.authorization != "" {
+				r.Header.Set("Authorization", tc.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			require.Equal(t, tc.status, w.Code)
+		})
+	}
+}
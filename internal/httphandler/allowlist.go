@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// AllowListOptions restrict the clients of the HTTP server in addition to the TLS and authentication settings
+// of the web config.
+type AllowListOptions struct {
+	// Networks are the networks of the allowed client addresses. If empty, all addresses are allowed.
+	Networks []netip.Prefix
+	// ClientNames matches the common name or one of the subject alternative names of the allowed client certificates.
+	// If nil, all clients are allowed. Only certificates that were verified against the client CA of the web config
+	// are accepted.
+	ClientNames *regexp.Regexp
+}
+
+// AllowListHandler responds with 403 Forbidden to requests of clients that are not allowed by the options
+// and passes all other requests to the next handler.
+type AllowListHandler struct {
+	logger  *slog.Logger
+	next    http.Handler
+	options AllowListOptions
+}
+
+// Interface guard.
+var _ http.Handler = (*AllowListHandler)(nil)
+
+func NewAllowListHandler(logger *slog.Logger, next http.Handler, options AllowListOptions) AllowListHandler {
+	return AllowListHandler{
+		logger:  logger,
+		next:    next,
+		options: options,
+	}
+}
+
+func (h AllowListHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h.allowed(r); err != nil {
+		h.logger.Debug("Denied request",
+			slog.String("remote", r.RemoteAddr),
+			slog.String("path", r.URL.Path),
+			slog.Any("err", err),
+		)
+
+		http.Error(w, "Forbidden", http.StatusForbidden)
+
+		return
+	}
+
+	h.next.ServeHTTP(w, r)
+}
+
+// allowed returns an error if the client of the request is not allowed.
+func (h AllowListHandler) allowed(r *http.Request) error {
+	if len(h.options.Networks) > 0 {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return fmt.Errorf("invalid remote address: %w", err)
+		}
+
+		addr, err := netip.ParseAddr(host)
+		if err != nil {
+			return fmt.Errorf("invalid remote address: %w", err)
+		}
+
+		addr = addr.Unmap().WithZone("")
+
+		if !slices.ContainsFunc(h.options.Networks, func(network netip.Prefix) bool { return network.Contains(addr) }) {
+			return fmt.Errorf("address %s is not in an allowed network", addr)
+		}
+	}
+
+	if h.options.ClientNames != nil {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			return errors.New("no verified client certificate")
+		}
+
+		names := certificateNames(r.TLS.VerifiedChains[0][0])
+		if !slices.ContainsFunc(names, h.options.ClientNames.MatchString) {
+			return fmt.Errorf("names %q of the client certificate are not allowed", names)
+		}
+	}
+
+	return nil
+}
+
+// certificateNames returns the common name and the DNS, IP address, URI and email subject alternative names
+// of the certificate.
+func certificateNames(certificate *x509.Certificate) []string {
+	names := make([]string, 0, 1+len(certificate.DNSNames)+len(certificate.IPAddresses)+len(certificate.URIs)+len(certificate.EmailAddresses))
+
+	if certificate.Subject.CommonName != "" {
+		names = append(names, certificate.Subject.CommonName)
+	}
+
+	names = append(names, certificate.DNSNames...)
+
+	for _, ip := range certificate.IPAddresses {
+		names = append(names, ip.String())
+	}
+
+	for _, uri := range certificate.URIs {
+		names = append(names, uri.String())
+	}
+
+	return append(names, certificate.EmailAddresses...)
+}
+
+// ParseNetworks parses a comma-separated list of networks in CIDR notation or single IP addresses,
+// e.g. "10.0.0.0/8,192.168.1.10,fd00::/8".
+func ParseNetworks(networks string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0)
+
+	for network := range strings.SplitSeq(networks, ",") {
+		network = strings.TrimSpace(network)
+		if network == "" {
+			continue
+		}
+
+		if !strings.Contains(network, "/") {
+			addr, err := netip.ParseAddr(network)
+			if err != nil {
+				return nil, fmt.Errorf("invalid network %q: %w", network, err)
+			}
+
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", network, err)
+		}
+
+		// IPv4-mapped IPv6 networks are converted to IPv4, like the client addresses.
+		if prefix.Addr().Is4In6() {
+			if prefix.Bits() < 96 {
+				return nil, fmt.Errorf("invalid network %q: IPv4-mapped IPv6 networks need a prefix length of at least 96", network)
+			}
+
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
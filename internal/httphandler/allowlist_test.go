@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowListHandler(t *testing.T) {
+	t.Parallel()
+
+	networks, err := ParseNetworks("10.0.0.0/8, 192.168.1.10,fd00::/8")
+	require.NoError(t, err)
+
+	handler := NewAllowListHandler(slog.New(slog.DiscardHandler), http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), AllowListOptions{
+		Networks:    networks,
+		ClientNames: regexp.MustCompile("^(?:prometheus-.+|10\\.0\\.0\\.5|spiffe://example\\.com/prometheus)$"),
+	})
+
+	verified := func(commonName string) *tls.ConnectionState {
+		return &tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: commonName}}}},
+		}
+	}
+
+	verifiedSANs := func(certificate *x509.Certificate) *tls.ConnectionState {
+		return &tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{certificate}},
+		}
+	}
+
+	for _, tc := range []struct {
+		name       string
+		remoteAddr string
+		tls        *tls.ConnectionState
+		status     int
+	}{
+		{name: "allowed", remoteAddr: "10.1.2.3:50000", tls: verified("prometheus-1"), status: http.StatusOK},
+		{name: "allowed address", remoteAddr: "192.168.1.10:50000", tls: verified("prometheus-2"), status: http.StatusOK},
+		{name: "IPv4-mapped IPv6 address", remoteAddr: "[::ffff:10.1.2.3]:50000", tls: verified("prometheus-1"), status: http.StatusOK},
+		{name: "IPv6 address", remoteAddr: "[fd00::1]:50000", tls: verified("prometheus-1"), status: http.StatusOK},
+		{name: "address not allowed", remoteAddr: "192.168.1.11:50000", tls: verified("prometheus-1"), status: http.StatusForbidden},
+		{name: "no TLS", remoteAddr: "10.1.2.3:50000", status: http.StatusForbidden},
+		{name: "unverified certificate", remoteAddr: "10.1.2.3:50000", tls: &tls.ConnectionState{}, status: http.StatusForbidden},
+		{name: "common name not allowed", remoteAddr: "10.1.2.3:50000", tls: verified("grafana"), status: http.StatusForbidden},
+		{name: "DNS name", remoteAddr: "10.1.2.3:50000", tls: verifiedSANs(&x509.Certificate{DNSNames: []string{"grafana", "prometheus-3"}}), status: http.StatusOK},
+		{name: "IP address", remoteAddr: "10.1.2.3:50000", tls: verifiedSANs(&x509.Certificate{IPAddresses: []net.IP{net.ParseIP("10.0.0.5")}}), status: http.StatusOK},
+		{name: "URI", remoteAddr: "10.1.2.3:50000", tls: verifiedSANs(&x509.Certificate{URIs: []*url.URL{{Scheme: "spiffe", Host: "example.com", Path: "/prometheus"}}}), status: http.StatusOK},
+		{name: "subject alternative names not allowed", remoteAddr: "10.1.2.3:50000", tls: verifiedSANs(&x509.Certificate{DNSNames: []string{"grafana"}}), status: http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			r.RemoteAddr = tc.remoteAddr
+			r.TLS = tc.tls
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			require.Equal(t, tc.status, w.Code)
+		})
+	}
+}
+
+func TestParseNetworks(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseNetworks("10.0.0.0/33")
+	require.Error(t, err)
+
+	_, err = ParseNetworks("prometheus.example.com")
+	require.Error(t, err)
+
+	_, err = ParseNetworks("::ffff:10.0.0.0/95")
+	require.Error(t, err)
+
+	networks, err := ParseNetworks("")
+	require.NoError(t, err)
+	require.Empty(t, networks)
+
+	networks, err = ParseNetworks("::ffff:10.0.0.0/104, ::ffff:192.168.1.10")
+	require.NoError(t, err)
+	require.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.1.10/32")}, networks)
+}
@@ -0,0 +1,256 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	// captureInterval is the time between the samples of a capture.
+	captureInterval        = time.Second
+	defaultCaptureDuration = 10 * time.Second
+	maxCaptureDuration     = 5 * time.Minute
+)
+
+// CaptureHandler collects the selected collectors every second for a short duration and returns the samples
+// as an OpenMetrics snapshot with timestamps, e.g. for the analysis of an incident:
+//
+//	POST /api/v1/capture?collect[]=cpu&collect[]=process&duration=30s
+//
+// Collectors and filters are selected like for POST /metrics. Requests must send the admin token as bearer token.
+// Only one capture runs at a time.
+type CaptureHandler struct {
+	logger  *slog.Logger
+	handler *MetricsHTTPHandler
+	token   []byte
+	running atomic.Bool
+}
+
+// Interface guard.
+var _ http.Handler = (*CaptureHandler)(nil)
+
+func NewCaptureHandler(logger *slog.Logger, handler *MetricsHTTPHandler, token []byte) *CaptureHandler {
+	return &CaptureHandler{
+		logger:  logger,
+		handler: handler,
+		token:   token,
+	}
+}
+
+func (h *CaptureHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, h.token) {
+		return
+	}
+
+	duration := defaultCaptureDuration
+
+	if value := r.URL.Query().Get("duration"); value != "" {
+		var err error
+
+		duration, err = time.ParseDuration(value)
+		if err != nil || duration < captureInterval || duration > maxCaptureDuration {
+			http.Error(w, fmt.Sprintf("invalid duration %q, expected a duration between %s and %s", value, captureInterval, maxCaptureDuration), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	requestedCollectors, filters, err := parseCaptureRequest(w, r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Couldn't parse capture request: %s", err), http.StatusBadRequest)
+
+		return
+	}
+
+	// A capture of all collectors every second would put the load of a scrape storm on the host.
+	if !slices.ContainsFunc(requestedCollectors, func(name string) bool { return !strings.HasPrefix(name, "!") }) {
+		http.Error(w, "no collectors selected, expected collect[] parameters", http.StatusBadRequest)
+
+		return
+	}
+
+	if !h.running.CompareAndSwap(false, true) {
+		http.Error(w, "another capture is running", http.StatusConflict)
+
+		return
+	}
+
+	defer h.running.Store(false)
+
+	start := time.Now()
+
+	families, samples, err := h.handler.capture(r.Context(), duration, requestedCollectors, filters)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+
+		http.Error(w, fmt.Sprintf("Couldn't capture metrics: %s", err), http.StatusBadRequest)
+
+		return
+	}
+
+	h.logger.Info("Captured metrics",
+		slog.String("remote", r.RemoteAddr),
+		slog.Any("collectors", requestedCollectors),
+		slog.Int("samples", samples),
+		slog.Duration("duration", time.Since(start)),
+	)
+
+	format := expfmt.NewFormat(expfmt.TypeOpenMetrics)
+
+	w.Header().Set("Content-Type", string(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="windows_exporter-capture-%s.om"`, start.UTC().Format("20060102T150405Z")))
+
+	encoder := expfmt.NewEncoder(w, format)
+
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			h.logger.Warn("Couldn't encode capture",
+				slog.String("remote", r.RemoteAddr),
+				slog.Any("err", err),
+			)
+
+			return
+		}
+	}
+
+	if closer, ok := encoder.(expfmt.Closer); ok {
+		_ = closer.Close()
+	}
+}
+
+// parseCaptureRequest returns the requested collectors and filters, see parseScrapeRequest.
+// A POST request without body selects the collectors by query parameters only.
+func parseCaptureRequest(w http.ResponseWriter, r *http.Request) ([]string, map[string]collector.MetricFilter, error) {
+	if r.Header.Get("Content-Type") == "" {
+		return appendCollectors(nil, r.URL.Query()["collect[]"], r.URL.Query()["exclude[]"]), nil, nil
+	}
+
+	return parseScrapeRequest(w, r)
+}
+
+// capture collects the requested collectors every captureInterval for duration. The samples of each metric
+// are merged into one metric family, ordered by series and timestamp. The result cache is bypassed, so each sample
+// is a new collection.
+func (c *MetricsHTTPHandler) capture(ctx context.Context, duration time.Duration, requestedCollectors []string, filters map[string]collector.MetricFilter) ([]*dto.MetricFamily, int, error) {
+	ticker := time.NewTicker(captureInterval)
+	defer ticker.Stop()
+
+	families := make(map[string]*dto.MetricFamily)
+	samples := int(duration / captureInterval)
+
+	for i := range samples {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, i, ctx.Err()
+			case <-ticker.C:
+			}
+		}
+
+		timestamp := time.Now().UnixMilli()
+
+		gathered, err := c.gatherCaptureSample(requestedCollectors, filters)
+		if err != nil {
+			return nil, i, err
+		}
+
+		for _, family := range gathered {
+			for _, metric := range family.GetMetric() {
+				if metric.TimestampMs == nil {
+					metric.TimestampMs = &timestamp
+				}
+			}
+
+			if merged, ok := families[family.GetName()]; ok {
+				merged.Metric = append(merged.Metric, family.GetMetric()...)
+			} else {
+				families[family.GetName()] = family
+			}
+		}
+	}
+
+	result := make([]*dto.MetricFamily, 0, len(families))
+
+	for _, name := range slices.Sorted(maps.Keys(families)) {
+		family := families[name]
+
+		// OpenMetrics requires the samples of a series to be consecutive. The stable sort keeps them in timestamp order.
+		slices.SortStableFunc(family.Metric, func(a, b *dto.Metric) int {
+			return strings.Compare(seriesKey(a), seriesKey(b))
+		})
+
+		result = append(result, family)
+	}
+
+	return result, samples, nil
+}
+
+func (c *MetricsHTTPHandler) gatherCaptureSample(requestedCollectors []string, filters map[string]collector.MetricFilter) ([]*dto.MetricFamily, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	collectionHandler, err := c.metricCollectors.WithoutCache().NewHandlerWithFilters(captureInterval, c.logger, requestedCollectors, filters)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create collector handler: %w", err)
+	}
+
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(collectionHandler); err != nil {
+		return nil, fmt.Errorf("couldn't register Prometheus collector: %w", err)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		c.logger.Warn("Couldn't gather all metrics of the capture",
+			slog.Any("err", err),
+		)
+	}
+
+	return families, nil
+}
+
+// seriesKey returns the label values of the metric, which identify its series within the metric family.
+func seriesKey(metric *dto.Metric) string {
+	var key strings.Builder
+
+	for _, label := range metric.GetLabel() {
+		key.WriteString(label.GetName())
+		key.WriteByte(0xff)
+		key.WriteString(label.GetValue())
+		key.WriteByte(0xff)
+	}
+
+	return key.String()
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureHandler(t *testing.T) {
+	t.Parallel()
+
+	captureHandler := NewCaptureHandler(slog.New(slog.DiscardHandler), nil, []byte("secret"))
+
+	for _, tc := range []struct {
+		name          string
+		path          string
+		authorization string
+		status        int
+	}{
+		{name: "no token", path: "/api/v1/capture?collect[]=cpu", status: http.StatusUnauthorized},
+		{name: "invalid duration", path: "/api/v1/capture?collect[]=cpu&duration=soon", authorization: "Bearer secret", status: http.StatusBadRequest},
+		{name: "duration too short", path: "/api/v1/capture?collect[]=cpu&duration=500ms", authorization: "Bearer secret", status: http.StatusBadRequest},
+		{name: "duration too long", path: "/api/v1/capture?collect[]=cpu&duration=1h", authorization: "Bearer secret", status: http.StatusBadRequest},
+		{name: "no collectors", path: "/api/v1/capture", authorization: "Bearer secret", status: http.StatusBadRequest},
+		{name: "only excluded collectors", path: "/api/v1/capture?exclude[]=cpu", authorization: "Bearer secret", status: http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodPost, tc.path, nil)
+			if tc.authorization != "" {
+				r.Header.Set("Authorization", tc.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			captureHandler.ServeHTTP(w, r)
+
+			require.Equal(t, tc.status, w.Code)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// CompatMetricNamesV025 emits the metric names of windows_exporter v0.25 next to the current names.
+const CompatMetricNamesV025 = "v0.25"
+
+// legacyMetricNames maps current metric names to the names they had in a previous release.
+// Only renames without changes of labels or values are listed, so the legacy series are exact copies.
+//
+//nolint:gochecknoglobals
+var legacyMetricNames = map[string]map[string][]string{
+	CompatMetricNamesV025: {
+		"windows_cpu_logical_processor":             {"windows_cs_logical_processors"},
+		"windows_memory_commit_limit":               {"windows_os_virtual_memory_bytes"},
+		"windows_memory_physical_free_bytes":        {"windows_os_physical_memory_free_bytes"},
+		"windows_memory_physical_total_bytes":       {"windows_cs_physical_memory_bytes", "windows_os_visible_memory_bytes"},
+		"windows_memory_process_memory_limit_bytes": {"windows_os_process_memory_limit_bytes"},
+		"windows_os_hostname":                       {"windows_cs_hostname"},
+		"windows_system_boot_time_timestamp":        {"windows_system_system_up_time"},
+		"windows_system_processes":                  {"windows_os_processes"},
+		"windows_system_processes_limit":            {"windows_os_processes_limit"},
+		"windows_time_current_timestamp_seconds":    {"windows_os_time"},
+		"windows_time_timezone":                     {"windows_os_timezone"},
+	},
+}
+
+// CompatMetricNamesVersions returns the releases supported by --compat.metric-names.
+func CompatMetricNamesVersions() []string {
+	versions := make([]string, 0, len(legacyMetricNames))
+	for version := range legacyMetricNames {
+		versions = append(versions, version)
+	}
+
+	slices.Sort(versions)
+
+	return versions
+}
+
+// compatGatherer copies metric families to their legacy names, so dashboards and recording rules
+// can be migrated after an upgrade.
+type compatGatherer struct {
+	gatherer prometheus.Gatherer
+	renames  map[string][]string
+	// exclude matches legacy names that are not emitted.
+	exclude *regexp.Regexp
+}
+
+func (g compatGatherer) Gather() ([]*dto.MetricFamily, error) {
+	metricFamilies, err := g.gatherer.Gather()
+	if len(metricFamilies) == 0 {
+		return metricFamilies, err
+	}
+
+	names := make(map[string]struct{}, len(metricFamilies))
+	for _, metricFamily := range metricFamilies {
+		names[metricFamily.GetName()] = struct{}{}
+	}
+
+	legacyFamilies := make([]*dto.MetricFamily, 0)
+
+	for _, metricFamily := range metricFamilies {
+		for _, legacyName := range g.renames[metricFamily.GetName()] {
+			// Never emit a legacy name twice, e.g. if a collector still exposes it.
+			if _, ok := names[legacyName]; ok {
+				continue
+			}
+
+			if g.exclude != nil && g.exclude.MatchString(legacyName) {
+				continue
+			}
+
+			names[legacyName] = struct{}{}
+
+			help := "Deprecated: renamed to " + metricFamily.GetName() + ". " + metricFamily.GetHelp()
+
+			legacyFamilies = append(legacyFamilies, &dto.MetricFamily{
+				Name:   &legacyName,
+				Help:   &help,
+				Type:   metricFamily.Type,
+				Unit:   metricFamily.Unit,
+				Metric: metricFamily.GetMetric(),
+			})
+		}
+	}
+
+	if len(legacyFamilies) == 0 {
+		return metricFamilies, err
+	}
+
+	metricFamilies = append(metricFamilies, legacyFamilies...)
+
+	slices.SortFunc(metricFamilies, func(a, b *dto.MetricFamily) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+
+	return metricFamilies, err
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompatGatherer(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+
+	processes := prometheus.NewGauge(prometheus.GaugeOpts{Name: "windows_system_processes", Help: "Current number of processes"})
+	processes.Set(42)
+
+	physicalMemory := prometheus.NewGauge(prometheus.GaugeOpts{Name: "windows_memory_physical_total_bytes", Help: "Total physical memory"})
+	physicalMemory.Set(1024)
+
+	legacyTime := prometheus.NewGauge(prometheus.GaugeOpts{Name: "windows_os_time", Help: "Still exposed by a collector"})
+	currentTime := prometheus.NewGauge(prometheus.GaugeOpts{Name: "windows_time_current_timestamp_seconds", Help: "Current time"})
+
+	reg.MustRegister(processes, physicalMemory, legacyTime, currentTime)
+
+	gatherer := compatGatherer{
+		gatherer: reg,
+		renames:  legacyMetricNames[CompatMetricNamesV025],
+		exclude:  regexp.MustCompile("^(?:windows_cs_.+)$"),
+	}
+
+	metricFamilies, err := gatherer.Gather()
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(metricFamilies))
+	for _, metricFamily := range metricFamilies {
+		names = append(names, metricFamily.GetName())
+
+		if metricFamily.GetName() == "windows_os_processes" {
+			require.Equal(t, "Deprecated: renamed to windows_system_processes. Current number of processes", metricFamily.GetHelp())
+			require.InDelta(t, 42.0, metricFamily.GetMetric()[0].GetGauge().GetValue(), 0)
+		}
+
+		if metricFamily.GetName() == "windows_os_time" {
+			require.Equal(t, "Still exposed by a collector", metricFamily.GetHelp())
+		}
+	}
+
+	require.Equal(t, []string{
+		"windows_memory_physical_total_bytes",
+		"windows_os_processes",
+		"windows_os_time",
+		"windows_os_visible_memory_bytes",
+		"windows_system_processes",
+		"windows_time_current_timestamp_seconds",
+	}, names)
+}
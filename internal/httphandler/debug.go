@@ -0,0 +1,284 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/debugdump"
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// dumpTimeout limits the collection of a dump.
+const dumpTimeout = time.Minute
+
+// DebugHandler serves diagnostic information about the collectors:
+//
+//	GET /debug/collectors
+//	GET /debug/dump?collector=cpu
+//
+// /debug/collectors lists the enabled collectors with their flags and the result of their last collection.
+// /debug/dump collects a single collector and returns the raw values of its PDH and WMI queries and the
+// resulting metrics as JSON. No other collector is collected during a dump.
+type DebugHandler struct {
+	logger  *slog.Logger
+	handler *MetricsHTTPHandler
+}
+
+type debugCollector struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	// Excluded is the reason why the collector was excluded at startup.
+	Excluded       string               `json:"excluded,omitempty"`
+	InitError      string               `json:"init_error,omitempty"`
+	Flags          map[string]string    `json:"flags,omitempty"`
+	LastCollection *debugLastCollection `json:"last_collection,omitempty"`
+}
+
+type debugLastCollection struct {
+	Time            time.Time `json:"time"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Success         bool      `json:"success"`
+	Error           string    `json:"error,omitempty"`
+	Metrics         int       `json:"metrics"`
+}
+
+type debugDump struct {
+	Collector       string             `json:"collector"`
+	DurationSeconds float64            `json:"duration_seconds"`
+	Error           string             `json:"error,omitempty"`
+	Queries         []debugdump.Record `json:"queries"`
+	Metrics         []debugMetric      `json:"metrics"`
+}
+
+type debugMetric struct {
+	Name    string        `json:"name"`
+	Help    string        `json:"help"`
+	Type    string        `json:"type"`
+	Samples []debugSample `json:"samples"`
+}
+
+// debugSample is a sample of a metric. Values are strings like in the Prometheus HTTP API, since JSON has no NaN.
+// Count is set for histograms and summaries, whose value is the sum.
+type debugSample struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  string            `json:"value"`
+	Count  *uint64           `json:"count,omitempty"`
+}
+
+// Interface guard.
+var _ http.Handler = (*DebugHandler)(nil)
+
+func NewDebugHandler(logger *slog.Logger, handler *MetricsHTTPHandler) DebugHandler {
+	return DebugHandler{
+		logger:  logger,
+		handler: handler,
+	}
+}
+
+func (h DebugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/debug/dump" {
+		writeJSON(w, h.handler.debugCollectors())
+
+		return
+	}
+
+	name := r.URL.Query().Get("collector")
+	if name == "" {
+		http.Error(w, "missing collector parameter", http.StatusBadRequest)
+
+		return
+	}
+
+	if _, ok := collector.BuildersWithFlags[name]; !ok {
+		http.Error(w, fmt.Sprintf("unknown collector %s", name), http.StatusNotFound)
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dumpTimeout)
+	defer cancel()
+
+	dump, err := h.handler.dump(ctx, name)
+	if err != nil {
+		status := http.StatusServiceUnavailable
+
+		switch {
+		case errors.Is(err, collector.ErrCollectorNotEnabled):
+			status = http.StatusNotFound
+		case errors.Is(err, collector.ErrCollectorBusy):
+			status = http.StatusConflict
+		}
+
+		http.Error(w, fmt.Sprintf("Couldn't dump collector: %s", err), status)
+
+		return
+	}
+
+	h.logger.Info("Dumped collector",
+		slog.String("remote", r.RemoteAddr),
+		slog.String("collector", name),
+		slog.Int("queries", len(dump.Queries)),
+		slog.Float64("duration_seconds", dump.DurationSeconds),
+	)
+
+	writeJSON(w, dump)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// debugCollectors returns the collectors of the current collection, see collector.Collection.Status.
+func (c *MetricsHTTPHandler) debugCollectors() []debugCollector {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	statuses := c.metricCollectors.Status()
+	collectors := make([]debugCollector, 0, len(statuses))
+
+	for _, status := range statuses {
+		debug := debugCollector{
+			Name:      status.Name,
+			Enabled:   !status.Disabled && status.Excluded == "",
+			Excluded:  status.Excluded,
+			InitError: status.InitError,
+			Flags:     status.Flags,
+		}
+
+		if last := status.LastCollection; last != nil {
+			debug.LastCollection = &debugLastCollection{
+				Time:            last.Time,
+				DurationSeconds: last.Duration.Seconds(),
+				Success:         last.Success,
+				Error:           last.Error,
+				Metrics:         last.Metrics,
+			}
+		}
+
+		collectors = append(collectors, debug)
+	}
+
+	return collectors
+}
+
+// dump collects a single collector of the current collection, see collector.Collection.Dump.
+func (c *MetricsHTTPHandler) dump(ctx context.Context, name string) (debugDump, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	dump, err := c.metricCollectors.Dump(ctx, c.logger, name)
+	if err != nil {
+		return debugDump{}, err
+	}
+
+	result := debugDump{
+		Collector:       name,
+		DurationSeconds: dump.Duration.Seconds(),
+		Queries:         dump.Records,
+	}
+
+	if result.Queries == nil {
+		result.Queries = []debugdump.Record{}
+	}
+
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(metricsReplay(dump.Metrics)); err != nil {
+		return debugDump{}, fmt.Errorf("couldn't register Prometheus collector: %w", err)
+	}
+
+	// Gather returns the valid metrics even if some metrics are invalid, e.g. duplicates.
+	families, gatherErr := reg.Gather()
+
+	if err := errors.Join(dump.Err, gatherErr); err != nil {
+		result.Error = err.Error()
+	}
+
+	result.Metrics = make([]debugMetric, 0, len(families))
+	for _, family := range families {
+		result.Metrics = append(result.Metrics, newDebugMetric(family))
+	}
+
+	return result, nil
+}
+
+// metricsReplay is an unchecked Prometheus collector that sends already collected metrics.
+type metricsReplay []prometheus.Metric
+
+func (m metricsReplay) Describe(chan<- *prometheus.Desc) {}
+
+func (m metricsReplay) Collect(ch chan<- prometheus.Metric) {
+	for _, metric := range m {
+		ch <- metric
+	}
+}
+
+func newDebugMetric(family *dto.MetricFamily) debugMetric {
+	metric := debugMetric{
+		Name:    family.GetName(),
+		Help:    family.GetHelp(),
+		Type:    strings.ToLower(family.GetType().String()),
+		Samples: make([]debugSample, 0, len(family.GetMetric())),
+	}
+
+	for _, m := range family.GetMetric() {
+		sample := debugSample{}
+
+		if len(m.GetLabel()) > 0 {
+			sample.Labels = make(map[string]string, len(m.GetLabel()))
+			for _, label := range m.GetLabel() {
+				sample.Labels[label.GetName()] = label.GetValue()
+			}
+		}
+
+		var value float64
+
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			value = m.GetCounter().GetValue()
+		case dto.MetricType_GAUGE:
+			value = m.GetGauge().GetValue()
+		case dto.MetricType_SUMMARY:
+			value = m.GetSummary().GetSampleSum()
+			count := m.GetSummary().GetSampleCount()
+			sample.Count = &count
+		case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+			value = m.GetHistogram().GetSampleSum()
+			count := m.GetHistogram().GetSampleCount()
+			sample.Count = &count
+		default:
+			value = m.GetUntyped().GetValue()
+		}
+
+		sample.Value = strconv.FormatFloat(value, 'g', -1, 64)
+		metric.Samples = append(metric.Samples, sample)
+	}
+
+	return metric
+}
@@ -0,0 +1,228 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"compress/gzip"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// maxDeltaSnapshots is the number of scrape results kept as base of delta responses. Clients whose base was
+// evicted receive all metric families again.
+const maxDeltaSnapshots = 64
+
+// DeltaHandler returns only the metric families that changed since a previous response, e.g. for bandwidth constrained
+// links which would otherwise transfer megabytes of unchanged series on every scrape:
+//
+//	GET /api/v1/metrics/delta?collect[]=service
+//	If-None-Match: "<ETag of the previous response>"
+//
+// Each response has an ETag. If the client sends the ETag of a previous response, families whose samples are unchanged
+// are left out and the families which no longer exist are listed in the X-Removed-Metric-Families header.
+// The client merges the response into its copy of the previous response. X-Delta-Base is set to the ETag of the base if
+// the response is a delta, otherwise the response contains all families. If nothing changed, 304 Not Modified is returned.
+//
+// Collectors are selected like for GET /metrics. The endpoint is experimental.
+type DeltaHandler struct {
+	logger  *slog.Logger
+	handler *MetricsHTTPHandler
+
+	// mu protects snapshots and order.
+	mu        sync.Mutex
+	snapshots map[string]map[string]uint64
+	// order are the ETags of the snapshots, the oldest first.
+	order []string
+}
+
+// Interface guard.
+var _ http.Handler = (*DeltaHandler)(nil)
+
+func NewDeltaHandler(logger *slog.Logger, handler *MetricsHTTPHandler) *DeltaHandler {
+	return &DeltaHandler{
+		logger:    logger,
+		handler:   handler,
+		snapshots: make(map[string]map[string]uint64),
+	}
+}
+
+func (h *DeltaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(
+		slog.String("remote", r.RemoteAddr),
+	)
+
+	requestedCollectors, filters, err := parseScrapeRequest(w, r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Couldn't parse scrape request: %s", err), http.StatusBadRequest)
+
+		return
+	}
+
+	families, err := h.handler.gather(getScrapeTimeout(logger, r, h.handler.options.TimeoutMargin), requestedCollectors, filters)
+	if err != nil {
+		logger.Warn("Couldn't gather all metrics of the delta scrape",
+			slog.Any("err", err),
+		)
+
+		if families == nil {
+			http.Error(w, fmt.Sprintf("Couldn't gather metrics: %s", err), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	hashes := familyHashes(families)
+	etag := snapshotETag(hashes)
+
+	w.Header().Set("ETag", etag)
+
+	baseETag := strings.TrimPrefix(strings.TrimSpace(r.Header.Get("If-None-Match")), "W/")
+	if baseETag == etag {
+		w.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	base, ok := h.snapshot(baseETag)
+	h.store(etag, hashes)
+
+	if ok {
+		var removed []string
+
+		families, removed = deltaFamilies(families, hashes, base)
+
+		w.Header().Set("X-Delta-Base", baseETag)
+
+		if len(removed) > 0 {
+			w.Header().Set("X-Removed-Metric-Families", strings.Join(removed, ","))
+		}
+	}
+
+	format := expfmt.Negotiate(r.Header)
+
+	w.Header().Set("Content-Type", string(format))
+	w.Header().Set("Vary", "Accept, Accept-Encoding")
+
+	var writer io.Writer = w
+
+	if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Header().Set("Content-Encoding", "gzip")
+
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+
+		writer = gz
+	}
+
+	encoder := expfmt.NewEncoder(writer, format)
+
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			logger.Warn("Couldn't encode delta scrape",
+				slog.Any("err", err),
+			)
+
+			return
+		}
+	}
+
+	if closer, ok := encoder.(expfmt.Closer); ok {
+		_ = closer.Close()
+	}
+}
+
+func (h *DeltaHandler) snapshot(etag string) (map[string]uint64, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	snapshot, ok := h.snapshots[etag]
+
+	return snapshot, ok
+}
+
+// store keeps the hashes of a response as base of later deltas. The oldest snapshot is evicted beyond maxDeltaSnapshots.
+func (h *DeltaHandler) store(etag string, hashes map[string]uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.snapshots[etag]; ok {
+		return
+	}
+
+	h.snapshots[etag] = hashes
+	h.order = append(h.order, etag)
+
+	if len(h.order) > maxDeltaSnapshots {
+		delete(h.snapshots, h.order[0])
+		h.order = h.order[1:]
+	}
+}
+
+// familyHashes returns the hash of the text exposition of each metric family by name.
+func familyHashes(families []*dto.MetricFamily) map[string]uint64 {
+	hashes := make(map[string]uint64, len(families))
+
+	for _, family := range families {
+		hasher := fnv.New64a()
+		_, _ = expfmt.MetricFamilyToText(hasher, family)
+		hashes[family.GetName()] = hasher.Sum64()
+	}
+
+	return hashes
+}
+
+// snapshotETag returns the strong ETag of a response with the given family hashes.
+func snapshotETag(hashes map[string]uint64) string {
+	hasher := fnv.New64a()
+
+	for _, name := range slices.Sorted(maps.Keys(hashes)) {
+		_, _ = fmt.Fprintf(hasher, "%s\xff%x\xff", name, hashes[name])
+	}
+
+	return `"` + hex.EncodeToString(hasher.Sum(nil)) + `"`
+}
+
+// deltaFamilies returns the families whose hash differs from base and the names of the families of base that are missing.
+func deltaFamilies(families []*dto.MetricFamily, hashes, base map[string]uint64) ([]*dto.MetricFamily, []string) {
+	changed := slices.DeleteFunc(slices.Clone(families), func(family *dto.MetricFamily) bool {
+		baseHash, ok := base[family.GetName()]
+
+		return ok && baseHash == hashes[family.GetName()]
+	})
+
+	removed := make([]string, 0)
+
+	for _, name := range slices.Sorted(maps.Keys(base)) {
+		if _, ok := hashes[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+
+	return changed, removed
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDeltaFamilies(t *testing.T) {
+	t.Parallel()
+
+	gauge := func(name string, value float64) *dto.MetricFamily {
+		return &dto.MetricFamily{
+			Name:   proto.String(name),
+			Help:   proto.String(name),
+			Type:   dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: proto.Float64(value)}}},
+		}
+	}
+
+	base := []*dto.MetricFamily{gauge("a", 1), gauge("b", 2), gauge("c", 3)}
+	current := []*dto.MetricFamily{gauge("a", 1), gauge("b", 5), gauge("d", 4)}
+
+	baseHashes := familyHashes(base)
+	currentHashes := familyHashes(current)
+
+	require.NotEqual(t, snapshotETag(baseHashes), snapshotETag(currentHashes))
+	require.Equal(t, snapshotETag(baseHashes), snapshotETag(familyHashes([]*dto.MetricFamily{gauge("a", 1), gauge("b", 2), gauge("c", 3)})))
+
+	changed, removed := deltaFamilies(current, currentHashes, baseHashes)
+
+	names := make([]string, 0, len(changed))
+	for _, family := range changed {
+		names = append(names, family.GetName())
+	}
+
+	require.Equal(t, []string{"b", "d"}, names)
+	require.Equal(t, []string{"c"}, removed)
+}
+
+func TestDeltaHandlerStore(t *testing.T) {
+	t.Parallel()
+
+	h := NewDeltaHandler(nil, nil)
+
+	for i := range maxDeltaSnapshots + 1 {
+		h.store(snapshotETag(map[string]uint64{"a": uint64(i)}), map[string]uint64{"a": uint64(i)})
+	}
+
+	_, ok := h.snapshot(snapshotETag(map[string]uint64{"a": 0}))
+	require.False(t, ok, "oldest snapshot must be evicted")
+
+	snapshot, ok := h.snapshot(snapshotETag(map[string]uint64{"a": maxDeltaSnapshots}))
+	require.True(t, ok)
+	require.Equal(t, map[string]uint64{"a": maxDeltaSnapshots}, snapshot)
+	require.Len(t, h.order, maxDeltaSnapshots)
+}
@@ -18,9 +18,11 @@
 package httphandler
 
 import (
+	"encoding/json"
 	"net/http"
 )
 
+// HealthHandler reports that the exporter is running. It doesn't check the collectors, see ReadyHandler.
 type HealthHandler struct{}
 
 // Interface guard.
@@ -34,3 +36,41 @@ func (h HealthHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	_, _ = w.Write([]byte(`{"status":"ok"}`))
 }
+
+// ReadyHandler reports whether all enabled collectors are initialized. Otherwise, it responds with
+// 503 Service Unavailable and the collectors that are not ready.
+type ReadyHandler struct {
+	handler *MetricsHTTPHandler
+}
+
+type readyStatus struct {
+	Status string `json:"status"`
+	// Collectors are the reasons why the collectors are not ready by collector name.
+	Collectors map[string]string `json:"collectors,omitempty"`
+}
+
+// Interface guard.
+var _ http.Handler = (*ReadyHandler)(nil)
+
+func NewReadyHandler(handler *MetricsHTTPHandler) ReadyHandler {
+	return ReadyHandler{handler: handler}
+}
+
+func (h ReadyHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	status := readyStatus{Status: "ok"}
+	statusCode := http.StatusOK
+
+	if notReady := h.handler.notReadyCollectors(); len(notReady) > 0 {
+		status.Status = "not ready"
+		status.Collectors = make(map[string]string, len(notReady))
+		statusCode = http.StatusServiceUnavailable
+
+		for name, err := range notReady {
+			status.Collectors[name] = err.Error()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(status)
+}
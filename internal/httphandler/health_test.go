@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadyHandler(t *testing.T) {
+	t.Parallel()
+
+	metricsHandler := New(slog.New(slog.DiscardHandler), collector.New(collector.Map{}), nil)
+
+	w := httptest.NewRecorder()
+	NewReadyHandler(metricsHandler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/-/ready", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
@@ -18,17 +18,26 @@
 package httphandler
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"regexp"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/prometheus-community/windows_exporter/internal/otlp"
+	"github.com/prometheus-community/windows_exporter/internal/relabel"
+	"github.com/prometheus-community/windows_exporter/internal/shutdown"
+	"github.com/prometheus-community/windows_exporter/internal/updatecheck"
 	"github.com/prometheus-community/windows_exporter/pkg/collector"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/collectors/version"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
 )
 
 // Interface guard.
@@ -37,10 +46,16 @@ var _ http.Handler = (*MetricsHTTPHandler)(nil)
 const defaultScrapeTimeout = 10.0
 
 type MetricsHTTPHandler struct {
+	// mu protects metricCollectors, relabelRules and profiles against a reload during a scrape.
+	mu               sync.RWMutex
 	metricCollectors *collector.Collection
+	relabelRules     *relabel.Rules
+	profiles         Profiles
 	// exporterMetricsRegistry is a separate registry for the metrics about
 	// the exporter itself.
 	exporterMetricsRegistry *prometheus.Registry
+	// counterRates is nil unless Options.CounterRates is set.
+	counterRates *counterRates
 
 	logger  *slog.Logger
 	options Options
@@ -49,6 +64,22 @@ type MetricsHTTPHandler struct {
 type Options struct {
 	DisableExporterMetrics bool
 	TimeoutMargin          float64
+	// CompatMetricNames is the release whose metric names are emitted next to the current names. Empty disables it.
+	CompatMetricNames string
+	// CompatMetricNamesExclude matches legacy metric names that are not emitted.
+	CompatMetricNamesExclude *regexp.Regexp
+	// RelabelRules are applied to the metrics of scrapes and pushes. Nil leaves the metrics unchanged.
+	RelabelRules *relabel.Rules
+	// Profiles are the named collector sets selectable per scrape. Nil disables profiles.
+	Profiles Profiles
+	// CounterRates matches the counters whose per-second rates between two scrapes are exported. Nil disables the rates.
+	CounterRates *regexp.Regexp
+	// UpdateChecker exports whether a newer release is available. Nil disables it.
+	UpdateChecker *updatecheck.Checker
+	// Tracer sends a trace of each scrape with a span per collector. Nil disables tracing.
+	Tracer *otlp.Tracer
+	// Shutdown exports the shutdown of Windows once it began. Nil disables it.
+	Shutdown *shutdown.Collector
 }
 
 func New(logger *slog.Logger, metricCollectors *collector.Collection, options *Options) *MetricsHTTPHandler {
@@ -61,10 +92,16 @@ func New(logger *slog.Logger, metricCollectors *collector.Collection, options *O
 
 	handler := &MetricsHTTPHandler{
 		metricCollectors: metricCollectors,
+		relabelRules:     options.RelabelRules,
+		profiles:         options.Profiles,
 		logger:           logger,
 		options:          *options,
 	}
 
+	if options.CounterRates != nil {
+		handler.counterRates = newCounterRates(options.CounterRates)
+	}
+
 	if !options.DisableExporterMetrics {
 		handler.exporterMetricsRegistry = prometheus.NewRegistry()
 		handler.exporterMetricsRegistry.MustRegister(
@@ -82,9 +119,60 @@ func (c *MetricsHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		slog.String("remote", r.RemoteAddr),
 	)
 
-	scrapeTimeout := c.getScrapeTimeout(logger, r)
+	scrapeTimeout := getScrapeTimeout(logger, r, c.options.TimeoutMargin)
+
+	var trace *otlp.ScrapeTrace
+	if c.options.Tracer != nil {
+		trace = c.options.Tracer.StartScrape(r)
+	}
+
+	if trace != nil {
+		defer trace.End()
+
+		logger = logger.With(slog.String("trace_id", trace.TraceID()))
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	requestedCollectors, filters, err := parseScrapeRequest(w, r)
+	if err != nil {
+		logger.Warn("Couldn't parse scrape request",
+			slog.Any("err", err),
+		)
+
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, "Couldn't parse scrape request: %s", err)
+
+		return
+	}
+
+	metricCollectors := c.metricCollectors
+
+	if name := profileName(r); name != "" {
+		requestedCollectors, filters, err = c.profiles.apply(name, requestedCollectors, filters)
+		if err != nil {
+			logger.Warn("Couldn't apply scrape profile",
+				slog.Any("err", err),
+			)
+
+			status := http.StatusBadRequest
+			if r.PathValue("profile") != "" {
+				status = http.StatusNotFound
+			}
+
+			w.WriteHeader(status)
+			_, _ = fmt.Fprintf(w, "Couldn't apply scrape profile: %s", err)
+
+			return
+		}
+
+		if collection := c.profiles.collection(name); collection != nil {
+			metricCollectors = collection
+		}
+	}
 
-	handler, err := c.handlerFactory(logger, scrapeTimeout, r.URL.Query()["collect[]"])
+	handler, err := c.handlerFactory(logger, scrapeTimeout, metricCollectors, requestedCollectors, filters, trace)
 	if err != nil {
 		logger.Warn("Couldn't create filtered metrics handler",
 			slog.Any("err", err),
@@ -99,7 +187,118 @@ func (c *MetricsHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	handler.ServeHTTP(w, r)
 }
 
-func (c *MetricsHTTPHandler) getScrapeTimeout(logger *slog.Logger, r *http.Request) time.Duration {
+// SetCollection replaces the collectors, e.g. after a reload of the configuration.
+// It waits for running scrapes and returns the previous collectors, which must be closed by the caller.
+// The maintenance mode of the previous collectors is kept.
+func (c *MetricsHTTPHandler) SetCollection(metricCollectors *collector.Collection) *collector.Collection {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previous := c.metricCollectors
+	metricCollectors.KeepMaintenance(previous)
+	c.metricCollectors = metricCollectors
+
+	return previous
+}
+
+// SetRelabelRules replaces the relabeling rules, e.g. after a reload of the configuration.
+func (c *MetricsHTTPHandler) SetRelabelRules(rules *relabel.Rules) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.relabelRules = rules
+}
+
+// SetProfiles replaces the scrape profiles, e.g. after a reload of the configuration.
+// It waits for running scrapes and returns the previous profiles, which must be closed by the caller.
+// The collectors of the profiles keep the maintenance mode of the current collection.
+func (c *MetricsHTTPHandler) SetProfiles(profiles Profiles) Profiles {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, compiled := range profiles {
+		if compiled.collection != nil {
+			compiled.collection.KeepMaintenance(c.metricCollectors)
+		}
+	}
+
+	previous := c.profiles
+	c.profiles = profiles
+
+	return previous
+}
+
+// SetCollectorDisabled disables or re-enables a collector of the current collection, see collector.Collection.SetDisabled.
+// The collector is also disabled in the collectors of the profiles that enable it. The change is lost on reload.
+func (c *MetricsHTTPHandler) SetCollectorDisabled(name string, disabled bool) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.metricCollectors.SetDisabled(name, disabled); err != nil {
+		return err
+	}
+
+	for _, compiled := range c.profiles {
+		if compiled.collection == nil {
+			continue
+		}
+
+		if err := compiled.collection.SetDisabled(name, disabled); err != nil && !errors.Is(err, collector.ErrCollectorNotEnabled) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetMaintenance enables or disables the maintenance mode of the current collection and the collectors of the profiles,
+// see collector.Collection.SetMaintenance.
+func (c *MetricsHTTPHandler) SetMaintenance(enabled bool, reason string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	c.metricCollectors.SetMaintenance(enabled, reason)
+
+	for _, compiled := range c.profiles {
+		if compiled.collection != nil {
+			compiled.collection.SetMaintenance(enabled, reason)
+		}
+	}
+}
+
+// maintenance returns the maintenance mode of the current collection.
+func (c *MetricsHTTPHandler) maintenance() collector.MaintenanceStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.metricCollectors.Maintenance()
+}
+
+// collectorStates returns the enabled collectors of the current collection and whether they are disabled at runtime.
+func (c *MetricsHTTPHandler) collectorStates() []adminCollector {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := c.metricCollectors.Collectors()
+	collectors := make([]adminCollector, 0, len(names))
+
+	for _, name := range names {
+		collectors = append(collectors, adminCollector{Name: name, Enabled: !c.metricCollectors.Disabled(name)})
+	}
+
+	return collectors
+}
+
+// notReadyCollectors returns the enabled collectors of the current collection that are not initialized, see
+// collector.Collection.NotReady.
+func (c *MetricsHTTPHandler) notReadyCollectors() map[string]error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.metricCollectors.NotReady()
+}
+
+func getScrapeTimeout(logger *slog.Logger, r *http.Request, timeoutMargin float64) time.Duration {
 	var timeoutSeconds float64
 
 	if v := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"); v != "" {
@@ -115,35 +314,121 @@ func (c *MetricsHTTPHandler) getScrapeTimeout(logger *slog.Logger, r *http.Reque
 		timeoutSeconds = defaultScrapeTimeout
 	}
 
-	timeoutSeconds -= c.options.TimeoutMargin
+	timeoutSeconds -= timeoutMargin
 
 	return time.Duration(timeoutSeconds*1e9) * time.Nanosecond
 }
 
-func (c *MetricsHTTPHandler) handlerFactory(logger *slog.Logger, scrapeTimeout time.Duration, requestedCollectors []string) (http.Handler, error) {
+// Gather collects the metrics of all enabled collectors and the exporter itself without an HTTP request,
+// e.g. to push them to an OpenTelemetry Collector.
+func (c *MetricsHTTPHandler) Gather(scrapeTimeout time.Duration) ([]*dto.MetricFamily, error) {
+	return c.gather(scrapeTimeout, nil, nil)
+}
+
+// gather collects the metrics of the requested collectors and the exporter itself, relabeled like a scrape.
+func (c *MetricsHTTPHandler) gather(scrapeTimeout time.Duration, requestedCollectors []string, filters map[string]collector.MetricFilter) ([]*dto.MetricFamily, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	gatherer, err := c.gatherer(scrapeTimeout, c.metricCollectors, requestedCollectors, filters, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.exporterMetricsRegistry != nil {
+		gatherer = prometheus.Gatherers{c.exporterMetricsRegistry, gatherer}
+	}
+
+	return relabel.NewGatherer(gatherer, c.relabelRules).Gather()
+}
+
+// GatherCollectors collects the metrics of the given collectors without the metrics of the exporter itself.
+// Collectors that are not enabled are ignored, e.g. to expose the metrics of a fixed set of collectors via SNMP.
+func (c *MetricsHTTPHandler) GatherCollectors(scrapeTimeout time.Duration, collectors []string) ([]*dto.MetricFamily, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	enabled := c.metricCollectors.Collectors()
+	collectors = slices.DeleteFunc(slices.Clone(collectors), func(name string) bool {
+		return !slices.Contains(enabled, name)
+	})
+
+	if len(collectors) == 0 {
+		return nil, nil
+	}
+
+	gatherer, err := c.gatherer(scrapeTimeout, c.metricCollectors, collectors, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return gatherer.Gather()
+}
+
+// gatherer returns the gatherer of a scrape. If trace is not nil, the collectors record their spans in the trace
+// and log its ID.
+func (c *MetricsHTTPHandler) gatherer(scrapeTimeout time.Duration, metricCollectors *collector.Collection, requestedCollectors []string, filters map[string]collector.MetricFilter, trace *otlp.ScrapeTrace) (prometheus.Gatherer, error) {
 	reg := prometheus.NewRegistry()
 	reg.MustRegister(version.NewCollector("windows_exporter"))
 
-	collectionHandler, err := c.metricCollectors.NewHandler(scrapeTimeout, c.logger, requestedCollectors)
+	if c.options.UpdateChecker != nil {
+		reg.MustRegister(c.options.UpdateChecker)
+	}
+
+	if c.options.Shutdown != nil {
+		reg.MustRegister(c.options.Shutdown)
+	}
+
+	logger := c.logger
+	if trace != nil {
+		logger = logger.With(slog.String("trace_id", trace.TraceID()))
+	}
+
+	collectionHandler, err := metricCollectors.NewHandlerWithFilters(scrapeTimeout, logger, requestedCollectors, filters)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't create collector handler: %w", err)
 	}
 
+	if trace != nil {
+		collectionHandler.SetSpanRecorder(trace.RecordCollector)
+	}
+
 	if err := reg.Register(collectionHandler); err != nil {
 		return nil, fmt.Errorf("couldn't register Prometheus collector: %w", err)
 	}
 
+	if renames, ok := legacyMetricNames[c.options.CompatMetricNames]; ok {
+		return compatGatherer{
+			gatherer: reg,
+			renames:  renames,
+			exclude:  c.options.CompatMetricNamesExclude,
+		}, nil
+	}
+
+	return reg, nil
+}
+
+func (c *MetricsHTTPHandler) handlerFactory(logger *slog.Logger, scrapeTimeout time.Duration, metricCollectors *collector.Collection, requestedCollectors []string, filters map[string]collector.MetricFilter, trace *otlp.ScrapeTrace) (http.Handler, error) {
+	gatherer, err := c.gatherer(scrapeTimeout, metricCollectors, requestedCollectors, filters, trace)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only scrapes update the rates, so pushes and other endpoints don't shorten the interval between two scrapes.
+	if c.counterRates != nil {
+		gatherer = rateGatherer{gatherer: gatherer, rates: c.counterRates}
+	}
+
 	var regHandler http.Handler
 	if c.exporterMetricsRegistry != nil {
 		regHandler = promhttp.HandlerFor(
-			prometheus.Gatherers{c.exporterMetricsRegistry, reg},
+			relabel.NewGatherer(prometheus.Gatherers{c.exporterMetricsRegistry, gatherer}, c.relabelRules),
 			promhttp.HandlerOpts{
-				ErrorLog:            slog.NewLogLogger(logger.Handler(), slog.LevelError),
-				ErrorHandling:       promhttp.ContinueOnError,
-				MaxRequestsInFlight: 1,
-				Registry:            c.exporterMetricsRegistry,
-				EnableOpenMetrics:   true,
-				ProcessStartTime:    c.metricCollectors.GetStartTime(),
+				ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
+				ErrorHandling:     promhttp.ContinueOnError,
+				Registry:          c.exporterMetricsRegistry,
+				EnableOpenMetrics: true,
+				ProcessStartTime:  c.metricCollectors.GetStartTime(),
 			},
 		)
 
@@ -154,13 +439,12 @@ func (c *MetricsHTTPHandler) handlerFactory(logger *slog.Logger, scrapeTimeout t
 		)
 	} else {
 		regHandler = promhttp.HandlerFor(
-			reg,
+			relabel.NewGatherer(gatherer, c.relabelRules),
 			promhttp.HandlerOpts{
-				ErrorLog:            slog.NewLogLogger(logger.Handler(), slog.LevelError),
-				ErrorHandling:       promhttp.ContinueOnError,
-				MaxRequestsInFlight: 1,
-				EnableOpenMetrics:   true,
-				ProcessStartTime:    c.metricCollectors.GetStartTime(),
+				ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
+				ErrorHandling:     promhttp.ContinueOnError,
+				EnableOpenMetrics: true,
+				ProcessStartTime:  c.metricCollectors.GetStartTime(),
 			},
 		)
 	}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// maxMaintenanceReasonLength limits the length of the reason, which is logged and returned by the API.
+const maxMaintenanceReasonLength = 256
+
+// MaintenanceHandler shows, enables and disables the maintenance mode of the host:
+//
+//	GET  /api/v1/maintenance
+//	POST /api/v1/maintenance/enable?reason=patching
+//	POST /api/v1/maintenance/disable
+//
+// Requests must send the admin token as bearer token.
+type MaintenanceHandler struct {
+	logger  *slog.Logger
+	handler *MetricsHTTPHandler
+	token   []byte
+}
+
+// Interface guard.
+var _ http.Handler = (*MaintenanceHandler)(nil)
+
+func NewMaintenanceHandler(logger *slog.Logger, handler *MetricsHTTPHandler, token []byte) MaintenanceHandler {
+	return MaintenanceHandler{
+		logger:  logger,
+		handler: handler,
+		token:   token,
+	}
+}
+
+func (h MaintenanceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, h.token) {
+		return
+	}
+
+	action := r.PathValue("action")
+	if action == "" {
+		h.writeStatus(w)
+
+		return
+	}
+
+	var enabled bool
+
+	switch action {
+	case "enable":
+		enabled = true
+	case "disable":
+		enabled = false
+	default:
+		http.Error(w, fmt.Sprintf("unknown action %s, expected enable or disable", action), http.StatusNotFound)
+
+		return
+	}
+
+	reason := r.URL.Query().Get("reason")
+	if len(reason) > maxMaintenanceReasonLength {
+		http.Error(w, fmt.Sprintf("reason is longer than %d bytes", maxMaintenanceReasonLength), http.StatusBadRequest)
+
+		return
+	}
+
+	h.handler.SetMaintenance(enabled, reason)
+
+	h.logger.Info("Changed maintenance mode",
+		slog.String("remote", r.RemoteAddr),
+		slog.Bool("enabled", enabled),
+		slog.String("reason", reason),
+	)
+
+	h.writeStatus(w)
+}
+
+func (h MaintenanceHandler) writeStatus(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(h.handler.maintenance())
+}
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceHandler(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	maintenanceHandler := NewMaintenanceHandler(slog.New(slog.DiscardHandler), nil, []byte("secret"))
+	mux.Handle("GET /api/v1/maintenance", maintenanceHandler)
+	mux.Handle("POST /api/v1/maintenance/{action}", maintenanceHandler)
+
+	for _, tc := range []struct {
+		name          string
+		path          string
+		authorization string
+		status        int
+	}{
+		{name: "no token", path: "/api/v1/maintenance/enable", status: http.StatusUnauthorized},
+		{name: "wrong token", path: "/api/v1/maintenance/enable", authorization: "Bearer wrong", status: http.StatusUnauthorized},
+		{name: "unknown action", path: "/api/v1/maintenance/pause", authorization: "Bearer secret", status: http.StatusNotFound},
+		{name: "reason too long", path: "/api/v1/maintenance/enable?reason=" + strings.Repeat("a", maxMaintenanceReasonLength+1), authorization: "Bearer secret", status: http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodPost, tc.path, nil)
+			if tc.authorization != "" {
+				r.Header.Set("Authorization", tc.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			require.Equal(t, tc.status, w.Code)
+		})
+	}
+}
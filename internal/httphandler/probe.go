@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Interface guard.
+var _ http.Handler = (*ProbeHTTPHandler)(nil)
+
+// ProbeHTTPHandler collects metrics from the remote host given by the target parameter.
+// Each request opens a new MI session to the target, which is closed after the scrape.
+type ProbeHTTPHandler struct {
+	logger  *slog.Logger
+	options ProbeOptions
+}
+
+type ProbeOptions struct {
+	Remote collector.RemoteOptions
+	// DefaultCollectors are used if the request doesn't contain collect[] parameters.
+	DefaultCollectors []string
+	TimeoutMargin     float64
+	// Targets matches the hosts that may be probed.
+	Targets *regexp.Regexp
+}
+
+func NewProbeHandler(logger *slog.Logger, options ProbeOptions) *ProbeHTTPHandler {
+	return &ProbeHTTPHandler{
+		logger:  logger,
+		options: options,
+	}
+}
+
+func (c *ProbeHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("target")
+
+	logger := c.logger.With(
+		slog.String("remote", r.RemoteAddr),
+		slog.String("target", target),
+	)
+
+	if target == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, "target parameter is missing")
+
+		return
+	}
+
+	if c.options.Targets != nil && !c.options.Targets.MatchString(target) {
+		logger.Warn("Rejected probe of target not matching --probe.targets")
+
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = fmt.Fprintf(w, "target %s is not allowed", target)
+
+		return
+	}
+
+	requestedCollectors := make([]string, 0)
+
+	for _, v := range r.URL.Query()["collect[]"] {
+		for name := range strings.SplitSeq(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				requestedCollectors = append(requestedCollectors, name)
+			}
+		}
+	}
+
+	if len(requestedCollectors) == 0 {
+		requestedCollectors = c.options.DefaultCollectors
+	}
+
+	scrapeTimeout := getScrapeTimeout(logger, r, c.options.TimeoutMargin)
+
+	remoteOptions := c.options.Remote
+	if remoteOptions.Timeout == 0 || remoteOptions.Timeout > scrapeTimeout {
+		remoteOptions.Timeout = scrapeTimeout
+	}
+
+	collection, err := collector.NewRemote(r.Context(), logger, target, remoteOptions, requestedCollectors)
+	if err != nil {
+		logger.Warn("Couldn't connect to target",
+			slog.Any("err", err),
+		)
+
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = fmt.Fprintf(w, "Couldn't connect to target %s: %s", target, err)
+
+		return
+	}
+
+	defer func() {
+		if err := collection.Close(); err != nil {
+			logger.Debug("Couldn't close remote collection",
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	collectionHandler, err := collection.NewHandler(scrapeTimeout, logger, nil)
+	if err != nil {
+		logger.Warn("Couldn't create metrics handler",
+			slog.Any("err", err),
+		)
+
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "Couldn't create metrics handler: %s", err)
+
+		return
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectionHandler)
+
+	promhttp.HandlerFor(
+		reg,
+		promhttp.HandlerOpts{
+			ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
+			ErrorHandling:     promhttp.ContinueOnError,
+			EnableOpenMetrics: true,
+		},
+	).ServeHTTP(w, r)
+}
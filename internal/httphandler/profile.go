@@ -0,0 +1,285 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+	"go.yaml.in/yaml/v3"
+)
+
+//nolint:gochecknoglobals
+var profileNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ProfilesConfig is the content of the profiles file, see --web.profiles-file, and the profiles section
+// of the configuration file, e.g.
+//
+//	profiles:
+//	  minimal:
+//	    collectors: [cpu, memory, logical_disk]
+//	  sql:
+//	    collectors: ["[defaults]", mssql]
+//	    filters:
+//	      service: {include: {name: "MSSQL.*|SQLAgent.*"}}
+//	  iis:
+//	    collectors: ["[defaults]", iis]
+//	    collector:
+//	      iis:
+//	        site-include: "Default Web Site"
+type ProfilesConfig struct {
+	Profiles map[string]ProfileConfig `yaml:"profiles"`
+}
+
+// ProfileConfig selects the collectors and filters of a scrape like the collect[] and exclude[] parameters
+// and the filters of a POST request, see scrapeRequest. Collectors may contain [defaults] like --collectors.enabled.
+// Collector are the settings of the collectors like the collector section of the configuration file.
+type ProfileConfig struct {
+	Collectors []string                 `yaml:"collectors"`
+	Exclude    []string                 `yaml:"exclude"`
+	Filters    map[string]ProfileFilter `yaml:"filters"`
+	Collector  ProfileSettings          `yaml:"collector"`
+}
+
+type ProfileFilter struct {
+	Include map[string]string `yaml:"include"`
+	Exclude map[string]string `yaml:"exclude"`
+}
+
+// ProfileSettings are the flattenable values of the collector settings of a profile, e.g. {"service": {"include": "MSSQL.*"}}.
+// They are validated like the collector section of the configuration file.
+type ProfileSettings map[string]any
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (s *ProfileSettings) UnmarshalYAML(node *yaml.Node) error {
+	content, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+
+	var config collector.Config
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid collector settings: %w", err)
+	}
+
+	var settings map[string]any
+	if err := node.Decode(&settings); err != nil {
+		return fmt.Errorf("invalid collector settings: %w", err)
+	}
+
+	*s = settings
+
+	return nil
+}
+
+// NewCollectionFunc builds the collectors of a profile with collector settings. A nil collection collects
+// the profile with the collectors of the handler, e.g. for the profile of the configuration file selected at startup.
+type NewCollectionFunc func(name string, profile ProfileConfig) (*collector.Collection, error)
+
+// Profiles are the compiled profiles by name. A nil Profiles has no profiles.
+type Profiles map[string]profile
+
+type profile struct {
+	collectors []string
+	filters    map[string]collector.MetricFilter
+	// collection collects the profile with its collector settings. Nil for profiles without collector settings.
+	collection *collector.Collection
+}
+
+// LoadProfiles reads the profiles file and adds the base profiles, e.g. the profiles of the configuration file.
+// newCollection builds the collectors of the profiles with collector settings.
+// An empty path without base profiles returns nil profiles.
+func LoadProfiles(path string, base ProfilesConfig, newCollection NewCollectionFunc) (Profiles, error) {
+	if path == "" {
+		if len(base.Profiles) == 0 {
+			return nil, nil
+		}
+
+		return NewProfiles(base, newCollection)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open profiles file: %w", err)
+	}
+
+	defer file.Close()
+
+	var config ProfilesConfig
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	// An empty file is a valid configuration without profiles.
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse profiles file: %w", err)
+	}
+
+	for name, profileConfig := range base.Profiles {
+		if _, ok := config.Profiles[name]; ok {
+			return nil, fmt.Errorf("profile %s is defined in the profiles file and the configuration file", name)
+		}
+
+		if config.Profiles == nil {
+			config.Profiles = make(map[string]ProfileConfig, len(base.Profiles))
+		}
+
+		config.Profiles[name] = profileConfig
+	}
+
+	return NewProfiles(config, newCollection)
+}
+
+// NewProfiles compiles the profiles of the configuration. newCollection builds the collectors of the profiles
+// with collector settings, which are closed by Close.
+func NewProfiles(config ProfilesConfig, newCollection NewCollectionFunc) (Profiles, error) {
+	profiles, err := compileProfiles(config)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(config.Profiles)) {
+		if len(config.Profiles[name].Collector) == 0 {
+			continue
+		}
+
+		collection, err := newCollection(name, config.Profiles[name])
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("profile %s: couldn't initialize collectors: %w", name, err), profiles.Close())
+		}
+
+		compiled := profiles[name]
+		compiled.collection = collection
+		profiles[name] = compiled
+	}
+
+	return profiles, nil
+}
+
+func compileProfiles(config ProfilesConfig) (Profiles, error) {
+	profiles := make(Profiles, len(config.Profiles))
+
+	for name, profileConfig := range config.Profiles {
+		if !profileNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid profile name %q, expected letters, digits, _ and -", name)
+		}
+
+		if len(profileConfig.Collectors) == 0 && len(profileConfig.Exclude) == 0 && len(profileConfig.Collector) == 0 {
+			return nil, fmt.Errorf("profile %s: collectors, exclude or collector are required", name)
+		}
+
+		compiled := profile{
+			collectors: appendCollectors(nil, expandDefaultCollectors(profileConfig.Collectors), profileConfig.Exclude),
+			filters:    make(map[string]collector.MetricFilter, len(profileConfig.Filters)),
+		}
+
+		for collectorName, filter := range profileConfig.Filters {
+			var (
+				metricFilter collector.MetricFilter
+				err          error
+			)
+
+			if metricFilter.Include, err = compileLabelFilters(filter.Include); err != nil {
+				return nil, fmt.Errorf("profile %s: invalid include filter of collector %s: %w", name, collectorName, err)
+			}
+
+			if metricFilter.Exclude, err = compileLabelFilters(filter.Exclude); err != nil {
+				return nil, fmt.Errorf("profile %s: invalid exclude filter of collector %s: %w", name, collectorName, err)
+			}
+
+			compiled.filters[collectorName] = metricFilter
+		}
+
+		profiles[name] = compiled
+	}
+
+	return profiles, nil
+}
+
+// Close closes the collectors of the profiles with collector settings.
+func (p Profiles) Close() error {
+	errs := make([]error, 0)
+
+	for name, compiled := range p {
+		if compiled.collection == nil {
+			continue
+		}
+
+		if err := compiled.collection.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("profile %s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// expandDefaultCollectors replaces [defaults] with the default collectors, like --collectors.enabled.
+func expandDefaultCollectors(collectors []string) []string {
+	expanded := make([]string, 0, len(collectors))
+
+	for _, name := range collectors {
+		if name == "[defaults]" {
+			expanded = append(expanded, strings.Split(collector.DefaultCollectors, ",")...)
+
+			continue
+		}
+
+		expanded = append(expanded, name)
+	}
+
+	return expanded
+}
+
+// profileName returns the profile of the request, either from the path, e.g. /metrics/sql, or the profile query parameter.
+func profileName(r *http.Request) string {
+	return cmp.Or(r.PathValue("profile"), r.URL.Query().Get("profile"))
+}
+
+// apply adds the collectors and filters of the profile to those of the request.
+// Filters of the request replace the filters of the profile for the same collector.
+func (p Profiles) apply(name string, collectors []string, filters map[string]collector.MetricFilter) ([]string, map[string]collector.MetricFilter, error) {
+	selected, ok := p[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown profile %q", name)
+	}
+
+	merged := maps.Clone(selected.filters)
+	maps.Copy(merged, filters)
+
+	return appendCollectors(slices.Clone(selected.collectors), collectors, nil), merged, nil
+}
+
+// collection returns the collectors of a profile with collector settings, or nil if the profile is
+// collected with the collectors of the handler.
+func (p Profiles) collection(name string) *collector.Collection {
+	return p[name].collection
+}
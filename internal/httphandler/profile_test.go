@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "profiles.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+profiles:
+  minimal:
+    collectors: [cpu, memory]
+  sql:
+    collectors: [mssql, service]
+    exclude: [process]
+    filters:
+      service:
+        include: {name: "MSSQL.*"}
+`), 0o600))
+
+	profiles, err := LoadProfiles(path, ProfilesConfig{}, nil)
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+	require.Equal(t, []string{"mssql", "service", "!process"}, profiles["sql"].collectors)
+	require.True(t, profiles["sql"].filters["service"].Include["name"].MatchString("MSSQL$SQLEXPRESS"))
+	require.False(t, profiles["sql"].filters["service"].Include["name"].MatchString("wuauserv"))
+
+	profiles, err = LoadProfiles("", ProfilesConfig{}, nil)
+	require.NoError(t, err)
+	require.Nil(t, profiles)
+
+	base := ProfilesConfig{Profiles: map[string]ProfileConfig{"domain-controller": {Collectors: []string{"ad", "dns"}}}}
+
+	profiles, err = LoadProfiles("", base, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ad", "dns"}, profiles["domain-controller"].collectors)
+
+	profiles, err = LoadProfiles(path, base, nil)
+	require.NoError(t, err)
+	require.Len(t, profiles, 3)
+
+	_, err = LoadProfiles(path, ProfilesConfig{Profiles: map[string]ProfileConfig{"sql": {Collectors: []string{"mssql"}}}}, nil)
+	require.Error(t, err)
+
+	for name, config := range map[string]string{
+		"unknown field":              "profiles:\n  sql:\n    collecters: [mssql]\n",
+		"invalid collector settings": "profiles:\n  sql:\n    collector: [mssql]\n",
+		"unknown collector setting":  "profiles:\n  sql:\n    collector: {service: {includ: MSSQL.*}}\n",
+		"invalid name":               "profiles:\n  sql/full:\n    collectors: [mssql]\n",
+		"no collectors":              "profiles:\n  sql:\n    filters: {service: {include: {name: MSSQL.*}}}\n",
+		"invalid regex":              "profiles:\n  sql:\n    collectors: [service]\n    filters: {service: {include: {name: \"(\"}}}\n",
+	} {
+		require.NoError(t, os.WriteFile(path, []byte(config), 0o600))
+
+		_, err := LoadProfiles(path, ProfilesConfig{}, nil)
+		require.Error(t, err, name)
+	}
+}
+
+func TestLoadProfilesCollectorSettings(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "profiles.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+profiles:
+  minimal:
+    collectors: [cpu, memory]
+  sql:
+    collectors: ["[defaults]", mssql]
+    collector:
+      service:
+        include: "MSSQL.*"
+  wsus:
+    collector:
+      service:
+        include: "WsusService"
+`), 0o600))
+
+	built := make(map[string]ProfileConfig)
+
+	profiles, err := LoadProfiles(path, ProfilesConfig{}, func(name string, profile ProfileConfig) (*collector.Collection, error) {
+		built[name] = profile
+
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, profiles, 3)
+
+	// Only the profiles with collector settings have their own collectors. Profiles without collectors
+	// collect all collectors of their collection.
+	require.Equal(t, []string{"sql", "wsus"}, slices.Sorted(maps.Keys(built)))
+	require.Equal(t, ProfileSettings{"service": map[string]any{"include": "MSSQL.*"}}, built["sql"].Collector)
+	require.Equal(t, append(strings.Split(collector.DefaultCollectors, ","), "mssql"), profiles["sql"].collectors)
+	require.Empty(t, profiles["wsus"].collectors)
+
+	_, err = LoadProfiles(path, ProfilesConfig{}, func(string, ProfileConfig) (*collector.Collection, error) {
+		return nil, errors.New("access denied")
+	})
+	require.ErrorContains(t, err, "access denied")
+}
+
+func TestProfilesApply(t *testing.T) {
+	t.Parallel()
+
+	profiles, err := NewProfiles(ProfilesConfig{Profiles: map[string]ProfileConfig{
+		"sql": {
+			Collectors: []string{"mssql", "service"},
+			Filters: map[string]ProfileFilter{
+				"service":      {Include: map[string]string{"name": "MSSQL.*"}},
+				"logical_disk": {Exclude: map[string]string{"volume": "HarddiskVolume.*"}},
+			},
+		},
+	}}, nil)
+	require.NoError(t, err)
+
+	requestFilter := collector.MetricFilter{Include: map[string]*regexp.Regexp{"name": regexp.MustCompile("^(?:SQLAgent.*)$")}}
+
+	collectors, filters, err := profiles.apply("sql", []string{"cpu", "service", "!mssql"}, map[string]collector.MetricFilter{"service": requestFilter})
+	require.NoError(t, err)
+	require.Equal(t, []string{"mssql", "service", "cpu", "!mssql"}, collectors)
+	require.Equal(t, requestFilter, filters["service"])
+	require.Contains(t, filters, "logical_disk")
+
+	// The profile must not be changed by the request.
+	require.Equal(t, []string{"mssql", "service"}, profiles["sql"].collectors)
+	require.True(t, profiles["sql"].filters["service"].Include["name"].MatchString("MSSQLSERVER"))
+
+	_, _, err = profiles.apply("hyperv", nil, nil)
+	require.ErrorContains(t, err, "unknown profile")
+
+	var noProfiles Profiles
+
+	_, _, err = noProfiles.apply("sql", nil, nil)
+	require.ErrorContains(t, err, "unknown profile")
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// staleCounterAge is the age after which the value of a counter of a previous scrape is dropped,
+// e.g. of a process that exited.
+const staleCounterAge = time.Hour
+
+// counterRates keeps the values of the counters of the previous scrape, so the per-second rates of the
+// counters between two scrapes can be exported. It is shared by all scrapes of a handler.
+type counterRates struct {
+	// match matches the names of the counters whose rates are exported.
+	match *regexp.Regexp
+
+	mu sync.Mutex
+	// samples are the values of the counters of the previous scrapes by series.
+	samples map[string]counterSample
+}
+
+type counterSample struct {
+	value float64
+	time  time.Time
+}
+
+func newCounterRates(match *regexp.Regexp) *counterRates {
+	return &counterRates{
+		match:   match,
+		samples: make(map[string]counterSample),
+	}
+}
+
+// apply returns the metric families with a gauge family <name>_per_second for each matching counter family <name>_total.
+// The rate of a series is exported from the second scrape on. A counter that decreased was reset, e.g. by a restart
+// of the service it counts, so its rate is calculated from 0 like rate() of PromQL does.
+func (r *counterRates) apply(metricFamilies []*dto.MetricFamily, now time.Time) []*dto.MetricFamily {
+	names := make(map[string]struct{}, len(metricFamilies))
+	for _, metricFamily := range metricFamilies {
+		names[metricFamily.GetName()] = struct{}{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rateFamilies := make([]*dto.MetricFamily, 0)
+
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetType() != dto.MetricType_COUNTER || !r.match.MatchString(metricFamily.GetName()) {
+			continue
+		}
+
+		rateName := strings.TrimSuffix(metricFamily.GetName(), "_total") + "_per_second"
+		// Never replace a metric of a collector.
+		if _, ok := names[rateName]; ok {
+			continue
+		}
+
+		rates := make([]*dto.Metric, 0, len(metricFamily.GetMetric()))
+
+		for _, metric := range metricFamily.GetMetric() {
+			key := metricFamily.GetName() + "\xff" + seriesKey(metric)
+			value := metric.GetCounter().GetValue()
+
+			previous, ok := r.samples[key]
+			r.samples[key] = counterSample{value: value, time: now}
+
+			if !ok || !now.After(previous.time) {
+				continue
+			}
+
+			increase := value - previous.value
+			if increase < 0 {
+				increase = value
+			}
+
+			rate := increase / now.Sub(previous.time).Seconds()
+
+			rates = append(rates, &dto.Metric{
+				Label: metric.GetLabel(),
+				Gauge: &dto.Gauge{Value: &rate},
+			})
+		}
+
+		if len(rates) == 0 {
+			continue
+		}
+
+		help := "Per-second rate of " + metricFamily.GetName() + " since the previous scrape. " + metricFamily.GetHelp()
+
+		rateFamilies = append(rateFamilies, &dto.MetricFamily{
+			Name:   &rateName,
+			Help:   &help,
+			Type:   dto.MetricType_GAUGE.Enum(),
+			Metric: rates,
+		})
+	}
+
+	for key, sample := range r.samples {
+		if now.Sub(sample.time) > staleCounterAge {
+			delete(r.samples, key)
+		}
+	}
+
+	if len(rateFamilies) == 0 {
+		return metricFamilies
+	}
+
+	metricFamilies = append(metricFamilies, rateFamilies...)
+
+	slices.SortFunc(metricFamilies, func(a, b *dto.MetricFamily) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+
+	return metricFamilies
+}
+
+// rateGatherer adds the per-second rates of the matching counters to the metric families of a scrape.
+type rateGatherer struct {
+	gatherer prometheus.Gatherer
+	rates    *counterRates
+}
+
+func (g rateGatherer) Gather() ([]*dto.MetricFamily, error) {
+	metricFamilies, err := g.gatherer.Gather()
+	if len(metricFamilies) == 0 {
+		return metricFamilies, err
+	}
+
+	return g.rates.apply(metricFamilies, time.Now()), err
+}
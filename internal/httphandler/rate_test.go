@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounterRates(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+
+	received := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "windows_net_bytes_received_total", Help: "Bytes received"}, []string{"nic"})
+	sent := prometheus.NewCounter(prometheus.CounterOpts{Name: "windows_net_bytes_sent_total", Help: "Bytes sent"})
+	existing := prometheus.NewGauge(prometheus.GaugeOpts{Name: "windows_net_bytes_sent_per_second", Help: "Exposed by a collector"})
+	other := prometheus.NewCounter(prometheus.CounterOpts{Name: "windows_cpu_interrupts_total", Help: "Interrupts"})
+
+	reg.MustRegister(received, sent, existing, other)
+
+	rates := newCounterRates(regexp.MustCompile("^(?:windows_net_.+)$"))
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	gather := func(now time.Time) map[string]*dto.MetricFamily {
+		t.Helper()
+
+		metricFamilies, err := reg.Gather()
+		require.NoError(t, err)
+
+		families := make(map[string]*dto.MetricFamily)
+		for _, metricFamily := range rates.apply(metricFamilies, now) {
+			families[metricFamily.GetName()] = metricFamily
+		}
+
+		return families
+	}
+
+	received.WithLabelValues("eth0").Add(1000)
+
+	families := gather(start)
+	require.NotContains(t, families, "windows_net_bytes_received_per_second")
+
+	received.WithLabelValues("eth0").Add(3000)
+
+	families = gather(start.Add(10 * time.Second))
+	require.Contains(t, families, "windows_net_bytes_received_per_second")
+	require.Equal(t, dto.MetricType_GAUGE, families["windows_net_bytes_received_per_second"].GetType())
+	require.InDelta(t, 300.0, families["windows_net_bytes_received_per_second"].GetMetric()[0].GetGauge().GetValue(), 1e-9)
+	require.Equal(t, "eth0", families["windows_net_bytes_received_per_second"].GetMetric()[0].GetLabel()[0].GetValue())
+	require.Equal(t, "Exposed by a collector", families["windows_net_bytes_sent_per_second"].GetHelp())
+	require.NotContains(t, families, "windows_cpu_interrupts_per_second")
+
+	// A counter that decreased was reset, so its rate is calculated from 0.
+	reg.Unregister(received)
+
+	received = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "windows_net_bytes_received_total", Help: "Bytes received"}, []string{"nic"})
+	received.WithLabelValues("eth0").Add(50)
+	reg.MustRegister(received)
+
+	families = gather(start.Add(20 * time.Second))
+	require.InDelta(t, 5.0, families["windows_net_bytes_received_per_second"].GetMetric()[0].GetGauge().GetValue(), 1e-9)
+}
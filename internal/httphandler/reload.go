@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// ReloadHandler reloads the configuration of windows_exporter.
+type ReloadHandler struct {
+	logger *slog.Logger
+	reload func(ctx context.Context) error
+}
+
+// Interface guard.
+var _ http.Handler = (*ReloadHandler)(nil)
+
+func NewReloadHandler(logger *slog.Logger, reload func(ctx context.Context) error) ReloadHandler {
+	return ReloadHandler{
+		logger: logger,
+		reload: reload,
+	}
+}
+
+func (h ReloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h.reload(r.Context()); err != nil {
+		h.logger.Warn("Couldn't reload configuration",
+			slog.String("remote", r.RemoteAddr),
+			slog.Any("err", err),
+		)
+
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "Couldn't reload configuration: %s", err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
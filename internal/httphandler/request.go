@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+)
+
+// maxRequestBodySize limits the size of POST bodies, which only contain collector names and filters.
+const maxRequestBodySize = 1 << 20
+
+// scrapeRequest is the JSON body of a POST request, e.g.
+//
+//	{
+//	  "collectors": ["service", "logical_disk"],
+//	  "exclude": ["mssql"],
+//	  "filters": {
+//	    "service": {"include": {"name": "wuauserv|mssql.*"}},
+//	    "logical_disk": {"exclude": {"volume": "HarddiskVolume.*"}}
+//	  }
+//	}
+type scrapeRequest struct {
+	Collectors []string                       `json:"collectors"`
+	Exclude    []string                       `json:"exclude"`
+	Filters    map[string]scrapeRequestFilter `json:"filters"`
+}
+
+type scrapeRequestFilter struct {
+	Include map[string]string `json:"include"`
+	Exclude map[string]string `json:"exclude"`
+}
+
+// parseScrapeRequest returns the requested collectors and filters. GET requests select collectors with
+// collect[] and exclude[] query parameters. POST requests additionally accept a JSON or form body with filters.
+// Form bodies use collect[] and exclude[] for collectors and <collector>.include.<label> or <collector>.exclude.<label> for filters.
+// Excluded collectors are returned with the "!" prefix of the negation syntax, which can also be used in collect[].
+func parseScrapeRequest(w http.ResponseWriter, r *http.Request) ([]string, map[string]collector.MetricFilter, error) {
+	collectors := appendCollectors(nil, r.URL.Query()["collect[]"], r.URL.Query()["exclude[]"])
+
+	if r.Method != http.MethodPost {
+		return collectors, nil, nil
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid Content-Type: %w", err)
+	}
+
+	var request scrapeRequest
+
+	switch mediaType {
+	case "application/json":
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&request); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode JSON body: %w", err)
+		}
+	case "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse form body: %w", err)
+		}
+
+		request, err = parseScrapeRequestForm(r)
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported Content-Type %s, expected application/json or application/x-www-form-urlencoded", mediaType)
+	}
+
+	collectors = appendCollectors(collectors, request.Collectors, request.Exclude)
+
+	filters := make(map[string]collector.MetricFilter, len(request.Filters))
+
+	for name, filter := range request.Filters {
+		metricFilter := collector.MetricFilter{}
+
+		if metricFilter.Include, err = compileLabelFilters(filter.Include); err != nil {
+			return nil, nil, fmt.Errorf("invalid include filter of collector %s: %w", name, err)
+		}
+
+		if metricFilter.Exclude, err = compileLabelFilters(filter.Exclude); err != nil {
+			return nil, nil, fmt.Errorf("invalid exclude filter of collector %s: %w", name, err)
+		}
+
+		filters[name] = metricFilter
+	}
+
+	return collectors, filters, nil
+}
+
+// appendCollectors appends the included and the negated excluded collectors, which are not yet in collectors.
+func appendCollectors(collectors, included, excluded []string) []string {
+	for _, name := range included {
+		if !slices.Contains(collectors, name) {
+			collectors = append(collectors, name)
+		}
+	}
+
+	for _, name := range excluded {
+		if !slices.Contains(collectors, "!"+name) {
+			collectors = append(collectors, "!"+name)
+		}
+	}
+
+	return collectors
+}
+
+func parseScrapeRequestForm(r *http.Request) (scrapeRequest, error) {
+	request := scrapeRequest{
+		Collectors: r.PostForm["collect[]"],
+		Exclude:    r.PostForm["exclude[]"],
+		Filters:    make(map[string]scrapeRequestFilter),
+	}
+
+	for key, values := range r.PostForm {
+		if key == "collect[]" || key == "exclude[]" {
+			continue
+		}
+
+		name, rest, ok := strings.Cut(key, ".")
+		if !ok {
+			return request, fmt.Errorf("unknown form field %s", key)
+		}
+
+		mode, label, ok := strings.Cut(rest, ".")
+		if !ok || label == "" {
+			return request, fmt.Errorf("invalid filter %s, expected <collector>.include.<label> or <collector>.exclude.<label>", key)
+		}
+
+		if len(values) != 1 {
+			return request, fmt.Errorf("filter %s must be set once", key)
+		}
+
+		filter := request.Filters[name]
+
+		switch mode {
+		case "include":
+			if filter.Include == nil {
+				filter.Include = make(map[string]string)
+			}
+
+			filter.Include[label] = values[0]
+		case "exclude":
+			if filter.Exclude == nil {
+				filter.Exclude = make(map[string]string)
+			}
+
+			filter.Exclude[label] = values[0]
+		default:
+			return request, fmt.Errorf("invalid filter %s, expected <collector>.include.<label> or <collector>.exclude.<label>", key)
+		}
+
+		request.Filters[name] = filter
+	}
+
+	return request, nil
+}
+
+// compileLabelFilters compiles the expressions of the label names. Like the collector flags,
+// the expressions must match the whole label value.
+func compileLabelFilters(filters map[string]string) (map[string]*regexp.Regexp, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+
+	compiled := make(map[string]*regexp.Regexp, len(filters))
+
+	for label, expr := range filters {
+		if label == "" {
+			return nil, errors.New("label name is empty")
+		}
+
+		re, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", expr))
+		if err != nil {
+			return nil, fmt.Errorf("label %s: %w", label, err)
+		}
+
+		compiled[label] = re
+	}
+
+	return compiled, nil
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package httphandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScrapeRequest(t *testing.T) {
+	t.Parallel()
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodPost, "/metrics?collect[]=os", strings.NewReader(`{
+			"collectors": ["service", "logical_disk"],
+			"filters": {
+				"service": {"include": {"name": "wuauserv|mssql.*"}},
+				"logical_disk": {"exclude": {"volume": "HarddiskVolume.*"}}
+			}
+		}`))
+		r.Header.Set("Content-Type", "application/json")
+
+		collectors, filters, err := parseScrapeRequest(httptest.NewRecorder(), r)
+		require.NoError(t, err)
+		require.Equal(t, []string{"os", "service", "logical_disk"}, collectors)
+
+		require.True(t, filters["service"].Include["name"].MatchString("mssql$sqlexpress"))
+		require.False(t, filters["service"].Include["name"].MatchString("wuauserv2"))
+		require.True(t, filters["logical_disk"].Exclude["volume"].MatchString("HarddiskVolume1"))
+		require.False(t, filters["logical_disk"].Exclude["volume"].MatchString("C:"))
+	})
+
+	t.Run("exclude", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodGet, "/metrics?exclude[]=mssql&collect[]=!service&exclude[]=service", nil)
+
+		collectors, filters, err := parseScrapeRequest(httptest.NewRecorder(), r)
+		require.NoError(t, err)
+		require.Equal(t, []string{"!service", "!mssql"}, collectors)
+		require.Nil(t, filters)
+	})
+
+	t.Run("form", func(t *testing.T) {
+		t.Parallel()
+
+		form := url.Values{
+			"collect[]":             {"service"},
+			"exclude[]":             {"mssql"},
+			"service.include.name":  {"wuauserv"},
+			"service.exclude.state": {"stopped"},
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/metrics", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		collectors, filters, err := parseScrapeRequest(httptest.NewRecorder(), r)
+		require.NoError(t, err)
+		require.Equal(t, []string{"service", "!mssql"}, collectors)
+		require.True(t, filters["service"].Include["name"].MatchString("wuauserv"))
+		require.True(t, filters["service"].Exclude["state"].MatchString("stopped"))
+	})
+
+	for name, tc := range map[string]struct {
+		contentType string
+		body        string
+	}{
+		"unknown json field":   {"application/json", `{"collector": ["os"]}`},
+		"invalid regexp":       {"application/json", `{"filters": {"service": {"include": {"name": "("}}}}`},
+		"invalid form filter":  {"application/x-www-form-urlencoded", "service.match.name=x"},
+		"unknown form field":   {"application/x-www-form-urlencoded", "service=x"},
+		"unsupported encoding": {"text/plain", "service"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodPost, "/metrics", strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", tc.contentType)
+
+			_, _, err := parseScrapeRequest(httptest.NewRecorder(), r)
+			require.Error(t, err)
+		})
+	}
+}
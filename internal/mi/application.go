@@ -29,6 +29,24 @@ import (
 
 const (
 	LocaleEnglish = "en-us"
+
+	// ProtocolWinRM connects to remote hosts via WS-Management.
+	ProtocolWinRM = "WINRM"
+	// ProtocolWMIDCOM connects to remote hosts via DCOM.
+	ProtocolWMIDCOM = "WMIDCOM"
+)
+
+// defaultOperationTimeout is the timeout of operations without explicit operation options.
+const defaultOperationTimeout = 5 * time.Second
+
+// Authentication types of remote sessions.
+//
+// https://learn.microsoft.com/en-us/windows/win32/api/mi/ns-mi-mi_usercredentials
+const (
+	AuthenticationTypeDefault   = "Default"
+	AuthenticationTypeNegotiate = "NegoWithCreds"
+	AuthenticationTypeKerberos  = "Kerberos"
+	AuthenticationTypeNTLM      = "NtlmDomain"
 )
 
 //nolint:gochecknoglobals
@@ -43,6 +61,11 @@ var (
 	//
 	// https://github.com/microsoft/win32metadata/blob/527806d20d83d3abd43d16cd3fa8795d8deba343/generation/WinSDK/RecompiledIdlHeaders/um/mi.h#L8248
 	destinationOptionsUILocale = UTF16PtrFromString[*uint16]("__MI_DESTINATIONOPTIONS_UI_LOCALE")
+
+	// destinationOptionsCredentials is the key for the credentials of the destination.
+	//
+	// https://learn.microsoft.com/en-us/windows/win32/api/mi/nf-mi-mi_destinationoptions_addcredentials
+	destinationOptionsCredentials = UTF16PtrFromString[*uint16]("__MI_DESTINATIONOPTIONS_DESTINATION_CREDENTIALS")
 )
 
 //nolint:gochecknoglobals
@@ -100,6 +123,17 @@ type DestinationOptionsFT struct {
 	GetInterval              uintptr
 }
 
+// userCredentials represents MI_UserCredentials with username and password credentials.
+// The credentials union is laid out as MI_UsernamePasswordCreds, its largest member.
+//
+// https://learn.microsoft.com/en-us/windows/win32/api/mi/ns-mi-mi_usercredentials
+type userCredentials struct {
+	authenticationType *uint16
+	domain             *uint16
+	username           *uint16
+	password           *uint16
+}
+
 // ApplicationInitialize initializes the MI [Application].
 // It is recommended to have only one Application per process.
 //
@@ -145,6 +179,28 @@ func (application *Application) Close() error {
 //
 // https://learn.microsoft.com/en-us/windows/win32/api/mi/nf-mi-mi_application_newsession
 func (application *Application) NewSession(options *DestinationOptions) (*Session, error) {
+	return application.newSession(nil, nil, options)
+}
+
+// NewRemoteSession creates a session to the remote destination using the given protocol,
+// either [ProtocolWinRM] or [ProtocolWMIDCOM].
+//
+// https://learn.microsoft.com/en-us/windows/win32/api/mi/nf-mi-mi_application_newsession
+func (application *Application) NewRemoteSession(protocol, destination string, options *DestinationOptions) (*Session, error) {
+	protocolUTF16, err := windows.UTF16PtrFromString(protocol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert protocol: %w", err)
+	}
+
+	destinationUTF16, err := windows.UTF16PtrFromString(destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert destination: %w", err)
+	}
+
+	return application.newSession(protocolUTF16, destinationUTF16, options)
+}
+
+func (application *Application) newSession(protocol, destination *uint16, options *DestinationOptions) (*Session, error) {
 	if application == nil || application.ft == nil {
 		return nil, ErrNotInitialized
 	}
@@ -154,8 +210,8 @@ func (application *Application) NewSession(options *DestinationOptions) (*Sessio
 	r0, _, _ := syscall.SyscallN(
 		application.ft.NewSession,
 		uintptr(unsafe.Pointer(application)),
-		0,
-		0,
+		uintptr(unsafe.Pointer(protocol)),
+		uintptr(unsafe.Pointer(destination)),
 		uintptr(unsafe.Pointer(options)),
 		0,
 		0,
@@ -171,7 +227,7 @@ func (application *Application) NewSession(options *DestinationOptions) (*Sessio
 		return nil, fmt.Errorf("failed to create default operation options: %w", err)
 	}
 
-	if err = defaultOperationOptions.SetTimeout(5 * time.Second); err != nil {
+	if err = defaultOperationOptions.SetTimeout(defaultOperationTimeout); err != nil {
 		return nil, fmt.Errorf("failed to set timeout: %w", err)
 	}
 
@@ -279,6 +335,48 @@ func (do *DestinationOptions) SetLocale(locale string) error {
 	return nil
 }
 
+// AddCredentials sets the username and password used to authenticate to the destination.
+//
+// https://learn.microsoft.com/en-us/windows/win32/api/mi/nf-mi-mi_destinationoptions_addcredentials
+func (do *DestinationOptions) AddCredentials(authenticationType, domain, username, password string) error {
+	if do == nil || do.ft == nil {
+		return ErrNotInitialized
+	}
+
+	credentials := userCredentials{}
+
+	for _, value := range []struct {
+		dst **uint16
+		src string
+	}{
+		{&credentials.authenticationType, authenticationType},
+		{&credentials.domain, domain},
+		{&credentials.username, username},
+		{&credentials.password, password},
+	} {
+		ptr, err := windows.UTF16PtrFromString(value.src)
+		if err != nil {
+			return fmt.Errorf("failed to convert credentials: %w", err)
+		}
+
+		*value.dst = ptr
+	}
+
+	r0, _, _ := syscall.SyscallN(
+		do.ft.AddCredentials,
+		uintptr(unsafe.Pointer(do)),
+		uintptr(unsafe.Pointer(destinationOptionsCredentials)),
+		uintptr(unsafe.Pointer(&credentials)),
+		0,
+	)
+
+	if result := ResultError(r0); !errors.Is(result, MI_RESULT_OK) {
+		return result
+	}
+
+	return nil
+}
+
 func (do *DestinationOptions) Delete() error {
 	r0, _, _ := syscall.SyscallN(
 		do.ft.Delete,
@@ -99,7 +99,7 @@ func (instance *Instance) GetElement(elementName string) (*Element, error) {
 	}
 
 	var (
-		value     uintptr
+		value     rawValue
 		valueType ValueType
 	)
 
@@ -118,8 +118,9 @@ func (instance *Instance) GetElement(elementName string) (*Element, error) {
 	}
 
 	return &Element{
-		value:     value,
+		value:     uintptr(value[0]),
 		valueType: valueType,
+		raw:       value,
 	}, nil
 }
 
@@ -18,6 +18,7 @@
 package mi_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -158,6 +159,76 @@ func Test_MI_QueryUnmarshal(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_MI_QueryContext(t *testing.T) {
+	application, err := mi.ApplicationInitialize()
+	require.NoError(t, err)
+	require.NotEmpty(t, application)
+
+	destinationOptions, err := application.NewDestinationOptions()
+	require.NoError(t, err)
+	require.NotEmpty(t, destinationOptions)
+
+	err = destinationOptions.SetLocale(mi.LocaleEnglish)
+	require.NoError(t, err)
+
+	session, err := application.NewSession(destinationOptions)
+	require.NoError(t, err)
+	require.NotEmpty(t, session)
+
+	queryProcess, err := mi.NewQuery("select Name from win32_process where handle = 0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 2*time.Second)
+	defer cancel()
+
+	var processes []win32Process
+
+	err = session.QueryContext(ctx, &processes, mi.NamespaceRootCIMv2, queryProcess)
+	require.NoError(t, err)
+	require.Equal(t, []win32Process{{Name: "System Idle Process"}}, processes)
+
+	ctx, cancel = context.WithCancel(t.Context())
+	cancel()
+
+	err = session.QueryContext(ctx, &processes, mi.NamespaceRootCIMv2, queryProcess)
+	require.ErrorIs(t, err, context.Canceled)
+
+	err = session.Close()
+	require.NoError(t, err)
+
+	err = application.Close()
+	require.NoError(t, err)
+}
+
+func Test_MI_QueryUnmarshal_Datetime(t *testing.T) {
+	application, err := mi.ApplicationInitialize()
+	require.NoError(t, err)
+	require.NotEmpty(t, application)
+
+	session, err := application.NewSession(nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, session)
+
+	var operatingSystems []struct {
+		LastBootUpTime time.Time `mi:"LastBootUpTime"`
+	}
+
+	queryOperatingSystem, err := mi.NewQuery("SELECT LastBootUpTime FROM Win32_OperatingSystem")
+	require.NoError(t, err)
+
+	err = session.Query(&operatingSystems, mi.NamespaceRootCIMv2, queryOperatingSystem)
+	require.NoError(t, err)
+	require.Len(t, operatingSystems, 1)
+	require.False(t, operatingSystems[0].LastBootUpTime.IsZero())
+	require.True(t, operatingSystems[0].LastBootUpTime.Before(time.Now()))
+
+	err = session.Close()
+	require.NoError(t, err)
+
+	err = application.Close()
+	require.NoError(t, err)
+}
+
 func Test_MI_EmptyQuery(t *testing.T) {
 	application, err := mi.ApplicationInitialize()
 	require.NoError(t, err)
@@ -237,7 +237,16 @@ func (o *Operation) Unmarshal(dst any) error {
 
 				field.SetString(stringValue)
 			case ValueTypeREAL32, ValueTypeREAL64:
-				field.SetFloat(float64(element.value))
+				field.SetFloat(element.Real())
+			case ValueTypeDATETIME:
+				if err := element.setDatetime(field); err != nil {
+					return fmt.Errorf("failed to set element %s: %w", miTag, err)
+				}
+			case ValueTypeBOOLEANA, ValueTypeUINT8A, ValueTypeUINT16A, ValueTypeUINT32A, ValueTypeUINT64A,
+				ValueTypeSINT8A, ValueTypeSINT16A, ValueTypeSINT32A, ValueTypeSINT64A, ValueTypeSTRINGA:
+				if err := element.setArray(field); err != nil {
+					return fmt.Errorf("failed to set element %s: %w", miTag, err)
+				}
 			default:
 				return fmt.Errorf("unsupported value type: %d", element.valueType)
 			}
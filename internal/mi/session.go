@@ -18,12 +18,16 @@
 package mi
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 	"syscall"
+	"time"
 	"unsafe"
 
+	"github.com/prometheus-community/windows_exporter/internal/debugdump"
 	"golang.org/x/sys/windows"
 )
 
@@ -189,6 +193,31 @@ func (s *Session) QueryInstances(flags OperationFlags, operationOptions *Operati
 func (s *Session) QueryUnmarshal(dst any,
 	flags OperationFlags, operationOptions *OperationOptions,
 	namespaceName Namespace, queryDialect QueryDialect, queryExpression Query,
+) error {
+	err := s.queryUnmarshal(context.Background(), dst, flags, operationOptions, namespaceName, queryDialect, queryExpression)
+	if err == nil {
+		dumpQuery(dst, namespaceName, queryExpression)
+	}
+
+	return err
+}
+
+// dumpQuery records the result of a query if a dump is running, see debugdump.
+func dumpQuery(dst any, namespaceName Namespace, queryExpression Query) {
+	if !debugdump.Enabled() {
+		return
+	}
+
+	debugdump.Add(debugdump.SourceMI,
+		windows.UTF16PtrToString(namespaceName)+": "+windows.UTF16PtrToString(queryExpression),
+		dst,
+	)
+}
+
+// queryUnmarshal runs the query of QueryUnmarshal. The operation is cancelled when ctx is done.
+func (s *Session) queryUnmarshal(ctx context.Context, dst any,
+	flags OperationFlags, operationOptions *OperationOptions,
+	namespaceName Namespace, queryDialect QueryDialect, queryExpression Query,
 ) error {
 	if s == nil || s.ft == nil {
 		return ErrNotInitialized
@@ -232,7 +261,29 @@ func (s *Session) QueryUnmarshal(dst any,
 		return result
 	}
 
+	// MI_Operation_Cancel may be called from another thread while GetInstance waits for results,
+	// but not after the operation is closed.
+	var (
+		operationMu     sync.Mutex
+		operationClosed bool
+	)
+
+	stopCancel := context.AfterFunc(ctx, func() {
+		operationMu.Lock()
+		defer operationMu.Unlock()
+
+		if !operationClosed {
+			_ = operation.Cancel()
+		}
+	})
+
 	defer func() {
+		stopCancel()
+
+		operationMu.Lock()
+		defer operationMu.Unlock()
+
+		operationClosed = true
 		_ = operation.Close()
 	}()
 
@@ -291,7 +342,16 @@ func (s *Session) QueryUnmarshal(dst any,
 
 				field.SetString(stringValue)
 			case ValueTypeREAL32, ValueTypeREAL64:
-				field.SetFloat(float64(element.value))
+				field.SetFloat(element.Real())
+			case ValueTypeDATETIME:
+				if err := element.setDatetime(field); err != nil {
+					return fmt.Errorf("failed to set element %s: %w", miTag, err)
+				}
+			case ValueTypeBOOLEANA, ValueTypeUINT8A, ValueTypeUINT16A, ValueTypeUINT32A, ValueTypeUINT64A,
+				ValueTypeSINT8A, ValueTypeSINT16A, ValueTypeSINT32A, ValueTypeSINT64A, ValueTypeSTRINGA:
+				if err := element.setArray(field); err != nil {
+					return fmt.Errorf("failed to set element %s: %w", miTag, err)
+				}
 			default:
 				return fmt.Errorf("unsupported value type: %d", element.valueType)
 			}
@@ -307,6 +367,49 @@ func (s *Session) QueryUnmarshal(dst any,
 	return nil
 }
 
+// QueryContext queries like Query, but the query is cancelled when ctx is done. If the deadline of ctx is
+// earlier than the default operation timeout, WMI times out the operation at the deadline.
+func (s *Session) QueryContext(ctx context.Context, dst any, namespaceName Namespace, queryExpression Query) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	var operationOptions *OperationOptions
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < defaultOperationTimeout {
+		application, err := s.GetApplication()
+		if err != nil {
+			return fmt.Errorf("failed to get MI application: %w", err)
+		}
+
+		operationOptions, err = application.NewOperationOptions()
+		if err != nil {
+			return fmt.Errorf("failed to create operation options: %w", err)
+		}
+
+		defer func() {
+			_ = operationOptions.Delete()
+		}()
+
+		if err = operationOptions.SetTimeout(max(time.Until(deadline), time.Millisecond)); err != nil {
+			return fmt.Errorf("failed to set timeout: %w", err)
+		}
+	}
+
+	err := s.queryUnmarshal(ctx, dst, OperationFlagsStandardRTTI, operationOptions, namespaceName, QueryDialectWQL, queryExpression)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("WMI query failed: %w: %w", ctxErr, err)
+		}
+
+		return fmt.Errorf("WMI query failed: %w", err)
+	}
+
+	dumpQuery(dst, namespaceName, queryExpression)
+
+	return nil
+}
+
 // Query queries for a set of instances based on a query expression.
 func (s *Session) Query(dst any, namespaceName Namespace, queryExpression Query) error {
 	err := s.QueryUnmarshal(dst, OperationFlagsStandardRTTI, nil, namespaceName, QueryDialectWQL, queryExpression)
@@ -49,11 +49,22 @@ func NewNamespace(namespace string) (Namespace, error) {
 
 //nolint:gochecknoglobals
 var (
-	NamespaceRootCIMv2             = utils.Must(NewNamespace("root/CIMv2"))
-	NamespaceRootWindowsFSRM       = utils.Must(NewNamespace("root/microsoft/windows/fsrm"))
-	NamespaceRootWebAdministration = utils.Must(NewNamespace("root/WebAdministration"))
-	NamespaceRootMSCluster         = utils.Must(NewNamespace("root/MSCluster"))
-	NamespaceRootMicrosoftDNS      = utils.Must(NewNamespace("root/MicrosoftDNS"))
+	NamespaceRootCIMv2                = utils.Must(NewNamespace("root/CIMv2"))
+	NamespaceRootWindowsFSRM          = utils.Must(NewNamespace("root/microsoft/windows/fsrm"))
+	NamespaceRootWebAdministration    = utils.Must(NewNamespace("root/WebAdministration"))
+	NamespaceRootMSCluster            = utils.Must(NewNamespace("root/MSCluster"))
+	NamespaceRootMicrosoftDNS         = utils.Must(NewNamespace("root/MicrosoftDNS"))
+	NamespaceRootWindowsDNS           = utils.Must(NewNamespace("root/Microsoft/Windows/DNS"))
+	NamespaceRootWindowsStorage       = utils.Must(NewNamespace("root/microsoft/windows/storage"))
+	NamespaceRootDeviceGuard          = utils.Must(NewNamespace("root/Microsoft/Windows/DeviceGuard"))
+	NamespaceRootStandardCimv2        = utils.Must(NewNamespace("root/StandardCimv2"))
+	NamespaceRootVirtualizationV2     = utils.Must(NewNamespace("root/virtualization/v2"))
+	NamespaceRootDeliveryOptimization = utils.Must(NewNamespace("root/Microsoft/Windows/DeliveryOptimization"))
+	NamespaceRootCIMv2MDMDMMap        = utils.Must(NewNamespace("root/cimv2/mdm/dmmap"))
+	NamespaceRootWindowsSMB           = utils.Must(NewNamespace("root/Microsoft/Windows/SMB"))
+	NamespaceRootWindowsSMBWitness    = utils.Must(NewNamespace("root/Microsoft/Windows/SMBWitness"))
+	NamespaceRootMicrosoftAD          = utils.Must(NewNamespace("root/MicrosoftActiveDirectory"))
+	NamespaceRootMicrosoftNLB         = utils.Must(NewNamespace("root/MicrosoftNLB"))
 )
 
 type Query *uint16
@@ -110,6 +121,28 @@ func NewInterval(interval time.Duration) *Interval {
 	}
 }
 
+// Time converts the timestamp to a [time.Time]. A zero timestamp is returned as the zero [time.Time].
+func (t *Timestamp) Time() time.Time {
+	if t.Year == 0 {
+		return time.Time{}
+	}
+
+	return time.Date(
+		int(t.Year), time.Month(t.Month), int(t.Day),
+		int(t.Hour), int(t.Minute), int(t.Second), int(t.Microseconds)*int(time.Microsecond),
+		time.FixedZone("", int(t.UTC)*60),
+	)
+}
+
+// Duration converts the interval to a [time.Duration].
+func (i *Interval) Duration() time.Duration {
+	return time.Duration(i.Days)*24*time.Hour +
+		time.Duration(i.Hours)*time.Hour +
+		time.Duration(i.Minutes)*time.Minute +
+		time.Duration(i.Seconds)*time.Second +
+		time.Duration(i.Microseconds)*time.Microsecond
+}
+
 type Datetime struct {
 	IsTimestamp bool
 	Timestamp   *Timestamp // Used when IsTimestamp is true
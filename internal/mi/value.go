@@ -20,6 +20,9 @@ package mi
 import (
 	"errors"
 	"fmt"
+	"math"
+	"reflect"
+	"time"
 	"unsafe"
 
 	"golang.org/x/sys/windows"
@@ -64,9 +67,27 @@ const (
 	ValueTypeARRAY ValueType = 16
 )
 
+// rawValue is large enough to hold any member of the MI_Value union.
+// The largest member is MI_Datetime with 36 bytes.
+type rawValue [5]uint64
+
+// rawDatetime is the in-memory layout of MI_Datetime.
+// Timestamp and Interval share the same union storage.
+type rawDatetime struct {
+	IsTimestamp uint32
+	Union       [8]uint32
+}
+
+// rawArray is the in-memory layout of the MI_*A array types.
+type rawArray struct {
+	Data unsafe.Pointer
+	Size uint32
+}
+
 type Element struct {
 	value     uintptr
 	valueType ValueType
+	raw       rawValue
 }
 
 func (e *Element) GetValue() (any, error) {
@@ -90,17 +111,13 @@ func (e *Element) GetValue() (any, error) {
 	case ValueTypeSINT64:
 		return int64(e.value), nil
 	case ValueTypeREAL32:
-		return float32(e.value), nil
+		return float32(e.Real()), nil
 	case ValueTypeREAL64:
-		return float64(e.value), nil
+		return e.Real(), nil
 	case ValueTypeCHAR16:
 		return uint16(e.value), nil
 	case ValueTypeDATETIME:
-		if e.value == 0 {
-			return nil, errors.New("invalid pointer: value is nil")
-		}
-
-		return *(*Datetime)(unsafe.Pointer(e.value)), nil
+		return e.Datetime(), nil
 	case ValueTypeSTRING:
 		if e.value == 0 {
 			return nil, errors.New("invalid pointer: value is nil")
@@ -126,3 +143,103 @@ func (e *Element) GetValue() (any, error) {
 		return nil, fmt.Errorf("unsupported value type: %d", e.valueType)
 	}
 }
+
+// Real returns the value of a REAL32 or REAL64 element.
+// The value is stored as IEEE 754 bits in the MI_Value union.
+func (e *Element) Real() float64 {
+	if e.valueType == ValueTypeREAL32 {
+		return float64(math.Float32frombits(uint32(e.raw[0])))
+	}
+
+	return math.Float64frombits(e.raw[0])
+}
+
+// Datetime returns the value of a DATETIME element.
+func (e *Element) Datetime() Datetime {
+	datetime := (*rawDatetime)(unsafe.Pointer(&e.raw))
+
+	if datetime.IsTimestamp == 1 {
+		return Datetime{
+			IsTimestamp: true,
+			Timestamp:   (*Timestamp)(unsafe.Pointer(&datetime.Union)),
+		}
+	}
+
+	return Datetime{
+		IsTimestamp: false,
+		Interval:    (*Interval)(unsafe.Pointer(&datetime.Union)),
+	}
+}
+
+// setArray sets a slice field from an UINT*A, SINT*A or STRINGA element.
+// The array memory is owned by the instance, so the values are copied.
+func (e *Element) setArray(field reflect.Value) error {
+	array := (*rawArray)(unsafe.Pointer(&e.raw))
+
+	if field.Kind() != reflect.Slice {
+		return fmt.Errorf("unsupported field type %s for array value", field.Type())
+	}
+
+	slice := reflect.MakeSlice(field.Type(), int(array.Size), int(array.Size))
+
+	if array.Size == 0 || array.Data == nil {
+		field.Set(slice)
+
+		return nil
+	}
+
+	for i := range int(array.Size) {
+		item := slice.Index(i)
+
+		switch e.valueType {
+		case ValueTypeBOOLEANA, ValueTypeUINT8A:
+			item.SetUint(uint64(unsafe.Slice((*uint8)(array.Data), array.Size)[i]))
+		case ValueTypeUINT16A:
+			item.SetUint(uint64(unsafe.Slice((*uint16)(array.Data), array.Size)[i]))
+		case ValueTypeUINT32A:
+			item.SetUint(uint64(unsafe.Slice((*uint32)(array.Data), array.Size)[i]))
+		case ValueTypeUINT64A:
+			item.SetUint(unsafe.Slice((*uint64)(array.Data), array.Size)[i])
+		case ValueTypeSINT8A:
+			item.SetInt(int64(unsafe.Slice((*int8)(array.Data), array.Size)[i]))
+		case ValueTypeSINT16A:
+			item.SetInt(int64(unsafe.Slice((*int16)(array.Data), array.Size)[i]))
+		case ValueTypeSINT32A:
+			item.SetInt(int64(unsafe.Slice((*int32)(array.Data), array.Size)[i]))
+		case ValueTypeSINT64A:
+			item.SetInt(unsafe.Slice((*int64)(array.Data), array.Size)[i])
+		case ValueTypeSTRINGA:
+			item.SetString(windows.UTF16PtrToString(unsafe.Slice((**uint16)(array.Data), array.Size)[i]))
+		default:
+			return fmt.Errorf("unsupported array value type: %d", e.valueType)
+		}
+	}
+
+	field.Set(slice)
+
+	return nil
+}
+
+// setDatetime sets a [time.Time] field from a timestamp or a [time.Duration] field from an interval.
+func (e *Element) setDatetime(field reflect.Value) error {
+	datetime := e.Datetime()
+
+	switch field.Type() {
+	case reflect.TypeFor[time.Time]():
+		if !datetime.IsTimestamp {
+			return errors.New("cannot set interval to time.Time field")
+		}
+
+		field.Set(reflect.ValueOf(datetime.Timestamp.Time()))
+	case reflect.TypeFor[time.Duration]():
+		if datetime.IsTimestamp {
+			return errors.New("cannot set timestamp to time.Duration field")
+		}
+
+		field.SetInt(int64(datetime.Interval.Duration()))
+	default:
+		return fmt.Errorf("unsupported field type %s for datetime value", field.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,329 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package otlp
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/version"
+)
+
+// aggregationTemporalityCumulative is AGGREGATION_TEMPORALITY_CUMULATIVE of the OTLP metrics data model.
+// Prometheus counters, histograms and summaries are always cumulative.
+const aggregationTemporalityCumulative = 2
+
+// The types below are the JSON encoding of the OTLP protobuf messages,
+// see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding.
+// 64-bit integers are encoded as strings, as required by the protobuf JSON mapping.
+type exportMetricsServiceRequest struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeMetrics struct {
+	Scope   scope    `json:"scope"`
+	Metrics []metric `json:"metrics"`
+}
+
+type scope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+// anyValue holds either a string or an integer.
+type anyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *int64  `json:"intValue,omitempty,string"`
+}
+
+type metric struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Unit        string     `json:"unit,omitempty"`
+	Gauge       *gauge     `json:"gauge,omitempty"`
+	Sum         *sum       `json:"sum,omitempty"`
+	Histogram   *histogram `json:"histogram,omitempty"`
+	Summary     *summary   `json:"summary,omitempty"`
+}
+
+type gauge struct {
+	DataPoints []numberDataPoint `json:"dataPoints"`
+}
+
+type sum struct {
+	DataPoints             []numberDataPoint `json:"dataPoints"`
+	AggregationTemporality int               `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic"`
+}
+
+type histogram struct {
+	DataPoints             []histogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                  `json:"aggregationTemporality"`
+}
+
+type summary struct {
+	DataPoints []summaryDataPoint `json:"dataPoints"`
+}
+
+type numberDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64     `json:"startTimeUnixNano,omitempty,string"`
+	TimeUnixNano      uint64     `json:"timeUnixNano,string"`
+	AsDouble          double     `json:"asDouble"`
+}
+
+type histogramDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64     `json:"startTimeUnixNano,string"`
+	TimeUnixNano      uint64     `json:"timeUnixNano,string"`
+	Count             uint64     `json:"count,string"`
+	Sum               double     `json:"sum"`
+	BucketCounts      []string   `json:"bucketCounts"`
+	ExplicitBounds    []double   `json:"explicitBounds"`
+}
+
+type summaryDataPoint struct {
+	Attributes        []keyValue        `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64            `json:"startTimeUnixNano,string"`
+	TimeUnixNano      uint64            `json:"timeUnixNano,string"`
+	Count             uint64            `json:"count,string"`
+	Sum               double            `json:"sum"`
+	QuantileValues    []valueAtQuantile `json:"quantileValues"`
+}
+
+type valueAtQuantile struct {
+	Quantile double `json:"quantile"`
+	Value    double `json:"value"`
+}
+
+// double is a float64 that encodes NaN and infinities like the protobuf JSON mapping,
+// which encoding/json rejects otherwise.
+type double float64
+
+func (d double) MarshalJSON() ([]byte, error) {
+	value := float64(d)
+
+	switch {
+	case math.IsNaN(value):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(value, 1):
+		return []byte(`"Infinity"`), nil
+	case math.IsInf(value, -1):
+		return []byte(`"-Infinity"`), nil
+	}
+
+	return json.Marshal(value)
+}
+
+func stringAttribute(key, value string) keyValue {
+	return keyValue{Key: key, Value: anyValue{StringValue: &value}}
+}
+
+func intAttribute(key string, value int64) keyValue {
+	return keyValue{Key: key, Value: anyValue{IntValue: &value}}
+}
+
+// newExportMetricsServiceRequest converts the Prometheus metric families to OTLP metrics.
+// Untyped metrics are sent as gauges, counters as monotonic cumulative sums.
+func newExportMetricsServiceRequest(res resource, metricFamilies []*dto.MetricFamily, startTime, now time.Time) exportMetricsServiceRequest {
+	metrics := make([]metric, 0, len(metricFamilies))
+
+	for _, metricFamily := range metricFamilies {
+		if len(metricFamily.GetMetric()) == 0 {
+			continue
+		}
+
+		metrics = append(metrics, convertMetricFamily(metricFamily, startTime, now))
+	}
+
+	return exportMetricsServiceRequest{
+		ResourceMetrics: []resourceMetrics{
+			{
+				Resource: res,
+				ScopeMetrics: []scopeMetrics{
+					{
+						Scope: scope{
+							Name:    "windows_exporter",
+							Version: version.Version,
+						},
+						Metrics: metrics,
+					},
+				},
+			},
+		},
+	}
+}
+
+func convertMetricFamily(metricFamily *dto.MetricFamily, startTime, now time.Time) metric {
+	m := metric{
+		Name:        metricFamily.GetName(),
+		Description: metricFamily.GetHelp(),
+		Unit:        metricFamily.GetUnit(),
+	}
+
+	switch metricFamily.GetType() {
+	case dto.MetricType_COUNTER:
+		m.Sum = &sum{
+			DataPoints:             make([]numberDataPoint, 0, len(metricFamily.GetMetric())),
+			AggregationTemporality: aggregationTemporalityCumulative,
+			IsMonotonic:            true,
+		}
+
+		for _, promMetric := range metricFamily.GetMetric() {
+			counterStartTime := startTime
+			if createdTimestamp := promMetric.GetCounter().GetCreatedTimestamp(); createdTimestamp != nil {
+				counterStartTime = createdTimestamp.AsTime()
+			}
+
+			m.Sum.DataPoints = append(m.Sum.DataPoints, numberDataPoint{
+				Attributes:        convertLabels(promMetric.GetLabel()),
+				StartTimeUnixNano: unixNano(counterStartTime),
+				TimeUnixNano:      timestamp(promMetric, now),
+				AsDouble:          double(promMetric.GetCounter().GetValue()),
+			})
+		}
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		m.Histogram = &histogram{
+			DataPoints:             make([]histogramDataPoint, 0, len(metricFamily.GetMetric())),
+			AggregationTemporality: aggregationTemporalityCumulative,
+		}
+
+		for _, promMetric := range metricFamily.GetMetric() {
+			m.Histogram.DataPoints = append(m.Histogram.DataPoints, convertHistogram(promMetric, startTime, now))
+		}
+	case dto.MetricType_SUMMARY:
+		m.Summary = &summary{
+			DataPoints: make([]summaryDataPoint, 0, len(metricFamily.GetMetric())),
+		}
+
+		for _, promMetric := range metricFamily.GetMetric() {
+			quantiles := make([]valueAtQuantile, 0, len(promMetric.GetSummary().GetQuantile()))
+			for _, quantile := range promMetric.GetSummary().GetQuantile() {
+				quantiles = append(quantiles, valueAtQuantile{
+					Quantile: double(quantile.GetQuantile()),
+					Value:    double(quantile.GetValue()),
+				})
+			}
+
+			m.Summary.DataPoints = append(m.Summary.DataPoints, summaryDataPoint{
+				Attributes:        convertLabels(promMetric.GetLabel()),
+				StartTimeUnixNano: unixNano(startTime),
+				TimeUnixNano:      timestamp(promMetric, now),
+				Count:             promMetric.GetSummary().GetSampleCount(),
+				Sum:               double(promMetric.GetSummary().GetSampleSum()),
+				QuantileValues:    quantiles,
+			})
+		}
+	default:
+		m.Gauge = &gauge{
+			DataPoints: make([]numberDataPoint, 0, len(metricFamily.GetMetric())),
+		}
+
+		for _, promMetric := range metricFamily.GetMetric() {
+			value := promMetric.GetGauge().GetValue()
+			if metricFamily.GetType() == dto.MetricType_UNTYPED {
+				value = promMetric.GetUntyped().GetValue()
+			}
+
+			m.Gauge.DataPoints = append(m.Gauge.DataPoints, numberDataPoint{
+				Attributes:   convertLabels(promMetric.GetLabel()),
+				TimeUnixNano: timestamp(promMetric, now),
+				AsDouble:     double(value),
+			})
+		}
+	}
+
+	return m
+}
+
+// convertHistogram converts the cumulative Prometheus buckets to the per-bucket counts of OTLP.
+// OTLP has one more bucket than bounds, which counts the observations above the last bound.
+func convertHistogram(promMetric *dto.Metric, startTime, now time.Time) histogramDataPoint {
+	promHistogram := promMetric.GetHistogram()
+
+	dataPoint := histogramDataPoint{
+		Attributes:        convertLabels(promMetric.GetLabel()),
+		StartTimeUnixNano: unixNano(startTime),
+		TimeUnixNano:      timestamp(promMetric, now),
+		Count:             promHistogram.GetSampleCount(),
+		Sum:               double(promHistogram.GetSampleSum()),
+		BucketCounts:      make([]string, 0, len(promHistogram.GetBucket())+1),
+		ExplicitBounds:    make([]double, 0, len(promHistogram.GetBucket())),
+	}
+
+	var previousCount uint64
+
+	for _, bucket := range promHistogram.GetBucket() {
+		if math.IsInf(bucket.GetUpperBound(), 1) {
+			continue
+		}
+
+		cumulativeCount := max(bucket.GetCumulativeCount(), previousCount)
+
+		dataPoint.ExplicitBounds = append(dataPoint.ExplicitBounds, double(bucket.GetUpperBound()))
+		dataPoint.BucketCounts = append(dataPoint.BucketCounts, strconv.FormatUint(cumulativeCount-previousCount, 10))
+		previousCount = cumulativeCount
+	}
+
+	dataPoint.BucketCounts = append(dataPoint.BucketCounts, strconv.FormatUint(max(dataPoint.Count, previousCount)-previousCount, 10))
+
+	return dataPoint
+}
+
+func convertLabels(labels []*dto.LabelPair) []keyValue {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	attributes := make([]keyValue, 0, len(labels))
+	for _, label := range labels {
+		attributes = append(attributes, stringAttribute(label.GetName(), label.GetValue()))
+	}
+
+	return attributes
+}
+
+// timestamp returns the timestamp of the metric, which is set by a few collectors, or the time of the push.
+func timestamp(promMetric *dto.Metric, now time.Time) uint64 {
+	if promMetric.TimestampMs != nil {
+		return unixNano(time.UnixMilli(promMetric.GetTimestampMs()))
+	}
+
+	return unixNano(now)
+}
+
+func unixNano(t time.Time) uint64 {
+	return uint64(max(t.UnixNano(), 0))
+}
@@ -0,0 +1,277 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+// Package otlp pushes the metrics and the traces of the scrapes of windows_exporter to an OpenTelemetry Collector
+// with the OTLP/HTTP protocol and the JSON encoding of the OTLP protobuf messages.
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/version"
+)
+
+// metricsPath is the default URL path of the OTLP/HTTP metrics receiver.
+const metricsPath = "/v1/metrics"
+
+// GatherFunc collects the metric families to push within the given timeout.
+type GatherFunc func(timeout time.Duration) ([]*dto.MetricFamily, error)
+
+type Options struct {
+	// Endpoint is the URL of the OTLP/HTTP receiver. If the URL has no path, /v1/metrics is used.
+	Endpoint string
+	// Interval is the time between two pushes.
+	Interval time.Duration
+	// Timeout limits the collection and the request of a push.
+	Timeout time.Duration
+	// Headers are added to each request, e.g. for authentication.
+	Headers map[string]string
+	// Align runs the pushes at multiples of Interval on the UTC wall clock, e.g. at :00 and :30 with 30m.
+	Align bool
+	// Jitter is the maximum offset of the pushes. The offset is derived from the hostname,
+	// so it is stable across restarts, but differs between hosts.
+	Jitter time.Duration
+}
+
+// Pusher periodically pushes the gathered metrics to an OTLP/HTTP receiver.
+type Pusher struct {
+	logger    *slog.Logger
+	gather    GatherFunc
+	startTime time.Time
+	endpoint  string
+	options   Options
+	client    *http.Client
+	resource  resource
+	// offset delays each push within the jitter.
+	offset time.Duration
+}
+
+// New returns a Pusher for the given options. startTime is reported as start of all cumulative series.
+func New(logger *slog.Logger, gather GatherFunc, startTime time.Time, options Options) (*Pusher, error) {
+	endpoint, err := url.Parse(options.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP endpoint: %w", err)
+	}
+
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: scheme must be http or https", options.Endpoint)
+	}
+
+	if endpoint.Path == "" || endpoint.Path == "/" {
+		endpoint.Path = metricsPath
+	}
+
+	if options.Interval <= 0 {
+		return nil, errors.New("OTLP push interval must be greater than 0")
+	}
+
+	if options.Timeout <= 0 || options.Timeout > options.Interval {
+		options.Timeout = options.Interval
+	}
+
+	if options.Jitter < 0 || options.Jitter > options.Interval {
+		return nil, fmt.Errorf("OTLP push jitter must be between 0 and the interval %s", options.Interval)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname: %w", err)
+	}
+
+	return &Pusher{
+		logger:    logger.With(slog.String("endpoint", endpoint.Redacted())),
+		gather:    gather,
+		startTime: startTime,
+		endpoint:  endpoint.String(),
+		options:   options,
+		client:    &http.Client{},
+		resource:  newResource(hostname),
+		offset:    jitterOffset(hostname, options.Jitter),
+	}, nil
+}
+
+// Run pushes the metrics once per interval until ctx is cancelled.
+// Failed pushes are logged and not retried, the next push sends the current values.
+func (p *Pusher) Run(ctx context.Context) {
+	next := p.firstPush(time.Now())
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if err := p.Push(ctx); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "failed to push metrics via OTLP",
+				slog.Any("err", err),
+			)
+		}
+
+		next = p.nextPush(next, time.Now())
+		timer.Reset(time.Until(next))
+	}
+}
+
+// firstPush returns the time of the first push. Without alignment and jitter, the first push runs immediately.
+func (p *Pusher) firstPush(now time.Time) time.Time {
+	if !p.options.Align {
+		return now.Add(p.offset)
+	}
+
+	first := now.Truncate(p.options.Interval).Add(p.offset)
+	if first.Before(now) {
+		first = first.Add(p.options.Interval)
+	}
+
+	return first
+}
+
+// nextPush returns the push after previous. Pushes that are missed, e.g. because a push took longer
+// than the interval, are skipped to keep the schedule.
+func (p *Pusher) nextPush(previous, now time.Time) time.Time {
+	next := previous.Add(p.options.Interval)
+	for !next.After(now) {
+		next = next.Add(p.options.Interval)
+	}
+
+	return next
+}
+
+// jitterOffset returns an offset below jitter, which is derived from the hostname.
+func jitterOffset(hostname string, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return 0
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(strings.ToLower(hostname)))
+
+	return time.Duration(hash.Sum64() % uint64(jitter))
+}
+
+// Push gathers the metrics and sends them to the OTLP receiver.
+func (p *Pusher) Push(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.options.Timeout)
+	defer cancel()
+
+	metricFamilies, err := p.gather(p.options.Timeout)
+	if err != nil {
+		// Gather returns the metrics of the successful collectors next to the error.
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "error while gathering metrics",
+			slog.Any("err", err),
+		)
+	}
+
+	if len(metricFamilies) == 0 {
+		return errors.New("no metrics gathered")
+	}
+
+	request := newExportMetricsServiceRequest(p.resource, metricFamilies, p.startTime, time.Now())
+
+	body, err := encodeRequest(request)
+	if err != nil {
+		return err
+	}
+
+	if err := send(ctx, p.client, p.endpoint, p.options.Headers, body); err != nil {
+		return err
+	}
+
+	p.logger.LogAttrs(ctx, slog.LevelDebug, "pushed metrics via OTLP",
+		slog.Int("metric_families", len(metricFamilies)),
+	)
+
+	return nil
+}
+
+// encodeRequest encodes an OTLP export request, i.e. metrics or traces, as gzip compressed JSON.
+func encodeRequest(request any) (*bytes.Buffer, error) {
+	body := &bytes.Buffer{}
+	writer := gzip.NewWriter(body)
+
+	if err := json.NewEncoder(writer).Encode(request); err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request: %w", err)
+	}
+
+	return body, nil
+}
+
+// send posts the encoded request to the OTLP receiver.
+func send(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("User-Agent", "windows_exporter/"+version.Version)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("OTLP receiver returned %s: %s", resp.Status, bytes.TrimSpace(message))
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
+
+// newResource returns the resource of the metrics and traces of windows_exporter.
+func newResource(hostname string) resource {
+	return resource{
+		Attributes: []keyValue{
+			stringAttribute("host.name", hostname),
+			stringAttribute("service.name", "windows_exporter"),
+			stringAttribute("service.version", version.Version),
+		},
+	}
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPush(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+
+	processes := prometheus.NewGauge(prometheus.GaugeOpts{Name: "windows_system_processes", Help: "Current number of processes"})
+	processes.Set(math.NaN())
+
+	duration := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "windows_test_duration_seconds", Help: "Test duration", Buckets: []float64{1, 10}})
+	duration.Observe(0.5)
+	duration.Observe(5)
+	duration.Observe(50)
+
+	reg.MustRegister(processes, duration)
+
+	var request map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/metrics" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		if err := json.NewDecoder(reader).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	// Collectors emit const metrics, which have no created timestamp.
+	bytesSent := &dto.MetricFamily{
+		Name: proto.String("windows_net_bytes_sent_total"),
+		Help: proto.String("Total bytes sent"),
+		Type: dto.MetricType_COUNTER.Enum(),
+		Metric: []*dto.Metric{
+			{
+				Label:   []*dto.LabelPair{{Name: proto.String("nic"), Value: proto.String("eth0")}},
+				Counter: &dto.Counter{Value: proto.Float64(1024)},
+			},
+		},
+	}
+
+	gather := func(time.Duration) ([]*dto.MetricFamily, error) {
+		metricFamilies, err := reg.Gather()
+
+		return append(metricFamilies, bytesSent), err
+	}
+
+	pusher, err := New(slog.New(slog.DiscardHandler), gather, time.Unix(1700000000, 0), Options{
+		Endpoint: server.URL,
+		Interval: time.Minute,
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, pusher.Push(context.Background()))
+
+	metrics := make(map[string]map[string]any)
+
+	for _, m := range request["resourceMetrics"].([]any)[0].(map[string]any)["scopeMetrics"].([]any)[0].(map[string]any)["metrics"].([]any) {
+		metrics[m.(map[string]any)["name"].(string)] = m.(map[string]any)
+	}
+
+	require.Len(t, metrics, 3)
+
+	gaugePoint := metrics["windows_system_processes"]["gauge"].(map[string]any)["dataPoints"].([]any)[0].(map[string]any)
+	require.Equal(t, "NaN", gaugePoint["asDouble"])
+
+	sumMetric := metrics["windows_net_bytes_sent_total"]["sum"].(map[string]any)
+	require.Equal(t, true, sumMetric["isMonotonic"])
+	require.InDelta(t, aggregationTemporalityCumulative, sumMetric["aggregationTemporality"], 0)
+
+	sumPoint := sumMetric["dataPoints"].([]any)[0].(map[string]any)
+	require.Equal(t, "1700000000000000000", sumPoint["startTimeUnixNano"])
+	require.InDelta(t, 1024.0, sumPoint["asDouble"], 0)
+	require.Equal(t, []any{map[string]any{"key": "nic", "value": map[string]any{"stringValue": "eth0"}}}, sumPoint["attributes"])
+
+	histogramPoint := metrics["windows_test_duration_seconds"]["histogram"].(map[string]any)["dataPoints"].([]any)[0].(map[string]any)
+	require.Equal(t, "3", histogramPoint["count"])
+	require.Equal(t, []any{1.0, 10.0}, histogramPoint["explicitBounds"])
+	require.Equal(t, []any{"1", "1", "1"}, histogramPoint["bucketCounts"])
+}
+
+func TestNewInvalidEndpoint(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(slog.New(slog.DiscardHandler), nil, time.Now(), Options{
+		Endpoint: "localhost:4318",
+		Interval: time.Minute,
+	})
+	require.Error(t, err)
+}
+
+func TestSchedule(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 10, 17, 0, 0, time.UTC)
+
+	pusher := &Pusher{options: Options{Interval: 30 * time.Minute}}
+	require.Equal(t, now, pusher.firstPush(now))
+	require.Equal(t, now.Add(30*time.Minute), pusher.nextPush(now, now.Add(time.Second)))
+	// A push that took longer than the interval skips the missed push.
+	require.Equal(t, now.Add(time.Hour), pusher.nextPush(now, now.Add(40*time.Minute)))
+
+	pusher = &Pusher{options: Options{Interval: 30 * time.Minute, Align: true}, offset: 5 * time.Minute}
+	require.Equal(t, time.Date(2024, 1, 1, 10, 35, 0, 0, time.UTC), pusher.firstPush(now))
+
+	pusher.offset = 0
+	require.Equal(t, time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC), pusher.firstPush(now))
+
+	offset := jitterOffset("server01", time.Minute)
+	require.Less(t, offset, time.Minute)
+	require.Equal(t, offset, jitterOffset("SERVER01", time.Minute))
+	require.Zero(t, jitterOffset("server01", 0))
+}
@@ -0,0 +1,345 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package otlp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+	"github.com/prometheus/common/version"
+)
+
+// tracesPath is the default URL path of the OTLP/HTTP traces receiver.
+const tracesPath = "/v1/traces"
+
+// traceQueueSize is the number of finished traces that are buffered while the receiver is slow or unreachable.
+// Further traces are dropped, so tracing never delays a scrape.
+const traceQueueSize = 100
+
+// Span kinds and status codes of the OTLP trace data model.
+const (
+	spanKindInternal = 1
+	spanKindServer   = 2
+
+	statusCodeOK    = 1
+	statusCodeError = 2
+)
+
+// The types below are the JSON encoding of the OTLP trace messages. Unlike other bytes fields,
+// trace and span IDs are encoded as hex strings, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding.
+type exportTraceServiceRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type scopeSpans struct {
+	Scope scope  `json:"scope"`
+	Spans []span `json:"spans"`
+}
+
+type span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano uint64     `json:"startTimeUnixNano,string"`
+	EndTimeUnixNano   uint64     `json:"endTimeUnixNano,string"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            spanStatus `json:"status"`
+}
+
+type spanStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type TracerOptions struct {
+	// Endpoint is the URL of the OTLP/HTTP receiver. If the URL has no path, /v1/traces is used.
+	Endpoint string
+	// Timeout limits each request.
+	Timeout time.Duration
+	// Headers are added to each request, e.g. for authentication.
+	Headers map[string]string
+}
+
+// Tracer sends a trace per scrape, with a child span per collector, to an OTLP/HTTP receiver.
+type Tracer struct {
+	logger   *slog.Logger
+	endpoint string
+	options  TracerOptions
+	client   *http.Client
+	resource resource
+	queue    chan []span
+}
+
+// ScrapeTrace is the trace of a single scrape, see Tracer.StartScrape.
+type ScrapeTrace struct {
+	tracer       *Tracer
+	traceID      string
+	spanID       string
+	parentSpanID string
+	start        time.Time
+	attributes   []keyValue
+
+	mu         sync.Mutex
+	collectors []span
+	failed     int
+}
+
+// NewTracer returns a Tracer for the given options.
+func NewTracer(logger *slog.Logger, options TracerOptions) (*Tracer, error) {
+	endpoint, err := url.Parse(options.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP traces endpoint: %w", err)
+	}
+
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("invalid OTLP traces endpoint %q: scheme must be http or https", options.Endpoint)
+	}
+
+	if endpoint.Path == "" || endpoint.Path == "/" {
+		endpoint.Path = tracesPath
+	}
+
+	if options.Timeout <= 0 {
+		return nil, errors.New("OTLP traces timeout must be greater than 0")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname: %w", err)
+	}
+
+	return &Tracer{
+		logger:   logger.With(slog.String("endpoint", endpoint.Redacted())),
+		endpoint: endpoint.String(),
+		options:  options,
+		client:   &http.Client{Timeout: options.Timeout},
+		resource: newResource(hostname),
+		queue:    make(chan []span, traceQueueSize),
+	}, nil
+}
+
+// Run sends the finished traces until ctx is cancelled. Failed requests are logged and not retried.
+func (t *Tracer) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case spans := <-t.queue:
+			if err := t.send(ctx, spans); err != nil {
+				t.logger.LogAttrs(ctx, slog.LevelWarn, "failed to send scrape trace via OTLP",
+					slog.Any("err", err),
+				)
+			}
+		}
+	}
+}
+
+func (t *Tracer) send(ctx context.Context, spans []span) error {
+	body, err := encodeRequest(exportTraceServiceRequest{
+		ResourceSpans: []resourceSpans{
+			{
+				Resource: t.resource,
+				ScopeSpans: []scopeSpans{
+					{
+						Scope: scope{
+							Name:    "windows_exporter",
+							Version: version.Version,
+						},
+						Spans: spans,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return send(ctx, t.client, t.endpoint, t.options.Headers, body)
+}
+
+// StartScrape starts the trace of a scrape. A request with a W3C traceparent header, e.g. of a Prometheus server
+// with tracing enabled, continues that trace. StartScrape returns nil if the traceparent is not sampled.
+func (t *Tracer) StartScrape(r *http.Request) *ScrapeTrace {
+	trace := &ScrapeTrace{
+		tracer: t,
+		spanID: randomID(8),
+		start:  time.Now(),
+		attributes: []keyValue{
+			stringAttribute("http.request.method", r.Method),
+			stringAttribute("url.path", r.URL.Path),
+			stringAttribute("client.address", r.RemoteAddr),
+		},
+	}
+
+	if traceID, parentSpanID, sampled, ok := parseTraceparent(r.Header.Get("traceparent")); ok {
+		if !sampled {
+			return nil
+		}
+
+		trace.traceID, trace.parentSpanID = traceID, parentSpanID
+	} else {
+		trace.traceID = randomID(16)
+	}
+
+	return trace
+}
+
+// TraceID returns the hex encoded ID of the trace, which is logged with the scrape to correlate logs and traces.
+func (s *ScrapeTrace) TraceID() string {
+	return s.traceID
+}
+
+// RecordCollector adds the span of a collector to the trace. It implements collector.SpanRecorder.
+func (s *ScrapeTrace) RecordCollector(collectorSpan collector.CollectorSpan) {
+	child := span{
+		TraceID:           s.traceID,
+		SpanID:            randomID(8),
+		ParentSpanID:      s.spanID,
+		Name:              "collect " + collectorSpan.Collector,
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: unixNano(collectorSpan.Start),
+		EndTimeUnixNano:   unixNano(collectorSpan.End),
+		Attributes: []keyValue{
+			stringAttribute("windows_exporter.collector", collectorSpan.Collector),
+			stringAttribute("windows_exporter.collector.backend", collectorSpan.Backend),
+			stringAttribute("windows_exporter.collector.status", collectorSpan.Status),
+			intAttribute("windows_exporter.collector.metrics", int64(collectorSpan.Metrics)),
+		},
+		Status: spanStatus{Code: statusCodeOK},
+	}
+
+	if collectorSpan.Err != nil {
+		child.Attributes = append(child.Attributes, stringAttribute("error.message", collectorSpan.Err.Error()))
+	}
+
+	if collectorSpan.Status != "success" {
+		child.Status = spanStatus{Code: statusCodeError, Message: collectorSpan.Status}
+		if collectorSpan.Err != nil {
+			child.Status.Message = collectorSpan.Err.Error()
+		}
+
+		child.Attributes = append(child.Attributes, stringAttribute("error.type", collectorSpan.Status))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.collectors = append(s.collectors, child)
+
+	if collectorSpan.Status != "success" {
+		s.failed++
+	}
+}
+
+// End finishes the scrape span and queues the trace. The scrape span has the status error if a collector failed
+// or timed out. The trace is dropped if the queue is full.
+func (s *ScrapeTrace) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	root := span{
+		TraceID:           s.traceID,
+		SpanID:            s.spanID,
+		ParentSpanID:      s.parentSpanID,
+		Name:              "scrape",
+		Kind:              spanKindServer,
+		StartTimeUnixNano: unixNano(s.start),
+		EndTimeUnixNano:   unixNano(time.Now()),
+		Attributes: append(s.attributes,
+			intAttribute("windows_exporter.scrape.collectors", int64(len(s.collectors))),
+			intAttribute("windows_exporter.scrape.failed_collectors", int64(s.failed)),
+		),
+		Status: spanStatus{Code: statusCodeOK},
+	}
+
+	if s.failed > 0 {
+		root.Status = spanStatus{Code: statusCodeError, Message: fmt.Sprintf("%d collectors failed or timed out", s.failed)}
+	}
+
+	select {
+	case s.tracer.queue <- append([]span{root}, s.collectors...):
+	default:
+		s.tracer.logger.LogAttrs(context.Background(), slog.LevelDebug, "dropped scrape trace, the queue is full",
+			slog.String("trace_id", s.traceID),
+		)
+	}
+}
+
+// parseTraceparent parses a W3C traceparent header, see https://www.w3.org/TR/trace-context/#traceparent-header.
+func parseTraceparent(header string) (string, string, bool, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return "", "", false, false
+	}
+
+	traceID, parentSpanID, flags := strings.ToLower(parts[1]), strings.ToLower(parts[2]), parts[3]
+
+	if !validID(traceID, 16) || !validID(parentSpanID, 8) || len(flags) != 2 {
+		return "", "", false, false
+	}
+
+	flagBytes, err := hex.DecodeString(flags)
+	if err != nil {
+		return "", "", false, false
+	}
+
+	return traceID, parentSpanID, flagBytes[0]&0x01 != 0, true
+}
+
+// validID reports whether id is the hex encoding of size bytes that are not all zero.
+func validID(id string, size int) bool {
+	decoded, err := hex.DecodeString(id)
+	if err != nil || len(decoded) != size {
+		return false
+	}
+
+	for _, b := range decoded {
+		if b != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// randomID returns size random bytes, hex encoded.
+func randomID(size int) string {
+	id := make([]byte, size)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
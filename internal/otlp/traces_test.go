@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package otlp
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrapeTrace(t *testing.T) {
+	t.Parallel()
+
+	requests := make(chan map[string]any, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		var request map[string]any
+		if err := json.NewDecoder(reader).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		requests <- request
+	}))
+	t.Cleanup(server.Close)
+
+	tracer, err := NewTracer(slog.New(slog.DiscardHandler), TracerOptions{Endpoint: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+
+	go tracer.Run(t.Context())
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	trace := tracer.StartScrape(req)
+	require.NotNil(t, trace)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.TraceID())
+
+	start := time.Unix(1700000000, 0)
+
+	trace.RecordCollector(collector.CollectorSpan{Collector: "cpu", Start: start, End: start.Add(time.Second), Backend: "collect", Status: "success", Metrics: 42})
+	trace.RecordCollector(collector.CollectorSpan{Collector: "service", Start: start, End: start.Add(5 * time.Second), Backend: "collect", Status: "timeout", Err: errors.New("context deadline exceeded")})
+	trace.End()
+
+	request := <-requests
+	spans := request["resourceSpans"].([]any)[0].(map[string]any)["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)
+	require.Len(t, spans, 3)
+
+	root := spans[0].(map[string]any)
+	require.Equal(t, "scrape", root["name"])
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", root["traceId"])
+	require.Equal(t, "00f067aa0ba902b7", root["parentSpanId"])
+	require.InDelta(t, statusCodeError, root["status"].(map[string]any)["code"], 0)
+
+	cpu := spans[1].(map[string]any)
+	require.Equal(t, "collect cpu", cpu["name"])
+	require.Equal(t, root["spanId"], cpu["parentSpanId"])
+	require.Equal(t, "1700000000000000000", cpu["startTimeUnixNano"])
+	require.Equal(t, "1700000001000000000", cpu["endTimeUnixNano"])
+	require.Contains(t, cpu["attributes"], map[string]any{"key": "windows_exporter.collector.metrics", "value": map[string]any{"intValue": "42"}})
+
+	service := spans[2].(map[string]any)
+	require.Equal(t, map[string]any{"code": float64(statusCodeError), "message": "context deadline exceeded"}, service["status"])
+}
+
+func TestStartScrapeNotSampled(t *testing.T) {
+	t.Parallel()
+
+	tracer, err := NewTracer(slog.New(slog.DiscardHandler), TracerOptions{Endpoint: "http://localhost:4318", Timeout: time.Second})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.Nil(t, tracer.StartScrape(req))
+
+	// Invalid traceparents start a new trace.
+	req.Header.Set("traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+
+	trace := tracer.StartScrape(req)
+	require.NotNil(t, trace)
+	require.Len(t, trace.TraceID(), 32)
+	require.NotEqual(t, "00000000000000000000000000000000", trace.TraceID())
+}
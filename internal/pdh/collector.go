@@ -28,6 +28,7 @@ import (
 	"sync"
 	"unsafe"
 
+	"github.com/prometheus-community/windows_exporter/internal/debugdump"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
 	"github.com/prometheus-community/windows_exporter/internal/osversion"
 	"github.com/prometheus/client_golang/prometheus"
@@ -289,7 +290,41 @@ func (c *Collector) Collect(dst any) error {
 
 	c.collectCh <- dst
 
-	return <-c.errorCh
+	err := <-c.errorCh
+	if err == nil && debugdump.Enabled() {
+		debugdump.Add(debugdump.SourcePDH, c.object, c.dumpValues(dst))
+	}
+
+	return err
+}
+
+// dumpValues returns the counter values of the instances in dst by counter name, see Collect.
+func (c *Collector) dumpValues(dst any) []map[string]any {
+	dv := reflect.ValueOf(dst).Elem()
+	values := make([]map[string]any, 0, dv.Len())
+
+	for i := range dv.Len() {
+		elem := dv.Index(i)
+		instance := make(map[string]any, len(c.counters)+1)
+
+		if c.nameIndexValue != -1 {
+			instance["Name"] = elem.Field(c.nameIndexValue).String()
+		}
+
+		for _, counter := range c.counters {
+			if counter.FieldIndexValue != -1 {
+				instance[counter.Name] = elem.Field(counter.FieldIndexValue).Float()
+			}
+
+			if counter.FieldIndexSecondValue != -1 {
+				instance[counter.Name+",secondvalue"] = elem.Field(counter.FieldIndexSecondValue).Float()
+			}
+		}
+
+		values = append(values, instance)
+	}
+
+	return values
 }
 
 func (c *Collector) collectWorkerRaw() {
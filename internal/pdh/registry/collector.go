@@ -32,6 +32,8 @@ type Collector struct {
 
 	counters       map[string]Counter
 	nameIndexValue int
+	// registered is true until the query is removed from the scrape snapshot by Close.
+	registered bool
 }
 
 type Counter struct {
@@ -46,6 +48,12 @@ type Counter struct {
 }
 
 func NewCollector[T any](object string, _ []string) (*Collector, error) {
+	// The object is queried by its index. Unknown objects would be queried as index 0,
+	// which returns no data instead of an error.
+	if CounterNameTable.LookupIndex(object) == 0 {
+		return nil, fmt.Errorf("performance object %s not found in the counter name table: %w", object, pdh.NewPdhError(pdh.CstatusNoObject))
+	}
+
 	collector := &Collector{
 		object:         object,
 		query:          MapCounterToIndex(object),
@@ -92,9 +100,14 @@ func NewCollector[T any](object string, _ []string) (*Collector, error) {
 		collector.counters[counterName] = counter
 	}
 
+	scrapeSnapshot.register(collector.query)
+	collector.registered = true
+
 	var collectValues []T
 
 	if err := collector.Collect(&collectValues); err != nil {
+		collector.Close()
+
 		return nil, fmt.Errorf("failed to collect initial data: %w", err)
 	}
 
@@ -120,7 +133,7 @@ func (c *Collector) Collect(data any) error {
 		return mi.ErrInvalidEntityType
 	}
 
-	perfObjects, err := QueryPerformanceData(c.query, c.object)
+	perfObjects, err := scrapeSnapshot.query(c.query, c.object)
 	if err != nil {
 		return fmt.Errorf("QueryPerformanceData: %w", err)
 	}
@@ -196,4 +209,9 @@ func (c *Collector) Collect(data any) error {
 	return nil
 }
 
-func (c *Collector) Close() {}
+func (c *Collector) Close() {
+	if c.registered {
+		scrapeSnapshot.unregister(c.query)
+		c.registered = false
+	}
+}
@@ -21,8 +21,14 @@ import (
 	"bytes"
 	"strconv"
 	"sync"
+
+	"golang.org/x/sys/windows"
 )
 
+// hkeyPerformanceNLSText is the predefined key that returns the name tables in the
+// language of the operating system. It is not defined in golang.org/x/sys/windows.
+const hkeyPerformanceNLSText = windows.Handle(0x80000060)
+
 // CounterNameTable Initialize global name tables
 // profiling, add option to disable name tables if necessary
 // Not sure if we should resolve the names at all or just have the caller do it on demand
@@ -31,6 +37,15 @@ import (
 //nolint:gochecknoglobals
 var CounterNameTable = *QueryNameTable("Counter 009")
 
+// LocalizedCounterNameTable contains the counter names in the language of the operating system,
+// as shown by perfmon. On English installations, it is identical to CounterNameTable.
+//
+//nolint:gochecknoglobals
+var LocalizedCounterNameTable = NameTable{
+	name: "Counter",
+	key:  hkeyPerformanceNLSText,
+}
+
 func (p *perfObjectType) LookupName() string {
 	return CounterNameTable.LookupString(p.ObjectNameTitleIndex)
 }
@@ -39,6 +54,7 @@ type NameTable struct {
 	once sync.Once
 
 	name string
+	key  windows.Handle
 
 	table struct {
 		index  map[uint32]string
@@ -52,6 +68,8 @@ func (t *NameTable) LookupString(index uint32) string {
 	return t.table.index[index]
 }
 
+// LookupIndex returns the index of the given name or 0 if the name is unknown.
+// Names are not unique, e.g. many objects share counter names. In this case, the lowest index is returned.
 func (t *NameTable) LookupIndex(str string) uint32 {
 	t.initialize()
 
@@ -63,36 +81,48 @@ func (t *NameTable) LookupIndex(str string) uint32 {
 func QueryNameTable(tableName string) *NameTable {
 	return &NameTable{
 		name: tableName,
+		key:  windows.HKEY_PERFORMANCE_DATA,
 	}
 }
 
 func (t *NameTable) initialize() {
 	t.once.Do(func() {
-		t.table.index = make(map[uint32]string)
-		t.table.string = make(map[string]uint32)
-
-		buffer, err := queryRawData(t.name)
+		buffer, err := queryRawDataFromKey(t.key, t.name)
 		if err != nil {
 			panic(err)
 		}
 
-		r := bytes.NewReader(buffer)
+		t.parse(buffer)
+	})
+}
+
+// parse reads a name table in the REG_MULTI_SZ format of alternating index and name strings.
+func (t *NameTable) parse(buffer []byte) {
+	t.table.index = make(map[uint32]string)
+	t.table.string = make(map[string]uint32)
 
-		for {
-			index, err := readUTF16String(r)
-			if err != nil {
-				break
-			}
+	r := bytes.NewReader(buffer)
 
-			desc, err := readUTF16String(r)
-			if err != nil {
-				break
-			}
+	for {
+		index, err := readUTF16String(r)
+		if err != nil {
+			break
+		}
+
+		desc, err := readUTF16String(r)
+		if err != nil {
+			break
+		}
 
-			indexInt, _ := strconv.Atoi(index)
+		indexInt, err := strconv.ParseUint(index, 10, 32)
+		if err != nil {
+			continue
+		}
+
+		t.table.index[uint32(indexInt)] = desc
 
-			t.table.index[uint32(indexInt)] = desc
+		if existing, ok := t.table.string[desc]; !ok || uint32(indexInt) < existing {
 			t.table.string[desc] = uint32(indexInt)
 		}
-	})
+	}
 }
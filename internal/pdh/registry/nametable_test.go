@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package registry
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestNameTable builds a name table from alternating index and name strings,
+// encoded like the REG_MULTI_SZ value returned by the registry.
+func newTestNameTable(t *testing.T, entries ...string) *NameTable {
+	t.Helper()
+
+	buffer := make([]byte, 0)
+
+	for _, entry := range entries {
+		for _, char := range utf16.Encode([]rune(entry + "\x00")) {
+			buffer = binary.LittleEndian.AppendUint16(buffer, char)
+		}
+	}
+
+	table := &NameTable{}
+	table.once.Do(func() {
+		table.parse(buffer)
+	})
+
+	return table
+}
+
+func TestNameTableParse(t *testing.T) {
+	t.Parallel()
+
+	table := newTestNameTable(t,
+		"6", "% Processor Time",
+		"230", "Process",
+		"238", "Processor",
+		"invalid", "Ignored",
+		"1412", "% Processor Time",
+	)
+
+	require.Equal(t, "Process", table.LookupString(230))
+	require.Equal(t, uint32(238), table.LookupIndex("Processor"))
+	require.Equal(t, uint32(6), table.LookupIndex("% Processor Time"))
+	require.Equal(t, "% Processor Time", table.LookupString(1412))
+	require.Zero(t, table.LookupIndex("Ignored"))
+	require.Zero(t, table.LookupIndex("Unknown"))
+}
+
+func TestTranslateCounterName(t *testing.T) {
+	t.Parallel()
+
+	english := newTestNameTable(t,
+		"6", "% Processor Time",
+		"230", "Process",
+		"238", "Processor",
+		"1848", "Processor Information",
+	)
+
+	require.Equal(t, "Prozessor", translateCounterName("Processor", english, newTestNameTable(t, "238", "Prozessor")))
+
+	for _, tc := range []struct {
+		language  string
+		localized *NameTable
+		names     map[string]string
+	}{
+		{
+			language: "German",
+			localized: newTestNameTable(t,
+				"6", "Prozessorzeit (%)",
+				"230", "Prozess",
+				"238", "Prozessor",
+				"1848", "Prozessorinformationen",
+			),
+			names: map[string]string{
+				"Prozessorzeit (%)":      "% Processor Time",
+				"Prozess":                "Process",
+				"Prozessorinformationen": "Processor Information",
+			},
+		},
+		{
+			language: "French",
+			localized: newTestNameTable(t,
+				"6", "% temps processeur",
+				"230", "Processus",
+				"238", "Processeur",
+				"1848", "Informations sur le processeur",
+			),
+			names: map[string]string{
+				"% temps processeur":             "% Processor Time",
+				"Processus":                      "Process",
+				"Informations sur le processeur": "Processor Information",
+			},
+		},
+		{
+			language: "Japanese",
+			localized: newTestNameTable(t,
+				"6", "% Processor Time",
+				"230", "プロセス",
+				"238", "プロセッサ",
+				"1848", "プロセッサ情報",
+			),
+			names: map[string]string{
+				"% Processor Time": "% Processor Time",
+				"プロセス":             "Process",
+				"プロセッサ情報":          "Processor Information",
+			},
+		},
+	} {
+		t.Run(tc.language, func(t *testing.T) {
+			t.Parallel()
+
+			for localizedName, englishName := range tc.names {
+				require.Equal(t, englishName, translateCounterName(localizedName, tc.localized, english))
+				require.Equal(t, englishName, translateCounterName(englishName, tc.localized, english))
+			}
+
+			require.Equal(t, "Unknown", translateCounterName("Unknown", tc.localized, english))
+		})
+	}
+}
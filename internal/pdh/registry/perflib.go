@@ -205,6 +205,11 @@ var (
 // queryRawData Queries the performance counter buffer using RegQueryValueEx, returning raw bytes. See:
 // https://msdn.microsoft.com/de-de/library/windows/desktop/aa373219(v=vs.85).aspx
 func queryRawData(query string) ([]byte, error) {
+	return queryRawDataFromKey(windows.HKEY_PERFORMANCE_DATA, query)
+}
+
+// queryRawDataFromKey is queryRawData for any predefined performance key, e.g. HKEY_PERFORMANCE_NLSTEXT.
+func queryRawDataFromKey(key windows.Handle, query string) ([]byte, error) {
 	var (
 		valType uint32
 		buffer  []byte
@@ -234,7 +239,7 @@ func queryRawData(query string) ([]byte, error) {
 		bufLen := uint32(len(buffer))
 
 		err := windows.RegQueryValueEx(
-			windows.HKEY_PERFORMANCE_DATA,
+			key,
 			name,
 			nil,
 			&valType,
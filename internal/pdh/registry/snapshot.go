@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package registry
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// snapshot shares one read of HKEY_PERFORMANCE_DATA between the registry collectors of the running scrapes.
+// The registry returns all objects of a query in one buffer, so one read of the objects of all collectors is
+// cheaper than one read per collector. PDH collectors keep their own queries, because a hung provider would
+// otherwise block all collectors of the snapshot.
+type snapshot struct {
+	mu sync.Mutex
+	// scrapes is the number of running scrapes, see BeginScrape.
+	scrapes int
+	// queries are the object indices of the registry collectors with the number of collectors that use them,
+	// see NewCollector and Collector.Close.
+	queries map[string]int
+	// taken is true once the objects were read for the running scrapes.
+	taken   bool
+	objects []*PerfObject
+	err     error
+}
+
+//nolint:gochecknoglobals
+var scrapeSnapshot = &snapshot{queries: make(map[string]int)}
+
+// BeginScrape marks the start of a scrape. Until the returned func is called, the registry collectors share one
+// read of the performance data. A scrape that begins while others are running takes a new snapshot, so the
+// data is never older than the start of the scrape.
+func BeginScrape() func() {
+	scrapeSnapshot.mu.Lock()
+	defer scrapeSnapshot.mu.Unlock()
+
+	scrapeSnapshot.scrapes++
+	scrapeSnapshot.reset()
+
+	return func() {
+		scrapeSnapshot.mu.Lock()
+		defer scrapeSnapshot.mu.Unlock()
+
+		scrapeSnapshot.scrapes--
+		if scrapeSnapshot.scrapes == 0 {
+			scrapeSnapshot.reset()
+		}
+	}
+}
+
+func (s *snapshot) register(query string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queries[query]++
+}
+
+// unregister removes the query of a closed collector, so the snapshot doesn't read objects that are no longer
+// collected, e.g. after a reload.
+func (s *snapshot) unregister(query string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queries[query]--
+	if s.queries[query] <= 0 {
+		delete(s.queries, query)
+	}
+}
+
+func (s *snapshot) reset() {
+	s.taken = false
+	s.objects = nil
+	s.err = nil
+}
+
+// query returns the objects with the given name. During a scrape they are taken from the snapshot,
+// otherwise the query is read directly.
+func (s *snapshot) query(query string, object string) ([]*PerfObject, error) {
+	s.mu.Lock()
+
+	if _, ok := s.queries[query]; s.scrapes == 0 || !ok {
+		s.mu.Unlock()
+
+		return QueryPerformanceData(query, object)
+	}
+
+	defer s.mu.Unlock()
+
+	if !s.taken {
+		s.objects, s.err = QueryPerformanceData(strings.Join(slices.Sorted(maps.Keys(s.queries)), " "), "")
+		s.taken = true
+	}
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	objects := make([]*PerfObject, 0, 1)
+
+	for _, perfObject := range s.objects {
+		if perfObject != nil && perfObject.Name == object {
+			objects = append(objects, perfObject)
+		}
+	}
+
+	return objects, nil
+}
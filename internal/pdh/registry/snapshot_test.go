@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotUnregister(t *testing.T) {
+	t.Parallel()
+
+	s := &snapshot{queries: make(map[string]int)}
+
+	// The old and the new collector of a reload share the query until the old one is closed.
+	s.register("230")
+	s.register("230")
+	s.register("238")
+	require.Equal(t, map[string]int{"230": 2, "238": 1}, s.queries)
+
+	s.unregister("230")
+	s.unregister("238")
+	require.Equal(t, map[string]int{"230": 1}, s.queries)
+
+	s.unregister("230")
+	require.Empty(t, s.queries)
+}
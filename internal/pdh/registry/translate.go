@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package registry
+
+// EnglishCounterName translates a performance object or counter name to the English name by its index.
+// Performance counters are registered by index, and each index has a name per installed language.
+// Names that are already English or unknown are returned unchanged.
+func EnglishCounterName(name string) string {
+	return translateCounterName(name, &LocalizedCounterNameTable, &CounterNameTable)
+}
+
+// LocalizedCounterName translates an English performance object or counter name to the language
+// of the operating system. Unknown names are returned unchanged.
+func LocalizedCounterName(name string) string {
+	return translateCounterName(name, &CounterNameTable, &LocalizedCounterNameTable)
+}
+
+func translateCounterName(name string, from, to *NameTable) string {
+	if to.LookupIndex(name) != 0 {
+		return name
+	}
+
+	index := from.LookupIndex(name)
+	if index == 0 {
+		return name
+	}
+
+	// Names shared by several counters, e.g. "% Processor Time", are translated by their lowest index.
+	if translated := to.LookupString(index); translated != "" {
+		return translated
+	}
+
+	return name
+}
@@ -0,0 +1,361 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+// Package relabel adds static labels to, drops and renames labels of the collected metrics,
+// for backends whose relabeling can't be configured, e.g. managed Prometheus services.
+package relabel
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.yaml.in/yaml/v3"
+)
+
+//nolint:gochecknoglobals
+var (
+	labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+	// targetLabelNames are set by Prometheus from the scrape target. Tenant labels with these names would be
+	// renamed to exported_<name> by scrapes without honor_labels, so they are rejected.
+	targetLabelNames = []string{"instance", "job"}
+)
+
+// Config is the content of the relabeling file, see --relabel.config-file.
+type Config struct {
+	// StaticLabels are added to all series. Labels of a series with the same name take precedence.
+	StaticLabels map[string]string `yaml:"static_labels"`
+	// Drop are the rules of series that are dropped.
+	Drop []DropRule `yaml:"drop"`
+	// RenameLabels are the rules of labels that are renamed, in order.
+	RenameLabels []RenameRule `yaml:"rename_labels"`
+}
+
+// DropRule drops the series whose metric name matches Metric and whose label values match all Labels.
+// The regular expressions are anchored. Missing labels match as empty value.
+type DropRule struct {
+	Metric string            `yaml:"metric"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+// RenameRule renames the label From to To on the series whose metric name matches Metric.
+// If Metric is empty, the label is renamed on all series.
+type RenameRule struct {
+	From   string `yaml:"from"`
+	To     string `yaml:"to"`
+	Metric string `yaml:"metric"`
+}
+
+// Rules are the compiled rules of a Config. A nil *Rules leaves the metrics unchanged.
+type Rules struct {
+	staticLabels []*dto.LabelPair
+	tenantLabels []*dto.LabelPair
+	drops        []dropRule
+	renames      []renameRule
+}
+
+type dropRule struct {
+	metric *regexp.Regexp
+	labels map[string]*regexp.Regexp
+}
+
+type renameRule struct {
+	from   string
+	to     string
+	metric *regexp.Regexp
+}
+
+// Load reads the relabeling file. An empty path returns nil rules.
+func Load(path string) (*Rules, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open relabel config file: %w", err)
+	}
+
+	defer file.Close()
+
+	var config Config
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	// An empty file is a valid configuration without rules.
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse relabel config file: %w", err)
+	}
+
+	return New(config)
+}
+
+// New compiles the rules of the configuration.
+func New(config Config) (*Rules, error) {
+	rules := &Rules{}
+
+	for _, name := range slices.Sorted(maps.Keys(config.StaticLabels)) {
+		if err := validateLabelName(name); err != nil {
+			return nil, fmt.Errorf("static label: %w", err)
+		}
+
+		value := config.StaticLabels[name]
+		rules.staticLabels = append(rules.staticLabels, &dto.LabelPair{Name: &name, Value: &value})
+	}
+
+	for i, drop := range config.Drop {
+		if drop.Metric == "" && len(drop.Labels) == 0 {
+			return nil, fmt.Errorf("drop rule %d: metric or labels are required", i+1)
+		}
+
+		rule := dropRule{labels: make(map[string]*regexp.Regexp, len(drop.Labels))}
+
+		var err error
+
+		if rule.metric, err = compileAnchored(cmp.Or(drop.Metric, ".*")); err != nil {
+			return nil, fmt.Errorf("drop rule %d: metric: %w", i+1, err)
+		}
+
+		for name, value := range drop.Labels {
+			if rule.labels[name], err = compileAnchored(value); err != nil {
+				return nil, fmt.Errorf("drop rule %d: label %s: %w", i+1, name, err)
+			}
+		}
+
+		rules.drops = append(rules.drops, rule)
+	}
+
+	for i, rename := range config.RenameLabels {
+		if err := validateLabelName(rename.From); err != nil {
+			return nil, fmt.Errorf("rename rule %d: from: %w", i+1, err)
+		}
+
+		if err := validateLabelName(rename.To); err != nil {
+			return nil, fmt.Errorf("rename rule %d: to: %w", i+1, err)
+		}
+
+		rule := renameRule{from: rename.From, to: rename.To}
+
+		if rename.Metric != "" {
+			var err error
+
+			if rule.metric, err = compileAnchored(rename.Metric); err != nil {
+				return nil, fmt.Errorf("rename rule %d: metric: %w", i+1, err)
+			}
+		}
+
+		rules.renames = append(rules.renames, rule)
+	}
+
+	return rules, nil
+}
+
+// WithTenantLabels returns a copy of the rules that stamps the tenant labels on all series, e.g. the customer
+// and the environment of the host for pipelines that scrape the hosts of several tenants.
+// Unlike static labels, tenant labels replace labels of a series with the same name, so the metrics of a collector,
+// e.g. of text files, can't claim another tenant. If r is nil, the rules only stamp the tenant labels.
+func (r *Rules) WithTenantLabels(labels map[string]string) (*Rules, error) {
+	if len(labels) == 0 {
+		return r, nil
+	}
+
+	rules := &Rules{}
+	if r != nil {
+		*rules = *r
+	}
+
+	rules.tenantLabels = make([]*dto.LabelPair, 0, len(labels))
+
+	for _, name := range slices.Sorted(maps.Keys(labels)) {
+		if err := validateLabelName(name); err != nil {
+			return nil, fmt.Errorf("tenant label: %w", err)
+		}
+
+		if slices.Contains(targetLabelNames, name) {
+			return nil, fmt.Errorf("tenant label: label name %q is set by Prometheus from the scrape target", name)
+		}
+
+		value := labels[name]
+		if value == "" {
+			return nil, fmt.Errorf("tenant label %s: empty value", name)
+		}
+
+		rules.tenantLabels = append(rules.tenantLabels, &dto.LabelPair{Name: &name, Value: &value})
+	}
+
+	return rules, nil
+}
+
+func validateLabelName(name string) error {
+	if !labelNameRegexp.MatchString(name) || strings.HasPrefix(name, "__") {
+		return fmt.Errorf("invalid label name %q", name)
+	}
+
+	return nil
+}
+
+func compileAnchored(expr string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + expr + ")$")
+}
+
+// Apply drops the matching series, renames the labels, adds the static labels and stamps the tenant labels, in this order.
+// Families without remaining series are removed. The metrics of families are copied, not modified,
+// because families may share their metrics, e.g. with legacy metric names.
+func (r *Rules) Apply(families []*dto.MetricFamily) []*dto.MetricFamily {
+	if r == nil {
+		return families
+	}
+
+	result := make([]*dto.MetricFamily, 0, len(families))
+
+	for _, family := range families {
+		metrics := make([]*dto.Metric, 0, len(family.GetMetric()))
+
+		for _, metric := range family.GetMetric() {
+			if r.dropped(family.GetName(), metric) {
+				continue
+			}
+
+			metrics = append(metrics, r.relabel(family.GetName(), metric))
+		}
+
+		if len(metrics) == 0 && len(family.GetMetric()) > 0 {
+			continue
+		}
+
+		result = append(result, &dto.MetricFamily{
+			Name:   family.Name,
+			Help:   family.Help,
+			Type:   family.Type,
+			Unit:   family.Unit,
+			Metric: metrics,
+		})
+	}
+
+	return result
+}
+
+func (r *Rules) dropped(name string, metric *dto.Metric) bool {
+	for _, rule := range r.drops {
+		if !rule.metric.MatchString(name) {
+			continue
+		}
+
+		matched := true
+
+		for labelName, value := range rule.labels {
+			if !value.MatchString(labelValue(metric, labelName)) {
+				matched = false
+
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Rules) relabel(name string, metric *dto.Metric) *dto.Metric {
+	labels := slices.Clone(metric.GetLabel())
+
+	for _, rule := range r.renames {
+		if rule.metric != nil && !rule.metric.MatchString(name) {
+			continue
+		}
+
+		i := slices.IndexFunc(labels, func(label *dto.LabelPair) bool { return label.GetName() == rule.from })
+		// A rename to an existing label would lose one of the values, so the label is kept.
+		if i == -1 || slices.ContainsFunc(labels, func(label *dto.LabelPair) bool { return label.GetName() == rule.to }) {
+			continue
+		}
+
+		labels[i] = &dto.LabelPair{Name: &rule.to, Value: labels[i].Value}
+	}
+
+	for _, static := range r.staticLabels {
+		if !slices.ContainsFunc(labels, func(label *dto.LabelPair) bool { return label.GetName() == static.GetName() }) {
+			labels = append(labels, static)
+		}
+	}
+
+	for _, tenant := range r.tenantLabels {
+		if i := slices.IndexFunc(labels, func(label *dto.LabelPair) bool { return label.GetName() == tenant.GetName() }); i != -1 {
+			labels[i] = tenant
+		} else {
+			labels = append(labels, tenant)
+		}
+	}
+
+	slices.SortFunc(labels, func(a, b *dto.LabelPair) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+
+	return &dto.Metric{
+		Label:       labels,
+		Gauge:       metric.Gauge,
+		Counter:     metric.Counter,
+		Summary:     metric.Summary,
+		Untyped:     metric.Untyped,
+		Histogram:   metric.Histogram,
+		TimestampMs: metric.TimestampMs,
+	}
+}
+
+func labelValue(metric *dto.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+
+	return ""
+}
+
+// NewGatherer returns a gatherer that applies the rules to the metric families of g.
+// If rules is nil, g is returned.
+func NewGatherer(g prometheus.Gatherer, rules *Rules) prometheus.Gatherer {
+	if rules == nil {
+		return g
+	}
+
+	return gatherer{gatherer: g, rules: rules}
+}
+
+type gatherer struct {
+	gatherer prometheus.Gatherer
+	rules    *Rules
+}
+
+func (g gatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	return g.rules.Apply(families), err
+}
@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package relabel_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus-community/windows_exporter/internal/relabel"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func family(name string, metrics ...*dto.Metric) *dto.MetricFamily {
+	return &dto.MetricFamily{Name: proto.String(name), Type: dto.MetricType_GAUGE.Enum(), Metric: metrics}
+}
+
+func metric(value float64, labels ...string) *dto.Metric {
+	m := &dto.Metric{Gauge: &dto.Gauge{Value: proto.Float64(value)}}
+
+	for i := 0; i < len(labels); i += 2 {
+		m.Label = append(m.Label, &dto.LabelPair{Name: proto.String(labels[i]), Value: proto.String(labels[i+1])})
+	}
+
+	return m
+}
+
+func labels(m *dto.Metric) map[string]string {
+	result := make(map[string]string)
+	for _, label := range m.GetLabel() {
+		result[label.GetName()] = label.GetValue()
+	}
+
+	return result
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	rules, err := relabel.New(relabel.Config{
+		StaticLabels: map[string]string{"datacenter": "fra1", "volume": "static"},
+		Drop: []relabel.DropRule{
+			{Metric: "windows_service_.*", Labels: map[string]string{"name": "wuauserv|bits"}},
+			{Metric: "windows_net_packets_total"},
+		},
+		RenameLabels: []relabel.RenameRule{
+			{From: "volume", To: "mountpoint", Metric: "windows_logical_disk_.*"},
+			{From: "core", To: "cpu"},
+		},
+	})
+	require.NoError(t, err)
+
+	shared := []*dto.Metric{metric(1, "volume", "C:")}
+
+	families := rules.Apply([]*dto.MetricFamily{
+		family("windows_service_state", metric(1, "name", "wuauserv"), metric(1, "name", "spooler")),
+		family("windows_net_packets_total", metric(1, "nic", "eth0")),
+		family("windows_logical_disk_free_bytes", shared...),
+		family("windows_volume_free_bytes", shared...),
+		family("windows_cpu_time_total", metric(1, "core", "0,0", "cpu", "x")),
+		family("windows_exporter_build_info"),
+	})
+
+	require.Len(t, families, 5)
+
+	// Only the series of the matching service are dropped, families without series are removed.
+	require.Equal(t, "windows_service_state", families[0].GetName())
+	require.Len(t, families[0].GetMetric(), 1)
+	require.Equal(t, map[string]string{"name": "spooler", "datacenter": "fra1", "volume": "static"}, labels(families[0].GetMetric()[0]))
+
+	// Labels of a series take precedence over static labels. Families sharing their metrics are relabeled independently.
+	require.Equal(t, map[string]string{"mountpoint": "C:", "datacenter": "fra1", "volume": "static"}, labels(families[1].GetMetric()[0]))
+	require.Equal(t, map[string]string{"volume": "C:", "datacenter": "fra1"}, labels(families[2].GetMetric()[0]))
+	require.Equal(t, "volume", shared[0].GetLabel()[0].GetName())
+
+	// A rename to an existing label keeps the label.
+	require.Equal(t, map[string]string{"core": "0,0", "cpu": "x", "datacenter": "fra1", "volume": "static"}, labels(families[3].GetMetric()[0]))
+
+	// Labels are sorted by name.
+	names := make([]string, 0)
+	for _, label := range families[3].GetMetric()[0].GetLabel() {
+		names = append(names, label.GetName())
+	}
+
+	require.IsIncreasing(t, names)
+
+	// Families without series are kept.
+	require.Equal(t, "windows_exporter_build_info", families[4].GetName())
+}
+
+func TestNewInvalid(t *testing.T) {
+	t.Parallel()
+
+	for name, config := range map[string]relabel.Config{
+		"static label name":   {StaticLabels: map[string]string{"data-center": "fra1"}},
+		"reserved label name": {StaticLabels: map[string]string{"__name__": "x"}},
+		"empty drop rule":     {Drop: []relabel.DropRule{{}}},
+		"drop regexp":         {Drop: []relabel.DropRule{{Metric: "windows_("}}},
+		"rename to":           {RenameLabels: []relabel.RenameRule{{From: "volume", To: ""}}},
+	} {
+		_, err := relabel.New(config)
+		require.Error(t, err, name)
+	}
+}
+
+func TestWithTenantLabels(t *testing.T) {
+	t.Parallel()
+
+	rules, err := relabel.New(relabel.Config{StaticLabels: map[string]string{"environment": "static"}})
+	require.NoError(t, err)
+
+	tenantRules, err := rules.WithTenantLabels(map[string]string{"tenant": "contoso", "environment": "prod"})
+	require.NoError(t, err)
+
+	families := tenantRules.Apply([]*dto.MetricFamily{
+		family("windows_textfile_info", metric(1, "tenant", "fabrikam")),
+	})
+
+	// Tenant labels replace the labels of a series and the static labels.
+	require.Equal(t, map[string]string{"tenant": "contoso", "environment": "prod"}, labels(families[0].GetMetric()[0]))
+
+	// The rules are copied.
+	families = rules.Apply([]*dto.MetricFamily{family("windows_os_info", metric(1))})
+	require.Equal(t, map[string]string{"environment": "static"}, labels(families[0].GetMetric()[0]))
+
+	// Nil rules only stamp the tenant labels.
+	var noRules *relabel.Rules
+
+	tenantRules, err = noRules.WithTenantLabels(map[string]string{"tenant": "contoso"})
+	require.NoError(t, err)
+
+	families = tenantRules.Apply([]*dto.MetricFamily{family("windows_os_info", metric(1))})
+	require.Equal(t, map[string]string{"tenant": "contoso"}, labels(families[0].GetMetric()[0]))
+
+	for name, tenantLabels := range map[string]map[string]string{
+		"label name":        {"tenant-id": "contoso"},
+		"target label name": {"instance": "contoso"},
+		"empty value":       {"tenant": ""},
+	} {
+		_, err := rules.WithTenantLabels(tenantLabels)
+		require.Error(t, err, name)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	rules, err := relabel.Load("")
+	require.NoError(t, err)
+	require.Nil(t, rules)
+
+	path := filepath.Join(t.TempDir(), "relabel.yml")
+	require.NoError(t, os.WriteFile(path, []byte("static_labels:\n  role: sql\ndrop:\n  - metric: windows_cpu_.*\n"), 0o600))
+
+	rules, err = relabel.Load(path)
+	require.NoError(t, err)
+
+	families := rules.Apply([]*dto.MetricFamily{
+		family("windows_cpu_time_total", metric(1)),
+		family("windows_os_info", metric(1)),
+	})
+	require.Len(t, families, 1)
+	require.Equal(t, map[string]string{"role": "sql"}, labels(families[0].GetMetric()[0]))
+
+	require.NoError(t, os.WriteFile(path, []byte("static_label:\n  role: sql\n"), 0o600))
+
+	_, err = relabel.Load(path)
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	rules, err = relabel.Load(path)
+	require.NoError(t, err)
+	require.NotNil(t, rules)
+}
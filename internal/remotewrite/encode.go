@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package remotewrite
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the remote write 1.0 messages of prompb.
+// 📑 https://github.com/prometheus/prometheus/blob/main/prompb/remote.proto
+const (
+	writeRequestTimeseries = 1
+	writeRequestMetadata   = 3
+
+	timeSeriesLabels  = 1
+	timeSeriesSamples = 2
+
+	labelName  = 1
+	labelValue = 2
+
+	sampleValue     = 1
+	sampleTimestamp = 2
+
+	metadataType             = 1
+	metadataMetricFamilyName = 2
+	metadataHelp             = 4
+)
+
+// Metric types of MetricMetadata.
+const (
+	metadataTypeUnknown   = 0
+	metadataTypeCounter   = 1
+	metadataTypeGauge     = 2
+	metadataTypeHistogram = 3
+	metadataTypeSummary   = 5
+)
+
+type label struct {
+	name, value string
+}
+
+// encodeWriteRequest returns the WriteRequest of the metric families in the protobuf encoding.
+// labels are added to all series unless a metric has a label of the same name.
+// Metrics without timestamp get the timestamp now.
+func encodeWriteRequest(families []*dto.MetricFamily, labels map[string]string, now time.Time) []byte {
+	var request []byte
+
+	timestamp := now.UnixMilli()
+
+	for _, family := range families {
+		name := family.GetName()
+
+		for _, metric := range family.GetMetric() {
+			metricTimestamp := timestamp
+			if metric.TimestampMs != nil {
+				metricTimestamp = metric.GetTimestampMs()
+			}
+
+			seriesLabels := make([]label, 0, len(metric.GetLabel())+len(labels)+2)
+			for _, pair := range metric.GetLabel() {
+				seriesLabels = append(seriesLabels, label{pair.GetName(), pair.GetValue()})
+			}
+
+			for extraName, extraValue := range labels {
+				if !slices.ContainsFunc(seriesLabels, func(l label) bool { return l.name == extraName }) {
+					seriesLabels = append(seriesLabels, label{extraName, extraValue})
+				}
+			}
+
+			appendSeries := func(name string, value float64, extra ...label) {
+				series := appendTimeSeries(nil, name, append(slices.Clip(seriesLabels), extra...), value, metricTimestamp)
+				request = protowire.AppendTag(request, writeRequestTimeseries, protowire.BytesType)
+				request = protowire.AppendBytes(request, series)
+			}
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				appendSeries(name, metric.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				appendSeries(name, metric.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				appendSeries(name, metric.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				histogram := metric.GetHistogram()
+
+				for _, bucket := range histogram.GetBucket() {
+					if math.IsInf(bucket.GetUpperBound(), +1) {
+						continue
+					}
+
+					appendSeries(name+"_bucket", float64(bucket.GetCumulativeCount()), label{"le", formatFloat(bucket.GetUpperBound())})
+				}
+
+				appendSeries(name+"_bucket", float64(histogram.GetSampleCount()), label{"le", "+Inf"})
+				appendSeries(name+"_sum", histogram.GetSampleSum())
+				appendSeries(name+"_count", float64(histogram.GetSampleCount()))
+			case dto.MetricType_SUMMARY:
+				summary := metric.GetSummary()
+
+				for _, quantile := range summary.GetQuantile() {
+					appendSeries(name, quantile.GetValue(), label{"quantile", formatFloat(quantile.GetQuantile())})
+				}
+
+				appendSeries(name+"_sum", summary.GetSampleSum())
+				appendSeries(name+"_count", float64(summary.GetSampleCount()))
+			}
+		}
+
+		request = protowire.AppendTag(request, writeRequestMetadata, protowire.BytesType)
+		request = protowire.AppendBytes(request, appendMetadata(nil, family))
+	}
+
+	return request
+}
+
+// appendTimeSeries appends a TimeSeries with one sample. The labels are sorted by name, as required by the protocol.
+func appendTimeSeries(b []byte, name string, labels []label, value float64, timestamp int64) []byte {
+	labels = append(labels, label{"__name__", name})
+	slices.SortFunc(labels, func(a, b label) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	for _, l := range labels {
+		var encoded []byte
+		encoded = protowire.AppendTag(encoded, labelName, protowire.BytesType)
+		encoded = protowire.AppendString(encoded, l.name)
+		encoded = protowire.AppendTag(encoded, labelValue, protowire.BytesType)
+		encoded = protowire.AppendString(encoded, l.value)
+
+		b = protowire.AppendTag(b, timeSeriesLabels, protowire.BytesType)
+		b = protowire.AppendBytes(b, encoded)
+	}
+
+	var sample []byte
+	sample = protowire.AppendTag(sample, sampleValue, protowire.Fixed64Type)
+	sample = protowire.AppendFixed64(sample, math.Float64bits(value))
+	sample = protowire.AppendTag(sample, sampleTimestamp, protowire.VarintType)
+	sample = protowire.AppendVarint(sample, uint64(timestamp))
+
+	b = protowire.AppendTag(b, timeSeriesSamples, protowire.BytesType)
+
+	return protowire.AppendBytes(b, sample)
+}
+
+func appendMetadata(b []byte, family *dto.MetricFamily) []byte {
+	var metricType uint64
+
+	switch family.GetType() {
+	case dto.MetricType_COUNTER:
+		metricType = metadataTypeCounter
+	case dto.MetricType_GAUGE:
+		metricType = metadataTypeGauge
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		metricType = metadataTypeHistogram
+	case dto.MetricType_SUMMARY:
+		metricType = metadataTypeSummary
+	default:
+		metricType = metadataTypeUnknown
+	}
+
+	b = protowire.AppendTag(b, metadataType, protowire.VarintType)
+	b = protowire.AppendVarint(b, metricType)
+	b = protowire.AppendTag(b, metadataMetricFamilyName, protowire.BytesType)
+	b = protowire.AppendString(b, family.GetName())
+	b = protowire.AppendTag(b, metadataHelp, protowire.BytesType)
+
+	return protowire.AppendString(b, family.GetHelp())
+}
+
+// formatFloat formats the le and quantile labels like the text exposition format.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
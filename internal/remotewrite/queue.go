@@ -0,0 +1,259 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package remotewrite
+
+import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// walExtension is the extension of the files of the write-ahead log, which each hold one compressed WriteRequest.
+const walExtension = ".rw"
+
+// batch is a compressed WriteRequest in the queue. data is nil if the batch is stored in the write-ahead log.
+type batch struct {
+	seq  uint64
+	size int64
+	data []byte
+}
+
+// queue buffers the batches of an endpoint until they are sent, in memory or in a directory of the write-ahead log.
+// If the batches exceed maxSize, the oldest batches are dropped.
+type queue struct {
+	logger  *slog.Logger
+	dir     string
+	maxSize int64
+
+	mu      sync.Mutex
+	batches []batch
+	size    int64
+	nextSeq uint64
+	// notify is signaled when a batch is added.
+	notify chan struct{}
+}
+
+// newQueue returns the queue of the endpoint url. If walDir is not empty, the batches are stored
+// in a subdirectory of walDir, and the batches that were not sent before a restart are loaded.
+func newQueue(logger *slog.Logger, url, walDir string, maxSize int64) (*queue, error) {
+	q := &queue{
+		logger:  logger,
+		maxSize: maxSize,
+		notify:  make(chan struct{}, 1),
+	}
+
+	if walDir == "" {
+		return q, nil
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(url))
+
+	q.dir = filepath.Join(walDir, strconv.FormatUint(hash.Sum64(), 16))
+
+	if err := os.MkdirAll(q.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create write-ahead log directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read write-ahead log directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		// Temporary files of batches that were not completely written.
+		if !strings.HasSuffix(name, walExtension) {
+			_ = os.Remove(filepath.Join(q.dir, name))
+
+			continue
+		}
+
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, walExtension), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		q.batches = append(q.batches, batch{seq: seq, size: info.Size()})
+		q.size += info.Size()
+		q.nextSeq = max(q.nextSeq, seq+1)
+	}
+
+	slices.SortFunc(q.batches, func(a, b batch) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+
+	if len(q.batches) > 0 {
+		q.logger.Info("loaded unsent batches from the write-ahead log",
+			slog.Int("batches", len(q.batches)),
+			slog.Int64("bytes", q.size),
+		)
+	}
+
+	q.trim()
+
+	return q, nil
+}
+
+func (q *queue) path(seq uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", seq, walExtension))
+}
+
+// add appends a batch to the queue. In the write-ahead log, the batch is written to a temporary file,
+// which is renamed after it was synced, so a crash never leaves a partial batch.
+func (q *queue) add(data []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b := batch{seq: q.nextSeq, size: int64(len(data)), data: data}
+
+	if q.dir != "" {
+		if err := q.write(b.seq, data); err != nil {
+			return err
+		}
+
+		b.data = nil
+	}
+
+	q.nextSeq++
+	q.batches = append(q.batches, b)
+	q.size += b.size
+
+	q.trim()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (q *queue) write(seq uint64, data []byte) error {
+	file, err := os.CreateTemp(q.dir, "*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create batch file: %w", err)
+	}
+
+	if _, err = file.Write(data); err == nil {
+		err = file.Sync()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), q.path(seq))
+	}
+
+	if err != nil {
+		_ = os.Remove(file.Name())
+
+		return fmt.Errorf("failed to write batch to the write-ahead log: %w", err)
+	}
+
+	return nil
+}
+
+// trim drops the oldest batches while the queue exceeds maxSize. The newest batch is always kept.
+func (q *queue) trim() {
+	var dropped int
+
+	for q.size > q.maxSize && len(q.batches) > 1 {
+		q.removeLocked(q.batches[0].seq)
+
+		dropped++
+	}
+
+	if dropped > 0 {
+		q.logger.Warn("dropped the oldest batches, since the queue exceeds its maximum size",
+			slog.Int("batches", dropped),
+			slog.Int64("max_size", q.maxSize),
+		)
+	}
+}
+
+// oldest returns the oldest batch and its data.
+func (q *queue) oldest() (batch, []byte, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.batches) == 0 {
+		return batch{}, nil, false, nil
+	}
+
+	b := q.batches[0]
+	if b.data != nil {
+		return b, b.data, true, nil
+	}
+
+	data, err := os.ReadFile(q.path(b.seq))
+	if err != nil {
+		return b, nil, true, fmt.Errorf("failed to read batch from the write-ahead log: %w", err)
+	}
+
+	return b, data, true, nil
+}
+
+// remove removes the batch from the queue, after it was sent or rejected.
+func (q *queue) remove(seq uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.removeLocked(seq)
+}
+
+func (q *queue) removeLocked(seq uint64) {
+	i := slices.IndexFunc(q.batches, func(b batch) bool { return b.seq == seq })
+	if i < 0 {
+		return
+	}
+
+	q.size -= q.batches[i].size
+	q.batches = slices.Delete(q.batches, i, i+1)
+
+	if q.dir != "" {
+		if err := os.Remove(q.path(seq)); err != nil && !os.IsNotExist(err) {
+			q.logger.Warn("failed to remove batch from the write-ahead log",
+				slog.Any("err", err),
+			)
+		}
+	}
+}
+
+// len returns the number of batches in the queue.
+func (q *queue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.batches)
+}
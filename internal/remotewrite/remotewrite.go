@@ -0,0 +1,383 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+// Package remotewrite pushes the metrics of windows_exporter to Prometheus remote write endpoints,
+// so windows_exporter can run as an agent without a separate scraper. Batches that couldn't be sent,
+// e.g. during a network outage, are buffered in a write-ahead log on disk and sent in order once the endpoint is reachable.
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/version"
+)
+
+// Delays between two attempts to send a batch to an endpoint that is not reachable.
+const (
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// GatherFunc collects the metric families to push within the given timeout.
+type GatherFunc func(timeout time.Duration) ([]*dto.MetricFamily, error)
+
+type Options struct {
+	// URLs are the remote write endpoints. Each endpoint has its own queue.
+	URLs []string
+	// Interval is the time between two collections.
+	Interval time.Duration
+	// Timeout limits the collection and each request.
+	Timeout time.Duration
+	// Headers are added to each request, e.g. for authentication.
+	Headers map[string]string
+	// Labels are added to all series, unless a metric has a label of the same name.
+	// The instance label defaults to the hostname and the job label to windows_exporter.
+	Labels map[string]string
+	// WALDir is the directory of the write-ahead log. If empty, the batches are buffered in memory only
+	// and are lost on restart.
+	WALDir string
+	// MaxSize limits the buffered batches of each endpoint in bytes. If exceeded, the oldest batches are dropped.
+	MaxSize int64
+}
+
+// Sender periodically collects the metrics and sends them to the remote write endpoints.
+type Sender struct {
+	logger  *slog.Logger
+	gather  GatherFunc
+	options Options
+	client  *http.Client
+	targets []*target
+}
+
+// target is a remote write endpoint with its queue.
+type target struct {
+	logger *slog.Logger
+	url    string
+	queue  *queue
+}
+
+// permanentError is returned by send for requests that are rejected by the endpoint and must not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// New returns a Sender for the given options. The batches of the write-ahead log that were not sent
+// before the last shutdown are loaded.
+func New(logger *slog.Logger, gather GatherFunc, options Options) (*Sender, error) {
+	if len(options.URLs) == 0 {
+		return nil, errors.New("no remote write URL")
+	}
+
+	if options.Interval <= 0 {
+		return nil, errors.New("remote write interval must be greater than 0")
+	}
+
+	if options.Timeout <= 0 || options.Timeout > options.Interval {
+		options.Timeout = options.Interval
+	}
+
+	if options.MaxSize <= 0 {
+		return nil, errors.New("remote write maximum queue size must be greater than 0")
+	}
+
+	labels := make(map[string]string, len(options.Labels)+2)
+	labels["job"] = "windows_exporter"
+
+	if _, ok := options.Labels["instance"]; !ok {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname: %w", err)
+		}
+
+		labels["instance"] = hostname
+	}
+
+	for name, value := range options.Labels {
+		labels[name] = value
+	}
+
+	options.Labels = labels
+
+	s := &Sender{
+		logger:  logger,
+		gather:  gather,
+		options: options,
+		client:  &http.Client{Timeout: options.Timeout},
+	}
+
+	for _, rawURL := range options.URLs {
+		endpoint, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote write URL: %w", err)
+		}
+
+		if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+			return nil, fmt.Errorf("invalid remote write URL %q: scheme must be http or https", rawURL)
+		}
+
+		targetLogger := logger.With(slog.String("url", endpoint.Redacted()))
+
+		queue, err := newQueue(targetLogger, endpoint.String(), options.WALDir, options.MaxSize)
+		if err != nil {
+			return nil, err
+		}
+
+		s.targets = append(s.targets, &target{
+			logger: targetLogger,
+			url:    endpoint.String(),
+			queue:  queue,
+		})
+	}
+
+	return s, nil
+}
+
+// Run collects the metrics once per interval until ctx is cancelled. The first collection runs immediately.
+// Each endpoint sends its queue in the background, so an unreachable endpoint doesn't delay the others.
+func (s *Sender) Run(ctx context.Context) {
+	for _, t := range s.targets {
+		go s.send(ctx, t)
+	}
+
+	ticker := time.NewTicker(s.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Collect(ctx); err != nil {
+			s.logger.LogAttrs(ctx, slog.LevelError, "failed to collect metrics for remote write",
+				slog.Any("err", err),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect gathers the metrics and adds them as a batch to the queue of each endpoint.
+func (s *Sender) Collect(ctx context.Context) error {
+	now := time.Now()
+
+	metricFamilies, err := s.gather(s.options.Timeout)
+	if err != nil {
+		// Gather returns the metrics of the successful collectors next to the error.
+		s.logger.LogAttrs(ctx, slog.LevelWarn, "error while gathering metrics",
+			slog.Any("err", err),
+		)
+	}
+
+	if len(metricFamilies) == 0 {
+		return errors.New("no metrics gathered")
+	}
+
+	data := snappyEncode(encodeWriteRequest(metricFamilies, s.options.Labels, now))
+
+	var errs []error
+
+	for _, t := range s.targets {
+		if err := t.queue.add(data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Flush collects the metrics once more and sends the queued batches of each endpoint until the queues are empty
+// or ctx is done, e.g. before Windows shuts down. Flush is called after the context of Run was cancelled, a batch that
+// Run was sending at that time may be sent twice. Each batch is sent once, batches that failed stay in the queue and
+// are sent after the next start if the write-ahead log is enabled.
+func (s *Sender) Flush(ctx context.Context) error {
+	errs := []error{s.Collect(ctx)}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for _, t := range s.targets {
+		wg.Go(func() {
+			if err := s.flush(ctx, t); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", t.url, err))
+				mu.Unlock()
+			}
+		})
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// flush sends the batches of the queue of t in order until the queue is empty or a request failed.
+func (s *Sender) flush(ctx context.Context, t *target) error {
+	for {
+		b, data, ok, err := t.queue.oldest()
+		if !ok {
+			return nil
+		}
+
+		if err != nil {
+			t.logger.LogAttrs(ctx, slog.LevelError, "dropped unreadable batch",
+				slog.Any("err", err),
+			)
+
+			t.queue.remove(b.seq)
+
+			continue
+		}
+
+		var permanent permanentError
+
+		if err := s.post(ctx, t.url, data); errors.As(err, &permanent) {
+			t.logger.LogAttrs(ctx, slog.LevelError, "remote write endpoint rejected batch, dropping it",
+				slog.Any("err", err),
+			)
+		} else if err != nil {
+			return err
+		}
+
+		t.queue.remove(b.seq)
+	}
+}
+
+// send sends the batches of the queue of t in order until ctx is cancelled. Failed requests are retried
+// with an exponential backoff, batches that are rejected by the endpoint are dropped.
+func (s *Sender) send(ctx context.Context, t *target) {
+	backoff := minBackoff
+
+	for {
+		b, data, ok, err := t.queue.oldest()
+
+		switch {
+		case !ok:
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.queue.notify:
+			}
+
+			continue
+		case err != nil:
+			t.logger.LogAttrs(ctx, slog.LevelError, "dropped unreadable batch",
+				slog.Any("err", err),
+			)
+
+			t.queue.remove(b.seq)
+
+			continue
+		}
+
+		err = s.post(ctx, t.url, data)
+
+		var permanent permanentError
+
+		switch {
+		case err == nil:
+			t.queue.remove(b.seq)
+
+			backoff = minBackoff
+
+			t.logger.LogAttrs(ctx, slog.LevelDebug, "sent metrics via remote write",
+				slog.Int("pending", t.queue.len()),
+			)
+
+			continue
+		case errors.As(err, &permanent):
+			t.logger.LogAttrs(ctx, slog.LevelError, "remote write endpoint rejected batch, dropping it",
+				slog.Any("err", err),
+			)
+
+			t.queue.remove(b.seq)
+
+			continue
+		case ctx.Err() != nil:
+			return
+		}
+
+		t.logger.LogAttrs(ctx, slog.LevelWarn, "failed to send metrics via remote write, retrying",
+			slog.Any("err", err),
+			slog.Duration("backoff", backoff),
+			slog.Int("pending", t.queue.len()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+// post sends a compressed WriteRequest. Responses with status 5xx and 429 are retried, other errors are permanent.
+func (s *Sender) post(ctx context.Context, url string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return permanentError{fmt.Errorf("failed to create request: %w", err)}
+	}
+
+	for name, value := range s.options.Headers {
+		req.Header.Set(name, value)
+	}
+
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	req.Header.Set("User-Agent", "windows_exporter/"+version.Version)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		return nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("remote write endpoint returned %s: %s", resp.Status, bytes.TrimSpace(message))
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return err
+	}
+
+	return permanentError{err}
+}
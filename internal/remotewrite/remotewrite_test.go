@@ -0,0 +1,408 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// snappyDecode decodes the block format of Snappy, see snappyEncode.
+func snappyDecode(src []byte) ([]byte, error) {
+	length, n := binary.Uvarint(src)
+	if n <= 0 {
+		return nil, errors.New("invalid length")
+	}
+
+	src = src[n:]
+	dst := make([]byte, 0, length)
+
+	for len(src) > 0 {
+		tag := src[0]
+
+		switch tag & 3 {
+		case 0:
+			literalLength := int(tag>>2) + 1
+			src = src[1:]
+
+			if extra := int(tag>>2) - 59; extra > 0 {
+				literalLength = 1
+				for i := range extra {
+					literalLength += int(src[i]) << (8 * i)
+				}
+
+				src = src[extra:]
+			}
+
+			dst = append(dst, src[:literalLength]...)
+			src = src[literalLength:]
+		case 2:
+			copyLength := int(tag>>2) + 1
+			offset := int(binary.LittleEndian.Uint16(src[1:]))
+			src = src[3:]
+
+			if offset == 0 || offset > len(dst) {
+				return nil, errors.New("invalid offset")
+			}
+
+			for range copyLength {
+				dst = append(dst, dst[len(dst)-offset])
+			}
+		default:
+			return nil, errors.New("unexpected tag")
+		}
+	}
+
+	if uint64(len(dst)) != length {
+		return nil, errors.New("invalid length")
+	}
+
+	return dst, nil
+}
+
+// decodeWriteRequest returns the values of the series by name and labels, in the format name{label="value",...},
+// and the metric types by name.
+func decodeWriteRequest(t *testing.T, request []byte) (map[string]float64, map[string]uint64) {
+	t.Helper()
+
+	series := make(map[string]float64)
+	types := make(map[string]uint64)
+
+	fields(t, request, func(num protowire.Number, data []byte) {
+		switch num {
+		case writeRequestTimeseries:
+			var (
+				labels []string
+				value  float64
+			)
+
+			fields(t, data, func(num protowire.Number, data []byte) {
+				switch num {
+				case timeSeriesLabels:
+					var name, value string
+
+					fields(t, data, func(num protowire.Number, data []byte) {
+						if num == labelName {
+							name = string(data)
+						} else {
+							value = string(data)
+						}
+					})
+
+					labels = append(labels, name+"="+value)
+				case timeSeriesSamples:
+					value = math.Float64frombits(binary.LittleEndian.Uint64(data[1:9]))
+				}
+			})
+
+			series[strings.Join(labels, ",")] = value
+		case writeRequestMetadata:
+			var (
+				name       string
+				metricType uint64
+			)
+
+			fields(t, data, func(num protowire.Number, data []byte) {
+				switch num {
+				case metadataType:
+					metricType, _ = protowire.ConsumeVarint(data)
+				case metadataMetricFamilyName:
+					name = string(data)
+				}
+			})
+
+			types[name] = metricType
+		}
+	})
+
+	return series, types
+}
+
+// fields calls fn with the content of each length-delimited or varint field of a message.
+func fields(t *testing.T, message []byte, fn func(num protowire.Number, data []byte)) {
+	t.Helper()
+
+	for len(message) > 0 {
+		num, typ, n := protowire.ConsumeTag(message)
+		require.Positive(t, n)
+
+		message = message[n:]
+
+		switch typ {
+		case protowire.BytesType:
+			data, n := protowire.ConsumeBytes(message)
+			require.Positive(t, n)
+
+			fn(num, data)
+
+			message = message[n:]
+		case protowire.VarintType:
+			_, n := protowire.ConsumeVarint(message)
+			require.Positive(t, n)
+
+			fn(num, message[:n])
+
+			message = message[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, message)
+			require.Positive(t, n)
+
+			message = message[n:]
+		}
+	}
+}
+
+func TestSnappyEncode(t *testing.T) {
+	t.Parallel()
+
+	for _, src := range [][]byte{
+		nil,
+		[]byte("abc"),
+		bytes.Repeat([]byte("windows_cpu_time_total"), 1000),
+		bytes.Repeat([]byte{0}, 100000),
+		[]byte(strings.Repeat("x", 70) + strings.Repeat("0123456789", 20)),
+	} {
+		encoded := snappyEncode(src)
+
+		decoded, err := snappyDecode(encoded)
+		require.NoError(t, err)
+		require.Equal(t, string(src), string(decoded))
+	}
+
+	require.Less(t, len(snappyEncode(bytes.Repeat([]byte("windows_cpu_time_total"), 1000))), 22000/10)
+}
+
+func TestEncodeWriteRequest(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+
+	duration := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "windows_test_duration_seconds", Help: "Test duration", Buckets: []float64{1, 10}})
+	duration.Observe(0.5)
+	duration.Observe(50)
+
+	bytesSent := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "windows_net_bytes_sent_total", Help: "Total bytes sent"}, []string{"nic", "instance"})
+	bytesSent.WithLabelValues("eth0", "override").Add(1024)
+
+	reg.MustRegister(duration, bytesSent)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	series, types := decodeWriteRequest(t, encodeWriteRequest(families, map[string]string{"instance": "server01", "job": "windows_exporter"}, time.Unix(1700000000, 0)))
+
+	require.Equal(t, map[string]float64{
+		`__name__=windows_net_bytes_sent_total,instance=override,job=windows_exporter,nic=eth0`:        1024,
+		`__name__=windows_test_duration_seconds_bucket,instance=server01,job=windows_exporter,le=1`:    1,
+		`__name__=windows_test_duration_seconds_bucket,instance=server01,job=windows_exporter,le=10`:   1,
+		`__name__=windows_test_duration_seconds_bucket,instance=server01,job=windows_exporter,le=+Inf`: 2,
+		`__name__=windows_test_duration_seconds_sum,instance=server01,job=windows_exporter`:            50.5,
+		`__name__=windows_test_duration_seconds_count,instance=server01,job=windows_exporter`:          2,
+	}, series)
+	require.Equal(t, map[string]uint64{
+		"windows_net_bytes_sent_total":  metadataTypeCounter,
+		"windows_test_duration_seconds": metadataTypeHistogram,
+	}, types)
+}
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	logger := slog.New(slog.DiscardHandler)
+
+	q, err := newQueue(logger, "http://prometheus:9090/api/v1/write", dir, 12)
+	require.NoError(t, err)
+
+	require.NoError(t, q.add([]byte("first")))
+	require.NoError(t, q.add([]byte("second")))
+	require.NoError(t, q.add([]byte("third")))
+
+	// The first batch is dropped, since the queue exceeds 12 bytes.
+	b, data, ok, err := q.oldest()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "second", string(data))
+
+	// A restart loads the batches of the write-ahead log.
+	q, err = newQueue(logger, "http://prometheus:9090/api/v1/write", dir, 12)
+	require.NoError(t, err)
+	require.Equal(t, 2, q.len())
+
+	q.remove(b.seq)
+
+	_, data, ok, err = q.oldest()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "third", string(data))
+
+	// A batch above the maximum size replaces all other batches.
+	require.NoError(t, q.add([]byte("oversized batch")))
+	require.Equal(t, 1, q.len())
+
+	_, data, _, err = q.oldest()
+	require.NoError(t, err)
+	require.Equal(t, "oversized batch", string(data))
+}
+
+func TestSend(t *testing.T) {
+	t.Parallel()
+
+	var (
+		requests  atomic.Int32
+		received  = make(chan []byte, 10)
+		available atomic.Bool
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if !available.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		if r.Header.Get("Content-Encoding") != "snappy" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+
+		request, err := snappyDecode(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		received <- request
+	}))
+	t.Cleanup(server.Close)
+
+	gather := func(time.Duration) ([]*dto.MetricFamily, error) {
+		return []*dto.MetricFamily{{
+			Name:   proto.String("windows_system_processes"),
+			Help:   proto.String("Current number of processes"),
+			Type:   dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: proto.Float64(42)}}},
+		}}, nil
+	}
+
+	sender, err := New(slog.New(slog.DiscardHandler), gather, Options{
+		URLs:     []string{server.URL},
+		Interval: time.Hour,
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		Labels:   map[string]string{"instance": "server01"},
+		WALDir:   t.TempDir(),
+		MaxSize:  1 << 20,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go sender.send(ctx, sender.targets[0])
+
+	require.NoError(t, sender.Collect(ctx))
+	require.NoError(t, sender.Collect(ctx))
+
+	// The batches are kept while the endpoint is unavailable.
+	require.Eventually(t, func() bool { return requests.Load() > 0 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 2, sender.targets[0].queue.len())
+
+	available.Store(true)
+
+	for range 2 {
+		select {
+		case request := <-received:
+			series, _ := decodeWriteRequest(t, request)
+			require.Equal(t, map[string]float64{`__name__=windows_system_processes,instance=server01,job=windows_exporter`: 42}, series)
+		case <-time.After(10 * time.Second):
+			t.Fatal("batch was not sent")
+		}
+	}
+
+	require.Eventually(t, func() bool { return sender.targets[0].queue.len() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestFlush(t *testing.T) {
+	t.Parallel()
+
+	var available atomic.Bool
+
+	received := make(chan []byte, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		received <- body
+	}))
+	t.Cleanup(server.Close)
+
+	gather := func(time.Duration) ([]*dto.MetricFamily, error) {
+		return []*dto.MetricFamily{{
+			Name:   proto.String("windows_system_processes"),
+			Help:   proto.String("Current number of processes"),
+			Type:   dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: proto.Float64(42)}}},
+		}}, nil
+	}
+
+	sender, err := New(slog.New(slog.DiscardHandler), gather, Options{
+		URLs:     []string{server.URL},
+		Interval: time.Hour,
+		MaxSize:  1 << 20,
+	})
+	require.NoError(t, err)
+
+	// A failed batch stays in the queue.
+	require.Error(t, sender.Flush(t.Context()))
+	require.Equal(t, 1, sender.targets[0].queue.len())
+
+	available.Store(true)
+
+	// The queued batch and the batch of the final collection are sent in order.
+	require.NoError(t, sender.Flush(t.Context()))
+	require.Equal(t, 0, sender.targets[0].queue.len())
+	require.Len(t, received, 2)
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package remotewrite
+
+import "encoding/binary"
+
+// Parameters of snappyEncode. Copies with a 2-byte offset reach back up to 64 KiB.
+const (
+	snappyTableBits = 14
+	snappyMinMatch  = 4
+	snappyMaxOffset = 1<<16 - 1
+	snappyMaxCopy   = 64
+)
+
+// snappyEncode compresses src in the block format of Snappy, which is required by the remote write protocol.
+// It is a simple greedy encoder that trades some compression ratio for the dependency on a Snappy package.
+// 📑 https://github.com/google/snappy/blob/main/format_description.txt
+func snappyEncode(src []byte) []byte {
+	dst := binary.AppendUvarint(make([]byte, 0, len(src)/2+16), uint64(len(src)))
+
+	// table holds the position + 1 of the last occurrence of the hashed 4 bytes.
+	var table [1 << snappyTableBits]int32
+
+	literalStart := 0
+
+	for i := 0; i+snappyMinMatch <= len(src); {
+		value := binary.LittleEndian.Uint32(src[i:])
+		hash := (value * 0x1e35a7bd) >> (32 - snappyTableBits)
+		candidate := int(table[hash]) - 1
+		table[hash] = int32(i + 1)
+
+		if candidate < 0 || i-candidate > snappyMaxOffset || binary.LittleEndian.Uint32(src[candidate:]) != value {
+			i++
+
+			continue
+		}
+
+		length := snappyMinMatch
+		for i+length < len(src) && src[candidate+length] == src[i+length] {
+			length++
+		}
+
+		dst = appendSnappyLiteral(dst, src[literalStart:i])
+		dst = appendSnappyCopy(dst, i-candidate, length)
+
+		i += length
+		literalStart = i
+	}
+
+	return appendSnappyLiteral(dst, src[literalStart:])
+}
+
+func appendSnappyLiteral(dst, literal []byte) []byte {
+	if len(literal) == 0 {
+		return dst
+	}
+
+	n := len(literal) - 1
+
+	switch {
+	case n < 60:
+		dst = append(dst, byte(n)<<2)
+	case n < 1<<8:
+		dst = append(dst, 60<<2, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2, byte(n), byte(n>>8))
+	case n < 1<<24:
+		dst = append(dst, 62<<2, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+
+	return append(dst, literal...)
+}
+
+// appendSnappyCopy appends copies with a 2-byte offset, which are limited to 64 bytes each.
+func appendSnappyCopy(dst []byte, offset, length int) []byte {
+	for length > 0 {
+		n := min(length, snappyMaxCopy)
+		dst = append(dst, byte(n-1)<<2|2, byte(offset), byte(offset>>8))
+		length -= n
+	}
+
+	return dst
+}
@@ -0,0 +1,241 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+// Package sampler samples gauges in the background, so collectors can report the minimum, maximum, average and
+// distribution of a gauge between two scrapes instead of its value at the time of the scrape, e.g. of queue lengths
+// that spike for a few seconds.
+package sampler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultMaxSeries is the default number of series of a sampler. Samples of further series are dropped.
+const DefaultMaxSeries = 1000
+
+// Sample is the value of a sampled gauge. LabelValues identify the series and match the labels of the Descs.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// SampleFunc returns the current values of the sampled gauges.
+type SampleFunc func() ([]Sample, error)
+
+type Options struct {
+	// Interval is the time between two samples.
+	Interval time.Duration
+	// Buckets are the upper bounds of the histogram buckets, in increasing order.
+	Buckets []float64
+	// MaxSeries limits the number of series and thereby the memory of the sampler. Defaults to DefaultMaxSeries.
+	MaxSeries int
+}
+
+// Descs are the descriptions of the metrics of a sampled gauge.
+type Descs struct {
+	Min       *prometheus.Desc
+	Max       *prometheus.Desc
+	Avg       *prometheus.Desc
+	Histogram *prometheus.Desc
+}
+
+// NewDescs returns the descriptions of the metrics of a sampled gauge: the gauges fqName_min, fqName_max and fqName_avg
+// of the samples since the previous scrape and the histogram fqName of all samples.
+func NewDescs(fqName, help string, variableLabels []string) Descs {
+	return Descs{
+		Min:       prometheus.NewDesc(fqName+"_min", "Minimum of the samples since the previous scrape: "+help, variableLabels, nil),
+		Max:       prometheus.NewDesc(fqName+"_max", "Maximum of the samples since the previous scrape: "+help, variableLabels, nil),
+		Avg:       prometheus.NewDesc(fqName+"_avg", "Average of the samples since the previous scrape: "+help, variableLabels, nil),
+		Histogram: prometheus.NewDesc(fqName, "Distribution of the samples: "+help, variableLabels, nil),
+	}
+}
+
+// A Sampler calls its SampleFunc every Interval in the background and aggregates the samples by series.
+// The memory of a sampler only depends on the number of series and buckets, not on the number of samples.
+type Sampler struct {
+	logger  *slog.Logger
+	options Options
+	sample  SampleFunc
+
+	ctxCancelFn context.CancelFunc
+
+	// mu protects series and droppedSeries.
+	mu     sync.Mutex
+	series map[string]*series
+	// droppedSeries is set once a series was dropped because of MaxSeries, so it is logged only once.
+	droppedSeries bool
+}
+
+type series struct {
+	labelValues []string
+
+	// count, min, max and sum are the samples since the previous scrape.
+	count uint64
+	min   float64
+	max   float64
+	sum   float64
+
+	// The histogram holds all samples since the series was created.
+	histogramCount uint64
+	histogramSum   float64
+	buckets        []uint64
+}
+
+// New starts a sampler. The first sample is taken after Interval. It must be stopped with Close.
+func New(logger *slog.Logger, options Options, sample SampleFunc) (*Sampler, error) {
+	if options.Interval <= 0 {
+		return nil, fmt.Errorf("invalid sample interval %s, expected a positive duration", options.Interval)
+	}
+
+	if !slices.IsSorted(options.Buckets) {
+		return nil, errors.New("buckets must be in increasing order")
+	}
+
+	if options.MaxSeries <= 0 {
+		options.MaxSeries = DefaultMaxSeries
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := &Sampler{
+		logger:      logger,
+		options:     options,
+		sample:      sample,
+		ctxCancelFn: cancel,
+		series:      make(map[string]*series),
+	}
+
+	go s.run(ctx)
+
+	return s, nil
+}
+
+// Close stops the sampler.
+func (s *Sampler) Close() {
+	if s != nil && s.ctxCancelFn != nil {
+		s.ctxCancelFn()
+	}
+}
+
+func (s *Sampler) run(ctx context.Context) {
+	ticker := time.NewTicker(s.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		samples, err := s.sample()
+		if err != nil {
+			s.logger.LogAttrs(ctx, slog.LevelDebug, "failed to sample",
+				slog.Any("err", err),
+			)
+
+			continue
+		}
+
+		s.observe(samples)
+	}
+}
+
+func (s *Sampler) observe(samples []Sample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sample := range samples {
+		key := strings.Join(sample.LabelValues, "\xff")
+
+		entry, ok := s.series[key]
+		if !ok {
+			if len(s.series) >= s.options.MaxSeries {
+				if !s.droppedSeries {
+					s.droppedSeries = true
+					s.logger.LogAttrs(context.Background(), slog.LevelWarn, fmt.Sprintf("sampler exceeds %d series, dropping samples of further series", s.options.MaxSeries))
+				}
+
+				continue
+			}
+
+			entry = &series{
+				labelValues: slices.Clone(sample.LabelValues),
+				buckets:     make([]uint64, len(s.options.Buckets)),
+			}
+			s.series[key] = entry
+		}
+
+		if entry.count == 0 {
+			entry.min = sample.Value
+			entry.max = sample.Value
+		}
+
+		entry.count++
+		entry.min = min(entry.min, sample.Value)
+		entry.max = max(entry.max, sample.Value)
+		entry.sum += sample.Value
+
+		entry.histogramCount++
+		entry.histogramSum += sample.Value
+
+		// Buckets are cumulative, the sample is counted in the first matching bucket and all following buckets.
+		if i, _ := slices.BinarySearch(s.options.Buckets, sample.Value); i < len(s.options.Buckets) {
+			for ; i < len(entry.buckets); i++ {
+				entry.buckets[i]++
+			}
+		}
+	}
+}
+
+// Collect sends the metrics of the series and starts the next scrape window. Series without samples since the
+// previous scrape are removed, e.g. of removed disks, so their histograms start from zero if they return.
+func (s *Sampler) Collect(ch chan<- prometheus.Metric, descs Descs) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, entry := range s.series {
+		if entry.count == 0 {
+			delete(s.series, key)
+
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(descs.Min, prometheus.GaugeValue, entry.min, entry.labelValues...)
+		ch <- prometheus.MustNewConstMetric(descs.Max, prometheus.GaugeValue, entry.max, entry.labelValues...)
+		ch <- prometheus.MustNewConstMetric(descs.Avg, prometheus.GaugeValue, entry.sum/float64(entry.count), entry.labelValues...)
+
+		buckets := make(map[float64]uint64, len(s.options.Buckets))
+		for i, upperBound := range s.options.Buckets {
+			buckets[upperBound] = entry.buckets[i]
+		}
+
+		ch <- prometheus.MustNewConstHistogram(descs.Histogram, entry.histogramCount, entry.histogramSum, buckets, entry.labelValues...)
+
+		entry.count = 0
+		entry.sum = 0
+	}
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package sampler
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func collect(t *testing.T, s *Sampler, descs Descs) map[*prometheus.Desc][]*dto.Metric {
+	t.Helper()
+
+	ch := make(chan prometheus.Metric, 100)
+	s.Collect(ch, descs)
+	close(ch)
+
+	metrics := make(map[*prometheus.Desc][]*dto.Metric)
+
+	for m := range ch {
+		var metric dto.Metric
+		require.NoError(t, m.Write(&metric))
+
+		metrics[m.Desc()] = append(metrics[m.Desc()], &metric)
+	}
+
+	return metrics
+}
+
+func TestSampler(t *testing.T) {
+	t.Parallel()
+
+	s := &Sampler{
+		logger:  slog.New(slog.DiscardHandler),
+		options: Options{Buckets: []float64{1, 5}, MaxSeries: 1},
+		series:  make(map[string]*series),
+	}
+	descs := NewDescs("test_queue_length", "Queue length", []string{"disk"})
+
+	s.observe([]Sample{{LabelValues: []string{"0"}, Value: 2}, {LabelValues: []string{"1"}, Value: 7}})
+	s.observe([]Sample{{LabelValues: []string{"0"}, Value: 6}})
+	s.observe([]Sample{{LabelValues: []string{"0"}, Value: 1}})
+
+	metrics := collect(t, s, descs)
+
+	require.Len(t, metrics[descs.Min], 1, "series beyond MaxSeries must be dropped")
+	require.InDelta(t, 1.0, metrics[descs.Min][0].GetGauge().GetValue(), 0)
+	require.InDelta(t, 6.0, metrics[descs.Max][0].GetGauge().GetValue(), 0)
+	require.InDelta(t, 3.0, metrics[descs.Avg][0].GetGauge().GetValue(), 0)
+
+	histogram := metrics[descs.Histogram][0].GetHistogram()
+	require.Equal(t, uint64(3), histogram.GetSampleCount())
+	require.InDelta(t, 9.0, histogram.GetSampleSum(), 0)
+	require.Equal(t, uint64(1), histogram.GetBucket()[0].GetCumulativeCount())
+	require.Equal(t, uint64(2), histogram.GetBucket()[1].GetCumulativeCount())
+
+	// The next window starts empty, while the histogram keeps counting.
+	s.observe([]Sample{{LabelValues: []string{"0"}, Value: 4}})
+
+	metrics = collect(t, s, descs)
+
+	require.InDelta(t, 4.0, metrics[descs.Min][0].GetGauge().GetValue(), 0)
+	require.InDelta(t, 4.0, metrics[descs.Max][0].GetGauge().GetValue(), 0)
+	require.Equal(t, uint64(4), metrics[descs.Histogram][0].GetHistogram().GetSampleCount())
+
+	// Series without samples since the previous scrape are removed.
+	require.Empty(t, collect(t, s, descs))
+	require.Empty(t, s.series)
+}
@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+// Package shutdown exports the shutdown of Windows as metrics, so the last metrics that windows_exporter sends before
+// the host disappears explain why, e.g. a restart for updates.
+package shutdown
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/headers/wevtapi"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	systemChannel = "System"
+	// initiatedQuery matches the event 1074 of User32, which is logged when a process or a user initiates a shutdown
+	// or restart, before the services are stopped.
+	initiatedQuery = "*[System[Provider[@Name='User32'] and EventID=1074]]"
+	// maxEventAge is the maximum age of the event 1074 of the current shutdown. Older events belong to a previous
+	// shutdown, e.g. if the current shutdown was initiated by a power button or a crash of csrss.exe without an event.
+	maxEventAge = 15 * time.Minute
+)
+
+// Values of event 1074 rendered by initiatedValuePaths.
+const (
+	valueTimeCreated = iota
+	valueProcess
+	valueComputer
+	valueReason
+	valueReasonCode
+	valueType
+	_ // comment of the user, which is free text
+	valueUser
+)
+
+//nolint:gochecknoglobals
+var initiatedValuePaths = []string{
+	"Event/System/TimeCreated/@SystemTime",
+	"Event/EventData/Data[@Name='param1']",
+	"Event/EventData/Data[@Name='param2']",
+	"Event/EventData/Data[@Name='param3']",
+	"Event/EventData/Data[@Name='param4']",
+	"Event/EventData/Data[@Name='param5']",
+	"Event/EventData/Data[@Name='param6']",
+	"Event/EventData/Data[@Name='param7']",
+}
+
+// Reason is the reason of a shutdown, as logged by Windows in event 1074. The fields are empty if the shutdown
+// was not initiated via the shutdown API, e.g. by the power button.
+type Reason struct {
+	// Type is the shutdown type, e.g. "restart" or "power off".
+	Type string
+	// Process is the process that initiated the shutdown, e.g. C:\Windows\system32\shutdown.exe.
+	Process string
+	// User is the user on whose behalf the process initiated the shutdown, e.g. NT AUTHORITY\SYSTEM.
+	User string
+	// Reason is the shutdown reason, e.g. "Operating System: Service pack (Planned)".
+	Reason string
+	// Code is the shutdown reason code, e.g. 0x80020010.
+	Code string
+}
+
+// Collector exports the shutdown after Begin was called. Before, it exports nothing.
+type Collector struct {
+	infoDesc      *prometheus.Desc
+	timestampDesc *prometheus.Desc
+
+	mu     sync.Mutex
+	begun  time.Time
+	reason Reason
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		infoDesc: prometheus.NewDesc(
+			"windows_exporter_shutdown_info",
+			"Windows is shutting down. The labels are the shutdown reason of event 1074, which are empty if the shutdown was initiated without the shutdown API.",
+			[]string{"type", "process", "user", "reason", "reason_code"},
+			nil,
+		),
+		timestampDesc: prometheus.NewDesc(
+			"windows_exporter_shutdown_timestamp_seconds",
+			"Time at which windows_exporter was notified about the shutdown of Windows.",
+			nil,
+			nil,
+		),
+	}
+}
+
+// Begin records that Windows is shutting down and reads the reason of the shutdown from the System event log.
+// The shutdown is recorded even if the reason can't be read.
+func (c *Collector) Begin(now time.Time) (Reason, error) {
+	reason, err := readReason(now)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.begun = now
+	c.reason = reason
+
+	return reason, err
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.infoDesc
+	ch <- c.timestampDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	begun, reason := c.begun, c.reason
+	c.mu.Unlock()
+
+	if begun.IsZero() {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.infoDesc, prometheus.GaugeValue, 1,
+		reason.Type, reason.Process, reason.User, reason.Reason, reason.Code,
+	)
+	ch <- prometheus.MustNewConstMetric(c.timestampDesc, prometheus.GaugeValue, float64(begun.UnixMicro())/1e6)
+}
+
+// readReason returns the reason of the newest event 1074, if it was logged within maxEventAge before now.
+func readReason(now time.Time) (Reason, error) {
+	renderContext, err := wevtapi.EvtCreateRenderContext(initiatedValuePaths)
+	if err != nil {
+		return Reason{}, fmt.Errorf("failed to create event render context: %w", err)
+	}
+
+	defer func() {
+		_ = wevtapi.EvtClose(renderContext)
+	}()
+
+	values, err := wevtapi.LatestEventValues(systemChannel, initiatedQuery, renderContext)
+	if err != nil {
+		return Reason{}, fmt.Errorf("failed to read events of %s: %w", systemChannel, err)
+	}
+
+	if len(values) != len(initiatedValuePaths) {
+		return Reason{}, nil
+	}
+
+	timeCreated, ok := values[valueTimeCreated].(uint64)
+	if !ok {
+		return Reason{}, errors.New("event 1074 has no creation time")
+	}
+
+	filetime := windows.Filetime{LowDateTime: uint32(timeCreated), HighDateTime: uint32(timeCreated >> 32)}
+	if now.Sub(time.Unix(0, filetime.Nanoseconds())) > maxEventAge {
+		return Reason{}, nil
+	}
+
+	return parseReason(values), nil
+}
+
+// parseReason converts the values of event 1074 to a Reason.
+func parseReason(values []any) Reason {
+	value := func(i int) string {
+		s, _ := values[i].(string)
+
+		return strings.TrimSpace(s)
+	}
+
+	// The process is logged with the computer name, e.g. "C:\Windows\system32\shutdown.exe (HOST)".
+	process := strings.TrimSuffix(value(valueProcess), " ("+value(valueComputer)+")")
+
+	return Reason{
+		Type:    value(valueType),
+		Process: process,
+		User:    value(valueUser),
+		Reason:  value(valueReason),
+		Code:    value(valueReasonCode),
+	}
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package shutdown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReason(t *testing.T) {
+	t.Parallel()
+
+	reason := parseReason([]any{
+		uint64(133000000000000000),
+		`C:\Windows\system32\shutdown.exe (HOST01)`,
+		"HOST01",
+		"Operating System: Service pack (Planned)",
+		"0x80020010",
+		"restart",
+		"",
+		`NT AUTHORITY\SYSTEM`,
+	})
+
+	require.Equal(t, Reason{
+		Type:    "restart",
+		Process: `C:\Windows\system32\shutdown.exe`,
+		User:    `NT AUTHORITY\SYSTEM`,
+		Reason:  "Operating System: Service pack (Planned)",
+		Code:    "0x80020010",
+	}, reason)
+
+	// Missing values are rendered as nil.
+	require.Equal(t, Reason{}, parseReason(make([]any, len(initiatedValuePaths))))
+}
+
+func TestCollect(t *testing.T) {
+	t.Parallel()
+
+	c := NewCollector()
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(c))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Empty(t, families)
+
+	c.begun = time.Unix(1700000000, 0)
+	c.reason = Reason{Type: "power off"}
+
+	families, err = registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 2)
+	require.Equal(t, "windows_exporter_shutdown_info", families[0].GetName())
+	require.Equal(t, "windows_exporter_shutdown_timestamp_seconds", families[1].GetName())
+	require.InDelta(t, 1700000000.0, families[1].GetMetric()[0].GetGauge().GetValue(), 0)
+}
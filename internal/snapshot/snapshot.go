@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+// Package snapshot periodically writes the metrics of windows_exporter to a local file in the text exposition format,
+// e.g. for backup agents or air-gapped collection processes that can't scrape the HTTP endpoint.
+package snapshot
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// GatherFunc collects the metric families to write within the given timeout.
+type GatherFunc func(timeout time.Duration) ([]*dto.MetricFamily, error)
+
+type Options struct {
+	// Path is the file the metrics are written to. The file is replaced by each write.
+	Path string
+	// Interval is the time between two writes.
+	Interval time.Duration
+	// Timeout limits the collection of a write.
+	Timeout time.Duration
+}
+
+// Writer periodically writes the metrics to a file.
+type Writer struct {
+	logger  *slog.Logger
+	gather  GatherFunc
+	options Options
+}
+
+// New returns a Writer for the given options.
+func New(logger *slog.Logger, gather GatherFunc, options Options) (*Writer, error) {
+	if options.Path == "" {
+		return nil, errors.New("path of the snapshot file is empty")
+	}
+
+	path, err := filepath.Abs(options.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot path %q: %w", options.Path, err)
+	}
+
+	if stat, err := os.Stat(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("directory of the snapshot file: %w", err)
+	} else if !stat.IsDir() {
+		return nil, fmt.Errorf("directory of the snapshot file %q is not a directory", filepath.Dir(path))
+	}
+
+	options.Path = path
+
+	if options.Interval <= 0 {
+		return nil, errors.New("interval of the snapshot writer must be greater than 0")
+	}
+
+	if options.Timeout <= 0 || options.Timeout > options.Interval {
+		options.Timeout = options.Interval
+	}
+
+	return &Writer{
+		logger:  logger.With(slog.String("path", path)),
+		gather:  gather,
+		options: options,
+	}, nil
+}
+
+// Run writes the metrics once per interval until ctx is cancelled.
+// Failed writes are logged and not retried, the file keeps the metrics of the last successful write.
+func (w *Writer) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Write(ctx); err != nil {
+			w.logger.LogAttrs(ctx, slog.LevelError, "failed to write metrics snapshot",
+				slog.Any("err", err),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Write gathers the metrics and replaces the snapshot file. The metrics are written to a temporary file
+// in the same directory, which is renamed to the snapshot file, so readers never see a partial file.
+func (w *Writer) Write(ctx context.Context) error {
+	metricFamilies, err := w.gather(w.options.Timeout)
+	if err != nil {
+		// Gather returns the metrics of the successful collectors next to the error.
+		w.logger.LogAttrs(ctx, slog.LevelWarn, "error while gathering metrics",
+			slog.Any("err", err),
+		)
+	}
+
+	if len(metricFamilies) == 0 {
+		return errors.New("no metrics gathered")
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(w.options.Path), "."+filepath.Base(w.options.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	tmpPath := file.Name()
+
+	if err := writeMetricFamilies(file, metricFamilies); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tmpPath)
+
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, w.options.Path); err != nil {
+		_ = os.Remove(tmpPath)
+
+		return fmt.Errorf("failed to replace snapshot file: %w", err)
+	}
+
+	w.logger.LogAttrs(ctx, slog.LevelDebug, "wrote metrics snapshot",
+		slog.Int("metric_families", len(metricFamilies)),
+	)
+
+	return nil
+}
+
+// writeMetricFamilies writes the metric families in the text exposition format and flushes them to disk.
+func writeMetricFamilies(file *os.File, metricFamilies []*dto.MetricFamily) error {
+	buf := bufio.NewWriter(file)
+
+	for _, metricFamily := range metricFamilies {
+		if _, err := expfmt.MetricFamilyToText(buf, metricFamily); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", metricFamily.GetName(), err)
+		}
+	}
+
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+
+	return nil
+}
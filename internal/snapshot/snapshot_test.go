@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package snapshot
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func testMetricFamilies(value float64) []*dto.MetricFamily {
+	return []*dto.MetricFamily{
+		{
+			Name: proto.String("windows_logical_disk_free_bytes"),
+			Help: proto.String("Free space in bytes."),
+			Type: dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{
+				{
+					Label: []*dto.LabelPair{{Name: proto.String("volume"), Value: proto.String("C:")}},
+					Gauge: &dto.Gauge{Value: proto.Float64(value)},
+				},
+			},
+		},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "windows_exporter.prom")
+	value := 1.0
+
+	writer, err := New(slog.New(slog.DiscardHandler), func(time.Duration) ([]*dto.MetricFamily, error) {
+		return testMetricFamilies(value), nil
+	}, Options{
+		Path:     path,
+		Interval: time.Minute,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, writer.Write(t.Context()))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "# HELP windows_logical_disk_free_bytes Free space in bytes.\n# TYPE windows_logical_disk_free_bytes gauge\nwindows_logical_disk_free_bytes{volume=\"C:\"} 1\n", string(content))
+
+	value = 2
+
+	require.NoError(t, writer.Write(t.Context()))
+
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "windows_logical_disk_free_bytes{volume=\"C:\"} 2\n")
+
+	// The temporary files are removed by the rename.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestWriteKeepsLastSnapshot(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "windows_exporter.prom")
+	require.NoError(t, os.WriteFile(path, []byte("previous\n"), 0o600))
+
+	writer, err := New(slog.New(slog.DiscardHandler), func(time.Duration) ([]*dto.MetricFamily, error) {
+		return nil, errors.New("collection failed")
+	}, Options{
+		Path:     path,
+		Interval: time.Minute,
+	})
+	require.NoError(t, err)
+
+	require.Error(t, writer.Write(t.Context()))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "previous\n", string(content))
+}
+
+func TestNewInvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	gather := func(time.Duration) ([]*dto.MetricFamily, error) { return nil, nil }
+
+	_, err := New(slog.New(slog.DiscardHandler), gather, Options{Path: filepath.Join(t.TempDir(), "missing", "windows_exporter.prom"), Interval: time.Minute})
+	require.Error(t, err)
+
+	_, err = New(slog.New(slog.DiscardHandler), gather, Options{Path: filepath.Join(t.TempDir(), "windows_exporter.prom")})
+	require.Error(t, err)
+}
@@ -0,0 +1,532 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+// Package snmp exposes a subset of the metrics of windows_exporter as read-only SNMPv1 and SNMPv2c agent,
+// so network management systems without Prometheus support can poll Windows hosts.
+package snmp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/version"
+)
+
+const (
+	versionV1  = 0
+	versionV2c = 1
+
+	// error-status values of the response, see RFC 3416.
+	errorTooBig      = 1
+	errorNoSuchName  = 2
+	errorNotWritable = 17
+
+	// maxMessageSize is the maximum size of a response, which fits into an Ethernet frame without fragmentation.
+	// GetBulk responses are truncated to it.
+	maxMessageSize = 1472
+	// maxBulkRepetitions limits max-repetitions of GetBulk requests.
+	maxBulkRepetitions = 100
+
+	defaultRefreshTimeout = 10 * time.Second
+)
+
+// GatherFunc collects the metric families of Collectors within the given timeout.
+type GatherFunc func(timeout time.Duration) ([]*dto.MetricFamily, error)
+
+type Options struct {
+	// ListenAddress is the UDP address of the agent, e.g. :161.
+	ListenAddress string
+	// Community is the community string that requests must send.
+	Community string
+	// BaseOID is the OID of WINDOWS-EXPORTER-MIB. It is also reported as sysObjectID.
+	BaseOID string
+	// RefreshInterval is the time between two collections. Requests are answered with the last collected values.
+	RefreshInterval time.Duration
+}
+
+// Agent answers SNMP requests with the metrics of the last collection.
+type Agent struct {
+	logger    *slog.Logger
+	gather    GatherFunc
+	startTime time.Time
+	options   Options
+	conn      net.PacketConn
+	base      oid
+	system    []variable
+
+	// mu protects variables, which are replaced on each refresh and not modified afterward.
+	mu        sync.RWMutex
+	variables []variable
+}
+
+// message is a decoded SNMP message. Responses use the same structure.
+type message struct {
+	version   int64
+	community []byte
+	pduType   byte
+	requestID int64
+	// errorStatus and errorIndex are non-repeaters and max-repetitions in GetBulk requests.
+	errorStatus int64
+	errorIndex  int64
+	varBinds    []varBind
+}
+
+type varBind struct {
+	oid   oid
+	value value
+}
+
+// New returns an agent listening on the address of the options. startTime is reported as start of sysUpTime.
+func New(logger *slog.Logger, gather GatherFunc, startTime time.Time, options Options) (*Agent, error) {
+	if options.Community == "" {
+		return nil, errors.New("SNMP community must not be empty")
+	}
+
+	if options.RefreshInterval <= 0 {
+		return nil, errors.New("SNMP refresh interval must be greater than 0")
+	}
+
+	base, err := parseOID(options.BaseOID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SNMP base OID: %w", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname: %w", err)
+	}
+
+	conn, err := net.ListenPacket("udp", options.ListenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", options.ListenAddress, err)
+	}
+
+	system := systemVariables(base, "windows_exporter "+version.Version, hostname)
+
+	return &Agent{
+		logger:    logger.With(slog.String("address", conn.LocalAddr().String())),
+		gather:    gather,
+		startTime: startTime,
+		options:   options,
+		conn:      conn,
+		base:      base,
+		system:    system,
+		variables: system,
+	}, nil
+}
+
+// Run answers requests and refreshes the values once per refresh interval until ctx is cancelled.
+func (a *Agent) Run(ctx context.Context) {
+	stop := context.AfterFunc(ctx, func() {
+		_ = a.conn.Close()
+	})
+	defer stop()
+
+	go a.refreshLoop(ctx)
+
+	buf := make([]byte, 65535)
+
+	for {
+		n, addr, err := a.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+
+			a.logger.LogAttrs(ctx, slog.LevelWarn, "failed to read SNMP request",
+				slog.Any("err", err),
+			)
+
+			continue
+		}
+
+		response := a.handle(buf[:n])
+		if response == nil {
+			continue
+		}
+
+		if _, err := a.conn.WriteTo(response, addr); err != nil {
+			a.logger.LogAttrs(ctx, slog.LevelDebug, "failed to send SNMP response",
+				slog.String("remote", addr.String()),
+				slog.Any("err", err),
+			)
+		}
+	}
+}
+
+func (a *Agent) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(a.options.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		a.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Agent) refresh(ctx context.Context) {
+	families, err := a.gather(min(a.options.RefreshInterval, defaultRefreshTimeout))
+	if err != nil {
+		// Gather returns the metrics of the successful collectors next to the error.
+		a.logger.LogAttrs(ctx, slog.LevelWarn, "failed to collect metrics for SNMP",
+			slog.Any("err", err),
+		)
+	}
+
+	variables := append(slices.Clone(a.system), newVariables(a.base, families)...)
+	slices.SortFunc(variables, func(x, y variable) int {
+		return slices.Compare(x.oid, y.oid)
+	})
+
+	a.mu.Lock()
+	a.variables = variables
+	a.mu.Unlock()
+}
+
+// handle returns the response to the request, or nil if the request is dropped, e.g. because of a wrong community.
+func (a *Agent) handle(packet []byte) []byte {
+	request, err := decodeMessage(packet)
+	if err != nil {
+		a.logger.Debug("dropped invalid SNMP request",
+			slog.Any("err", err),
+		)
+
+		return nil
+	}
+
+	if request.version != versionV1 && request.version != versionV2c {
+		return nil
+	}
+
+	if subtle.ConstantTimeCompare(request.community, []byte(a.options.Community)) != 1 {
+		a.logger.Debug("dropped SNMP request with unknown community")
+
+		return nil
+	}
+
+	a.mu.RLock()
+	variables := a.variables
+	a.mu.RUnlock()
+
+	var response message
+
+	switch request.pduType {
+	case tagGetRequest, tagGetNextRequest:
+		response = a.get(variables, request)
+	case tagGetBulkRequest:
+		if request.version == versionV1 {
+			return nil
+		}
+
+		response = a.getBulk(variables, request)
+	case tagSetRequest:
+		if request.version == versionV1 {
+			response = errorResponse(request, errorNoSuchName, 1)
+		} else {
+			response = errorResponse(request, errorNotWritable, 1)
+		}
+	default:
+		return nil
+	}
+
+	encoded := encodeMessage(response)
+	if len(encoded) > maxMessageSize {
+		tooBig := errorResponse(request, errorTooBig, 0)
+		tooBig.varBinds = nil
+
+		encoded = encodeMessage(tooBig)
+	}
+
+	return encoded
+}
+
+// get answers GetRequest and GetNextRequest PDUs.
+func (a *Agent) get(variables []variable, request message) message {
+	response := errorResponse(request, 0, 0)
+	response.varBinds = make([]varBind, 0, len(request.varBinds))
+
+	for i, requested := range request.varBinds {
+		var (
+			instance varBind
+			ok       bool
+		)
+
+		if request.pduType == tagGetRequest {
+			instance, ok = a.lookup(variables, requested.oid, request.version)
+		} else {
+			instance, ok = a.lookupNext(variables, requested.oid, request.version)
+		}
+
+		if ok {
+			response.varBinds = append(response.varBinds, instance)
+
+			continue
+		}
+
+		if request.version == versionV1 {
+			return errorResponse(request, errorNoSuchName, int64(i+1))
+		}
+
+		exception := tagEndOfMibView
+		if request.pduType == tagGetRequest {
+			exception = a.getException(variables, requested.oid)
+		}
+
+		response.varBinds = append(response.varBinds, varBind{oid: requested.oid, value: value{tag: exception}})
+	}
+
+	return response
+}
+
+// getBulk answers GetBulkRequest PDUs. The response is truncated to maxMessageSize, see RFC 3416 4.2.3.
+func (a *Agent) getBulk(variables []variable, request message) message {
+	nonRepeaters := int(min(max(request.errorStatus, 0), int64(len(request.varBinds))))
+	maxRepetitions := int(min(max(request.errorIndex, 0), maxBulkRepetitions))
+
+	results := make([]varBind, 0, nonRepeaters+maxRepetitions*(len(request.varBinds)-nonRepeaters))
+	next := func(o oid) varBind {
+		if instance, ok := a.lookupNext(variables, o, request.version); ok {
+			return instance
+		}
+
+		return varBind{oid: o, value: value{tag: tagEndOfMibView}}
+	}
+
+	for _, requested := range request.varBinds[:nonRepeaters] {
+		results = append(results, next(requested.oid))
+	}
+
+	repeaters := make([]oid, 0, len(request.varBinds)-nonRepeaters)
+	for _, requested := range request.varBinds[nonRepeaters:] {
+		repeaters = append(repeaters, requested.oid)
+	}
+
+	for range maxRepetitions {
+		if len(repeaters) == 0 {
+			break
+		}
+
+		endOfMibView := true
+
+		for j, o := range repeaters {
+			instance := next(o)
+			if instance.value.tag != tagEndOfMibView {
+				endOfMibView = false
+			}
+
+			repeaters[j] = instance.oid
+			results = append(results, instance)
+		}
+
+		if endOfMibView {
+			break
+		}
+	}
+
+	response := errorResponse(request, 0, 0)
+	response.varBinds = nil
+
+	// The lengths of the enclosing sequences grow by at most 3 bytes each.
+	size := len(encodeMessage(response)) + 9
+
+	for _, result := range results {
+		size += len(encodeVarBind(result))
+		if size > maxMessageSize && len(response.varBinds) > 0 {
+			break
+		}
+
+		response.varBinds = append(response.varBinds, result)
+	}
+
+	return response
+}
+
+// getException returns noSuchInstance if the object of the OID exists, e.g. for sysUpTime.1, and noSuchObject otherwise.
+func (a *Agent) getException(variables []variable, o oid) byte {
+	if len(o) < 2 {
+		return tagNoSuchObject
+	}
+
+	object := o[:len(o)-1]
+
+	if next, ok := a.lookupNext(variables, object, versionV2c); ok && len(next.oid) == len(o) && slices.Equal(next.oid[:len(object)], object) {
+		return tagNoSuchInstance
+	}
+
+	return tagNoSuchObject
+}
+
+// lookup returns the instance with the OID. Counter64 values don't exist in SNMPv1.
+func (a *Agent) lookup(variables []variable, o oid, version int64) (varBind, bool) {
+	i, ok := slices.BinarySearchFunc(variables, o, func(v variable, target oid) int {
+		return slices.Compare(v.oid, target)
+	})
+	if !ok || (version == versionV1 && variables[i].value.tag == tagCounter64) {
+		return varBind{}, false
+	}
+
+	return a.instance(variables[i]), true
+}
+
+// lookupNext returns the first instance after the OID. Counter64 values are skipped for SNMPv1, see RFC 3584.
+func (a *Agent) lookupNext(variables []variable, o oid, version int64) (varBind, bool) {
+	i, ok := slices.BinarySearchFunc(variables, o, func(v variable, target oid) int {
+		return slices.Compare(v.oid, target)
+	})
+	if ok {
+		i++
+	}
+
+	for ; i < len(variables); i++ {
+		if version != versionV1 || variables[i].value.tag != tagCounter64 {
+			return a.instance(variables[i]), true
+		}
+	}
+
+	return varBind{}, false
+}
+
+// instance returns the variable binding of the variable. sysUpTime is the time since the start of windows_exporter.
+func (a *Agent) instance(v variable) varBind {
+	if slices.Equal(v.oid, sysUpTimeOID) {
+		return varBind{oid: v.oid, value: timeTicksValue(uint64(time.Since(a.startTime) / (10 * time.Millisecond)))}
+	}
+
+	return varBind{oid: v.oid, value: v.value}
+}
+
+// errorResponse returns a response with the error status and the variable bindings of the request.
+func errorResponse(request message, errorStatus, errorIndex int64) message {
+	return message{
+		version:     request.version,
+		community:   request.community,
+		pduType:     tagResponse,
+		requestID:   request.requestID,
+		errorStatus: errorStatus,
+		errorIndex:  errorIndex,
+		varBinds:    request.varBinds,
+	}
+}
+
+func decodeMessage(packet []byte) (message, error) {
+	var m message
+
+	outer := decoder{data: packet}
+
+	content, err := outer.expect(tagSequence)
+	if err != nil {
+		return m, err
+	}
+
+	d := decoder{data: content}
+
+	if m.version, err = d.integer(); err != nil {
+		return m, err
+	}
+
+	if m.community, err = d.expect(tagOctetString); err != nil {
+		return m, err
+	}
+
+	var pdu []byte
+
+	if m.pduType, pdu, err = d.next(); err != nil {
+		return m, err
+	}
+
+	d = decoder{data: pdu}
+
+	if m.requestID, err = d.integer(); err != nil {
+		return m, err
+	}
+
+	if m.errorStatus, err = d.integer(); err != nil {
+		return m, err
+	}
+
+	if m.errorIndex, err = d.integer(); err != nil {
+		return m, err
+	}
+
+	varBinds, err := d.expect(tagSequence)
+	if err != nil {
+		return m, err
+	}
+
+	d = decoder{data: varBinds}
+
+	for len(d.data) > 0 {
+		content, err := d.expect(tagSequence)
+		if err != nil {
+			return m, err
+		}
+
+		vb := decoder{data: content}
+
+		name, err := vb.oid()
+		if err != nil {
+			return m, err
+		}
+
+		tag, raw, err := vb.next()
+		if err != nil {
+			return m, err
+		}
+
+		m.varBinds = append(m.varBinds, varBind{oid: name, value: value{tag: tag, raw: raw}})
+	}
+
+	return m, nil
+}
+
+func encodeMessage(m message) []byte {
+	varBinds := make([]byte, 0)
+	for _, vb := range m.varBinds {
+		varBinds = append(varBinds, encodeVarBind(vb)...)
+	}
+
+	pdu := encodeInteger(m.requestID)
+	pdu = append(pdu, encodeInteger(m.errorStatus)...)
+	pdu = append(pdu, encodeInteger(m.errorIndex)...)
+	pdu = append(pdu, encodeTLV(tagSequence, varBinds)...)
+
+	content := encodeInteger(m.version)
+	content = append(content, encodeTLV(tagOctetString, m.community)...)
+	content = append(content, encodeTLV(m.pduType, pdu)...)
+
+	return encodeTLV(tagSequence, content)
+}
+
+func encodeVarBind(vb varBind) []byte {
+	content := encodeTLV(tagOID, encodeOIDContent(vb.oid))
+
+	return encodeTLV(tagSequence, append(content, vb.value.encode()...))
+}
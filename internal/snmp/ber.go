@@ -0,0 +1,316 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// BER tags of the SNMP message, see RFC 3416.
+// 📑 https://datatracker.ietf.org/doc/html/rfc3416
+const (
+	tagInteger     byte = 0x02
+	tagOctetString byte = 0x04
+	tagNull        byte = 0x05
+	tagOID         byte = 0x06
+	tagSequence    byte = 0x30
+
+	tagCounter32 byte = 0x41
+	tagGauge32   byte = 0x42
+	tagTimeTicks byte = 0x43
+	tagCounter64 byte = 0x46
+
+	tagNoSuchObject   byte = 0x80
+	tagNoSuchInstance byte = 0x81
+	tagEndOfMibView   byte = 0x82
+
+	tagGetRequest     byte = 0xa0
+	tagGetNextRequest byte = 0xa1
+	tagResponse       byte = 0xa2
+	tagSetRequest     byte = 0xa3
+	tagGetBulkRequest byte = 0xa5
+)
+
+var errInvalidBER = errors.New("invalid BER encoding")
+
+// oid is an object identifier, e.g. 1.3.6.1.2.1.1.3.0 for sysUpTime.0.
+type oid []uint32
+
+func parseOID(s string) (oid, error) {
+	arcs := strings.Split(strings.TrimPrefix(s, "."), ".")
+	if len(arcs) < 2 {
+		return nil, fmt.Errorf("invalid OID %q: at least two arcs are required", s)
+	}
+
+	parsed := make(oid, 0, len(arcs))
+
+	for _, arc := range arcs {
+		value, err := strconv.ParseUint(arc, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %q: %w", s, err)
+		}
+
+		parsed = append(parsed, uint32(value))
+	}
+
+	if parsed[0] > 2 || (parsed[0] < 2 && parsed[1] >= 40) {
+		return nil, fmt.Errorf("invalid OID %q: first arcs out of range", s)
+	}
+
+	return parsed, nil
+}
+
+func (o oid) String() string {
+	arcs := make([]string, len(o))
+	for i, arc := range o {
+		arcs[i] = strconv.FormatUint(uint64(arc), 10)
+	}
+
+	return strings.Join(arcs, ".")
+}
+
+// append returns a new OID with the arcs appended, which doesn't share the backing array with o.
+func (o oid) append(arcs ...uint32) oid {
+	return append(slices.Clip(o), arcs...)
+}
+
+// value is the value of a variable binding. bytes holds the content of octet strings, integer the value of
+// signed integers and unsigned the value of the unsigned application types.
+// raw holds the undecoded content of values of requests, which are returned unchanged in error responses.
+type value struct {
+	tag      byte
+	integer  int64
+	unsigned uint64
+	bytes    []byte
+	raw      []byte
+}
+
+func integerValue(v int64) value    { return value{tag: tagInteger, integer: v} }
+func stringValue(v string) value    { return value{tag: tagOctetString, bytes: []byte(v)} }
+func oidValue(v oid) value          { return value{tag: tagOID, bytes: encodeOIDContent(v)} }
+func gauge32Value(v uint64) value   { return value{tag: tagGauge32, unsigned: min(v, 1<<32-1)} }
+func timeTicksValue(v uint64) value { return value{tag: tagTimeTicks, unsigned: v % (1 << 32)} }
+func counter64Value(v uint64) value { return value{tag: tagCounter64, unsigned: v} }
+
+func (v value) encode() []byte {
+	if v.raw != nil {
+		return encodeTLV(v.tag, v.raw)
+	}
+
+	switch v.tag {
+	case tagInteger:
+		return encodeTLV(tagInteger, encodeIntegerContent(v.integer))
+	case tagCounter32, tagGauge32, tagTimeTicks, tagCounter64:
+		return encodeTLV(v.tag, encodeUnsignedContent(v.unsigned))
+	default:
+		return encodeTLV(v.tag, v.bytes)
+	}
+}
+
+func encodeTLV(tag byte, content []byte) []byte {
+	encoded := make([]byte, 0, len(content)+6)
+	encoded = append(encoded, tag)
+	encoded = appendLength(encoded, len(content))
+
+	return append(encoded, content...)
+}
+
+func appendLength(b []byte, length int) []byte {
+	if length < 0x80 {
+		return append(b, byte(length))
+	}
+
+	var lengthBytes []byte
+	for ; length > 0; length >>= 8 {
+		lengthBytes = append([]byte{byte(length)}, lengthBytes...)
+	}
+
+	b = append(b, 0x80|byte(len(lengthBytes)))
+
+	return append(b, lengthBytes...)
+}
+
+func encodeInteger(v int64) []byte {
+	return encodeTLV(tagInteger, encodeIntegerContent(v))
+}
+
+// encodeIntegerContent returns the shortest two's complement encoding of v.
+func encodeIntegerContent(v int64) []byte {
+	content := []byte{byte(v)}
+	for v >>= 8; !(v == 0 && content[0]&0x80 == 0) && !(v == -1 && content[0]&0x80 != 0); v >>= 8 {
+		content = append([]byte{byte(v)}, content...)
+	}
+
+	return content
+}
+
+// encodeUnsignedContent returns the shortest encoding of v, with a leading zero byte if the high bit is set.
+func encodeUnsignedContent(v uint64) []byte {
+	content := []byte{byte(v)}
+	for v >>= 8; v > 0; v >>= 8 {
+		content = append([]byte{byte(v)}, content...)
+	}
+
+	if content[0]&0x80 != 0 {
+		content = append([]byte{0}, content...)
+	}
+
+	return content
+}
+
+func encodeOIDContent(o oid) []byte {
+	if len(o) < 2 {
+		return []byte{0}
+	}
+
+	content := appendBase128(nil, o[0]*40+o[1])
+	for _, arc := range o[2:] {
+		content = appendBase128(content, arc)
+	}
+
+	return content
+}
+
+func appendBase128(b []byte, v uint32) []byte {
+	var groups []byte
+	for groups = []byte{byte(v & 0x7f)}; v > 0x7f; {
+		v >>= 7
+		groups = append([]byte{byte(v&0x7f) | 0x80}, groups...)
+	}
+
+	return append(b, groups...)
+}
+
+// decoder reads BER encoded values of an SNMP message.
+type decoder struct {
+	data []byte
+}
+
+// next returns the tag and the content of the next value.
+func (d *decoder) next() (byte, []byte, error) {
+	if len(d.data) < 2 {
+		return 0, nil, errInvalidBER
+	}
+
+	tag := d.data[0]
+	length := int(d.data[1])
+	offset := 2
+
+	if length&0x80 != 0 {
+		lengthBytes := length & 0x7f
+		// Indefinite lengths are not allowed in SNMP and lengths beyond 4 bytes exceed any UDP datagram.
+		if lengthBytes == 0 || lengthBytes > 4 || len(d.data) < offset+lengthBytes {
+			return 0, nil, errInvalidBER
+		}
+
+		length = 0
+		for _, b := range d.data[offset : offset+lengthBytes] {
+			length = length<<8 | int(b)
+		}
+
+		offset += lengthBytes
+	}
+
+	if length < 0 || len(d.data) < offset+length {
+		return 0, nil, errInvalidBER
+	}
+
+	content := d.data[offset : offset+length]
+	d.data = d.data[offset+length:]
+
+	return tag, content, nil
+}
+
+// expect returns the content of the next value, which must have the given tag.
+func (d *decoder) expect(tag byte) ([]byte, error) {
+	actual, content, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if actual != tag {
+		return nil, fmt.Errorf("%w: expected tag 0x%02x, got 0x%02x", errInvalidBER, tag, actual)
+	}
+
+	return content, nil
+}
+
+func (d *decoder) integer() (int64, error) {
+	content, err := d.expect(tagInteger)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(content) == 0 || len(content) > 8 {
+		return 0, errInvalidBER
+	}
+
+	// Sign extension of the first byte.
+	v := int64(int8(content[0]))
+	for _, b := range content[1:] {
+		v = v<<8 | int64(b)
+	}
+
+	return v, nil
+}
+
+func (d *decoder) oid() (oid, error) {
+	content, err := d.expect(tagOID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(content) == 0 {
+		return nil, errInvalidBER
+	}
+
+	var (
+		arcs oid
+		arc  uint64
+	)
+
+	for i, b := range content {
+		arc = arc<<7 | uint64(b&0x7f)
+		if arc > 1<<32-1 {
+			return nil, errInvalidBER
+		}
+
+		if b&0x80 != 0 {
+			if i == len(content)-1 {
+				return nil, errInvalidBER
+			}
+
+			continue
+		}
+
+		if len(arcs) == 0 {
+			first := min(arc/40, 2)
+			arcs = append(arcs, uint32(first), uint32(arc-first*40))
+		} else {
+			arcs = append(arcs, uint32(arc))
+		}
+
+		arc = 0
+	}
+
+	return arcs, nil
+}
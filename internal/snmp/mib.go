@@ -0,0 +1,228 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package snmp
+
+import (
+	"maps"
+	"math"
+	"slices"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// DefaultBaseOID is the OID of WINDOWS-EXPORTER-MIB below netSnmpPlaypen, which is reserved for local use.
+// The last arc is the default port of windows_exporter.
+const DefaultBaseOID = "1.3.6.1.4.1.8072.9999.9999.9182"
+
+// Collectors are the collectors whose metrics are exposed via SNMP.
+//
+//nolint:gochecknoglobals
+var Collectors = []string{"cpu", "memory", "logical_disk", "service"}
+
+// OIDs of the system group of SNMPv2-MIB, see RFC 3418.
+//
+//nolint:gochecknoglobals
+var (
+	sysDescrOID    = oid{1, 3, 6, 1, 2, 1, 1, 1, 0}
+	sysObjectIDOID = oid{1, 3, 6, 1, 2, 1, 1, 2, 0}
+	sysUpTimeOID   = oid{1, 3, 6, 1, 2, 1, 1, 3, 0}
+	sysNameOID     = oid{1, 3, 6, 1, 2, 1, 1, 5, 0}
+)
+
+// Arcs of WINDOWS-EXPORTER-MIB below the base OID, see docs/WINDOWS-EXPORTER-MIB.txt.
+const (
+	arcObjects = 1
+
+	arcScalars         = 1
+	arcCPUCount        = 1
+	arcCPUIdleTime     = 2
+	arcCPUTotalTime    = 3
+	arcMemoryTotal     = 4
+	arcMemoryAvailable = 5
+
+	arcDiskTable  = 2
+	arcDiskIndex  = 1
+	arcDiskVolume = 2
+	arcDiskSize   = 3
+	arcDiskFree   = 4
+
+	arcServiceTable = 3
+	arcServiceIndex = 1
+	arcServiceName  = 2
+	arcServiceState = 3
+
+	// arcEntry is the arc of the conceptual row below a table.
+	arcEntry = 1
+)
+
+// serviceStates are the values of wxServiceState by the state label of windows_service_state.
+// They match the SERVICE_STATUS values of the Windows API.
+//
+//nolint:gochecknoglobals
+var serviceStates = map[string]int64{
+	"stopped":          1,
+	"start pending":    2,
+	"stop pending":     3,
+	"running":          4,
+	"continue pending": 5,
+	"pause pending":    6,
+	"paused":           7,
+}
+
+// variable is an object instance of the MIB view.
+type variable struct {
+	oid   oid
+	value value
+}
+
+// newVariables converts the metric families of the collectors to the object instances of WINDOWS-EXPORTER-MIB.
+// Objects whose metrics are missing, e.g. because the collector is not enabled, are left out.
+func newVariables(base oid, families []*dto.MetricFamily) []variable {
+	metrics := make(map[string][]*dto.Metric, len(families))
+	for _, family := range families {
+		metrics[family.GetName()] = family.GetMetric()
+	}
+
+	scalars := base.append(arcObjects, arcScalars)
+	variables := make([]variable, 0)
+
+	if cpuTime, ok := metrics["windows_cpu_time_total"]; ok {
+		cores := make(map[string]struct{})
+
+		var idle, total float64
+
+		for _, metric := range cpuTime {
+			cores[labelValue(metric, "core")] = struct{}{}
+			total += metric.GetCounter().GetValue()
+
+			if labelValue(metric, "mode") == "idle" {
+				idle += metric.GetCounter().GetValue()
+			}
+		}
+
+		variables = append(variables,
+			variable{oid: scalars.append(arcCPUCount, 0), value: gauge32Value(uint64(len(cores)))},
+			// Centiseconds like hrSWRunPerfCPU of HOST-RESOURCES-MIB.
+			variable{oid: scalars.append(arcCPUIdleTime, 0), value: counter64Value(toUint64(idle * 100))},
+			variable{oid: scalars.append(arcCPUTotalTime, 0), value: counter64Value(toUint64(total * 100))},
+		)
+	}
+
+	if memoryTotal, ok := metrics["windows_memory_physical_total_bytes"]; ok && len(memoryTotal) == 1 {
+		variables = append(variables, variable{oid: scalars.append(arcMemoryTotal, 0), value: counter64Value(toUint64(memoryTotal[0].GetGauge().GetValue()))})
+	}
+
+	if memoryAvailable, ok := metrics["windows_memory_available_bytes"]; ok && len(memoryAvailable) == 1 {
+		variables = append(variables, variable{oid: scalars.append(arcMemoryAvailable, 0), value: counter64Value(toUint64(memoryAvailable[0].GetGauge().GetValue()))})
+	}
+
+	variables = append(variables, diskVariables(base.append(arcObjects, arcDiskTable, arcEntry), metrics)...)
+	variables = append(variables, serviceVariables(base.append(arcObjects, arcServiceTable, arcEntry), metrics)...)
+
+	return variables
+}
+
+// diskVariables returns the rows of wxDiskTable. The rows are numbered in the order of the volume names.
+func diskVariables(entry oid, metrics map[string][]*dto.Metric) []variable {
+	sizes := make(map[string]float64)
+	for _, metric := range metrics["windows_logical_disk_size_bytes"] {
+		sizes[labelValue(metric, "volume")] = metric.GetGauge().GetValue()
+	}
+
+	free := make(map[string]float64)
+	for _, metric := range metrics["windows_logical_disk_free_bytes"] {
+		free[labelValue(metric, "volume")] = metric.GetGauge().GetValue()
+	}
+
+	variables := make([]variable, 0, 4*len(sizes))
+
+	for i, volume := range slices.Sorted(maps.Keys(sizes)) {
+		index := uint32(i + 1)
+
+		variables = append(variables,
+			variable{oid: entry.append(arcDiskIndex, index), value: integerValue(int64(index))},
+			variable{oid: entry.append(arcDiskVolume, index), value: stringValue(volume)},
+			variable{oid: entry.append(arcDiskSize, index), value: counter64Value(toUint64(sizes[volume]))},
+			variable{oid: entry.append(arcDiskFree, index), value: counter64Value(toUint64(free[volume]))},
+		)
+	}
+
+	return variables
+}
+
+// serviceVariables returns the rows of wxServiceTable. The rows are numbered in the order of the service names.
+func serviceVariables(entry oid, metrics map[string][]*dto.Metric) []variable {
+	states := make(map[string]int64)
+
+	for _, metric := range metrics["windows_service_state"] {
+		if metric.GetGauge().GetValue() != 1 {
+			continue
+		}
+
+		if state, ok := serviceStates[labelValue(metric, "state")]; ok {
+			states[labelValue(metric, "name")] = state
+		}
+	}
+
+	variables := make([]variable, 0, 3*len(states))
+
+	for i, name := range slices.Sorted(maps.Keys(states)) {
+		index := uint32(i + 1)
+
+		variables = append(variables,
+			variable{oid: entry.append(arcServiceIndex, index), value: integerValue(int64(index))},
+			variable{oid: entry.append(arcServiceName, index), value: stringValue(name)},
+			variable{oid: entry.append(arcServiceState, index), value: integerValue(states[name])},
+		)
+	}
+
+	return variables
+}
+
+func labelValue(metric *dto.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+
+	return ""
+}
+
+// toUint64 converts a metric value to an unsigned SNMP value. Negative and NaN values are reported as 0.
+func toUint64(v float64) uint64 {
+	if math.IsNaN(v) || v <= 0 {
+		return 0
+	}
+
+	if v >= math.MaxUint64 {
+		return math.MaxUint64
+	}
+
+	return uint64(v)
+}
+
+// systemVariables returns the system group of SNMPv2-MIB. sysUpTime is set for each request.
+func systemVariables(base oid, description, hostname string) []variable {
+	return []variable{
+		{oid: sysDescrOID, value: stringValue(description)},
+		{oid: sysObjectIDOID, value: oidValue(base)},
+		{oid: sysUpTimeOID, value: timeTicksValue(0)},
+		{oid: sysNameOID, value: stringValue(hostname)},
+	}
+}
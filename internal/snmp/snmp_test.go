@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package snmp
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+
+	cpuTime := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "windows_cpu_time_total"}, []string{"core", "mode"})
+	cpuTime.WithLabelValues("0,0", "idle").Add(90)
+	cpuTime.WithLabelValues("0,0", "user").Add(10)
+	cpuTime.WithLabelValues("0,1", "idle").Add(80.5)
+	cpuTime.WithLabelValues("0,1", "user").Add(19.5)
+
+	memoryTotal := prometheus.NewGauge(prometheus.GaugeOpts{Name: "windows_memory_physical_total_bytes"})
+	memoryTotal.Set(8 << 30)
+
+	diskSize := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "windows_logical_disk_size_bytes"}, []string{"volume"})
+	diskSize.WithLabelValues("C:").Set(100 << 30)
+	diskSize.WithLabelValues("D:").Set(200 << 30)
+
+	serviceState := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "windows_service_state"}, []string{"name", "state"})
+	serviceState.WithLabelValues("spooler", "running").Set(1)
+	serviceState.WithLabelValues("spooler", "stopped").Set(0)
+	serviceState.WithLabelValues("wuauserv", "running").Set(0)
+	serviceState.WithLabelValues("wuauserv", "stopped").Set(1)
+
+	reg.MustRegister(cpuTime, memoryTotal, diskSize, serviceState)
+
+	agent, err := New(slog.New(slog.DiscardHandler), func(time.Duration) ([]*dto.MetricFamily, error) {
+		return reg.Gather()
+	}, time.Now(), Options{
+		ListenAddress:   "127.0.0.1:0",
+		Community:       "public",
+		BaseOID:         DefaultBaseOID,
+		RefreshInterval: time.Minute,
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, agent.conn.Close())
+	})
+
+	agent.refresh(context.Background())
+
+	base, err := parseOID(DefaultBaseOID)
+	require.NoError(t, err)
+
+	scalars := base.append(arcObjects, arcScalars)
+	services := base.append(arcObjects, arcServiceTable, arcEntry)
+
+	request := func(version int64, pduType byte, community string, errorStatus, errorIndex int64, oids ...oid) message {
+		t.Helper()
+
+		varBinds := make([]varBind, 0, len(oids))
+		for _, o := range oids {
+			varBinds = append(varBinds, varBind{oid: o, value: value{tag: tagNull, raw: []byte{}}})
+		}
+
+		response := agent.handle(encodeMessage(message{
+			version:     version,
+			community:   []byte(community),
+			pduType:     pduType,
+			requestID:   42,
+			errorStatus: errorStatus,
+			errorIndex:  errorIndex,
+			varBinds:    varBinds,
+		}))
+		if response == nil {
+			return message{}
+		}
+
+		decoded, err := decodeMessage(response)
+		require.NoError(t, err)
+		require.Equal(t, tagResponse, decoded.pduType)
+		require.Equal(t, int64(42), decoded.requestID)
+
+		return decoded
+	}
+
+	t.Run("get", func(t *testing.T) {
+		t.Parallel()
+
+		response := request(versionV2c, tagGetRequest, "public", 0, 0, scalars.append(arcCPUCount, 0), scalars.append(arcCPUIdleTime, 0), scalars.append(arcMemoryTotal, 0))
+		require.Equal(t, int64(0), response.errorStatus)
+		require.Len(t, response.varBinds, 3)
+		require.Equal(t, value{tag: tagGauge32, raw: []byte{2}}, response.varBinds[0].value)
+		require.Equal(t, tagCounter64, response.varBinds[1].value.tag)
+		require.Equal(t, encodeUnsignedContent(17050), response.varBinds[1].value.raw)
+		require.Equal(t, encodeUnsignedContent(8<<30), response.varBinds[2].value.raw)
+	})
+
+	t.Run("get missing", func(t *testing.T) {
+		t.Parallel()
+
+		response := request(versionV2c, tagGetRequest, "public", 0, 0, oid{1, 3, 6, 1, 2, 1, 1, 3, 1}, scalars.append(arcMemoryAvailable, 0))
+		require.Equal(t, tagNoSuchInstance, response.varBinds[0].value.tag)
+		require.Equal(t, tagNoSuchObject, response.varBinds[1].value.tag)
+	})
+
+	t.Run("get next", func(t *testing.T) {
+		t.Parallel()
+
+		response := request(versionV2c, tagGetNextRequest, "public", 0, 0, oid{1, 3, 6, 1, 2, 1, 1}, base, services.append(arcServiceState, 2))
+		require.Equal(t, sysDescrOID, response.varBinds[0].oid)
+		require.Equal(t, scalars.append(arcCPUCount, 0), response.varBinds[1].oid)
+		require.Equal(t, tagEndOfMibView, response.varBinds[2].value.tag)
+	})
+
+	t.Run("get bulk", func(t *testing.T) {
+		t.Parallel()
+
+		response := request(versionV2c, tagGetBulkRequest, "public", 1, 10, sysNameOID, services.append(arcServiceName))
+		// The last repetition reaches the end of the MIB view.
+		require.Len(t, response.varBinds, 1+5)
+		require.Equal(t, scalars.append(arcCPUCount, 0), response.varBinds[0].oid)
+		require.Equal(t, []byte("spooler"), response.varBinds[1].value.raw)
+		require.Equal(t, []byte("wuauserv"), response.varBinds[2].value.raw)
+		require.Equal(t, value{tag: tagInteger, raw: []byte{4}}, response.varBinds[3].value)
+		require.Equal(t, value{tag: tagInteger, raw: []byte{1}}, response.varBinds[4].value)
+		require.Equal(t, tagEndOfMibView, response.varBinds[5].value.tag)
+	})
+
+	t.Run("SNMPv1 skips Counter64", func(t *testing.T) {
+		t.Parallel()
+
+		response := request(versionV1, tagGetRequest, "public", 0, 0, scalars.append(arcCPUCount, 0), scalars.append(arcMemoryTotal, 0))
+		require.Equal(t, int64(errorNoSuchName), response.errorStatus)
+		require.Equal(t, int64(2), response.errorIndex)
+
+		response = request(versionV1, tagGetNextRequest, "public", 0, 0, scalars.append(arcCPUCount, 0))
+		require.Equal(t, base.append(arcObjects, arcDiskTable, arcEntry, arcDiskIndex, 1), response.varBinds[0].oid)
+	})
+
+	t.Run("set", func(t *testing.T) {
+		t.Parallel()
+
+		response := request(versionV2c, tagSetRequest, "public", 0, 0, sysNameOID)
+		require.Equal(t, int64(errorNotWritable), response.errorStatus)
+		require.Equal(t, int64(1), response.errorIndex)
+	})
+
+	t.Run("wrong community", func(t *testing.T) {
+		t.Parallel()
+
+		require.Nil(t, agent.handle(encodeMessage(message{version: versionV2c, community: []byte("private"), pduType: tagGetRequest})))
+	})
+}
+
+func TestBER(t *testing.T) {
+	t.Parallel()
+
+	for _, v := range []int64{0, 1, -1, 127, 128, -128, -129, 1 << 40, -(1 << 40)} {
+		d := decoder{data: encodeInteger(v)}
+
+		decoded, err := d.integer()
+		require.NoError(t, err)
+		require.Equal(t, v, decoded)
+	}
+
+	o, err := parseOID("1.3.6.1.4.1.8072.9999.9999.4294967295")
+	require.NoError(t, err)
+
+	d := decoder{data: encodeTLV(tagOID, encodeOIDContent(o))}
+
+	decoded, err := d.oid()
+	require.NoError(t, err)
+	require.Equal(t, o, decoded)
+	require.Equal(t, "1.3.6.1.4.1.8072.9999.9999.4294967295", decoded.String())
+
+	d = decoder{data: []byte{tagSequence, 0x84, 0xff, 0xff, 0xff, 0xff}}
+
+	_, _, err = d.next()
+	require.ErrorIs(t, err, errInvalidBER)
+}
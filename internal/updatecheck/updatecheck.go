@@ -0,0 +1,317 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+// Package updatecheck periodically reads a release feed and reports whether a newer release of windows_exporter
+// is available, so the version skew of a fleet can be tracked in Prometheus. It never downloads or installs releases.
+package updatecheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxFeedSize limits the size of the feed response. The release list of GitHub is about 100 KiB per page.
+const maxFeedSize = 4 << 20
+
+type Options struct {
+	// FeedURL is the URL of the release feed, in the JSON format of the GitHub releases API.
+	FeedURL string
+	// Interval is the time between two checks.
+	Interval time.Duration
+	// Timeout limits the request of a check.
+	Timeout time.Duration
+}
+
+// Checker periodically reads the release feed and exports the latest release as metrics.
+type Checker struct {
+	logger  *slog.Logger
+	client  *http.Client
+	options Options
+	current string
+
+	updateAvailableDesc *prometheus.Desc
+	lastSuccessDesc     *prometheus.Desc
+
+	mu          sync.Mutex
+	latest      string
+	lastSuccess time.Time
+}
+
+// release is the subset of a release of the GitHub releases API that is read from the feed.
+type release struct {
+	TagName    string `json:"tag_name"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// New returns a Checker that compares the releases of the feed with the current version of windows_exporter.
+func New(logger *slog.Logger, current string, options Options) (*Checker, error) {
+	feedURL, err := url.Parse(options.FeedURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release feed URL %q: %w", options.FeedURL, err)
+	}
+
+	if feedURL.Scheme != "http" && feedURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid release feed URL %q: scheme must be http or https", options.FeedURL)
+	}
+
+	if options.Interval <= 0 {
+		return nil, errors.New("interval of the update check must be greater than 0")
+	}
+
+	if options.Timeout <= 0 || options.Timeout > options.Interval {
+		options.Timeout = options.Interval
+	}
+
+	if _, ok := parseVersion(current); !ok {
+		logger.Warn("current version is not a release version, every release of the feed is reported as an update",
+			slog.String("version", current),
+		)
+	}
+
+	return &Checker{
+		logger:  logger.With(slog.String("feed", feedURL.Redacted())),
+		client:  &http.Client{Timeout: options.Timeout},
+		options: options,
+		current: current,
+
+		updateAvailableDesc: prometheus.NewDesc(
+			"windows_exporter_update_available",
+			"Whether the latest release of the release feed, given by the version label, is newer than the running windows_exporter.",
+			[]string{"version"},
+			nil,
+		),
+		lastSuccessDesc: prometheus.NewDesc(
+			"windows_exporter_update_check_last_success_timestamp_seconds",
+			"Time of the last successful read of the release feed.",
+			nil,
+			nil,
+		),
+	}, nil
+}
+
+// Run checks the release feed once per interval until ctx is cancelled.
+// Failed checks are logged and not retried, the metrics keep the result of the last successful check.
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Check(ctx); err != nil {
+			c.logger.LogAttrs(ctx, slog.LevelWarn, "failed to check for updates",
+				slog.Any("err", err),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check reads the release feed and stores its latest release.
+func (c *Checker) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.options.FeedURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "windows_exporter/"+c.current)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to read release feed: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("release feed returned status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return fmt.Errorf("failed to read release feed: %w", err)
+	}
+
+	latest, err := latestRelease(body)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	previous := c.latest
+	c.latest = latest
+	c.lastSuccess = time.Now()
+	c.mu.Unlock()
+
+	if latest != previous && newerVersion(latest, c.current) {
+		c.logger.LogAttrs(ctx, slog.LevelInfo, "a newer release of windows_exporter is available",
+			slog.String("version", latest),
+			slog.String("current_version", c.current),
+		)
+	}
+
+	return nil
+}
+
+func (c *Checker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.updateAvailableDesc
+	ch <- c.lastSuccessDesc
+}
+
+// Collect exports the result of the last successful check. Before the first successful check, nothing is exported.
+func (c *Checker) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	latest, lastSuccess := c.latest, c.lastSuccess
+	c.mu.Unlock()
+
+	if lastSuccess.IsZero() {
+		return
+	}
+
+	updateAvailable := 0.0
+	if newerVersion(latest, c.current) {
+		updateAvailable = 1
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.updateAvailableDesc, prometheus.GaugeValue, updateAvailable, latest)
+	ch <- prometheus.MustNewConstMetric(c.lastSuccessDesc, prometheus.GaugeValue, float64(lastSuccess.Unix()))
+}
+
+// latestRelease returns the newest version of a feed, which is either a single release like
+// /repos/{owner}/{repo}/releases/latest or a list of releases like /repos/{owner}/{repo}/releases.
+// Drafts, pre-releases and tags that are not versions are ignored.
+func latestRelease(body []byte) (string, error) {
+	var releases []release
+
+	if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(body, &releases); err != nil {
+			return "", fmt.Errorf("failed to decode release feed: %w", err)
+		}
+	} else {
+		var single release
+		if err := json.Unmarshal(body, &single); err != nil {
+			return "", fmt.Errorf("failed to decode release feed: %w", err)
+		}
+
+		releases = []release{single}
+	}
+
+	var latest string
+
+	for _, r := range releases {
+		if r.Draft || r.Prerelease {
+			continue
+		}
+
+		if _, ok := parseVersion(r.TagName); !ok {
+			continue
+		}
+
+		if latest == "" || newerVersion(r.TagName, latest) {
+			latest = r.TagName
+		}
+	}
+
+	if latest == "" {
+		return "", errors.New("release feed contains no release")
+	}
+
+	return strings.TrimPrefix(latest, "v"), nil
+}
+
+// version is a parsed semantic version. pre is the pre-release suffix, which is empty for releases.
+type version struct {
+	core [3]uint64
+	pre  string
+}
+
+// parseVersion parses versions like v0.31.0, 0.31.0 and 0.31.0-rc.1. Build metadata after + is ignored.
+func parseVersion(s string) (version, bool) {
+	s, _, _ = strings.Cut(strings.TrimPrefix(s, "v"), "+")
+	s, pre, _ := strings.Cut(s, "-")
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return version{}, false
+	}
+
+	var v version
+
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return version{}, false
+		}
+
+		v.core[i] = n
+	}
+
+	v.pre = pre
+
+	return v, true
+}
+
+// newerVersion reports whether a is newer than b. A b that is not a version, e.g. of a development build,
+// is older than every version.
+func newerVersion(a, b string) bool {
+	va, ok := parseVersion(a)
+	if !ok {
+		return false
+	}
+
+	vb, ok := parseVersion(b)
+	if !ok {
+		return true
+	}
+
+	for i := range va.core {
+		if va.core[i] != vb.core[i] {
+			return va.core[i] > vb.core[i]
+		}
+	}
+
+	// A release is newer than its pre-releases.
+	switch {
+	case va.pre == vb.pre:
+		return false
+	case va.pre == "":
+		return true
+	case vb.pre == "":
+		return false
+	default:
+		return va.pre > vb.pre
+	}
+}
@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package updatecheck
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewerVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		a, b  string
+		newer bool
+	}{
+		{"0.31.0", "0.30.5", true},
+		{"v0.31.0", "0.31.0", false},
+		{"0.30.5", "0.31.0", false},
+		{"1.0.0", "0.99.99", true},
+		{"0.31.0", "0.31.0-rc.1", true},
+		{"0.31.0-rc.2", "0.31.0-rc.1", true},
+		{"0.31.0-rc.1", "0.31.0", false},
+		{"0.31.0", "", true},
+		{"0.31.0", "devel", true},
+		{"latest", "0.31.0", false},
+	} {
+		require.Equal(t, tc.newer, newerVersion(tc.a, tc.b), "%s > %s", tc.a, tc.b)
+	}
+}
+
+func TestLatestRelease(t *testing.T) {
+	t.Parallel()
+
+	latest, err := latestRelease([]byte(`{"tag_name": "v0.31.0", "draft": false, "prerelease": false}`))
+	require.NoError(t, err)
+	require.Equal(t, "0.31.0", latest)
+
+	latest, err = latestRelease([]byte(`[
+		{"tag_name": "v0.32.0-rc.1", "prerelease": true},
+		{"tag_name": "v0.33.0", "draft": true},
+		{"tag_name": "v0.31.1"},
+		{"tag_name": "nightly"},
+		{"tag_name": "v0.31.0"}
+	]`))
+	require.NoError(t, err)
+	require.Equal(t, "0.31.1", latest)
+
+	_, err = latestRelease([]byte(`[]`))
+	require.Error(t, err)
+
+	_, err = latestRelease([]byte(`<html>`))
+	require.Error(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"tag_name": "v0.31.0"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	checker, err := New(slog.New(slog.DiscardHandler), "0.30.0", Options{
+		FeedURL:  server.URL,
+		Interval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(checker))
+
+	// Nothing is exported before the first successful check.
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Empty(t, families)
+
+	require.NoError(t, checker.Check(t.Context()))
+
+	families, err = registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 2)
+	require.Equal(t, "windows_exporter_update_available", families[0].GetName())
+	require.Len(t, families[0].GetMetric(), 1)
+	require.Equal(t, "version", families[0].GetMetric()[0].GetLabel()[0].GetName())
+	require.Equal(t, "0.31.0", families[0].GetMetric()[0].GetLabel()[0].GetValue())
+	require.InDelta(t, 1.0, families[0].GetMetric()[0].GetGauge().GetValue(), 0)
+	require.Equal(t, "windows_exporter_update_check_last_success_timestamp_seconds", families[1].GetName())
+}
+
+func TestNewInvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(slog.New(slog.DiscardHandler), "0.31.0", Options{FeedURL: "ftp://example.com/releases", Interval: time.Hour})
+	require.Error(t, err)
+
+	_, err = New(slog.New(slog.DiscardHandler), "0.31.0", Options{FeedURL: "https://example.com/releases"})
+	require.Error(t, err)
+}
@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package zabbix
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"go.yaml.in/yaml/v3"
+)
+
+// placeholderRegexp matches the label placeholders of a key, e.g. {volume} in vfs.fs.size[{volume},free].
+//
+//nolint:gochecknoglobals
+var placeholderRegexp = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
+// Key maps the series of a metric to the key of a Zabbix item.
+type Key struct {
+	// Metric is the name of the metric, e.g. windows_logical_disk_free_bytes.
+	Metric string `yaml:"metric"`
+	// Labels select the series of the metric whose labels have the given values. If empty, all series are selected.
+	Labels map[string]string `yaml:"labels"`
+	// Key is the key of the item. Placeholders like {volume} are replaced by the value of the label,
+	// which is quoted if it contains characters that are special in the parameters of keys.
+	// Series with the same key are summed up.
+	Key string `yaml:"key"`
+}
+
+// LoadKeys reads the keys from a YAML file, which contains a list of keys.
+func LoadKeys(path string) ([]Key, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Zabbix keys file: %w", err)
+	}
+
+	defer file.Close()
+
+	var keys []Key
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&keys); err != nil {
+		return nil, fmt.Errorf("failed to parse Zabbix keys file: %w", err)
+	}
+
+	for i, key := range keys {
+		if key.Metric == "" || key.Key == "" {
+			return nil, fmt.Errorf("key %d of the Zabbix keys file: metric and key are required", i+1)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no keys in the Zabbix keys file")
+	}
+
+	return keys, nil
+}
+
+// newValues converts the series of the metric families to the values of the Zabbix items.
+// Only counters, gauges and untyped metrics are sent. Sums that are not finite are dropped.
+func newValues(host string, keys []Key, metricFamilies []*dto.MetricFamily, now time.Time) []value {
+	families := make(map[string]*dto.MetricFamily, len(metricFamilies))
+	for _, family := range metricFamilies {
+		families[family.GetName()] = family
+	}
+
+	// The order of the values follows the keys and the series.
+	itemKeys := make([]string, 0)
+	sums := make(map[string]float64)
+
+	for _, key := range keys {
+		family, ok := families[key.Metric]
+		if !ok {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			v, ok := metricValue(family.GetType(), metric)
+			if !ok || !matchLabels(metric, key.Labels) {
+				continue
+			}
+
+			itemKey := expandKey(key.Key, metric)
+			if _, ok := sums[itemKey]; !ok {
+				itemKeys = append(itemKeys, itemKey)
+			}
+
+			sums[itemKey] += v
+		}
+	}
+
+	values := make([]value, 0, len(itemKeys))
+
+	for _, itemKey := range itemKeys {
+		sum := sums[itemKey]
+		if math.IsNaN(sum) || math.IsInf(sum, 0) {
+			continue
+		}
+
+		values = append(values, value{
+			Host:  host,
+			Key:   itemKey,
+			Value: strconv.FormatFloat(sum, 'f', -1, 64),
+			Clock: now.Unix(),
+			NS:    int64(now.Nanosecond()),
+		})
+	}
+
+	return values
+}
+
+func metricValue(metricType dto.MetricType, metric *dto.Metric) (float64, bool) {
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		return metric.GetCounter().GetValue(), true
+	case dto.MetricType_GAUGE:
+		return metric.GetGauge().GetValue(), true
+	case dto.MetricType_UNTYPED:
+		return metric.GetUntyped().GetValue(), true
+	default:
+		return 0, false
+	}
+}
+
+func matchLabels(metric *dto.Metric, labels map[string]string) bool {
+	for name, expected := range labels {
+		if labelValue(metric, name) != expected {
+			return false
+		}
+	}
+
+	return true
+}
+
+// expandKey replaces the placeholders of the key by the label values of the metric.
+// Placeholders of missing labels are replaced by empty strings.
+func expandKey(key string, metric *dto.Metric) string {
+	return placeholderRegexp.ReplaceAllStringFunc(key, func(placeholder string) string {
+		return quoteParameter(labelValue(metric, placeholder[1:len(placeholder)-1]))
+	})
+}
+
+// quoteParameter quotes a parameter of a key that contains a comma, a closing bracket or a quote,
+// or starts with a space, see the item key format of Zabbix.
+func quoteParameter(parameter string) string {
+	if !strings.ContainsAny(parameter, `,]"`) && !strings.HasPrefix(parameter, " ") {
+		return parameter
+	}
+
+	return `"` + strings.ReplaceAll(parameter, `"`, `\"`) + `"`
+}
+
+func labelValue(metric *dto.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,278 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+// Package zabbix sends selected metrics of windows_exporter to a Zabbix server or proxy
+// with the Zabbix sender protocol, like zabbix_sender does for trapper items.
+package zabbix
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"regexp"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// defaultPort is the trapper port of the Zabbix server and proxy.
+const defaultPort = "10051"
+
+// maxResponseSize limits the response of the server, which only contains a short summary.
+const maxResponseSize = 64 * 1024
+
+// header precedes each message of the Zabbix protocol: "ZBXD" and the protocol flag 0x01.
+//
+//nolint:gochecknoglobals
+var header = []byte("ZBXD\x01")
+
+// infoRegexp matches the summary of the server, e.g. "processed: 1; failed: 0; total: 1; seconds spent: 0.000055".
+//
+//nolint:gochecknoglobals
+var infoRegexp = regexp.MustCompile(`processed: (\d+); failed: (\d+); total: (\d+)`)
+
+// GatherFunc collects the metric families to send within the given timeout.
+type GatherFunc func(timeout time.Duration) ([]*dto.MetricFamily, error)
+
+type Options struct {
+	// Server is the address of the Zabbix server or proxy. If the address has no port, 10051 is used.
+	Server string
+	// Host is the name of the host in Zabbix. If empty, the hostname is used.
+	Host string
+	// Interval is the time between two sends.
+	Interval time.Duration
+	// Timeout limits the collection and the request of a send.
+	Timeout time.Duration
+	// Keys map the metrics to the keys of Zabbix items. Metrics without key are not sent.
+	Keys []Key
+}
+
+// Sender periodically sends the mapped metrics to a Zabbix server or proxy.
+type Sender struct {
+	logger  *slog.Logger
+	gather  GatherFunc
+	server  string
+	options Options
+	dialer  *net.Dialer
+}
+
+type request struct {
+	Request string  `json:"request"`
+	Data    []value `json:"data"`
+	Clock   int64   `json:"clock"`
+	NS      int64   `json:"ns"`
+}
+
+type value struct {
+	Host  string `json:"host"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Clock int64  `json:"clock"`
+	NS    int64  `json:"ns"`
+}
+
+type response struct {
+	Response string `json:"response"`
+	Info     string `json:"info"`
+}
+
+// New returns a Sender for the given options.
+func New(logger *slog.Logger, gather GatherFunc, options Options) (*Sender, error) {
+	server := options.Server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, defaultPort)
+	}
+
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		return nil, fmt.Errorf("invalid Zabbix server %q: %w", options.Server, err)
+	}
+
+	if options.Interval <= 0 {
+		return nil, errors.New("interval of the Zabbix sender must be greater than 0")
+	}
+
+	if options.Timeout <= 0 || options.Timeout > options.Interval {
+		options.Timeout = options.Interval
+	}
+
+	if len(options.Keys) == 0 {
+		return nil, errors.New("no Zabbix keys configured")
+	}
+
+	if options.Host == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname: %w", err)
+		}
+
+		options.Host = hostname
+	}
+
+	return &Sender{
+		logger:  logger.With(slog.String("server", server)),
+		gather:  gather,
+		server:  server,
+		options: options,
+		dialer:  &net.Dialer{},
+	}, nil
+}
+
+// Run sends the metrics once per interval until ctx is cancelled.
+// Failed sends are logged and not retried, the next send sends the current values.
+func (s *Sender) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Send(ctx); err != nil {
+			s.logger.LogAttrs(ctx, slog.LevelError, "failed to send metrics to Zabbix",
+				slog.Any("err", err),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Send gathers the metrics and sends the mapped values to the Zabbix server.
+func (s *Sender) Send(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.options.Timeout)
+	defer cancel()
+
+	metricFamilies, err := s.gather(s.options.Timeout)
+	if err != nil {
+		// Gather returns the metrics of the successful collectors next to the error.
+		s.logger.LogAttrs(ctx, slog.LevelWarn, "error while gathering metrics",
+			slog.Any("err", err),
+		)
+	}
+
+	now := time.Now()
+
+	values := newValues(s.options.Host, s.options.Keys, metricFamilies, now)
+	if len(values) == 0 {
+		return errors.New("no metrics matched the Zabbix keys")
+	}
+
+	resp, err := s.send(ctx, request{
+		Request: "sender data",
+		Data:    values,
+		Clock:   now.Unix(),
+		NS:      int64(now.Nanosecond()),
+	})
+	if err != nil {
+		return err
+	}
+
+	if resp.Response != "success" {
+		return fmt.Errorf("server returned %q: %s", resp.Response, resp.Info)
+	}
+
+	// Values of keys without trapper item on the host are counted as failed.
+	if match := infoRegexp.FindStringSubmatch(resp.Info); match != nil && match[2] != "0" {
+		s.logger.LogAttrs(ctx, slog.LevelWarn, "Zabbix server rejected values, check that the host has trapper items for all keys",
+			slog.String("info", resp.Info),
+		)
+	}
+
+	s.logger.LogAttrs(ctx, slog.LevelDebug, "sent metrics to Zabbix",
+		slog.Int("values", len(values)),
+		slog.String("info", resp.Info),
+	)
+
+	return nil
+}
+
+func (s *Sender) send(ctx context.Context, req request) (response, error) {
+	var resp response
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return resp, fmt.Errorf("failed to encode values: %w", err)
+	}
+
+	conn, err := s.dialer.DialContext(ctx, "tcp", s.server)
+	if err != nil {
+		return resp, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write(encodeMessage(body)); err != nil {
+		return resp, fmt.Errorf("failed to send values: %w", err)
+	}
+
+	content, err := readMessage(conn)
+	if err != nil {
+		return resp, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := json.Unmarshal(content, &resp); err != nil {
+		return resp, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, nil
+}
+
+// encodeMessage prepends the header and the length of the content. The length is followed by 4 reserved bytes,
+// which are only used by compressed messages.
+func encodeMessage(content []byte) []byte {
+	message := make([]byte, 0, len(header)+8+len(content))
+	message = append(message, header...)
+	message = binary.LittleEndian.AppendUint32(message, uint32(len(content)))
+	message = binary.LittleEndian.AppendUint32(message, 0)
+
+	return append(message, content...)
+}
+
+func readMessage(r io.Reader) ([]byte, error) {
+	prefix := make([]byte, len(header)+8)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(prefix[:len(header)], header) {
+		return nil, errors.New("invalid header")
+	}
+
+	length := binary.LittleEndian.Uint32(prefix[len(header):])
+	if length > maxResponseSize {
+		return nil, fmt.Errorf("response of %d bytes exceeds the limit of %d bytes", length, maxResponseSize)
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package zabbix
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func testMetricFamilies() []*dto.MetricFamily {
+	label := func(name, value string) *dto.LabelPair {
+		return &dto.LabelPair{Name: proto.String(name), Value: proto.String(value)}
+	}
+
+	return []*dto.MetricFamily{
+		{
+			Name: proto.String("windows_cpu_time_total"),
+			Type: dto.MetricType_COUNTER.Enum(),
+			Metric: []*dto.Metric{
+				{Label: []*dto.LabelPair{label("core", "0,0"), label("mode", "idle")}, Counter: &dto.Counter{Value: proto.Float64(10)}},
+				{Label: []*dto.LabelPair{label("core", "0,1"), label("mode", "idle")}, Counter: &dto.Counter{Value: proto.Float64(20.5)}},
+				{Label: []*dto.LabelPair{label("core", "0,0"), label("mode", "user")}, Counter: &dto.Counter{Value: proto.Float64(5)}},
+			},
+		},
+		{
+			Name: proto.String("windows_logical_disk_free_bytes"),
+			Type: dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{
+				{Label: []*dto.LabelPair{label("volume", "C:")}, Gauge: &dto.Gauge{Value: proto.Float64(1e12)}},
+				{Label: []*dto.LabelPair{label("volume", "D:")}, Gauge: &dto.Gauge{Value: proto.Float64(math.NaN())}},
+			},
+		},
+		{
+			Name:   proto.String("windows_test_duration_seconds"),
+			Type:   dto.MetricType_HISTOGRAM.Enum(),
+			Metric: []*dto.Metric{{Histogram: &dto.Histogram{SampleCount: proto.Uint64(1)}}},
+		},
+	}
+}
+
+func TestNewValues(t *testing.T) {
+	t.Parallel()
+
+	keys := []Key{
+		{Metric: "windows_cpu_time_total", Key: "windows.cpu.time[{mode}]"},
+		{Metric: "windows_cpu_time_total", Labels: map[string]string{"mode": "idle"}, Key: "windows.cpu.idle[{core}]"},
+		{Metric: "windows_logical_disk_free_bytes", Key: "vfs.fs.size[{volume},free]"},
+		{Metric: "windows_test_duration_seconds", Key: "windows.test"},
+		{Metric: "windows_missing", Key: "windows.missing"},
+	}
+
+	now := time.Unix(1700000000, 5)
+
+	values := newValues("host01", keys, testMetricFamilies(), now)
+
+	keyValues := make(map[string]string, len(values))
+	for _, v := range values {
+		require.Equal(t, "host01", v.Host)
+		require.Equal(t, int64(1700000000), v.Clock)
+		require.Equal(t, int64(5), v.NS)
+
+		keyValues[v.Key] = v.Value
+	}
+
+	require.Equal(t, map[string]string{
+		"windows.cpu.time[idle]":  "30.5",
+		"windows.cpu.time[user]":  "5",
+		`windows.cpu.idle["0,0"]`: "10",
+		`windows.cpu.idle["0,1"]`: "20.5",
+		"vfs.fs.size[C:,free]":    "1000000000000",
+	}, keyValues)
+}
+
+func TestSend(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	received := make(chan request, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		content, err := readMessage(conn)
+		if err != nil {
+			return
+		}
+
+		var req request
+		if err := json.Unmarshal(content, &req); err != nil {
+			return
+		}
+
+		received <- req
+
+		resp, _ := json.Marshal(response{Response: "success", Info: "processed: 1; failed: 0; total: 1; seconds spent: 0.000055"})
+		_, _ = conn.Write(encodeMessage(resp))
+	}()
+
+	sender, err := New(slog.New(slog.DiscardHandler), func(time.Duration) ([]*dto.MetricFamily, error) {
+		return testMetricFamilies(), nil
+	}, Options{
+		Server:   listener.Addr().String(),
+		Host:     "host01",
+		Interval: time.Minute,
+		Keys:     []Key{{Metric: "windows_logical_disk_free_bytes", Labels: map[string]string{"volume": "C:"}, Key: "vfs.fs.size[C:,free]"}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, sender.Send(t.Context()))
+
+	req := <-received
+	require.Equal(t, "sender data", req.Request)
+	require.Len(t, req.Data, 1)
+	require.Equal(t, "vfs.fs.size[C:,free]", req.Data[0].Key)
+	require.Equal(t, "1000000000000", req.Data[0].Value)
+}
+
+func TestLoadKeys(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+- metric: windows_logical_disk_free_bytes
+  labels:
+    volume: "C:"
+  key: vfs.fs.size[C:,free]
+`), 0o600))
+
+	keys, err := LoadKeys(path)
+	require.NoError(t, err)
+	require.Equal(t, []Key{{Metric: "windows_logical_disk_free_bytes", Labels: map[string]string{"volume": "C:"}, Key: "vfs.fs.size[C:,free]"}}, keys)
+
+	require.NoError(t, os.WriteFile(path, []byte("- metric: windows_cpu_time_total\n"), 0o600))
+
+	_, err = LoadKeys(path)
+	require.ErrorContains(t, err, "metric and key are required")
+}
+
+func TestEncodeMessage(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []byte("ZBXD\x01\x02\x00\x00\x00\x00\x00\x00\x00{}"), encodeMessage([]byte("{}")))
+}
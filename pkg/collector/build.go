@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+)
+
+const (
+	// startupBuildTimeout limits the time the exporter waits for the collectors at startup.
+	// Collectors that take longer are initialized in the background and fail their scrapes until they are ready.
+	startupBuildTimeout = 30 * time.Second
+
+	buildRetryInitialBackoff = 10 * time.Second
+	buildRetryMaxBackoff     = 5 * time.Minute
+)
+
+var errBuildRunning = errors.New("collector is still initializing")
+
+// collectorBuild tracks the initialization of a local collector. If Build fails, e.g. because the SQL instance or the
+// cluster service is still starting, the collector stays enabled and Build is retried with exponential backoff
+// on later scrapes.
+type collectorBuild struct {
+	mu      sync.Mutex
+	running bool
+	// background is set if nobody waits for the running build, so it logs its own failure.
+	background  bool
+	err         error
+	attempts    int
+	nextAttempt time.Time
+}
+
+// start runs Build of the collector in a goroutine and calls wg.Done after it finished.
+func (b *collectorBuild) start(logger *slog.Logger, name string, collector Collector, miSession *mi.Session, wg *sync.WaitGroup) {
+	b.mu.Lock()
+	b.running = true
+	b.mu.Unlock()
+
+	go func() {
+		defer wg.Done()
+
+		b.run(logger, name, collector, miSession)
+	}()
+}
+
+// run calls Build of the collector. b.running must be set by the caller.
+func (b *collectorBuild) run(logger *slog.Logger, name string, collector Collector, miSession *mi.Session) {
+	var err error
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic in build of collector %s: %v. stack: %s", name, r, string(debug.Stack()))
+			}
+		}()
+
+		err = collector.Build(logger, miSession)
+	}()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.running = false
+	b.err = err
+
+	if err == nil {
+		b.attempts = 0
+
+		if b.background {
+			logger.LogAttrs(context.Background(), slog.LevelInfo, "initialized collector "+name)
+		}
+
+		return
+	}
+
+	b.attempts++
+	b.nextAttempt = time.Now().Add(b.backoff())
+
+	if b.background {
+		logger.LogAttrs(context.Background(), slog.LevelWarn, "couldn't initialize collector "+name,
+			slog.Any("err", err),
+			slog.Int("attempts", b.attempts),
+			slog.Time("next_attempt", b.nextAttempt),
+		)
+	}
+}
+
+// backoff returns the time until the next attempt, which doubles with each failed attempt.
+func (b *collectorBuild) backoff() time.Duration {
+	backoff := buildRetryInitialBackoff
+	for range b.attempts - 1 {
+		backoff *= 2
+
+		if backoff >= buildRetryMaxBackoff {
+			return buildRetryMaxBackoff
+		}
+	}
+
+	return backoff
+}
+
+// detach lets a running build continue in the background and returns true, or returns false if it already finished.
+func (b *collectorBuild) detach() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.running {
+		b.background = true
+	}
+
+	return b.running
+}
+
+// lastError returns the error of the finished build.
+func (b *collectorBuild) lastError() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.err
+}
+
+// ready returns nil if the collector is initialized. Otherwise, it starts the next attempt in the background,
+// if its backoff has passed, and returns the reason why the collector can't be collected yet.
+func (b *collectorBuild) ready(logger *slog.Logger, name string, collector Collector, miSession *mi.Session) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case b.running:
+		return errBuildRunning
+	case b.err == nil:
+		return nil
+	case time.Now().Before(b.nextAttempt):
+		return fmt.Errorf("initialization failed %d times, next attempt at %s: %w", b.attempts, b.nextAttempt.Format(time.RFC3339), b.err)
+	}
+
+	b.running = true
+	b.background = true
+
+	logger.LogAttrs(context.Background(), slog.LevelInfo, "retrying initialization of collector "+name)
+
+	go b.run(logger, name, collector, miSession)
+
+	return errBuildRunning
+}
+
+// status returns nil if the collector is initialized, or the reason why it isn't. Unlike ready, it doesn't start
+// another attempt.
+func (b *collectorBuild) status() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.running {
+		return errBuildRunning
+	}
+
+	return b.err
+}
+
+// NotReady returns the enabled collectors that are not initialized by name, with the reason, e.g. because the
+// performance counters or the WMI classes of the collector are not available yet.
+// Collectors that are disabled at runtime are ignored.
+func (c *Collection) NotReady() map[string]error {
+	notReady := make(map[string]error)
+
+	for name := range c.activeCollectors() {
+		if err := c.builds[name].status(); err != nil {
+			notReady[name] = err
+		}
+	}
+
+	return notReady
+}
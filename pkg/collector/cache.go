@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package collector
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// resultCache keeps the metrics of the last collection of each collector for a fixed duration.
+// Scrapes within the duration are served from the cache instead of running the collector again.
+// The cache is shared by all copies of a Collection.
+type resultCache struct {
+	duration time.Duration
+	// collectors are the names of the cached collectors. If empty, all collectors are cached.
+	collectors []string
+
+	mu      sync.Mutex
+	results map[string]cachedResult
+}
+
+type cachedResult struct {
+	metrics    []prometheus.Metric
+	statusCode collectorStatusCode
+	duration   time.Duration
+	// err is the error returned by the collector, if any.
+	err     error
+	expires time.Time
+}
+
+// SetCache enables caching of collector results for the given duration. If collectors is empty,
+// the results of all collectors are cached. A duration of 0 disables the cache.
+// Must be called before the first scrape.
+func (c *Collection) SetCache(duration time.Duration, collectors []string) error {
+	for _, name := range collectors {
+		if _, ok := BuildersWithFlags[name]; !ok {
+			return fmt.Errorf("cache for unknown collector %s", name)
+		}
+	}
+
+	if duration <= 0 {
+		c.cache = nil
+
+		return nil
+	}
+
+	c.cache = &resultCache{
+		duration:   duration,
+		collectors: collectors,
+		results:    make(map[string]cachedResult),
+	}
+
+	return nil
+}
+
+// CacheEnabled returns true if collector results are cached, see SetCache.
+func (c *Collection) CacheEnabled() bool {
+	return c.cache != nil
+}
+
+// WithoutCache returns a copy of the collection that always runs the collectors, e.g. for captures
+// with a higher resolution than the cache duration.
+func (c *Collection) WithoutCache() *Collection {
+	metricCollectors := *c
+	metricCollectors.cache = nil
+
+	return &metricCollectors
+}
+
+func (r *resultCache) enabled(name string) bool {
+	return r != nil && (len(r.collectors) == 0 || slices.Contains(r.collectors, name))
+}
+
+// get returns the cached result of the collector, if it has not expired yet.
+func (r *resultCache) get(name string) (cachedResult, bool) {
+	if !r.enabled(name) {
+		return cachedResult{}, false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.results[name]
+	if !ok || time.Now().After(result.expires) {
+		return cachedResult{}, false
+	}
+
+	return result, true
+}
+
+func (r *resultCache) set(name string, result cachedResult) {
+	if !r.enabled(name) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result.expires = time.Now().Add(r.duration)
+	r.results[name] = result
+}
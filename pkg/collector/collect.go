@@ -24,10 +24,10 @@ import (
 	"log/slog"
 	"runtime/debug"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"github.com/prometheus-community/windows_exporter/internal/pdh/registry"
 	"github.com/prometheus-community/windows_exporter/internal/types"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sys/windows"
@@ -46,26 +46,168 @@ const (
 	failed
 )
 
-func (c *Collection) collectAll(ch chan<- prometheus.Metric, logger *slog.Logger, maxScrapeDuration time.Duration) {
+// String returns the status of the span of a collector, see CollectorSpan.
+func (s collectorStatusCode) String() string {
+	switch s {
+	case success:
+		return "success"
+	case failed:
+		return "failed"
+	default:
+		return "timeout"
+	}
+}
+
+// flight is a running collection of a collector. Concurrent scrapes attach to the flight instead of running the
+// collector again, so a collector is never collected concurrently. A collector that timed out keeps its flight
+// until the abandoned Collect returned, e.g. while a hung WMI provider or PDH query blocks it. Later scrapes attach
+// to it instead of piling up more hung calls.
+type flight struct {
+	done chan struct{}
+	// result holds all metrics of the collector before filtering. It is set before done is closed.
+	result cachedResult
+}
+
+// collectorFlights are the running flights by collector, shared by all copies of the collection.
+type collectorFlights struct {
+	mu      sync.Mutex
+	running map[string]*flight
+	// ctx is the parent of the flights. It is detached from the scrapes and cancelled on Close.
+	ctx    context.Context //nolint:containedctx
+	cancel context.CancelFunc
+}
+
+func newCollectorFlights() *collectorFlights {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &collectorFlights{running: make(map[string]*flight), ctx: ctx, cancel: cancel}
+}
+
+// join returns the running flight of the collector. If there is none, a new flight is returned and leader is true.
+// The leader must run the collector and call finish.
+func (r *collectorFlights) join(name string) (*flight, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.running[name]; ok {
+		return f, false
+	}
+
+	f := &flight{done: make(chan struct{})}
+	r.running[name] = f
+
+	return f, true
+}
+
+func (r *collectorFlights) finish(name string, f *flight, result cachedResult) {
+	r.mu.Lock()
+	delete(r.running, name)
+	r.mu.Unlock()
+
+	f.result = result
+	close(f.done)
+}
+
+// wait returns once all flights finished, including flights whose scrapes timed out and abandoned them.
+func (r *collectorFlights) wait() {
+	for {
+		var running *flight
+
+		r.mu.Lock()
+		for _, f := range r.running {
+			running = f
+
+			break
+		}
+		r.mu.Unlock()
+
+		if running == nil {
+			return
+		}
+
+		<-running.done
+	}
+}
+
+// lastSuccesses are the times of the last successful collection by collector.
+type lastSuccesses struct {
+	mu    sync.Mutex
+	times map[string]time.Time
+}
+
+func (l *lastSuccesses) set(name string, t time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.times[name] = t
+}
+
+func (l *lastSuccesses) get(name string) (time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	t, ok := l.times[name]
+
+	return t, ok
+}
+
+// lastCollections are the results of the last collection by collector, see Collection.Status.
+type lastCollections struct {
+	mu          sync.Mutex
+	collections map[string]LastCollection
+}
+
+func (l *lastCollections) set(name string, collection LastCollection) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.collections[name] = collection
+}
+
+func (l *lastCollections) get(name string) (LastCollection, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	collection, ok := l.collections[name]
+
+	return collection, ok
+}
+
+func (c *Collection) collectAll(ch chan<- prometheus.Metric, logger *slog.Logger, maxScrapeDuration time.Duration, recordSpan SpanRecorder) {
 	collectorStartTime := time.Now()
+	collectors := c.activeCollectors()
+
+	// Remote collections don't read the local performance data.
+	if !c.remote {
+		defer registry.BeginScrape()()
+	}
 
 	// WaitGroup to wait for all collectors to finish
 	wg := sync.WaitGroup{}
-	wg.Add(len(c.collectors))
+	wg.Add(len(collectors))
 
 	// Using a channel to collect the status of each collector
 	// A channel is safe to use concurrently while a map is not
-	collectorStatusCh := make(chan collectorStatus, len(c.collectors))
+	collectorStatusCh := make(chan collectorStatus, len(collectors))
 
 	// Execute all collectors concurrently
 	// timeout handling is done in the execute function
-	for name, metricsCollector := range c.collectors {
+	for name, metricsCollector := range collectors {
 		go func(name string, metricsCollector Collector) {
 			defer wg.Done()
 
+			span := CollectorSpan{Collector: name, Start: time.Now()}
+			statusCode := c.collectCollector(ch, logger, name, metricsCollector, maxScrapeDuration, &span)
+
+			if recordSpan != nil {
+				span.End = time.Now()
+				span.Status = statusCode.String()
+				recordSpan(span)
+			}
+
 			collectorStatusCh <- collectorStatus{
 				name:       name,
-				statusCode: c.collectCollector(ch, logger, name, metricsCollector, maxScrapeDuration),
+				statusCode: statusCode,
 			}
 		}(name, metricsCollector)
 	}
@@ -99,8 +241,52 @@ func (c *Collection) collectAll(ch chan<- prometheus.Metric, logger *slog.Logger
 			timeoutValue,
 			status.name,
 		)
+
+		lastSuccess, ok := c.lastSuccesses.get(status.name)
+
+		// A successful scrape of a background collector may return the result of a background collection
+		// that succeeded long ago, so its own time is reported.
+		if backgroundCollector, isBackground := collectors[status.name].(BackgroundCollector); isBackground {
+			lastSuccess = backgroundCollector.LastSuccess()
+			ok = !lastSuccess.IsZero()
+		}
+
+		if ok {
+			ch <- prometheus.MustNewConstMetric(
+				c.collectorLastSuccessDesc,
+				prometheus.GaugeValue,
+				float64(lastSuccess.UnixMicro())/1e6,
+				status.name,
+			)
+		}
+
+		if cost, ok := c.startupCosts.get(status.name); ok {
+			ch <- prometheus.MustNewConstMetric(
+				c.collectorStartupCostDesc,
+				prometheus.GaugeValue,
+				cost.Seconds(),
+				status.name,
+			)
+		}
+	}
+
+	for name, exclusion := range c.excludedCollectors {
+		ch <- prometheus.MustNewConstMetric(
+			c.collectorExcludedDesc,
+			prometheus.GaugeValue,
+			1.0,
+			name,
+			exclusion.reason,
+			exclusion.detail,
+		)
 	}
 
+	ch <- prometheus.MustNewConstMetric(
+		c.maintenanceModeDesc,
+		prometheus.GaugeValue,
+		c.maintenanceValue(),
+	)
+
 	ch <- prometheus.MustNewConstMetric(
 		c.scrapeDurationDesc,
 		prometheus.GaugeValue,
@@ -108,108 +294,170 @@ func (c *Collection) collectAll(ch chan<- prometheus.Metric, logger *slog.Logger
 	)
 }
 
-func (c *Collection) collectCollector(ch chan<- prometheus.Metric, logger *slog.Logger, name string, collector Collector, maxScrapeDuration time.Duration) collectorStatusCode {
-	var (
-		err        error
-		numMetrics int
-		duration   time.Duration
-		timeout    atomic.Bool
-	)
-
-	// bufCh is a buffer channel to store the metrics
-	// This is needed because once timeout is reached, the prometheus registry channel is closed.
-	bufCh := make(chan prometheus.Metric, 1000)
-	errCh := make(chan error, 1)
+// collectCollector sends the metrics of the collector to ch and fills the backend, metrics and error of span.
+func (c *Collection) collectCollector(ch chan<- prometheus.Metric, logger *slog.Logger, name string, collector Collector, maxScrapeDuration time.Duration, span *CollectorSpan) collectorStatusCode {
+	if err := c.builds[name].ready(logger, name, collector, c.miSession); err != nil {
+		span.Err = err
 
-	ctx, cancel := context.WithTimeout(context.Background(), maxScrapeDuration)
-	defer cancel()
+		logger.LogAttrs(context.Background(), slog.LevelDebug, fmt.Sprintf("collector %s is not initialized", name),
+			slog.Any("err", err),
+		)
 
-	// execute the collector
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				errCh <- fmt.Errorf("panic in collector %s: %v. stack: %s", name, r,
-					string(debug.Stack()),
-				)
-			}
+		ch <- prometheus.MustNewConstMetric(
+			c.collectorScrapeDurationDesc,
+			prometheus.GaugeValue,
+			0,
+			name,
+		)
 
-			close(bufCh)
-		}()
+		return failed
+	}
 
-		errCh <- collector.Collect(bufCh)
-	}()
+	filter, hasFilter := c.filters[name]
 
-	wg := sync.WaitGroup{}
-	wg.Add(1)
+	if result, ok := c.cache.get(name); ok {
+		span.Backend, span.Metrics, span.Err = "cache", len(result.metrics), result.err
 
-	go func() {
-		defer func() {
-			// This prevents a panic from race-condition when closing the ch channel too early.
-			_ = recover()
-
-			wg.Done()
-		}()
-
-		// Pass metrics to the prometheus registry
-		// If timeout is reached, the channel is closed.
-		// This will cause a panic if we try to write to it.
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case m, ok := <-bufCh:
-				if !ok {
-					return
-				}
-
-				if !timeout.Load() {
-					ch <- m
-
-					numMetrics++
-				}
+		for _, m := range result.metrics {
+			if !hasFilter || filter.Match(m) {
+				ch <- m
 			}
 		}
-	}()
+
+		ch <- prometheus.MustNewConstMetric(
+			c.collectorScrapeDurationDesc,
+			prometheus.GaugeValue,
+			result.duration.Seconds(),
+			name,
+		)
+
+		logger.LogAttrs(context.Background(), slog.LevelDebug, fmt.Sprintf("collector %s served %d metrics from cache", name, len(result.metrics)))
+
+		return result.statusCode
+	}
+
+	// A collector with a shorter timeout is cancelled before the scrape timeout, so the other collectors still
+	// return their metrics within the scrape.
+	timeoutDuration := maxScrapeDuration
+	if collectorTimeout, ok := c.timeouts[name]; ok && collectorTimeout < timeoutDuration {
+		timeoutDuration = collectorTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutDuration)
+	defer cancel()
+
+	f, leader := c.flights.join(name)
+	if leader {
+		span.Backend = "collect"
+
+		go c.runFlight(logger, name, collector, f, maxScrapeDuration)
+	} else {
+		span.Backend = "shared"
+
+		logger.LogAttrs(ctx, slog.LevelDebug, fmt.Sprintf("collector %s attached to the running collection", name))
+	}
 
 	t := time.Now()
 
-	// Wait for the collector to finish or timeout
 	select {
-	case err = <-errCh:
-		wg.Wait() // Wait for the buffer channel to be closed and empty
+	case <-f.done:
+		span.Metrics, span.Err = len(f.result.metrics), f.result.err
+
+		for _, m := range f.result.metrics {
+			if !hasFilter || filter.Match(m) {
+				ch <- m
+			}
+		}
 
-		duration = time.Since(t)
 		ch <- prometheus.MustNewConstMetric(
 			c.collectorScrapeDurationDesc,
 			prometheus.GaugeValue,
-			duration.Seconds(),
+			f.result.duration.Seconds(),
 			name,
 		)
+
+		return f.result.statusCode
 	case <-ctx.Done():
-		timeout.Store(true)
+		span.Err = ctx.Err()
 
-		duration = time.Since(t)
 		ch <- prometheus.MustNewConstMetric(
 			c.collectorScrapeDurationDesc,
 			prometheus.GaugeValue,
-			duration.Seconds(),
+			time.Since(t).Seconds(),
 			name,
 		)
 
-		logger.LogAttrs(ctx, slog.LevelWarn, fmt.Sprintf("collector %s timeouted after %s, resulting in %d metrics", name, maxScrapeDuration, numMetrics))
-
-		go func() {
-			// Drain channel in case of premature return to not leak a goroutine.
-			for range bufCh {
-			}
-		}()
+		logger.LogAttrs(ctx, slog.LevelWarn, fmt.Sprintf("collector %s timeouted after %s", name, timeoutDuration))
 
 		return pending
 	}
+}
+
+// runFlight runs the collector in one of the workers and finishes the flight with its metrics.
+// The result is stored in the cache even if the scrape that started the flight timed out in the meantime.
+//
+// The flight doesn't run on the context of the scrape that started it, since scrapes with a longer timeout
+// may attach to it. It is cancelled after the timeout of the collector, see SetTimeouts, or after scrapeTimeout,
+// the timeout of the scrape that started it, if the collector has none. It is cancelled on Close as well.
+func (c *Collection) runFlight(logger *slog.Logger, name string, collector Collector, f *flight, scrapeTimeout time.Duration) {
+	timeout := scrapeTimeout
+	if collectorTimeout, ok := c.timeouts[name]; ok {
+		timeout = collectorTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(c.flights.ctx, timeout)
+	defer cancel()
+
+	result := cachedResult{statusCode: pending}
+
+	defer func() {
+		c.flights.finish(name, f, result)
+	}()
+
+	select {
+	case c.workers <- struct{}{}:
+	case <-ctx.Done():
+		logger.LogAttrs(ctx, slog.LevelWarn, fmt.Sprintf("collector %s skipped, no worker became free within its timeout", name))
+
+		return
+	}
+
+	defer func() {
+		<-c.workers
+	}()
+
+	// Collectors send their metrics while collecting, so they are buffered until Collect returned.
+	metricsCh := make(chan prometheus.Metric, 1000)
+	collectedCh := make(chan []prometheus.Metric)
+
+	go func() {
+		var collected []prometheus.Metric
+		for m := range metricsCh {
+			collected = append(collected, m)
+		}
+
+		collectedCh <- collected
+	}()
+
+	t := time.Now()
+	err := runCollector(ctx, name, collector, metricsCh)
+	result.metrics = <-collectedCh
+	result.duration = time.Since(t)
+	result.err = err
+
+	lastCollection := LastCollection{Time: t, Duration: result.duration, Metrics: len(result.metrics)}
+	if err != nil {
+		lastCollection.Error = err.Error()
+	}
+
+	defer func() {
+		lastCollection.Success = result.statusCode == success
+		c.lastCollections.set(name, lastCollection)
+	}()
 
 	slogAttrs := make([]slog.Attr, 0)
 
-	result := "succeeded"
+	status := "succeeded"
 
 	if err != nil {
 		if !errors.Is(err, pdh.ErrNoData) && !errors.Is(err, types.ErrNoData) && !errors.Is(err, windows.EPT_S_NOT_REGISTERED) {
@@ -218,23 +466,47 @@ func (c *Collection) collectCollector(ch chan<- prometheus.Metric, logger *slog.
 			}
 
 			logger.LogAttrs(ctx, slog.LevelWarn,
-				fmt.Sprintf("collector %s failed after %s, resulting in %d metrics", name, duration, numMetrics),
+				fmt.Sprintf("collector %s failed after %s, resulting in %d metrics", name, result.duration, len(result.metrics)),
 				slog.Any("err", err),
 			)
 
-			return failed
+			result.statusCode = failed
+			c.cache.set(name, result)
+
+			return
 		}
 
 		slogAttrs = append(slogAttrs, slog.Any("err", err))
 
-		result = "succeeded with warnings"
+		status = "succeeded with warnings"
 	}
 
 	logger.LogAttrs(ctx, slog.LevelDebug, fmt.Sprintf(
-		"collector %s %s after %s, resulting in %d metrics", name, result, duration, numMetrics,
+		"collector %s %s after %s, resulting in %d metrics", name, status, result.duration, len(result.metrics),
 	),
 		slogAttrs...,
 	)
 
-	return success
+	result.statusCode = success
+	c.cache.set(name, result)
+	c.lastSuccesses.set(name, time.Now())
+}
+
+// runCollector runs Collect of the collector and closes ch afterward. A panic of the collector is returned as error.
+func runCollector(ctx context.Context, name string, collector Collector, ch chan<- prometheus.Metric) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic in collector %s: %v. stack: %s", name, r,
+				string(debug.Stack()),
+			)
+		}
+
+		close(ch)
+	}()
+
+	if contextCollector, ok := collector.(ContextCollector); ok {
+		return contextCollector.CollectWithContext(ctx, ch)
+	}
+
+	return collector.Collect(ch)
 }
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingCollector blocks in CollectWithContext until ctx is done.
+type blockingCollector struct{}
+
+func (blockingCollector) GetName() string { return "blocking" }
+
+func (blockingCollector) Build(*slog.Logger, *mi.Session) error { return nil }
+
+func (blockingCollector) Close() error { return nil }
+
+func (blockingCollector) Collect(chan<- prometheus.Metric) error {
+	select {}
+}
+
+func (blockingCollector) CollectWithContext(ctx context.Context, _ chan<- prometheus.Metric) error {
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+func TestRunFlightCancelsCollectorWithoutTimeout(t *testing.T) {
+	t.Parallel()
+
+	c := New(Map{"blocking": blockingCollector{}})
+
+	f, leader := c.flights.join("blocking")
+	require.True(t, leader)
+
+	go c.runFlight(slog.New(slog.DiscardHandler), "blocking", blockingCollector{}, f, 50*time.Millisecond)
+
+	select {
+	case <-f.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("flight of a collector without timeout was not cancelled after the scrape timeout")
+	}
+
+	require.ErrorIs(t, f.result.err, context.DeadlineExceeded)
+	require.Equal(t, failed, f.result.statusCode)
+}
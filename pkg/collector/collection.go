@@ -23,49 +23,96 @@ import (
 	"fmt"
 	"log/slog"
 	"maps"
+	"runtime"
 	"slices"
+	"strings"
 	"sync"
 	gotime "time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus-community/windows_exporter/internal/collector/ad"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ad_accounts"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ad_replication"
 	"github.com/prometheus-community/windows_exporter/internal/collector/adcs"
 	"github.com/prometheus-community/windows_exporter/internal/collector/adfs"
+	"github.com/prometheus-community/windows_exporter/internal/collector/appx"
+	"github.com/prometheus-community/windows_exporter/internal/collector/autorun"
+	"github.com/prometheus-community/windows_exporter/internal/collector/bits"
 	"github.com/prometheus-community/windows_exporter/internal/collector/cache"
+	"github.com/prometheus-community/windows_exporter/internal/collector/cert_enrollment"
+	"github.com/prometheus-community/windows_exporter/internal/collector/certificates"
+	"github.com/prometheus-community/windows_exporter/internal/collector/citrix_vda"
+	"github.com/prometheus-community/windows_exporter/internal/collector/cloud"
 	"github.com/prometheus-community/windows_exporter/internal/collector/container"
 	"github.com/prometheus-community/windows_exporter/internal/collector/cpu"
 	"github.com/prometheus-community/windows_exporter/internal/collector/cpu_info"
+	"github.com/prometheus-community/windows_exporter/internal/collector/dc_advertisement"
+	"github.com/prometheus-community/windows_exporter/internal/collector/defender"
+	"github.com/prometheus-community/windows_exporter/internal/collector/delivery_optimization"
 	"github.com/prometheus-community/windows_exporter/internal/collector/dfsr"
 	"github.com/prometheus-community/windows_exporter/internal/collector/dhcp"
+	"github.com/prometheus-community/windows_exporter/internal/collector/disk_cleanup"
 	"github.com/prometheus-community/windows_exporter/internal/collector/diskdrive"
 	"github.com/prometheus-community/windows_exporter/internal/collector/dns"
+	"github.com/prometheus-community/windows_exporter/internal/collector/dns_client"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ephemeral_ports"
+	"github.com/prometheus-community/windows_exporter/internal/collector/eventlog"
 	"github.com/prometheus-community/windows_exporter/internal/collector/exchange"
 	"github.com/prometheus-community/windows_exporter/internal/collector/filetime"
+	"github.com/prometheus-community/windows_exporter/internal/collector/firewall"
 	"github.com/prometheus-community/windows_exporter/internal/collector/fsrmquota"
 	"github.com/prometheus-community/windows_exporter/internal/collector/gpu"
+	"github.com/prometheus-community/windows_exporter/internal/collector/hello_for_business"
+	"github.com/prometheus-community/windows_exporter/internal/collector/hns"
+	"github.com/prometheus-community/windows_exporter/internal/collector/host"
+	"github.com/prometheus-community/windows_exporter/internal/collector/httpsys"
 	"github.com/prometheus-community/windows_exporter/internal/collector/hyperv"
+	"github.com/prometheus-community/windows_exporter/internal/collector/icmp"
 	"github.com/prometheus-community/windows_exporter/internal/collector/iis"
+	"github.com/prometheus-community/windows_exporter/internal/collector/image_load"
+	"github.com/prometheus-community/windows_exporter/internal/collector/job_object"
+	"github.com/prometheus-community/windows_exporter/internal/collector/kubernetes_node"
+	"github.com/prometheus-community/windows_exporter/internal/collector/laps"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ldap_client"
 	"github.com/prometheus-community/windows_exporter/internal/collector/license"
 	"github.com/prometheus-community/windows_exporter/internal/collector/logical_disk"
+	"github.com/prometheus-community/windows_exporter/internal/collector/lsa"
+	"github.com/prometheus-community/windows_exporter/internal/collector/mdm"
 	"github.com/prometheus-community/windows_exporter/internal/collector/memory"
 	"github.com/prometheus-community/windows_exporter/internal/collector/mscluster"
 	"github.com/prometheus-community/windows_exporter/internal/collector/msmq"
 	"github.com/prometheus-community/windows_exporter/internal/collector/mssql"
 	"github.com/prometheus-community/windows_exporter/internal/collector/net"
 	"github.com/prometheus-community/windows_exporter/internal/collector/netframework"
+	"github.com/prometheus-community/windows_exporter/internal/collector/nlb"
 	"github.com/prometheus-community/windows_exporter/internal/collector/nps"
+	"github.com/prometheus-community/windows_exporter/internal/collector/nvme"
+	"github.com/prometheus-community/windows_exporter/internal/collector/onedrive"
 	"github.com/prometheus-community/windows_exporter/internal/collector/os"
+	"github.com/prometheus-community/windows_exporter/internal/collector/outlook_data"
 	"github.com/prometheus-community/windows_exporter/internal/collector/pagefile"
+	"github.com/prometheus-community/windows_exporter/internal/collector/perflib"
 	"github.com/prometheus-community/windows_exporter/internal/collector/performancecounter"
 	"github.com/prometheus-community/windows_exporter/internal/collector/physical_disk"
 	"github.com/prometheus-community/windows_exporter/internal/collector/printer"
+	"github.com/prometheus-community/windows_exporter/internal/collector/probe"
 	"github.com/prometheus-community/windows_exporter/internal/collector/process"
+	"github.com/prometheus-community/windows_exporter/internal/collector/rds_licensing"
 	"github.com/prometheus-community/windows_exporter/internal/collector/remote_fx"
+	"github.com/prometheus-community/windows_exporter/internal/collector/schannel"
 	"github.com/prometheus-community/windows_exporter/internal/collector/scheduled_task"
 	"github.com/prometheus-community/windows_exporter/internal/collector/service"
+	"github.com/prometheus-community/windows_exporter/internal/collector/service_account"
+	"github.com/prometheus-community/windows_exporter/internal/collector/service_slo"
 	"github.com/prometheus-community/windows_exporter/internal/collector/smb"
+	"github.com/prometheus-community/windows_exporter/internal/collector/smb_security"
+	"github.com/prometheus-community/windows_exporter/internal/collector/smb_witness"
 	"github.com/prometheus-community/windows_exporter/internal/collector/smbclient"
 	"github.com/prometheus-community/windows_exporter/internal/collector/smtp"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ssas"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ssis"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ssrs"
+	"github.com/prometheus-community/windows_exporter/internal/collector/storage_hotplug"
 	"github.com/prometheus-community/windows_exporter/internal/collector/system"
 	"github.com/prometheus-community/windows_exporter/internal/collector/tcp"
 	"github.com/prometheus-community/windows_exporter/internal/collector/terminal_services"
@@ -74,12 +121,18 @@ import (
 	"github.com/prometheus-community/windows_exporter/internal/collector/time"
 	"github.com/prometheus-community/windows_exporter/internal/collector/udp"
 	"github.com/prometheus-community/windows_exporter/internal/collector/update"
+	"github.com/prometheus-community/windows_exporter/internal/collector/update_policy"
+	"github.com/prometheus-community/windows_exporter/internal/collector/user_profile"
 	"github.com/prometheus-community/windows_exporter/internal/collector/vmware"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wdac"
+	"github.com/prometheus-community/windows_exporter/internal/collector/web_application_proxy"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wef"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wins"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wmi_health"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wmi_provider_host"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
-	"github.com/prometheus-community/windows_exporter/internal/pdh"
 	"github.com/prometheus-community/windows_exporter/internal/types"
 	"github.com/prometheus/client_golang/prometheus"
-	"golang.org/x/sys/windows/registry"
 )
 
 // NewWithFlags To be called by the exporter for collector initialization before running kingpin.Parse.
@@ -90,7 +143,10 @@ func NewWithFlags(app *kingpin.Application) *Collection {
 		collectors[name] = builder(app)
 	}
 
-	return New(collectors)
+	collection := New(collectors)
+	collection.app = app
+
+	return collection
 }
 
 // NewWithConfig To be called by the external libraries for collector initialization without running [kingpin.Parse].
@@ -99,43 +155,88 @@ func NewWithFlags(app *kingpin.Application) *Collection {
 func NewWithConfig(config Config) *Collection {
 	collectors := Map{}
 	collectors[ad.Name] = ad.New(&config.AD)
+	collectors[ad_accounts.Name] = ad_accounts.New(&config.AdAccounts)
+	collectors[ad_replication.Name] = ad_replication.New(&config.ADReplication)
 	collectors[adcs.Name] = adcs.New(&config.ADCS)
 	collectors[adfs.Name] = adfs.New(&config.ADFS)
+	collectors[appx.Name] = appx.New(&config.AppX)
+	collectors[autorun.Name] = autorun.New(&config.Autorun)
+	collectors[bits.Name] = bits.New(&config.BITS)
 	collectors[cache.Name] = cache.New(&config.Cache)
+	collectors[cert_enrollment.Name] = cert_enrollment.New(&config.CertEnrollment)
+	collectors[certificates.Name] = certificates.New(&config.Certificates)
+	collectors[citrix_vda.Name] = citrix_vda.New(&config.CitrixVDA)
+	collectors[cloud.Name] = cloud.New(&config.Cloud)
 	collectors[container.Name] = container.New(&config.Container)
 	collectors[cpu.Name] = cpu.New(&config.CPU)
 	collectors[cpu_info.Name] = cpu_info.New(&config.CPUInfo)
+	collectors[dc_advertisement.Name] = dc_advertisement.New(&config.DcAdvertisement)
+	collectors[defender.Name] = defender.New(&config.Defender)
+	collectors[delivery_optimization.Name] = delivery_optimization.New(&config.DeliveryOptimization)
 	collectors[dfsr.Name] = dfsr.New(&config.DFSR)
 	collectors[dhcp.Name] = dhcp.New(&config.Dhcp)
+	collectors[disk_cleanup.Name] = disk_cleanup.New(&config.DiskCleanup)
 	collectors[diskdrive.Name] = diskdrive.New(&config.DiskDrive)
 	collectors[dns.Name] = dns.New(&config.DNS)
+	collectors[dns_client.Name] = dns_client.New(&config.DNSClient)
+	collectors[ephemeral_ports.Name] = ephemeral_ports.New(&config.EphemeralPorts)
+	collectors[eventlog.Name] = eventlog.New(&config.EventLog)
 	collectors[exchange.Name] = exchange.New(&config.Exchange)
 	collectors[filetime.Name] = filetime.New(&config.Filetime)
+	collectors[firewall.Name] = firewall.New(&config.Firewall)
 	collectors[fsrmquota.Name] = fsrmquota.New(&config.Fsrmquota)
 	collectors[gpu.Name] = gpu.New(&config.GPU)
+	collectors[hello_for_business.Name] = hello_for_business.New(&config.HelloForBusiness)
+	collectors[hns.Name] = hns.New(&config.HNS)
+	collectors[host.Name] = host.New(&config.Host)
+	collectors[httpsys.Name] = httpsys.New(&config.HTTPSys)
 	collectors[hyperv.Name] = hyperv.New(&config.HyperV)
+	collectors[icmp.Name] = icmp.New(&config.ICMP)
 	collectors[iis.Name] = iis.New(&config.IIS)
+	collectors[image_load.Name] = image_load.New(&config.ImageLoad)
+	collectors[job_object.Name] = job_object.New(&config.JobObject)
+	collectors[kubernetes_node.Name] = kubernetes_node.New(&config.KubernetesNode)
+	collectors[laps.Name] = laps.New(&config.LAPS)
+	collectors[ldap_client.Name] = ldap_client.New(&config.LDAPClient)
 	collectors[license.Name] = license.New(&config.License)
 	collectors[logical_disk.Name] = logical_disk.New(&config.LogicalDisk)
+	collectors[lsa.Name] = lsa.New(&config.LSA)
+	collectors[mdm.Name] = mdm.New(&config.MDM)
 	collectors[memory.Name] = memory.New(&config.Memory)
 	collectors[mscluster.Name] = mscluster.New(&config.MSCluster)
 	collectors[msmq.Name] = msmq.New(&config.Msmq)
 	collectors[mssql.Name] = mssql.New(&config.Mssql)
 	collectors[net.Name] = net.New(&config.Net)
 	collectors[netframework.Name] = netframework.New(&config.NetFramework)
+	collectors[nlb.Name] = nlb.New(&config.NLB)
 	collectors[nps.Name] = nps.New(&config.Nps)
+	collectors[nvme.Name] = nvme.New(&config.Nvme)
+	collectors[onedrive.Name] = onedrive.New(&config.OneDrive)
 	collectors[os.Name] = os.New(&config.OS)
+	collectors[outlook_data.Name] = outlook_data.New(&config.OutlookData)
 	collectors[pagefile.Name] = pagefile.New(&config.Paging)
+	collectors[perflib.Name] = perflib.New(&config.Perflib)
 	collectors[performancecounter.Name] = performancecounter.New(&config.PerformanceCounter)
 	collectors[physical_disk.Name] = physical_disk.New(&config.PhysicalDisk)
 	collectors[printer.Name] = printer.New(&config.Printer)
+	collectors[probe.Name] = probe.New(&config.Probe)
 	collectors[process.Name] = process.New(&config.Process)
+	collectors[rds_licensing.Name] = rds_licensing.New(&config.RDSLicensing)
 	collectors[remote_fx.Name] = remote_fx.New(&config.RemoteFx)
+	collectors[schannel.Name] = schannel.New(&config.Schannel)
 	collectors[scheduled_task.Name] = scheduled_task.New(&config.ScheduledTask)
 	collectors[service.Name] = service.New(&config.Service)
+	collectors[service_account.Name] = service_account.New(&config.ServiceAccount)
+	collectors[service_slo.Name] = service_slo.New(&config.ServiceSLO)
 	collectors[smb.Name] = smb.New(&config.SMB)
+	collectors[smb_security.Name] = smb_security.New(&config.SMBSecurity)
+	collectors[smb_witness.Name] = smb_witness.New(&config.SMBWitness)
 	collectors[smbclient.Name] = smbclient.New(&config.SMBClient)
 	collectors[smtp.Name] = smtp.New(&config.SMTP)
+	collectors[ssas.Name] = ssas.New(&config.SSAS)
+	collectors[ssis.Name] = ssis.New(&config.SSIS)
+	collectors[ssrs.Name] = ssrs.New(&config.SSRS)
+	collectors[storage_hotplug.Name] = storage_hotplug.New(&config.StorageHotplug)
 	collectors[system.Name] = system.New(&config.System)
 	collectors[tcp.Name] = tcp.New(&config.TCP)
 	collectors[terminal_services.Name] = terminal_services.New(&config.TerminalServices)
@@ -144,7 +245,15 @@ func NewWithConfig(config Config) *Collection {
 	collectors[time.Name] = time.New(&config.Time)
 	collectors[udp.Name] = udp.New(&config.UDP)
 	collectors[update.Name] = update.New(&config.Update)
+	collectors[update_policy.Name] = update_policy.New(&config.UpdatePolicy)
+	collectors[user_profile.Name] = user_profile.New(&config.UserProfile)
 	collectors[vmware.Name] = vmware.New(&config.Vmware)
+	collectors[wdac.Name] = wdac.New(&config.WDAC)
+	collectors[web_application_proxy.Name] = web_application_proxy.New(&config.WebApplicationProxy)
+	collectors[wef.Name] = wef.New(&config.WEF)
+	collectors[wins.Name] = wins.New(&config.WINS)
+	collectors[wmi_health.Name] = wmi_health.New(&config.WMIHealth)
+	collectors[wmi_provider_host.Name] = wmi_provider_host.New(&config.WmiProviderHost)
 
 	return New(collectors)
 }
@@ -152,8 +261,15 @@ func NewWithConfig(config Config) *Collection {
 // New To be called by the external libraries for collector initialization.
 func New(collectors Map) *Collection {
 	return &Collection{
-		collectors:    collectors,
-		concurrencyCh: make(chan struct{}, 1),
+		collectors:         collectors,
+		excludedCollectors: make(map[string]collectorExclusion),
+		toggles:            &runtimeToggles{disabled: make(map[string]struct{})},
+		flights:            newCollectorFlights(),
+		workers:            make(chan struct{}, DefaultMaxConcurrency()),
+		lastSuccesses:      &lastSuccesses{times: make(map[string]gotime.Time)},
+		lastCollections:    &lastCollections{collections: make(map[string]LastCollection)},
+		startupCosts:       &startupCosts{costs: make(map[string]gotime.Duration)},
+		maintenance:        &maintenance{},
 		scrapeDurationDesc: prometheus.NewDesc(
 			prometheus.BuildFQName(types.Namespace, "exporter", "scrape_duration_seconds"),
 			"windows_exporter: Total scrape duration.",
@@ -178,6 +294,30 @@ func New(collectors Map) *Collection {
 			[]string{"collector"},
 			nil,
 		),
+		collectorLastSuccessDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, "exporter", "collector_last_success_timestamp_seconds"),
+			"windows_exporter: Unix timestamp of the last successful collection, also on cached scrapes. For collectors that collect in the background, the time of the last successful background collection.",
+			[]string{"collector"},
+			nil,
+		),
+		collectorExcludedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, "exporter", "collector_excluded_info"),
+			"windows_exporter: Enabled collector that was excluded at startup, because it can't work on this system.",
+			[]string{"collector", "reason", "detail"},
+			nil,
+		),
+		collectorStartupCostDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, "exporter", "collector_startup_cost_seconds"),
+			"windows_exporter: Duration of the collection of the startup self-test.",
+			[]string{"collector"},
+			nil,
+		),
+		maintenanceModeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, "host", "maintenance_mode"),
+			"windows_exporter: Whether the host is in maintenance, see /api/v1/maintenance.",
+			nil,
+			nil,
+		),
 	}
 }
 
@@ -207,6 +347,46 @@ func (c *Collection) Disable(disabledCollectors []string) {
 	}
 }
 
+// SetTimeouts sets timeouts of individual collectors. A collector is reported as timed out after its timeout
+// or the scrape timeout, whichever is shorter. Its collection is cancelled after its timeout, while collections of
+// collectors without a timeout are cancelled after the timeout of the scrape that started them.
+func (c *Collection) SetTimeouts(timeouts map[string]gotime.Duration) error {
+	for name, timeout := range timeouts {
+		_, builtin := BuildersWithFlags[name]
+		if !builtin && !isPlugin(c.collectors[name]) {
+			return fmt.Errorf("timeout for unknown collector %s", name)
+		}
+
+		if timeout <= 0 {
+			return fmt.Errorf("timeout for collector %s must be positive, got %s", name, timeout)
+		}
+	}
+
+	c.timeouts = maps.Clone(timeouts)
+
+	return nil
+}
+
+// DefaultMaxConcurrency returns the default number of collectors that collect at the same time.
+// Most collectors wait for WMI, PDH or Win32 calls instead of using the CPU, so at least 4 collectors run
+// concurrently even on small hosts.
+func DefaultMaxConcurrency() int {
+	return max(4, runtime.NumCPU())
+}
+
+// SetMaxConcurrency sets the number of collectors that collect at the same time. Collectors that wait for a worker
+// are reported as timed out if no worker becomes free within their timeout.
+// Must be called before the first scrape.
+func (c *Collection) SetMaxConcurrency(n int) error {
+	if n <= 0 {
+		return fmt.Errorf("max concurrency must be positive, got %d", n)
+	}
+
+	c.workers = make(chan struct{}, n)
+
+	return nil
+}
+
 // Build To be called by the exporter for collector initialization.
 // Instead, fail fast, it will try to build all collectors and return all errors.
 // errors are joined with errors.Join.
@@ -218,46 +398,91 @@ func (c *Collection) Build(ctx context.Context, logger *slog.Logger) error {
 		return fmt.Errorf("error from initialize MI: %w", err)
 	}
 
+	c.probeCompatibility(ctx, logger)
+
+	return c.buildCollectors(ctx, logger)
+}
+
+// buildCollectors builds all collectors with the MI session of the collection.
+//
+// Local collectors that fail to build stay enabled and are retried on later scrapes, see collectorBuild.
+// Collectors that are still building after startupBuildTimeout continue in the background.
+// Remote collections are built for a single request, so their errors are returned.
+func (c *Collection) buildCollectors(ctx context.Context, logger *slog.Logger) error {
 	wg := sync.WaitGroup{}
 	wg.Add(len(c.collectors))
 
-	errCh := make(chan error, len(c.collectors))
-
-	for _, collector := range c.collectors {
-		go func() {
-			defer wg.Done()
+	c.builds = make(map[string]*collectorBuild, len(c.collectors))
 
-			if err = collector.Build(logger, c.miSession); err != nil {
-				errCh <- fmt.Errorf("error build collector %s: %w", collector.GetName(), err)
-			}
-		}()
+	for name, collector := range c.collectors {
+		c.builds[name] = &collectorBuild{}
+		c.builds[name].start(logger, name, collector, c.miSession, &wg)
 	}
 
-	wg.Wait()
+	done := make(chan struct{})
 
-	close(errCh)
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	if c.remote {
+		<-done
+	} else {
+		select {
+		case <-done:
+		case <-gotime.After(startupBuildTimeout):
+		}
+	}
 
 	errs := make([]error, 0, len(c.collectors))
 
-	for err := range errCh {
-		if errors.Is(err, pdh.ErrNoData) ||
-			errors.Is(err, registry.ErrNotExist) ||
-			errors.Is(err, pdh.NewPdhError(pdh.CstatusNoObject)) ||
-			errors.Is(err, pdh.NewPdhError(pdh.CstatusNoCounter)) ||
-			errors.Is(err, mi.MI_RESULT_INVALID_NAMESPACE) {
-			logger.LogAttrs(ctx, slog.LevelWarn, "couldn't initialize collector", slog.Any("err", err))
+	for name, build := range c.builds {
+		if build.detach() {
+			logger.LogAttrs(ctx, slog.LevelWarn, fmt.Sprintf("collector %s is still initializing after %s, continuing in the background", name, startupBuildTimeout))
+
+			continue
+		}
+
+		buildErr := build.lastError()
+		if buildErr == nil {
+			continue
+		}
+
+		// Collectors of roles or applications that are not installed would fail every scrape.
+		if isMissingRoleError(buildErr) {
+			_ = c.collectors[name].Close()
+
+			delete(c.builds, name)
+			c.exclude(ctx, logger, name, collectorExclusion{reason: exclusionReasonMissingRole, detail: buildErr.Error()})
+
+			continue
+		}
+
+		err := fmt.Errorf("error build collector %s: %w", name, buildErr)
+
+		if c.remote {
+			errs = append(errs, err)
 
 			continue
 		}
 
-		errs = append(errs, err)
+		logger.LogAttrs(ctx, slog.LevelWarn, "couldn't initialize collector, retrying on later scrapes",
+			slog.Any("err", err),
+			slog.Time("next_attempt", build.nextAttempt),
+		)
 	}
 
 	return errors.Join(errs...)
 }
 
 // Close To be called by the exporter for collector cleanup.
+// It cancels and waits for the running collections, so the collectors are not closed while an abandoned Collect
+// still uses them.
 func (c *Collection) Close() error {
+	c.flights.cancel()
+	c.flights.wait()
+
 	errs := make([]error, 0, len(c.collectors))
 
 	for _, collector := range c.collectors {
@@ -311,18 +536,50 @@ func (c *Collection) initMI() error {
 }
 
 // WithCollectors To be called by the exporter for collector initialization.
+// Collectors prefixed with "!" are excluded, see resolveCollectors.
 func (c *Collection) WithCollectors(collectors []string) (*Collection, error) {
 	metricCollectors := &Collection{
+		excludedCollectors:          make(map[string]collectorExclusion),
 		miSession:                   c.miSession,
+		remote:                      c.remote,
+		cache:                       c.cache,
+		builds:                      c.builds,
+		toggles:                     c.toggles,
+		flights:                     c.flights,
+		workers:                     c.workers,
+		lastSuccesses:               c.lastSuccesses,
+		lastCollections:             c.lastCollections,
+		startupCosts:                c.startupCosts,
+		maintenance:                 c.maintenance,
+		app:                         c.app,
+		timeouts:                    c.timeouts,
 		startTime:                   c.startTime,
-		concurrencyCh:               c.concurrencyCh,
 		scrapeDurationDesc:          c.scrapeDurationDesc,
 		collectorScrapeDurationDesc: c.collectorScrapeDurationDesc,
 		collectorScrapeSuccessDesc:  c.collectorScrapeSuccessDesc,
 		collectorScrapeTimeoutDesc:  c.collectorScrapeTimeoutDesc,
+		collectorLastSuccessDesc:    c.collectorLastSuccessDesc,
+		collectorExcludedDesc:       c.collectorExcludedDesc,
+		collectorStartupCostDesc:    c.collectorStartupCostDesc,
+		maintenanceModeDesc:         c.maintenanceModeDesc,
 		collectors:                  maps.Clone(c.collectors),
 	}
 
+	collectors, err := c.resolveCollectors(collectors)
+	if err != nil {
+		return nil, err
+	}
+
+	// Excluded collectors are reported as excluded instead of failing the request.
+	collectors = slices.DeleteFunc(collectors, func(name string) bool {
+		exclusion, ok := c.excludedCollectors[name]
+		if ok {
+			metricCollectors.excludedCollectors[name] = exclusion
+		}
+
+		return ok
+	})
+
 	if err := metricCollectors.Enable(collectors); err != nil {
 		return nil, err
 	}
@@ -330,6 +587,56 @@ func (c *Collection) WithCollectors(collectors []string) (*Collection, error) {
 	return metricCollectors, nil
 }
 
+// resolveCollectors expands the negation syntax of collector names. Names prefixed with "!" are removed from the
+// other names, or from all enabled collectors if only negated names are given.
+func (c *Collection) resolveCollectors(collectors []string) ([]string, error) {
+	included := make([]string, 0, len(collectors))
+	negated := make([]string, 0)
+
+	for _, name := range collectors {
+		negatedName, ok := strings.CutPrefix(name, "!")
+		if !ok {
+			included = append(included, name)
+
+			continue
+		}
+
+		// Collectors that aren't enabled may be negated, so a scrape job can be used for hosts with different collectors.
+		// Plugins are always enabled, so they are known if they are in the collection.
+		if _, builtin := BuildersWithFlags[negatedName]; !builtin && !isPlugin(c.collectors[negatedName]) {
+			return nil, fmt.Errorf("unknown collector %s", negatedName)
+		}
+
+		negated = append(negated, negatedName)
+	}
+
+	if len(negated) == 0 {
+		return included, nil
+	}
+
+	if len(included) == 0 {
+		included = slices.AppendSeq(slices.Collect(maps.Keys(c.collectors)), maps.Keys(c.excludedCollectors))
+	}
+
+	return slices.DeleteFunc(included, func(name string) bool {
+		return slices.Contains(negated, name)
+	}), nil
+}
+
+// withFilters returns a copy of the collection that filters the metrics of the given collectors.
+func (c *Collection) withFilters(filters map[string]MetricFilter) (*Collection, error) {
+	for name := range filters {
+		if _, ok := c.collectors[name]; !ok {
+			return nil, fmt.Errorf("filter for collector %s, which is not enabled", name)
+		}
+	}
+
+	metricCollectors := *c
+	metricCollectors.filters = filters
+
+	return &metricCollectors, nil
+}
+
 func (c *Collection) GetStartTime() gotime.Time {
 	return c.startTime
 }
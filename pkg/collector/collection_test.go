@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveCollectors(t *testing.T) {
+	t.Parallel()
+
+	c := New(Map{
+		"cpu":    blockingCollector{},
+		"memory": blockingCollector{},
+		"backup": &execPlugin{},
+	})
+
+	for _, tc := range []struct {
+		name       string
+		collectors []string
+		expected   []string
+		err        bool
+	}{
+		{name: "included", collectors: []string{"cpu", "backup"}, expected: []string{"cpu", "backup"}},
+		{name: "negated builtin", collectors: []string{"!cpu"}, expected: []string{"memory", "backup"}},
+		{name: "negated plugin", collectors: []string{"!backup"}, expected: []string{"cpu", "memory"}},
+		{name: "negated from included", collectors: []string{"cpu", "backup", "!backup"}, expected: []string{"cpu"}},
+		{name: "negated builtin that isn't enabled", collectors: []string{"!service"}, expected: []string{"cpu", "memory", "backup"}},
+		{name: "negated unknown collector", collectors: []string{"!unknown"}, err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			collectors, err := c.resolveCollectors(tc.collectors)
+			if tc.err {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.ElementsMatch(t, tc.expected, collectors)
+		})
+	}
+}
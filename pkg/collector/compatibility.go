@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/prometheus-community/windows_exporter/internal/collector/bits"
+	"github.com/prometheus-community/windows_exporter/internal/collector/container"
+	"github.com/prometheus-community/windows_exporter/internal/collector/dhcp"
+	"github.com/prometheus-community/windows_exporter/internal/collector/gpu"
+	"github.com/prometheus-community/windows_exporter/internal/collector/hns"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ssis"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/pdh"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	exclusionReasonMissingDLL  = "missing_dll"
+	exclusionReasonMissingRole = "missing_role"
+)
+
+// collectorRequiredDLLs lists the system DLLs a collector can't work without.
+// They are missing on Server Core or Nano Server, or if the role or feature is not installed.
+//
+//nolint:gochecknoglobals
+var collectorRequiredDLLs = map[string][]string{
+	bits.Name:      {"qmgr.dll"},
+	container.Name: {"computecore.dll", "vmcompute.dll"},
+	dhcp.Name:      {"dhcpsapi.dll"},
+	gpu.Name:       {"gdi32.dll"},
+	hns.Name:       {"vmcompute.dll"},
+	ssis.Name:      {"odbc32.dll"},
+}
+
+// collectorExclusion records why a collector was excluded at startup.
+type collectorExclusion struct {
+	reason string
+	detail string
+}
+
+// probeCompatibility excludes the enabled collectors whose required DLLs are missing,
+// instead of failing the build or every scrape.
+func (c *Collection) probeCompatibility(ctx context.Context, logger *slog.Logger) {
+	installationType := getInstallationType()
+
+	for name := range c.collectors {
+		for _, dll := range collectorRequiredDLLs[name] {
+			if dllExists(dll) {
+				continue
+			}
+
+			c.exclude(ctx, logger, name, collectorExclusion{reason: exclusionReasonMissingDLL, detail: dll},
+				slog.String("installation_type", installationType),
+			)
+
+			break
+		}
+	}
+}
+
+// isMissingRoleError returns true if the collector failed to build because the role,
+// feature or application it monitors is not installed.
+func isMissingRoleError(err error) bool {
+	return errors.Is(err, registry.ErrNotExist) ||
+		errors.Is(err, pdh.NewPdhError(pdh.CstatusNoObject)) ||
+		errors.Is(err, mi.MI_RESULT_INVALID_NAMESPACE)
+}
+
+func (c *Collection) exclude(ctx context.Context, logger *slog.Logger, name string, exclusion collectorExclusion, attrs ...slog.Attr) {
+	delete(c.collectors, name)
+
+	c.excludedCollectors[name] = exclusion
+
+	logger.LogAttrs(ctx, slog.LevelWarn, "excluding collector "+name+", because it can't work on this system",
+		append([]slog.Attr{
+			slog.String("reason", exclusion.reason),
+			slog.String("detail", exclusion.detail),
+		}, attrs...)...,
+	)
+}
+
+// dllExists checks whether the DLL exists in the system directory without running its initialization code.
+func dllExists(name string) bool {
+	handle, err := windows.LoadLibraryEx(name, 0, windows.LOAD_LIBRARY_AS_DATAFILE|windows.LOAD_LIBRARY_SEARCH_SYSTEM32)
+	if err != nil {
+		return false
+	}
+
+	_ = windows.FreeLibrary(handle)
+
+	return true
+}
+
+// getInstallationType returns the installation type of the operating system, e.g. "Server", "Server Core",
+// "Nano Server" or "Client".
+func getInstallationType() string {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows NT\CurrentVersion`, registry.QUERY_VALUE)
+	if err != nil {
+		return "unknown"
+	}
+
+	defer key.Close()
+
+	installationType, _, err := key.GetStringValue("InstallationType")
+	if err != nil {
+		return "unknown"
+	}
+
+	return installationType
+}
@@ -19,43 +19,88 @@ package collector
 
 import (
 	"github.com/prometheus-community/windows_exporter/internal/collector/ad"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ad_accounts"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ad_replication"
 	"github.com/prometheus-community/windows_exporter/internal/collector/adcs"
 	"github.com/prometheus-community/windows_exporter/internal/collector/adfs"
+	"github.com/prometheus-community/windows_exporter/internal/collector/appx"
+	"github.com/prometheus-community/windows_exporter/internal/collector/autorun"
+	"github.com/prometheus-community/windows_exporter/internal/collector/bits"
 	"github.com/prometheus-community/windows_exporter/internal/collector/cache"
+	"github.com/prometheus-community/windows_exporter/internal/collector/cert_enrollment"
+	"github.com/prometheus-community/windows_exporter/internal/collector/certificates"
+	"github.com/prometheus-community/windows_exporter/internal/collector/citrix_vda"
+	"github.com/prometheus-community/windows_exporter/internal/collector/cloud"
 	"github.com/prometheus-community/windows_exporter/internal/collector/container"
 	"github.com/prometheus-community/windows_exporter/internal/collector/cpu"
 	"github.com/prometheus-community/windows_exporter/internal/collector/cpu_info"
+	"github.com/prometheus-community/windows_exporter/internal/collector/dc_advertisement"
+	"github.com/prometheus-community/windows_exporter/internal/collector/defender"
+	"github.com/prometheus-community/windows_exporter/internal/collector/delivery_optimization"
 	"github.com/prometheus-community/windows_exporter/internal/collector/dfsr"
 	"github.com/prometheus-community/windows_exporter/internal/collector/dhcp"
+	"github.com/prometheus-community/windows_exporter/internal/collector/disk_cleanup"
 	"github.com/prometheus-community/windows_exporter/internal/collector/diskdrive"
 	"github.com/prometheus-community/windows_exporter/internal/collector/dns"
+	"github.com/prometheus-community/windows_exporter/internal/collector/dns_client"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ephemeral_ports"
+	"github.com/prometheus-community/windows_exporter/internal/collector/eventlog"
 	"github.com/prometheus-community/windows_exporter/internal/collector/exchange"
 	"github.com/prometheus-community/windows_exporter/internal/collector/filetime"
+	"github.com/prometheus-community/windows_exporter/internal/collector/firewall"
 	"github.com/prometheus-community/windows_exporter/internal/collector/fsrmquota"
 	"github.com/prometheus-community/windows_exporter/internal/collector/gpu"
+	"github.com/prometheus-community/windows_exporter/internal/collector/hello_for_business"
+	"github.com/prometheus-community/windows_exporter/internal/collector/hns"
+	"github.com/prometheus-community/windows_exporter/internal/collector/host"
+	"github.com/prometheus-community/windows_exporter/internal/collector/httpsys"
 	"github.com/prometheus-community/windows_exporter/internal/collector/hyperv"
+	"github.com/prometheus-community/windows_exporter/internal/collector/icmp"
 	"github.com/prometheus-community/windows_exporter/internal/collector/iis"
+	"github.com/prometheus-community/windows_exporter/internal/collector/image_load"
+	"github.com/prometheus-community/windows_exporter/internal/collector/job_object"
+	"github.com/prometheus-community/windows_exporter/internal/collector/kubernetes_node"
+	"github.com/prometheus-community/windows_exporter/internal/collector/laps"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ldap_client"
 	"github.com/prometheus-community/windows_exporter/internal/collector/license"
 	"github.com/prometheus-community/windows_exporter/internal/collector/logical_disk"
+	"github.com/prometheus-community/windows_exporter/internal/collector/lsa"
+	"github.com/prometheus-community/windows_exporter/internal/collector/mdm"
 	"github.com/prometheus-community/windows_exporter/internal/collector/memory"
 	"github.com/prometheus-community/windows_exporter/internal/collector/mscluster"
 	"github.com/prometheus-community/windows_exporter/internal/collector/msmq"
 	"github.com/prometheus-community/windows_exporter/internal/collector/mssql"
 	"github.com/prometheus-community/windows_exporter/internal/collector/net"
 	"github.com/prometheus-community/windows_exporter/internal/collector/netframework"
+	"github.com/prometheus-community/windows_exporter/internal/collector/nlb"
 	"github.com/prometheus-community/windows_exporter/internal/collector/nps"
+	"github.com/prometheus-community/windows_exporter/internal/collector/nvme"
+	"github.com/prometheus-community/windows_exporter/internal/collector/onedrive"
 	"github.com/prometheus-community/windows_exporter/internal/collector/os"
+	"github.com/prometheus-community/windows_exporter/internal/collector/outlook_data"
 	"github.com/prometheus-community/windows_exporter/internal/collector/pagefile"
+	"github.com/prometheus-community/windows_exporter/internal/collector/perflib"
 	"github.com/prometheus-community/windows_exporter/internal/collector/performancecounter"
 	"github.com/prometheus-community/windows_exporter/internal/collector/physical_disk"
 	"github.com/prometheus-community/windows_exporter/internal/collector/printer"
+	"github.com/prometheus-community/windows_exporter/internal/collector/probe"
 	"github.com/prometheus-community/windows_exporter/internal/collector/process"
+	"github.com/prometheus-community/windows_exporter/internal/collector/rds_licensing"
 	"github.com/prometheus-community/windows_exporter/internal/collector/remote_fx"
+	"github.com/prometheus-community/windows_exporter/internal/collector/schannel"
 	"github.com/prometheus-community/windows_exporter/internal/collector/scheduled_task"
 	"github.com/prometheus-community/windows_exporter/internal/collector/service"
+	"github.com/prometheus-community/windows_exporter/internal/collector/service_account"
+	"github.com/prometheus-community/windows_exporter/internal/collector/service_slo"
 	"github.com/prometheus-community/windows_exporter/internal/collector/smb"
+	"github.com/prometheus-community/windows_exporter/internal/collector/smb_security"
+	"github.com/prometheus-community/windows_exporter/internal/collector/smb_witness"
 	"github.com/prometheus-community/windows_exporter/internal/collector/smbclient"
 	"github.com/prometheus-community/windows_exporter/internal/collector/smtp"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ssas"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ssis"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ssrs"
+	"github.com/prometheus-community/windows_exporter/internal/collector/storage_hotplug"
 	"github.com/prometheus-community/windows_exporter/internal/collector/system"
 	"github.com/prometheus-community/windows_exporter/internal/collector/tcp"
 	"github.com/prometheus-community/windows_exporter/internal/collector/terminal_services"
@@ -64,57 +109,118 @@ import (
 	"github.com/prometheus-community/windows_exporter/internal/collector/time"
 	"github.com/prometheus-community/windows_exporter/internal/collector/udp"
 	"github.com/prometheus-community/windows_exporter/internal/collector/update"
+	"github.com/prometheus-community/windows_exporter/internal/collector/update_policy"
+	"github.com/prometheus-community/windows_exporter/internal/collector/user_profile"
 	"github.com/prometheus-community/windows_exporter/internal/collector/vmware"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wdac"
+	"github.com/prometheus-community/windows_exporter/internal/collector/web_application_proxy"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wef"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wins"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wmi_health"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wmi_provider_host"
 )
 
 type Config struct {
-	AD                 ad.Config                 `yaml:"ad"`
-	ADCS               adcs.Config               `yaml:"adcs"`
-	ADFS               adfs.Config               `yaml:"adfs"`
-	Cache              cache.Config              `yaml:"cache"`
-	Container          container.Config          `yaml:"container"`
-	CPU                cpu.Config                `yaml:"cpu"`
-	CPUInfo            cpu_info.Config           `yaml:"cpu_info"`
-	DFSR               dfsr.Config               `yaml:"dfsr"`
-	Dhcp               dhcp.Config               `yaml:"dhcp"`
-	DiskDrive          diskdrive.Config          `yaml:"diskdrive"`
-	DNS                dns.Config                `yaml:"dns"`
-	Exchange           exchange.Config           `yaml:"exchange"`
-	Filetime           filetime.Config           `yaml:"filetime"`
-	Fsrmquota          fsrmquota.Config          `yaml:"fsrmquota"`
-	GPU                gpu.Config                `yaml:"gpu"`
-	HyperV             hyperv.Config             `yaml:"hyperv"`
-	IIS                iis.Config                `yaml:"iis"`
-	License            license.Config            `yaml:"license"`
-	LogicalDisk        logical_disk.Config       `yaml:"logical_disk"`
-	Memory             memory.Config             `yaml:"memory"`
-	MSCluster          mscluster.Config          `yaml:"mscluster"`
-	Msmq               msmq.Config               `yaml:"msmq"`
-	Mssql              mssql.Config              `yaml:"mssql"`
-	Net                net.Config                `yaml:"net"`
-	NetFramework       netframework.Config       `yaml:"netframework"`
-	Nps                nps.Config                `yaml:"nps"`
-	OS                 os.Config                 `yaml:"os"`
-	Paging             pagefile.Config           `yaml:"paging"`
-	PerformanceCounter performancecounter.Config `yaml:"performancecounter"`
-	PhysicalDisk       physical_disk.Config      `yaml:"physical_disk"`
-	Printer            printer.Config            `yaml:"printer"`
-	Process            process.Config            `yaml:"process"`
-	RemoteFx           remote_fx.Config          `yaml:"remote_fx"`
-	ScheduledTask      scheduled_task.Config     `yaml:"scheduled_task"`
-	Service            service.Config            `yaml:"service"`
-	SMB                smb.Config                `yaml:"smb"`
-	SMBClient          smbclient.Config          `yaml:"smb_client"`
-	SMTP               smtp.Config               `yaml:"smtp"`
-	System             system.Config             `yaml:"system"`
-	TCP                tcp.Config                `yaml:"tcp"`
-	TerminalServices   terminal_services.Config  `yaml:"terminal_services"`
-	Textfile           textfile.Config           `yaml:"textfile"`
-	ThermalZone        thermalzone.Config        `yaml:"thermalzone"`
-	Time               time.Config               `yaml:"time"`
-	UDP                udp.Config                `yaml:"udp"`
-	Update             update.Config             `yaml:"update"`
-	Vmware             vmware.Config             `yaml:"vmware"`
+	AD                   ad.Config                    `yaml:"ad"`
+	AdAccounts           ad_accounts.Config           `yaml:"ad_accounts"`
+	ADReplication        ad_replication.Config        `yaml:"ad_replication"`
+	ADCS                 adcs.Config                  `yaml:"adcs"`
+	ADFS                 adfs.Config                  `yaml:"adfs"`
+	AppX                 appx.Config                  `yaml:"appx"`
+	Autorun              autorun.Config               `yaml:"autorun"`
+	BITS                 bits.Config                  `yaml:"bits"`
+	Cache                cache.Config                 `yaml:"cache"`
+	CertEnrollment       cert_enrollment.Config       `yaml:"cert_enrollment"`
+	Certificates         certificates.Config          `yaml:"certificates"`
+	CitrixVDA            citrix_vda.Config            `yaml:"citrix_vda"`
+	Cloud                cloud.Config                 `yaml:"cloud"`
+	Container            container.Config             `yaml:"container"`
+	CPU                  cpu.Config                   `yaml:"cpu"`
+	CPUInfo              cpu_info.Config              `yaml:"cpu_info"`
+	DcAdvertisement      dc_advertisement.Config      `yaml:"dc_advertisement"`
+	Defender             defender.Config              `yaml:"defender"`
+	DeliveryOptimization delivery_optimization.Config `yaml:"delivery_optimization"`
+	DFSR                 dfsr.Config                  `yaml:"dfsr"`
+	Dhcp                 dhcp.Config                  `yaml:"dhcp"`
+	DiskCleanup          disk_cleanup.Config          `yaml:"disk_cleanup"`
+	DiskDrive            diskdrive.Config             `yaml:"diskdrive"`
+	DNS                  dns.Config                   `yaml:"dns"`
+	DNSClient            dns_client.Config            `yaml:"dns_client"`
+	EphemeralPorts       ephemeral_ports.Config       `yaml:"ephemeral_ports"`
+	EventLog             eventlog.Config              `yaml:"eventlog"`
+	Exchange             exchange.Config              `yaml:"exchange"`
+	Filetime             filetime.Config              `yaml:"filetime"`
+	Firewall             firewall.Config              `yaml:"firewall"`
+	Fsrmquota            fsrmquota.Config             `yaml:"fsrmquota"`
+	GPU                  gpu.Config                   `yaml:"gpu"`
+	HelloForBusiness     hello_for_business.Config    `yaml:"hello_for_business"`
+	HNS                  hns.Config                   `yaml:"hns"`
+	Host                 host.Config                  `yaml:"host"`
+	HTTPSys              httpsys.Config               `yaml:"httpsys"`
+	HyperV               hyperv.Config                `yaml:"hyperv"`
+	ICMP                 icmp.Config                  `yaml:"icmp"`
+	IIS                  iis.Config                   `yaml:"iis"`
+	ImageLoad            image_load.Config            `yaml:"image_load"`
+	JobObject            job_object.Config            `yaml:"job_object"`
+	KubernetesNode       kubernetes_node.Config       `yaml:"kubernetes_node"`
+	LAPS                 laps.Config                  `yaml:"laps"`
+	LDAPClient           ldap_client.Config           `yaml:"ldap_client"`
+	License              license.Config               `yaml:"license"`
+	LogicalDisk          logical_disk.Config          `yaml:"logical_disk"`
+	LSA                  lsa.Config                   `yaml:"lsa"`
+	MDM                  mdm.Config                   `yaml:"mdm"`
+	Memory               memory.Config                `yaml:"memory"`
+	MSCluster            mscluster.Config             `yaml:"mscluster"`
+	Msmq                 msmq.Config                  `yaml:"msmq"`
+	Mssql                mssql.Config                 `yaml:"mssql"`
+	Net                  net.Config                   `yaml:"net"`
+	NetFramework         netframework.Config          `yaml:"netframework"`
+	NLB                  nlb.Config                   `yaml:"nlb"`
+	Nps                  nps.Config                   `yaml:"nps"`
+	Nvme                 nvme.Config                  `yaml:"nvme"`
+	OneDrive             onedrive.Config              `yaml:"onedrive"`
+	OS                   os.Config                    `yaml:"os"`
+	OutlookData          outlook_data.Config          `yaml:"outlook_data"`
+	Paging               pagefile.Config              `yaml:"paging"`
+	Perflib              perflib.Config               `yaml:"perflib"`
+	PerformanceCounter   performancecounter.Config    `yaml:"performancecounter"`
+	PhysicalDisk         physical_disk.Config         `yaml:"physical_disk"`
+	Printer              printer.Config               `yaml:"printer"`
+	Probe                probe.Config                 `yaml:"probe"`
+	Process              process.Config               `yaml:"process"`
+	RDSLicensing         rds_licensing.Config         `yaml:"rds_licensing"`
+	RemoteFx             remote_fx.Config             `yaml:"remote_fx"`
+	Schannel             schannel.Config              `yaml:"schannel"`
+	ScheduledTask        scheduled_task.Config        `yaml:"scheduled_task"`
+	Service              service.Config               `yaml:"service"`
+	ServiceAccount       service_account.Config       `yaml:"service_account"`
+	ServiceSLO           service_slo.Config           `yaml:"service_slo"`
+	SMB                  smb.Config                   `yaml:"smb"`
+	SMBSecurity          smb_security.Config          `yaml:"smb_security"`
+	SMBWitness           smb_witness.Config           `yaml:"smb_witness"`
+	SMBClient            smbclient.Config             `yaml:"smb_client"`
+	SMTP                 smtp.Config                  `yaml:"smtp"`
+	SSAS                 ssas.Config                  `yaml:"ssas"`
+	SSIS                 ssis.Config                  `yaml:"ssis"`
+	SSRS                 ssrs.Config                  `yaml:"ssrs"`
+	StorageHotplug       storage_hotplug.Config       `yaml:"storage_hotplug"`
+	System               system.Config                `yaml:"system"`
+	TCP                  tcp.Config                   `yaml:"tcp"`
+	TerminalServices     terminal_services.Config     `yaml:"terminal_services"`
+	Textfile             textfile.Config              `yaml:"textfile"`
+	ThermalZone          thermalzone.Config           `yaml:"thermalzone"`
+	Time                 time.Config                  `yaml:"time"`
+	UDP                  udp.Config                   `yaml:"udp"`
+	Update               update.Config                `yaml:"update"`
+	UpdatePolicy         update_policy.Config         `yaml:"update_policy"`
+	UserProfile          user_profile.Config          `yaml:"user_profile"`
+	Vmware               vmware.Config                `yaml:"vmware"`
+	WDAC                 wdac.Config                  `yaml:"wdac"`
+	WebApplicationProxy  web_application_proxy.Config `yaml:"web_application_proxy"`
+	WEF                  wef.Config                   `yaml:"wef"`
+	WINS                 wins.Config                  `yaml:"wins"`
+	WMIHealth            wmi_health.Config            `yaml:"wmi_health"`
+	WmiProviderHost      wmi_provider_host.Config     `yaml:"wmi_provider_host"`
 }
 
 // ConfigDefaults Is an interface to be used by the external libraries. It holds all ConfigDefaults form all collectors
@@ -122,51 +228,104 @@ type Config struct {
 //nolint:gochecknoglobals
 //goland:noinspection GoUnusedGlobalVariable
 var ConfigDefaults = Config{
-	AD:                 ad.ConfigDefaults,
-	ADCS:               adcs.ConfigDefaults,
-	ADFS:               adfs.ConfigDefaults,
-	Cache:              cache.ConfigDefaults,
-	Container:          container.ConfigDefaults,
-	CPU:                cpu.ConfigDefaults,
-	CPUInfo:            cpu_info.ConfigDefaults,
-	DFSR:               dfsr.ConfigDefaults,
-	Dhcp:               dhcp.ConfigDefaults,
-	DiskDrive:          diskdrive.ConfigDefaults,
-	DNS:                dns.ConfigDefaults,
-	Exchange:           exchange.ConfigDefaults,
-	Filetime:           filetime.ConfigDefaults,
-	Fsrmquota:          fsrmquota.ConfigDefaults,
-	GPU:                gpu.ConfigDefaults,
-	HyperV:             hyperv.ConfigDefaults,
-	IIS:                iis.ConfigDefaults,
-	License:            license.ConfigDefaults,
-	LogicalDisk:        logical_disk.ConfigDefaults,
-	Memory:             memory.ConfigDefaults,
-	MSCluster:          mscluster.ConfigDefaults,
-	Msmq:               msmq.ConfigDefaults,
-	Mssql:              mssql.ConfigDefaults,
-	Net:                net.ConfigDefaults,
-	NetFramework:       netframework.ConfigDefaults,
-	Nps:                nps.ConfigDefaults,
-	OS:                 os.ConfigDefaults,
-	Paging:             pagefile.ConfigDefaults,
-	PerformanceCounter: performancecounter.ConfigDefaults,
-	PhysicalDisk:       physical_disk.ConfigDefaults,
-	Printer:            printer.ConfigDefaults,
-	Process:            process.ConfigDefaults,
-	RemoteFx:           remote_fx.ConfigDefaults,
-	ScheduledTask:      scheduled_task.ConfigDefaults,
-	Service:            service.ConfigDefaults,
-	SMB:                smb.ConfigDefaults,
-	SMBClient:          smbclient.ConfigDefaults,
-	SMTP:               smtp.ConfigDefaults,
-	System:             system.ConfigDefaults,
-	TCP:                tcp.ConfigDefaults,
-	TerminalServices:   terminal_services.ConfigDefaults,
-	Textfile:           textfile.ConfigDefaults,
-	ThermalZone:        thermalzone.ConfigDefaults,
-	Time:               time.ConfigDefaults,
-	UDP:                udp.ConfigDefaults,
-	Update:             update.ConfigDefaults,
-	Vmware:             vmware.ConfigDefaults,
+	AD:                   ad.ConfigDefaults,
+	AdAccounts:           ad_accounts.ConfigDefaults,
+	ADReplication:        ad_replication.ConfigDefaults,
+	ADCS:                 adcs.ConfigDefaults,
+	ADFS:                 adfs.ConfigDefaults,
+	AppX:                 appx.ConfigDefaults,
+	Autorun:              autorun.ConfigDefaults,
+	BITS:                 bits.ConfigDefaults,
+	Cache:                cache.ConfigDefaults,
+	CertEnrollment:       cert_enrollment.ConfigDefaults,
+	Certificates:         certificates.ConfigDefaults,
+	CitrixVDA:            citrix_vda.ConfigDefaults,
+	Cloud:                cloud.ConfigDefaults,
+	Container:            container.ConfigDefaults,
+	CPU:                  cpu.ConfigDefaults,
+	CPUInfo:              cpu_info.ConfigDefaults,
+	DcAdvertisement:      dc_advertisement.ConfigDefaults,
+	Defender:             defender.ConfigDefaults,
+	DeliveryOptimization: delivery_optimization.ConfigDefaults,
+	DFSR:                 dfsr.ConfigDefaults,
+	Dhcp:                 dhcp.ConfigDefaults,
+	DiskCleanup:          disk_cleanup.ConfigDefaults,
+	DiskDrive:            diskdrive.ConfigDefaults,
+	DNS:                  dns.ConfigDefaults,
+	DNSClient:            dns_client.ConfigDefaults,
+	EphemeralPorts:       ephemeral_ports.ConfigDefaults,
+	EventLog:             eventlog.ConfigDefaults,
+	Exchange:             exchange.ConfigDefaults,
+	Filetime:             filetime.ConfigDefaults,
+	Firewall:             firewall.ConfigDefaults,
+	Fsrmquota:            fsrmquota.ConfigDefaults,
+	GPU:                  gpu.ConfigDefaults,
+	HelloForBusiness:     hello_for_business.ConfigDefaults,
+	HNS:                  hns.ConfigDefaults,
+	Host:                 host.ConfigDefaults,
+	HTTPSys:              httpsys.ConfigDefaults,
+	HyperV:               hyperv.ConfigDefaults,
+	ICMP:                 icmp.ConfigDefaults,
+	IIS:                  iis.ConfigDefaults,
+	ImageLoad:            image_load.ConfigDefaults,
+	JobObject:            job_object.ConfigDefaults,
+	KubernetesNode:       kubernetes_node.ConfigDefaults,
+	LAPS:                 laps.ConfigDefaults,
+	LDAPClient:           ldap_client.ConfigDefaults,
+	License:              license.ConfigDefaults,
+	LogicalDisk:          logical_disk.ConfigDefaults,
+	LSA:                  lsa.ConfigDefaults,
+	MDM:                  mdm.ConfigDefaults,
+	Memory:               memory.ConfigDefaults,
+	MSCluster:            mscluster.ConfigDefaults,
+	Msmq:                 msmq.ConfigDefaults,
+	Mssql:                mssql.ConfigDefaults,
+	Net:                  net.ConfigDefaults,
+	NetFramework:         netframework.ConfigDefaults,
+	NLB:                  nlb.ConfigDefaults,
+	Nps:                  nps.ConfigDefaults,
+	Nvme:                 nvme.ConfigDefaults,
+	OneDrive:             onedrive.ConfigDefaults,
+	OS:                   os.ConfigDefaults,
+	OutlookData:          outlook_data.ConfigDefaults,
+	Paging:               pagefile.ConfigDefaults,
+	Perflib:              perflib.ConfigDefaults,
+	PerformanceCounter:   performancecounter.ConfigDefaults,
+	PhysicalDisk:         physical_disk.ConfigDefaults,
+	Printer:              printer.ConfigDefaults,
+	Probe:                probe.ConfigDefaults,
+	Process:              process.ConfigDefaults,
+	RDSLicensing:         rds_licensing.ConfigDefaults,
+	RemoteFx:             remote_fx.ConfigDefaults,
+	Schannel:             schannel.ConfigDefaults,
+	ScheduledTask:        scheduled_task.ConfigDefaults,
+	Service:              service.ConfigDefaults,
+	ServiceAccount:       service_account.ConfigDefaults,
+	ServiceSLO:           service_slo.ConfigDefaults,
+	SMB:                  smb.ConfigDefaults,
+	SMBSecurity:          smb_security.ConfigDefaults,
+	SMBWitness:           smb_witness.ConfigDefaults,
+	SMBClient:            smbclient.ConfigDefaults,
+	SMTP:                 smtp.ConfigDefaults,
+	SSAS:                 ssas.ConfigDefaults,
+	SSIS:                 ssis.ConfigDefaults,
+	SSRS:                 ssrs.ConfigDefaults,
+	StorageHotplug:       storage_hotplug.ConfigDefaults,
+	System:               system.ConfigDefaults,
+	TCP:                  tcp.ConfigDefaults,
+	TerminalServices:     terminal_services.ConfigDefaults,
+	Textfile:             textfile.ConfigDefaults,
+	ThermalZone:          thermalzone.ConfigDefaults,
+	Time:                 time.ConfigDefaults,
+	UDP:                  udp.ConfigDefaults,
+	Update:               update.ConfigDefaults,
+	UpdatePolicy:         update_policy.ConfigDefaults,
+	UserProfile:          user_profile.ConfigDefaults,
+	Vmware:               vmware.ConfigDefaults,
+	WDAC:                 wdac.ConfigDefaults,
+	WebApplicationProxy:  web_application_proxy.ConfigDefaults,
+	WEF:                  wef.ConfigDefaults,
+	WINS:                 wins.ConfigDefaults,
+	WMIHealth:            wmi_health.ConfigDefaults,
+	WmiProviderHost:      wmi_provider_host.ConfigDefaults,
 }
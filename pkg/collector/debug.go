@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/debugdump"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrCollectorBusy is returned by Dump if the collector is collected by a scrape that waits for a worker.
+var ErrCollectorBusy = errors.New("collector is busy")
+
+// LastCollection is the result of the last collection of a collector.
+type LastCollection struct {
+	Time     time.Time
+	Duration time.Duration
+	Success  bool
+	// Error is the error of the collection, which may be set for successful collections with warnings.
+	Error   string
+	Metrics int
+}
+
+// CollectorStatus describes an enabled collector, see Status.
+type CollectorStatus struct {
+	Name string
+	// Disabled is set if the collector was disabled at runtime, see SetDisabled.
+	Disabled bool
+	// Excluded is the reason why the collector was excluded at startup.
+	Excluded string
+	// InitError is the reason why the collector is not initialized.
+	InitError string
+	// Flags are the collector.<name>.* flags by name. They are only known for collections created by NewWithFlags.
+	Flags          map[string]string
+	LastCollection *LastCollection
+}
+
+// Dump is the result of a collection of a single collector, see Collection.Dump.
+type Dump struct {
+	Duration time.Duration
+	Err      error
+	// Records are the raw values of the PDH and WMI queries of the collection.
+	Records []debugdump.Record
+	Metrics []prometheus.Metric
+}
+
+// Status returns the enabled and the excluded collectors, sorted by name.
+func (c *Collection) Status() []CollectorStatus {
+	names := slices.Sorted(maps.Keys(c.collectors))
+	for name := range c.excludedCollectors {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	statuses := make([]CollectorStatus, 0, len(names))
+
+	for _, name := range names {
+		status := CollectorStatus{
+			Name:     name,
+			Disabled: c.Disabled(name),
+			Flags:    c.collectorFlags(name),
+		}
+
+		if exclusion, ok := c.excludedCollectors[name]; ok {
+			status.Excluded = exclusion.reason + ": " + exclusion.detail
+		}
+
+		if build, ok := c.builds[name]; ok {
+			if err := build.status(); err != nil {
+				status.InitError = err.Error()
+			}
+		}
+
+		if collection, ok := c.lastCollections.get(name); ok {
+			status.LastCollection = &collection
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// collectorFlags returns the values of the collector.<name>.* flags by name.
+func (c *Collection) collectorFlags(name string) map[string]string {
+	if c.app == nil {
+		return nil
+	}
+
+	prefix := "collector." + name + "."
+	flags := make(map[string]string)
+
+	for _, flag := range c.app.Model().Flags {
+		if strings.HasPrefix(flag.Name, prefix) {
+			flags[flag.Name] = flag.String()
+		}
+	}
+
+	return flags
+}
+
+// Dump collects a single enabled collector and records the raw values of its PDH and WMI queries.
+// The cache is bypassed. While the collector runs, no other collector is collected, so the records only contain
+// the queries of the collector. Scrapes wait for the dump or time out.
+func (c *Collection) Dump(ctx context.Context, logger *slog.Logger, name string) (*Dump, error) {
+	collector, ok := c.activeCollectors()[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCollectorNotEnabled, name)
+	}
+
+	if err := c.builds[name].ready(logger, name, collector, c.miSession); err != nil {
+		return nil, fmt.Errorf("collector %s is not initialized: %w", name, err)
+	}
+
+	// Taking all workers waits for the running collectors and keeps other collectors from starting.
+	acquired := 0
+
+	defer func() {
+		for range acquired {
+			<-c.workers
+		}
+	}()
+
+	for range cap(c.workers) {
+		select {
+		case c.workers <- struct{}{}:
+			acquired++
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to wait for the running collectors: %w", ctx.Err())
+		}
+	}
+
+	f, leader := c.flights.join(name)
+	if !leader {
+		return nil, fmt.Errorf("%w: %s", ErrCollectorBusy, name)
+	}
+
+	recorder := debugdump.Start()
+	if recorder == nil {
+		c.flights.finish(name, f, cachedResult{statusCode: pending})
+
+		return nil, errors.New("another dump is running")
+	}
+
+	metricsCh := make(chan prometheus.Metric, 1000)
+	collectedCh := make(chan []prometheus.Metric)
+
+	go func() {
+		var collected []prometheus.Metric
+		for m := range metricsCh {
+			collected = append(collected, m)
+		}
+
+		collectedCh <- collected
+	}()
+
+	t := time.Now()
+	err := runCollector(ctx, name, collector, metricsCh)
+
+	dump := &Dump{
+		Metrics: <-collectedCh,
+		Err:     err,
+	}
+	dump.Duration = time.Since(t)
+	dump.Records = recorder.Stop()
+
+	// Scrapes that attached to the dump receive its metrics.
+	result := cachedResult{metrics: dump.Metrics, duration: dump.Duration, statusCode: success}
+	if err != nil {
+		result.statusCode = failed
+	}
+
+	c.flights.finish(name, f, result)
+
+	return dump, nil
+}
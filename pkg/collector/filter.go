@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package collector
+
+import (
+	"regexp"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// MetricFilter filters the metrics of a collector by their label values for a single scrape.
+// Metrics without a filtered label are kept.
+type MetricFilter struct {
+	// Include keeps only metrics whose label values match the expression of the label name.
+	Include map[string]*regexp.Regexp
+	// Exclude drops metrics whose label values match the expression of the label name.
+	Exclude map[string]*regexp.Regexp
+}
+
+// Match returns true if the metric passes the filter.
+func (f MetricFilter) Match(m prometheus.Metric) bool {
+	var metric dto.Metric
+	if err := m.Write(&metric); err != nil {
+		return true
+	}
+
+	for _, label := range metric.GetLabel() {
+		if re, ok := f.Exclude[label.GetName()]; ok && re.MatchString(label.GetValue()) {
+			return false
+		}
+
+		if re, ok := f.Include[label.GetName()]; ok && !re.MatchString(label.GetValue()) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -20,7 +20,6 @@ package collector
 import (
 	"fmt"
 	"log/slog"
-	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -29,21 +28,43 @@ import (
 // Interface guard.
 var _ prometheus.Collector = (*Handler)(nil)
 
-// We are expose metrics directly from the memory region of the Win32 API.
-// We should not allow more than one request at a time.
-//
-//nolint:gochecknoglobals
-var concurrencyMu sync.Mutex
-
 // Handler implements [prometheus.Collector] for a set of Windows Collection.
 type Handler struct {
 	maxScrapeDuration time.Duration
 	logger            *slog.Logger
 	collection        *Collection
+	recordSpan        SpanRecorder
+}
+
+// CollectorSpan is the collection of a collector within a scrape, e.g. to trace slow scrapes.
+type CollectorSpan struct {
+	Collector string
+	Start     time.Time
+	End       time.Time
+	// Backend is how the metrics were obtained: "collect" if the collector ran, "shared" if the scrape attached to
+	// the running collection of a concurrent scrape and "cache" if the result was served from the cache.
+	// It is empty if the collector is not initialized.
+	Backend string
+	// Status is "success", "failed" or "timeout".
+	Status  string
+	Metrics int
+	// Err is the error of the collector, if any. Collectors that succeed with warnings have a status of "success"
+	// next to an error.
+	Err error
 }
 
+// SpanRecorder is called with the span of each collector after the collector finished or timed out.
+// It is called concurrently by the collectors of a scrape.
+type SpanRecorder func(span CollectorSpan)
+
 // NewHandler returns a new Handler that implements a [prometheus.Collector] for the given metrics Collection.
 func (c *Collection) NewHandler(maxScrapeDuration time.Duration, logger *slog.Logger, collectors []string) (*Handler, error) {
+	return c.NewHandlerWithFilters(maxScrapeDuration, logger, collectors, nil)
+}
+
+// NewHandlerWithFilters returns a new Handler like NewHandler, which additionally filters the metrics of
+// the collectors by label values. The keys of filters are collector names.
+func (c *Collection) NewHandlerWithFilters(maxScrapeDuration time.Duration, logger *slog.Logger, collectors []string, filters map[string]MetricFilter) (*Handler, error) {
 	collection := c
 
 	if len(collectors) != 0 {
@@ -55,6 +76,15 @@ func (c *Collection) NewHandler(maxScrapeDuration time.Duration, logger *slog.Lo
 		}
 	}
 
+	if len(filters) != 0 {
+		var err error
+
+		collection, err = collection.withFilters(filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create handler with filters: %w", err)
+		}
+	}
+
 	return &Handler{
 		maxScrapeDuration: maxScrapeDuration,
 		collection:        collection,
@@ -64,10 +94,13 @@ func (c *Collection) NewHandler(maxScrapeDuration time.Duration, logger *slog.Lo
 
 func (p *Handler) Describe(_ chan<- *prometheus.Desc) {}
 
+// SetSpanRecorder sets the function that records the span of each collector on Collect. Nil disables recording.
+func (p *Handler) SetSpanRecorder(recordSpan SpanRecorder) {
+	p.recordSpan = recordSpan
+}
+
 // Collect sends the collected metrics from each of the Collection to
-// prometheus.
+// prometheus. Concurrent scrapes attach to the running collections of their collectors, see collectorFlights.
 func (p *Handler) Collect(ch chan<- prometheus.Metric) {
-	concurrencyMu.Lock()
-	p.collection.collectAll(ch, p.logger, p.maxScrapeDuration)
-	concurrencyMu.Unlock()
+	p.collection.collectAll(ch, p.logger, p.maxScrapeDuration, p.recordSpan)
 }
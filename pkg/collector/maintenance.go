@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package collector
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MaintenanceStatus is the maintenance mode of the host, see SetMaintenance.
+type MaintenanceStatus struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason,omitempty"`
+	// Since is the time the maintenance mode was enabled. It is zero if the maintenance mode is disabled.
+	Since time.Time `json:"since,omitzero"`
+	// Suppressed are the collectors that are skipped while the maintenance mode is enabled.
+	Suppressed []string `json:"suppressed"`
+}
+
+// maintenance is the maintenance mode of the host. It is shared by all copies of a Collection.
+type maintenance struct {
+	mu     sync.RWMutex
+	status MaintenanceStatus
+}
+
+// SetMaintenance enables or disables the maintenance mode of the host, e.g. during a patch window.
+// The mode is exported as windows_host_maintenance_mode, so alerts can be silenced or routed by it.
+// While the mode is enabled, the collectors of SetMaintenanceSuppressed are skipped by all scrapes.
+func (c *Collection) SetMaintenance(enabled bool, reason string) {
+	c.maintenance.mu.Lock()
+	defer c.maintenance.mu.Unlock()
+
+	if !enabled {
+		c.maintenance.status.Enabled = false
+		c.maintenance.status.Reason = ""
+		c.maintenance.status.Since = time.Time{}
+
+		return
+	}
+
+	if !c.maintenance.status.Enabled {
+		c.maintenance.status.Since = time.Now()
+	}
+
+	c.maintenance.status.Enabled = true
+	c.maintenance.status.Reason = reason
+}
+
+// SetMaintenanceSuppressed sets the collectors that are skipped while the maintenance mode is enabled.
+func (c *Collection) SetMaintenanceSuppressed(collectors []string) error {
+	for _, name := range collectors {
+		if _, ok := BuildersWithFlags[name]; !ok {
+			return fmt.Errorf("unknown collector %s", name)
+		}
+	}
+
+	c.maintenance.mu.Lock()
+	defer c.maintenance.mu.Unlock()
+
+	c.maintenance.status.Suppressed = slices.Clone(collectors)
+
+	return nil
+}
+
+// KeepMaintenance takes over the maintenance mode of previous, e.g. after a reload of the configuration,
+// so a reload doesn't end a maintenance window. The suppressed collectors of c are kept.
+func (c *Collection) KeepMaintenance(previous *Collection) {
+	status := previous.Maintenance()
+
+	c.maintenance.mu.Lock()
+	defer c.maintenance.mu.Unlock()
+
+	c.maintenance.status.Enabled = status.Enabled
+	c.maintenance.status.Reason = status.Reason
+	c.maintenance.status.Since = status.Since
+}
+
+// Maintenance returns the maintenance mode of the host, see SetMaintenance.
+func (c *Collection) Maintenance() MaintenanceStatus {
+	c.maintenance.mu.RLock()
+	defer c.maintenance.mu.RUnlock()
+
+	status := c.maintenance.status
+	status.Suppressed = slices.Clone(status.Suppressed)
+
+	return status
+}
+
+// maintenanceSuppressed returns true if the collector is skipped because the maintenance mode is enabled.
+func (c *Collection) maintenanceSuppressed(name string) bool {
+	c.maintenance.mu.RLock()
+	defer c.maintenance.mu.RUnlock()
+
+	return c.maintenance.status.Enabled && slices.Contains(c.maintenance.status.Suppressed, name)
+}
+
+// maintenanceValue returns 1 if the maintenance mode is enabled, 0 otherwise.
+func (c *Collection) maintenanceValue() float64 {
+	c.maintenance.mu.RLock()
+	defer c.maintenance.mu.RUnlock()
+
+	if c.maintenance.status.Enabled {
+		return 1
+	}
+
+	return 0
+}
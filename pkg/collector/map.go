@@ -23,43 +23,88 @@ import (
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus-community/windows_exporter/internal/collector/ad"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ad_accounts"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ad_replication"
 	"github.com/prometheus-community/windows_exporter/internal/collector/adcs"
 	"github.com/prometheus-community/windows_exporter/internal/collector/adfs"
+	"github.com/prometheus-community/windows_exporter/internal/collector/appx"
+	"github.com/prometheus-community/windows_exporter/internal/collector/autorun"
+	"github.com/prometheus-community/windows_exporter/internal/collector/bits"
 	"github.com/prometheus-community/windows_exporter/internal/collector/cache"
+	"github.com/prometheus-community/windows_exporter/internal/collector/cert_enrollment"
+	"github.com/prometheus-community/windows_exporter/internal/collector/certificates"
+	"github.com/prometheus-community/windows_exporter/internal/collector/citrix_vda"
+	"github.com/prometheus-community/windows_exporter/internal/collector/cloud"
 	"github.com/prometheus-community/windows_exporter/internal/collector/container"
 	"github.com/prometheus-community/windows_exporter/internal/collector/cpu"
 	"github.com/prometheus-community/windows_exporter/internal/collector/cpu_info"
+	"github.com/prometheus-community/windows_exporter/internal/collector/dc_advertisement"
+	"github.com/prometheus-community/windows_exporter/internal/collector/defender"
+	"github.com/prometheus-community/windows_exporter/internal/collector/delivery_optimization"
 	"github.com/prometheus-community/windows_exporter/internal/collector/dfsr"
 	"github.com/prometheus-community/windows_exporter/internal/collector/dhcp"
+	"github.com/prometheus-community/windows_exporter/internal/collector/disk_cleanup"
 	"github.com/prometheus-community/windows_exporter/internal/collector/diskdrive"
 	"github.com/prometheus-community/windows_exporter/internal/collector/dns"
+	"github.com/prometheus-community/windows_exporter/internal/collector/dns_client"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ephemeral_ports"
+	"github.com/prometheus-community/windows_exporter/internal/collector/eventlog"
 	"github.com/prometheus-community/windows_exporter/internal/collector/exchange"
 	"github.com/prometheus-community/windows_exporter/internal/collector/filetime"
+	"github.com/prometheus-community/windows_exporter/internal/collector/firewall"
 	"github.com/prometheus-community/windows_exporter/internal/collector/fsrmquota"
 	"github.com/prometheus-community/windows_exporter/internal/collector/gpu"
+	"github.com/prometheus-community/windows_exporter/internal/collector/hello_for_business"
+	"github.com/prometheus-community/windows_exporter/internal/collector/hns"
+	"github.com/prometheus-community/windows_exporter/internal/collector/host"
+	"github.com/prometheus-community/windows_exporter/internal/collector/httpsys"
 	"github.com/prometheus-community/windows_exporter/internal/collector/hyperv"
+	"github.com/prometheus-community/windows_exporter/internal/collector/icmp"
 	"github.com/prometheus-community/windows_exporter/internal/collector/iis"
+	"github.com/prometheus-community/windows_exporter/internal/collector/image_load"
+	"github.com/prometheus-community/windows_exporter/internal/collector/job_object"
+	"github.com/prometheus-community/windows_exporter/internal/collector/kubernetes_node"
+	"github.com/prometheus-community/windows_exporter/internal/collector/laps"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ldap_client"
 	"github.com/prometheus-community/windows_exporter/internal/collector/license"
 	"github.com/prometheus-community/windows_exporter/internal/collector/logical_disk"
+	"github.com/prometheus-community/windows_exporter/internal/collector/lsa"
+	"github.com/prometheus-community/windows_exporter/internal/collector/mdm"
 	"github.com/prometheus-community/windows_exporter/internal/collector/memory"
 	"github.com/prometheus-community/windows_exporter/internal/collector/mscluster"
 	"github.com/prometheus-community/windows_exporter/internal/collector/msmq"
 	"github.com/prometheus-community/windows_exporter/internal/collector/mssql"
 	"github.com/prometheus-community/windows_exporter/internal/collector/net"
 	"github.com/prometheus-community/windows_exporter/internal/collector/netframework"
+	"github.com/prometheus-community/windows_exporter/internal/collector/nlb"
 	"github.com/prometheus-community/windows_exporter/internal/collector/nps"
+	"github.com/prometheus-community/windows_exporter/internal/collector/nvme"
+	"github.com/prometheus-community/windows_exporter/internal/collector/onedrive"
 	"github.com/prometheus-community/windows_exporter/internal/collector/os"
+	"github.com/prometheus-community/windows_exporter/internal/collector/outlook_data"
 	"github.com/prometheus-community/windows_exporter/internal/collector/pagefile"
+	"github.com/prometheus-community/windows_exporter/internal/collector/perflib"
 	"github.com/prometheus-community/windows_exporter/internal/collector/performancecounter"
 	"github.com/prometheus-community/windows_exporter/internal/collector/physical_disk"
 	"github.com/prometheus-community/windows_exporter/internal/collector/printer"
+	"github.com/prometheus-community/windows_exporter/internal/collector/probe"
 	"github.com/prometheus-community/windows_exporter/internal/collector/process"
+	"github.com/prometheus-community/windows_exporter/internal/collector/rds_licensing"
 	"github.com/prometheus-community/windows_exporter/internal/collector/remote_fx"
+	"github.com/prometheus-community/windows_exporter/internal/collector/schannel"
 	"github.com/prometheus-community/windows_exporter/internal/collector/scheduled_task"
 	"github.com/prometheus-community/windows_exporter/internal/collector/service"
+	"github.com/prometheus-community/windows_exporter/internal/collector/service_account"
+	"github.com/prometheus-community/windows_exporter/internal/collector/service_slo"
 	"github.com/prometheus-community/windows_exporter/internal/collector/smb"
+	"github.com/prometheus-community/windows_exporter/internal/collector/smb_security"
+	"github.com/prometheus-community/windows_exporter/internal/collector/smb_witness"
 	"github.com/prometheus-community/windows_exporter/internal/collector/smbclient"
 	"github.com/prometheus-community/windows_exporter/internal/collector/smtp"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ssas"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ssis"
+	"github.com/prometheus-community/windows_exporter/internal/collector/ssrs"
+	"github.com/prometheus-community/windows_exporter/internal/collector/storage_hotplug"
 	"github.com/prometheus-community/windows_exporter/internal/collector/system"
 	"github.com/prometheus-community/windows_exporter/internal/collector/tcp"
 	"github.com/prometheus-community/windows_exporter/internal/collector/terminal_services"
@@ -68,7 +113,15 @@ import (
 	"github.com/prometheus-community/windows_exporter/internal/collector/time"
 	"github.com/prometheus-community/windows_exporter/internal/collector/udp"
 	"github.com/prometheus-community/windows_exporter/internal/collector/update"
+	"github.com/prometheus-community/windows_exporter/internal/collector/update_policy"
+	"github.com/prometheus-community/windows_exporter/internal/collector/user_profile"
 	"github.com/prometheus-community/windows_exporter/internal/collector/vmware"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wdac"
+	"github.com/prometheus-community/windows_exporter/internal/collector/web_application_proxy"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wef"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wins"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wmi_health"
+	"github.com/prometheus-community/windows_exporter/internal/collector/wmi_provider_host"
 )
 
 func NewBuilderWithFlags[C Collector](fn BuilderWithFlags[C]) BuilderWithFlags[Collector] {
@@ -79,53 +132,106 @@ func NewBuilderWithFlags[C Collector](fn BuilderWithFlags[C]) BuilderWithFlags[C
 
 //nolint:gochecknoglobals
 var BuildersWithFlags = map[string]BuilderWithFlags[Collector]{
-	ad.Name:                 NewBuilderWithFlags(ad.NewWithFlags),
-	adcs.Name:               NewBuilderWithFlags(adcs.NewWithFlags),
-	adfs.Name:               NewBuilderWithFlags(adfs.NewWithFlags),
-	cache.Name:              NewBuilderWithFlags(cache.NewWithFlags),
-	container.Name:          NewBuilderWithFlags(container.NewWithFlags),
-	cpu.Name:                NewBuilderWithFlags(cpu.NewWithFlags),
-	cpu_info.Name:           NewBuilderWithFlags(cpu_info.NewWithFlags),
-	dfsr.Name:               NewBuilderWithFlags(dfsr.NewWithFlags),
-	dhcp.Name:               NewBuilderWithFlags(dhcp.NewWithFlags),
-	diskdrive.Name:          NewBuilderWithFlags(diskdrive.NewWithFlags),
-	dns.Name:                NewBuilderWithFlags(dns.NewWithFlags),
-	exchange.Name:           NewBuilderWithFlags(exchange.NewWithFlags),
-	filetime.Name:           NewBuilderWithFlags(filetime.NewWithFlags),
-	fsrmquota.Name:          NewBuilderWithFlags(fsrmquota.NewWithFlags),
-	gpu.Name:                NewBuilderWithFlags(gpu.NewWithFlags),
-	hyperv.Name:             NewBuilderWithFlags(hyperv.NewWithFlags),
-	iis.Name:                NewBuilderWithFlags(iis.NewWithFlags),
-	license.Name:            NewBuilderWithFlags(license.NewWithFlags),
-	logical_disk.Name:       NewBuilderWithFlags(logical_disk.NewWithFlags),
-	memory.Name:             NewBuilderWithFlags(memory.NewWithFlags),
-	mscluster.Name:          NewBuilderWithFlags(mscluster.NewWithFlags),
-	msmq.Name:               NewBuilderWithFlags(msmq.NewWithFlags),
-	mssql.Name:              NewBuilderWithFlags(mssql.NewWithFlags),
-	net.Name:                NewBuilderWithFlags(net.NewWithFlags),
-	netframework.Name:       NewBuilderWithFlags(netframework.NewWithFlags),
-	nps.Name:                NewBuilderWithFlags(nps.NewWithFlags),
-	os.Name:                 NewBuilderWithFlags(os.NewWithFlags),
-	pagefile.Name:           NewBuilderWithFlags(pagefile.NewWithFlags),
-	performancecounter.Name: NewBuilderWithFlags(performancecounter.NewWithFlags),
-	physical_disk.Name:      NewBuilderWithFlags(physical_disk.NewWithFlags),
-	printer.Name:            NewBuilderWithFlags(printer.NewWithFlags),
-	process.Name:            NewBuilderWithFlags(process.NewWithFlags),
-	remote_fx.Name:          NewBuilderWithFlags(remote_fx.NewWithFlags),
-	scheduled_task.Name:     NewBuilderWithFlags(scheduled_task.NewWithFlags),
-	service.Name:            NewBuilderWithFlags(service.NewWithFlags),
-	smb.Name:                NewBuilderWithFlags(smb.NewWithFlags),
-	smbclient.Name:          NewBuilderWithFlags(smbclient.NewWithFlags),
-	smtp.Name:               NewBuilderWithFlags(smtp.NewWithFlags),
-	system.Name:             NewBuilderWithFlags(system.NewWithFlags),
-	tcp.Name:                NewBuilderWithFlags(tcp.NewWithFlags),
-	terminal_services.Name:  NewBuilderWithFlags(terminal_services.NewWithFlags),
-	textfile.Name:           NewBuilderWithFlags(textfile.NewWithFlags),
-	thermalzone.Name:        NewBuilderWithFlags(thermalzone.NewWithFlags),
-	time.Name:               NewBuilderWithFlags(time.NewWithFlags),
-	udp.Name:                NewBuilderWithFlags(udp.NewWithFlags),
-	update.Name:             NewBuilderWithFlags(update.NewWithFlags),
-	vmware.Name:             NewBuilderWithFlags(vmware.NewWithFlags),
+	ad.Name:                    NewBuilderWithFlags(ad.NewWithFlags),
+	ad_accounts.Name:           NewBuilderWithFlags(ad_accounts.NewWithFlags),
+	ad_replication.Name:        NewBuilderWithFlags(ad_replication.NewWithFlags),
+	adcs.Name:                  NewBuilderWithFlags(adcs.NewWithFlags),
+	adfs.Name:                  NewBuilderWithFlags(adfs.NewWithFlags),
+	appx.Name:                  NewBuilderWithFlags(appx.NewWithFlags),
+	autorun.Name:               NewBuilderWithFlags(autorun.NewWithFlags),
+	bits.Name:                  NewBuilderWithFlags(bits.NewWithFlags),
+	cache.Name:                 NewBuilderWithFlags(cache.NewWithFlags),
+	cert_enrollment.Name:       NewBuilderWithFlags(cert_enrollment.NewWithFlags),
+	certificates.Name:          NewBuilderWithFlags(certificates.NewWithFlags),
+	citrix_vda.Name:            NewBuilderWithFlags(citrix_vda.NewWithFlags),
+	cloud.Name:                 NewBuilderWithFlags(cloud.NewWithFlags),
+	container.Name:             NewBuilderWithFlags(container.NewWithFlags),
+	cpu.Name:                   NewBuilderWithFlags(cpu.NewWithFlags),
+	cpu_info.Name:              NewBuilderWithFlags(cpu_info.NewWithFlags),
+	dc_advertisement.Name:      NewBuilderWithFlags(dc_advertisement.NewWithFlags),
+	defender.Name:              NewBuilderWithFlags(defender.NewWithFlags),
+	delivery_optimization.Name: NewBuilderWithFlags(delivery_optimization.NewWithFlags),
+	dfsr.Name:                  NewBuilderWithFlags(dfsr.NewWithFlags),
+	dhcp.Name:                  NewBuilderWithFlags(dhcp.NewWithFlags),
+	disk_cleanup.Name:          NewBuilderWithFlags(disk_cleanup.NewWithFlags),
+	diskdrive.Name:             NewBuilderWithFlags(diskdrive.NewWithFlags),
+	dns.Name:                   NewBuilderWithFlags(dns.NewWithFlags),
+	dns_client.Name:            NewBuilderWithFlags(dns_client.NewWithFlags),
+	ephemeral_ports.Name:       NewBuilderWithFlags(ephemeral_ports.NewWithFlags),
+	eventlog.Name:              NewBuilderWithFlags(eventlog.NewWithFlags),
+	exchange.Name:              NewBuilderWithFlags(exchange.NewWithFlags),
+	filetime.Name:              NewBuilderWithFlags(filetime.NewWithFlags),
+	firewall.Name:              NewBuilderWithFlags(firewall.NewWithFlags),
+	fsrmquota.Name:             NewBuilderWithFlags(fsrmquota.NewWithFlags),
+	gpu.Name:                   NewBuilderWithFlags(gpu.NewWithFlags),
+	hello_for_business.Name:    NewBuilderWithFlags(hello_for_business.NewWithFlags),
+	hns.Name:                   NewBuilderWithFlags(hns.NewWithFlags),
+	host.Name:                  NewBuilderWithFlags(host.NewWithFlags),
+	httpsys.Name:               NewBuilderWithFlags(httpsys.NewWithFlags),
+	hyperv.Name:                NewBuilderWithFlags(hyperv.NewWithFlags),
+	icmp.Name:                  NewBuilderWithFlags(icmp.NewWithFlags),
+	iis.Name:                   NewBuilderWithFlags(iis.NewWithFlags),
+	image_load.Name:            NewBuilderWithFlags(image_load.NewWithFlags),
+	job_object.Name:            NewBuilderWithFlags(job_object.NewWithFlags),
+	kubernetes_node.Name:       NewBuilderWithFlags(kubernetes_node.NewWithFlags),
+	laps.Name:                  NewBuilderWithFlags(laps.NewWithFlags),
+	ldap_client.Name:           NewBuilderWithFlags(ldap_client.NewWithFlags),
+	license.Name:               NewBuilderWithFlags(license.NewWithFlags),
+	logical_disk.Name:          NewBuilderWithFlags(logical_disk.NewWithFlags),
+	lsa.Name:                   NewBuilderWithFlags(lsa.NewWithFlags),
+	mdm.Name:                   NewBuilderWithFlags(mdm.NewWithFlags),
+	memory.Name:                NewBuilderWithFlags(memory.NewWithFlags),
+	mscluster.Name:             NewBuilderWithFlags(mscluster.NewWithFlags),
+	msmq.Name:                  NewBuilderWithFlags(msmq.NewWithFlags),
+	mssql.Name:                 NewBuilderWithFlags(mssql.NewWithFlags),
+	net.Name:                   NewBuilderWithFlags(net.NewWithFlags),
+	netframework.Name:          NewBuilderWithFlags(netframework.NewWithFlags),
+	nlb.Name:                   NewBuilderWithFlags(nlb.NewWithFlags),
+	nps.Name:                   NewBuilderWithFlags(nps.NewWithFlags),
+	nvme.Name:                  NewBuilderWithFlags(nvme.NewWithFlags),
+	onedrive.Name:              NewBuilderWithFlags(onedrive.NewWithFlags),
+	os.Name:                    NewBuilderWithFlags(os.NewWithFlags),
+	outlook_data.Name:          NewBuilderWithFlags(outlook_data.NewWithFlags),
+	pagefile.Name:              NewBuilderWithFlags(pagefile.NewWithFlags),
+	perflib.Name:               NewBuilderWithFlags(perflib.NewWithFlags),
+	performancecounter.Name:    NewBuilderWithFlags(performancecounter.NewWithFlags),
+	physical_disk.Name:         NewBuilderWithFlags(physical_disk.NewWithFlags),
+	printer.Name:               NewBuilderWithFlags(printer.NewWithFlags),
+	probe.Name:                 NewBuilderWithFlags(probe.NewWithFlags),
+	process.Name:               NewBuilderWithFlags(process.NewWithFlags),
+	rds_licensing.Name:         NewBuilderWithFlags(rds_licensing.NewWithFlags),
+	remote_fx.Name:             NewBuilderWithFlags(remote_fx.NewWithFlags),
+	schannel.Name:              NewBuilderWithFlags(schannel.NewWithFlags),
+	scheduled_task.Name:        NewBuilderWithFlags(scheduled_task.NewWithFlags),
+	service.Name:               NewBuilderWithFlags(service.NewWithFlags),
+	service_account.Name:       NewBuilderWithFlags(service_account.NewWithFlags),
+	service_slo.Name:           NewBuilderWithFlags(service_slo.NewWithFlags),
+	smb.Name:                   NewBuilderWithFlags(smb.NewWithFlags),
+	smb_security.Name:          NewBuilderWithFlags(smb_security.NewWithFlags),
+	smb_witness.Name:           NewBuilderWithFlags(smb_witness.NewWithFlags),
+	smbclient.Name:             NewBuilderWithFlags(smbclient.NewWithFlags),
+	smtp.Name:                  NewBuilderWithFlags(smtp.NewWithFlags),
+	ssas.Name:                  NewBuilderWithFlags(ssas.NewWithFlags),
+	ssis.Name:                  NewBuilderWithFlags(ssis.NewWithFlags),
+	ssrs.Name:                  NewBuilderWithFlags(ssrs.NewWithFlags),
+	storage_hotplug.Name:       NewBuilderWithFlags(storage_hotplug.NewWithFlags),
+	system.Name:                NewBuilderWithFlags(system.NewWithFlags),
+	tcp.Name:                   NewBuilderWithFlags(tcp.NewWithFlags),
+	terminal_services.Name:     NewBuilderWithFlags(terminal_services.NewWithFlags),
+	textfile.Name:              NewBuilderWithFlags(textfile.NewWithFlags),
+	thermalzone.Name:           NewBuilderWithFlags(thermalzone.NewWithFlags),
+	time.Name:                  NewBuilderWithFlags(time.NewWithFlags),
+	udp.Name:                   NewBuilderWithFlags(udp.NewWithFlags),
+	update.Name:                NewBuilderWithFlags(update.NewWithFlags),
+	update_policy.Name:         NewBuilderWithFlags(update_policy.NewWithFlags),
+	user_profile.Name:          NewBuilderWithFlags(user_profile.NewWithFlags),
+	vmware.Name:                NewBuilderWithFlags(vmware.NewWithFlags),
+	wdac.Name:                  NewBuilderWithFlags(wdac.NewWithFlags),
+	web_application_proxy.Name: NewBuilderWithFlags(web_application_proxy.NewWithFlags),
+	wef.Name:                   NewBuilderWithFlags(wef.NewWithFlags),
+	wins.Name:                  NewBuilderWithFlags(wins.NewWithFlags),
+	wmi_health.Name:            NewBuilderWithFlags(wmi_health.NewWithFlags),
+	wmi_provider_host.Name:     NewBuilderWithFlags(wmi_provider_host.NewWithFlags),
 }
 
 // Available returns a sorted list of available collectors.
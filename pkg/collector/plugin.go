@@ -0,0 +1,311 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package collector
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	PluginTypeExec = "exec"
+	PluginTypeGRPC = "grpc"
+
+	// pluginStopTimeout is the time a plugin has to exit after it was asked to, before it's killed.
+	pluginStopTimeout = 2 * time.Second
+	// maxPluginResponseSize limits the metrics of a plugin per collection.
+	maxPluginResponseSize = 16 << 20
+)
+
+//nolint:gochecknoglobals
+var pluginNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// PluginConfig configures an external collector, which is run as long-running child process of windows_exporter.
+type PluginConfig struct {
+	// Name is the name of the collector, e.g. for --scrape.collector-timeouts and the collect[] parameters of scrapes.
+	Name string `yaml:"name"`
+	// Type is either PluginTypeExec or PluginTypeGRPC.
+	Type    string            `yaml:"type"`
+	Command string            `yaml:"command"`
+	Args    []string          `yaml:"args"`
+	Env     map[string]string `yaml:"env"`
+}
+
+// LoadPlugins reads the plugins from a YAML file, which contains a list of plugins.
+func LoadPlugins(path string) ([]PluginConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugins file: %w", err)
+	}
+
+	defer file.Close()
+
+	var plugins []PluginConfig
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&plugins); err != nil {
+		return nil, fmt.Errorf("failed to parse plugins file: %w", err)
+	}
+
+	for i, plugin := range plugins {
+		if !pluginNameRegexp.MatchString(plugin.Name) {
+			return nil, fmt.Errorf("plugin %d of the plugins file: name %q must match %s", i+1, plugin.Name, pluginNameRegexp)
+		}
+
+		if plugin.Type != PluginTypeExec && plugin.Type != PluginTypeGRPC {
+			return nil, fmt.Errorf("plugin %s: type must be %s or %s, got %q", plugin.Name, PluginTypeExec, PluginTypeGRPC, plugin.Type)
+		}
+
+		if plugin.Command == "" {
+			return nil, fmt.Errorf("plugin %s: command is required", plugin.Name)
+		}
+	}
+
+	return plugins, nil
+}
+
+// AddPlugins adds the plugins as enabled collectors. It must be called before Build.
+func (c *Collection) AddPlugins(plugins []PluginConfig) error {
+	for _, plugin := range plugins {
+		if _, ok := BuildersWithFlags[plugin.Name]; ok {
+			return fmt.Errorf("plugin %s has the name of a collector of windows_exporter", plugin.Name)
+		}
+
+		if _, ok := c.collectors[plugin.Name]; ok {
+			return fmt.Errorf("duplicate plugin %s", plugin.Name)
+		}
+
+		switch plugin.Type {
+		case PluginTypeExec:
+			c.collectors[plugin.Name] = &execPlugin{config: plugin}
+		case PluginTypeGRPC:
+			c.collectors[plugin.Name] = &grpcPlugin{config: plugin}
+		default:
+			return fmt.Errorf("plugin %s has unknown type %q", plugin.Name, plugin.Type)
+		}
+	}
+
+	return nil
+}
+
+func isPlugin(collector Collector) bool {
+	switch collector.(type) {
+	case *execPlugin, *grpcPlugin:
+		return true
+	default:
+		return false
+	}
+}
+
+// pluginProcess is the running child process of a plugin.
+type pluginProcess struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+	// stopping is set once stop is called, so the exit isn't logged as a crash.
+	stopping atomic.Bool
+}
+
+// startPluginProcess starts the command of the plugin. stdin and stdout are pipes, which are closed by stop,
+// the lines of stderr are logged.
+func startPluginProcess(logger *slog.Logger, config PluginConfig, env ...string) (*pluginProcess, *os.File, *os.File, error) {
+	cmd := exec.Command(config.Command, config.Args...)
+	cmd.Env = append(os.Environ(), env...)
+
+	for _, name := range slices.Sorted(maps.Keys(config.Env)) {
+		cmd.Env = append(cmd.Env, name+"="+config.Env[name])
+	}
+
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return nil, nil, nil, errors.Join(err, stdinReader.Close(), stdinWriter.Close())
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, nil, nil, errors.Join(err, stdinReader.Close(), stdinWriter.Close(), stdoutReader.Close(), stdoutWriter.Close())
+	}
+
+	// The pipes are passed to the process directly, so stdout is closed as soon as the process exits.
+	cmd.Stdin = stdinReader
+	cmd.Stdout = stdoutWriter
+
+	err = cmd.Start()
+
+	// The ends of the process are closed, they are inherited by the process.
+	closeErr := errors.Join(stdinReader.Close(), stdoutWriter.Close())
+
+	if err != nil {
+		return nil, nil, nil, errors.Join(fmt.Errorf("failed to start plugin: %w", err), closeErr, stdinWriter.Close(), stdoutReader.Close())
+	}
+
+	go logPluginOutput(logger, "stderr", stderr)
+
+	process := &pluginProcess{cmd: cmd, done: make(chan struct{})}
+
+	go func() {
+		err := cmd.Wait()
+
+		level := slog.LevelWarn
+		if process.stopping.Load() {
+			level = slog.LevelDebug
+		}
+
+		logger.Log(context.Background(), level, "plugin exited",
+			slog.Any("err", err),
+		)
+
+		close(process.done)
+	}()
+
+	return process, stdinWriter, stdoutReader, nil
+}
+
+// exited reports whether the process exited, e.g. because it crashed.
+func (p *pluginProcess) exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop waits up to timeout for the process to exit and kills it afterward.
+func (p *pluginProcess) stop(timeout time.Duration) error {
+	p.stopping.Store(true)
+
+	select {
+	case <-p.done:
+		return nil
+	case <-time.After(timeout):
+	}
+
+	if err := p.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("failed to kill plugin: %w", err)
+	}
+
+	<-p.done
+
+	return nil
+}
+
+func logPluginOutput(logger *slog.Logger, stream string, output io.Reader) {
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		logger.Info(scanner.Text(),
+			slog.String("stream", stream),
+		)
+	}
+}
+
+// collectMetricFamilies sends the metrics of the plugin to ch. Metrics without help get a generic one,
+// since the registry requires the same help for all metrics of a family.
+func collectMetricFamilies(name string, families []*dto.MetricFamily, ch chan<- prometheus.Metric) error {
+	for _, family := range families {
+		help := family.GetHelp()
+		if help == "" {
+			help = "Metric of the plugin " + name
+		}
+
+		for _, metric := range family.GetMetric() {
+			labels := slices.Clone(metric.GetLabel())
+			slices.SortFunc(labels, func(a, b *dto.LabelPair) int {
+				return cmp.Compare(a.GetName(), b.GetName())
+			})
+
+			labelNames := make([]string, len(labels))
+			for i, label := range labels {
+				labelNames[i] = label.GetName()
+			}
+
+			desc := prometheus.NewDesc(family.GetName(), help, labelNames, nil)
+
+			if !pluginMetricMatchesType(metric, family.GetType()) {
+				return fmt.Errorf("metric %s of the plugin %s doesn't match its type %s", family.GetName(), name, strings.ToLower(family.GetType().String()))
+			}
+
+			ch <- pluginMetric{desc: desc, labels: labels, metric: metric}
+		}
+	}
+
+	return nil
+}
+
+func pluginMetricMatchesType(metric *dto.Metric, metricType dto.MetricType) bool {
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		return metric.Counter != nil
+	case dto.MetricType_GAUGE:
+		return metric.Gauge != nil
+	case dto.MetricType_SUMMARY:
+		return metric.Summary != nil
+	case dto.MetricType_UNTYPED:
+		return metric.Untyped != nil
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		return metric.Histogram != nil
+	default:
+		return false
+	}
+}
+
+// pluginMetric is a metric of a plugin, which is passed to the registry as received.
+type pluginMetric struct {
+	desc   *prometheus.Desc
+	labels []*dto.LabelPair
+	metric *dto.Metric
+}
+
+func (m pluginMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+func (m pluginMetric) Write(out *dto.Metric) error {
+	out.Label = m.labels
+	out.Counter = m.metric.Counter
+	out.Gauge = m.metric.Gauge
+	out.Summary = m.metric.Summary
+	out.Untyped = m.metric.Untyped
+	out.Histogram = m.metric.Histogram
+	out.TimestampMs = m.metric.TimestampMs
+
+	return nil
+}
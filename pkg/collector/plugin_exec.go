@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// execPluginRequest is written to stdin of exec plugins for each collection.
+	execPluginRequest = "collect\n"
+	// execPluginEOF terminates the response of exec plugins.
+	execPluginEOF = "# EOF"
+)
+
+// execPlugin is a plugin that speaks a line-based protocol on stdin and stdout: For each collection,
+// windows_exporter writes the line "collect" to stdin and the plugin responds with its metrics in the
+// Prometheus text format, terminated by the line "# EOF". Plugins that don't respond in time are killed
+// and restarted on the next collection, since the response would mix up with the next one.
+type execPlugin struct {
+	config PluginConfig
+	logger *slog.Logger
+
+	// mu serializes the collections, the protocol handles one request at a time.
+	mu      sync.Mutex
+	process *pluginProcess
+	stdin   *os.File
+	stdout  *os.File
+	reader  *bufio.Reader
+}
+
+func (p *execPlugin) GetName() string {
+	return p.config.Name
+}
+
+func (p *execPlugin) Build(logger *slog.Logger, _ *mi.Session) error {
+	p.logger = logger.With(slog.String("collector", p.config.Name))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.start()
+}
+
+func (p *execPlugin) start() error {
+	process, stdin, stdout, err := startPluginProcess(p.logger, p.config)
+	if err != nil {
+		return err
+	}
+
+	p.process, p.stdin, p.stdout = process, stdin, stdout
+	p.reader = bufio.NewReader(stdout)
+
+	return nil
+}
+
+// stop closes stdin, which asks the plugin to exit, and kills it after pluginStopTimeout.
+func (p *execPlugin) stop(timeout time.Duration) error {
+	if p.process == nil {
+		return nil
+	}
+
+	err := errors.Join(p.stdin.Close(), p.process.stop(timeout), p.stdout.Close())
+	p.process = nil
+
+	return err
+}
+
+func (p *execPlugin) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.stop(pluginStopTimeout)
+}
+
+func (p *execPlugin) Collect(ch chan<- prometheus.Metric) error {
+	return p.CollectWithContext(context.Background(), ch)
+}
+
+func (p *execPlugin) CollectWithContext(ctx context.Context, ch chan<- prometheus.Metric) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.process != nil && p.process.exited() {
+		_ = p.stop(0)
+	}
+
+	if p.process == nil {
+		if err := p.start(); err != nil {
+			return err
+		}
+	}
+
+	type response struct {
+		content []byte
+		err     error
+	}
+
+	responseCh := make(chan response, 1)
+
+	go func() {
+		content, err := p.request()
+		responseCh <- response{content: content, err: err}
+	}()
+
+	var resp response
+
+	select {
+	case resp = <-responseCh:
+	case <-ctx.Done():
+		// Killing the plugin unblocks the pending request.
+		err := p.stop(0)
+		<-responseCh
+
+		return errors.Join(fmt.Errorf("plugin didn't respond in time: %w", ctx.Err()), err)
+	}
+
+	if resp.err != nil {
+		return errors.Join(resp.err, p.stop(0))
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(resp.content))
+	if err != nil {
+		return fmt.Errorf("failed to parse metrics of plugin: %w", err)
+	}
+
+	return collectMetricFamilies(p.config.Name, slices.Collect(maps.Values(families)), ch)
+}
+
+// request writes the request to the plugin and reads the response up to the EOF line.
+func (p *execPlugin) request() ([]byte, error) {
+	if _, err := io.WriteString(p.stdin, execPluginRequest); err != nil {
+		return nil, fmt.Errorf("failed to write request to plugin: %w", err)
+	}
+
+	var content bytes.Buffer
+
+	for {
+		line, err := p.reader.ReadBytes('\n')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response of plugin: %w", err)
+		}
+
+		if string(bytes.TrimRight(line, "\r\n")) == execPluginEOF {
+			return content.Bytes(), nil
+		}
+
+		if content.Len()+len(line) > maxPluginResponseSize {
+			return nil, fmt.Errorf("response of plugin exceeds %d bytes", maxPluginResponseSize)
+		}
+
+		content.Write(line)
+	}
+}
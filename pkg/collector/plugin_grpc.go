@@ -0,0 +1,342 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// The magic cookie tells the plugin that it's started by windows_exporter, like the magic cookie of
+	// hashicorp/go-plugin. The protocol versions are the ones of go-plugin and of the Collector service.
+	grpcPluginMagicCookieKey   = "WINDOWS_EXPORTER_PLUGIN"
+	grpcPluginMagicCookieValue = "collector"
+	grpcPluginCoreVersion      = "1"
+	grpcPluginProtocolVersion  = "1"
+
+	// grpcPluginStartTimeout limits the time until the plugin writes the handshake line.
+	grpcPluginStartTimeout = 10 * time.Second
+
+	grpcPluginCollectMethod  = "/windows_exporter.plugin.v1.Collector/Collect"
+	grpcPluginShutdownMethod = "/plugin.GRPCController/Shutdown"
+)
+
+// grpcPlugin is a plugin that serves the Collector service of docs/plugin.proto via gRPC, in the style of
+// hashicorp/go-plugin: windows_exporter starts the plugin with the magic cookie in the environment, and the plugin
+// writes the handshake line "1|1|tcp|127.0.0.1:<port>|grpc" to stdout once it's listening. Plugins built with
+// go-plugin write this line themselves. TLS is not supported, since the plugin only listens on the loopback interface.
+type grpcPlugin struct {
+	config PluginConfig
+	logger *slog.Logger
+
+	// mu protects the process, which is restarted on the next collection if it exited.
+	mu      sync.Mutex
+	process *pluginProcess
+	stdin   *os.File
+	stdout  *os.File
+	address string
+	client  *http.Client
+}
+
+func (p *grpcPlugin) GetName() string {
+	return p.config.Name
+}
+
+func (p *grpcPlugin) Build(logger *slog.Logger, _ *mi.Session) error {
+	p.logger = logger.With(slog.String("collector", p.config.Name))
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return errors.New("unexpected type of http.DefaultTransport")
+	}
+
+	transport = transport.Clone()
+	transport.Proxy = nil
+	// gRPC requires HTTP/2, which is used without TLS on the loopback interface.
+	transport.Protocols = new(http.Protocols)
+	transport.Protocols.SetUnencryptedHTTP2(true)
+
+	p.client = &http.Client{Transport: transport}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.start()
+}
+
+func (p *grpcPlugin) start() error {
+	process, stdin, stdout, err := startPluginProcess(p.logger, p.config,
+		grpcPluginMagicCookieKey+"="+grpcPluginMagicCookieValue,
+		"PLUGIN_PROTOCOL_VERSIONS="+grpcPluginProtocolVersion,
+	)
+	if err != nil {
+		return err
+	}
+
+	p.process, p.stdin, p.stdout = process, stdin, stdout
+
+	reader := bufio.NewReader(stdout)
+	lineCh := make(chan string, 1)
+
+	go func() {
+		line, _ := reader.ReadString('\n')
+		lineCh <- line
+	}()
+
+	var line string
+
+	select {
+	case line = <-lineCh:
+	case <-time.After(grpcPluginStartTimeout):
+	}
+
+	address, err := parseGRPCHandshake(line)
+	if err != nil {
+		return errors.Join(err, p.stop(0))
+	}
+
+	p.address = address
+
+	// Plugins shouldn't write to stdout after the handshake, since go-plugin forwards the output via gRPC.
+	go logPluginOutput(p.logger, "stdout", reader)
+
+	return nil
+}
+
+// parseGRPCHandshake returns the address of the plugin from the handshake line.
+func parseGRPCHandshake(line string) (string, error) {
+	if line == "" {
+		return "", errors.New("plugin didn't write a handshake line")
+	}
+
+	parts := strings.Split(strings.TrimSpace(line), "|")
+
+	switch {
+	case len(parts) < 5:
+		return "", fmt.Errorf("invalid handshake line %q", strings.TrimSpace(line))
+	case parts[0] != grpcPluginCoreVersion:
+		return "", fmt.Errorf("unsupported core protocol version %s of plugin, expected %s", parts[0], grpcPluginCoreVersion)
+	case parts[1] != grpcPluginProtocolVersion:
+		return "", fmt.Errorf("unsupported protocol version %s of plugin, expected %s", parts[1], grpcPluginProtocolVersion)
+	case parts[2] != "tcp":
+		return "", fmt.Errorf("unsupported network %s of plugin, expected tcp", parts[2])
+	case parts[4] != "grpc":
+		return "", fmt.Errorf("unsupported protocol %s of plugin, expected grpc", parts[4])
+	case len(parts) > 5 && parts[5] != "":
+		return "", errors.New("plugin requires TLS, which is not supported")
+	}
+
+	return parts[3], nil
+}
+
+// stop closes stdin and kills the plugin after timeout.
+func (p *grpcPlugin) stop(timeout time.Duration) error {
+	if p.process == nil {
+		return nil
+	}
+
+	err := errors.Join(p.stdin.Close(), p.process.stop(timeout), p.stdout.Close())
+	p.process = nil
+
+	return err
+}
+
+// Close asks the plugin to shut down via the GRPCController service of go-plugin. Plugins that don't implement it
+// are killed after pluginStopTimeout.
+func (p *grpcPlugin) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.process == nil {
+		return nil
+	}
+
+	if !p.process.exited() {
+		ctx, cancel := context.WithTimeout(context.Background(), pluginStopTimeout)
+		_, _ = p.invoke(ctx, grpcPluginShutdownMethod, nil)
+
+		cancel()
+	}
+
+	err := p.stop(pluginStopTimeout)
+
+	p.client.CloseIdleConnections()
+
+	return err
+}
+
+func (p *grpcPlugin) Collect(ch chan<- prometheus.Metric) error {
+	return p.CollectWithContext(context.Background(), ch)
+}
+
+func (p *grpcPlugin) CollectWithContext(ctx context.Context, ch chan<- prometheus.Metric) error {
+	p.mu.Lock()
+
+	if p.process != nil && p.process.exited() {
+		_ = p.stop(0)
+	}
+
+	if p.process == nil {
+		if err := p.start(); err != nil {
+			p.mu.Unlock()
+
+			return err
+		}
+	}
+
+	p.mu.Unlock()
+
+	// CollectRequest has no fields yet, the deadline is sent as grpc-timeout.
+	message, err := p.invoke(ctx, grpcPluginCollectMethod, nil)
+	if err != nil {
+		return err
+	}
+
+	families, err := decodeCollectResponse(message)
+	if err != nil {
+		return fmt.Errorf("failed to decode response of plugin: %w", err)
+	}
+
+	return collectMetricFamilies(p.config.Name, families, ch)
+}
+
+// invoke sends a unary gRPC request and returns the response message.
+// 📑 https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md
+func (p *grpcPlugin) invoke(ctx context.Context, method string, message []byte) ([]byte, error) {
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	frame = append(frame, message...)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+p.address+method, bytes.NewReader(frame))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	req.Header.Set("User-Agent", "windows_exporter")
+
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Header.Set("Grpc-Timeout", strconv.FormatInt(max(time.Until(deadline).Milliseconds(), 1), 10)+"m")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to plugin: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPluginResponseSize+5+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response of plugin: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d of plugin", resp.StatusCode)
+	}
+
+	if len(body) > maxPluginResponseSize+5 {
+		return nil, fmt.Errorf("response of plugin exceeds %d bytes", maxPluginResponseSize)
+	}
+
+	// Responses without message send the status in the headers.
+	status, statusMessage := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, statusMessage = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+
+	if status != "0" {
+		if unescaped, err := url.PathUnescape(statusMessage); err == nil {
+			statusMessage = unescaped
+		}
+
+		return nil, fmt.Errorf("plugin returned gRPC status %s: %s", status, statusMessage)
+	}
+
+	if len(body) < 5 || binary.BigEndian.Uint32(body[1:5]) != uint32(len(body)-5) {
+		return nil, errors.New("invalid gRPC message of plugin")
+	}
+
+	if body[0] != 0 {
+		return nil, errors.New("compressed gRPC message of plugin, compression is not supported")
+	}
+
+	return body[5:], nil
+}
+
+// decodeCollectResponse decodes the metric families of the CollectResponse message, see docs/plugin.proto.
+func decodeCollectResponse(message []byte) ([]*dto.MetricFamily, error) {
+	var families []*dto.MetricFamily
+
+	for len(message) > 0 {
+		number, wireType, n := protowire.ConsumeTag(message)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+
+		message = message[n:]
+
+		if number != 1 || wireType != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(number, wireType, message)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+
+			message = message[n:]
+
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(message)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+
+		message = message[n:]
+
+		family := &dto.MetricFamily{}
+		if err := proto.Unmarshal(value, family); err != nil {
+			return nil, err
+		}
+
+		families = append(families, family)
+	}
+
+	return families, nil
+}
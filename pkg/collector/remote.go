@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	gotime "time"
+
+	"github.com/prometheus-community/windows_exporter/internal/collector/delivery_optimization"
+	"github.com/prometheus-community/windows_exporter/internal/collector/diskdrive"
+	"github.com/prometheus-community/windows_exporter/internal/collector/fsrmquota"
+	"github.com/prometheus-community/windows_exporter/internal/collector/netframework"
+	"github.com/prometheus-community/windows_exporter/internal/collector/printer"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+)
+
+// remoteCollectors are the collectors that read all their data through the MI session
+// and therefore also work with a session to a remote host.
+//
+//nolint:gochecknoglobals
+var remoteCollectors = []string{
+	delivery_optimization.Name,
+	diskdrive.Name,
+	fsrmquota.Name,
+	netframework.Name,
+	printer.Name,
+}
+
+// RemoteOptions configures the MI session to a remote host.
+type RemoteOptions struct {
+	// Protocol is either mi.ProtocolWinRM or mi.ProtocolWMIDCOM.
+	Protocol string
+	// AuthenticationType is one of the mi.AuthenticationType* constants. Only used if Username is set.
+	AuthenticationType string
+	Domain             string
+	// Username and Password authenticate to the remote host. If Username is empty,
+	// the account of windows_exporter is used.
+	Username string
+	Password string
+	Timeout  gotime.Duration
+}
+
+// RemoteCollectors returns the names of the collectors that support remote hosts.
+func RemoteCollectors() []string {
+	return slices.Clone(remoteCollectors)
+}
+
+// NewRemote builds a collection of the given collectors that collects from the remote host target.
+// The collectors use their default configuration. The collection must be closed after use.
+func NewRemote(ctx context.Context, logger *slog.Logger, target string, options RemoteOptions, collectors []string) (*Collection, error) {
+	for _, name := range collectors {
+		if !slices.Contains(remoteCollectors, name) {
+			return nil, fmt.Errorf("collector %s doesn't support remote hosts", name)
+		}
+	}
+
+	c := NewWithConfig(ConfigDefaults)
+	c.remote = true
+	c.startTime = gotime.Now()
+
+	if err := c.Enable(collectors); err != nil {
+		return nil, err
+	}
+
+	if err := c.initRemoteMI(target, options); err != nil {
+		return nil, fmt.Errorf("error from initialize MI: %w", err)
+	}
+
+	if err := c.buildCollectors(ctx, logger); err != nil {
+		return nil, errors.Join(err, c.Close())
+	}
+
+	return c, nil
+}
+
+// initRemoteMI creates the MI session to the remote host.
+func (c *Collection) initRemoteMI(target string, options RemoteOptions) error {
+	app, err := mi.ApplicationInitialize()
+	if err != nil {
+		return fmt.Errorf("error from initialize MI application: %w", err)
+	}
+
+	destinationOptions, err := app.NewDestinationOptions()
+	if err != nil {
+		return errors.Join(fmt.Errorf("error from create NewDestinationOptions: %w", err), app.Close())
+	}
+
+	defer destinationOptions.Delete() //nolint:errcheck
+
+	if err = destinationOptions.SetLocale(mi.LocaleEnglish); err != nil {
+		return errors.Join(fmt.Errorf("error from set locale: %w", err), app.Close())
+	}
+
+	if options.Timeout > 0 {
+		if err = destinationOptions.SetTimeout(options.Timeout); err != nil {
+			return errors.Join(fmt.Errorf("error from set timeout: %w", err), app.Close())
+		}
+	}
+
+	if options.Username != "" {
+		if err = destinationOptions.AddCredentials(options.AuthenticationType, options.Domain, options.Username, options.Password); err != nil {
+			return errors.Join(fmt.Errorf("error from add credentials: %w", err), app.Close())
+		}
+	}
+
+	c.miSession, err = app.NewRemoteSession(options.Protocol, target, destinationOptions)
+	if err != nil {
+		return errors.Join(fmt.Errorf("error from create NewRemoteSession: %w", err), app.Close())
+	}
+
+	return nil
+}
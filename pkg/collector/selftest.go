@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// startupCosts are the durations of the collections of the startup self-test by collector, see SelfTest.
+type startupCosts struct {
+	mu    sync.Mutex
+	costs map[string]time.Duration
+}
+
+func (s *startupCosts) set(name string, cost time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.costs[name] = cost
+}
+
+func (s *startupCosts) get(name string) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cost, ok := s.costs[name]
+
+	return cost, ok
+}
+
+// SelfTest collects each initialized collector once, one after another, so the collections don't slow each other down,
+// and records their durations as windows_exporter_collector_startup_cost_seconds. Collectors that take longer than
+// scrapeInterval are logged as warnings, since they would delay or time out every scrape.
+//
+// The results of the self-test are not cached. A collector that is still collecting after scrapeInterval keeps
+// running in the background, its startup cost is recorded once it returns. Scrapes attach to the running collection,
+// so a collector is never collected concurrently. Collectors that are not initialized yet are skipped.
+func (c *Collection) SelfTest(ctx context.Context, logger *slog.Logger, scrapeInterval time.Duration) {
+	collectors := c.activeCollectors()
+	slow := make([]string, 0)
+
+	start := time.Now()
+
+	for _, name := range slices.Sorted(maps.Keys(collectors)) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := c.builds[name].status(); err != nil {
+			logger.LogAttrs(ctx, slog.LevelDebug, fmt.Sprintf("skipping self-test of collector %s, it is not initialized", name),
+				slog.Any("err", err),
+			)
+
+			continue
+		}
+
+		cost, finished := c.selfTestCollector(ctx, logger, name, collectors[name], scrapeInterval)
+		if !finished || cost > scrapeInterval {
+			slow = append(slow, name)
+
+			logger.LogAttrs(ctx, slog.LevelWarn, fmt.Sprintf(
+				"collector %s took longer than the scrape interval of %s at startup. Scrapes including it will be slow or time out",
+				name, scrapeInterval,
+			),
+				slog.Duration("cost", cost),
+				slog.Bool("finished", finished),
+			)
+		}
+	}
+
+	if len(slow) > 0 {
+		logger.LogAttrs(ctx, slog.LevelWarn, fmt.Sprintf("self-test finished, %d collectors exceed the scrape interval of %s", len(slow), scrapeInterval),
+			slog.Any("collectors", slow),
+			slog.Duration("duration", time.Since(start)),
+		)
+
+		return
+	}
+
+	logger.LogAttrs(ctx, slog.LevelInfo, "self-test finished, all collectors are within the scrape interval of "+scrapeInterval.String(),
+		slog.Duration("duration", time.Since(start)),
+	)
+}
+
+// selfTestCollector runs a collection of the collector and returns its duration. If the collection doesn't return
+// within timeout, the time waited is returned and finished is false.
+func (c *Collection) selfTestCollector(ctx context.Context, logger *slog.Logger, name string, collector Collector, timeout time.Duration) (time.Duration, bool) {
+	f, leader := c.flights.join(name)
+	if !leader {
+		// A scrape is collecting the collector already, its duration is recorded instead.
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return 0, false
+		}
+
+		c.startupCosts.set(name, f.result.duration)
+
+		return f.result.duration, true
+	}
+
+	done := make(chan struct{})
+	start := time.Now()
+
+	go func() {
+		defer close(done)
+
+		result := cachedResult{statusCode: success}
+
+		defer func() {
+			c.flights.finish(name, f, result)
+		}()
+
+		metricsCh := make(chan prometheus.Metric, 1000)
+		collectedCh := make(chan []prometheus.Metric)
+
+		go func() {
+			var collected []prometheus.Metric
+			for m := range metricsCh {
+				collected = append(collected, m)
+			}
+
+			collectedCh <- collected
+		}()
+
+		// The self-test is not cancelled at the timeout, the startup cost of collectors that don't support
+		// cancellation would be unknown otherwise.
+		err := runCollector(context.WithoutCancel(ctx), name, collector, metricsCh)
+		result.metrics = <-collectedCh
+		result.duration = time.Since(start)
+
+		c.startupCosts.set(name, result.duration)
+
+		if err != nil {
+			result.statusCode = failed
+
+			logger.LogAttrs(ctx, slog.LevelDebug, fmt.Sprintf("self-test of collector %s failed after %s", name, result.duration),
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	select {
+	case <-done:
+		cost, _ := c.startupCosts.get(name)
+
+		return cost, true
+	case <-time.After(timeout):
+		return time.Since(start), false
+	case <-ctx.Done():
+		return time.Since(start), false
+	}
+}
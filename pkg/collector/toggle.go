@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// ErrCollectorNotEnabled is returned by SetDisabled for collectors that were not enabled at startup.
+var ErrCollectorNotEnabled = errors.New("collector is not enabled")
+
+// runtimeToggles are the enabled collectors that were disabled at runtime, e.g. to shed load during an incident.
+// The toggles are shared by all copies of a Collection and are not persisted.
+type runtimeToggles struct {
+	mu       sync.RWMutex
+	disabled map[string]struct{}
+}
+
+// SetDisabled disables or re-enables an enabled collector at runtime.
+// Disabled collectors are skipped by all scrapes until they are enabled again.
+func (c *Collection) SetDisabled(name string, disabled bool) error {
+	if _, ok := BuildersWithFlags[name]; !ok {
+		return fmt.Errorf("unknown collector %s", name)
+	}
+
+	if _, ok := c.collectors[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrCollectorNotEnabled, name)
+	}
+
+	c.toggles.mu.Lock()
+	defer c.toggles.mu.Unlock()
+
+	if disabled {
+		c.toggles.disabled[name] = struct{}{}
+	} else {
+		delete(c.toggles.disabled, name)
+	}
+
+	return nil
+}
+
+// Disabled returns true if the collector was disabled at runtime, see SetDisabled.
+func (c *Collection) Disabled(name string) bool {
+	c.toggles.mu.RLock()
+	defer c.toggles.mu.RUnlock()
+
+	_, ok := c.toggles.disabled[name]
+
+	return ok
+}
+
+// Collectors returns the sorted names of the enabled collectors, including the collectors disabled at runtime.
+func (c *Collection) Collectors() []string {
+	return slices.Sorted(maps.Keys(c.collectors))
+}
+
+// activeCollectors returns the enabled collectors without the collectors disabled at runtime
+// and the collectors suppressed by the maintenance mode, see SetMaintenance.
+func (c *Collection) activeCollectors() Map {
+	c.toggles.mu.RLock()
+	defer c.toggles.mu.RUnlock()
+
+	if len(c.toggles.disabled) == 0 && c.maintenanceValue() == 0 {
+		return c.collectors
+	}
+
+	collectors := make(Map, len(c.collectors))
+
+	for name, collector := range c.collectors {
+		if _, ok := c.toggles.disabled[name]; !ok && !c.maintenanceSuppressed(name) {
+			collectors[name] = collector
+		}
+	}
+
+	return collectors
+}
@@ -18,6 +18,7 @@
 package collector
 
 import (
+	"context"
 	"log/slog"
 	"time"
 
@@ -29,15 +30,46 @@ import (
 const DefaultCollectors = "cpu,memory,logical_disk,physical_disk,net,os,service,system"
 
 type Collection struct {
-	collectors    Map
-	miSession     *mi.Session
-	startTime     time.Time
-	concurrencyCh chan struct{}
+	collectors Map
+	// excludedCollectors are the enabled collectors that were removed at startup, because they can't work on this system.
+	excludedCollectors map[string]collectorExclusion
+	// filters are the per-scrape metric filters by collector name.
+	filters   map[string]MetricFilter
+	miSession *mi.Session
+	// remote is set if the collection collects from a remote host, see NewRemote.
+	remote bool
+	// timeouts are the per-collector timeouts, see SetTimeouts.
+	timeouts map[string]time.Duration
+	// builds tracks the initialization of the collectors, see buildCollectors.
+	builds map[string]*collectorBuild
+	// toggles are shared by all copies of the collection, see SetDisabled.
+	toggles *runtimeToggles
+	// flights are the running collections of the collectors, shared by all copies of the collection.
+	flights *collectorFlights
+	// workers limits the number of collectors that collect at the same time, see SetMaxConcurrency.
+	workers chan struct{}
+	// lastSuccesses are shared by all copies of the collection, so cached and filtered scrapes report them too.
+	lastSuccesses *lastSuccesses
+	// lastCollections are shared by all copies of the collection, see Status.
+	lastCollections *lastCollections
+	// startupCosts are shared by all copies of the collection, see SelfTest.
+	startupCosts *startupCosts
+	// maintenance is shared by all copies of the collection, see SetMaintenance.
+	maintenance *maintenance
+	// app holds the flags of the collectors if the collection was created by NewWithFlags.
+	app *kingpin.Application
+	// cache is shared by all copies of the collection. It is nil if caching is disabled, see SetCache.
+	cache     *resultCache
+	startTime time.Time
 
 	scrapeDurationDesc          *prometheus.Desc
 	collectorScrapeDurationDesc *prometheus.Desc
 	collectorScrapeSuccessDesc  *prometheus.Desc
 	collectorScrapeTimeoutDesc  *prometheus.Desc
+	collectorLastSuccessDesc    *prometheus.Desc
+	collectorExcludedDesc       *prometheus.Desc
+	collectorStartupCostDesc    *prometheus.Desc
+	maintenanceModeDesc         *prometheus.Desc
 }
 
 type (
@@ -56,3 +88,16 @@ type Collector interface {
 	// Close closes the collector
 	Close() error
 }
+
+// ContextCollector is implemented by collectors that can stop their Win32 or WMI calls at the scrape deadline.
+// CollectWithContext is called instead of Collect with a context that is cancelled at the collector timeout.
+type ContextCollector interface {
+	CollectWithContext(ctx context.Context, ch chan<- prometheus.Metric) (err error)
+}
+
+// BackgroundCollector is implemented by collectors that collect in the background, e.g. on a scan interval,
+// and return the result of the last background collection on each scrape.
+type BackgroundCollector interface {
+	// LastSuccess returns the time of the last successful background collection. It is zero until the first one succeeded.
+	LastSuccess() time.Time
+}